@@ -349,7 +349,10 @@ BINARIES:=./istioctl/cmd/istioctl \
   ./cmd/istiod \
   ./tools/hyperistio \
   ./tools/istio-iptables \
-  ./tools/istio-clean-iptables
+  ./tools/istio-clean-iptables \
+  ./cni/cmd/istio-cni \
+  ./cni/cmd/install-cni \
+  ./operator/cmd/operator
 
 # List of binaries included in releases
 RELEASE_BINARIES:=pilot-discovery pilot-agent sidecar-injector mixc mixs mixgen node_agent node_agent_k8s istio_ca istiod istioctl galley sdsclient
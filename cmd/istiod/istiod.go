@@ -19,19 +19,43 @@ import (
 	"net"
 	"os"
 	"strings"
+	"time"
+
+	"go.uber.org/atomic"
 
 	"istio.io/pkg/env"
 
 	"k8s.io/client-go/kubernetes"
 
+	envoyv2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
 	"istio.io/istio/pkg/istiod"
 	"istio.io/istio/pkg/istiod/k8s"
+	"istio.io/istio/security/pkg/k8s/chiron"
 	"istio.io/pkg/log"
 )
 
 var (
 	istiodAddress = env.RegisterStringVar("ISTIOD_ADDR", "",
 		"Local service address of istiod. Default value is mesh.defaultConfig.discoveryAddress, use this to override if the discovery address is external")
+
+	injectionWebhookConfigName = env.RegisterStringVar("INJECTION_WEBHOOK_CONFIG_NAME", "istio-sidecar-injector",
+		"Name of the mutatingwebhookconfiguration whose caBundle and failurePolicy are reconciled, empty to disable")
+
+	validationWebhookConfigName = env.RegisterStringVar("VALIDATION_WEBHOOK_CONFIG_NAME", "istio-galley",
+		"Name of the validatingwebhookconfiguration whose caBundle and failurePolicy are reconciled, empty to disable")
+)
+
+const (
+	// certGracePeriodRatio is the length of the grace period of the Istiod certificates, before their
+	// expiration, in which they are renewed. It is configured as the ratio of the certificate TTL.
+	certGracePeriodRatio = 0.5
+
+	// minCertGracePeriod is the minimum grace period of the Istiod certificates.
+	minCertGracePeriod = 10 * time.Minute
+
+	// webhookResyncPeriod is the interval at which the webhook configurations are reconciled,
+	// in addition to the changes of the configurations and of the certificates.
+	webhookResyncPeriod = time.Minute
 )
 
 // Istio control plane with K8S support.
@@ -63,8 +87,20 @@ func main() {
 	}
 
 	// Create k8s-signed certificates. This allows injector, validation to work without Citadel, and
-	// allows secure SDS connections to Istiod.
-	initCerts(istiods, client)
+	// allows secure SDS connections to Istiod. The certificates are renewed before they expire, and
+	// reloaded by the servers using them.
+	initCerts(istiods, client, stop)
+
+	// Keep the caBundle of the webhooks in sync with the K8S CA, and let the API server ignore the
+	// failures of the webhooks while the Istiod certificate is not trusted by it.
+	webhookReconciler := k8s.NewWebhookReconciler(client.AdmissionregistrationV1beta1(), k8s.WebhookReconcilerArgs{
+		CACertFile:                  k8s.DefaultCA,
+		CertFile:                    istiod.DNSCertDir + "/cert-chain.pem",
+		MutatingWebhookConfigName:   injectionWebhookConfigName.Get(),
+		ValidatingWebhookConfigName: validationWebhookConfigName.Get(),
+		ResyncPeriod:                webhookResyncPeriod,
+	})
+	go webhookReconciler.Run(stop)
 
 	// Init k8s related components, including Galley K8S controllers and
 	// Pilot discovery. Code kept in separate package.
@@ -86,6 +122,7 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed on start XDS server: %v", err)
 	}
+	istiods.EnvoyXdsServer.DebugAuthenticator = envoyv2.NewDebugAuthenticator(client)
 
 	// Injector should run along, even if not used - but only if the injection template is mounted.
 	if _, err := os.Stat("./var/lib/istio/inject/injection-template.yaml"); err == nil {
@@ -95,18 +132,23 @@ func main() {
 		}
 	}
 
+	// Istiod is not ready before the CA is initialized, or disabled.
+	caInitialized := atomic.NewBool(false)
+	istiods.EnvoyXdsServer.AddReadinessCheck("CA", caInitialized.Load)
 	// Options based on the current 'defaults' in istio.
 	// If adjustments are needed - env or mesh.config ( if of general interest ).
 	istiod.RunCA(istiods.SecureGRPCServer, client, &istiod.CAOptions{
 		TrustDomain: istiods.Mesh.TrustDomain,
 	})
+	caInitialized.Store(true)
 
 	istiods.Serve(stop)
 	istiods.WaitStop(stop)
 }
 
-// initCerts will create the certificates to be used by Istiod GRPC server and webhooks, signed by K8S server.
-func initCerts(server *istiod.Server, client *kubernetes.Clientset) {
+// initCerts will create the certificates to be used by Istiod GRPC server and webhooks, signed by K8S server,
+// and rotate them until stop is closed.
+func initCerts(server *istiod.Server, client *kubernetes.Clientset, stop <-chan struct{}) {
 
 	// TODO: fallback to citadel (or custom CA) if K8S signing is broken
 
@@ -134,25 +176,41 @@ func initCerts(server *istiod.Server, client *kubernetes.Clientset) {
 		"istio-ca" + ns,
 	}
 
-	certChain, keyPEM, err := k8s.GenKeyCertK8sCA(client.CertificatesV1beta1(), istiod.IstiodNamespace.Get(),
-		strings.Join(names, ","))
-	if err != nil {
-		log.Fatal("Failed to initialize certs")
-	}
-	server.CertChain = certChain
-	server.CertKey = keyPEM
-
 	// Save the certificates to /var/run/secrets/istio-dns - this is needed since most of the code we currently
 	// use to start grpc and webhooks is based on files. This is a memory-mounted dir.
 	if err := os.MkdirAll(istiod.DNSCertDir, 0700); err != nil {
 		log.Fatalf("Failed to create certs dir: %v", err)
 	}
-	err = ioutil.WriteFile(istiod.DNSCertDir+"/key.pem", keyPEM, 0700)
+
+	certChain, keyPEM, err := genCerts(client, names)
 	if err != nil {
-		log.Fatalf("Failed to write certs: %v", err)
+		log.Fatalf("Failed to initialize certs: %v", err)
 	}
-	err = ioutil.WriteFile(istiod.DNSCertDir+"/cert-chain.pem", certChain, 0700)
+	server.CertChain = certChain
+	server.CertKey = keyPEM
+
+	rotator, err := chiron.NewKeyCertRotator(certGracePeriodRatio, minCertGracePeriod, func() ([]byte, error) {
+		certChain, _, err := genCerts(client, names)
+		return certChain, err
+	})
 	if err != nil {
-		log.Fatal("Failed to write certs")
+		log.Fatalf("Failed to create the certs rotator: %v", err)
+	}
+	go rotator.Run(certChain, stop)
+}
+
+// genCerts creates a key and certificate for the names, signed by K8S server, and saves them to DNSCertDir.
+func genCerts(client *kubernetes.Clientset, names []string) (certChain []byte, keyPEM []byte, err error) {
+	certChain, keyPEM, err = k8s.GenKeyCertK8sCA(client.CertificatesV1beta1(), istiod.IstiodNamespace.Get(),
+		strings.Join(names, ","))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = ioutil.WriteFile(istiod.DNSCertDir+"/key.pem", keyPEM, 0700); err != nil {
+		return nil, nil, err
+	}
+	if err = ioutil.WriteFile(istiod.DNSCertDir+"/cert-chain.pem", certChain, 0700); err != nil {
+		return nil, nil, err
 	}
+	return certChain, keyPEM, nil
 }
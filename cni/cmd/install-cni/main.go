@@ -0,0 +1,115 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// install-cni runs in the istio-cni-node DaemonSet. It installs the Istio CNI plugin on the node,
+// keeps it chained into the CNI configuration, and removes it when the pod terminates.
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/cni/pkg/install"
+	"istio.io/istio/pkg/cmd"
+	"istio.io/pkg/log"
+)
+
+var (
+	cfg = &install.Config{}
+
+	loggingOptions = log.DefaultOptions()
+
+	checkInterval time.Duration
+
+	rootCmd = &cobra.Command{
+		Use:          "install-cni",
+		Short:        "Installs the Istio CNI plugin on the node.",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(0),
+		RunE: func(c *cobra.Command, _ []string) error {
+			cmd.PrintFlags(c.Flags())
+			if err := log.Configure(loggingOptions); err != nil {
+				return err
+			}
+			cfg.KubernetesServiceHost = os.Getenv("KUBERNETES_SERVICE_HOST")
+			cfg.KubernetesServicePort = os.Getenv("KUBERNETES_SERVICE_PORT")
+
+			// the cluster network plugin may not have written its configuration yet
+			for {
+				err := install.Install(cfg)
+				if err == nil {
+					break
+				}
+				log.Warnf("failed to install the Istio CNI plugin, retrying: %v", err)
+				time.Sleep(checkInterval)
+			}
+			log.Info("installed the Istio CNI plugin")
+
+			stop := make(chan struct{})
+			go func() {
+				ticker := time.NewTicker(checkInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-stop:
+						return
+					case <-ticker.C:
+						if err := install.InstallPluginConfig(cfg); err != nil {
+							log.Warnf("failed to check the CNI configuration: %v", err)
+						}
+					}
+				}
+			}()
+			cmd.WaitSignal(stop)
+
+			return install.Uninstall(cfg)
+		},
+	}
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&cfg.CNINetDir, "cniNetDir", "/etc/cni/net.d",
+		"CNI configuration directory of the host")
+	rootCmd.PersistentFlags().StringVar(&cfg.MountedCNINetDir, "mountedCniNetDir", "/host/etc/cni/net.d",
+		"CNI configuration directory of the host, as mounted in the container")
+	rootCmd.PersistentFlags().StringVar(&cfg.CNIConfName, "cniConfName", "",
+		"CNI configuration file to chain the plugin into, defaults to the one used by the container runtime")
+	rootCmd.PersistentFlags().StringVar(&cfg.CNIBinDir, "cniBinDir", "/opt/cni/bin",
+		"CNI binary directory of the host")
+	rootCmd.PersistentFlags().StringVar(&cfg.MountedCNIBinDir, "mountedCniBinDir", "/host/opt/cni/bin",
+		"CNI binary directory of the host, as mounted in the container")
+	rootCmd.PersistentFlags().StringVar(&cfg.CNIBinSourceDir, "cniBinSourceDir", "/opt/cni/bin",
+		"Directory holding the binaries to install")
+	rootCmd.PersistentFlags().StringVar(&cfg.KubeconfigFilename, "kubeconfigFilename", "ZZZ-istio-cni-kubeconfig",
+		"Name of the kubeconfig written for the plugin")
+	rootCmd.PersistentFlags().StringVar(&cfg.ServiceAccountDir, "serviceAccountDir", "/var/run/secrets/kubernetes.io/serviceaccount",
+		"Directory holding the service account credentials the plugin authenticates with")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.ExcludeNamespaces, "excludeNamespaces", []string{},
+		"Namespaces whose pods are never redirected by the plugin")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogLevel, "pluginLogLevel", "warn",
+		"Log level of the plugin")
+	rootCmd.PersistentFlags().DurationVar(&checkInterval, "checkInterval", 10*time.Second,
+		"How often the plugin is checked to still be chained into the CNI configuration")
+
+	loggingOptions.AttachCobraFlags(rootCmd)
+	cmd.AddFlags(rootCmd)
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(-1)
+	}
+}
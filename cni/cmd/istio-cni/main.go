@@ -0,0 +1,34 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// istio-cni is the CNI plugin invoked by the container runtime. It is installed on the nodes by
+// install-cni.
+package main
+
+import (
+	"os"
+
+	"istio.io/istio/cni/pkg/plugin"
+)
+
+func main() {
+	args, err := plugin.ArgsFromEnv(os.Stdin)
+	if err == nil {
+		err = plugin.NewPlugin().Run(args, os.Stdout)
+	}
+	if err != nil {
+		plugin.WriteError(os.Stdout, err)
+		os.Exit(1)
+	}
+}
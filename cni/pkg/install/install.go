@@ -0,0 +1,309 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package install installs the Istio CNI plugin on a node: it copies the plugin binaries to the
+// host, writes the kubeconfig the plugin uses, and chains the plugin into the CNI configuration
+// of the cluster network plugin.
+package install
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// PluginType is the type of the plugin in the CNI configuration.
+	PluginType = "istio-cni"
+
+	// Binaries are the files copied to the CNI bin directory of the host.
+	pluginBinary   = "istio-cni"
+	iptablesBinary = "istio-iptables"
+)
+
+// Config configures the installation. The mounted directories are the host directories as seen
+// by the installer; the others are the same directories as seen by the host, which is where the
+// plugin runs.
+type Config struct {
+	// CNINetDir is the CNI configuration directory of the host.
+	CNINetDir        string
+	MountedCNINetDir string
+	// CNIConfName is the configuration file the plugin is chained into. If empty, the file the
+	// container runtime uses is picked, the first one in lexicographic order.
+	CNIConfName string
+
+	// CNIBinDir is the CNI binary directory of the host.
+	CNIBinDir        string
+	MountedCNIBinDir string
+	// CNIBinSourceDir holds the binaries to install.
+	CNIBinSourceDir string
+
+	// KubeconfigFilename is the name of the kubeconfig written to the CNI configuration directory.
+	KubeconfigFilename string
+	// KubernetesServiceHost and KubernetesServicePort address the API server.
+	KubernetesServiceHost string
+	KubernetesServicePort string
+	// ServiceAccountDir holds the token and CA certificate of the installer service account,
+	// which the plugin authenticates with.
+	ServiceAccountDir string
+
+	ExcludeNamespaces []string
+	LogLevel          string
+}
+
+// Install copies the binaries, writes the kubeconfig and chains the plugin.
+func Install(cfg *Config) error {
+	for _, b := range []string{pluginBinary, iptablesBinary} {
+		if err := copyFile(filepath.Join(cfg.CNIBinSourceDir, b), filepath.Join(cfg.MountedCNIBinDir, b)); err != nil {
+			return fmt.Errorf("failed to install %s: %v", b, err)
+		}
+	}
+	if err := writeKubeconfig(cfg); err != nil {
+		return fmt.Errorf("failed to write the kubeconfig: %v", err)
+	}
+	return InstallPluginConfig(cfg)
+}
+
+// InstallPluginConfig chains the plugin into the CNI configuration, unless it already is. It is
+// called periodically since the cluster network plugin may rewrite its configuration.
+func InstallPluginConfig(cfg *Config) error {
+	path, conf, err := readConfList(cfg)
+	if err != nil {
+		return err
+	}
+	plugins := removePlugin(conf["plugins"].([]interface{}))
+	updated := make(map[string]interface{}, len(conf))
+	for k, v := range conf {
+		updated[k] = v
+	}
+	updated["plugins"] = append(plugins, pluginConfig(cfg))
+	if reflect.DeepEqual(conf, updated) && strings.HasSuffix(path, ".conflist") {
+		return nil
+	}
+
+	out := path
+	if !strings.HasSuffix(path, ".conflist") {
+		// a single plugin configuration has no chain, convert it to a list
+		out = strings.TrimSuffix(path, filepath.Ext(path)) + ".conflist"
+	}
+	if err := writeJSON(out, updated); err != nil {
+		return err
+	}
+	if out != path {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+	}
+	log.Infof("chained %s into %s", PluginType, out)
+	return nil
+}
+
+// Uninstall removes the plugin from the CNI configuration, and the files it installed.
+func Uninstall(cfg *Config) error {
+	path, conf, err := readConfList(cfg)
+	if err != nil {
+		return err
+	}
+	conf["plugins"] = removePlugin(conf["plugins"].([]interface{}))
+	if err := writeJSON(path, conf); err != nil {
+		return err
+	}
+	for _, f := range []string{
+		filepath.Join(cfg.MountedCNINetDir, cfg.KubeconfigFilename),
+		filepath.Join(cfg.MountedCNIBinDir, pluginBinary),
+		filepath.Join(cfg.MountedCNIBinDir, iptablesBinary),
+	} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	log.Infof("removed %s from %s", PluginType, path)
+	return nil
+}
+
+// pluginConfig is the configuration of the plugin in the chain, read by the plugin as a
+// plugin.Config.
+func pluginConfig(cfg *Config) map[string]interface{} {
+	exclude := make([]interface{}, 0, len(cfg.ExcludeNamespaces))
+	for _, ns := range cfg.ExcludeNamespaces {
+		exclude = append(exclude, ns)
+	}
+	return map[string]interface{}{
+		"type":      PluginType,
+		"log_level": cfg.LogLevel,
+		"kubernetes": map[string]interface{}{
+			"kubeconfig":         filepath.Join(cfg.CNINetDir, cfg.KubeconfigFilename),
+			"cni_bin_dir":        cfg.CNIBinDir,
+			"exclude_namespaces": exclude,
+		},
+	}
+}
+
+func removePlugin(plugins []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(plugins))
+	for _, p := range plugins {
+		if m, ok := p.(map[string]interface{}); ok && m["type"] == PluginType {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// readConfList reads the CNI configuration to chain the plugin into, as a configuration list.
+func readConfList(cfg *Config) (string, map[string]interface{}, error) {
+	path, err := confFile(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	conf := map[string]interface{}{}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+	if _, f := conf["plugins"]; f {
+		if _, ok := conf["plugins"].([]interface{}); !ok {
+			return "", nil, fmt.Errorf("invalid plugins in %s", path)
+		}
+		return path, conf, nil
+	}
+	list := map[string]interface{}{
+		"name":    conf["name"],
+		"plugins": []interface{}{conf},
+	}
+	if v, f := conf["cniVersion"]; f {
+		list["cniVersion"] = v
+	}
+	return path, list, nil
+}
+
+func confFile(cfg *Config) (string, error) {
+	if cfg.CNIConfName != "" {
+		path := filepath.Join(cfg.MountedCNINetDir, cfg.CNIConfName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		// the configuration may have been converted to a list already
+		list := strings.TrimSuffix(path, filepath.Ext(path)) + ".conflist"
+		if _, err := os.Stat(list); err == nil {
+			return list, nil
+		}
+		return "", fmt.Errorf("CNI configuration %s not found", path)
+	}
+	files, err := ioutil.ReadDir(cfg.MountedCNINetDir)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		switch filepath.Ext(f.Name()) {
+		case ".conf", ".conflist", ".json":
+			if !f.IsDir() {
+				names = append(names, f.Name())
+			}
+		}
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no CNI configuration found in %s", cfg.MountedCNINetDir)
+	}
+	sort.Strings(names)
+	return filepath.Join(cfg.MountedCNINetDir, names[0]), nil
+}
+
+const kubeconfigTemplate = `apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+users:
+- name: istio-cni
+  user:
+    token: %s
+contexts:
+- name: istio-cni-context
+  context:
+    cluster: local
+    user: istio-cni
+current-context: istio-cni-context
+`
+
+func writeKubeconfig(cfg *Config) error {
+	token, err := ioutil.ReadFile(filepath.Join(cfg.ServiceAccountDir, "token"))
+	if err != nil {
+		return err
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(cfg.ServiceAccountDir, "ca.crt"))
+	if err != nil {
+		return err
+	}
+	data := fmt.Sprintf(kubeconfigTemplate,
+		"https://"+net.JoinHostPort(cfg.KubernetesServiceHost, cfg.KubernetesServicePort),
+		base64.StdEncoding.EncodeToString(ca), string(token))
+	return atomicWrite(filepath.Join(cfg.MountedCNINetDir, cfg.KubeconfigFilename), []byte(data), 0600)
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicWrite(path, data, 0644)
+}
+
+// atomicWrite writes the file through a rename, so the container runtime never reads it partially.
+func atomicWrite(path string, data []byte, mode os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() // nolint: errcheck
+
+	// binaries may be in use, replace them rather than writing them in place
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
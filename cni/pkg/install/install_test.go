@@ -0,0 +1,224 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package install
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	calicoConflist = `{
+  "name": "k8s-pod-network",
+  "cniVersion": "0.3.1",
+  "plugins": [
+    {"type": "calico", "ipam": {"type": "calico-ipam"}},
+    {"type": "portmap", "capabilities": {"portMappings": true}}
+  ]
+}`
+	bridgeConf = `{"cniVersion": "0.3.1", "name": "bridge", "type": "bridge", "bridge": "cni0"}`
+)
+
+func setup(t *testing.T, files map[string]string) (*Config, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "install-cni")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{
+		CNINetDir:             "/etc/cni/net.d",
+		MountedCNINetDir:      filepath.Join(dir, "net.d"),
+		CNIBinDir:             "/opt/cni/bin",
+		MountedCNIBinDir:      filepath.Join(dir, "bin"),
+		CNIBinSourceDir:       filepath.Join(dir, "src"),
+		KubeconfigFilename:    "ZZZ-istio-cni-kubeconfig",
+		KubernetesServiceHost: "10.96.0.1",
+		KubernetesServicePort: "443",
+		ServiceAccountDir:     filepath.Join(dir, "sa"),
+		ExcludeNamespaces:     []string{"istio-system"},
+		LogLevel:              "warn",
+	}
+	for _, d := range []string{cfg.MountedCNINetDir, cfg.MountedCNIBinDir, cfg.CNIBinSourceDir, cfg.ServiceAccountDir} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all := map[string]string{
+		filepath.Join(cfg.CNIBinSourceDir, pluginBinary):   "plugin",
+		filepath.Join(cfg.CNIBinSourceDir, iptablesBinary): "iptables",
+		filepath.Join(cfg.ServiceAccountDir, "token"):      "token",
+		filepath.Join(cfg.ServiceAccountDir, "ca.crt"):     "ca",
+	}
+	for name, content := range files {
+		all[filepath.Join(cfg.MountedCNINetDir, name)] = content
+	}
+	for path, content := range all {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg, func() { _ = os.RemoveAll(dir) }
+}
+
+func readPluginTypes(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := struct {
+		Plugins []struct {
+			Type string `json:"type"`
+		} `json:"plugins"`
+	}{}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		t.Fatal(err)
+	}
+	types := make([]string, 0, len(conf.Plugins))
+	for _, p := range conf.Plugins {
+		types = append(types, p.Type)
+	}
+	return types
+}
+
+func TestInstall(t *testing.T) {
+	cases := []struct {
+		name     string
+		files    map[string]string
+		confName string
+		wantFile string
+		want     string
+		wantGone string
+	}{
+		{
+			name:     "conflist",
+			files:    map[string]string{"10-calico.conflist": calicoConflist, "99-loopback.conf": bridgeConf},
+			wantFile: "10-calico.conflist",
+			want:     "calico,portmap,istio-cni",
+		},
+		{
+			name:     "conf converted to conflist",
+			files:    map[string]string{"10-bridge.conf": bridgeConf},
+			wantFile: "10-bridge.conflist",
+			want:     "bridge,istio-cni",
+			wantGone: "10-bridge.conf",
+		},
+		{
+			name:     "named configuration",
+			files:    map[string]string{"10-calico.conflist": calicoConflist, "20-bridge.conf": bridgeConf},
+			confName: "20-bridge.conf",
+			wantFile: "20-bridge.conflist",
+			want:     "bridge,istio-cni",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, cleanup := setup(t, tt.files)
+			defer cleanup()
+			cfg.CNIConfName = tt.confName
+
+			if err := Install(cfg); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(cfg.MountedCNINetDir, tt.wantFile)
+			if got := strings.Join(readPluginTypes(t, path), ","); got != tt.want {
+				t.Errorf("got plugins %s, want %s", got, tt.want)
+			}
+			if tt.wantGone != "" {
+				if _, err := os.Stat(filepath.Join(cfg.MountedCNINetDir, tt.wantGone)); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be replaced by its conflist", tt.wantGone)
+				}
+			}
+
+			// installing again, as the periodic check does, is a no-op
+			before, _ := ioutil.ReadFile(path)
+			if err := InstallPluginConfig(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if after, _ := ioutil.ReadFile(path); string(after) != string(before) {
+				t.Errorf("expected the configuration to be unchanged, got %s", string(after))
+			}
+
+			if err := Uninstall(cfg); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(readPluginTypes(t, path), ","); got != strings.TrimSuffix(tt.want, ",istio-cni") {
+				t.Errorf("got plugins %s after uninstall", got)
+			}
+			for _, f := range []string{
+				filepath.Join(cfg.MountedCNIBinDir, pluginBinary),
+				filepath.Join(cfg.MountedCNINetDir, cfg.KubeconfigFilename),
+			} {
+				if _, err := os.Stat(f); !os.IsNotExist(err) {
+					t.Errorf("expected %s to be removed", f)
+				}
+			}
+		})
+	}
+}
+
+func TestInstallFiles(t *testing.T) {
+	cfg, cleanup := setup(t, map[string]string{"10-calico.conflist": calicoConflist})
+	defer cleanup()
+
+	if err := Install(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(cfg.MountedCNIBinDir, iptablesBinary)); err != nil || string(data) != "iptables" {
+		t.Errorf("expected %s to be installed: %v", iptablesBinary, err)
+	}
+
+	kubeconfig, err := clientcmd.LoadFromFile(filepath.Join(cfg.MountedCNINetDir, cfg.KubeconfigFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster := kubeconfig.Clusters[kubeconfig.Contexts[kubeconfig.CurrentContext].Cluster]
+	if cluster.Server != "https://10.96.0.1:443" || string(cluster.CertificateAuthorityData) != "ca" {
+		t.Errorf("unexpected cluster %+v", cluster)
+	}
+	if token := kubeconfig.AuthInfos[kubeconfig.Contexts[kubeconfig.CurrentContext].AuthInfo].Token; token != "token" {
+		t.Errorf("got token %q", token)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(cfg.MountedCNINetDir, "10-calico.conflist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := struct {
+		Plugins []map[string]interface{} `json:"plugins"`
+	}{}
+	if err := json.Unmarshal(data, &conf); err != nil {
+		t.Fatal(err)
+	}
+	kube := conf.Plugins[len(conf.Plugins)-1]["kubernetes"].(map[string]interface{})
+	if kube["kubeconfig"] != "/etc/cni/net.d/ZZZ-istio-cni-kubeconfig" || kube["cni_bin_dir"] != "/opt/cni/bin" {
+		t.Errorf("unexpected plugin configuration %v", kube)
+	}
+}
+
+func TestInstallNoConfiguration(t *testing.T) {
+	cfg, cleanup := setup(t, nil)
+	defer cleanup()
+
+	if err := Install(cfg); err == nil {
+		t.Fatal("expected an error without CNI configuration")
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package plugin implements the Istio CNI plugin. It is chained after the network plugin of the
+// cluster and programs the traffic redirection of the injected pods when their network namespace
+// is set up, so that they do not need the privileged istio-init container.
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pkg/kube"
+	"istio.io/pkg/log"
+)
+
+const (
+	// CNIVersion is the newest version of the CNI specification supported by the plugin.
+	CNIVersion = "0.4.0"
+
+	// errorCodeInternal is the CNI error code reported for the failures of the plugin itself.
+	errorCodeInternal = 100
+
+	commandAdd     = "ADD"
+	commandCheck   = "CHECK"
+	commandDel     = "DEL"
+	commandVersion = "VERSION"
+)
+
+var supportedVersions = []string{"0.1.0", "0.2.0", "0.3.0", "0.3.1", CNIVersion}
+
+// Kubernetes is the Kubernetes specific configuration of the plugin.
+type Kubernetes struct {
+	// Kubeconfig is the path of the kubeconfig used to look up the pods.
+	Kubeconfig string `json:"kubeconfig"`
+	// CNIBinDir is the directory the istio-iptables binary is installed to.
+	CNIBinDir string `json:"cni_bin_dir"`
+	// ExcludeNamespaces are the namespaces whose pods are never redirected.
+	ExcludeNamespaces []string `json:"exclude_namespaces"`
+}
+
+// Config is the network configuration passed to the plugin by the container runtime.
+type Config struct {
+	CNIVersion string `json:"cniVersion"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	// PrevResult is the result of the previous plugin of the chain, which is passed through.
+	PrevResult json.RawMessage `json:"prevResult,omitempty"`
+	LogLevel   string          `json:"log_level"`
+	Kubernetes Kubernetes      `json:"kubernetes"`
+}
+
+// Args are the parameters of a plugin invocation, passed by the container runtime as CNI_*
+// environment variables and on stdin.
+type Args struct {
+	Command     string
+	ContainerID string
+	Netns       string
+	IfName      string
+
+	// PodName and PodNamespace are set from the K8S_POD_NAME and K8S_POD_NAMESPACE CNI_ARGS.
+	PodName      string
+	PodNamespace string
+
+	StdinData []byte
+}
+
+// ArgsFromEnv reads the parameters of the invocation from the environment and stdin.
+func ArgsFromEnv(stdin io.Reader) (*Args, error) {
+	args := &Args{
+		Command:     os.Getenv("CNI_COMMAND"),
+		ContainerID: os.Getenv("CNI_CONTAINERID"),
+		Netns:       os.Getenv("CNI_NETNS"),
+		IfName:      os.Getenv("CNI_IFNAME"),
+	}
+	if args.Command == "" {
+		return nil, errors.New("CNI_COMMAND is not set")
+	}
+	cniArgs := parseCNIArgs(os.Getenv("CNI_ARGS"))
+	args.PodName = cniArgs["K8S_POD_NAME"]
+	args.PodNamespace = cniArgs["K8S_POD_NAMESPACE"]
+	if args.Command != commandVersion {
+		data, err := ioutil.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the network configuration: %v", err)
+		}
+		args.StdinData = data
+	}
+	return args, nil
+}
+
+// parseCNIArgs parses the semicolon separated KEY=VALUE pairs of CNI_ARGS.
+func parseCNIArgs(s string) map[string]string {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ";") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			out[kv[0]] = kv[1]
+		}
+	}
+	return out
+}
+
+// Plugin executes the CNI commands.
+type Plugin struct {
+	// newClient creates the client the pods are looked up with.
+	newClient func(kubeconfig string) (kubernetes.Interface, error)
+	// redirect programs the redirection in the network namespace of a pod.
+	redirect func(binDir, netns string, r *Redirect) error
+}
+
+// NewPlugin creates a plugin that looks up the pods in Kubernetes and programs their redirection
+// with istio-iptables.
+func NewPlugin() *Plugin {
+	return &Plugin{
+		newClient: func(kubeconfig string) (kubernetes.Interface, error) {
+			return kube.CreateClientset(kubeconfig, "")
+		},
+		redirect: programIptables,
+	}
+}
+
+// Run executes the command of args, writing its result to out.
+func (p *Plugin) Run(args *Args, out io.Writer) error {
+	switch args.Command {
+	case commandVersion:
+		return json.NewEncoder(out).Encode(map[string]interface{}{
+			"cniVersion":        CNIVersion,
+			"supportedVersions": supportedVersions,
+		})
+	case commandAdd:
+		return p.cmdAdd(args, out)
+	case commandCheck, commandDel:
+		// the rules go away with the network namespace of the pod
+		return nil
+	default:
+		return fmt.Errorf("unsupported CNI command %q", args.Command)
+	}
+}
+
+func (p *Plugin) cmdAdd(args *Args, out io.Writer) error {
+	conf, err := parseConfig(args.StdinData)
+	if err != nil {
+		return err
+	}
+	configureLogging(conf.LogLevel)
+
+	if err := p.setupRedirect(conf, args); err != nil {
+		log.Errorf("istio-cni failed to set up the redirection of %s/%s: %v", args.PodNamespace, args.PodName, err)
+		return err
+	}
+
+	// pass the result of the previous plugin through
+	_, err = out.Write(conf.PrevResult)
+	return err
+}
+
+func (p *Plugin) setupRedirect(conf *Config, args *Args) error {
+	if args.PodName == "" || args.PodNamespace == "" {
+		log.Debugf("container %s is not a Kubernetes pod, skipping", args.ContainerID)
+		return nil
+	}
+	for _, ns := range conf.Kubernetes.ExcludeNamespaces {
+		if ns == args.PodNamespace {
+			log.Debugf("pod %s/%s is in an excluded namespace, skipping", args.PodNamespace, args.PodName)
+			return nil
+		}
+	}
+
+	client, err := p.newClient(conf.Kubernetes.Kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to create the kube client: %v", err)
+	}
+	pod, err := getPod(client, args.PodNamespace, args.PodName)
+	if err != nil {
+		return err
+	}
+
+	r, err := redirectForPod(pod)
+	if err != nil || r == nil {
+		return err
+	}
+	log.Infof("setting up the redirection of pod %s/%s: %v", args.PodNamespace, args.PodName, r.args())
+	return p.redirect(conf.Kubernetes.CNIBinDir, args.Netns, r)
+}
+
+func parseConfig(data []byte) (*Config, error) {
+	conf := &Config{}
+	if err := json.Unmarshal(data, conf); err != nil {
+		return nil, fmt.Errorf("failed to parse the network configuration: %v", err)
+	}
+	if len(conf.PrevResult) == 0 {
+		return nil, errors.New("istio-cni must be run as a chained plugin")
+	}
+	return conf, nil
+}
+
+var logLevels = map[string]log.Level{
+	"debug": log.DebugLevel,
+	"info":  log.InfoLevel,
+	"warn":  log.WarnLevel,
+	"error": log.ErrorLevel,
+	"none":  log.NoneLevel,
+}
+
+// configureLogging sends the logs to stderr, since stdout carries the result of the plugin.
+func configureLogging(level string) {
+	options := log.DefaultOptions()
+	options.OutputPaths = []string{"stderr"}
+	options.ErrorOutputPaths = []string{"stderr"}
+	if l, f := logLevels[strings.ToLower(level)]; f {
+		options.SetOutputLevel(log.DefaultScopeName, l)
+	}
+	_ = log.Configure(options)
+}
+
+// WriteError writes err to out in the format of the CNI specification.
+func WriteError(out io.Writer, err error) {
+	_ = json.NewEncoder(out).Encode(map[string]interface{}{
+		"cniVersion": CNIVersion,
+		"code":       errorCodeInternal,
+		"msg":        err.Error(),
+	})
+}
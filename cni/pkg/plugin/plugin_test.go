@@ -0,0 +1,181 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/api/annotation"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	prevResult = `{"cniVersion":"0.3.1","interfaces":[{"name":"eth0"}],"ips":[{"version":"4","address":"10.0.0.2/24"}]}`
+	netConf    = `{"cniVersion":"0.3.1","name":"k8s-pod-network","type":"istio-cni","log_level":"debug",
+"kubernetes":{"kubeconfig":"/etc/cni/net.d/ZZZ-istio-cni-kubeconfig","cni_bin_dir":"/opt/cni/bin","exclude_namespaces":["istio-system"]},
+"prevResult":` + prevResult + `}`
+)
+
+func makePod(namespace string, annotations map[string]string, initContainers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, Annotations: annotations},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app"}, {Name: "istio-proxy"}},
+		},
+	}
+	for _, c := range initContainers {
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{Name: c})
+	}
+	return pod
+}
+
+func TestCmdAdd(t *testing.T) {
+	injected := map[string]string{annotation.SidecarStatus.Name: `{"version":"1"}`}
+
+	cases := []struct {
+		name    string
+		pod     *corev1.Pod
+		conf    string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "injected pod",
+			pod:  makePod("default", injected),
+			conf: netConf,
+			want: []string{"-p", "15001", "-z", "15006", "-u", "1337", "-m", "REDIRECT", "-i", "*", "-x", "", "-b", "*", "-d", "15020"},
+		},
+		{
+			name: "annotated pod",
+			pod: makePod("default", map[string]string{
+				annotation.SidecarStatus.Name:                         `{"version":"1"}`,
+				annotation.SidecarInterceptionMode.Name:               "TPROXY",
+				annotation.SidecarTrafficIncludeOutboundIPRanges.Name: "10.0.0.0/8",
+				annotation.SidecarTrafficIncludeInboundPorts.Name:     "8080",
+				annotation.SidecarTrafficExcludeOutboundPorts.Name:    "3306",
+				annotation.SidecarTrafficExcludeInboundPorts.Name:     "15020,9090",
+				annotation.SidecarTrafficExcludeOutboundIPRanges.Name: "10.1.0.0/16",
+				annotation.SidecarTrafficKubevirtInterfaces.Name:      "net1",
+				constants.SidecarTrafficExcludeInterfacesAnnotation:   "eth1",
+			}),
+			conf: netConf,
+			want: []string{"-p", "15001", "-z", "15006", "-u", "1337", "-m", "TPROXY", "-i", "10.0.0.0/8", "-x", "10.1.0.0/16",
+				"-b", "8080", "-d", "15020,9090", "-o", "3306", "-k", "net1", "-c", "eth1"},
+		},
+		{
+			name: "not injected",
+			pod:  makePod("default", nil),
+			conf: netConf,
+		},
+		{
+			name: "redirected by istio-init",
+			pod:  makePod("default", injected, "istio-init"),
+			conf: netConf,
+		},
+		{
+			name: "interception disabled",
+			pod: makePod("default", map[string]string{
+				annotation.SidecarStatus.Name:           `{"version":"1"}`,
+				annotation.SidecarInterceptionMode.Name: "NONE",
+			}),
+			conf: netConf,
+		},
+		{
+			name: "excluded namespace",
+			pod:  makePod("istio-system", injected),
+			conf: netConf,
+		},
+		{
+			name: "invalid annotation",
+			pod: makePod("default", map[string]string{
+				annotation.SidecarStatus.Name:                         `{"version":"1"}`,
+				annotation.SidecarTrafficIncludeOutboundIPRanges.Name: "not-a-cidr",
+			}),
+			conf:    netConf,
+			wantErr: "invalid annotation",
+		},
+		{
+			name:    "not chained",
+			pod:     makePod("default", injected),
+			conf:    `{"cniVersion":"0.3.1","name":"k8s-pod-network","type":"istio-cni"}`,
+			wantErr: "chained plugin",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			p := &Plugin{
+				newClient: func(kubeconfig string) (kubernetes.Interface, error) {
+					return fake.NewSimpleClientset(tt.pod), nil
+				},
+				redirect: func(binDir, netns string, r *Redirect) error {
+					if binDir != "/opt/cni/bin" || netns != "/proc/1/ns/net" {
+						t.Errorf("unexpected bin dir %q or netns %q", binDir, netns)
+					}
+					got = r.args()
+					return nil
+				},
+			}
+			out := &bytes.Buffer{}
+			err := p.Run(&Args{
+				Command:      "ADD",
+				ContainerID:  "container",
+				Netns:        "/proc/1/ns/net",
+				IfName:       "eth0",
+				PodName:      tt.pod.Name,
+				PodNamespace: tt.pod.Namespace,
+				StdinData:    []byte(tt.conf),
+			}, out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got redirect %v, want %v", got, tt.want)
+			}
+			if out.String() != prevResult {
+				t.Errorf("got result %s, want the previous result passed through", out.String())
+			}
+		})
+	}
+}
+
+func TestParseCNIArgs(t *testing.T) {
+	got := parseCNIArgs("IgnoreUnknown=1;K8S_POD_NAMESPACE=default;K8S_POD_NAME=app-1;K8S_POD_INFRA_CONTAINER_ID=abc;invalid")
+	want := map[string]string{
+		"IgnoreUnknown":              "1",
+		"K8S_POD_NAMESPACE":          "default",
+		"K8S_POD_NAME":               "app-1",
+		"K8S_POD_INFRA_CONTAINER_ID": "abc",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
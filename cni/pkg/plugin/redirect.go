@@ -0,0 +1,188 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/api/annotation"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/pkg/log"
+)
+
+const (
+	// istioInitContainerName is the init container programming the redirection without the plugin.
+	istioInitContainerName = "istio-init"
+
+	proxyPort          = 15001
+	inboundCapturePort = 15006
+
+	defaultInterceptionMode = "REDIRECT"
+
+	// podLookupAttempts bounds how long the plugin waits for the pod to be visible in the API server.
+	podLookupAttempts = 30
+	podLookupInterval = time.Second
+)
+
+// Redirect is the redirection of the traffic of a pod to its sidecar, with the same parameters the
+// istio-init container passes to istio-iptables.
+type Redirect struct {
+	InterceptionMode     string
+	IncludeIPRanges      string
+	ExcludeIPRanges      string
+	IncludeInboundPorts  string
+	ExcludeInboundPorts  string
+	ExcludeOutboundPorts string
+	KubevirtInterfaces   string
+	ExcludeInterfaces    string
+}
+
+type redirectParam struct {
+	annotation string
+	def        string
+	validate   func(string) error
+	field      func(*Redirect) *string
+}
+
+// redirectParams are read from the pod annotations, which the injector fills in from the
+// podRedirectAnnot section of the injection template.
+var redirectParams = []redirectParam{
+	{annotation.SidecarInterceptionMode.Name, defaultInterceptionMode, validateInterceptionMode,
+		func(r *Redirect) *string { return &r.InterceptionMode }},
+	{annotation.SidecarTrafficIncludeOutboundIPRanges.Name, inject.DefaultIncludeIPRanges, inject.ValidateIncludeIPRanges,
+		func(r *Redirect) *string { return &r.IncludeIPRanges }},
+	{annotation.SidecarTrafficExcludeOutboundIPRanges.Name, "", inject.ValidateExcludeIPRanges,
+		func(r *Redirect) *string { return &r.ExcludeIPRanges }},
+	{annotation.SidecarTrafficIncludeInboundPorts.Name, inject.DefaultIncludeInboundPorts, inject.ValidateIncludeInboundPorts,
+		func(r *Redirect) *string { return &r.IncludeInboundPorts }},
+	{annotation.SidecarTrafficExcludeInboundPorts.Name, strconv.Itoa(inject.DefaultStatusPort), inject.ValidateExcludeInboundPorts,
+		func(r *Redirect) *string { return &r.ExcludeInboundPorts }},
+	{annotation.SidecarTrafficExcludeOutboundPorts.Name, "", inject.ValidateExcludeOutboundPorts,
+		func(r *Redirect) *string { return &r.ExcludeOutboundPorts }},
+	{annotation.SidecarTrafficKubevirtInterfaces.Name, inject.DefaultkubevirtInterfaces, inject.ValidateKubevirtInterfaces,
+		func(r *Redirect) *string { return &r.KubevirtInterfaces }},
+	{constants.SidecarTrafficExcludeInterfacesAnnotation, "", inject.ValidateExcludeInterfaces,
+		func(r *Redirect) *string { return &r.ExcludeInterfaces }},
+}
+
+func validateInterceptionMode(mode string) error {
+	switch mode {
+	case "REDIRECT", "TPROXY":
+		return nil
+	default:
+		return fmt.Errorf("unsupported interception mode %q", mode)
+	}
+}
+
+// redirectForPod returns the redirection of the pod, or nil if its traffic is not to be redirected:
+// it has no sidecar, redirects its traffic with istio-init, or has interception disabled.
+func redirectForPod(pod *corev1.Pod) (*Redirect, error) {
+	if pod.Annotations[annotation.SidecarStatus.Name] == "" {
+		log.Debugf("pod %s/%s is not injected, skipping", pod.Namespace, pod.Name)
+		return nil, nil
+	}
+	hasProxy := false
+	for _, c := range pod.Spec.Containers {
+		if c.Name == inject.ProxyContainerName {
+			hasProxy = true
+		}
+	}
+	if !hasProxy {
+		log.Debugf("pod %s/%s has no sidecar, skipping", pod.Namespace, pod.Name)
+		return nil, nil
+	}
+	for _, c := range pod.Spec.InitContainers {
+		if c.Name == istioInitContainerName {
+			log.Debugf("pod %s/%s is redirected by %s, skipping", pod.Namespace, pod.Name, istioInitContainerName)
+			return nil, nil
+		}
+	}
+	if pod.Annotations[annotation.SidecarInterceptionMode.Name] == "NONE" {
+		log.Debugf("pod %s/%s has interception disabled, skipping", pod.Namespace, pod.Name)
+		return nil, nil
+	}
+
+	r := &Redirect{}
+	for _, p := range redirectParams {
+		value, f := pod.Annotations[p.annotation]
+		if !f {
+			value = p.def
+		}
+		if p.validate != nil {
+			if err := p.validate(value); err != nil {
+				return nil, fmt.Errorf("invalid annotation %s=%q of pod %s/%s: %v", p.annotation, value, pod.Namespace, pod.Name, err)
+			}
+		}
+		*p.field(r) = value
+	}
+	return r, nil
+}
+
+// args returns the istio-iptables arguments of the redirection.
+func (r *Redirect) args() []string {
+	args := []string{
+		"-p", strconv.Itoa(proxyPort),
+		"-z", strconv.Itoa(inboundCapturePort),
+		"-u", strconv.FormatUint(inject.DefaultSidecarProxyUID, 10),
+		"-m", r.InterceptionMode,
+		"-i", r.IncludeIPRanges,
+		"-x", r.ExcludeIPRanges,
+		"-b", r.IncludeInboundPorts,
+		"-d", r.ExcludeInboundPorts,
+	}
+	if r.ExcludeOutboundPorts != "" {
+		args = append(args, "-o", r.ExcludeOutboundPorts)
+	}
+	if r.KubevirtInterfaces != "" {
+		args = append(args, "-k", r.KubevirtInterfaces)
+	}
+	if r.ExcludeInterfaces != "" {
+		args = append(args, "-c", r.ExcludeInterfaces)
+	}
+	return args
+}
+
+// programIptables runs istio-iptables in the network namespace of the pod.
+func programIptables(binDir, netns string, r *Redirect) error {
+	args := append([]string{"--net=" + netns, "--", filepath.Join(binDir, "istio-iptables")}, r.args()...)
+	out, err := exec.Command("nsenter", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("istio-iptables failed: %v: %s", err, string(out))
+	}
+	log.Debugf("istio-iptables output: %s", string(out))
+	return nil
+}
+
+// getPod looks up the pod, waiting for it to be visible in the API server.
+func getPod(client kubernetes.Interface, namespace, name string) (*corev1.Pod, error) {
+	var err error
+	for i := 0; i < podLookupAttempts; i++ {
+		var pod *corev1.Pod
+		if pod, err = client.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{}); err == nil {
+			return pod, nil
+		}
+		time.Sleep(podLookupInterval)
+	}
+	return nil, fmt.Errorf("failed to get pod %s/%s: %v", namespace, name, err)
+}
@@ -158,7 +158,8 @@ func serverCmd() *cobra.Command {
 	svr.PersistentFlags().BoolVar(&serverArgs.WatchConfigFiles, "watchConfigFiles", serverArgs.WatchConfigFiles,
 		"Enable the Fsnotify for watching config source files on the disk and implicit signaling on a config change. Explicit signaling will still be enabled")
 	svr.PersistentFlags().BoolVar(&serverArgs.EnableConfigAnalysis, "enableAnalysis", serverArgs.EnableConfigAnalysis,
-		"Enable config analysis service")
+		"Enable config analysis service, writing its findings to the status of the analyzed resources. "+
+			"Only the elected leader of the Galley replicas writes them.")
 
 	// validation config
 	svr.PersistentFlags().StringVar(&serverArgs.ValidationArgs.WebhookConfigFile,
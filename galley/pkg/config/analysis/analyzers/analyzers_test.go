@@ -25,6 +25,7 @@ import (
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/annotations"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/auth"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/deprecation"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/destinationrule"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/gateway"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/injection"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/sidecar"
@@ -100,6 +101,24 @@ var testGrid = []testCase{
 			{msg.Deprecated, "ServiceRoleBinding bind-mongodb-viewer.default"},
 		},
 	},
+	{
+		name:       "destinationRuleConflictingTLSModes",
+		inputFiles: []string{"testdata/destinationrule-conflicting-tls-modes.yaml"},
+		analyzer:   &destinationrule.ConflictingTLSModesAnalyzer{},
+		expected: []message{
+			{msg.ConflictingDestinationRuleTLSModes, "DestinationRule reviews-mtls.default"},
+			{msg.ConflictingDestinationRuleTLSModes, "DestinationRule reviews-disable.default"},
+		},
+	},
+	{
+		name:       "gatewayConflictingServers",
+		inputFiles: []string{"testdata/gateway-conflicting-servers.yaml"},
+		analyzer:   &gateway.ConflictingServersAnalyzer{},
+		expected: []message{
+			{msg.ConflictingGatewayServers, "Gateway bookinfo-gateway.default"},
+			{msg.ConflictingGatewayServers, "Gateway team-gateway.team"},
+		},
+	},
 	{
 		name:       "gatewayNoWorkload",
 		inputFiles: []string{"testdata/gateway-no-workload.yaml"},
@@ -188,6 +207,15 @@ var testGrid = []testCase{
 			{msg.ConflictingSidecarWorkloadSelectors, "Sidecar overlap-2.default"},
 		},
 	},
+	{
+		name:       "virtualServiceConflictingGatewayHosts",
+		inputFiles: []string{"testdata/virtualservice_conflictinggatewayhosts.yaml"},
+		analyzer:   &virtualservice.ConflictingGatewayHostsAnalyzer{},
+		expected: []message{
+			{msg.ConflictingGatewayVirtualServiceHosts, "VirtualService productpage.default"},
+			{msg.ConflictingGatewayVirtualServiceHosts, "VirtualService productpage-v2.team"},
+		},
+	},
 	{
 		name:       "virtualServiceConflictingMeshGatewayHosts",
 		inputFiles: []string{"testdata/virtualservice_conflictingmeshgatewayhosts.yaml"},
@@ -230,6 +258,15 @@ var testGrid = []testCase{
 			{msg.ReferencedResourceNotFound, "VirtualService httpbin-bogus"},
 		},
 	},
+	{
+		name:       "virtualServiceGatewayBinding",
+		inputFiles: []string{"testdata/virtualservice_gatewaybinding.yaml"},
+		analyzer:   &virtualservice.GatewayBindingAnalyzer{},
+		expected: []message{
+			{msg.GatewayBindingNotAllowed, "VirtualService other-listed.other"},
+			{msg.GatewayBindingNotAllowed, "VirtualService other-selector.other"},
+		},
+	},
 }
 
 // regex patterns for analyzer names that should be explicitly ignored for testing
@@ -24,6 +24,7 @@ import (
 	"istio.io/istio/galley/pkg/config/meta/metadata"
 	"istio.io/istio/galley/pkg/config/meta/schema/collection"
 	"istio.io/istio/galley/pkg/config/resource"
+	"istio.io/istio/pkg/config/constants"
 )
 
 // K8sAnalyzer checks for misplayed and invalid Istio annotations in K8s resources
@@ -79,6 +80,123 @@ var (
 		&annotation.SidecarTrafficIncludeInboundPorts,
 		&annotation.SidecarTrafficIncludeOutboundIPRanges,
 		&annotation.SidecarTrafficKubevirtInterfaces,
+		&sidecarTraceSampling,
+		&sidecarNative,
+		&sidecarConcurrency,
+		&sidecarDrainDuration,
+		&sidecarParentShutdownDuration,
+		&proxyConfig,
+		&sidecarMaxDownstreamConnections,
+		&sidecarMaxInboundConnections,
+		&sidecarMaxOutboundConnections,
+		&sidecarOverloadMaxHeapSize,
+		&sidecarOverloadShrinkHeap,
+		&sidecarOverloadStopConnections,
+		&sidecarHTTPDrainTimeout,
+	}
+
+	// sidecarTraceSampling is not yet part of the Istio annotation API.
+	sidecarTraceSampling = annotation.Instance{
+		Name:        constants.SidecarTraceSamplingAnnotation,
+		Description: "Specifies the percentage of requests traced by the sidecar, overriding the mesh default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarNative is not yet part of the Istio annotation API.
+	sidecarNative = annotation.Instance{
+		Name:        constants.SidecarNativeAnnotation,
+		Description: "Specifies whether the sidecar is injected as a native sidecar container, overriding the injector default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarConcurrency is not yet part of the Istio annotation API.
+	sidecarConcurrency = annotation.Instance{
+		Name:        constants.SidecarConcurrencyAnnotation,
+		Description: "Specifies the number of worker threads of the sidecar, overriding the proxy config default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarDrainDuration is not yet part of the Istio annotation API.
+	sidecarDrainDuration = annotation.Instance{
+		Name:        constants.SidecarDrainDurationAnnotation,
+		Description: "Specifies how long the sidecar drains connections, overriding the proxy config default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarParentShutdownDuration is not yet part of the Istio annotation API.
+	sidecarParentShutdownDuration = annotation.Instance{
+		Name:        constants.SidecarParentShutdownDurationAnnotation,
+		Description: "Specifies when the sidecar terminates its previous instance on hot restart, overriding the proxy config default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// proxyConfig is not yet part of the Istio annotation API.
+	proxyConfig = annotation.Instance{
+		Name:        constants.ProxyConfigAnnotation,
+		Description: "Specifies a ProxyConfig in YAML whose fields override the proxy config defaults of the mesh for the sidecar.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarMaxDownstreamConnections is not yet part of the Istio annotation API.
+	sidecarMaxDownstreamConnections = annotation.Instance{
+		Name:        constants.SidecarMaxDownstreamConnectionsAnnotation,
+		Description: "Specifies the maximum number of connections accepted by all the listeners of the sidecar.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarMaxInboundConnections is not yet part of the Istio annotation API.
+	sidecarMaxInboundConnections = annotation.Instance{
+		Name:        constants.SidecarMaxInboundConnectionsAnnotation,
+		Description: "Specifies the maximum number of connections accepted by the inbound listener of the sidecar.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarMaxOutboundConnections is not yet part of the Istio annotation API.
+	sidecarMaxOutboundConnections = annotation.Instance{
+		Name:        constants.SidecarMaxOutboundConnectionsAnnotation,
+		Description: "Specifies the maximum number of connections accepted by the outbound listener of the sidecar.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarOverloadMaxHeapSize is not yet part of the Istio annotation API.
+	sidecarOverloadMaxHeapSize = annotation.Instance{
+		Name:        constants.SidecarOverloadMaxHeapSizeAnnotation,
+		Description: "Specifies the maximum heap size of the sidecar, enabling its overload manager, overriding the mesh default.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarOverloadShrinkHeap is not yet part of the Istio annotation API.
+	sidecarOverloadShrinkHeap = annotation.Instance{
+		Name:        constants.SidecarOverloadShrinkHeapAnnotation,
+		Description: "Specifies the percentage of the maximum heap size at which the sidecar returns its free memory to the system.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarOverloadStopConnections is not yet part of the Istio annotation API.
+	sidecarOverloadStopConnections = annotation.Instance{
+		Name:        constants.SidecarOverloadStopConnectionsAnnotation,
+		Description: "Specifies the percentage of the maximum heap size at which the sidecar stops accepting new connections.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// sidecarHTTPDrainTimeout is not yet part of the Istio annotation API.
+	sidecarHTTPDrainTimeout = annotation.Instance{
+		Name:        constants.SidecarHTTPDrainTimeoutAnnotation,
+		Description: "Specifies the time the HTTP/2 connections of a drained listener of the sidecar are given to complete their requests.",
+		Resources:   []annotation.ResourceTypes{annotation.Pod},
+	}
+
+	// namespaceProxyDefaults are the pod annotations a namespace can carry to set the default of the
+	// pods injected in it, see istio.io/istio/pkg/kube/inject.
+	namespaceProxyDefaults = map[string]bool{
+		annotation.SidecarProxyCPU.Name:                   true,
+		annotation.SidecarProxyMemory.Name:                true,
+		annotation.SidecarLogLevel.Name:                   true,
+		annotation.SidecarComponentLogLevel.Name:          true,
+		annotation.SidecarInterceptionMode.Name:           true,
+		constants.SidecarConcurrencyAnnotation:            true,
+		constants.SidecarDrainDurationAnnotation:          true,
+		constants.SidecarParentShutdownDurationAnnotation: true,
 	}
 
 	// Currently we don't have an Istio API that enumerates Istio annotations ResourceTypes
@@ -166,6 +284,10 @@ outer:
 			}
 		}
 
+		if kind == "Namespace" && namespaceProxyDefaults[ann] {
+			continue
+		}
+
 		attachesTo := resourceTypesAsStrings(annotationDef.Resources)
 		if !contains(attachesTo, kind) {
 			ctx.Report(collectionType,
@@ -0,0 +1,97 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package destinationrule
+
+import (
+	"sort"
+	"strings"
+
+	"istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+)
+
+// ConflictingTLSModesAnalyzer checks if multiple DestinationRules for the same host
+// set different TLS modes in their top level traffic policy. The DestinationRules of
+// a host are merged, and only the traffic policy of one of them is used.
+type ConflictingTLSModesAnalyzer struct{}
+
+var _ analysis.Analyzer = &ConflictingTLSModesAnalyzer{}
+
+// Metadata implements Analyzer
+func (c *ConflictingTLSModesAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name: "destinationrule.ConflictingTLSModesAnalyzer",
+		Inputs: collection.Names{
+			metadata.IstioNetworkingV1Alpha3Destinationrules,
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (c *ConflictingTLSModesAnalyzer) Analyze(ctx analysis.Context) {
+	var hosts []util.ScopedFqdn
+	rulesByHost := map[util.ScopedFqdn][]*resource.Entry{}
+	ctx.ForEach(metadata.IstioNetworkingV1Alpha3Destinationrules, func(r *resource.Entry) bool {
+		dr := r.Item.(*v1alpha3.DestinationRule)
+		if dr.GetTrafficPolicy().GetTls() == nil {
+			return true
+		}
+		drNamespace, _ := r.Metadata.Name.InterpretAsNamespaceAndName()
+		// determine the scope of the host i.e. local to DestinationRule namespace or
+		// all namespaces
+		scope := drNamespace
+		if util.IsExportToAllNamespaces(dr.ExportTo) {
+			scope = util.ExportToAllNamespaces
+		}
+		host := util.NewScopedFqdn(scope, drNamespace, dr.Host)
+		if len(rulesByHost[host]) == 0 {
+			hosts = append(hosts, host)
+		}
+		rulesByHost[host] = append(rulesByHost[host], r)
+		return true
+	})
+
+	for _, host := range hosts {
+		rules := rulesByHost[host]
+		modes := map[string]struct{}{}
+		for _, r := range rules {
+			modes[r.Item.(*v1alpha3.DestinationRule).TrafficPolicy.Tls.Mode.String()] = struct{}{}
+		}
+		if len(modes) < 2 {
+			continue
+		}
+
+		modeNames := make([]string, 0, len(modes))
+		for m := range modes {
+			modeNames = append(modeNames, m)
+		}
+		sort.Strings(modeNames)
+		ruleNames := make([]string, 0, len(rules))
+		for _, r := range rules {
+			ruleNames = append(ruleNames, r.Metadata.Name.String())
+		}
+		_, fqdn := host.GetScopeAndFqdn()
+		for _, r := range rules {
+			ctx.Report(metadata.IstioNetworkingV1Alpha3Destinationrules,
+				msg.NewConflictingDestinationRuleTLSModes(r, strings.Join(ruleNames, ","), fqdn, strings.Join(modeNames, ",")))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"strings"
+
+	k8s_labels "k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+)
+
+// ConflictingServersAnalyzer checks if Gateways selecting the same workloads declare the
+// same host on the same port. Only one of the servers is used if conflicts exist.
+type ConflictingServersAnalyzer struct{}
+
+var _ analysis.Analyzer = &ConflictingServersAnalyzer{}
+
+// serverKey identifies the host of a port on the workloads selected by a Gateway.
+type serverKey struct {
+	selector string
+	port     uint32
+	host     string
+}
+
+// Metadata implements analysis.Analyzer
+func (*ConflictingServersAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name: "gateway.ConflictingServersAnalyzer",
+		Inputs: collection.Names{
+			metadata.IstioNetworkingV1Alpha3Gateways,
+		},
+	}
+}
+
+// Analyze implements analysis.Analyzer
+func (*ConflictingServersAnalyzer) Analyze(c analysis.Context) {
+	var keys []serverKey
+	gateways := map[serverKey][]*resource.Entry{}
+	c.ForEach(metadata.IstioNetworkingV1Alpha3Gateways, func(r *resource.Entry) bool {
+		gw := r.Item.(*v1alpha3.Gateway)
+		selector := k8s_labels.SelectorFromSet(gw.Selector).String()
+		for _, server := range gw.Servers {
+			if server.Port == nil {
+				continue
+			}
+			for _, h := range server.Hosts {
+				// The namespace of a host restricts the VirtualServices bound to it, not the traffic it matches.
+				if i := strings.Index(h, "/"); i >= 0 {
+					h = h[i+1:]
+				}
+				key := serverKey{selector: selector, port: server.Port.Number, host: h}
+				entries := gateways[key]
+				if len(entries) > 0 && entries[len(entries)-1] == r {
+					// A Gateway declaring a host twice is rejected by its validation.
+					continue
+				}
+				if len(entries) == 0 {
+					keys = append(keys, key)
+				}
+				gateways[key] = append(entries, r)
+			}
+		}
+		return true
+	})
+
+	for _, key := range keys {
+		entries := gateways[key]
+		if len(entries) < 2 {
+			continue
+		}
+		names := make([]string, 0, len(entries))
+		for _, r := range entries {
+			names = append(names, r.Metadata.Name.String())
+		}
+		for _, r := range entries {
+			c.Report(metadata.IstioNetworkingV1Alpha3Gateways,
+				msg.NewConflictingGatewayServers(r, strings.Join(names, ","), key.host, int(key.port)))
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualservice
+
+import (
+	"istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+)
+
+// ConflictingGatewayHostsAnalyzer checks if multiple VirtualServices associated
+// with the same gateway, other than the mesh gateway, have conflicting hosts.
+// The routes of only one of them may apply if conflicts exist.
+type ConflictingGatewayHostsAnalyzer struct{}
+
+var _ analysis.Analyzer = &ConflictingGatewayHostsAnalyzer{}
+
+// gatewayHost is a host of the VirtualServices bound to a gateway.
+type gatewayHost struct {
+	gateway string
+	host    string
+}
+
+// Metadata implements Analyzer
+func (c *ConflictingGatewayHostsAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name: "virtualservice.ConflictingGatewayHostsAnalyzer",
+		Inputs: collection.Names{
+			metadata.IstioNetworkingV1Alpha3Virtualservices,
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (c *ConflictingGatewayHostsAnalyzer) Analyze(ctx analysis.Context) {
+	var keys []gatewayHost
+	hostsVirtualServices := map[gatewayHost][]*resource.Entry{}
+	ctx.ForEach(metadata.IstioNetworkingV1Alpha3Virtualservices, func(r *resource.Entry) bool {
+		vs := r.Item.(*v1alpha3.VirtualService)
+		vsNamespace, _ := r.Metadata.Name.InterpretAsNamespaceAndName()
+		for _, g := range vs.Gateways {
+			if g == util.MeshGateway {
+				continue
+			}
+			gateway := resource.NewShortOrFullName(vsNamespace, g).String()
+			for _, h := range vs.Hosts {
+				key := gatewayHost{gateway: gateway, host: util.ConvertHostToFQDN(vsNamespace, h)}
+				if len(hostsVirtualServices[key]) == 0 {
+					keys = append(keys, key)
+				}
+				hostsVirtualServices[key] = append(hostsVirtualServices[key], r)
+			}
+		}
+		return true
+	})
+
+	for _, key := range keys {
+		vsList := hostsVirtualServices[key]
+		if len(vsList) > 1 {
+			vsNames := combineResourceEntryNames(vsList)
+			for i := range vsList {
+				ctx.Report(metadata.IstioNetworkingV1Alpha3Virtualservices,
+					msg.NewConflictingGatewayVirtualServiceHosts(vsList[i], vsNames, key.gateway, key.host))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualservice
+
+import (
+	"istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/meta/metadata"
+	"istio.io/istio/galley/pkg/config/meta/schema/collection"
+	"istio.io/istio/galley/pkg/config/resource"
+	"istio.io/istio/pkg/config/gateway"
+)
+
+// GatewayBindingAnalyzer checks that the gateways the VirtualServices bind to allow the VirtualServices of
+// their namespace, when restricted by the annotations of the gateways. The routes of the VirtualServices
+// not allowed are not applied to the gateway.
+type GatewayBindingAnalyzer struct{}
+
+var _ analysis.Analyzer = &GatewayBindingAnalyzer{}
+
+// Metadata implements Analyzer
+func (s *GatewayBindingAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name: "virtualservice.GatewayBindingAnalyzer",
+		Inputs: collection.Names{
+			metadata.IstioNetworkingV1Alpha3Gateways,
+			metadata.IstioNetworkingV1Alpha3Virtualservices,
+			metadata.K8SCoreV1Namespaces,
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (s *GatewayBindingAnalyzer) Analyze(c analysis.Context) {
+	c.ForEach(metadata.IstioNetworkingV1Alpha3Virtualservices, func(r *resource.Entry) bool {
+		s.analyzeVirtualService(r, c)
+		return true
+	})
+}
+
+func (s *GatewayBindingAnalyzer) analyzeVirtualService(r *resource.Entry, c analysis.Context) {
+	vs := r.Item.(*v1alpha3.VirtualService)
+
+	vsNs, _ := r.Metadata.Name.InterpretAsNamespaceAndName()
+	var nsLabels map[string]string
+	if ns := c.Find(metadata.K8SCoreV1Namespaces, resource.NewName("", vsNs)); ns != nil {
+		nsLabels = ns.Metadata.Labels
+	}
+	for _, gwName := range vs.Gateways {
+		if gwName == util.MeshGateway {
+			continue
+		}
+
+		name := resource.NewShortOrFullName(vsNs, gwName)
+		gw := c.Find(metadata.IstioNetworkingV1Alpha3Gateways, name)
+		if gw == nil {
+			// reported by the GatewayAnalyzer
+			continue
+		}
+		gwNs, _ := name.InterpretAsNamespaceAndName()
+		// an invalid selector allows no other namespace
+		policy, _ := gateway.NewBindingPolicy(gwNs, gw.Metadata.Annotations)
+		if !policy.Allows(vsNs, nsLabels) {
+			c.Report(metadata.IstioNetworkingV1Alpha3Virtualservices, msg.NewGatewayBindingNotAllowed(r, name.String(), vsNs))
+		}
+	}
+}
@@ -64,6 +64,22 @@ var (
 	// VirtualServiceDestinationPortSelectorRequired defines a diag.MessageType for message "VirtualServiceDestinationPortSelectorRequired".
 	// Description: A VirtualService routes to a service with more than one port exposed, but does not specify which to use.
 	VirtualServiceDestinationPortSelectorRequired = diag.NewMessageType(diag.Error, "IST0112", "This VirtualService routes to a service %q that exposes multiple ports %v. Specifying a port in the destination is required to disambiguate.")
+
+	// ConflictingGatewayServers defines a diag.MessageType for message "ConflictingGatewayServers".
+	// Description: Servers of Gateways selecting the same workloads declare the same host on the same port
+	ConflictingGatewayServers = diag.NewMessageType(diag.Error, "IST0113", "The Gateways %s select the same workloads and declare the host %s on port %d, so only one of their servers is used. This can be fixed by declaring the host in a single Gateway.")
+
+	// ConflictingGatewayVirtualServiceHosts defines a diag.MessageType for message "ConflictingGatewayVirtualServiceHosts".
+	// Description: Conflicting hosts on VirtualServices associated with the same gateway
+	ConflictingGatewayVirtualServiceHosts = diag.NewMessageType(diag.Error, "IST0114", "The VirtualServices %s associated with gateway %s define the same host %s, so the routes of only one of them may apply. This can be fixed by merging the conflicting VirtualServices into a single resource.")
+
+	// ConflictingDestinationRuleTLSModes defines a diag.MessageType for message "ConflictingDestinationRuleTLSModes".
+	// Description: DestinationRules for the same host set conflicting TLS modes
+	ConflictingDestinationRuleTLSModes = diag.NewMessageType(diag.Error, "IST0115", "The DestinationRules %s for host %s set the conflicting TLS modes %s, so only one of them is used. This can be fixed by setting the TLS mode in a single DestinationRule.")
+
+	// GatewayBindingNotAllowed defines a diag.MessageType for message "GatewayBindingNotAllowed".
+	// Description: A VirtualService binds to a Gateway which does not allow the VirtualServices of its namespace
+	GatewayBindingNotAllowed = diag.NewMessageType(diag.Error, "IST0116", "The gateway %s does not allow the VirtualServices of namespace %s to bind to it, so the routes of this VirtualService are not applied to it. This can be fixed by allowing the namespace in the gateway.istio.io/allowed-namespaces or gateway.istio.io/allowed-namespace-selector annotation of the gateway.")
 )
 
 // NewInternalError returns a new diag.Message based on InternalError.
@@ -203,6 +219,49 @@ func NewVirtualServiceDestinationPortSelectorRequired(entry *resource.Entry, des
 	)
 }
 
+// NewConflictingGatewayServers returns a new diag.Message based on ConflictingGatewayServers.
+func NewConflictingGatewayServers(entry *resource.Entry, gateways string, host string, port int) diag.Message {
+	return diag.NewMessage(
+		ConflictingGatewayServers,
+		originOrNil(entry),
+		gateways,
+		host,
+		port,
+	)
+}
+
+// NewConflictingGatewayVirtualServiceHosts returns a new diag.Message based on ConflictingGatewayVirtualServiceHosts.
+func NewConflictingGatewayVirtualServiceHosts(entry *resource.Entry, virtualServices string, gateway string, host string) diag.Message {
+	return diag.NewMessage(
+		ConflictingGatewayVirtualServiceHosts,
+		originOrNil(entry),
+		virtualServices,
+		gateway,
+		host,
+	)
+}
+
+// NewConflictingDestinationRuleTLSModes returns a new diag.Message based on ConflictingDestinationRuleTLSModes.
+func NewConflictingDestinationRuleTLSModes(entry *resource.Entry, destinationRules string, host string, modes string) diag.Message {
+	return diag.NewMessage(
+		ConflictingDestinationRuleTLSModes,
+		originOrNil(entry),
+		destinationRules,
+		host,
+		modes,
+	)
+}
+
+// NewGatewayBindingNotAllowed returns a new diag.Message based on GatewayBindingNotAllowed.
+func NewGatewayBindingNotAllowed(entry *resource.Entry, gateway string, namespace string) diag.Message {
+	return diag.NewMessage(
+		GatewayBindingNotAllowed,
+		originOrNil(entry),
+		gateway,
+		namespace,
+	)
+}
+
 func originOrNil(e *resource.Entry) resource.Origin {
 	var o resource.Origin
 	if e != nil {
@@ -150,6 +150,12 @@ var (
 	// IstioNetworkingV1Alpha3Virtualservices is the name of collection istio/networking/v1alpha3/virtualservices
 	IstioNetworkingV1Alpha3Virtualservices = collection.NewName("istio/networking/v1alpha3/virtualservices")
 
+	// IstioNetworkingV1Alpha3Workloadentries is the name of collection istio/networking/v1alpha3/workloadentries
+	IstioNetworkingV1Alpha3Workloadentries = collection.NewName("istio/networking/v1alpha3/workloadentries")
+
+	// IstioNetworkingV1Alpha3Workloadgroups is the name of collection istio/networking/v1alpha3/workloadgroups
+	IstioNetworkingV1Alpha3Workloadgroups = collection.NewName("istio/networking/v1alpha3/workloadgroups")
+
 	// IstioPolicyV1Beta1Attributemanifests is the name of collection istio/policy/v1beta1/attributemanifests
 	IstioPolicyV1Beta1Attributemanifests = collection.NewName("istio/policy/v1beta1/attributemanifests")
 
@@ -177,6 +183,9 @@ var (
 	// IstioSecurityV1Beta1Authorizationpolicies is the name of collection istio/security/v1beta1/authorizationpolicies
 	IstioSecurityV1Beta1Authorizationpolicies = collection.NewName("istio/security/v1beta1/authorizationpolicies")
 
+	// IstioTelemetryV1Alpha1Telemetries is the name of collection istio/telemetry/v1alpha1/telemetries
+	IstioTelemetryV1Alpha1Telemetries = collection.NewName("istio/telemetry/v1alpha1/telemetries")
+
 	// K8SAppsV1Deployments is the name of collection k8s/apps/v1/deployments
 	K8SAppsV1Deployments = collection.NewName("k8s/apps/v1/deployments")
 
@@ -345,6 +354,12 @@ var (
 	// K8SNetworkingIstioIoV1Alpha3Virtualservices is the name of collection k8s/networking.istio.io/v1alpha3/virtualservices
 	K8SNetworkingIstioIoV1Alpha3Virtualservices = collection.NewName("k8s/networking.istio.io/v1alpha3/virtualservices")
 
+	// K8SNetworkingIstioIoV1Alpha3Workloadentries is the name of collection k8s/networking.istio.io/v1alpha3/workloadentries
+	K8SNetworkingIstioIoV1Alpha3Workloadentries = collection.NewName("k8s/networking.istio.io/v1alpha3/workloadentries")
+
+	// K8SNetworkingIstioIoV1Alpha3Workloadgroups is the name of collection k8s/networking.istio.io/v1alpha3/workloadgroups
+	K8SNetworkingIstioIoV1Alpha3Workloadgroups = collection.NewName("k8s/networking.istio.io/v1alpha3/workloadgroups")
+
 	// K8SRbacIstioIoV1Alpha1Clusterrbacconfigs is the name of collection k8s/rbac.istio.io/v1alpha1/clusterrbacconfigs
 	K8SRbacIstioIoV1Alpha1Clusterrbacconfigs = collection.NewName("k8s/rbac.istio.io/v1alpha1/clusterrbacconfigs")
 
@@ -359,6 +374,9 @@ var (
 
 	// K8SSecurityIstioIoV1Beta1Authorizationpolicies is the name of collection k8s/security.istio.io/v1beta1/authorizationpolicies
 	K8SSecurityIstioIoV1Beta1Authorizationpolicies = collection.NewName("k8s/security.istio.io/v1beta1/authorizationpolicies")
+
+	// K8STelemetryIstioIoV1Alpha1Telemetries is the name of collection k8s/telemetry.istio.io/v1alpha1/telemetries
+	K8STelemetryIstioIoV1Alpha1Telemetries = collection.NewName("k8s/telemetry.istio.io/v1alpha1/telemetries")
 )
 
 // CollectionNames returns the collection names declared in this package.
@@ -411,6 +429,8 @@ func CollectionNames() []collection.Name {
 		IstioNetworkingV1Alpha3Sidecars,
 		IstioNetworkingV1Alpha3SyntheticServiceentries,
 		IstioNetworkingV1Alpha3Virtualservices,
+		IstioNetworkingV1Alpha3Workloadentries,
+		IstioNetworkingV1Alpha3Workloadgroups,
 		IstioPolicyV1Beta1Attributemanifests,
 		IstioPolicyV1Beta1Handlers,
 		IstioPolicyV1Beta1Instances,
@@ -420,6 +440,7 @@ func CollectionNames() []collection.Name {
 		IstioRbacV1Alpha1Servicerolebindings,
 		IstioRbacV1Alpha1Serviceroles,
 		IstioSecurityV1Beta1Authorizationpolicies,
+		IstioTelemetryV1Alpha1Telemetries,
 		K8SAppsV1Deployments,
 		K8SAuthenticationIstioIoV1Alpha1Meshpolicies,
 		K8SAuthenticationIstioIoV1Alpha1Policies,
@@ -476,10 +497,13 @@ func CollectionNames() []collection.Name {
 		K8SNetworkingIstioIoV1Alpha3Serviceentries,
 		K8SNetworkingIstioIoV1Alpha3Sidecars,
 		K8SNetworkingIstioIoV1Alpha3Virtualservices,
+		K8SNetworkingIstioIoV1Alpha3Workloadentries,
+		K8SNetworkingIstioIoV1Alpha3Workloadgroups,
 		K8SRbacIstioIoV1Alpha1Clusterrbacconfigs,
 		K8SRbacIstioIoV1Alpha1Policy,
 		K8SRbacIstioIoV1Alpha1Rbacconfigs,
 		K8SRbacIstioIoV1Alpha1Serviceroles,
 		K8SSecurityIstioIoV1Beta1Authorizationpolicies,
+		K8STelemetryIstioIoV1Alpha1Telemetries,
 	}
 }
@@ -122,6 +122,14 @@ collections:
     proto: "istio.networking.v1alpha3.ServiceEntry"
     protoPackage: "istio.io/api/networking/v1alpha3"
 
+  - name: "istio/networking/v1alpha3/workloadentries"
+    proto: "istio.networking.v1alpha3.WorkloadEntry"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
+  - name: "istio/networking/v1alpha3/workloadgroups"
+    proto: "istio.networking.v1alpha3.WorkloadGroup"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
   - name: "istio/networking/v1alpha3/sidecars"
     proto: "istio.networking.v1alpha3.Sidecar"
     protoPackage: "istio.io/api/networking/v1alpha3"
@@ -166,6 +174,10 @@ collections:
     proto: "istio.security.v1beta1.AuthorizationPolicy"
     protoPackage: "istio.io/api/security/v1beta1"
 
+  - name: "istio/telemetry/v1alpha1/telemetries"
+    proto: "istio.telemetry.v1alpha1.Telemetry"
+    protoPackage: "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+
   ### K8s collections ###
 
   # Built-in K8s collections
@@ -256,6 +268,14 @@ collections:
     proto: "istio.networking.v1alpha3.ServiceEntry"
     protoPackage: "istio.io/api/networking/v1alpha3"
 
+  - name: "k8s/networking.istio.io/v1alpha3/workloadentries"
+    proto: "istio.networking.v1alpha3.WorkloadEntry"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
+  - name: "k8s/networking.istio.io/v1alpha3/workloadgroups"
+    proto: "istio.networking.v1alpha3.WorkloadGroup"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
   - name: "k8s/networking.istio.io/v1alpha3/sidecars"
     proto: "istio.networking.v1alpha3.Sidecar"
     protoPackage: "istio.io/api/networking/v1alpha3"
@@ -288,6 +308,10 @@ collections:
     proto: "istio.security.v1beta1.AuthorizationPolicy"
     protoPackage: "istio.io/api/security/v1beta1"
 
+  - name: "k8s/telemetry.istio.io/v1alpha1/telemetries"
+    proto: "istio.telemetry.v1alpha1.Telemetry"
+    protoPackage: "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+
     # Keep Legacy Mixer CRD related collections separate, as these will be gone soon.
   - name: "k8s/config.istio.io/v1alpha2/apikeys"
     proto: "google.protobuf.Struct"
@@ -559,6 +583,8 @@ snapshots:
       - "istio/networking/v1alpha3/serviceentries"
       - "istio/networking/v1alpha3/sidecars"
       - "istio/networking/v1alpha3/virtualservices"
+      - "istio/networking/v1alpha3/workloadentries"
+      - "istio/networking/v1alpha3/workloadgroups"
       - "istio/policy/v1beta1/attributemanifests"
       - "istio/policy/v1beta1/handlers"
       - "istio/policy/v1beta1/instances"
@@ -568,6 +594,7 @@ snapshots:
       - "istio/rbac/v1alpha1/servicerolebindings"
       - "istio/rbac/v1alpha1/serviceroles"
       - "istio/security/v1beta1/authorizationpolicies"
+      - "istio/telemetry/v1alpha1/telemetries"
       - "k8s/core/v1/namespaces"
       - "k8s/core/v1/services"
 
@@ -691,6 +718,18 @@ sources:
       group: "networking.istio.io"
       version: "v1alpha3"
 
+    - collection: "k8s/networking.istio.io/v1alpha3/workloadentries"
+      kind: "WorkloadEntry"
+      plural: "workloadentries"
+      group: "networking.istio.io"
+      version: "v1alpha3"
+
+    - collection: "k8s/networking.istio.io/v1alpha3/workloadgroups"
+      kind: "WorkloadGroup"
+      plural: "workloadgroups"
+      group: "networking.istio.io"
+      version: "v1alpha3"
+
     - collection: "k8s/networking.istio.io/v1alpha3/destinationrules"
       kind: "DestinationRule"
       plural: "destinationrules"
@@ -777,6 +816,12 @@ sources:
       group: "security.istio.io"
       version: "v1beta1"
 
+    - collection: "k8s/telemetry.istio.io/v1alpha1/telemetries"
+      kind: "Telemetry"
+      plural: "telemetries"
+      group: "telemetry.istio.io"
+      version: "v1alpha1"
+
     - collection: "k8s/config.istio.io/v1alpha2/rules"
       kind: "rule"
       plural: "rules"
@@ -1026,11 +1071,14 @@ transforms:
       "k8s/networking.istio.io/v1alpha3/serviceentries": "istio/networking/v1alpha3/serviceentries"
       "k8s/networking.istio.io/v1alpha3/sidecars": "istio/networking/v1alpha3/sidecars"
       "k8s/networking.istio.io/v1alpha3/virtualservices": "istio/networking/v1alpha3/virtualservices"
+      "k8s/networking.istio.io/v1alpha3/workloadentries": "istio/networking/v1alpha3/workloadentries"
+      "k8s/networking.istio.io/v1alpha3/workloadgroups": "istio/networking/v1alpha3/workloadgroups"
       "k8s/rbac.istio.io/v1alpha1/policy": "istio/rbac/v1alpha1/servicerolebindings"
       "k8s/rbac.istio.io/v1alpha1/rbacconfigs": "istio/rbac/v1alpha1/rbacconfigs"
       "k8s/rbac.istio.io/v1alpha1/clusterrbacconfigs": "istio/rbac/v1alpha1/clusterrbacconfigs"
       "k8s/rbac.istio.io/v1alpha1/serviceroles": "istio/rbac/v1alpha1/serviceroles"
       "k8s/security.istio.io/v1beta1/authorizationpolicies": "istio/security/v1beta1/authorizationpolicies"
+      "k8s/telemetry.istio.io/v1alpha1/telemetries": "istio/telemetry/v1alpha1/telemetries"
       "k8s/core/v1/namespaces": "k8s/core/v1/namespaces"
       "k8s/core/v1/services": "k8s/core/v1/services"
       "k8s/core/v1/pods": "k8s/core/v1/pods"
@@ -30,6 +30,12 @@ import (
 	// Register protos in "istio.io/api/security/v1beta1"
 	_ "istio.io/api/security/v1beta1"
 
+	// Register protos in "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	_ "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
+	// Register protos in "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	_ "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+
 	// Register protos in "k8s.io/api/apps/v1"
 	_ "k8s.io/api/apps/v1"
 
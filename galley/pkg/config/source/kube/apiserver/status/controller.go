@@ -50,6 +50,9 @@ type ControllerImpl struct {
 
 	// Subfield of status that this controller manages
 	subfield string
+
+	// Whether this controller updates the status fields. See SetLeading.
+	leading bool
 }
 
 var _ Controller = &ControllerImpl{}
@@ -58,6 +61,7 @@ var _ Controller = &ControllerImpl{}
 func NewController(subfield string) *ControllerImpl {
 	return &ControllerImpl{
 		subfield: subfield,
+		leading:  true,
 	}
 }
 
@@ -70,6 +74,7 @@ func (c *ControllerImpl) Start(p *rt.Provider, resources []schema.KubeResource)
 		return
 	}
 	c.state = newState()
+	c.state.setLeading(c.leading)
 
 	ifaces := make(map[collection.Name]dynamic.NamespaceableResourceInterface)
 	for _, r := range resources {
@@ -99,6 +104,18 @@ func (c *ControllerImpl) Stop() {
 	}
 }
 
+// SetLeading starts or stops the updates of the status fields. When several replicas of Galley run, only the
+// leader updates them, so that the replicas do not race to write the same fields. The controller keeps tracking
+// the desired and observed status while it is not leading, and reconciles them as soon as it starts leading.
+func (c *ControllerImpl) SetLeading(leading bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.leading = leading
+	if c.state != nil {
+		c.state.setLeading(leading)
+	}
+}
+
 // UpdateResourceStatus is called by the source to relay the currently observed status of a resource.
 func (c *ControllerImpl) UpdateResourceStatus(
 	col collection.Name, name resource.Name, version resource.Version, status interface{}) {
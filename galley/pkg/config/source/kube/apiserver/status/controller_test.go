@@ -385,3 +385,33 @@ func setupClientWithReactors(retVal runtime.Object, updateErrVal error) (*mock.K
 
 	return k, cl
 }
+
+func TestBasicReconcilation_NotLeading(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	c := NewController(subfield)
+	c.SetLeading(false)
+
+	s := map[string]interface{}{
+		subfield: "s1",
+	}
+
+	r := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": s,
+		},
+	}
+
+	k, cl := setupClientWithReactors(r, nil)
+
+	c.Start(rt.NewProvider(k, 0), basicmeta.MustGet().KubeSource().Resources())
+	c.UpdateResourceStatus(basicmeta.Collection1, resource.NewName("foo", "bar"), "v1", s)
+	c.Report(diag.Messages{})
+	defer c.Stop()
+
+	// The status is only reconciled once the controller leads.
+	g.Consistently(cl.Actions).Should(BeEmpty())
+	c.SetLeading(true)
+	g.Eventually(cl.Actions).Should(HaveLen(2))
+	g.Expect(cl.Actions()[1]).To(BeAssignableToTypeOf(k8stesting.UpdateActionImpl{}))
+}
@@ -0,0 +1,80 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"istio.io/istio/galley/pkg/config/scope"
+)
+
+// ElectionID is the name of the config map used to elect the Galley replica updating the status fields.
+const ElectionID = "istio-galley-status-leader"
+
+var leaseDuration = 30 * time.Second
+
+// RunLeaderElection elects, among the replicas of Galley, the one whose controller updates the status fields. The
+// controller must not be leading when called, see SetLeading. RunLeaderElection blocks until the stop channel is
+// closed, running for election again whenever the leadership is lost.
+func RunLeaderElection(stop <-chan struct{}, client kubernetes.Interface, namespace, identity string, c *ControllerImpl) error {
+	lock := &resourcelock.ConfigMapLock{
+		ConfigMapMeta: metav1.ObjectMeta{Namespace: namespace, Name: ElectionID},
+		Client:        client.CoreV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: leaseDuration,
+		RenewDeadline: leaseDuration / 2,
+		RetryPeriod:   leaseDuration / 4,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				scope.Source.Infof("Started leading the status updates as %s", identity)
+				c.SetLeading(true)
+			},
+			OnStoppedLeading: func() {
+				scope.Source.Infof("Stopped leading the status updates as %s", identity)
+				c.SetLeading(false)
+			},
+			OnNewLeader: func(leader string) {
+				scope.Source.Infof("New leader of the status updates: %s", leader)
+			},
+		},
+		ReleaseOnCancel: true,
+		Name:            ElectionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	// Run returns when the leadership is lost: run for election again until stopped.
+	for ctx.Err() == nil {
+		le.Run(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRunLeaderElection(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	defer func(d time.Duration) { leaseDuration = d }(leaseDuration)
+	leaseDuration = 2 * time.Second
+
+	client := fake.NewSimpleClientset()
+	leader := NewController(subfield)
+	leader.SetLeading(false)
+	follower := NewController(subfield)
+	follower.SetLeading(false)
+
+	stopLeader := make(chan struct{})
+	stopFollower := make(chan struct{})
+	defer close(stopFollower)
+	go func() {
+		_ = RunLeaderElection(stopLeader, client, "istio-system", "leader", leader)
+	}()
+	g.Eventually(leader.isLeading).Should(BeTrue())
+
+	go func() {
+		_ = RunLeaderElection(stopFollower, client, "istio-system", "follower", follower)
+	}()
+	g.Consistently(follower.isLeading).Should(BeFalse())
+
+	// The lock is released when the leader stops.
+	close(stopLeader)
+	g.Eventually(leader.isLeading).Should(BeFalse())
+	g.Eventually(follower.isLeading, 2*leaseDuration).Should(BeTrue())
+
+	cm, err := client.CoreV1().ConfigMaps("istio-system").Get(ElectionID, metav1.GetOptions{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cm.Annotations).NotTo(BeEmpty())
+}
+
+func (c *ControllerImpl) isLeading() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leading
+}
@@ -60,6 +60,10 @@ type state struct {
 	// status fields.
 	reconcile bool
 
+	// Indicates that this instance is the one updating the status fields. The work keeps being queued while it is
+	// not, so that the pending updates are performed as soon as the instance starts leading.
+	leading bool
+
 	// quiesce work. Go routine(s) that are blocked on dequeue will wake up and exit.
 	quiesce bool
 
@@ -75,7 +79,8 @@ type state struct {
 
 func newState() *state {
 	s := &state{
-		states: make(map[key]*status),
+		states:  make(map[key]*status),
+		leading: true,
 	}
 	s.available = sync.NewCond(&s.mu)
 
@@ -194,7 +199,7 @@ func (s *state) dequeueWork() (status, bool) {
 			return status{}, false
 		}
 
-		if s.head != nil {
+		if s.head != nil && s.leading {
 			st := s.head
 			s.head = st.next
 			if s.head == sentinel {
@@ -219,6 +224,14 @@ func (s *state) dequeueWork() (status, bool) {
 	}
 }
 
+// setLeading starts or stops the dequeuing of work, depending on whether this instance updates the status fields.
+func (s *state) setLeading(leading bool) {
+	s.mu.Lock()
+	s.leading = leading
+	s.available.Broadcast()
+	s.mu.Unlock()
+}
+
 func (s *state) quiesceWork() {
 	s.mu.Lock()
 	s.quiesce = true
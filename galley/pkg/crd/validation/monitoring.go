@@ -72,6 +72,10 @@ var (
 		"galley/validation/failed",
 		"Resource validation failed",
 		stats.UnitDimensionless)
+	metricValidationWarning = stats.Int64(
+		"galley/validation/warning",
+		"Resource is valid, with warnings",
+		stats.UnitDimensionless)
 	metricValidationHTTPError = stats.Int64(
 		"galley/validation/http_error",
 		"Resource validation http serve errors",
@@ -140,6 +144,7 @@ func init() {
 		newView(metricCertKeyUpdateError, errorKey, view.Count()),
 		newView(metricValidationPassed, resourceKeys, view.Count()),
 		newView(metricValidationFailed, resourceErrorKeys, view.Count()),
+		newView(metricValidationWarning, resourceKeys, view.Count()),
 		newView(metricValidationHTTPError, statusKey, view.Count()),
 		newView(metricWebhookConfigurationUpdateError, errorKey, view.Count()),
 		newView(metricWebhookConfigurationUpdates, noKeys, view.Count()),
@@ -177,6 +182,18 @@ func reportValidationPass(request *admissionv1beta1.AdmissionRequest) {
 	}
 }
 
+func reportValidationWarning(request *admissionv1beta1.AdmissionRequest) {
+	ctx, err := tag.New(context.Background(),
+		tag.Insert(GroupTag, request.Resource.Group),
+		tag.Insert(VersionTag, request.Resource.Version),
+		tag.Insert(ResourceTag, request.Resource.Resource))
+	if err != nil {
+		scope.Errorf("Error creating monitoring context for reportValidationWarning: %v", err)
+	} else {
+		stats.Record(ctx, metricValidationWarning.M(1))
+	}
+}
+
 func reportValidationHTTPError(status int) {
 	ctx, err := tag.New(context.Background(), tag.Insert(StatusTag, strconv.Itoa(status)))
 	if err != nil {
@@ -23,6 +23,9 @@ import (
 	"regexp"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/hashicorp/go-multierror"
@@ -31,6 +34,9 @@ import (
 	"istio.io/pkg/probe"
 
 	mixervalidate "istio.io/istio/mixer/pkg/validate"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	istioschema "istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/kube"
 )
@@ -94,6 +100,11 @@ func RunValidation(ready chan<- struct{}, stopCh chan struct{}, vc *WebhookParam
 	vc.MixerValidator = mixerValidator
 	vc.PilotDescriptor = schemas.Istio
 	vc.Clientset = clientset
+	if vc.ListConfigs == nil {
+		if vc.ListConfigs, err = newConfigLister(kubeConfig, vc.DomainSuffix); err != nil {
+			log.Warnf("cannot list the configs, their references are not checked: %v", err)
+		}
+	}
 	wh, err := NewWebhook(*vc)
 	if err != nil || vc.Clientset == nil {
 		log.Fatalf("cannot create validation webhook service: %v", err)
@@ -202,3 +213,37 @@ func (p *WebhookParameters) Validate() error {
 
 	return errs.ErrorOrNil()
 }
+
+// newConfigLister returns a lister of the Pilot configs stored in the cluster.
+func newConfigLister(kubeConfig, domainSuffix string) (ConfigLister, error) {
+	restConfig, err := kube.BuildClientConfig(kubeConfig, "")
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s istioschema.Instance) ([]model.Config, error) {
+		gvr := schema.GroupVersionResource{
+			Group:    crd.ResourceGroup(&s),
+			Version:  s.Version,
+			Resource: crd.ResourceName(s.Plural),
+		}
+		list, err := client.Resource(gvr).List(metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		configs := make([]model.Config, 0, len(list.Items))
+		for i := range list.Items {
+			cfg, err := crd.ConvertObjectFromUnstructured(s, &list.Items[i], domainSuffix)
+			if err != nil {
+				log.Warnf("cannot convert %s %s/%s: %v", s.Type, list.Items[i].GetNamespace(), list.Items[i].GetName(), err)
+				continue
+			}
+			configs = append(configs, *cfg)
+		}
+		return configs, nil
+	}, nil
+}
@@ -23,10 +23,12 @@ import (
 	"io/ioutil"
 	"net/http"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/ghodss/yaml"
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/howeyc/fsnotify"
 	admissionv1beta1 "k8s.io/api/admission/v1beta1"
 	"k8s.io/api/admissionregistration/v1beta1"
@@ -38,13 +40,22 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 
+	networking "istio.io/api/networking/v1alpha3"
+
 	mixerCrd "istio.io/istio/mixer/pkg/config/crd"
 	"istio.io/istio/mixer/pkg/config/store"
 	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/validation"
 )
 
+// warningsAnnotation is the audit annotation holding the warnings of the accepted configs.
+const warningsAnnotation = "validation.istio.io/warnings"
+
 var (
 	runtimeScheme = runtime.NewScheme()
 	codecs        = serializer.NewCodecFactory(runtimeScheme)
@@ -124,8 +135,16 @@ type WebhookParameters struct {
 
 	// Enable reconcile validatingwebhookconfiguration
 	EnableReconcileWebhookConfiguration bool
+
+	// ListConfigs lists the Pilot configs of a type in all the namespaces. If set, the webhook checks
+	// the references of the configs to the other configs, e.g. the subsets of the destinations of the
+	// virtual services.
+	ListConfigs ConfigLister
 }
 
+// ConfigLister lists the configs of a type in all the namespaces.
+type ConfigLister func(s schema.Instance) ([]model.Config, error)
+
 type createInformerEndpointSource func(cl clientset.Interface, namespace, name string) cache.ListerWatcher
 
 var (
@@ -169,6 +188,7 @@ func DefaultArgs() *WebhookParameters {
 		DeploymentName:                      "istio-galley",
 		ServiceName:                         "istio-galley",
 		WebhookName:                         "istio-galley",
+		DomainSuffix:                        "cluster.local",
 		EnableValidation:                    true,
 		EnableReconcileWebhookConfiguration: true,
 	}
@@ -184,6 +204,7 @@ type Webhook struct {
 	// pilot
 	descriptor   schema.Set
 	domainSuffix string
+	listConfigs  ConfigLister
 
 	// mixer
 	validator store.BackendValidator
@@ -271,6 +292,8 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 		keyCertWatcher:                keyCertWatcher,
 		cert:                          pair,
 		descriptor:                    p.PilotDescriptor,
+		domainSuffix:                  p.DomainSuffix,
+		listConfigs:                   p.ListConfigs,
 		validator:                     p.MixerValidator,
 		clientset:                     p.Clientset,
 		deploymentName:                p.DeploymentName,
@@ -351,6 +374,72 @@ func toAdmissionResponse(err error) *admissionv1beta1.AdmissionResponse {
 	return &admissionv1beta1.AdmissionResponse{Result: &v1.Status{Message: err.Error()}}
 }
 
+// toInvalidResponse rejects an invalid config, with a cause per invalid field of the config.
+func toInvalidResponse(request *admissionv1beta1.AdmissionRequest, name string, err error) *admissionv1beta1.AdmissionResponse {
+	errs, _ := validation.FieldErrors(err)
+	causes := make([]v1.StatusCause, 0, len(errs))
+	for _, e := range errs {
+		causes = append(causes, v1.StatusCause{
+			Type:    v1.CauseTypeFieldValueInvalid,
+			Message: e.Err.Error(),
+			Field:   specField(e.Path),
+		})
+	}
+	return &admissionv1beta1.AdmissionResponse{
+		Result: &v1.Status{
+			Status:  v1.StatusFailure,
+			Reason:  v1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: fmt.Sprintf("configuration is invalid: %v", err),
+			Details: &v1.StatusDetails{
+				Name:   name,
+				Group:  request.Kind.Group,
+				Kind:   request.Kind.Kind,
+				Causes: causes,
+			},
+		},
+	}
+}
+
+// toWarningResponse accepts a config with warnings. The API server does not return the result of
+// the accepted requests to the clients, so the warnings are also recorded in the audit annotations.
+func toWarningResponse(warnings error) *admissionv1beta1.AdmissionResponse {
+	_, ws := validation.FieldErrors(warnings)
+	messages := make([]string, 0, len(ws))
+	causes := make([]v1.StatusCause, 0, len(ws))
+	for _, w := range ws {
+		messages = append(messages, w.Error())
+		causes = append(causes, v1.StatusCause{
+			Message: w.Err.Error(),
+			Field:   specField(w.Path),
+		})
+	}
+	return &admissionv1beta1.AdmissionResponse{
+		Allowed: true,
+		Result: &v1.Status{
+			Status:  v1.StatusSuccess,
+			Message: fmt.Sprintf("configuration is valid, with warnings: %s", strings.Join(messages, "; ")),
+			Details: &v1.StatusDetails{
+				Causes: causes,
+			},
+		},
+		AuditAnnotations: map[string]string{
+			warningsAnnotation: strings.Join(messages, "; "),
+		},
+	}
+}
+
+// specField returns the path of a field of the spec of a config.
+func specField(path string) string {
+	if path == "" {
+		return "spec"
+	}
+	if strings.HasPrefix(path, "[") {
+		return "spec" + path
+	}
+	return "spec." + path
+}
+
 type admitFunc func(*admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse
 
 func serve(w http.ResponseWriter, r *http.Request, admit admitFunc) {
@@ -444,7 +533,13 @@ func (wh *Webhook) admitPilot(request *admissionv1beta1.AdmissionRequest) *admis
 		return toAdmissionResponse(fmt.Errorf("error decoding configuration: %v", err))
 	}
 
-	if err := s.Validate(out.Name, out.Namespace, out.Spec); err != nil {
+	errs, warnings := validation.SplitWarnings(s.Validate(out.Name, out.Namespace, out.Spec))
+	if errs != nil {
+		scope.Infof("configuration is invalid: %v", errs)
+		reportValidationFailed(request, reasonInvalidConfig)
+		return toInvalidResponse(request, out.Name, errs)
+	}
+	if err := validation.ValidateAnnotations(out.Spec, out.Annotations); err != nil {
 		scope.Infof("configuration is invalid: %v", err)
 		reportValidationFailed(request, reasonInvalidConfig)
 		return toAdmissionResponse(fmt.Errorf("configuration is invalid: %v", err))
@@ -455,10 +550,50 @@ func (wh *Webhook) admitPilot(request *admissionv1beta1.AdmissionRequest) *admis
 		return toAdmissionResponse(err)
 	}
 
+	warnings = multierror.Append(warnings,
+		validation.ValidateWarnings(out.Name, out.Namespace, out.Spec),
+		wh.referenceWarnings(out)).ErrorOrNil()
+	if warnings != nil {
+		scope.Infof("configuration %s/%s is valid, with warnings: %v", out.Namespace, out.Name, warnings)
+		reportValidationWarning(request)
+		return toWarningResponse(warnings)
+	}
+
 	reportValidationPass(request)
 	return &admissionv1beta1.AdmissionResponse{Allowed: true}
 }
 
+// referenceWarnings checks the references of the config to the other configs, returning the missing
+// ones as warnings.
+func (wh *Webhook) referenceWarnings(cfg *model.Config) error {
+	vs, ok := cfg.Spec.(*networking.VirtualService)
+	if !ok || wh.listConfigs == nil {
+		return nil
+	}
+	rules, err := wh.listConfigs(schemas.DestinationRule)
+	if err != nil {
+		// The references are only checked for the warnings, failing to check them does not reject the config.
+		scope.Warnf("cannot list the destination rules to check virtual service %s/%s: %v", cfg.Namespace, cfg.Name, err)
+		return nil
+	}
+
+	subsets := make(map[host.Name]map[string]bool)
+	for _, rule := range rules {
+		dr := rule.Spec.(*networking.DestinationRule)
+		h := host.Name(dr.Host)
+		if !strings.Contains(dr.Host, ".") {
+			h = host.Name(fmt.Sprintf("%s.%s.svc.%s", dr.Host, rule.Namespace, wh.domainSuffix))
+		}
+		if subsets[h] == nil {
+			subsets[h] = make(map[string]bool)
+		}
+		for _, subset := range dr.Subsets {
+			subsets[h][subset.Name] = true
+		}
+	}
+	return validation.ValidateSubsetReferences(vs, cfg.Namespace, wh.domainSuffix, subsets)
+}
+
 func (wh *Webhook) admitMixer(request *admissionv1beta1.AdmissionRequest) *admissionv1beta1.AdmissionResponse {
 	ev := &store.BackendEvent{
 		Key: store.Key{
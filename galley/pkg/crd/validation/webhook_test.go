@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -42,11 +43,15 @@ import (
 	"k8s.io/client-go/tools/cache"
 	fcache "k8s.io/client-go/tools/cache/testing"
 
+	networking "istio.io/api/networking/v1alpha3"
+
 	"istio.io/istio/mixer/pkg/config/store"
 	"istio.io/istio/pilot/pkg/config/kube/crd"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/test/mock"
+	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/virtualservice"
 	"istio.io/istio/pkg/mcp/testing/testcerts"
 	testConfig "istio.io/istio/pkg/test/config"
 )
@@ -349,6 +354,137 @@ func TestAdmitPilot(t *testing.T) {
 	}
 }
 
+func makeVirtualService(t *testing.T, destination *networking.Destination, annotations map[string]string) []byte {
+	t.Helper()
+
+	config := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.VirtualService.Type,
+			Name:        "reviews",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: &networking.VirtualService{
+			Hosts: []string{"reviews"},
+			Http: []*networking.HTTPRoute{{
+				Route: []*networking.HTTPRouteDestination{{Destination: destination}},
+			}},
+		},
+	}
+	obj, err := crd.ConvertConfig(schemas.VirtualService, config)
+	if err != nil {
+		t.Fatalf("ConvertConfig(%v) failed: %v", config.Name, err)
+	}
+	raw, err := json.Marshal(&obj)
+	if err != nil {
+		t.Fatalf("Marshal(%v) failed: %v", config.Name, err)
+	}
+	return raw
+}
+
+func TestAdmitPilotFieldsAndWarnings(t *testing.T) {
+	wh, cancel := createTestWebhook(t, dummyClient, createFakeEndpointsSource(), dummyConfig)
+	defer cancel()
+	wh.descriptor = schemas.Istio
+	wh.listConfigs = func(s schema.Instance) ([]model.Config, error) {
+		if s.Type != schemas.DestinationRule.Type {
+			return nil, nil
+		}
+		return []model.Config{{
+			ConfigMeta: model.ConfigMeta{
+				Type:      schemas.DestinationRule.Type,
+				Name:      "reviews",
+				Namespace: "default",
+			},
+			Spec: &networking.DestinationRule{
+				Host:    "reviews",
+				Subsets: []*networking.Subset{{Name: "v1", Labels: map[string]string{"version": "v1"}}},
+			},
+		}}, nil
+	}
+
+	cases := []struct {
+		name        string
+		destination *networking.Destination
+		allowed     bool
+		field       string
+	}{
+		{
+			name:        "valid",
+			destination: &networking.Destination{Host: "reviews", Subset: "v1"},
+			allowed:     true,
+		},
+		{
+			name:        "invalid host",
+			destination: &networking.Destination{Host: "reviews?"},
+			allowed:     false,
+			field:       "spec.http[0].route[0].destination",
+		},
+		{
+			name:        "unknown subset",
+			destination: &networking.Destination{Host: "reviews", Subset: "v2"},
+			allowed:     true,
+			field:       "spec.http[0].route[0].destination.subset",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := wh.admitPilot(&admissionv1beta1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Kind: "VirtualService"},
+				Object:    runtime.RawExtension{Raw: makeVirtualService(t, c.destination, nil)},
+				Operation: admissionv1beta1.Create,
+			})
+			if got.Allowed != c.allowed {
+				t.Fatalf("got allowed %v want %v: %v", got.Allowed, c.allowed, got.Result)
+			}
+			if c.field == "" {
+				if got.Result != nil {
+					t.Fatalf("got result %v, want none", got.Result)
+				}
+				return
+			}
+			if got.Result == nil || got.Result.Details == nil || len(got.Result.Details.Causes) == 0 {
+				t.Fatalf("got result %v, want causes", got.Result)
+			}
+			if field := got.Result.Details.Causes[0].Field; !strings.HasPrefix(field, c.field) {
+				t.Fatalf("got field %q, want %q", field, c.field)
+			}
+			if c.allowed && got.AuditAnnotations[warningsAnnotation] == "" {
+				t.Fatalf("got no %s audit annotation", warningsAnnotation)
+			}
+		})
+	}
+}
+
+func TestAdmitPilotAnnotations(t *testing.T) {
+	wh, cancel := createTestWebhook(t, dummyClient, createFakeEndpointsSource(), dummyConfig)
+	defer cancel()
+	wh.descriptor = schemas.Istio
+
+	cases := []struct {
+		name       string
+		annotation string
+		allowed    bool
+	}{
+		{"valid regex rewrite", `{"v1": {"pattern": "^/v1/(.*)$", "substitution": "/api/\\1"}}`, true},
+		{"invalid regex rewrite", `{"v1": {"pattern": "^/v1/(.*$"}}`, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := wh.admitPilot(&admissionv1beta1.AdmissionRequest{
+				Kind: metav1.GroupVersionKind{Kind: "VirtualService"},
+				Object: runtime.RawExtension{Raw: makeVirtualService(t, &networking.Destination{Host: "reviews"},
+					map[string]string{virtualservice.URIRegexRewriteAnnotation: c.annotation})},
+				Operation: admissionv1beta1.Create,
+			})
+			if got.Allowed != c.allowed {
+				t.Fatalf("got allowed %v want %v: %v", got.Allowed, c.allowed, got.Result)
+			}
+		})
+	}
+}
+
 func makeMixerConfig(t *testing.T, i int, includeBogusKey bool) []byte {
 	t.Helper()
 	uns := &unstructured.Unstructured{}
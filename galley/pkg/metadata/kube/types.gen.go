@@ -757,6 +757,36 @@ func init() {
 
 	versions = make([]string, 0)
 
+	versions = append(versions, "v1alpha3")
+
+	b.Add(schema.ResourceSpec{
+		Kind:      "WorkloadEntry",
+		ListKind:  "WorkloadEntryList",
+		Singular:  "workloadentry",
+		Plural:    "workloadentries",
+		Versions:  versions,
+		Group:     "networking.istio.io",
+		Target:    metadata.Types.Get("istio/networking/v1alpha3/workloadentries"),
+		Converter: converter.Get("identity"),
+	})
+
+	versions = make([]string, 0)
+
+	versions = append(versions, "v1alpha3")
+
+	b.Add(schema.ResourceSpec{
+		Kind:      "WorkloadGroup",
+		ListKind:  "WorkloadGroupList",
+		Singular:  "workloadgroup",
+		Plural:    "workloadgroups",
+		Versions:  versions,
+		Group:     "networking.istio.io",
+		Target:    metadata.Types.Get("istio/networking/v1alpha3/workloadgroups"),
+		Converter: converter.Get("identity"),
+	})
+
+	versions = make([]string, 0)
+
 	versions = append(versions, "v1alpha2")
 
 	b.Add(schema.ResourceSpec{
@@ -892,6 +922,21 @@ func init() {
 
 	versions = make([]string, 0)
 
+	versions = append(versions, "v1alpha1")
+
+	b.Add(schema.ResourceSpec{
+		Kind:      "Telemetry",
+		ListKind:  "TelemetryList",
+		Singular:  "telemetry",
+		Plural:    "telemetries",
+		Versions:  versions,
+		Group:     "telemetry.istio.io",
+		Target:    metadata.Types.Get("istio/telemetry/v1alpha1/telemetries"),
+		Converter: converter.Get("identity"),
+	})
+
+	versions = make([]string, 0)
+
 	versions = append(versions, "v1")
 
 	b.Add(schema.ResourceSpec{
@@ -29,6 +29,12 @@ import (
 	// Register protos in "istio.io/api/security/v1beta1"
 	_ "istio.io/api/security/v1beta1"
 
+	// Register protos in "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	_ "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+
+	// Register protos in "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	_ "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+
 	// Register protos in "k8s.io/api/core/v1"
 	_ "k8s.io/api/core/v1"
 
@@ -181,6 +187,12 @@ var (
 	// istio/networking/v1alpha3/virtualservices metadata
 	IstioNetworkingV1alpha3Virtualservices resource.Info
 
+	// istio/networking/v1alpha3/workloadentries metadata
+	IstioNetworkingV1alpha3Workloadentries resource.Info
+
+	// istio/networking/v1alpha3/workloadgroups metadata
+	IstioNetworkingV1alpha3Workloadgroups resource.Info
+
 	// istio/policy/v1beta1/attributemanifests metadata
 	IstioPolicyV1beta1Attributemanifests resource.Info
 
@@ -208,6 +220,9 @@ var (
 	// istio/security/v1beta1/authorizationpolicies metadata
 	IstioSecurityV1beta1Authorizationpolicies resource.Info
 
+	// istio/telemetry/v1alpha1/telemetries metadata
+	IstioTelemetryV1alpha1Telemetries resource.Info
+
 	// k8s/core/v1/endpoints metadata
 	K8sCoreV1Endpoints resource.Info
 
@@ -368,6 +383,12 @@ func init() {
 	IstioNetworkingV1alpha3Virtualservices = b.Register(
 		"istio/networking/v1alpha3/virtualservices",
 		"type.googleapis.com/istio.networking.v1alpha3.VirtualService")
+	IstioNetworkingV1alpha3Workloadentries = b.Register(
+		"istio/networking/v1alpha3/workloadentries",
+		"type.googleapis.com/istio.networking.v1alpha3.WorkloadEntry")
+	IstioNetworkingV1alpha3Workloadgroups = b.Register(
+		"istio/networking/v1alpha3/workloadgroups",
+		"type.googleapis.com/istio.networking.v1alpha3.WorkloadGroup")
 	IstioPolicyV1beta1Attributemanifests = b.Register(
 		"istio/policy/v1beta1/attributemanifests",
 		"type.googleapis.com/istio.policy.v1beta1.AttributeManifest")
@@ -395,6 +416,9 @@ func init() {
 	IstioSecurityV1beta1Authorizationpolicies = b.Register(
 		"istio/security/v1beta1/authorizationpolicies",
 		"type.googleapis.com/istio.security.v1beta1.AuthorizationPolicy")
+	IstioTelemetryV1alpha1Telemetries = b.Register(
+		"istio/telemetry/v1alpha1/telemetries",
+		"type.googleapis.com/istio.telemetry.v1alpha1.Telemetry")
 	K8sCoreV1Endpoints = b.Register(
 		"k8s/core/v1/endpoints",
 		"type.googleapis.com/k8s.io.api.core.v1.Endpoints")
@@ -17,6 +17,7 @@ package components
 import (
 	"fmt"
 	"net"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -46,6 +47,7 @@ import (
 	"istio.io/istio/galley/pkg/config/source/kube/apiserver"
 	"istio.io/istio/galley/pkg/config/source/kube/apiserver/status"
 	"istio.io/istio/galley/pkg/config/util/kuberesource"
+	"istio.io/istio/galley/pkg/crd/validation"
 	"istio.io/istio/galley/pkg/server/process"
 	"istio.io/istio/galley/pkg/server/settings"
 	configz "istio.io/istio/pkg/mcp/configz/server"
@@ -78,6 +80,9 @@ type Processing2 struct {
 	listenerMutex sync.Mutex
 	listener      net.Listener
 	stopCh        chan struct{}
+
+	// statusCtl updates the status of the analyzed resources, if the analysis is enabled on a cluster.
+	statusCtl *status.ControllerImpl
 }
 
 var _ process.Component = &Processing2{}
@@ -150,6 +155,11 @@ func (p *Processing2) Start() (err error) {
 	grpcOptions := p.getServerGrpcOptions()
 
 	p.stopCh = make(chan struct{})
+	if p.statusCtl != nil {
+		if err = p.electStatusLeader(); err != nil {
+			return
+		}
+	}
 	var checker source.AuthChecker = server.NewAllowAllChecker()
 	if !p.args.Insecure {
 		if checker, err = watchAccessList(p.stopCh, p.args.AccessListFile); err != nil {
@@ -306,7 +316,8 @@ func (p *Processing2) createSourceAndStatusUpdater(resources schema.KubeResource
 
 		var statusCtl status.Controller
 		if p.args.EnableConfigAnalysis {
-			statusCtl = status.NewController("validationMessages")
+			p.statusCtl = status.NewController("validationMessages")
+			statusCtl = p.statusCtl
 		}
 
 		o := apiserver.Options{
@@ -322,6 +333,32 @@ func (p *Processing2) createSourceAndStatusUpdater(resources schema.KubeResource
 	return
 }
 
+// electStatusLeader runs for the leadership of the status updates of the analyzed resources, so that only one of
+// the replicas of Galley updates them.
+func (p *Processing2) electStatusLeader() error {
+	client, err := p.k.KubeClient()
+	if err != nil {
+		return err
+	}
+	identity, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+	namespace := validation.DefaultArgs().DeploymentAndServiceNamespace
+	if p.args.ValidationArgs != nil {
+		namespace = p.args.ValidationArgs.DeploymentAndServiceNamespace
+	}
+
+	// Do not update the status until elected.
+	p.statusCtl.SetLeading(false)
+	go func() {
+		if err := status.RunLeaderElection(p.stopCh, client, namespace, identity, p.statusCtl); err != nil {
+			scope.Errorf("Unable to elect the leader of the status updates: %v", err)
+		}
+	}()
+	return nil
+}
+
 // Stop implements process.Component
 func (p *Processing2) Stop() {
 	if p.stopCh != nil {
@@ -30,3 +30,51 @@ Create chart name and version as used by the chart label.
 {{- define "mixer.chart" -}}
 {{- .Chart.Name | trunc 63 | trimSuffix "-" -}}
 {{- end -}}
+
+{{/*
+Dimensions of the standard HTTP metrics, after adding telemetry.metrics.extraDimensions and
+dropping telemetry.metrics.removedDimensions.
+*/}}
+{{- define "mixer.httpMetricDimensionMap" -}}
+{{- $dimensions := dict }}
+{{- $_ := set $dimensions "reporter" `conditional((context.reporter.kind | "inbound") == "outbound", "source", "destination")` }}
+{{- $_ := set $dimensions "source_workload" `source.workload.name | "unknown"` }}
+{{- $_ := set $dimensions "source_workload_namespace" `source.workload.namespace | "unknown"` }}
+{{- $_ := set $dimensions "source_principal" `source.principal | "unknown"` }}
+{{- $_ := set $dimensions "source_app" `source.labels["app"] | "unknown"` }}
+{{- $_ := set $dimensions "source_version" `source.labels["version"] | "unknown"` }}
+{{- $_ := set $dimensions "destination_workload" `destination.workload.name | "unknown"` }}
+{{- $_ := set $dimensions "destination_workload_namespace" `destination.workload.namespace | "unknown"` }}
+{{- $_ := set $dimensions "destination_principal" `destination.principal | "unknown"` }}
+{{- $_ := set $dimensions "destination_app" `destination.labels["app"] | "unknown"` }}
+{{- $_ := set $dimensions "destination_version" `destination.labels["version"] | "unknown"` }}
+{{- $_ := set $dimensions "destination_service" `destination.service.host | request.host | "unknown"` }}
+{{- $_ := set $dimensions "destination_service_name" `destination.service.name | "unknown"` }}
+{{- $_ := set $dimensions "destination_service_namespace" `destination.service.namespace | "unknown"` }}
+{{- $_ := set $dimensions "request_protocol" `api.protocol | context.protocol | "unknown"` }}
+{{- $_ := set $dimensions "request_operation" `api.operation | "unknown"` }}
+{{- $_ := set $dimensions "response_code" `response.code | 200` }}
+{{- $_ := set $dimensions "response_flags" `context.proxy_error_code | "-"` }}
+{{- $_ := set $dimensions "permissive_response_code" `rbac.permissive.response_code | "none"` }}
+{{- $_ := set $dimensions "permissive_response_policyid" `rbac.permissive.effective_policy_id | "none"` }}
+{{- $_ := set $dimensions "connection_security_policy" `conditional((context.reporter.kind | "inbound") == "outbound", "unknown", conditional(connection.mtls | false, "mutual_tls", "none"))` }}
+{{- range $name, $expression := .Values.telemetry.metrics.extraDimensions }}
+{{- $_ := set $dimensions $name $expression }}
+{{- end }}
+{{- range .Values.telemetry.metrics.removedDimensions }}
+{{- $_ := unset $dimensions . }}
+{{- end }}
+{{- toJson $dimensions -}}
+{{- end -}}
+
+{{- define "mixer.httpMetricDimensions" -}}
+{{- range $name, $expression := include "mixer.httpMetricDimensionMap" . | fromJson }}
+{{ $name }}: {{ $expression }}
+{{- end }}
+{{- end -}}
+
+{{- define "mixer.httpMetricLabels" -}}
+{{- range $name, $expression := include "mixer.httpMetricDimensionMap" . | fromJson }}
+- {{ $name }}
+{{- end }}
+{{- end -}}
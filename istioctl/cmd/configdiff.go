@@ -0,0 +1,72 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/istioctl/pkg/writer/pilot"
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+func configDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config-diff <pod-name[.namespace]>",
+		Short: "Diffs the configuration Pilot generates for a proxy against the one the proxy last ACKed",
+		Long: `
+Generates the clusters, listeners and routes Pilot would currently push to a proxy, and diffs them against
+the ones the proxy last ACKed, highlighting the configuration the proxy is missing or still holds.
+Pilot only records the configuration ACKed by the proxies with PILOT_DEBUG_ADSZ_CONFIG=true.
+`,
+		Example: `# Retrieve the configuration drift of the proxy of pod "productpage-v1-bb8d5cbc7-k7qbm" in namespace default:
+istioctl experimental config-diff productpage-v1-bb8d5cbc7-k7qbm.default`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("config-diff requires pod name")
+			}
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			kubeClient, err := clientExecFactory(kubeconfig, configContext)
+			if err != nil {
+				return err
+			}
+			podName, ns := handlers.InferPodInfo(args[0], handlers.HandleNamespace(namespace, defaultNamespace))
+			results, err := kubeClient.AllPilotsDiscoveryDo(istioNamespace, "GET",
+				fmt.Sprintf("/debug/config_diff?proxyID=%s.%s", podName, ns), nil)
+			if err != nil {
+				return err
+			}
+
+			// only the Pilot the proxy is connected to returns its config diff
+			for _, result := range results {
+				diff := &v2.ProxyConfigDiff{}
+				if err := json.Unmarshal(result, diff); err != nil || diff.ProxyID == "" {
+					continue
+				}
+				dw := pilot.ConfigDiffWriter{Writer: c.OutOrStdout()}
+				return dw.Print(diff)
+			}
+			return fmt.Errorf("checked %d pilot instances and found no config diff for %s.%s, check proxy status",
+				len(results), podName, ns)
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"istio.io/istio/istioctl/pkg/kubernetes"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestConfigDiff(t *testing.T) {
+	clientExecFactory = mockExecClientConfigDiff
+
+	cases := []testCase{
+		{ // case 0
+			configs:        []model.Config{},
+			args:           strings.Split("experimental config-diff", " "),
+			expectedRegexp: regexp.MustCompile("Error: config-diff requires pod name\n"),
+			wantException:  true,
+		},
+		{ // case 1
+			configs: []model.Config{},
+			args:    strings.Split("experimental config-diff details-v1-5b7f94f9bc-wp5tb.default", " "),
+			expectedOutput: `Clusters Match
+Listeners Don't Match (last push not ACKed)
+   Missing from the proxy: 10.0.0.1_9080
+   Stale in the proxy: 10.0.0.2_9080
+Routes Don't Match
+   Changed: 9080
+--- Applied 9080
++++ Generated 9080
+@@ -1 +1 @@
+-  "name": "old"
++  "name": "new"
+
+`,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyOutput(t, c)
+		})
+	}
+}
+
+func TestConfigDiffNotConnected(t *testing.T) {
+	clientExecFactory = mockExecClientConfigDiffNotConnected
+
+	cases := []testCase{
+		{ // case 0
+			configs:        []model.Config{},
+			args:           strings.Split("experimental config-diff unknown-5b7f94f9bc-wp5tb.default", " "),
+			expectedRegexp: regexp.MustCompile("Error: checked 1 pilot instances and found no config diff for unknown-5b7f94f9bc-wp5tb.default"),
+			wantException:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyOutput(t, c)
+		})
+	}
+}
+
+func mockExecClientConfigDiff(_, _ string) (kubernetes.ExecClient, error) {
+	return &mockExecConfig{
+		results: map[string][]byte{
+			"istio-pilot-123456-7890": []byte(`
+{
+  "proxy": "details-v1-5b7f94f9bc-wp5tb.default",
+  "clusters": {},
+  "listeners": {
+    "pending": true,
+    "missing": ["10.0.0.1_9080"],
+    "stale": ["10.0.0.2_9080"]
+  },
+  "routes": {
+    "changed": {
+      "9080": "--- Applied 9080\n+++ Generated 9080\n@@ -1 +1 @@\n-  \"name\": \"old\"\n+  \"name\": \"new\"\n"
+    }
+  }
+}`),
+			"istio-pilot-123456-0987": []byte("Proxy not connected to this Pilot instance"),
+		},
+	}, nil
+}
+
+func mockExecClientConfigDiffNotConnected(_, _ string) (kubernetes.ExecClient, error) {
+	return &mockExecConfig{
+		results: map[string][]byte{
+			"istio-pilot-123456-7890": []byte("Proxy not connected to this Pilot instance"),
+		},
+	}, nil
+}
@@ -30,11 +30,11 @@ func TestProxyStatus(t *testing.T) {
 	cases := []execTestCase{
 		{ // case 0
 			args:           strings.Split("proxy-status", " "),
-			expectedString: "NAME     CDS     LDS     EDS     RDS     PILOT",
+			expectedString: "NAME     CLUSTER     NETWORK     CDS     LDS     EDS     RDS     PILOT",
 		},
 		{ // case 1 short name "ps"
 			args:           strings.Split("ps", " "),
-			expectedString: "NAME     CDS     LDS     EDS     RDS     PILOT",
+			expectedString: "NAME     CLUSTER     NETWORK     CDS     LDS     EDS     RDS     PILOT",
 		},
 		{ // case 2  "proxy-status podName.namespace"
 			execClientConfig: cannedConfig,
@@ -0,0 +1,411 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	// injectionLabel enables the injection of the control plane without revision in a namespace.
+	injectionLabel = "istio-injection"
+
+	// restartedAtAnnotation is the pod template annotation `kubectl rollout restart` sets.
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+)
+
+func revisionCmd() *cobra.Command {
+	revisionCmd := &cobra.Command{
+		Use:   "revision",
+		Short: "Manage the revisions of the control plane injecting the namespaces",
+		Long: `A revision is a control plane installed alongside the others, injecting the pods of the
+namespaces labeled istio.io/rev=<revision>. Tags are aliases of revisions: the namespaces labeled
+with a tag are injected by the revision it points to, so that moving the tag upgrades them all.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.HelpFunc()(cmd, args)
+			if len(args) != 0 {
+				return fmt.Errorf("unknown subcommand %q", args[0])
+			}
+			return nil
+		},
+	}
+	revisionCmd.AddCommand(tagCmd())
+	revisionCmd.AddCommand(migrateCmd())
+	return revisionCmd
+}
+
+func tagCmd() *cobra.Command {
+	tagCmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Manage the tags of the revisions",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.HelpFunc()(cmd, args)
+			if len(args) != 0 {
+				return fmt.Errorf("unknown subcommand %q", args[0])
+			}
+			return nil
+		},
+	}
+
+	var overwrite bool
+	setCmd := &cobra.Command{
+		Use:   "set <tag> <revision>",
+		Short: "Point a tag to a revision",
+		Long: `Points a tag to a revision. The pods of the namespaces labeled istio.io/rev=<tag> are injected
+by the revision once they restart.
+`,
+		Example: `istioctl experimental revision tag set stable 1-4-0`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			return setTag(client, args[0], args[1], overwrite, cmd.OutOrStdout())
+		},
+	}
+	setCmd.PersistentFlags().BoolVar(&overwrite, "overwrite", false,
+		"Move the tag if it points to another revision")
+
+	listCmd := &cobra.Command{
+		Use:     "list",
+		Short:   "List the tags, with the revision they point to and the namespaces using them",
+		Example: `istioctl experimental revision tag list`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			return listTags(client, cmd.OutOrStdout())
+		},
+	}
+
+	var force bool
+	removeCmd := &cobra.Command{
+		Use:     "remove <tag>",
+		Short:   "Remove a tag",
+		Example: `istioctl experimental revision tag remove canary`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			return removeTag(client, args[0], force, cmd.OutOrStdout())
+		},
+	}
+	removeCmd.PersistentFlags().BoolVar(&force, "force", false,
+		"Remove the tag even if namespaces use it, their new pods are no longer injected")
+
+	tagCmd.AddCommand(setCmd, listCmd, removeCmd)
+	return tagCmd
+}
+
+func migrateCmd() *cobra.Command {
+	var (
+		namespaces []string
+		all        bool
+		restart    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "migrate <from> <to>",
+		Short: "Move namespaces from a revision or tag to another",
+		Long: `Relabels the namespaces using a revision or tag, "default" for the control plane without
+revision, to use another one. Migrate a few namespaces at a time with --namespaces to upgrade the
+data plane gradually; their pods move to the new revision as they restart.
+`,
+		Example: `  # Move two namespaces from the control plane without revision to the 1-4-0 revision
+  istioctl experimental revision migrate default 1-4-0 --namespaces bookinfo,httpbin --restart
+
+  # Move the remaining namespaces
+  istioctl experimental revision migrate default 1-4-0 --all`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(namespaces) != 0) {
+				return fmt.Errorf("exactly one of --namespaces and --all is required")
+			}
+			client, err := interfaceFactory(kubeconfig)
+			if err != nil {
+				return err
+			}
+			return migrate(client, args[0], args[1], namespaces, restart, cmd.OutOrStdout())
+		},
+	}
+	cmd.PersistentFlags().StringSliceVar(&namespaces, "namespaces", nil,
+		"Namespaces to migrate")
+	cmd.PersistentFlags().BoolVar(&all, "all", false,
+		"Migrate all the namespaces using the revision or tag")
+	cmd.PersistentFlags().BoolVar(&restart, "restart", false,
+		"Restart the deployments of the migrated namespaces, moving their pods to the new revision")
+	return cmd
+}
+
+// revisionWebhookName returns the name of the injection webhook configuration of a revision.
+func revisionWebhookName(revision string) string {
+	if revision == constants.DefaultRevision {
+		return "istio-sidecar-injector"
+	}
+	return "istio-sidecar-injector-" + revision
+}
+
+// tagWebhookName returns the name of the injection webhook configuration of a tag.
+func tagWebhookName(tag string) string {
+	return "istio-revision-tag-" + tag
+}
+
+func setTag(client kubernetes.Interface, tag, revision string, overwrite bool, writer io.Writer) error {
+	if tag == constants.DefaultRevision {
+		return fmt.Errorf("%q is the revision of the control plane without revision, it cannot be a tag", tag)
+	}
+	webhooks := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	if _, err := webhooks.Get(revisionWebhookName(tag), metav1.GetOptions{}); err == nil {
+		return fmt.Errorf("%q is the name of a revision, it cannot be a tag", tag)
+	}
+	revisionWebhook, err := webhooks.Get(revisionWebhookName(revision), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("revision %q not found: %v", revision, err)
+	}
+
+	tagWebhook, err := buildTagWebhook(revisionWebhook, tag, revision)
+	if err != nil {
+		return err
+	}
+	existing, err := webhooks.Get(tagWebhookName(tag), metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = webhooks.Create(tagWebhook)
+	case err != nil:
+		return err
+	default:
+		if current := existing.Labels[constants.IstioRevisionLabel]; current != revision && !overwrite {
+			return fmt.Errorf("tag %q points to revision %q, use --overwrite to move it", tag, current)
+		}
+		tagWebhook.ResourceVersion = existing.ResourceVersion
+		_, err = webhooks.Update(tagWebhook)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to point tag %q to revision %q: %v", tag, revision, err)
+	}
+	fmt.Fprintf(writer, "Tag %s points to revision %s, the pods of the namespaces labeled %s=%s are injected by it once restarted.\n",
+		tag, revision, constants.IstioRevisionLabel, tag)
+	return nil
+}
+
+// buildTagWebhook returns the webhook configuration of a tag, calling the injector of the revision
+// for the namespaces labeled with the tag.
+func buildTagWebhook(revisionWebhook *v1beta1.MutatingWebhookConfiguration, tag, revision string) (*v1beta1.MutatingWebhookConfiguration, error) {
+	out := &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: tagWebhookName(tag),
+			Labels: map[string]string{
+				constants.IstioTagLabel:      tag,
+				constants.IstioRevisionLabel: revision,
+			},
+		},
+	}
+	path := constants.InjectTagPathPrefix + tag
+	for _, wh := range revisionWebhook.Webhooks {
+		wh := *wh.DeepCopy()
+		wh.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{constants.IstioRevisionLabel: tag},
+		}
+		switch {
+		case wh.ClientConfig.Service != nil:
+			wh.ClientConfig.Service.Path = &path
+		case wh.ClientConfig.URL != nil:
+			u, err := url.Parse(*wh.ClientConfig.URL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid URL of webhook %s: %v", wh.Name, err)
+			}
+			u.Path = path
+			s := u.String()
+			wh.ClientConfig.URL = &s
+		}
+		out.Webhooks = append(out.Webhooks, wh)
+	}
+	return out, nil
+}
+
+func listTags(client kubernetes.Interface, writer io.Writer) error {
+	tags, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(metav1.ListOptions{
+		LabelSelector: constants.IstioTagLabel,
+	})
+	if err != nil {
+		return err
+	}
+	if len(tags.Items) == 0 {
+		fmt.Fprintln(writer, "No tags found.")
+		return nil
+	}
+	sort.Slice(tags.Items, func(i, j int) bool {
+		return tags.Items[i].Labels[constants.IstioTagLabel] < tags.Items[j].Labels[constants.IstioTagLabel]
+	})
+
+	w := tabwriter.NewWriter(writer, 0, 8, 1, ' ', 0)
+	fmt.Fprintln(w, "TAG\tREVISION\tNAMESPACES")
+	for _, t := range tags.Items {
+		tag := t.Labels[constants.IstioTagLabel]
+		namespaces, err := revisionNamespaces(client, tag)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", tag, t.Labels[constants.IstioRevisionLabel], strings.Join(namespaces, ","))
+	}
+	return w.Flush()
+}
+
+func removeTag(client kubernetes.Interface, tag string, force bool, writer io.Writer) error {
+	webhooks := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	if _, err := webhooks.Get(tagWebhookName(tag), metav1.GetOptions{}); err != nil {
+		return fmt.Errorf("tag %q not found: %v", tag, err)
+	}
+	namespaces, err := revisionNamespaces(client, tag)
+	if err != nil {
+		return err
+	}
+	if len(namespaces) != 0 && !force {
+		return fmt.Errorf("namespaces %s use tag %q, migrate them first or use --force", strings.Join(namespaces, ","), tag)
+	}
+	if err := webhooks.Delete(tagWebhookName(tag), &metav1.DeleteOptions{}); err != nil {
+		return err
+	}
+	fmt.Fprintf(writer, "Tag %s removed.\n", tag)
+	return nil
+}
+
+// revisionNamespaces returns the names of the namespaces using a revision or tag.
+func revisionNamespaces(client kubernetes.Interface, revision string) ([]string, error) {
+	selector := constants.IstioRevisionLabel + "=" + revision
+	if revision == constants.DefaultRevision {
+		selector = fmt.Sprintf("%s=enabled,!%s", injectionLabel, constants.IstioRevisionLabel)
+	}
+	namespaces, err := client.CoreV1().Namespaces().List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		out = append(out, ns.Name)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// migrate moves the namespaces from a revision or tag to another, all the namespaces using it if
+// names is empty.
+func migrate(client kubernetes.Interface, from, to string, names []string, restart bool, writer io.Writer) error {
+	if from == to {
+		return fmt.Errorf("the namespaces already use %q", to)
+	}
+	webhooks := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations()
+	if _, err := webhooks.Get(revisionWebhookName(to), metav1.GetOptions{}); err != nil {
+		if _, err := webhooks.Get(tagWebhookName(to), metav1.GetOptions{}); err != nil {
+			return fmt.Errorf("no revision or tag %q found", to)
+		}
+	}
+
+	using, err := revisionNamespaces(client, from)
+	if err != nil {
+		return err
+	}
+	if len(names) != 0 {
+		selected := map[string]bool{}
+		for _, name := range names {
+			selected[name] = true
+		}
+		var filtered []string
+		for _, name := range using {
+			if selected[name] {
+				filtered = append(filtered, name)
+				delete(selected, name)
+			}
+		}
+		for name := range selected {
+			fmt.Fprintf(writer, "Namespace %s does not use %s, skipped.\n", name, from)
+		}
+		using = filtered
+	}
+
+	var errs error
+	for _, name := range using {
+		if err := migrateNamespace(client, name, to, restart); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to migrate namespace %s: %v", name, err))
+			continue
+		}
+		fmt.Fprintf(writer, "Namespace %s migrated from %s to %s.\n", name, from, to)
+	}
+	if errs == nil && len(using) != 0 && !restart {
+		fmt.Fprintf(writer, "Restart the workloads of the migrated namespaces to move their pods to %s.\n", to)
+	}
+	return errs
+}
+
+func migrateNamespace(client kubernetes.Interface, name, to string, restart bool) error {
+	ns, err := client.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	ns = ns.DeepCopy()
+	if ns.Labels == nil {
+		ns.Labels = map[string]string{}
+	}
+	if to == constants.DefaultRevision {
+		delete(ns.Labels, constants.IstioRevisionLabel)
+		ns.Labels[injectionLabel] = "enabled"
+	} else {
+		// the injector without revision skips the namespaces with a revision
+		if ns.Labels[injectionLabel] == "enabled" {
+			delete(ns.Labels, injectionLabel)
+		}
+		ns.Labels[constants.IstioRevisionLabel] = to
+	}
+	if _, err := client.CoreV1().Namespaces().Update(ns); err != nil {
+		return err
+	}
+	if !restart {
+		return nil
+	}
+
+	deployments, err := client.AppsV1().Deployments(name).List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	patch := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339))
+	for _, d := range deployments.Items {
+		if _, err := client.AppsV1().Deployments(name).Patch(d.Name, types.StrategicMergePatchType, []byte(patch)); err != nil {
+			return fmt.Errorf("failed to restart deployment %s: %v", d.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+func revisionWebhook(revision string) *v1beta1.MutatingWebhookConfiguration {
+	path := "/inject"
+	return &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: revisionWebhookName(revision)},
+		Webhooks: []v1beta1.MutatingWebhook{{
+			Name: "sidecar-injector.istio.io",
+			ClientConfig: v1beta1.WebhookClientConfig{
+				Service: &v1beta1.ServiceReference{
+					Name:      revisionWebhookName(revision),
+					Namespace: "istio-system",
+					Path:      &path,
+				},
+				CABundle: []byte("ca"),
+			},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{constants.IstioRevisionLabel: revision},
+			},
+		}},
+	}
+}
+
+func revisionNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestSetTag(t *testing.T) {
+	cases := []struct {
+		name      string
+		objects   []runtime.Object
+		tag       string
+		revision  string
+		overwrite bool
+		wantErr   string
+	}{
+		{
+			name:     "new tag",
+			objects:  []runtime.Object{revisionWebhook("1-4-0")},
+			tag:      "stable",
+			revision: "1-4-0",
+		},
+		{
+			name:     "tag of the control plane without revision",
+			objects:  []runtime.Object{revisionWebhook(constants.DefaultRevision)},
+			tag:      "stable",
+			revision: constants.DefaultRevision,
+		},
+		{
+			name:     "unknown revision",
+			tag:      "stable",
+			revision: "1-4-0",
+			wantErr:  `revision "1-4-0" not found`,
+		},
+		{
+			name:     "tag named after a revision",
+			objects:  []runtime.Object{revisionWebhook("1-4-0"), revisionWebhook("1-5-0")},
+			tag:      "1-5-0",
+			revision: "1-4-0",
+			wantErr:  `"1-5-0" is the name of a revision`,
+		},
+		{
+			name: "move without overwrite",
+			objects: []runtime.Object{revisionWebhook("1-4-0"), revisionWebhook("1-5-0"), &v1beta1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   tagWebhookName("stable"),
+					Labels: map[string]string{constants.IstioTagLabel: "stable", constants.IstioRevisionLabel: "1-4-0"},
+				},
+			}},
+			tag:      "stable",
+			revision: "1-5-0",
+			wantErr:  `tag "stable" points to revision "1-4-0"`,
+		},
+		{
+			name: "move with overwrite",
+			objects: []runtime.Object{revisionWebhook("1-4-0"), revisionWebhook("1-5-0"), &v1beta1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   tagWebhookName("stable"),
+					Labels: map[string]string{constants.IstioTagLabel: "stable", constants.IstioRevisionLabel: "1-4-0"},
+				},
+			}},
+			tag:       "stable",
+			revision:  "1-5-0",
+			overwrite: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(c.objects...)
+			var out bytes.Buffer
+			err := setTag(client, c.tag, c.revision, c.overwrite, &out)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(tagWebhookName(c.tag), metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Labels[constants.IstioRevisionLabel] != c.revision || got.Labels[constants.IstioTagLabel] != c.tag {
+				t.Errorf("got labels %v, want the tag and revision", got.Labels)
+			}
+			if len(got.Webhooks) != 1 {
+				t.Fatalf("got %d webhooks, want 1", len(got.Webhooks))
+			}
+			wh := got.Webhooks[0]
+			if want := map[string]string{constants.IstioRevisionLabel: c.tag}; !reflect.DeepEqual(wh.NamespaceSelector.MatchLabels, want) {
+				t.Errorf("got namespace selector %v, want %v", wh.NamespaceSelector.MatchLabels, want)
+			}
+			if want := "/inject/tag/" + c.tag; *wh.ClientConfig.Service.Path != want {
+				t.Errorf("got path %s, want %s", *wh.ClientConfig.Service.Path, want)
+			}
+			if wh.ClientConfig.Service.Name != revisionWebhookName(c.revision) || string(wh.ClientConfig.CABundle) != "ca" {
+				t.Errorf("got client config %+v, want the one of the revision", wh.ClientConfig)
+			}
+		})
+	}
+}
+
+func TestListAndRemoveTags(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		revisionWebhook("1-4-0"),
+		revisionNamespace("bookinfo", map[string]string{constants.IstioRevisionLabel: "stable"}),
+	)
+	var out bytes.Buffer
+	for _, tag := range []string{"stable", "canary"} {
+		if err := setTag(client, tag, "1-4-0", false, &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out.Reset()
+	if err := listTags(client, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "TAG    REVISION NAMESPACES\ncanary 1-4-0    \nstable 1-4-0    bookinfo\n"
+	if out.String() != want {
+		t.Errorf("got\n%q\nwant\n%q", out.String(), want)
+	}
+
+	if err := removeTag(client, "stable", false, &out); err == nil || !strings.Contains(err.Error(), "bookinfo") {
+		t.Errorf("got error %v, want the namespaces using the tag", err)
+	}
+	if err := removeTag(client, "canary", false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeTag(client, "stable", true, &out); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := listTags(client, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "No tags found.\n" {
+		t.Errorf("got %q, want no tags", out.String())
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	cases := []struct {
+		name       string
+		from       string
+		to         string
+		namespaces []string
+		restart    bool
+		wantLabels map[string]map[string]string
+		wantErr    string
+	}{
+		{
+			name: "all from the control plane without revision",
+			from: constants.DefaultRevision,
+			to:   "1-4-0",
+			wantLabels: map[string]map[string]string{
+				"bookinfo": {constants.IstioRevisionLabel: "1-4-0"},
+				"httpbin":  {constants.IstioRevisionLabel: "1-4-0"},
+				"canary":   {constants.IstioRevisionLabel: "1-4-0"},
+			},
+		},
+		{
+			name:       "selected namespaces",
+			from:       constants.DefaultRevision,
+			to:         "1-4-0",
+			namespaces: []string{"bookinfo", "canary"},
+			restart:    true,
+			wantLabels: map[string]map[string]string{
+				"bookinfo": {constants.IstioRevisionLabel: "1-4-0"},
+				"httpbin":  {injectionLabel: "enabled"},
+				"canary":   {constants.IstioRevisionLabel: "1-4-0"},
+			},
+		},
+		{
+			name: "back to the control plane without revision",
+			from: "1-4-0",
+			to:   constants.DefaultRevision,
+			wantLabels: map[string]map[string]string{
+				"bookinfo": {injectionLabel: "enabled"},
+				"httpbin":  {injectionLabel: "enabled"},
+				"canary":   {injectionLabel: "enabled"},
+			},
+		},
+		{
+			name:    "unknown target",
+			from:    constants.DefaultRevision,
+			to:      "1-5-0",
+			wantErr: `no revision or tag "1-5-0" found`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			initial := map[string]string{injectionLabel: "enabled"}
+			if c.from != constants.DefaultRevision {
+				initial = map[string]string{constants.IstioRevisionLabel: c.from}
+			}
+			client := fake.NewSimpleClientset(
+				revisionWebhook(constants.DefaultRevision),
+				revisionWebhook("1-4-0"),
+				revisionNamespace("bookinfo", initial),
+				revisionNamespace("httpbin", initial),
+				revisionNamespace("canary", initial),
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "productpage", Namespace: "bookinfo"}},
+			)
+			var out bytes.Buffer
+			err := migrate(client, c.from, c.to, c.namespaces, c.restart, &out)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range c.wantLabels {
+				ns, err := client.CoreV1().Namespaces().Get(name, metav1.GetOptions{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(ns.Labels, want) {
+					t.Errorf("namespace %s: got labels %v, want %v", name, ns.Labels, want)
+				}
+			}
+			d, err := client.AppsV1().Deployments("bookinfo").Get("productpage", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, restarted := d.Spec.Template.Annotations[restartedAtAnnotation]; restarted != c.restart {
+				t.Errorf("got deployment restarted %v, want %v", restarted, c.restart)
+			}
+		})
+	}
+}
@@ -147,10 +147,14 @@ debug and diagnose their Istio mesh.
 	experimentalCmd.AddCommand(uninjectCommand())
 	experimentalCmd.AddCommand(metricsCmd)
 	experimentalCmd.AddCommand(describe())
+	experimentalCmd.AddCommand(configDiffCmd())
+	experimentalCmd.AddCommand(versionSkewCmd())
 	experimentalCmd.AddCommand(addToMeshCmd())
 	experimentalCmd.AddCommand(removeFromMeshCmd())
 	experimentalCmd.AddCommand(Analyze())
 	experimentalCmd.AddCommand(waitCmd())
+	experimentalCmd.AddCommand(revisionCmd())
+	experimentalCmd.AddCommand(uninstallCmd())
 
 	postInstallCmd.AddCommand(Webhook())
 	experimentalCmd.AddCommand(postInstallCmd)
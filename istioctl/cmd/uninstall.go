@@ -0,0 +1,161 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+	"istio.io/istio/operator/pkg/apply"
+	"istio.io/istio/operator/pkg/controller"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/kube"
+)
+
+// applierFactory creates the applier of the resources of the cluster, overridden by tests.
+var applierFactory = func(kubeconfig, configContext string) (apply.Applier, error) {
+	config, err := kube.BuildClientConfig(kubeconfig, configContext)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return apply.NewKubeApplier(client, discoveryClient), nil
+}
+
+func uninstallCmd() *cobra.Command {
+	var (
+		revision         string
+		dryRun           bool
+		skipConfirmation bool
+	)
+	cmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the control plane of a revision installed by the operator",
+		Long: `Removes the IstioOperator resources of a revision and all the resources the operator installed
+for it. The custom resource definitions, holding the configuration of the mesh, and the namespaces
+are kept. Migrate the namespaces using the revision beforehand, their new pods are no longer injected.
+`,
+		Example: `  # List the resources of the canary revision
+  istioctl experimental uninstall --revision canary --dry-run
+
+  # Remove the control plane installed without revision
+  istioctl experimental uninstall`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !dryRun && !skipConfirmation &&
+				!confirm(fmt.Sprintf("All the resources of revision %s will be removed. Proceed? (y/N)", revision),
+					cmd.InOrStdin(), cmd.OutOrStdout()) {
+				fmt.Fprintln(cmd.OutOrStdout(), "Cancelled.")
+				return nil
+			}
+			a, err := applierFactory(kubeconfig, configContext)
+			if err != nil {
+				return err
+			}
+			return uninstall(a, revision, dryRun, cmd.OutOrStdout())
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&revision, "revision", "r", constants.DefaultRevision,
+		"Revision of the control plane to remove")
+	cmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false,
+		"List the resources which would be removed")
+	cmd.PersistentFlags().BoolVarP(&skipConfirmation, "skip-confirmation", "y", false,
+		"Remove the resources without asking for confirmation")
+	return cmd
+}
+
+func confirm(message string, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "%s ", message)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// uninstall removes the IstioOperator resources of the revision and the resources installed for it.
+func uninstall(a apply.Applier, revision string, dryRun bool, writer io.Writer) error {
+	action := "removed"
+	if dryRun {
+		action = "would be removed"
+	}
+
+	// the operator reinstalls the revision as long as its IstioOperator resources exist
+	iops, err := a.List(v1alpha1.IstioOperatorGVK, "")
+	if err != nil {
+		return err
+	}
+	var errs error
+	for i := range iops {
+		iop := &iops[i]
+		rev, _, _ := unstructured.NestedString(iop.Object, "spec", "revision")
+		if rev == "" {
+			rev = constants.DefaultRevision
+		}
+		if rev != revision {
+			continue
+		}
+		if !dryRun {
+			if err := a.Delete(iop); err != nil {
+				errs = multierror.Append(errs, err)
+				continue
+			}
+		}
+		fmt.Fprintf(writer, "IstioOperator %s/%s %s.\n", iop.GetNamespace(), iop.GetName(), action)
+	}
+	if errs != nil {
+		return errs
+	}
+
+	crds, err := a.List(apply.CRDKind, "")
+	if err != nil {
+		return err
+	}
+	var istioCRDs []*unstructured.Unstructured
+	for i := range crds {
+		if group, _, _ := unstructured.NestedString(crds[i].Object, "spec", "group"); strings.HasSuffix(group, "istio.io") {
+			istioCRDs = append(istioCRDs, &crds[i])
+		}
+	}
+	removed, err := apply.Prune(a, apply.Kinds(istioCRDs), controller.RevisionLabel+"="+revision, nil, dryRun)
+	for _, obj := range removed {
+		name := obj.GetName()
+		if obj.GetNamespace() != "" {
+			name = obj.GetNamespace() + "/" + name
+		}
+		fmt.Fprintf(writer, "%s %s %s.\n", obj.GetKind(), name, action)
+	}
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Fprintf(writer, "No resources of revision %s found.\n", revision)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+	"istio.io/istio/operator/pkg/apply"
+	"istio.io/istio/operator/pkg/apply/fake"
+	"istio.io/istio/operator/pkg/controller"
+)
+
+func installedObject(apiVersion, kind, namespace, name, revision string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	if revision != "" {
+		u.SetLabels(map[string]string{controller.RevisionLabel: revision})
+	}
+	return u
+}
+
+func istioOperator(name, revision string) *unstructured.Unstructured {
+	u := installedObject(v1alpha1.Group+"/"+v1alpha1.Version, v1alpha1.Kind, "istio-operator", name, "")
+	if revision != "" {
+		_ = unstructured.SetNestedField(u.Object, revision, "spec", "revision")
+	}
+	return u
+}
+
+func TestUninstall(t *testing.T) {
+	gatewayCRD := installedObject("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "gateways.networking.istio.io", "default")
+	gatewayCRD.Object["spec"] = map[string]interface{}{
+		"group":   "networking.istio.io",
+		"names":   map[string]interface{}{"kind": "Gateway"},
+		"version": "v1alpha3",
+	}
+	objects := []*unstructured.Unstructured{
+		istioOperator("control-plane", ""),
+		istioOperator("control-plane-canary", "canary"),
+		gatewayCRD,
+		installedObject("v1", "Namespace", "", "istio-system", "default"),
+		installedObject("apps/v1", "Deployment", "istio-system", "istio-pilot", "default"),
+		installedObject("apps/v1", "Deployment", "istio-system", "istio-pilot-canary", "canary"),
+		installedObject("rbac.authorization.k8s.io/v1", "ClusterRole", "", "istio-pilot-istio-system", "default"),
+		installedObject("networking.istio.io/v1alpha3", "Gateway", "istio-system", "ingressgateway", "default"),
+		installedObject("v1", "ConfigMap", "istio-system", "user-config", ""),
+	}
+
+	cases := []struct {
+		name     string
+		revision string
+		dryRun   bool
+		want     []string
+	}{
+		{
+			name:     "default",
+			revision: "default",
+			want: []string{
+				"/ConfigMap/istio-system/user-config",
+				"/Namespace//istio-system",
+				"apiextensions.k8s.io/CustomResourceDefinition//gateways.networking.istio.io",
+				"apps/Deployment/istio-system/istio-pilot-canary",
+				"install.istio.io/IstioOperator/istio-operator/control-plane-canary",
+			},
+		},
+		{
+			name:     "canary",
+			revision: "canary",
+			want: []string{
+				"/ConfigMap/istio-system/user-config",
+				"/Namespace//istio-system",
+				"apiextensions.k8s.io/CustomResourceDefinition//gateways.networking.istio.io",
+				"apps/Deployment/istio-system/istio-pilot",
+				"install.istio.io/IstioOperator/istio-operator/control-plane",
+				"networking.istio.io/Gateway/istio-system/ingressgateway",
+				"rbac.authorization.k8s.io/ClusterRole//istio-pilot-istio-system",
+			},
+		},
+		{
+			name:     "dry run",
+			revision: "default",
+			dryRun:   true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := fake.NewApplier(objects...)
+			var out bytes.Buffer
+			if err := uninstall(a, c.revision, c.dryRun, &out); err != nil {
+				t.Fatal(err)
+			}
+			want := c.want
+			if c.dryRun {
+				want = nil
+				for _, obj := range objects {
+					want = append(want, apply.Key(obj))
+				}
+				sort.Strings(want)
+			}
+			var got []string
+			for key := range a.Objects {
+				got = append(got, key)
+			}
+			sort.Strings(got)
+			if len(got) != len(want) {
+				t.Fatalf("got resources\n%v\nwant\n%v\noutput:\n%s", got, want, out.String())
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("got resources\n%v\nwant\n%v\noutput:\n%s", got, want, out.String())
+				}
+			}
+		})
+	}
+}
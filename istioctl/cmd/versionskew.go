@@ -0,0 +1,49 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/writer/pilot"
+)
+
+func versionSkewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version-skew",
+		Short: "Reports the versions of the proxies connected to each Pilot, and the ones too old for it",
+		Long: `
+Reports, for each Pilot, the number of connected proxies of each Istio version, and lists the proxies lagging
+more minor versions behind Pilot than PILOT_MAX_PROXY_VERSION_SKEW allows. They should be upgraded before
+Pilot, which refuses them with PILOT_REJECT_UNSUPPORTED_PROXIES=true.
+`,
+		Example: `# Retrieve the versions of the proxies of the mesh before upgrading the control plane:
+istioctl experimental version-skew`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			kubeClient, err := clientExecFactory(kubeconfig, configContext)
+			if err != nil {
+				return err
+			}
+			results, err := kubeClient.AllPilotsDiscoveryDo(istioNamespace, "GET", "/debug/version_skew", nil)
+			if err != nil {
+				return err
+			}
+			sw := pilot.VersionSkewWriter{Writer: c.OutOrStdout()}
+			return sw.PrintAll(results)
+		},
+	}
+	return cmd
+}
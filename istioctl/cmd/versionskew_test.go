@@ -0,0 +1,81 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"istio.io/istio/istioctl/pkg/kubernetes"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestVersionSkew(t *testing.T) {
+	clientExecFactory = mockExecClientVersionSkew
+
+	cases := []testCase{
+		{ // case 0
+			configs: []model.Config{},
+			args:    strings.Split("experimental version-skew", " "),
+			expectedOutput: `PILOT                       PILOT VERSION     PROXY VERSION     PROXIES     SUPPORTED
+istio-pilot-123456-0987     1.5.0             1.5.0             3           true
+istio-pilot-123456-7890     1.5.0             1.2.0             1           false
+istio-pilot-123456-7890     1.5.0             1.4.1             2           true
+
+Proxies of istio-pilot-123456-7890 more than 2 minor versions behind it:
+   details-v1-5b7f94f9bc-wp5tb.default
+`,
+		},
+		{ // case 1
+			configs:        []model.Config{},
+			args:           strings.Split("experimental version-skew extra", " "),
+			expectedRegexp: regexp.MustCompile("Error: unknown command \"extra\""),
+			wantException:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyOutput(t, c)
+		})
+	}
+}
+
+func mockExecClientVersionSkew(_, _ string) (kubernetes.ExecClient, error) {
+	return &mockExecConfig{
+		results: map[string][]byte{
+			"istio-pilot-123456-7890": []byte(`
+{
+  "pilot_version": "1.5.0",
+  "max_skew": 2,
+  "versions": [
+    {"version": "1.2.0", "proxies": 1, "supported": false},
+    {"version": "1.4.1", "proxies": 2, "supported": true}
+  ],
+  "unsupported": ["details-v1-5b7f94f9bc-wp5tb.default"]
+}`),
+			"istio-pilot-123456-0987": []byte(`
+{
+  "pilot_version": "1.5.0",
+  "max_skew": 2,
+  "versions": [
+    {"version": "1.5.0", "proxies": 3, "supported": true}
+  ]
+}`),
+		},
+	}, nil
+}
@@ -33,6 +33,7 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/validation"
 
 	"istio.io/pkg/log"
 
@@ -96,7 +97,8 @@ func (v *validator) validateResource(istioNamespace string, un *unstructured.Uns
 		if err = checkFields(un); err != nil {
 			return err
 		}
-		return schema.Validate(obj.Name, obj.Namespace, obj.Spec)
+		return multierror.Append(schema.Validate(obj.Name, obj.Namespace, obj.Spec),
+			validation.ValidateAnnotations(obj.Spec, obj.Annotations)).ErrorOrNil()
 	}
 
 	if v.mixerValidator != nil && un.GetAPIVersion() == mixerAPIVersion {
@@ -0,0 +1,80 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+// ConfigDiffWriter enables printing of the drift between the configuration Pilot generates for a proxy
+// and the one the proxy last ACKed, using a single Pilot config_diff response
+type ConfigDiffWriter struct {
+	Writer io.Writer
+}
+
+// Print outputs the drift of the clusters, listeners and routes of the proxy
+func (c *ConfigDiffWriter) Print(diff *v2.ProxyConfigDiff) error {
+	for _, d := range []struct {
+		kind string
+		diff *v2.ResourceDiff
+	}{
+		{"Clusters", diff.Clusters},
+		{"Listeners", diff.Listeners},
+		{"Routes", diff.Routes},
+	} {
+		if err := c.printResourceDiff(d.kind, d.diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ConfigDiffWriter) printResourceDiff(kind string, diff *v2.ResourceDiff) error {
+	if diff == nil {
+		diff = &v2.ResourceDiff{}
+	}
+	pending := ""
+	if diff.Pending {
+		pending = " (last push not ACKed)"
+	}
+	if len(diff.Missing) == 0 && len(diff.Stale) == 0 && len(diff.Changed) == 0 {
+		_, err := fmt.Fprintf(c.Writer, "%s Match%s\n", kind, pending)
+		return err
+	}
+	_, _ = fmt.Fprintf(c.Writer, "%s Don't Match%s\n", kind, pending)
+	if len(diff.Missing) > 0 {
+		_, _ = fmt.Fprintf(c.Writer, "   Missing from the proxy: %s\n", strings.Join(diff.Missing, ", "))
+	}
+	if len(diff.Stale) > 0 {
+		_, _ = fmt.Fprintf(c.Writer, "   Stale in the proxy: %s\n", strings.Join(diff.Stale, ", "))
+	}
+	changed := make([]string, 0, len(diff.Changed))
+	for name := range diff.Changed {
+		changed = append(changed, name)
+	}
+	sort.Strings(changed)
+	if len(changed) > 0 {
+		_, _ = fmt.Fprintf(c.Writer, "   Changed: %s\n", strings.Join(changed, ", "))
+	}
+	for _, name := range changed {
+		_, _ = fmt.Fprintln(c.Writer, diff.Changed[name])
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
+)
+
+// VersionSkewWriter enables printing of the version skew between the Pilots and their proxies using multiple
+// []byte Pilot version_skew responses
+type VersionSkewWriter struct {
+	Writer io.Writer
+}
+
+// PrintAll outputs the versions of the proxies connected to each Pilot, followed by the unsupported proxies
+func (v *VersionSkewWriter) PrintAll(reports map[string][]byte) error {
+	pilots := make([]string, 0, len(reports))
+	parsed := make(map[string]*v2.VersionSkewReport, len(reports))
+	for pilot, report := range reports {
+		r := &v2.VersionSkewReport{}
+		if err := json.Unmarshal(report, r); err != nil {
+			return fmt.Errorf("unable to parse the version skew of %s: %v", pilot, err)
+		}
+		pilots = append(pilots, pilot)
+		parsed[pilot] = r
+	}
+	sort.Strings(pilots)
+
+	w := new(tabwriter.Writer).Init(v.Writer, 0, 8, 5, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PILOT\tPILOT VERSION\tPROXY VERSION\tPROXIES\tSUPPORTED")
+	for _, pilot := range pilots {
+		r := parsed[pilot]
+		for _, pv := range r.Versions {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%t\n", pilot, r.PilotVersion, pv.Version, pv.Proxies, pv.Supported)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, pilot := range pilots {
+		r := parsed[pilot]
+		if len(r.Unsupported) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(v.Writer, "\nProxies of %s more than %d minor versions behind it:\n", pilot, r.MaxSkew)
+		for _, proxy := range r.Unsupported {
+			_, _ = fmt.Fprintf(v.Writer, "   %s\n", proxy)
+		}
+	}
+	return nil
+}
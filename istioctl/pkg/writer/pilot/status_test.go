@@ -168,6 +168,8 @@ func statusInput1() []v2.SyncStatus {
 		{
 			ProxyID:         "proxy1",
 			IstioVersion:    "1.1",
+			ClusterID:       "cluster1",
+			Network:         "network1",
 			ClusterSent:     preDefinedNonce,
 			ClusterAcked:    newNonce(),
 			ListenerSent:    preDefinedNonce,
@@ -184,6 +186,8 @@ func statusInput2() []v2.SyncStatus {
 		{
 			ProxyID:       "proxy2",
 			IstioVersion:  "1.1",
+			ClusterID:     "cluster2",
+			Network:       "network2",
 			ClusterSent:   preDefinedNonce,
 			ClusterAcked:  newNonce(),
 			ListenerSent:  preDefinedNonce,
@@ -0,0 +1,108 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+
+	"istio.io/istio/operator/pkg/apply"
+	"istio.io/istio/operator/pkg/controller"
+	"istio.io/istio/operator/pkg/render"
+	"istio.io/istio/pkg/cmd"
+	"istio.io/istio/pkg/kube"
+	"istio.io/pkg/collateral"
+	"istio.io/pkg/log"
+	"istio.io/pkg/version"
+)
+
+var (
+	flags = struct {
+		loggingOptions *log.Options
+
+		kubeconfigFile string
+		chartsDir      string
+		watchNamespace string
+	}{
+		loggingOptions: log.DefaultOptions(),
+	}
+
+	rootCmd = &cobra.Command{
+		Use:          "operator",
+		Short:        "Kubernetes controller installing the Istio control plane described by IstioOperator resources.",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(0),
+		RunE: func(c *cobra.Command, _ []string) error {
+			cmd.PrintFlags(c.Flags())
+			if err := log.Configure(flags.loggingOptions); err != nil {
+				return err
+			}
+
+			log.Infof("version %s", version.Info.String())
+
+			config, err := kube.BuildClientConfig(flags.kubeconfigFile, "")
+			if err != nil {
+				return multierror.Prefix(err, "failed to create the Kubernetes client")
+			}
+			client, err := dynamic.NewForConfig(config)
+			if err != nil {
+				return multierror.Prefix(err, "failed to create the Kubernetes client")
+			}
+			discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+			if err != nil {
+				return multierror.Prefix(err, "failed to create the Kubernetes client")
+			}
+
+			ctl := controller.NewController(client, flags.watchNamespace, render.NewRenderer(flags.chartsDir),
+				apply.NewKubeApplier(client, discoveryClient))
+			stop := make(chan struct{})
+			go ctl.Run(stop)
+			cmd.WaitSignal(stop)
+			return nil
+		},
+	}
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&flags.kubeconfigFile, "kubeconfig", "",
+		"Specifies path to kubeconfig file. This must be specified when not running inside a Kubernetes pod.")
+	rootCmd.PersistentFlags().StringVar(&flags.chartsDir, "chartsDir", "/var/lib/istio/charts",
+		"Directory holding the istio and istio-init helm charts the installations are rendered from.")
+	rootCmd.PersistentFlags().StringVar(&flags.watchNamespace, "watchNamespace", "istio-operator",
+		"Namespace of the IstioOperator resources reconciled, all the namespaces if empty.")
+	// Attach the Istio logging options to the command.
+	flags.loggingOptions.AttachCobraFlags(rootCmd)
+
+	cmd.AddFlags(rootCmd)
+
+	rootCmd.AddCommand(version.CobraCommand())
+	rootCmd.AddCommand(collateral.CobraCommand(rootCmd, &doc.GenManHeader{
+		Title:   "Istio Operator",
+		Section: "operator CLI",
+		Manual:  "Istio Operator",
+	}))
+}
+
+func main() {
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(-1)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1alpha1 defines the IstioOperator custom resource, which describes an installation of
+// the Istio control plane reconciled by the operator.
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Group is the API group of the IstioOperator resource.
+	Group = "install.istio.io"
+	// Version is the API version of the IstioOperator resource.
+	Version = "v1alpha1"
+	// Kind is the kind of the IstioOperator resource.
+	Kind = "IstioOperator"
+
+	// DefaultNamespace is the namespace the control plane is installed in when the spec sets none.
+	DefaultNamespace = "istio-system"
+)
+
+var (
+	// IstioOperatorGVK is the kind of the IstioOperator resource.
+	IstioOperatorGVK = schema.GroupVersionKind{Group: Group, Version: Version, Kind: Kind}
+	// IstioOperatorGVR is the resource of the IstioOperator kind.
+	IstioOperatorGVR = schema.GroupVersionResource{Group: Group, Version: Version, Resource: "istiooperators"}
+)
+
+// Profiles, the base configurations an IstioOperator starts from.
+const (
+	// ProfileDefault installs the control plane recommended for production.
+	ProfileDefault = "default"
+	// ProfileMinimal installs only the components required for traffic management.
+	ProfileMinimal = "minimal"
+	// ProfileRemote installs the components of a cluster whose workloads are managed by the
+	// control plane of another cluster.
+	ProfileRemote = "remote"
+)
+
+// IstioOperator describes an installation of the Istio control plane.
+type IstioOperator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IstioOperatorSpec   `json:"spec,omitempty"`
+	Status IstioOperatorStatus `json:"status,omitempty"`
+}
+
+// IstioOperatorSpec is the desired installation.
+type IstioOperatorSpec struct {
+	// Profile is the base configuration of the installation, the default profile if empty.
+	Profile string `json:"profile,omitempty"`
+	// Namespace the control plane is installed in, DefaultNamespace if empty.
+	Namespace string `json:"namespace,omitempty"`
+	// Revision installs the control plane as a revision, alongside the control planes of other
+	// revisions. Its injector handles the namespaces labeled istio.io/rev=<revision>.
+	Revision string `json:"revision,omitempty"`
+	// Hub and Tag override the images of all the components.
+	Hub string `json:"hub,omitempty"`
+	Tag string `json:"tag,omitempty"`
+	// Values are helm values overlaid on the ones of the profile.
+	Values map[string]interface{} `json:"values,omitempty"`
+	// DisablePruning keeps the resources of the installation which are no longer rendered, such as
+	// the ones of disabled components. They are deleted by default.
+	DisablePruning bool `json:"disablePruning,omitempty"`
+}
+
+// Phase is the state of an installation, or of one of its components.
+type Phase string
+
+const (
+	// PhaseReconciling is the phase of an installation whose resources are being updated.
+	PhaseReconciling Phase = "RECONCILING"
+	// PhaseHealthy is the phase of an installation whose resources are all up to date.
+	PhaseHealthy Phase = "HEALTHY"
+	// PhaseError is the phase of an installation some resources of which could not be updated.
+	PhaseError Phase = "ERROR"
+)
+
+// IstioOperatorStatus is the observed state of the installation.
+type IstioOperatorStatus struct {
+	Status Phase `json:"status,omitempty"`
+	// Message describes the error of an installation in the ERROR phase.
+	Message string `json:"message,omitempty"`
+	// ObservedGeneration is the generation of the spec last reconciled.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Components is the status of each component, keyed by component name.
+	Components map[string]*ComponentStatus `json:"components,omitempty"`
+}
+
+// ComponentStatus is the observed state of a component of the installation.
+type ComponentStatus struct {
+	Status  Phase  `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	// Resources is the number of resources of the component.
+	Resources int `json:"resources"`
+	// Updated is the number of resources updated by the last reconciliation, the others were up
+	// to date.
+	Updated int `json:"updated"`
+	// Pruned is the number of resources deleted by the last reconciliation, as they are no longer
+	// part of the installation.
+	Pruned int `json:"pruned,omitempty"`
+}
+
+// FromUnstructured converts an IstioOperator read from the dynamic client.
+func FromUnstructured(u *unstructured.Unstructured) (*IstioOperator, error) {
+	out := &IstioOperator{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ToUnstructured converts an IstioOperator to be written with the dynamic client.
+func ToUnstructured(in *IstioOperator) (*unstructured.Unstructured, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: obj}
+	u.SetAPIVersion(Group + "/" + Version)
+	u.SetKind(Kind)
+	return u, nil
+}
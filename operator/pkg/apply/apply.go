@@ -0,0 +1,165 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package apply applies the resources of an installation with server-side apply, the API server
+// computing both the result of the apply and whether it changes the resource.
+package apply
+
+import (
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+)
+
+// FieldManager is the manager of the fields applied by the operator.
+const FieldManager = "istio-operator"
+
+// Applier applies resources to the cluster.
+type Applier interface {
+	// Apply applies the resource and returns the result. With dryRun, the result is computed but
+	// not persisted. Namespace is the namespace of namespaced resources which set none.
+	Apply(obj *unstructured.Unstructured, namespace string, dryRun bool) (*unstructured.Unstructured, error)
+	// Get returns the resource in the cluster, or nil if it does not exist.
+	Get(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error)
+	// List returns the resources of the kind in all the namespaces matching the label selector,
+	// none if the cluster does not serve the kind.
+	List(gvk schema.GroupVersionKind, selector string) ([]unstructured.Unstructured, error)
+	// Delete deletes the resource, which must have its namespace set if it is namespaced.
+	Delete(obj *unstructured.Unstructured) error
+}
+
+// Changed returns whether applying a resource changed it, from the resource in the cluster and
+// the result of applying it, nil if it does not exist.
+func Changed(live, applied *unstructured.Unstructured) bool {
+	if live == nil {
+		return true
+	}
+	return !equality.Semantic.DeepEqual(comparable(live), comparable(applied))
+}
+
+// comparable strips the fields updated by the API server on every write.
+func comparable(obj *unstructured.Unstructured) map[string]interface{} {
+	out := obj.DeepCopy()
+	out.SetResourceVersion("")
+	out.SetGeneration(0)
+	out.SetManagedFields(nil)
+	unstructured.RemoveNestedField(out.Object, "status")
+	return out.Object
+}
+
+// kubeApplier applies resources with server-side apply.
+type kubeApplier struct {
+	client dynamic.Interface
+	mapper *restmapper.DeferredDiscoveryRESTMapper
+}
+
+// NewKubeApplier returns an applier using the clients.
+func NewKubeApplier(client dynamic.Interface, discoveryClient discovery.DiscoveryInterface) Applier {
+	return &kubeApplier{
+		client: client,
+		mapper: restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+	}
+}
+
+func (a *kubeApplier) Apply(obj *unstructured.Unstructured, namespace string, dryRun bool) (*unstructured.Unstructured, error) {
+	ri, obj, err := a.resource(obj, namespace)
+	if err != nil {
+		return nil, err
+	}
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	force := true
+	opts := metav1.PatchOptions{FieldManager: FieldManager, Force: &force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	return ri.Patch(obj.GetName(), types.ApplyPatchType, data, opts)
+}
+
+func (a *kubeApplier) Get(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	ri, obj, err := a.resource(obj, namespace)
+	if err != nil {
+		return nil, err
+	}
+	live, err := ri.Get(obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	return live, err
+}
+
+func (a *kubeApplier) List(gvk schema.GroupVersionKind, selector string) ([]unstructured.Unstructured, error) {
+	mapping, err := a.mapping(gvk)
+	if meta.IsNoMatchError(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list, err := a.client.Resource(mapping.Resource).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (a *kubeApplier) Delete(obj *unstructured.Unstructured) error {
+	ri, obj, err := a.resource(obj, "")
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	err = ri.Delete(obj.GetName(), &metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// resource returns the client of the resource, and the resource with its namespace set if it is
+// namespaced.
+func (a *kubeApplier) resource(obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, *unstructured.Unstructured, error) {
+	mapping, err := a.mapping(obj.GroupVersionKind())
+	if err != nil {
+		return nil, nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.client.Resource(mapping.Resource), obj, nil
+	}
+	if obj.GetNamespace() == "" {
+		obj = obj.DeepCopy()
+		obj.SetNamespace(namespace)
+	}
+	return a.client.Resource(mapping.Resource).Namespace(obj.GetNamespace()), obj, nil
+}
+
+func (a *kubeApplier) mapping(gvk schema.GroupVersionKind) (*meta.RESTMapping, error) {
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		// the kind may be defined by a custom resource definition applied since discovery
+		a.mapper.Reset()
+		mapping, err = a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	}
+	return mapping, err
+}
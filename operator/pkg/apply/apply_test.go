@@ -0,0 +1,108 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newConfigMap(resourceVersion string, data map[string]interface{}) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":            "istio",
+			"namespace":       "istio-system",
+			"resourceVersion": resourceVersion,
+		},
+		"data": data,
+	}}
+	return u
+}
+
+func TestChanged(t *testing.T) {
+	cases := []struct {
+		name    string
+		live    *unstructured.Unstructured
+		applied *unstructured.Unstructured
+		want    bool
+	}{
+		{
+			name:    "created",
+			applied: newConfigMap("1", map[string]interface{}{"mesh": "a"}),
+			want:    true,
+		},
+		{
+			name:    "unchanged",
+			live:    newConfigMap("1", map[string]interface{}{"mesh": "a"}),
+			applied: newConfigMap("2", map[string]interface{}{"mesh": "a"}),
+			want:    false,
+		},
+		{
+			name:    "changed",
+			live:    newConfigMap("1", map[string]interface{}{"mesh": "a"}),
+			applied: newConfigMap("2", map[string]interface{}{"mesh": "b"}),
+			want:    true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Changed(c.live, c.applied); got != c.want {
+				t.Errorf("got changed %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestKinds(t *testing.T) {
+	crds := []*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"kind": "CustomResourceDefinition",
+			"spec": map[string]interface{}{
+				"group":   "networking.istio.io",
+				"names":   map[string]interface{}{"kind": "Gateway"},
+				"version": "v1alpha3",
+			},
+		}},
+		{Object: map[string]interface{}{
+			"kind": "CustomResourceDefinition",
+			"spec": map[string]interface{}{
+				"group":    "security.istio.io",
+				"names":    map[string]interface{}{"kind": "AuthorizationPolicy"},
+				"versions": []interface{}{map[string]interface{}{"name": "v1beta1"}},
+			},
+		}},
+	}
+	kinds := Kinds(crds)
+	if len(kinds) != len(builtinKinds)+2 {
+		t.Fatalf("got %d kinds, want %d", len(kinds), len(builtinKinds)+2)
+	}
+	want := []schema.GroupVersionKind{
+		{Group: "networking.istio.io", Version: "v1alpha3", Kind: "Gateway"},
+		{Group: "security.istio.io", Version: "v1beta1", Kind: "AuthorizationPolicy"},
+	}
+	if got := kinds[len(builtinKinds):]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, k := range kinds {
+		if k.Kind == "Namespace" || k.Kind == CRDKind.Kind {
+			t.Errorf("%s is pruned", k.Kind)
+		}
+	}
+}
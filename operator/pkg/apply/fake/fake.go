@@ -0,0 +1,112 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fake provides an in-memory applier for tests.
+package fake
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"istio.io/istio/operator/pkg/apply"
+)
+
+// clusterKinds are the kinds of the cluster scoped resources of the installations.
+var clusterKinds = map[string]bool{
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// Applier stores the applied resources, applying a resource replacing it.
+type Applier struct {
+	// Objects are the resources in the cluster, by key.
+	Objects map[string]*unstructured.Unstructured
+	// Applied is the number of resources applied, dry runs excluded.
+	Applied int
+	// FailKind fails the applies of the resources of the kind.
+	FailKind string
+}
+
+var _ apply.Applier = &Applier{}
+
+// NewApplier returns an applier of a cluster holding the resources.
+func NewApplier(objects ...*unstructured.Unstructured) *Applier {
+	a := &Applier{Objects: map[string]*unstructured.Unstructured{}}
+	for _, obj := range objects {
+		a.Objects[apply.Key(obj)] = obj.DeepCopy()
+	}
+	return a
+}
+
+func withNamespace(obj *unstructured.Unstructured, namespace string) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	if out.GetNamespace() == "" && !clusterKinds[out.GetKind()] {
+		out.SetNamespace(namespace)
+	}
+	return out
+}
+
+// Apply implements apply.Applier.
+func (a *Applier) Apply(obj *unstructured.Unstructured, namespace string, dryRun bool) (*unstructured.Unstructured, error) {
+	if obj.GetKind() == a.FailKind {
+		return nil, fmt.Errorf("forbidden")
+	}
+	out := withNamespace(obj, namespace)
+	if !dryRun {
+		a.Applied++
+		a.Objects[apply.Key(out)] = out.DeepCopy()
+	}
+	return out, nil
+}
+
+// Get implements apply.Applier.
+func (a *Applier) Get(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, error) {
+	if live, f := a.Objects[apply.Key(withNamespace(obj, namespace))]; f {
+		return live.DeepCopy(), nil
+	}
+	return nil, nil
+}
+
+// List implements apply.Applier.
+func (a *Applier) List(gvk schema.GroupVersionKind, selector string) ([]unstructured.Unstructured, error) {
+	s, err := labels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	var out []unstructured.Unstructured
+	for _, obj := range a.Objects {
+		if obj.GroupVersionKind().GroupKind() != gvk.GroupKind() || !s.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		out = append(out, *obj.DeepCopy())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return apply.Key(&out[i]) < apply.Key(&out[j])
+	})
+	return out, nil
+}
+
+// Delete implements apply.Applier.
+func (a *Applier) Delete(obj *unstructured.Unstructured) error {
+	delete(a.Objects, apply.Key(obj))
+	return nil
+}
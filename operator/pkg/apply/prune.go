@@ -0,0 +1,108 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CRDKind is the kind of the custom resource definitions.
+var CRDKind = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1beta1", Kind: "CustomResourceDefinition"}
+
+// builtinKinds are the kinds of the resources of an installation pruned when they are no longer
+// rendered. Namespaces are shared by installations and the custom resource definitions hold the
+// configuration of the mesh, both are never pruned.
+var builtinKinds = []schema.GroupVersionKind{
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "MutatingWebhookConfiguration"},
+	{Group: "admissionregistration.k8s.io", Version: "v1beta1", Kind: "ValidatingWebhookConfiguration"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "apps", Version: "v1", Kind: "DaemonSet"},
+	{Group: "apps", Version: "v1", Kind: "StatefulSet"},
+	{Group: "autoscaling", Version: "v2beta1", Kind: "HorizontalPodAutoscaler"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "Role"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	{Version: "v1", Kind: "ConfigMap"},
+	{Version: "v1", Kind: "Endpoints"},
+	{Version: "v1", Kind: "Secret"},
+	{Version: "v1", Kind: "Service"},
+	{Version: "v1", Kind: "ServiceAccount"},
+}
+
+// Kinds returns the kinds of the resources pruned from an installation: the builtin kinds and the
+// kinds defined by the custom resource definitions.
+func Kinds(crds []*unstructured.Unstructured) []schema.GroupVersionKind {
+	out := append([]schema.GroupVersionKind(nil), builtinKinds...)
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		version, _, _ := unstructured.NestedString(crd.Object, "spec", "version")
+		if version == "" {
+			versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+			if len(versions) > 0 {
+				if v, ok := versions[0].(map[string]interface{}); ok {
+					version, _ = v["name"].(string)
+				}
+			}
+		}
+		if group == "" || kind == "" || version == "" {
+			continue
+		}
+		out = append(out, schema.GroupVersionKind{Group: group, Version: version, Kind: kind})
+	}
+	return out
+}
+
+// Key identifies a resource regardless of its version.
+func Key(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())
+}
+
+// Prune deletes the resources of the kinds matching the label selector whose key is not kept, and
+// returns them. With dryRun, the resources are only returned.
+func Prune(a Applier, kinds []schema.GroupVersionKind, selector string, keep map[string]bool,
+	dryRun bool) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+	var errs error
+	for _, gvk := range kinds {
+		objects, err := a.List(gvk, selector)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to list %s: %v", gvk.Kind, err))
+			continue
+		}
+		for i := range objects {
+			obj := &objects[i]
+			if keep[Key(obj)] {
+				continue
+			}
+			if !dryRun {
+				if err := a.Delete(obj); err != nil {
+					errs = multierror.Append(errs, fmt.Errorf("failed to delete %s %s: %v", obj.GetKind(), obj.GetName(), err))
+					continue
+				}
+			}
+			out = append(out, obj)
+		}
+	}
+	return out, errs
+}
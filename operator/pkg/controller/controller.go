@@ -0,0 +1,326 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package controller reconciles IstioOperator resources into the installation they describe.
+package controller
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+	"istio.io/istio/operator/pkg/apply"
+	"istio.io/istio/operator/pkg/render"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/pkg/log"
+)
+
+const (
+	// OwnerNameLabel is the label set on the resources of an installation to the name of its
+	// IstioOperator.
+	OwnerNameLabel = "install.operator.istio.io/owner-name"
+	// ComponentLabel is the label set on the resources of an installation to their component.
+	ComponentLabel = "install.operator.istio.io/component"
+	// RevisionLabel is the label set on the resources of an installation to its revision,
+	// constants.DefaultRevision for the installations without revision.
+	RevisionLabel = "install.operator.istio.io/revision"
+
+	// resyncPeriod is the period the installations are reconciled at, reverting the changes made
+	// to their resources out of the operator.
+	resyncPeriod = 5 * time.Minute
+
+	maxRetries = 5
+)
+
+var scope = log.RegisterScope("operator", "IstioOperator controller", 0)
+
+// Controller reconciles the IstioOperator resources of a namespace.
+type Controller struct {
+	client   dynamic.Interface
+	renderer *render.Renderer
+	applier  apply.Applier
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+}
+
+// NewController returns a controller of the IstioOperator resources of the namespace, all the
+// namespaces if empty.
+func NewController(client dynamic.Interface, namespace string, renderer *render.Renderer, applier apply.Applier) *Controller {
+	informer := dynamicinformer.NewFilteredDynamicInformer(client, v1alpha1.IstioOperatorGVR, namespace,
+		resyncPeriod, cache.Indexers{}, nil).Informer()
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+
+	c := &Controller{
+		client:   client,
+		renderer: renderer,
+		applier:  applier,
+		queue:    queue,
+		informer: informer,
+	}
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+				queue.Add(key)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldIop, newIop := oldObj.(*unstructured.Unstructured), newObj.(*unstructured.Unstructured)
+			// the status updates of the controller leave the generation unchanged, a resync
+			// leaves the resource version unchanged
+			if oldIop.GetGeneration() == newIop.GetGeneration() && oldIop.GetResourceVersion() != newIop.GetResourceVersion() {
+				return
+			}
+			if key, err := cache.MetaNamespaceKeyFunc(newObj); err == nil {
+				queue.Add(key)
+			}
+		},
+	})
+
+	return c
+}
+
+// Run starts the controller until it receives a message over stopCh
+func (c *Controller) Run(stopCh <-chan struct{}) {
+	defer utilruntime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	scope.Info("Starting IstioOperator controller")
+
+	go c.informer.Run(stopCh)
+
+	if !cache.WaitForCacheSync(stopCh, c.informer.HasSynced) {
+		utilruntime.HandleError(fmt.Errorf("timed out waiting for caches to sync"))
+		return
+	}
+	wait.Until(c.runWorker, 5*time.Second, stopCh)
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *Controller) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	err := c.processItem(key.(string))
+	if err == nil {
+		c.queue.Forget(key)
+	} else if c.queue.NumRequeues(key) < maxRetries {
+		scope.Errorf("Error reconciling %s (will retry): %v", key, err)
+		c.queue.AddRateLimited(key)
+	} else {
+		scope.Errorf("Error reconciling %s (giving up): %v", key, err)
+		c.queue.Forget(key)
+		utilruntime.HandleError(err)
+	}
+	return true
+}
+
+func (c *Controller) processItem(key string) error {
+	obj, exists, err := c.informer.GetIndexer().GetByKey(key)
+	if err != nil {
+		return fmt.Errorf("error fetching object %s: %v", key, err)
+	}
+	if !exists {
+		// the resources of the installation are left in place
+		scope.Infof("IstioOperator %s deleted", key)
+		return nil
+	}
+	iop, err := v1alpha1.FromUnstructured(obj.(*unstructured.Unstructured))
+	if err != nil {
+		return fmt.Errorf("invalid IstioOperator %s: %v", key, err)
+	}
+	return c.Reconcile(iop)
+}
+
+// Reconcile updates the resources of the installation and its status. Returns an error if the
+// installation is not healthy.
+func (c *Controller) Reconcile(iop *v1alpha1.IstioOperator) error {
+	if iop.Status.ObservedGeneration != iop.Generation {
+		status := iop.Status
+		status.Status = v1alpha1.PhaseReconciling
+		status.Message = ""
+		if err := c.updateStatus(iop, status); err != nil {
+			return err
+		}
+	}
+	status := c.reconcile(iop)
+	if err := c.updateStatus(iop, status); err != nil {
+		return err
+	}
+	if status.Status == v1alpha1.PhaseError {
+		return fmt.Errorf("%s", status.Message)
+	}
+	return nil
+}
+
+// reconcile applies the resources of the installation which changed, and returns its status.
+func (c *Controller) reconcile(iop *v1alpha1.IstioOperator) v1alpha1.IstioOperatorStatus {
+	status := v1alpha1.IstioOperatorStatus{
+		Status:             v1alpha1.PhaseHealthy,
+		ObservedGeneration: iop.Generation,
+		Components:         map[string]*v1alpha1.ComponentStatus{},
+	}
+	objects, err := c.renderer.Render(&iop.Spec)
+	if err != nil {
+		status.Status = v1alpha1.PhaseError
+		status.Message = fmt.Sprintf("failed to render the installation: %v", err)
+		return status
+	}
+	namespace := iop.Spec.Namespace
+	if namespace == "" {
+		namespace = v1alpha1.DefaultNamespace
+	}
+
+	revision := iop.Spec.Revision
+	if revision == "" {
+		revision = constants.DefaultRevision
+	}
+
+	// the keys of the resources of the installation, which are not pruned
+	keep := map[string]bool{}
+	var crds []*unstructured.Unstructured
+	for _, obj := range objects {
+		if obj.Component == render.CRDComponent {
+			crds = append(crds, obj.Unstructured)
+		}
+		cs, f := status.Components[obj.Component]
+		if !f {
+			cs = &v1alpha1.ComponentStatus{Status: v1alpha1.PhaseHealthy}
+			status.Components[obj.Component] = cs
+		}
+		cs.Resources++
+
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[OwnerNameLabel] = iop.Name
+		labels[ComponentLabel] = obj.Component
+		labels[RevisionLabel] = revision
+		obj.SetLabels(labels)
+
+		applied, updated, err := c.applyObject(obj.Unstructured, namespace)
+		if err != nil {
+			scope.Errorf("failed to apply %s %s of %s: %v", obj.GetKind(), obj.GetName(), iop.Name, err)
+			if cs.Status != v1alpha1.PhaseError {
+				cs.Status = v1alpha1.PhaseError
+				cs.Message = fmt.Sprintf("failed to apply %s %s: %v", obj.GetKind(), obj.GetName(), err)
+			}
+			continue
+		}
+		keep[apply.Key(applied)] = true
+		if updated {
+			cs.Updated++
+		}
+	}
+
+	components := make([]string, 0, len(status.Components))
+	for name := range status.Components {
+		components = append(components, name)
+	}
+	sort.Strings(components)
+	for _, name := range components {
+		if cs := status.Components[name]; cs.Status == v1alpha1.PhaseError {
+			status.Status = v1alpha1.PhaseError
+			status.Message = fmt.Sprintf("component %s: %s", name, cs.Message)
+			break
+		}
+	}
+
+	// the resources of the components which failed to apply may no longer be rendered as well
+	if status.Status == v1alpha1.PhaseHealthy && !iop.Spec.DisablePruning {
+		c.prune(iop, status, crds, keep)
+	}
+	return status
+}
+
+// prune deletes the resources of the installation which are no longer rendered, and counts them
+// in the status of their component.
+func (c *Controller) prune(iop *v1alpha1.IstioOperator, status v1alpha1.IstioOperatorStatus,
+	crds []*unstructured.Unstructured, keep map[string]bool) {
+	selector := fmt.Sprintf("%s=%s", OwnerNameLabel, iop.Name)
+	pruned, err := apply.Prune(c.applier, apply.Kinds(crds), selector, keep, false)
+	for _, obj := range pruned {
+		scope.Infof("pruned %s %s of %s", obj.GetKind(), obj.GetName(), iop.Name)
+		component := obj.GetLabels()[ComponentLabel]
+		cs, f := status.Components[component]
+		if !f {
+			cs = &v1alpha1.ComponentStatus{Status: v1alpha1.PhaseHealthy}
+			status.Components[component] = cs
+		}
+		cs.Pruned++
+	}
+	if err != nil {
+		// the installation is up to date, the obsolete resources are pruned by the next reconciliation
+		scope.Errorf("failed to prune the resources of %s: %v", iop.Name, err)
+	}
+}
+
+// applyObject applies the resource if applying it changes it, and returns the result and whether
+// it did.
+func (c *Controller) applyObject(obj *unstructured.Unstructured, namespace string) (*unstructured.Unstructured, bool, error) {
+	live, err := c.applier.Get(obj, namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	if live != nil {
+		applied, err := c.applier.Apply(obj, namespace, true)
+		if err != nil {
+			return nil, false, err
+		}
+		if !apply.Changed(live, applied) {
+			return applied, false, nil
+		}
+	}
+	applied, err := c.applier.Apply(obj, namespace, false)
+	if err != nil {
+		return nil, false, err
+	}
+	return applied, true, nil
+}
+
+func (c *Controller) updateStatus(iop *v1alpha1.IstioOperator, status v1alpha1.IstioOperatorStatus) error {
+	if reflect.DeepEqual(iop.Status, status) {
+		return nil
+	}
+	iop.Status = status
+	u, err := v1alpha1.ToUnstructured(iop)
+	if err != nil {
+		return err
+	}
+	updated, err := c.client.Resource(v1alpha1.IstioOperatorGVR).Namespace(iop.Namespace).UpdateStatus(u, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update the status of %s/%s: %v", iop.Namespace, iop.Name, err)
+	}
+	iop.ResourceVersion = updated.GetResourceVersion()
+	return nil
+}
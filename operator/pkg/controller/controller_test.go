@@ -0,0 +1,216 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+	"istio.io/istio/operator/pkg/apply"
+	"istio.io/istio/operator/pkg/apply/fake"
+	"istio.io/istio/operator/pkg/render"
+)
+
+const chartsDir = "../../../install/kubernetes/helm"
+
+func newIstioOperator(t *testing.T, spec v1alpha1.IstioOperatorSpec) *v1alpha1.IstioOperator {
+	t.Helper()
+	return &v1alpha1.IstioOperator{
+		TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.Group + "/" + v1alpha1.Version, Kind: v1alpha1.Kind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "control-plane",
+			Namespace:  "istio-operator",
+			Generation: 1,
+		},
+		Spec: spec,
+	}
+}
+
+func newController(t *testing.T, iop *v1alpha1.IstioOperator, applier *fake.Applier) *Controller {
+	t.Helper()
+	u, err := v1alpha1.ToUnstructured(iop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), u)
+	return NewController(client, "", render.NewRenderer(chartsDir), applier)
+}
+
+func getStatus(t *testing.T, c *Controller, iop *v1alpha1.IstioOperator) v1alpha1.IstioOperatorStatus {
+	t.Helper()
+	u, err := c.client.Resource(v1alpha1.IstioOperatorGVR).Namespace(iop.Namespace).Get(iop.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := v1alpha1.FromUnstructured(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got.Status
+}
+
+func TestReconcile(t *testing.T) {
+	iop := newIstioOperator(t, v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileMinimal})
+	applier := fake.NewApplier()
+	c := newController(t, iop, applier)
+
+	if err := c.Reconcile(iop); err != nil {
+		t.Fatal(err)
+	}
+	status := getStatus(t, c, iop)
+	if status.Status != v1alpha1.PhaseHealthy || status.ObservedGeneration != 1 {
+		t.Fatalf("got status %s of generation %d, want HEALTHY of generation 1", status.Status, status.ObservedGeneration)
+	}
+	for _, name := range []string{render.BaseComponent, render.CRDComponent, "pilot"} {
+		cs := status.Components[name]
+		if cs == nil {
+			t.Fatalf("no status for component %s", name)
+		}
+		if cs.Status != v1alpha1.PhaseHealthy || cs.Resources == 0 || cs.Updated != cs.Resources {
+			t.Errorf("component %s: got %+v, want all the resources updated", name, cs)
+		}
+	}
+	if len(applier.Objects) != applier.Applied {
+		t.Errorf("applied %d resources, want %d", applier.Applied, len(applier.Objects))
+	}
+	for key, obj := range applier.Objects {
+		labels := obj.GetLabels()
+		if labels[OwnerNameLabel] != iop.Name || labels[ComponentLabel] == "" {
+			t.Errorf("%s: got labels %v, want the owner and component labels", key, labels)
+		}
+	}
+
+	// the resources are up to date
+	applied := applier.Applied
+	if err := c.Reconcile(iop); err != nil {
+		t.Fatal(err)
+	}
+	if applier.Applied != applied {
+		t.Errorf("applied %d resources up to date", applier.Applied-applied)
+	}
+	for name, cs := range getStatus(t, c, iop).Components {
+		if cs.Updated != 0 {
+			t.Errorf("component %s: got %d resources updated, want 0", name, cs.Updated)
+		}
+	}
+}
+
+func TestReconcileError(t *testing.T) {
+	cases := []struct {
+		name    string
+		spec    v1alpha1.IstioOperatorSpec
+		fail    string
+		message string
+	}{
+		{
+			name:    "unknown profile",
+			spec:    v1alpha1.IstioOperatorSpec{Profile: "huge"},
+			message: `failed to render the installation: unknown profile "huge", must be one of [default minimal remote]`,
+		},
+		{
+			name:    "apply",
+			spec:    v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileMinimal},
+			fail:    "Deployment",
+			message: "component pilot: failed to apply Deployment istio-pilot: forbidden",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			iop := newIstioOperator(t, tc.spec)
+			applier := fake.NewApplier()
+			applier.FailKind = tc.fail
+			c := newController(t, iop, applier)
+
+			if err := c.Reconcile(iop); err == nil {
+				t.Fatal("reconciled an installation which failed")
+			}
+			status := getStatus(t, c, iop)
+			if status.Status != v1alpha1.PhaseError || status.Message != tc.message {
+				t.Errorf("got status %s: %q, want ERROR: %q", status.Status, status.Message, tc.message)
+			}
+		})
+	}
+}
+
+func list(t *testing.T, applier *fake.Applier, gvk schema.GroupVersionKind, selector string) []unstructured.Unstructured {
+	t.Helper()
+	out, err := applier.List(gvk, selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestReconcilePrune(t *testing.T) {
+	cases := []struct {
+		name           string
+		disablePruning bool
+		wantPruned     bool
+	}{
+		{name: "prune", wantPruned: true},
+		{name: "pruning disabled", disablePruning: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			iop := newIstioOperator(t, v1alpha1.IstioOperatorSpec{
+				Profile:        v1alpha1.ProfileRemote,
+				DisablePruning: tc.disablePruning,
+			})
+			other := &unstructured.Unstructured{}
+			other.SetAPIVersion("v1")
+			other.SetKind("ConfigMap")
+			other.SetName("other")
+			other.SetNamespace(v1alpha1.DefaultNamespace)
+			other.SetLabels(map[string]string{OwnerNameLabel: "other-control-plane", ComponentLabel: "pilot"})
+			applier := fake.NewApplier(other)
+			c := newController(t, iop, applier)
+			if err := c.Reconcile(iop); err != nil {
+				t.Fatal(err)
+			}
+			crds := len(list(t, applier, apply.CRDKind, ""))
+
+			// the remote components are disabled
+			iop.Spec.Profile = v1alpha1.ProfileMinimal
+			iop.Generation++
+			if err := c.Reconcile(iop); err != nil {
+				t.Fatal(err)
+			}
+			status := getStatus(t, c, iop)
+			for _, component := range []string{"security", "sidecarInjectorWebhook"} {
+				objects := list(t, applier, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+					ComponentLabel+"="+component)
+				if pruned := len(objects) == 0; pruned != tc.wantPruned {
+					t.Errorf("component %s: got deployments %d, want pruned %v", component, len(objects), tc.wantPruned)
+				}
+				cs := status.Components[component]
+				if tc.wantPruned && (cs == nil || cs.Pruned == 0) {
+					t.Errorf("component %s: got status %+v, want resources pruned", component, cs)
+				}
+			}
+			if got := len(list(t, applier, apply.CRDKind, "")); got != crds {
+				t.Errorf("got %d custom resource definitions, want %d", got, crds)
+			}
+			if _, f := applier.Objects[apply.Key(other)]; !f {
+				t.Error("pruned the resource of another installation")
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package render renders the Istio helm charts into the resources of the installation described
+// by an IstioOperator.
+package render
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/engine"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+)
+
+const (
+	// istioChart is the chart of the control plane, in the charts directory.
+	istioChart = "istio"
+	// crdChart is the chart holding the custom resource definitions, in the charts directory.
+	crdChart = "istio-init"
+
+	// CRDComponent is the component of the custom resource definitions.
+	CRDComponent = "crds"
+	// BaseComponent is the component of the resources of the top level chart.
+	BaseComponent = "base"
+
+	// releaseName is the helm release name the charts are rendered with.
+	releaseName = "istio"
+)
+
+var (
+	// profileValues are the values file of each profile, in the control plane chart. The chart
+	// values are the ones of the default profile.
+	profileValues = map[string]string{
+		v1alpha1.ProfileDefault: "",
+		v1alpha1.ProfileMinimal: "values-istio-minimal.yaml",
+		v1alpha1.ProfileRemote:  "values-istio-remote.yaml",
+	}
+
+	// crdFiles are the files of the CRD chart holding the custom resource definitions.
+	crdFiles = []string{"files/crd-all.gen.yaml", "files/crd-mixer.yaml"}
+
+	documentSeparator = regexp.MustCompile(`(?m)^---`)
+)
+
+// Object is a resource of the installation.
+type Object struct {
+	*unstructured.Unstructured
+	// Component is the name of the component the resource belongs to, the subchart rendering it.
+	Component string
+}
+
+// Renderer renders the charts of a directory.
+type Renderer struct {
+	chartsDir string
+}
+
+// NewRenderer returns a renderer of the charts in the directory, which holds the istio and
+// istio-init charts.
+func NewRenderer(chartsDir string) *Renderer {
+	return &Renderer{chartsDir: chartsDir}
+}
+
+// Profiles returns the names of the supported profiles.
+func Profiles() []string {
+	out := make([]string, 0, len(profileValues))
+	for p := range profileValues {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Values returns the helm values of the installation: the values of its profile overlaid with
+// the ones of the spec.
+func (r *Renderer) Values(spec *v1alpha1.IstioOperatorSpec) (map[string]interface{}, error) {
+	profile := spec.Profile
+	if profile == "" {
+		profile = v1alpha1.ProfileDefault
+	}
+	file, f := profileValues[profile]
+	if !f {
+		return nil, fmt.Errorf("unknown profile %q, must be one of %v", profile, Profiles())
+	}
+	values := map[string]interface{}{}
+	if file != "" {
+		data, err := ioutil.ReadFile(filepath.Join(r.chartsDir, istioChart, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the values of profile %s: %v", profile, err)
+		}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse the values of profile %s: %v", profile, err)
+		}
+	}
+	global := map[string]interface{}{}
+	if spec.Hub != "" {
+		global["hub"] = spec.Hub
+	}
+	if spec.Tag != "" {
+		global["tag"] = spec.Tag
+	}
+	if spec.Revision != "" {
+		global["revision"] = spec.Revision
+	}
+	mergeValues(values, map[string]interface{}{"global": global})
+	mergeValues(values, spec.Values)
+	return values, nil
+}
+
+// Render returns the resources of the installation, in the order they are applied: the custom
+// resource definitions, then the resources of each component.
+func (r *Renderer) Render(spec *v1alpha1.IstioOperatorSpec) ([]*Object, error) {
+	values, err := r.Values(spec)
+	if err != nil {
+		return nil, err
+	}
+	namespace := spec.Namespace
+	if namespace == "" {
+		namespace = v1alpha1.DefaultNamespace
+	}
+
+	var out []*Object
+	for _, f := range crdFiles {
+		data, err := ioutil.ReadFile(filepath.Join(r.chartsDir, crdChart, f))
+		if err != nil {
+			return nil, err
+		}
+		objects, err := parseManifest(string(data), CRDComponent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", f, err)
+		}
+		out = append(out, objects...)
+	}
+
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(namespace)
+	out = append(out, &Object{Unstructured: ns, Component: BaseComponent})
+
+	rendered, err := renderChart(filepath.Join(r.chartsDir, istioChart), namespace, values)
+	if err != nil {
+		return nil, err
+	}
+	var objects []*Object
+	for _, name := range sortedKeys(rendered) {
+		if ext := filepath.Ext(name); ext != ".yaml" && ext != ".yml" {
+			// NOTES.txt
+			continue
+		}
+		parsed, err := parseManifest(rendered[name], component(name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", name, err)
+		}
+		objects = append(objects, parsed...)
+	}
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindOrder(objects[i].GetKind()) < kindOrder(objects[j].GetKind())
+	})
+	return append(out, objects...), nil
+}
+
+func renderChart(dir, namespace string, values map[string]interface{}) (map[string]string, error) {
+	c, err := chartutil.Load(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the chart: %v", err)
+	}
+	raw, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	config := &chart.Config{Raw: string(raw)}
+	// drop the subcharts of the disabled components
+	if err := chartutil.ProcessRequirementsEnabled(c, config); err != nil {
+		return nil, err
+	}
+	renderValues, err := chartutil.ToRenderValues(c, config, chartutil.ReleaseOptions{Name: releaseName, Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	return engine.New().Render(c, renderValues)
+}
+
+// component returns the component of a rendered template, the subchart it belongs to.
+func component(template string) string {
+	parts := strings.Split(template, "/")
+	if len(parts) > 3 && parts[1] == "charts" {
+		return parts[2]
+	}
+	return BaseComponent
+}
+
+func parseManifest(manifest, component string) ([]*Object, error) {
+	var out []*Object
+	for _, doc := range documentSeparator.Split(manifest, -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		js, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			return nil, err
+		}
+		if string(js) == "null" {
+			// only comments
+			continue
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(js); err != nil {
+			return nil, err
+		}
+		if u.GetKind() == "" {
+			continue
+		}
+		out = append(out, &Object{Unstructured: u, Component: component})
+	}
+	return out, nil
+}
+
+// kindOrder orders the resources so that the ones others depend on are applied first.
+func kindOrder(kind string) int {
+	switch kind {
+	case "CustomResourceDefinition":
+		return 0
+	case "Namespace":
+		return 1
+	case "ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding":
+		return 2
+	case "ConfigMap", "Secret":
+		return 3
+	case "Service", "Deployment", "DaemonSet", "StatefulSet", "Job", "HorizontalPodAutoscaler", "PodDisruptionBudget":
+		return 4
+	case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+		return 5
+	default:
+		// custom resources, whose definitions must be established
+		return 6
+	}
+}
+
+// mergeValues merges src into dst, the values of src taking precedence.
+func mergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]interface{}); ok {
+			if dm, ok := dst[k].(map[string]interface{}); ok {
+				mergeValues(dm, sm)
+				continue
+			}
+			if len(sm) == 0 {
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,306 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"istio.io/istio/operator/pkg/apis/v1alpha1"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+const chartsDir = "../../../install/kubernetes/helm"
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name       string
+		spec       v1alpha1.IstioOperatorSpec
+		components []string
+		image      string
+		deployment string
+	}{
+		{
+			name:       "minimal",
+			spec:       v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileMinimal},
+			components: []string{BaseComponent, CRDComponent, "pilot"},
+		},
+		{
+			name:       "remote",
+			spec:       v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileRemote},
+			components: []string{BaseComponent, CRDComponent, "security", "sidecarInjectorWebhook"},
+		},
+		{
+			name: "hub and tag",
+			spec: v1alpha1.IstioOperatorSpec{
+				Profile: v1alpha1.ProfileMinimal,
+				Hub:     "docker.io/istio",
+				Tag:     "canary",
+			},
+			components: []string{BaseComponent, CRDComponent, "pilot"},
+			image:      "docker.io/istio/pilot:canary",
+		},
+		{
+			name: "values",
+			spec: v1alpha1.IstioOperatorSpec{
+				Profile: v1alpha1.ProfileMinimal,
+				Values: map[string]interface{}{
+					"pilot": map[string]interface{}{"image": "pilot-custom"},
+				},
+			},
+			components: []string{BaseComponent, CRDComponent, "pilot"},
+			image:      "gcr.io/istio-testing/pilot-custom:",
+		},
+		{
+			name:       "revision",
+			spec:       v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileMinimal, Revision: "canary"},
+			components: []string{BaseComponent, CRDComponent, "pilot"},
+			deployment: "istio-pilot-canary",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			objects, err := NewRenderer(chartsDir).Render(&c.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			components := map[string]bool{}
+			deployments := map[string]bool{}
+			var images []string
+			var lastOrder int
+			for _, obj := range objects {
+				components[obj.Component] = true
+				order := kindOrder(obj.GetKind())
+				if order < lastOrder && obj.Component != CRDComponent {
+					t.Errorf("%s %s applied after resources of order %d", obj.GetKind(), obj.GetName(), lastOrder)
+				}
+				lastOrder = order
+				if obj.GetKind() == "Namespace" && obj.GetName() != v1alpha1.DefaultNamespace {
+					t.Errorf("got namespace %s, want %s", obj.GetName(), v1alpha1.DefaultNamespace)
+				}
+				if obj.GetKind() != "Deployment" {
+					continue
+				}
+				deployments[obj.GetName()] = true
+				containers, _, _ := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				for _, container := range containers {
+					images = append(images, container.(map[string]interface{})["image"].(string))
+				}
+			}
+			var got []string
+			for name := range components {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, c.components) {
+				t.Errorf("got components %v, want %v", got, c.components)
+			}
+			if c.deployment != "" && !deployments[c.deployment] {
+				t.Errorf("no deployment %s in %v", c.deployment, deployments)
+			}
+			if c.image == "" {
+				return
+			}
+			for _, image := range images {
+				if strings.HasPrefix(image, c.image) {
+					return
+				}
+			}
+			t.Errorf("no image with prefix %s in %v", c.image, images)
+		})
+	}
+}
+
+func TestRenderUnknownProfile(t *testing.T) {
+	_, err := NewRenderer(chartsDir).Render(&v1alpha1.IstioOperatorSpec{Profile: "huge"})
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "huge"`) {
+		t.Fatalf("got error %v, want unknown profile", err)
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"global": map[string]interface{}{"hub": "docker.io/istio", "tag": "1.4.0"},
+		"pilot":  map[string]interface{}{"enabled": true},
+	}
+	mergeValues(dst, map[string]interface{}{
+		"global": map[string]interface{}{"tag": "canary"},
+		"pilot":  map[string]interface{}{},
+		"mixer":  map[string]interface{}{"enabled": false},
+	})
+	want := map[string]interface{}{
+		"global": map[string]interface{}{"hub": "docker.io/istio", "tag": "canary"},
+		"pilot":  map[string]interface{}{"enabled": true},
+		"mixer":  map[string]interface{}{"enabled": false},
+	}
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("got %v, want %v", dst, want)
+	}
+}
+
+func findObject(t *testing.T, objects []*Object, kind, name string) *Object {
+	t.Helper()
+	for _, obj := range objects {
+		if obj.GetKind() == kind && obj.GetName() == name {
+			return obj
+		}
+	}
+	t.Fatalf("no %s %s", kind, name)
+	return nil
+}
+
+func TestRenderMixerMetricDimensions(t *testing.T) {
+	objects, err := NewRenderer(chartsDir).Render(&v1alpha1.IstioOperatorSpec{
+		Profile: v1alpha1.ProfileMinimal,
+		Values: map[string]interface{}{
+			"mixer": map[string]interface{}{
+				"telemetry": map[string]interface{}{
+					"enabled": true,
+					"metrics": map[string]interface{}{
+						"extraDimensions":   map[string]interface{}{"tenant": `request.headers["x-tenant-id"] | "unknown"`},
+						"removedDimensions": []interface{}{"request_protocol"},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dimensions, _, _ := unstructured.NestedStringMap(findObject(t, objects, "instance", "requestcount").Object,
+		"spec", "params", "dimensions")
+	if got, want := dimensions["tenant"], `request.headers["x-tenant-id"] | "unknown"`; got != want {
+		t.Errorf("got tenant dimension %q, want %q", got, want)
+	}
+	if _, ok := dimensions["request_protocol"]; ok {
+		t.Errorf("got removed dimension request_protocol in %v", dimensions)
+	}
+	if _, ok := dimensions["destination_service"]; !ok {
+		t.Errorf("no default dimension destination_service in %v", dimensions)
+	}
+
+	metrics, _, _ := unstructured.NestedSlice(findObject(t, objects, "handler", "prometheus").Object, "spec", "params", "metrics")
+	if len(metrics) == 0 {
+		t.Fatal("no prometheus metrics")
+	}
+	labels := map[string]bool{}
+	for _, label := range metrics[0].(map[string]interface{})["label_names"].([]interface{}) {
+		labels[label.(string)] = true
+	}
+	if !labels["tenant"] {
+		t.Errorf("no tenant label in %v", labels)
+	}
+	if labels["request_protocol"] {
+		t.Errorf("got removed label request_protocol in %v", labels)
+	}
+}
+
+func TestRenderStatsFilter(t *testing.T) {
+	objects, err := NewRenderer(chartsDir).Render(&v1alpha1.IstioOperatorSpec{
+		Profile: v1alpha1.ProfileMinimal,
+		Values: map[string]interface{}{
+			"global": map[string]interface{}{
+				"statsFilter": map[string]interface{}{
+					"enabled": true,
+					"extraDimensions": map[string]interface{}{
+						"request_user_agent": map[string]interface{}{"requestHeader": "user-agent"},
+						"source_mesh":        map[string]interface{}{"nodeMetadata": "MESH_ID"},
+					},
+					"removedDimensions": []interface{}{"request_protocol"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meshConfig, _, _ := unstructured.NestedString(findObject(t, objects, "ConfigMap", "istio").Object, "data", "mesh")
+	extensions, err := mesh.ApplyExtensionsDefaults(meshConfig)
+	if err != nil {
+		t.Fatalf("invalid mesh config: %v", err)
+	}
+	want := &mesh.StatsFilterSettings{
+		ExtraDimensions: map[string]*mesh.DimensionSource{
+			"request_user_agent": {RequestHeader: "user-agent"},
+			"source_mesh":        {NodeMetadata: "MESH_ID"},
+		},
+		RemovedDimensions: []string{"request_protocol"},
+	}
+	if !reflect.DeepEqual(extensions.GetStatsFilter(), want) {
+		t.Errorf("got stats filter %v, want %v", extensions.GetStatsFilter(), want)
+	}
+
+}
+
+func TestRenderStatsFilterDisabled(t *testing.T) {
+	objects, err := NewRenderer(chartsDir).Render(&v1alpha1.IstioOperatorSpec{Profile: v1alpha1.ProfileMinimal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meshConfig, _, _ := unstructured.NestedString(findObject(t, objects, "ConfigMap", "istio").Object, "data", "mesh")
+	extensions, err := mesh.ApplyExtensionsDefaults(meshConfig)
+	if err != nil {
+		t.Fatalf("invalid mesh config: %v", err)
+	}
+	if got := extensions.GetStatsFilter(); got != nil {
+		t.Errorf("got stats filter %v, want none", got)
+	}
+}
+
+func TestRenderOpenTelemetryTracer(t *testing.T) {
+	objects, err := NewRenderer(chartsDir).Render(&v1alpha1.IstioOperatorSpec{
+		Profile: v1alpha1.ProfileMinimal,
+		Values: map[string]interface{}{
+			"global": map[string]interface{}{
+				"proxy": map[string]interface{}{"tracer": "openTelemetry"},
+				"tracer": map[string]interface{}{
+					"openTelemetry": map[string]interface{}{
+						"address":            "otel-collector.observability:4317",
+						"resourceAttributes": map[string]interface{}{"deployment.environment": "prod"},
+						"sampling":           10,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	meshConfig, _, _ := unstructured.NestedString(findObject(t, objects, "ConfigMap", "istio").Object, "data", "mesh")
+	if _, err := mesh.ApplyMeshConfigDefaults(meshConfig); err != nil {
+		t.Fatalf("invalid mesh config: %v", err)
+	}
+	extensions, err := mesh.ApplyExtensionsDefaults(meshConfig)
+	if err != nil {
+		t.Fatalf("invalid mesh config: %v", err)
+	}
+	sampling := 10.0
+	want := &mesh.OpenTelemetryTracer{
+		Address:            "otel-collector.observability:4317",
+		ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+		Sampling:           &sampling,
+	}
+	if got := extensions.GetDefaultConfig().GetOpenTelemetry(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got OpenTelemetry tracer %v, want %v", got, want)
+	}
+}
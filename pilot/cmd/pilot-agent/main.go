@@ -82,6 +82,7 @@ var (
 	lightstepSecure          bool
 	lightstepCacertPath      string
 	datadogAgentAddress      string
+	openTelemetryTracer      string
 	connectTimeout           time.Duration
 	statsdUDPAddress         string
 	envoyMetricsService      string
@@ -120,6 +121,12 @@ var (
 	stackdriverTracingMaxNumberOfMessageEvents = env.RegisterIntVar("STACKDRIVER_TRACING_MAX_NUMBER_OF_MESSAGE_EVENTS", 200, "Sets the "+
 		"max number of message events for stackdriver")
 
+	discoveryShardingHostVar = env.RegisterStringVar("DISCOVERY_SHARDING_HOST", "", "The headless service of the "+
+		"discovery servers. If set, the proxy connects to the replica picked by consistent hashing of its node ID.")
+
+	proxyConfigVar = env.RegisterStringVar("PROXY_CONFIG", "", "The ProxyConfig in YAML of the proxy, overriding "+
+		"the flags and the defaults of the mesh. Set from the proxy.istio.io/config annotation of the pod at injection.")
+
 	sdsUdsWaitTimeout = time.Minute
 
 	// Indicates if any the remote services like AccessLogService, MetricsService have enabled tls.
@@ -231,6 +238,24 @@ var (
 			}
 			proxyConfig.ProxyAdminPort = int32(proxyAdminPort)
 			proxyConfig.Concurrency = int32(concurrency)
+			openTelemetry, err := mesh.ParseOpenTelemetryTracer(openTelemetryTracer)
+			if err != nil {
+				return fmt.Errorf("failed to parse the OpenTelemetry tracer: %v", err)
+			}
+			if pc := proxyConfigVar.Get(); pc != "" {
+				merged, err := mesh.ApplyProxyConfig(pc, proxyConfig)
+				if err != nil {
+					return fmt.Errorf("failed to apply the proxy configuration of the pod: %v", err)
+				}
+				proxyConfig = *merged
+				extensions, err := mesh.ApplyProxyConfigExtensions(pc, &mesh.ProxyConfigExtensions{
+					Tracing: &mesh.TracingExtensions{OpenTelemetry: openTelemetry},
+				})
+				if err != nil {
+					return fmt.Errorf("failed to apply the proxy configuration of the pod: %v", err)
+				}
+				openTelemetry = extensions.GetOpenTelemetry()
+			}
 
 			var pilotSAN []string
 			controlPlaneAuthEnabled := false
@@ -287,7 +312,11 @@ var (
 			}
 
 			// set tracing config
-			if lightstepAddress != "" {
+			if openTelemetry != nil {
+				// spans are exported by the OpenTelemetry tracer added to the bootstrap, which replaces
+				// any tracer from the proxy config
+				proxyConfig.Tracing = nil
+			} else if lightstepAddress != "" {
 				proxyConfig.Tracing = &meshconfig.Tracing{
 					Tracer: &meshconfig.Tracing_Lightstep_{
 						Lightstep: &meshconfig.Tracing_Lightstep{
@@ -478,6 +507,17 @@ var (
 
 			log.Infof("PilotSAN %#v", pilotSAN)
 
+			var discoveryShard *envoy.DiscoveryShard
+			if host := discoveryShardingHostVar.Get(); host != "" {
+				var err error
+				discoveryShard, err = envoy.NewDiscoveryShard(role.ServiceNode(), host, proxyConfig.DiscoveryAddress)
+				if err != nil {
+					cancel()
+					return err
+				}
+				log.Infof("Discovery replica %q", discoveryShard.Address())
+			}
+
 			envoyProxy := envoy.NewProxy(envoy.ProxyConfig{
 				Config:              proxyConfig,
 				Node:                role.ServiceNode(),
@@ -494,6 +534,9 @@ var (
 				SDSTokenPath:        sdsTokenPath,
 				ControlPlaneAuth:    controlPlaneAuthEnabled,
 				DisableReportCalls:  disableInternalTelemetry,
+				DiscoveryShard:      discoveryShard,
+
+				OpenTelemetry: openTelemetry,
 			})
 
 			agent := envoy.NewAgent(envoyProxy, features.TerminationDrainDuration())
@@ -503,7 +546,7 @@ var (
 			}
 
 			// Watcher is also kicking envoy start.
-			watcher := envoy.NewWatcher(tlsCertsToWatch, agent.Restart)
+			watcher := envoy.NewShardedWatcher(tlsCertsToWatch, discoveryShard, agent.Restart)
 			go watcher.Run(ctx)
 
 			// On SIGINT or SIGTERM, cancel the context, triggering a graceful shutdown
@@ -708,6 +751,8 @@ func init() {
 		"Path to the trusted cacert used to authenticate the pool")
 	proxyCmd.PersistentFlags().StringVar(&datadogAgentAddress, "datadogAgentAddress", "",
 		"Address of the Datadog Agent")
+	proxyCmd.PersistentFlags().StringVar(&openTelemetryTracer, "openTelemetryTracer", "",
+		"JSON configuration of the OpenTelemetry tracer exporting spans to an OTLP/gRPC collector")
 	proxyCmd.PersistentFlags().DurationVar(&connectTimeout, "connectTimeout",
 		timeDuration(values.ConnectTimeout),
 		"Connection timeout used by Envoy for supporting services")
@@ -44,11 +44,15 @@ const (
 	// quitPath is to notify the pilot agent to quit.
 	quitPath = "/quitquitquit"
 	// KubeAppProberEnvName is the name of the command line flag for pilot agent to pass app prober config.
-	// The json encoded string to pass app HTTP probe information from injector(istioctl or webhook).
+	// The json encoded string to pass app HTTP and TCP probe information from injector(istioctl or webhook).
 	// For example, ISTIO_KUBE_APP_PROBERS='{"/app-health/httpbin/livez":{"path": "/hello", "port": 8080}.
-	// indicates that httpbin container liveness prober port is 8080 and probing path is /hello.
+	// indicates that httpbin container liveness prober port is 8080 and probing path is /hello, and
+	// '{"/app-health/redis/readyz":{"tcpSocket": {"port": 6379}}}' that redis container readiness is probed
+	// by connecting to port 6379.
 	// This environment variable should never be set manually.
 	KubeAppProberEnvName = "ISTIO_KUBE_APP_PROBERS"
+	// appProbeTimeout bounds how long the agent waits for the application when probing it.
+	appProbeTimeout = 10 * time.Second
 )
 
 var (
@@ -59,7 +63,47 @@ var (
 // It's a map from the prober URL path to the Kubernetes Prober config.
 // For example, "/app-health/hello-world/livez" entry contains livenss prober config for
 // container "hello-world".
-type KubeAppProbers map[string]*corev1.HTTPGetAction
+type KubeAppProbers map[string]*Prober
+
+// Prober is the HTTP or TCP probe of an application container, executed by the agent on behalf
+// of the kubelet.
+type Prober struct {
+	HTTPGet   *corev1.HTTPGetAction   `json:"httpGet,omitempty"`
+	TCPSocket *corev1.TCPSocketAction `json:"tcpSocket,omitempty"`
+}
+
+// MarshalJSON encodes HTTP probers as a bare HTTPGetAction, which is what agents that do not
+// support TCP probers expect.
+func (p *Prober) MarshalJSON() ([]byte, error) {
+	if p.HTTPGet != nil && p.TCPSocket == nil {
+		return json.Marshal(p.HTTPGet)
+	}
+	type prober Prober
+	return json.Marshal((*prober)(p))
+}
+
+// UnmarshalJSON decodes the prober, accepting HTTP probers encoded as a bare HTTPGetAction.
+func (p *Prober) UnmarshalJSON(data []byte) error {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	_, isHTTP := fields["httpGet"]
+	_, isTCP := fields["tcpSocket"]
+	if !isHTTP && !isTCP {
+		p.HTTPGet = &corev1.HTTPGetAction{}
+		return json.Unmarshal(data, p.HTTPGet)
+	}
+	type prober Prober
+	return json.Unmarshal(data, (*prober)(p))
+}
+
+func (p *Prober) port() intstr.IntOrString {
+	if p.TCPSocket != nil {
+		return p.TCPSocket.Port
+	}
+	return p.HTTPGet.Port
+}
 
 // Config for the status server.
 type Config struct {
@@ -101,7 +145,10 @@ func NewServer(config Config) (*Server, error) {
 		if !appProberPattern.Match([]byte(path)) {
 			return nil, fmt.Errorf(`invalid key, must be in form of regex pattern ^/app-health/[^\/]+/(livez|readyz)$`)
 		}
-		if prober.Port.Type != intstr.Int {
+		if (prober.HTTPGet == nil) == (prober.TCPSocket == nil) {
+			return nil, fmt.Errorf("invalid prober config for %v, exactly one of httpGet and tcpSocket must be set", path)
+		}
+		if prober.port().Type != intstr.Int {
 			return nil, fmt.Errorf("invalid prober config for %v, the port must be int type", path)
 		}
 	}
@@ -214,10 +261,29 @@ func (s *Server) handleAppProbe(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if prober.TCPSocket != nil {
+		s.handleAppTCPProbe(w, path, prober.TCPSocket)
+		return
+	}
+	s.handleAppHTTPProbe(w, req, path, prober.HTTPGet)
+}
+
+func (s *Server) handleAppTCPProbe(w http.ResponseWriter, path string, prober *corev1.TCPSocketAction) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("localhost", strconv.Itoa(prober.Port.IntValue())), appProbeTimeout)
+	if err != nil {
+		log.Errorf("TCP probe of app failed: %v, original URL path = %v", err, path)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_ = conn.Close()
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) handleAppHTTPProbe(w http.ResponseWriter, req *http.Request, path string, prober *corev1.HTTPGetAction) {
 	// Construct a request sent to the application.
 	httpClient := &http.Client{
 		// TODO: figure out the appropriate timeout?
-		Timeout: 10 * time.Second,
+		Timeout: appProbeTimeout,
 		// We skip the verification since kubelet skips the verification for HTTPS prober as well
 		// https://kubernetes.io/docs/tasks/configure-pod-container/configure-liveness-readiness-probes/#configure-probes
 		Transport: &http.Transport{
@@ -76,6 +76,21 @@ func TestNewServer(t *testing.T) {
 		{
 			httpProbe: `{}`,
 		},
+		// A valid input with HTTP and TCP probers.
+		{
+			httpProbe: `{"/app-health/hello-world/readyz": {"httpGet": {"path": "/hello/sunnyvale", "port": 8080}},` +
+				`"/app-health/business/livez": {"tcpSocket": {"port": 9090}}}`,
+		},
+		// TCP port is not Int typed.
+		{
+			httpProbe: `{"/app-health/business/livez": {"tcpSocket": {"port": "container-port-dontknow"}}}`,
+			err:       "must be int type",
+		},
+		// Both HTTP and TCP are set.
+		{
+			httpProbe: `{"/app-health/business/livez": {"httpGet": {"port": 8080}, "tcpSocket": {"port": 9090}}}`,
+			err:       "exactly one of httpGet and tcpSocket",
+		},
 	}
 	for _, tc := range testCases {
 		_, err := NewServer(Config{
@@ -107,11 +122,21 @@ func TestAppProbe(t *testing.T) {
 	go http.Serve(listener, &handler{})
 	appPort := listener.Addr().(*net.TCPAddr).Port
 
+	// A port nothing listens on.
+	closed, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed to allocate unused port %v", err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
 	// Starts the pilot agent status server.
 	server, err := NewServer(Config{
 		StatusPort: 0,
 		KubeAppHTTPProbers: fmt.Sprintf(`{"/app-health/hello-world/readyz": {"path": "/hello/sunnyvale", "port": %v},
-"/app-health/hello-world/livez": {"port": %v}}`, appPort, appPort),
+"/app-health/hello-world/livez": {"port": %v},
+"/app-health/tcp/readyz": {"tcpSocket": {"port": %v}},
+"/app-health/tcp/livez": {"tcpSocket": {"port": %v}}}`, appPort, appPort, appPort, closedPort),
 	})
 	if err != nil {
 		t.Errorf("failed to create status server %v", err)
@@ -143,6 +168,14 @@ func TestAppProbe(t *testing.T) {
 			probePath:  fmt.Sprintf(":%v/app-health/hello-world/livez", statusPort),
 			statusCode: http.StatusOK,
 		},
+		{
+			probePath:  fmt.Sprintf(":%v/app-health/tcp/readyz", statusPort),
+			statusCode: http.StatusOK,
+		},
+		{
+			probePath:  fmt.Sprintf(":%v/app-health/tcp/livez", statusPort),
+			statusCode: http.StatusInternalServerError,
+		},
 	}
 	for _, tc := range testCases {
 		client := http.Client{}
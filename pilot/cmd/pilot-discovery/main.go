@@ -99,7 +99,10 @@ func init() {
 	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Service.Registries, "registries",
 		[]string{string(serviceregistry.KubernetesRegistry)},
 		fmt.Sprintf("Comma separated list of platform service registries to read from (choose one or more from {%s, %s, %s, %s})",
-			serviceregistry.KubernetesRegistry, serviceregistry.ConsulRegistry, serviceregistry.MCPRegistry, serviceregistry.MockRegistry))
+			serviceregistry.KubernetesRegistry, serviceregistry.ConsulRegistry, serviceregistry.MCPRegistry, serviceregistry.MockRegistry)+
+			" or a registry of the linked in providers")
+	discoveryCmd.PersistentFlags().StringToStringVar(&serverArgs.Service.ProviderArgs, "registryArgs", nil,
+		"Arguments of the service registries of the linked in providers, as key=value pairs")
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Config.ClusterRegistriesNamespace, "clusterRegistriesNamespace", metav1.NamespaceAll,
 		"Namespace for ConfigMap which stores clusters configs")
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Config.KubeConfig, "kubeconfig", "",
@@ -108,6 +111,8 @@ func init() {
 		fmt.Sprintf("File name for Istio mesh configuration. If not specified, a default mesh will be used."))
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.NetworksConfigFile, "networksConfig", "/etc/istio/config/meshNetworks",
 		fmt.Sprintf("File name for Istio mesh networks configuration. If not specified, a default mesh networks will be used."))
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.FlagsConfigFile, "flagsConfig", "/etc/istio/config/pilotFlags",
+		"File name for the runtime flags of Pilot, overriding the dynamic feature flags without restart")
 	discoveryCmd.PersistentFlags().StringVarP(&serverArgs.Namespace, "namespace", "n", "",
 		"Select a namespace where the controller resides. If not set, uses ${POD_NAMESPACE} environment variable")
 	discoveryCmd.PersistentFlags().StringSliceVar(&serverArgs.Plugins, "plugins", bootstrap.DefaultPlugins,
@@ -138,6 +143,14 @@ func init() {
 		"The domain serves to identify the system with spiffe")
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Service.Consul.ServerURL, "consulserverURL", "",
 		"URL for the Consul server")
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Service.Consul.Namespace, "consulNamespace", "",
+		"Consul Enterprise namespace to read the services from")
+	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.Service.Consul.PassingOnly, "consulPassingOnly", false,
+		"Only use the Consul endpoints passing all of their health checks")
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.Service.MCPSink.Address, "mcpSinkAddress", "",
+		"Address of the MCP server of the inventory serving the services of the MCPSink registry, prefixed with xds:// for an xDS server")
+	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.Service.MCPSink.IstioMutual, "mcpSinkIstioMutual", false,
+		"Use Istio mutual TLS to connect to the MCP server of the MCPSink registry")
 
 	// using address, so it can be configured as localhost:.. (possibly UDS in future)
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.DiscoveryOptions.HTTPAddr, "httpAddr", ":8080",
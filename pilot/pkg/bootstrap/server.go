@@ -33,6 +33,7 @@ import (
 	"istio.io/istio/security/pkg/k8s/chiron"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/ghodss/yaml"
 	middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	multierror "github.com/hashicorp/go-multierror"
@@ -57,6 +58,7 @@ import (
 	"istio.io/istio/pilot/pkg/config/clusterregistry"
 	"istio.io/istio/pilot/pkg/config/coredatamodel"
 	"istio.io/istio/pilot/pkg/config/kube/crd/controller"
+	"istio.io/istio/pilot/pkg/config/kube/gateway"
 	"istio.io/istio/pilot/pkg/config/kube/ingress"
 	"istio.io/istio/pilot/pkg/config/memory"
 	configmonitor "istio.io/istio/pilot/pkg/config/monitor"
@@ -71,7 +73,10 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry/consul"
 	"istio.io/istio/pilot/pkg/serviceregistry/external"
 	controller2 "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
+	srmcp "istio.io/istio/pilot/pkg/serviceregistry/mcp"
 	srmemory "istio.io/istio/pilot/pkg/serviceregistry/memory"
+	"istio.io/istio/pilot/pkg/serviceregistry/provider"
+	"istio.io/istio/pilot/pkg/status"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/mesh"
@@ -82,10 +87,12 @@ import (
 	"istio.io/istio/pkg/mcp/creds"
 	"istio.io/istio/pkg/mcp/monitoring"
 	"istio.io/istio/pkg/mcp/sink"
+	mcpxds "istio.io/istio/pkg/mcp/xds"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 )
@@ -108,6 +115,8 @@ const (
 	// URL types supported by the config store
 	// example fs:///tmp/configroot
 	fsScheme = "fs"
+	// example xds://config-server.example.com:15010, for a server sending the config over the xDS transport
+	xdsScheme = "xds"
 
 	// DefaultCertGracePeriodRatio is the default length of certificate rotation grace period,
 	// configured as the ratio of the certificate TTL.
@@ -125,8 +134,9 @@ const (
 )
 
 var (
-	// FilepathWalkInterval dictates how often the file system is walked for config
-	FilepathWalkInterval = 100 * time.Millisecond
+	// FilepathWalkInterval dictates how often the file system is walked for config, in addition to the
+	// walks triggered by the changes of the watched files.
+	FilepathWalkInterval = 10 * time.Second
 
 	// PilotCertDir is the default location for mTLS certificates used by pilot
 	// Visible for tests - at runtime can be set by PILOT_CERT_DIR environment variable.
@@ -139,6 +149,7 @@ var (
 		plugin.Authz,
 		plugin.Health,
 		plugin.Mixer,
+		plugin.Stats,
 	}
 )
 
@@ -164,8 +175,8 @@ type MeshArgs struct {
 }
 
 // ConfigArgs provide configuration options for the configuration controller. If FileDir is set, that directory will
-// be monitored for CRD yaml files and will update the controller as those files change (This is used for testing
-// purposes). Otherwise, a CRD client is created based on the configuration.
+// be watched for CRD yaml files and will update the controller as those files change, so that Pilot can run without
+// Kubernetes. Otherwise, a CRD client is created based on the configuration.
 type ConfigArgs struct {
 	ControllerOptions          controller2.Options
 	ClusterRegistriesNamespace string
@@ -194,12 +205,27 @@ func (ca *ConfigArgs) buildLedger() ledger.Ledger {
 // ConsulArgs provides configuration for the Consul service registry.
 type ConsulArgs struct {
 	ServerURL string
+	// Namespace is the Consul Enterprise namespace to read the services from
+	Namespace string
+	// PassingOnly drops the endpoints failing their Consul health checks
+	PassingOnly bool
+}
+
+// MCPSinkArgs provides configuration for the registry fed over MCP by an external inventory system.
+type MCPSinkArgs struct {
+	// Address is the address of the MCP server of the inventory, prefixed with xds:// for an xDS server
+	Address string
+	// IstioMutual secures the connection with the Istio certificates of Pilot
+	IstioMutual bool
 }
 
 // ServiceArgs provides the composite configuration for all service registries in the system.
 type ServiceArgs struct {
 	Registries []string
 	Consul     ConsulArgs
+	MCPSink    MCPSinkArgs
+	// ProviderArgs are the arguments of the registries implemented by providers registered out of tree.
+	ProviderArgs map[string]string
 }
 
 // PilotArgs provides all of the configuration parameters for the Pilot discovery service.
@@ -211,6 +237,7 @@ type PilotArgs struct {
 	Service                  ServiceArgs
 	MeshConfig               *meshconfig.MeshConfig
 	NetworksConfigFile       string
+	FlagsConfigFile          string
 	CtrlZOptions             *ctrlz.Options
 	Plugins                  []string
 	MCPMaxMessageSize        int
@@ -256,6 +283,7 @@ type Server struct {
 	ServiceController *aggregate.Controller
 
 	mesh             *meshconfig.MeshConfig
+	meshExtensions   *mesh.Extensions
 	meshNetworks     *meshconfig.MeshNetworks
 	configController model.ConfigStoreCache
 
@@ -272,12 +300,30 @@ type Server struct {
 	fileWatcher           filewatcher.FileWatcher
 	discoveryOptions      *coredatamodel.DiscoveryOptions
 	mcpDiscovery          *coredatamodel.MCPDiscovery
+	mcpSinkRegistry       *srmcp.Registry
 	incrementalMcpOptions *coredatamodel.Options
 	mcpOptions            *coredatamodel.Options
 	certController        *chiron.WebhookController
+
+	// servingCert is the key and certificate served by the secure gRPC and HTTPS servers,
+	// reloaded from the certificate directory each time the files are rotated.
+	servingCert      *tls.Certificate
+	servingCertMutex sync.RWMutex
+
+	// configOrigins are the config controllers, with the source their configs come from.
+	configOrigins []configOrigin
 }
 
-var podNamespaceVar = env.RegisterStringVar("POD_NAMESPACE", "", "")
+// configOrigin is a config controller, with the source its configs come from, e.g. a cluster or an MCP server.
+type configOrigin struct {
+	origin     string
+	controller model.ConfigStoreCache
+}
+
+var (
+	podNamespaceVar = env.RegisterStringVar("POD_NAMESPACE", "", "")
+	podNameVar      = env.RegisterStringVar("POD_NAME", "", "")
+)
 
 // NewServer creates a new Server instance based on the provided arguments.
 func NewServer(args PilotArgs) (*Server, error) {
@@ -312,6 +358,9 @@ func NewServer(args PilotArgs) (*Server, error) {
 	if err := s.initMeshNetworks(&args); err != nil {
 		return nil, fmt.Errorf("mesh networks: %v", err)
 	}
+	if err := s.initRuntimeFlags(&args); err != nil {
+		return nil, fmt.Errorf("runtime flags: %v", err)
+	}
 	// Certificate controller is created before MCP
 	// controller in case MCP server pod waits to mount a certificate
 	// to be provisioned by the certificate controller.
@@ -327,6 +376,14 @@ func NewServer(args PilotArgs) (*Server, error) {
 	if err := s.initDiscoveryService(&args); err != nil {
 		return nil, fmt.Errorf("discovery service: %v", err)
 	}
+	if err := s.initStatus(&args); err != nil {
+		return nil, fmt.Errorf("status: %v", err)
+	}
+	s.initConfigErrorEvents()
+	s.initGatewayDeployments()
+	if err := s.initGatewayCertificates(&args); err != nil {
+		return nil, fmt.Errorf("gateway certificates: %v", err)
+	}
 	if err := s.initMonitor(&args); err != nil {
 		return nil, fmt.Errorf("monitor: %v", err)
 	}
@@ -335,6 +392,8 @@ func NewServer(args PilotArgs) (*Server, error) {
 	}
 
 	if args.CtrlZOptions != nil {
+		// ControlZ is superseded by the introspection API, served under /introspect/v1/ on the HTTP port.
+		log.Infof("Starting ControlZ, deprecated in favor of the introspection API at /introspect/v1/")
 		_, _ = ctrlz.Run(args.CtrlZOptions, nil)
 	}
 
@@ -387,6 +446,7 @@ func (s *Server) initClusterRegistries(args *PilotArgs) (err error) {
 			args.Config.ControllerOptions.WatchedNamespace,
 			args.Config.ControllerOptions.DomainSuffix,
 			args.Config.ControllerOptions.ResyncPeriod,
+			s.meshExtensions.GetDiscoverySelectors(),
 			s.ServiceController,
 			s.EnvoyXdsServer,
 			s.meshNetworks)
@@ -440,12 +500,15 @@ func (s *Server) initMesh(args *PilotArgs) error {
 		return nil
 	}
 	var meshConfig *meshconfig.MeshConfig
+	var meshExtensions *mesh.Extensions
 	var err error
 
 	if args.Mesh.ConfigFile != "" {
 		meshConfig, err = cmd.ReadMeshConfig(args.Mesh.ConfigFile)
 		if err != nil {
 			log.Warnf("failed to read mesh configuration, using default: %v", err)
+		} else if meshExtensions, err = cmd.ReadMeshExtensions(args.Mesh.ConfigFile); err != nil {
+			log.Warnf("failed to read mesh configuration extensions, using default: %v", err)
 		}
 
 		// Watch the config file for changes and reload if it got modified
@@ -456,15 +519,22 @@ func (s *Server) initMesh(args *PilotArgs) error {
 				log.Warnf("failed to read mesh configuration, using default: %v", err)
 				return
 			}
-			if !reflect.DeepEqual(meshConfig, s.mesh) {
-				log.Infof("mesh configuration updated to: %s", spew.Sdump(meshConfig))
+			meshExtensions, err = cmd.ReadMeshExtensions(args.Mesh.ConfigFile)
+			if err != nil {
+				log.Warnf("failed to read mesh configuration extensions, using default: %v", err)
+				return
+			}
+			if !reflect.DeepEqual(meshConfig, s.mesh) || !reflect.DeepEqual(meshExtensions, s.meshExtensions) {
+				log.Infof("mesh configuration updated to: %s %s", spew.Sdump(meshConfig), spew.Sdump(meshExtensions))
 				if !reflect.DeepEqual(meshConfig.ConfigSources, s.mesh.ConfigSources) {
 					log.Infof("mesh configuration sources have changed")
 					//TODO Need to re-create or reload initConfigController()
 				}
 				s.mesh = meshConfig
+				s.meshExtensions = meshExtensions
 				if s.EnvoyXdsServer != nil {
 					s.EnvoyXdsServer.Env.Mesh = meshConfig
+					s.EnvoyXdsServer.Env.MeshExtensions = meshExtensions
 					s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
 				}
 			}
@@ -473,11 +543,17 @@ func (s *Server) initMesh(args *PilotArgs) error {
 
 	if meshConfig == nil {
 		// Config file either wasn't specified or failed to load - use a default mesh.
-		if _, meshConfig, err = GetMeshConfig(s.kubeClient, controller2.IstioNamespace, controller2.IstioConfigMap); err != nil {
+		var cfg *v1.ConfigMap
+		if cfg, meshConfig, err = GetMeshConfig(s.kubeClient, controller2.IstioNamespace, controller2.IstioConfigMap); err != nil {
 			log.Warnf("failed to read the default mesh configuration: %v, from the %s config map in the %s namespace",
 				err, controller2.IstioConfigMap, controller2.IstioNamespace)
 			return err
 		}
+		if cfg != nil {
+			if meshExtensions, err = mesh.ApplyExtensionsDefaults(cfg.Data[ConfigMapKey]); err != nil {
+				log.Warnf("failed to read the default mesh configuration extensions, using default: %v", err)
+			}
+		}
 
 		// Allow some overrides for testing purposes.
 		if args.Mesh.MixerAddress != "" {
@@ -486,11 +562,18 @@ func (s *Server) initMesh(args *PilotArgs) error {
 		}
 	}
 
+	if meshExtensions == nil {
+		defaultExtensions := mesh.DefaultExtensions()
+		meshExtensions = &defaultExtensions
+	}
+
 	log.Infof("mesh configuration %s", spew.Sdump(meshConfig))
+	log.Infof("mesh configuration extensions %s", spew.Sdump(meshExtensions))
 	log.Infof("version %s", version.Info.String())
 	log.Infof("flags %s", spew.Sdump(args))
 
 	s.mesh = meshConfig
+	s.meshExtensions = meshExtensions
 	return nil
 }
 
@@ -544,6 +627,63 @@ func (s *Server) initMeshNetworks(args *PilotArgs) error { //nolint: unparam
 	return nil
 }
 
+// initRuntimeFlags applies the runtime flags of Pilot, overriding the values of the dynamic feature flags
+// read from the environment, and reapplies them whenever the file changes, without restarting Pilot.
+func (s *Server) initRuntimeFlags(args *PilotArgs) error { //nolint: unparam
+	if args.FlagsConfigFile == "" {
+		log.Info("runtime flags not provided")
+		return nil
+	}
+
+	applyRuntimeFlags := func() bool {
+		flags, err := readRuntimeFlags(args.FlagsConfigFile)
+		if err != nil {
+			log.Warnf("failed to read runtime flags from %q: %v", args.FlagsConfigFile, err)
+			return false
+		}
+		changed, err := features.ApplyDynamicFlags(flags)
+		if err != nil {
+			log.Warnf("invalid runtime flags in %q: %v", args.FlagsConfigFile, err)
+			return false
+		}
+		for _, name := range changed {
+			log.Infof("runtime flag %s updated", name)
+		}
+		return len(changed) > 0
+	}
+	applyRuntimeFlags()
+
+	// Watch the runtime flags file for changes and reapply it if it got modified
+	s.addFileWatcher(args.FlagsConfigFile, func() {
+		if applyRuntimeFlags() && s.EnvoyXdsServer != nil {
+			s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
+		}
+	})
+
+	return nil
+}
+
+// readRuntimeFlags reads the runtime flags from a YAML map of the flag names to their values.
+// A missing file holds no runtime flags.
+func readRuntimeFlags(file string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	flags := make(map[string]string, len(values))
+	for name, value := range values {
+		flags[name] = fmt.Sprint(value)
+	}
+	return flags, nil
+}
+
 func (s *Server) getKubeCfgFile(args *PilotArgs) string {
 	return args.Config.KubeConfig
 }
@@ -603,10 +743,18 @@ func (s *Server) initMCPConfigController(args *PilotArgs) error {
 					return err
 				}
 				configStores = append(configStores, configController)
+				s.configOrigins = append(s.configOrigins, configOrigin{configSource.Address, configController})
 				continue
 			}
 		}
 
+		address := configSource.Address
+		newSourceClient := mcpapi.NewResourceSourceClient
+		if strings.HasPrefix(address, xdsScheme+"://") {
+			address = strings.TrimPrefix(address, xdsScheme+"://")
+			newSourceClient = mcpxds.NewResourceSourceClient
+		}
+
 		securityOption, err := mcpSecurityOptions(ctx, cancel, configSource)
 		if err != nil {
 			return err
@@ -623,7 +771,7 @@ func (s *Server) initMCPConfigController(args *PilotArgs) error {
 
 		conn, err := grpc.DialContext(
 			ctx,
-			configSource.Address,
+			address,
 			securityOption,
 			msgSizeOption,
 			keepaliveOption,
@@ -635,7 +783,8 @@ func (s *Server) initMCPConfigController(args *PilotArgs) error {
 			return err
 		}
 		conns = append(conns, conn)
-		s.mcpController(args, conn, reporter, &clients, &configStores)
+		sourceStores := len(configStores)
+		s.mcpController(args, newSourceClient(conn), reporter, &clients, &configStores)
 
 		// create MCP SyntheticServiceEntryController
 		if resourceContains(configSource.SubscribedResources, meshconfig.Resource_SERVICE_REGISTRY) {
@@ -644,7 +793,7 @@ func (s *Server) initMCPConfigController(args *PilotArgs) error {
 			args.Service.Registries = []string{string(serviceregistry.MCPRegistry)}
 			conn, err := grpc.DialContext(
 				ctx,
-				configSource.Address,
+				address,
 				securityOption,
 				msgSizeOption,
 				keepaliveOption,
@@ -656,7 +805,10 @@ func (s *Server) initMCPConfigController(args *PilotArgs) error {
 				return err
 			}
 			conns = append(conns, conn)
-			s.sseMCPController(args, conn, reporter, &clients, &configStores)
+			s.sseMCPController(args, newSourceClient(conn), reporter, &clients, &configStores)
+		}
+		for _, store := range configStores[sourceStores:] {
+			s.configOrigins = append(s.configOrigins, configOrigin{configSource.Address, store})
 		}
 	}
 
@@ -771,7 +923,7 @@ func mcpSecurityOptions(ctx context.Context, cancel context.CancelFunc, configSo
 }
 
 func (s *Server) mcpController(args *PilotArgs,
-	conn *grpc.ClientConn,
+	cl mcpapi.ResourceSourceClient,
 	reporter monitoring.Reporter,
 	clients *[]*sink.Client,
 	configStores *[]model.ConfigStoreCache) {
@@ -797,7 +949,6 @@ func (s *Server) mcpController(args *PilotArgs,
 		Reporter:          reporter,
 	}
 
-	cl := mcpapi.NewResourceSourceClient(conn)
 	mcpClient := sink.NewClient(cl, sinkOptions)
 	configz.Register(mcpClient)
 	*clients = append(*clients, mcpClient)
@@ -805,7 +956,7 @@ func (s *Server) mcpController(args *PilotArgs,
 }
 
 func (s *Server) sseMCPController(args *PilotArgs,
-	conn *grpc.ClientConn,
+	incSrcClient mcpapi.ResourceSourceClient,
 	reporter monitoring.Reporter,
 	clients *[]*sink.Client,
 	configStores *[]model.ConfigStoreCache) {
@@ -829,7 +980,6 @@ func (s *Server) sseMCPController(args *PilotArgs,
 		ID:       clientNodeID,
 		Reporter: reporter,
 	}
-	incSrcClient := mcpapi.NewResourceSourceClient(conn)
 	incMcpClient := sink.NewClient(incSrcClient, incrementalSinkOptions)
 	configz.Register(incMcpClient)
 	*clients = append(*clients, incMcpClient)
@@ -852,6 +1002,7 @@ func (s *Server) initConfigController(args *PilotArgs) error {
 		}
 
 		s.configController = configController
+		s.configOrigins = append(s.configOrigins, configOrigin{fsScheme + "://" + args.Config.FileDir, configController})
 	} else {
 		cfgController, err := s.makeKubeConfigController(args)
 		if err != nil {
@@ -859,6 +1010,7 @@ func (s *Server) initConfigController(args *PilotArgs) error {
 		}
 
 		s.configController = cfgController
+		s.configOrigins = append(s.configOrigins, configOrigin{string(serviceregistry.KubernetesRegistry), cfgController})
 	}
 
 	// Defer starting the controller until after the service is created.
@@ -870,13 +1022,15 @@ func (s *Server) initConfigController(args *PilotArgs) error {
 	// If running in ingress mode (requires k8s), wrap the config controller.
 	if hasKubeRegistry(args) && s.mesh.IngressControllerMode != meshconfig.MeshConfig_OFF {
 		// Wrap the config controller with a cache.
+		ingressController := ingress.NewController(s.kubeClient, s.mesh, args.Config.ControllerOptions)
 		configController, err := configaggregate.MakeCache([]model.ConfigStoreCache{
 			s.configController,
-			ingress.NewController(s.kubeClient, s.mesh, args.Config.ControllerOptions),
+			ingressController,
 		})
 		if err != nil {
 			return err
 		}
+		s.configOrigins = append(s.configOrigins, configOrigin{string(serviceregistry.KubernetesRegistry), ingressController})
 
 		// Update the config controller
 		s.configController = configController
@@ -916,8 +1070,7 @@ func (s *Server) makeKubeConfigController(args *PilotArgs) (model.ConfigStoreCac
 }
 
 func (s *Server) makeFileMonitor(fileDir string, configController model.ConfigStore) error {
-	fileSnapshot := configmonitor.NewFileSnapshot(fileDir, schemas.Istio)
-	fileMonitor := configmonitor.NewMonitor("file-monitor", configController, FilepathWalkInterval, fileSnapshot.ReadConfigFiles)
+	fileMonitor := configmonitor.NewFileMonitor("file-monitor", configController, FilepathWalkInterval, fileDir, schemas.Istio)
 
 	// Defer starting the file monitor until after the service is created.
 	s.addStartFunc(func(stop <-chan struct{}) error {
@@ -933,6 +1086,7 @@ func (s *Server) createK8sServiceControllers(serviceControllers *aggregate.Contr
 	clusterID := string(serviceregistry.KubernetesRegistry)
 	log.Infof("Primary Cluster name: %s", clusterID)
 	args.Config.ControllerOptions.ClusterID = clusterID
+	args.Config.ControllerOptions.DiscoverySelectors = s.meshExtensions.GetDiscoverySelectors()
 	kubectl := controller2.NewController(s.kubeClient, args.Config.ControllerOptions)
 	s.kubeRegistry = kubectl
 	serviceControllers.AddRegistry(
@@ -978,6 +1132,10 @@ func (s *Server) initServiceControllers(args *PilotArgs) error {
 			if err := s.initConsulRegistry(serviceControllers, args); err != nil {
 				return err
 			}
+		case serviceregistry.MCPSinkRegistry:
+			if err := s.initMCPSinkRegistry(serviceControllers, args); err != nil {
+				return err
+			}
 		case serviceregistry.MCPRegistry:
 			if s.mcpDiscovery != nil {
 				serviceControllers.AddRegistry(
@@ -988,7 +1146,9 @@ func (s *Server) initServiceControllers(args *PilotArgs) error {
 					})
 			}
 		default:
-			return fmt.Errorf("service registry %s is not supported", r)
+			if err := s.initProviderRegistry(serviceControllers, serviceRegistry, args); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -1043,6 +1203,7 @@ func (s *Server) initMemoryRegistry(serviceControllers *aggregate.Controller) {
 func (s *Server) initDiscoveryService(args *PilotArgs) error {
 	environment := &model.Environment{
 		Mesh:             s.mesh,
+		MeshExtensions:   s.meshExtensions,
 		MeshNetworks:     s.meshNetworks,
 		IstioConfigStore: s.istioConfigStore,
 		ServiceDiscovery: s.ServiceController,
@@ -1051,6 +1212,12 @@ func (s *Server) initDiscoveryService(args *PilotArgs) error {
 
 	s.EnvoyXdsServer = envoyv2.NewDiscoveryServer(environment,
 		istio_networking.NewConfigGenerator(args.Plugins))
+	if s.kubeClient != nil {
+		s.EnvoyXdsServer.DebugAuthenticator = envoyv2.NewDebugAuthenticator(s.kubeClient)
+	}
+	// Pilot is not ready before the caches of all the registries and config stores have synced.
+	s.EnvoyXdsServer.AddReadinessCheck("service registries", s.ServiceController.HasSynced)
+	s.EnvoyXdsServer.AddReadinessCheck("config stores", s.configController.HasSynced)
 	s.mux = http.NewServeMux()
 	s.EnvoyXdsServer.InitDebug(s.mux, s.ServiceController, args.DiscoveryOptions.EnableProfiling)
 
@@ -1064,11 +1231,16 @@ func (s *Server) initDiscoveryService(args *PilotArgs) error {
 		s.kubeRegistry.Env = environment
 		s.kubeRegistry.InitNetworkLookup(s.meshNetworks)
 		s.kubeRegistry.XDSUpdater = s.EnvoyXdsServer
+		environment.Namespaces = s.kubeRegistry
 	}
 
 	if s.mcpOptions != nil {
 		s.mcpOptions.XDSUpdater = s.EnvoyXdsServer
 	}
+	if s.mcpSinkRegistry != nil {
+		s.mcpSinkRegistry.ClusterID = string(serviceregistry.MCPSinkRegistry)
+		s.mcpSinkRegistry.XDSUpdater = s.EnvoyXdsServer
+	}
 	if s.incrementalMcpOptions != nil {
 		clusterID := args.Config.ControllerOptions.ClusterID
 		s.incrementalMcpOptions.XDSUpdater = s.EnvoyXdsServer
@@ -1191,10 +1363,115 @@ func (s *Server) initDiscoveryService(args *PilotArgs) error {
 	return nil
 }
 
+// initStatus starts writing in the status of the networking resources how many proxies have acked their latest
+// version, when enabled.
+func (s *Server) initStatus(args *PilotArgs) error {
+	if !features.EnableStatus || s.kubeClient == nil {
+		return nil
+	}
+	if !features.EnableDistributionTracking {
+		log.Warn("Not writing the status of the resources: the config distribution tracking is disabled")
+		return nil
+	}
+
+	restConfig, err := kubelib.BuildClientConfig(s.getKubeCfgFile(args), "")
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	podName := podNameVar.Get()
+	if podName == "" {
+		podName, _ = os.Hostname()
+	}
+
+	reporter := status.NewReporter(s.kubeClient, args.Namespace, podName, s.istioConfigStore, s.EnvoyXdsServer,
+		features.StatusUpdateInterval)
+	writer := status.NewWriter(s.kubeClient, dynamicClient, args.Namespace, s.istioConfigStore,
+		features.StatusUpdateInterval)
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go reporter.Run(stop)
+		go func() {
+			if err := writer.Run(stop, podName); err != nil {
+				log.Errorf("Unable to run for the leadership of the status updates: %v", err)
+			}
+		}()
+		return nil
+	})
+	return nil
+}
+
+// initConfigErrorEvents starts emitting the errors of the translation of the config resources as events on them.
+func (s *Server) initConfigErrorEvents() {
+	if !features.EnableConfigErrorEvents || s.kubeClient == nil || s.istioConfigStore == nil {
+		return
+	}
+	podName := podNameVar.Get()
+	if podName == "" {
+		podName, _ = os.Hostname()
+	}
+	emitter := status.NewEventEmitter(s.kubeClient, podName, s.istioConfigStore, s.EnvoyXdsServer,
+		features.StatusUpdateInterval)
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go emitter.Run(stop)
+		return nil
+	})
+}
+
+// initGatewayDeployments starts provisioning the deployments of the Gateways of the managed class, when set.
+func (s *Server) initGatewayDeployments() {
+	if features.ManagedGatewayClass == "" || s.kubeClient == nil || s.configController == nil {
+		return
+	}
+	controller := gateway.NewDeploymentController(s.kubeClient, s.configController, features.ManagedGatewayClass)
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go controller.Run(stop)
+		return nil
+	})
+}
+
+// initGatewayCertificates starts provisioning the certificates of the Gateways via ACME, when enabled.
+func (s *Server) initGatewayCertificates(args *PilotArgs) error {
+	if !features.EnableACME || s.kubeClient == nil || s.configController == nil {
+		return nil
+	}
+	restConfig, err := kubelib.BuildClientConfig(s.getKubeCfgFile(args), "")
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+	podName := podNameVar.Get()
+	if podName == "" {
+		podName, _ = os.Hostname()
+	}
+	controller, err := gateway.NewCertificateController(s.kubeClient, dynamicClient, s.configController,
+		features.ACMEDirectoryURL, features.ACMEEmail, features.ACMEChallengeType, args.Namespace)
+	if err != nil {
+		return err
+	}
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go func() {
+			if err := controller.Run(stop, podName); err != nil {
+				log.Errorf("Unable to run for the leadership of the ACME certificates: %v", err)
+			}
+		}()
+		return nil
+	})
+	return nil
+}
+
 func (s *Server) initConsulRegistry(serviceControllers *aggregate.Controller, args *PilotArgs) error {
 	log.Infof("Consul url: %v", args.Service.Consul.ServerURL)
-	conctl, conerr := consul.NewController(
-		args.Service.Consul.ServerURL)
+	conctl, conerr := consul.NewControllerWithOptions(consul.Options{
+		ServerURL:   args.Service.Consul.ServerURL,
+		Namespace:   args.Service.Consul.Namespace,
+		PassingOnly: args.Service.Consul.PassingOnly,
+	})
 	if conerr != nil {
 		return fmt.Errorf("failed to create Consul controller: %v", conerr)
 	}
@@ -1208,6 +1485,94 @@ func (s *Server) initConsulRegistry(serviceControllers *aggregate.Controller, ar
 	return nil
 }
 
+// initMCPSinkRegistry creates the registry fed over MCP by an external inventory system.
+func (s *Server) initMCPSinkRegistry(serviceControllers *aggregate.Controller, args *PilotArgs) error {
+	configSource := &meshconfig.ConfigSource{Address: args.Service.MCPSink.Address}
+	if configSource.Address == "" {
+		return fmt.Errorf("the address of the MCP server of the %s registry is not set", serviceregistry.MCPSinkRegistry)
+	}
+	if args.Service.MCPSink.IstioMutual {
+		configSource.TlsSettings = &istio_networking_v1alpha3.TLSSettings{
+			Mode: istio_networking_v1alpha3.TLSSettings_ISTIO_MUTUAL,
+		}
+	}
+	address := configSource.Address
+	newSourceClient := mcpapi.NewResourceSourceClient
+	if strings.HasPrefix(address, xdsScheme+"://") {
+		address = strings.TrimPrefix(address, xdsScheme+"://")
+		newSourceClient = mcpxds.NewResourceSourceClient
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	securityOption, err := mcpSecurityOptions(ctx, cancel, configSource)
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.DialContext(ctx, address, securityOption)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("unable to dial the MCP server %q of the %s registry: %v",
+			configSource.Address, serviceregistry.MCPSinkRegistry, err)
+	}
+
+	s.mcpSinkRegistry = srmcp.NewRegistry(args.Config.ControllerOptions.DomainSuffix)
+	collections := make([]sink.CollectionOptions, 0, len(srmcp.Collections))
+	for _, c := range srmcp.Collections {
+		collections = append(collections, sink.CollectionOptions{Name: c, Incremental: true})
+	}
+	reporter := monitoring.NewStatsContext("pilot/mcpsink")
+	client := sink.NewClient(newSourceClient(conn), &sink.Options{
+		CollectionOptions: collections,
+		Updater:           s.mcpSinkRegistry,
+		ID:                string(serviceregistry.MCPSinkRegistry),
+		Reporter:          reporter,
+	})
+	configz.Register(client)
+
+	s.addStartFunc(func(stop <-chan struct{}) error {
+		go client.Run(ctx)
+		go func() {
+			<-stop
+			cancel()
+			_ = conn.Close()
+			_ = reporter.Close()
+		}()
+		return nil
+	})
+
+	serviceControllers.AddRegistry(
+		aggregate.Registry{
+			Name:             serviceregistry.MCPSinkRegistry,
+			ServiceDiscovery: s.mcpSinkRegistry,
+			Controller:       s.mcpSinkRegistry,
+		})
+	return nil
+}
+
+// initProviderRegistry creates a registry implemented by a provider registered out of tree.
+func (s *Server) initProviderRegistry(serviceControllers *aggregate.Controller, name serviceregistry.ServiceRegistry,
+	args *PilotArgs) error {
+	factory, f := provider.Lookup(string(name))
+	if !f {
+		return fmt.Errorf("service registry %s is not supported", name)
+	}
+	p, err := factory(provider.Options{
+		DomainSuffix: args.Config.ControllerOptions.DomainSuffix,
+		Args:         args.Service.ProviderArgs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s registry: %v", name, err)
+	}
+	serviceControllers.AddRegistry(
+		aggregate.Registry{
+			Name:             name,
+			ServiceDiscovery: provider.NewServiceDiscovery(p),
+			Controller:       p,
+		})
+
+	return nil
+}
+
 func (s *Server) initGrpcServer(options *istiokeepalive.Options) {
 	grpcOptions := s.grpcServerOptions(options)
 	s.grpcServer = grpc.NewServer(grpcOptions...)
@@ -1225,15 +1590,8 @@ func (s *Server) initSecureGrpcServer(options *istiokeepalive.Options) error {
 	key := path.Join(certDir, constants.KeyFilename)
 	cert := path.Join(certDir, constants.CertChainFilename)
 
-	tlsCreds, err := credentials.NewServerTLSFromFile(cert, key)
 	// certs not ready yet.
-	if err != nil {
-		return err
-	}
-
-	// TODO: parse the file to determine expiration date. Restart listener before expiration
-	certificate, err := tls.LoadX509KeyPair(cert, key)
-	if err != nil {
+	if err := s.initServingCertificate(cert, key); err != nil {
 		return err
 	}
 
@@ -1245,12 +1603,14 @@ func (s *Server) initSecureGrpcServer(options *istiokeepalive.Options) error {
 	caCertPool.AppendCertsFromPEM(caCert)
 
 	opts := s.grpcServerOptions(options)
-	opts = append(opts, grpc.Creds(tlsCreds))
+	opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+		GetCertificate: s.getServingCertificate,
+	})))
 	s.secureGRPCServer = grpc.NewServer(opts...)
 	s.EnvoyXdsServer.Register(s.secureGRPCServer)
 	s.secureHTTPServer = &http.Server{
 		TLSConfig: &tls.Config{
-			Certificates: []tls.Certificate{certificate},
+			GetCertificate: s.getServingCertificate,
 			VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 				// For now accept any certs - pilot is not authenticating the caller, TLS used for
 				// privacy
@@ -1273,6 +1633,43 @@ func (s *Server) initSecureGrpcServer(options *istiokeepalive.Options) error {
 	return nil
 }
 
+// initServingCertificate loads the key and certificate of the secure servers, and reloads them
+// whenever the files change so that rotated certificates are served without restarting the listeners.
+func (s *Server) initServingCertificate(cert, key string) error {
+	if err := s.loadServingCertificate(cert, key); err != nil {
+		return err
+	}
+	reload := func() {
+		// The key and the certificate are not written atomically: a mismatched pair is
+		// ignored, and the event for the other file will load the complete pair.
+		if err := s.loadServingCertificate(cert, key); err != nil {
+			log.Warnf("failed to reload the serving certificate %s: %v", cert, err)
+			return
+		}
+		log.Infof("reloaded the serving certificate %s", cert)
+	}
+	s.addFileWatcher(cert, reload)
+	s.addFileWatcher(key, reload)
+	return nil
+}
+
+func (s *Server) loadServingCertificate(cert, key string) error {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return err
+	}
+	s.servingCertMutex.Lock()
+	s.servingCert = &certificate
+	s.servingCertMutex.Unlock()
+	return nil
+}
+
+func (s *Server) getServingCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.servingCertMutex.RLock()
+	defer s.servingCertMutex.RUnlock()
+	return s.servingCert, nil
+}
+
 func (s *Server) grpcServerOptions(options *istiokeepalive.Options) []grpc.ServerOption {
 	interceptors := []grpc.UnaryServerInterceptor{
 		// setup server prometheus monitoring (as final interceptor in chain)
@@ -1382,29 +1779,22 @@ func (s *Server) initCertController(args *PilotArgs) error {
 					return fmt.Errorf("err to create directory %v: %v", dir, err)
 				}
 			}
-			// Generate Pilot certificate
-			certChain, keyPEM, caCert, err := chiron.GenKeyCertK8sCA(k8sClient.CertificatesV1beta1().CertificateSigningRequests(),
-				name, svcName+".csr.secret", args.Namespace, DefaultCACertPath)
+			// Generate Pilot certificate, and renew it before it expires.
+			certChain, err := genPilotKeyCert(k8sClient, dir, name, svcName, args.Namespace)
 			if err != nil {
-				log.Errorf("err to generate key cert for %v: %v", svcName, err)
-				return nil
-			}
-			// Save cert-chain.pem, root.pem, and key.pem to the directory.
-			file := path.Join(dir, "cert-chain.pem")
-			if err = ioutil.WriteFile(file, certChain, 0644); err != nil {
-				log.Errorf("err to write %v cert-chain.pem (%v): %v", svcName, file, err)
+				log.Errora(err)
 				return nil
 			}
-			file = path.Join(dir, "root.pem")
-			if err = ioutil.WriteFile(file, caCert, 0644); err != nil {
-				log.Errorf("err to write %v root.pem (%v): %v", svcName, file, err)
-				return nil
+			rotator, err := chiron.NewKeyCertRotator(DefaultCertGracePeriodRatio, DefaultMinCertGracePeriod, func() ([]byte, error) {
+				return genPilotKeyCert(k8sClient, dir, name, svcName, args.Namespace)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create the rotator of the %v certificate: %v", svcName, err)
 			}
-			file = path.Join(dir, "key.pem")
-			if err = ioutil.WriteFile(file, keyPEM, 0600); err != nil {
-				log.Errorf("err to write %v key.pem (%v): %v", svcName, file, err)
+			s.addStartFunc(func(stop <-chan struct{}) error {
+				go rotator.Run(certChain, stop)
 				return nil
-			}
+			})
 			pilotCertGenerated = true
 		}
 	}
@@ -1429,6 +1819,29 @@ func (s *Server) initCertController(args *PilotArgs) error {
 	return nil
 }
 
+// genPilotKeyCert generates a key and certificate for Pilot signed by the Kubernetes CA, saves
+// cert-chain.pem, root.pem, and key.pem to the directory, and returns the certificate chain.
+func genPilotKeyCert(k8sClient kubernetes.Interface, dir, dnsName, svcName, namespace string) ([]byte, error) {
+	certChain, keyPEM, caCert, err := chiron.GenKeyCertK8sCA(k8sClient.CertificatesV1beta1().CertificateSigningRequests(),
+		dnsName, svcName+".csr.secret", namespace, DefaultCACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("err to generate key cert for %v: %v", svcName, err)
+	}
+	file := path.Join(dir, "cert-chain.pem")
+	if err = ioutil.WriteFile(file, certChain, 0644); err != nil {
+		return nil, fmt.Errorf("err to write %v cert-chain.pem (%v): %v", svcName, file, err)
+	}
+	file = path.Join(dir, "root.pem")
+	if err = ioutil.WriteFile(file, caCert, 0644); err != nil {
+		return nil, fmt.Errorf("err to write %v root.pem (%v): %v", svcName, file, err)
+	}
+	file = path.Join(dir, "key.pem")
+	if err = ioutil.WriteFile(file, keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("err to write %v key.pem (%v): %v", svcName, file, err)
+	}
+	return certChain, nil
+}
+
 // initEventHandlers sets up event handlers for config and service updates
 func (s *Server) initEventHandlers() error {
 	// Flush cached discovery responses whenever services configuration change.
@@ -1465,8 +1878,9 @@ func (s *Server) initEventHandlers() error {
 		// (especially mixerclient HTTP and quota)
 		configHandler := func(c model.Config, _ model.Event) {
 			pushReq := &model.PushRequest{
-				Full:               true,
-				ConfigTypesUpdated: map[string]struct{}{c.Type: {}},
+				Full:                    true,
+				ConfigTypesUpdated:      map[string]struct{}{c.Type: {}},
+				ConfigNamespacesUpdated: map[string]struct{}{c.Namespace: {}},
 			}
 			s.EnvoyXdsServer.ConfigUpdate(pushReq)
 		}
@@ -1475,5 +1889,16 @@ func (s *Server) initEventHandlers() error {
 		}
 	}
 
+	// Record the config changes in the history, with the source they come from.
+	for _, source := range s.configOrigins {
+		origin := source.origin
+		historyHandler := func(c model.Config, event model.Event) {
+			s.EnvoyXdsServer.ConfigHistory.Record(origin, c, event)
+		}
+		for _, descriptor := range source.controller.ConfigDescriptor() {
+			source.controller.RegisterEventHandler(descriptor.Type, historyHandler)
+		}
+	}
+
 	return nil
 }
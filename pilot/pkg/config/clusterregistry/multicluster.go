@@ -18,9 +18,11 @@ import (
 	"sync"
 	"time"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
@@ -37,21 +39,27 @@ type kubeController struct {
 
 // Multicluster structure holds the remote kube Controllers and multicluster specific attributes.
 type Multicluster struct {
-	WatchedNamespace  string
-	DomainSuffix      string
-	ResyncPeriod      time.Duration
-	serviceController *aggregate.Controller
-	XDSUpdater        model.XDSUpdater
+	WatchedNamespace   string
+	DomainSuffix       string
+	ResyncPeriod       time.Duration
+	DiscoverySelectors []*metav1.LabelSelector
+	serviceController  *aggregate.Controller
+	XDSUpdater         model.XDSUpdater
 
 	m                     sync.Mutex // protects remoteKubeControllers
 	remoteKubeControllers map[string]*kubeController
 	meshNetworks          *meshconfig.MeshNetworks
+
+	// localClient is used to mirror remote-only services in the local cluster. It is nil
+	// unless PILOT_MIRROR_REMOTE_SERVICES is enabled.
+	localClient kubernetes.Interface
 }
 
 // NewMulticluster initializes data structure to store multicluster information
 // It also starts the secret controller
 func NewMulticluster(kc kubernetes.Interface, secretNamespace string,
 	watchedNamespace string, domainSuffix string, resyncPeriod time.Duration,
+	discoverySelectors []*metav1.LabelSelector,
 	serviceController *aggregate.Controller, xds model.XDSUpdater, meshNetworks *meshconfig.MeshNetworks) (*Multicluster, error) {
 
 	remoteKubeController := make(map[string]*kubeController)
@@ -64,11 +72,15 @@ func NewMulticluster(kc kubernetes.Interface, secretNamespace string,
 		WatchedNamespace:      watchedNamespace,
 		DomainSuffix:          domainSuffix,
 		ResyncPeriod:          resyncPeriod,
+		DiscoverySelectors:    discoverySelectors,
 		serviceController:     serviceController,
 		XDSUpdater:            xds,
 		remoteKubeControllers: remoteKubeController,
 		meshNetworks:          meshNetworks,
 	}
+	if features.MirrorRemoteServices {
+		mc.localClient = kc
+	}
 
 	err := secretcontroller.StartSecretController(kc,
 		mc.AddMemberCluster,
@@ -87,11 +99,12 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 	remoteKubeController.stopCh = stopCh
 	m.m.Lock()
 	kubectl := controller.NewController(clientset, controller.Options{
-		WatchedNamespace: m.WatchedNamespace,
-		ResyncPeriod:     m.ResyncPeriod,
-		DomainSuffix:     m.DomainSuffix,
-		XDSUpdater:       m.XDSUpdater,
-		ClusterID:        clusterID,
+		WatchedNamespace:   m.WatchedNamespace,
+		ResyncPeriod:       m.ResyncPeriod,
+		DomainSuffix:       m.DomainSuffix,
+		XDSUpdater:         m.XDSUpdater,
+		ClusterID:          clusterID,
+		DiscoverySelectors: m.DiscoverySelectors,
 	})
 	kubectl.InitNetworkLookup(m.meshNetworks)
 
@@ -109,6 +122,11 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 
 	_ = kubectl.AppendServiceHandler(func(*model.Service, model.Event) { m.updateHandler() })
 	_ = kubectl.AppendInstanceHandler(func(*model.ServiceInstance, model.Event) { m.updateHandler() })
+	if m.localClient != nil {
+		_ = kubectl.AppendServiceHandler(func(svc *model.Service, event model.Event) {
+			m.mirrorRemoteService(clusterID, svc, event)
+		})
+	}
 	go kubectl.Run(stopCh)
 	return nil
 }
@@ -117,6 +135,8 @@ func (m *Multicluster) AddMemberCluster(clientset kubernetes.Interface, clusterI
 // when a remote cluster is deleted.  Also must clear the cache so remote resources
 // are removed.
 func (m *Multicluster) DeleteMemberCluster(clusterID string) error {
+	// runs after the lock is released
+	defer m.pruneRemoteServices()
 
 	m.m.Lock()
 	defer m.m.Unlock()
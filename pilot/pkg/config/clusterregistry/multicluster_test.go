@@ -25,7 +25,10 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pilot/pkg/serviceregistry/kube"
+	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/kube/secretcontroller"
 	pkgtest "istio.io/istio/pkg/test"
 )
@@ -97,7 +100,7 @@ func Test_KubeSecretController(t *testing.T) {
 
 	clientset := fake.NewSimpleClientset()
 
-	mc, err := NewMulticluster(clientset, testSecretNameSpace, WatchedNamespace, DomainSuffix, ResyncPeriod, mockserviceController, nil, nil)
+	mc, err := NewMulticluster(clientset, testSecretNameSpace, WatchedNamespace, DomainSuffix, ResyncPeriod, nil, mockserviceController, nil, nil)
 
 	if err != nil {
 		t.Fatalf("error creating Multicluster object and startign secret controller: %v", err)
@@ -124,3 +127,58 @@ func Test_KubeSecretController(t *testing.T) {
 	verifyControllers(t, mc, 0, "delete remote controller")
 
 }
+
+func Test_MirrorRemoteService(t *testing.T) {
+	local := fake.NewSimpleClientset()
+	mc := &Multicluster{
+		DomainSuffix:          DomainSuffix,
+		remoteKubeControllers: map[string]*kubeController{},
+		localClient:           local,
+	}
+	if _, err := local.CoreV1().Services("default").Create(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "default"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	remoteService := func(name string, ports ...int) *model.Service {
+		svc := &model.Service{
+			Hostname: kube.ServiceHostname(name, "default", DomainSuffix),
+			Attributes: model.ServiceAttributes{
+				Name:      name,
+				Namespace: "default",
+			},
+		}
+		for _, p := range ports {
+			svc.Ports = append(svc.Ports, &model.Port{Name: "http", Port: p, Protocol: protocol.HTTP})
+		}
+		return svc
+	}
+
+	mc.mirrorRemoteService("remote", remoteService("remote-only", 80), model.EventAdd)
+	mirror, err := local.CoreV1().Services("default").Get("remote-only", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected the remote service to be mirrored: %v", err)
+	}
+	if mirror.Labels[RemoteServiceLabel] != "remote" || len(mirror.Spec.Selector) != 0 || mirror.Spec.Ports[0].Port != 80 {
+		t.Errorf("unexpected mirrored service %v", mirror)
+	}
+
+	mc.mirrorRemoteService("remote", remoteService("remote-only", 8080), model.EventUpdate)
+	mirror, _ = local.CoreV1().Services("default").Get("remote-only", metav1.GetOptions{})
+	if mirror.Spec.Ports[0].Port != 8080 {
+		t.Errorf("expected the mirrored service ports to be updated, got %v", mirror.Spec.Ports)
+	}
+
+	mc.mirrorRemoteService("remote", remoteService("local", 8080), model.EventUpdate)
+	mc.mirrorRemoteService("remote", remoteService("local", 8080), model.EventDelete)
+	existing, err := local.CoreV1().Services("default").Get("local", metav1.GetOptions{})
+	if err != nil || len(existing.Spec.Ports) != 0 || existing.Labels[RemoteServiceLabel] != "" {
+		t.Errorf("expected the local service to be left untouched, got %v %v", existing, err)
+	}
+
+	mc.mirrorRemoteService("remote", remoteService("remote-only", 8080), model.EventDelete)
+	if _, err := local.CoreV1().Services("default").Get("remote-only", metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the mirrored service to be deleted")
+	}
+}
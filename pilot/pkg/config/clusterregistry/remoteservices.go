@@ -0,0 +1,157 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterregistry
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/kube"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/pkg/log"
+)
+
+const (
+	// RemoteServiceLabel marks the selector-less services that pilot creates in the local cluster
+	// for services that only exist in remote clusters. The value is the remote cluster the service
+	// was first seen in.
+	RemoteServiceLabel = "networking.istio.io/remote-service"
+)
+
+// mirrorRemoteService keeps a selector-less copy of a remote service in the local cluster, so that
+// its cluster.local name resolves for the local workloads. The copy has no endpoints of its own:
+// the aggregate registry merges it with the remote service, whose endpoints are reached through the
+// sidecar. Services that already exist in the local cluster are left untouched.
+func (m *Multicluster) mirrorRemoteService(clusterID string, svc *model.Service, event model.Event) {
+	if m.localClient == nil || svc.Attributes.Name == "" || svc.Attributes.Namespace == "" {
+		return
+	}
+	services := m.localClient.CoreV1().Services(svc.Attributes.Namespace)
+	existing, err := services.Get(svc.Attributes.Name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		log.Warnf("failed to get local service %s/%s: %v", svc.Attributes.Namespace, svc.Attributes.Name, err)
+		return
+	}
+	if existing != nil && err == nil && existing.Labels[RemoteServiceLabel] == "" {
+		// a real local service, nothing to mirror
+		return
+	}
+
+	if event == model.EventDelete {
+		if err != nil || m.remoteServiceExists(clusterID, svc) {
+			return
+		}
+		if err := services.Delete(svc.Attributes.Name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			log.Warnf("failed to delete mirrored service %s/%s: %v", svc.Attributes.Namespace, svc.Attributes.Name, err)
+		}
+		return
+	}
+
+	ports := convertServicePorts(svc.Ports)
+	if err == nil {
+		if reflect.DeepEqual(existing.Spec.Ports, ports) {
+			return
+		}
+		updated := existing.DeepCopy()
+		updated.Spec.Ports = ports
+		if _, err := services.Update(updated); err != nil {
+			log.Warnf("failed to update mirrored service %s/%s: %v", svc.Attributes.Namespace, svc.Attributes.Name, err)
+		}
+		return
+	}
+
+	mirror := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      svc.Attributes.Name,
+			Namespace: svc.Attributes.Namespace,
+			Labels: map[string]string{
+				RemoteServiceLabel: clusterID,
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Ports: ports,
+		},
+	}
+	if _, err := services.Create(mirror); err != nil {
+		// the namespace may not exist in the local cluster, in which case there is nobody to resolve the name
+		log.Debugf("failed to mirror remote service %s/%s from cluster %s: %v",
+			svc.Attributes.Namespace, svc.Attributes.Name, clusterID, err)
+		return
+	}
+	log.Infof("mirrored remote service %s/%s from cluster %s", svc.Attributes.Namespace, svc.Attributes.Name, clusterID)
+}
+
+// remoteServiceExists returns true if a remote cluster other than clusterID still has the service.
+func (m *Multicluster) remoteServiceExists(clusterID string, svc *model.Service) bool {
+	m.m.Lock()
+	defer m.m.Unlock()
+	for id, kc := range m.remoteKubeControllers {
+		if id == clusterID || kc.rc == nil {
+			continue
+		}
+		if s, _ := kc.rc.GetService(svc.Hostname); s != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneRemoteServices deletes the mirrored services that no remote cluster has anymore.
+func (m *Multicluster) pruneRemoteServices() {
+	if m.localClient == nil {
+		return
+	}
+	mirrored, err := m.localClient.CoreV1().Services(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: RemoteServiceLabel,
+	})
+	if err != nil {
+		log.Warnf("failed to list mirrored services: %v", err)
+		return
+	}
+	for _, s := range mirrored.Items {
+		svc := &model.Service{
+			Hostname: kube.ServiceHostname(s.Name, s.Namespace, m.DomainSuffix),
+		}
+		if m.remoteServiceExists("", svc) {
+			continue
+		}
+		if err := m.localClient.CoreV1().Services(s.Namespace).Delete(s.Name, &metav1.DeleteOptions{}); err != nil &&
+			!errors.IsNotFound(err) {
+			log.Warnf("failed to delete mirrored service %s/%s: %v", s.Namespace, s.Name, err)
+		}
+	}
+}
+
+func convertServicePorts(ports model.PortList) []v1.ServicePort {
+	out := make([]v1.ServicePort, 0, len(ports))
+	for _, p := range ports {
+		proto := v1.ProtocolTCP
+		if p.Protocol == protocol.UDP {
+			proto = v1.ProtocolUDP
+		}
+		out = append(out, v1.ServicePort{
+			Name:       p.Name,
+			Port:       int32(p.Port),
+			Protocol:   proto,
+			TargetPort: intstr.FromInt(p.Port),
+		})
+	}
+	return out
+}
@@ -180,7 +180,13 @@ func (c *controller) createInformer(
 	wf cache.WatchFunc,
 	vf ValidateFunc) cacheHandler {
 	handler := &kube.ChainHandler{}
-	handler.Append(c.notify)
+	handler.Append(func(obj interface{}, event model.Event) error {
+		if err := c.notify(obj, event); err != nil {
+			return err
+		}
+		c.trackVersion(otype, obj, event)
+		return nil
+	})
 
 	// TODO: finer-grained index (perf)
 	informer := cache.NewSharedIndexInformer(
@@ -205,7 +211,7 @@ func (c *controller) createInformer(
 					log.Errorf("failed to update CRD. New value: %v, error: %v", cur, err)
 					return
 				}
-				if !reflect.DeepEqual(old, cur) {
+				if configChanged(old, cur) {
 					incrementEvent(otype, "update")
 					c.queue.Push(kube.NewTask(handler.Apply, cur, model.EventUpdate))
 				} else {
@@ -221,6 +227,49 @@ func (c *controller) createInformer(
 	return cacheHandler{informer: informer, handler: handler}
 }
 
+// configChanged tells whether an update changed the config carried by an object, rather than only its
+// status or resource version: the status written back by Pilot must not trigger a push.
+func configChanged(old, cur interface{}) bool {
+	oldItem, ok := old.(crd.IstioObject)
+	if !ok {
+		return !reflect.DeepEqual(old, cur)
+	}
+	curItem, ok := cur.(crd.IstioObject)
+	if !ok {
+		return true
+	}
+	oldMeta, curMeta := oldItem.GetObjectMeta(), curItem.GetObjectMeta()
+	return !reflect.DeepEqual(oldItem.GetSpec(), curItem.GetSpec()) ||
+		!reflect.DeepEqual(oldMeta.Labels, curMeta.Labels) ||
+		!reflect.DeepEqual(oldMeta.Annotations, curMeta.Annotations)
+}
+
+// trackVersion records the resource version of the object in the config ledger, so that the versions of the
+// resources acked by the proxies can be looked up for the config distribution tracking.
+func (c *controller) trackVersion(typ string, obj interface{}, event model.Event) {
+	if c.client.configLedger == nil {
+		return
+	}
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	item, ok := obj.(crd.IstioObject)
+	if !ok {
+		return
+	}
+	meta := item.GetObjectMeta()
+	key := model.Key(typ, meta.Name, meta.Namespace)
+	var err error
+	if event == model.EventDelete {
+		err = c.client.configLedger.Delete(key)
+	} else {
+		_, err = c.client.configLedger.Put(key, meta.ResourceVersion)
+	}
+	if err != nil {
+		log.Warnf("error tracking pilot config versions for distribution: %v", err)
+	}
+}
+
 func incrementEvent(kind, event string) {
 	k8sEvents.With(typeTag.Value(kind), eventTag.Value(event)).Increment()
 }
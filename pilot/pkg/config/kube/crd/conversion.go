@@ -29,6 +29,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/defaults"
 	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/util/gogoprotomarshal"
@@ -41,6 +42,7 @@ func ConvertObject(schema schema.Instance, object IstioObject, domain string) (*
 	if err != nil {
 		return nil, err
 	}
+	defaults.Apply(data)
 	meta := object.GetObjectMeta()
 
 	return &model.Config{
@@ -67,6 +69,7 @@ func ConvertObjectFromUnstructured(schema schema.Instance, un *unstructured.Unst
 	if err != nil {
 		return nil, err
 	}
+	defaults.Apply(data)
 
 	return &model.Config{
 		ConfigMeta: model.ConfigMeta{
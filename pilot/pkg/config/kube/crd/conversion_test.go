@@ -18,8 +18,11 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
+
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/test/mock"
+	"istio.io/istio/pkg/config/defaults"
 	"istio.io/istio/pkg/config/schemas"
 )
 
@@ -74,7 +77,11 @@ func TestConvert(t *testing.T) {
 	if err != nil {
 		t.Errorf("ConvertObject() => unexpected error %v", err)
 	}
-	if !reflect.DeepEqual(&config, got) {
+	// The documented defaults are filled in when converting the object.
+	want := config
+	want.Spec = proto.Clone(config.Spec)
+	defaults.Apply(want.Spec)
+	if !reflect.DeepEqual(&want, got) {
 		t.Errorf("ConvertObject(ConvertConfig(%#v)) => got %#v", config, got)
 	}
 }
@@ -86,6 +86,26 @@ var KnownTypes = map[string]SchemaType{
 		},
 		Collection: &SyntheticServiceEntryList{},
 	},
+	schemas.WorkloadEntry.Type: {
+		Schema: schemas.WorkloadEntry,
+		Object: &WorkloadEntry{
+			TypeMeta: meta_v1.TypeMeta{
+				Kind:       "WorkloadEntry",
+				APIVersion: APIVersion(&schemas.WorkloadEntry),
+			},
+		},
+		Collection: &WorkloadEntryList{},
+	},
+	schemas.WorkloadGroup.Type: {
+		Schema: schemas.WorkloadGroup,
+		Object: &WorkloadGroup{
+			TypeMeta: meta_v1.TypeMeta{
+				Kind:       "WorkloadGroup",
+				APIVersion: APIVersion(&schemas.WorkloadGroup),
+			},
+		},
+		Collection: &WorkloadGroupList{},
+	},
 	schemas.DestinationRule.Type: {
 		Schema: schemas.DestinationRule,
 		Object: &DestinationRule{
@@ -226,6 +246,16 @@ var KnownTypes = map[string]SchemaType{
 		},
 		Collection: &AuthorizationPolicyList{},
 	},
+	schemas.Telemetry.Type: {
+		Schema: schemas.Telemetry,
+		Object: &Telemetry{
+			TypeMeta: meta_v1.TypeMeta{
+				Kind:       "Telemetry",
+				APIVersion: APIVersion(&schemas.Telemetry),
+			},
+		},
+		Collection: &TelemetryList{},
+	},
 }
 
 // MockConfig is the generic Kubernetes API Object wrapper
@@ -743,6 +773,212 @@ func (in *SyntheticServiceEntryList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// WorkloadEntry is the generic Kubernetes API Object wrapper
+type WorkloadEntry struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               map[string]interface{} `json:"spec"`
+}
+
+// GetSpec from a wrapper
+func (in *WorkloadEntry) GetSpec() map[string]interface{} {
+	return in.Spec
+}
+
+// SetSpec for a wrapper
+func (in *WorkloadEntry) SetSpec(spec map[string]interface{}) {
+	in.Spec = spec
+}
+
+// GetObjectMeta from a wrapper
+func (in *WorkloadEntry) GetObjectMeta() meta_v1.ObjectMeta {
+	return in.ObjectMeta
+}
+
+// SetObjectMeta for a wrapper
+func (in *WorkloadEntry) SetObjectMeta(metadata meta_v1.ObjectMeta) {
+	in.ObjectMeta = metadata
+}
+
+// WorkloadEntryList is the generic Kubernetes API list wrapper
+type WorkloadEntryList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []WorkloadEntry `json:"items"`
+}
+
+// GetItems from a wrapper
+func (in *WorkloadEntryList) GetItems() []IstioObject {
+	out := make([]IstioObject, len(in.Items))
+	for i := range in.Items {
+		out[i] = &in.Items[i]
+	}
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadEntry) DeepCopyInto(out *WorkloadEntry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadEntry.
+func (in *WorkloadEntry) DeepCopy() *WorkloadEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkloadEntry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadEntryList) DeepCopyInto(out *WorkloadEntryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkloadEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadEntryList.
+func (in *WorkloadEntryList) DeepCopy() *WorkloadEntryList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadEntryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkloadEntryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// WorkloadGroup is the generic Kubernetes API Object wrapper
+type WorkloadGroup struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               map[string]interface{} `json:"spec"`
+}
+
+// GetSpec from a wrapper
+func (in *WorkloadGroup) GetSpec() map[string]interface{} {
+	return in.Spec
+}
+
+// SetSpec for a wrapper
+func (in *WorkloadGroup) SetSpec(spec map[string]interface{}) {
+	in.Spec = spec
+}
+
+// GetObjectMeta from a wrapper
+func (in *WorkloadGroup) GetObjectMeta() meta_v1.ObjectMeta {
+	return in.ObjectMeta
+}
+
+// SetObjectMeta for a wrapper
+func (in *WorkloadGroup) SetObjectMeta(metadata meta_v1.ObjectMeta) {
+	in.ObjectMeta = metadata
+}
+
+// WorkloadGroupList is the generic Kubernetes API list wrapper
+type WorkloadGroupList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []WorkloadGroup `json:"items"`
+}
+
+// GetItems from a wrapper
+func (in *WorkloadGroupList) GetItems() []IstioObject {
+	out := make([]IstioObject, len(in.Items))
+	for i := range in.Items {
+		out[i] = &in.Items[i]
+	}
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadGroup) DeepCopyInto(out *WorkloadGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadGroup.
+func (in *WorkloadGroup) DeepCopy() *WorkloadGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkloadGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadGroupList) DeepCopyInto(out *WorkloadGroupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkloadGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadGroupList.
+func (in *WorkloadGroupList) DeepCopy() *WorkloadGroupList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadGroupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkloadGroupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
 // DestinationRule is the generic Kubernetes API Object wrapper
 type DestinationRule struct {
 	meta_v1.TypeMeta   `json:",inline"`
@@ -2184,3 +2420,106 @@ func (in *AuthorizationPolicyList) DeepCopyObject() runtime.Object {
 
 	return nil
 }
+
+// Telemetry is the generic Kubernetes API Object wrapper
+type Telemetry struct {
+	meta_v1.TypeMeta   `json:",inline"`
+	meta_v1.ObjectMeta `json:"metadata"`
+	Spec               map[string]interface{} `json:"spec"`
+}
+
+// GetSpec from a wrapper
+func (in *Telemetry) GetSpec() map[string]interface{} {
+	return in.Spec
+}
+
+// SetSpec for a wrapper
+func (in *Telemetry) SetSpec(spec map[string]interface{}) {
+	in.Spec = spec
+}
+
+// GetObjectMeta from a wrapper
+func (in *Telemetry) GetObjectMeta() meta_v1.ObjectMeta {
+	return in.ObjectMeta
+}
+
+// SetObjectMeta for a wrapper
+func (in *Telemetry) SetObjectMeta(metadata meta_v1.ObjectMeta) {
+	in.ObjectMeta = metadata
+}
+
+// TelemetryList is the generic Kubernetes API list wrapper
+type TelemetryList struct {
+	meta_v1.TypeMeta `json:",inline"`
+	meta_v1.ListMeta `json:"metadata"`
+	Items            []Telemetry `json:"items"`
+}
+
+// GetItems from a wrapper
+func (in *TelemetryList) GetItems() []IstioObject {
+	out := make([]IstioObject, len(in.Items))
+	for i := range in.Items {
+		out[i] = &in.Items[i]
+	}
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Telemetry) DeepCopyInto(out *Telemetry) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Telemetry.
+func (in *Telemetry) DeepCopy() *Telemetry {
+	if in == nil {
+		return nil
+	}
+	out := new(Telemetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Telemetry) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TelemetryList) DeepCopyInto(out *TelemetryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Telemetry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TelemetryList.
+func (in *TelemetryList) DeepCopy() *TelemetryList {
+	if in == nil {
+		return nil
+	}
+	out := new(TelemetryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *TelemetryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
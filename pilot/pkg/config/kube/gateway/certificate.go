@@ -0,0 +1,654 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"golang.org/x/crypto/acme"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/kube"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/pkg/log"
+)
+
+const (
+	// ACMEAnnotation is the annotation of the Gateways whose certificates are provisioned via ACME,
+	// set to "true".
+	ACMEAnnotation = "gateway.istio.io/acme"
+
+	// ACMELabel is the label of the secrets holding a certificate provisioned via ACME, set to the name
+	// of their Gateway.
+	ACMELabel = "gateway.istio.io/acme"
+
+	// ACMEElectionID is the name of the config map used to elect the Pilot replica provisioning the
+	// certificates.
+	ACMEElectionID = "istio-pilot-acme-leader"
+
+	// acmeAccountSecret is the name of the secret holding the key of the ACME account of Pilot.
+	acmeAccountSecret = "istio-acme-account"
+	acmeAccountKey    = "key"
+
+	// HTTP01Challenge validates the hosts with the HTTP servers of their Gateway.
+	HTTP01Challenge = "http-01"
+
+	// DNS01Challenge validates the hosts with TXT records, published as external-dns DNSEndpoint resources.
+	DNS01Challenge = "dns-01"
+
+	// dnsChallengePrefix prefixes the name of the TXT record answering the DNS-01 challenge of a host.
+	dnsChallengePrefix = "_acme-challenge."
+
+	// dnsChallengeTTL is the TTL of the TXT records answering the DNS-01 challenges, in seconds.
+	dnsChallengeTTL = 60
+)
+
+var (
+	// renewBefore is how long before their expiration the certificates are renewed.
+	renewBefore = 30 * 24 * time.Hour
+
+	// renewalCheckInterval is the interval between the checks of the expiration of the certificates.
+	renewalCheckInterval = 12 * time.Hour
+
+	// issueTimeout bounds the issuance of a certificate, including the challenges of its hosts.
+	issueTimeout = 5 * time.Minute
+
+	// challengePropagationDelay is the time given to the gateways to receive the route answering a
+	// challenge before it is accepted.
+	challengePropagationDelay = 10 * time.Second
+
+	// dnsChallengePropagationDelay is the time given to external-dns to publish the TXT records answering
+	// the challenges, and to the DNS servers to serve them, before they are accepted.
+	dnsChallengePropagationDelay = 2 * time.Minute
+
+	// acmeErrorDelay is the delay before retrying a failed issuance, to stay within the rate limits of
+	// the ACME servers.
+	acmeErrorDelay = time.Minute
+
+	acmeLeaseDuration = 30 * time.Second
+
+	// dnsEndpointResource is the resource of the external-dns DNSEndpoints.
+	dnsEndpointResource = schema.GroupVersionResource{
+		Group:    "externaldns.k8s.io",
+		Version:  "v1alpha1",
+		Resource: "dnsendpoints",
+	}
+)
+
+// CertificateController provisions and renews via ACME the certificates of the HTTPS servers of the
+// Gateways annotated with ACMEAnnotation, in the TLS secrets named by the credentialName of the servers
+// in the namespace of the Gateway, where the gateway serves them from through SDS. The hosts are
+// validated with HTTP-01 challenges, answered by the HTTP servers of the Gateway on port 80 while the
+// controller holds their key authorization in an annotation of the Gateway, or with DNS-01 challenges,
+// answered by TXT records the controller publishes as DNSEndpoint resources in the namespace of the
+// Gateway, for external-dns to write them to the DNS provider of the hosts.
+//
+// Only the replica of Pilot elected as leader provisions the certificates.
+type CertificateController struct {
+	client        kubernetes.Interface
+	dynamic       dynamic.Interface
+	store         model.ConfigStoreCache
+	directory     string
+	email         string
+	challengeType string
+	namespace     string
+	queue         kube.Queue
+
+	// leading is 1 while the replica is elected as leader
+	leading int32
+	// acme is the client of the ACME account, registered on first use by the queue
+	acme *acme.Client
+
+	mu sync.Mutex
+	// seen holds the Gateways last queued by their events, by key
+	seen map[string]model.Config
+}
+
+// NewCertificateController creates a controller provisioning the certificates of the Gateways watched
+// in the store from the ACME directory, validating their hosts with challenges of the type, HTTP01Challenge
+// or DNS01Challenge. The key of the account registered with the email is kept in the namespace, along with
+// the lock of the leader election.
+func NewCertificateController(client kubernetes.Interface, dynamicClient dynamic.Interface, store model.ConfigStoreCache,
+	directory, email, challengeType, namespace string) (*CertificateController, error) {
+	if challengeType != HTTP01Challenge && challengeType != DNS01Challenge {
+		return nil, fmt.Errorf("unsupported ACME challenge type %q, expected %s or %s", challengeType,
+			HTTP01Challenge, DNS01Challenge)
+	}
+	c := &CertificateController{
+		client:        client,
+		dynamic:       dynamicClient,
+		store:         store,
+		directory:     directory,
+		email:         email,
+		challengeType: challengeType,
+		namespace:     namespace,
+		queue:         kube.NewQueue(acmeErrorDelay),
+		seen:          make(map[string]model.Config),
+	}
+	store.RegisterEventHandler(schemas.Gateway.Type, func(cfg model.Config, event model.Event) {
+		if c.changed(cfg, event) {
+			c.queue.Push(kube.NewTask(c.handle, cfg, event))
+		}
+	})
+	return c, nil
+}
+
+// changed returns whether the event changes the Gateway apart from the challenge annotations written by the
+// controller, whose updates would otherwise start another issuance of the certificates being issued.
+func (c *CertificateController) changed(cfg model.Config, event model.Event) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := cfg.Key()
+	if event == model.EventDelete {
+		delete(c.seen, key)
+		return false
+	}
+	previous, ok := c.seen[key]
+	c.seen[key] = cfg
+	return !ok || !proto.Equal(previous.Spec, cfg.Spec) ||
+		!reflect.DeepEqual(withoutChallenges(previous.Annotations), withoutChallenges(cfg.Annotations))
+}
+
+// withoutChallenges returns the annotations without the challenge annotations.
+func withoutChallenges(annotations map[string]string) map[string]string {
+	out := make(map[string]string, len(annotations))
+	for k, v := range annotations {
+		if !strings.HasPrefix(k, model.ACMEChallengeAnnotationPrefix) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// Run runs for election among the replicas of Pilot as identity, and provisions the certificates while
+// leading, until the stop channel is closed.
+func (c *CertificateController) Run(stop <-chan struct{}, identity string) error {
+	lock := &resourcelock.ConfigMapLock{
+		ConfigMapMeta: metav1.ObjectMeta{Namespace: c.namespace, Name: ACMEElectionID},
+		Client:        c.client.CoreV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: acmeLeaseDuration,
+		RenewDeadline: acmeLeaseDuration / 2,
+		RetryPeriod:   acmeLeaseDuration / 4,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Infof("Started leading the ACME certificates as %s", identity)
+				c.provisionUntil(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				log.Infof("Stopped leading the ACME certificates as %s", identity)
+			},
+		},
+		ReleaseOnCancel: true,
+		Name:            ACMEElectionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	go c.queue.Run(stop)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	// Run returns when the leadership is lost: run for election again until stopped.
+	for ctx.Err() == nil {
+		le.Run(ctx)
+	}
+	return nil
+}
+
+// provisionUntil checks the certificates of all the Gateways now and then periodically, until the stop
+// channel is closed. The changes of the Gateways are handled as they come in the meantime.
+func (c *CertificateController) provisionUntil(stop <-chan struct{}) {
+	atomic.StoreInt32(&c.leading, 1)
+	defer atomic.StoreInt32(&c.leading, 0)
+
+	ticker := time.NewTicker(renewalCheckInterval)
+	defer ticker.Stop()
+	for {
+		configs, err := c.store.List(schemas.Gateway.Type, model.NamespaceAll)
+		if err != nil {
+			log.Warnf("Unable to list the gateways to check their certificates: %v", err)
+		}
+		for _, cfg := range configs {
+			c.queue.Push(kube.NewTask(c.handle, cfg, model.EventUpdate))
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *CertificateController) handle(obj interface{}, _ model.Event) error {
+	cfg := obj.(model.Config)
+	if atomic.LoadInt32(&c.leading) == 0 || cfg.Annotations[ACMEAnnotation] != "true" {
+		return nil
+	}
+	certificates := acmeCertificates(cfg)
+	names := make([]string, 0, len(certificates))
+	for name := range certificates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		hosts := certificates[name]
+		secret, err := c.client.CoreV1().Secrets(cfg.Namespace).Get(name, metav1.GetOptions{})
+		switch {
+		case errors.IsNotFound(err):
+			secret = nil
+		case err != nil:
+			return err
+		case secret.Labels[ACMELabel] != cfg.Name:
+			log.Debugf("Not provisioning the certificate of %v: secret %s/%s was not provisioned via ACME", hosts, cfg.Namespace, name)
+			continue
+		}
+		if !needsCertificate(secret, hosts, time.Now()) {
+			continue
+		}
+		if err := c.issue(cfg, name, hosts, secret); err != nil {
+			return fmt.Errorf("provisioning the certificate of %v in secret %s/%s: %v", hosts, cfg.Namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// acmeCertificates returns the hosts of the HTTPS servers of the Gateway terminating TLS with an SDS
+// credential, by credential name. The wildcard hosts are skipped: they cannot be validated with HTTP-01
+// challenges, and are not supported with DNS-01 challenges yet.
+func acmeCertificates(cfg model.Config) map[string][]string {
+	gw := cfg.Spec.(*networking.Gateway)
+	certificates := make(map[string][]string)
+	for _, s := range gw.Servers {
+		if s.Tls == nil || s.Tls.Mode != networking.Server_TLSOptions_SIMPLE || s.Tls.CredentialName == "" {
+			continue
+		}
+		for _, h := range s.Hosts {
+			// strip the namespace of the host
+			h = h[strings.Index(h, "/")+1:]
+			if strings.Contains(h, "*") {
+				log.Debugf("Not provisioning the certificate of wildcard host %s of gateway %s/%s", h, cfg.Namespace, cfg.Name)
+				continue
+			}
+			certificates[s.Tls.CredentialName] = appendHost(certificates[s.Tls.CredentialName], h)
+		}
+	}
+	return certificates
+}
+
+func appendHost(hosts []string, h string) []string {
+	for _, existing := range hosts {
+		if existing == h {
+			return hosts
+		}
+	}
+	hosts = append(hosts, h)
+	sort.Strings(hosts)
+	return hosts
+}
+
+// needsCertificate returns whether the certificate of the secret, if any, has to be issued again for
+// the hosts: it does not cover all of them or expires soon.
+func needsCertificate(secret *corev1.Secret, hosts []string, now time.Time) bool {
+	if secret == nil {
+		return true
+	}
+	block, _ := pem.Decode(secret.Data[corev1.TLSCertKey])
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	if now.Add(renewBefore).After(cert.NotAfter) {
+		return true
+	}
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// issue validates the hosts and writes the certificate issued for them in the secret, replacing the
+// existing one if any.
+func (c *CertificateController) issue(cfg model.Config, name string, hosts []string, existing *corev1.Secret) error {
+	ctx, cancel := context.WithTimeout(context.Background(), issueTimeout)
+	defer cancel()
+
+	client, err := c.acmeClient(ctx)
+	if err != nil {
+		return err
+	}
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(hosts...))
+	if err != nil {
+		return err
+	}
+	if err := c.authorize(ctx, client, cfg, order); err != nil {
+		return err
+	}
+	if order, err = client.WaitOrder(ctx, order.URI); err != nil {
+		return err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: hosts[0]},
+		DNSNames: hosts,
+	}, key)
+	if err != nil {
+		return err
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	var certPEM []byte
+	for _, der := range chain {
+		certPEM = append(certPEM, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cfg.Namespace,
+			Labels:    map[string]string{ACMELabel: cfg.Name},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       certPEM,
+			corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}
+	if existing == nil {
+		_, err = c.client.CoreV1().Secrets(cfg.Namespace).Create(secret)
+	} else {
+		secret.ResourceVersion = existing.ResourceVersion
+		_, err = c.client.CoreV1().Secrets(cfg.Namespace).Update(secret)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Provisioned the certificate of %v in secret %s/%s", hosts, cfg.Namespace, name)
+	return nil
+}
+
+// pendingChallenge is a challenge of a host whose response is published.
+type pendingChallenge struct {
+	host      string
+	authzURI  string
+	challenge *acme.Challenge
+}
+
+// authorize validates the hosts of the order. The responses to their challenges are all published first,
+// and accepted once they had time to propagate. They are removed when done.
+func (c *CertificateController) authorize(ctx context.Context, client *acme.Client, cfg model.Config, order *acme.Order) error {
+	var pending []pendingChallenge
+	defer func() {
+		for _, p := range pending {
+			if err := c.respond(client, cfg, p.host, nil); err != nil {
+				log.Warnf("Unable to remove the challenge of host %s of gateway %s/%s: %v", p.host, cfg.Namespace, cfg.Name, err)
+			}
+		}
+	}()
+
+	for _, u := range order.AuthzURLs {
+		authz, err := client.GetAuthorization(ctx, u)
+		if err != nil {
+			return err
+		}
+		h := authz.Identifier.Value
+		if authz.Status != acme.StatusPending {
+			continue
+		}
+		var challenge *acme.Challenge
+		for _, ch := range authz.Challenges {
+			if ch.Type == c.challengeType {
+				challenge = ch
+				break
+			}
+		}
+		if challenge == nil {
+			return fmt.Errorf("validating host %s: no %s challenge offered", h, c.challengeType)
+		}
+		if err := c.respond(client, cfg, h, challenge); err != nil {
+			return fmt.Errorf("validating host %s: %v", h, err)
+		}
+		pending = append(pending, pendingChallenge{host: h, authzURI: u, challenge: challenge})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	delay := challengePropagationDelay
+	if c.challengeType == DNS01Challenge {
+		delay = dnsChallengePropagationDelay
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+	}
+	for _, p := range pending {
+		if _, err := client.Accept(ctx, p.challenge); err != nil {
+			return fmt.Errorf("validating host %s: %v", p.host, err)
+		}
+		if _, err := client.WaitAuthorization(ctx, p.authzURI); err != nil {
+			return fmt.Errorf("validating host %s: %v", p.host, err)
+		}
+	}
+	return nil
+}
+
+// respond publishes the response to the challenge of the host, or removes it if the challenge is nil.
+func (c *CertificateController) respond(client *acme.Client, cfg model.Config, h string, challenge *acme.Challenge) error {
+	var response string
+	var err error
+	switch {
+	case challenge == nil:
+	case c.challengeType == DNS01Challenge:
+		response, err = client.DNS01ChallengeRecord(challenge.Token)
+	default:
+		response, err = client.HTTP01ChallengeResponse(challenge.Token)
+	}
+	if err != nil {
+		return err
+	}
+	if c.challengeType == DNS01Challenge {
+		return c.setDNSRecord(cfg, h, response)
+	}
+	return c.setChallenge(cfg, challengeAnnotation(h), response)
+}
+
+// hostHash returns a short hash of the host, valid in the names of the resources.
+func hostHash(h string) string {
+	sum := sha256.Sum256([]byte(h))
+	return hex.EncodeToString(sum[:8])
+}
+
+// challengeAnnotation returns the annotation of the Gateway holding the pending challenge of the host.
+func challengeAnnotation(h string) string {
+	return model.ACMEChallengeAnnotationPrefix + hostHash(h)
+}
+
+// dnsRecordName returns the name of the DNSEndpoint holding the TXT record of the pending challenge of the
+// host of the Gateway.
+func dnsRecordName(gateway, h string) string {
+	return fmt.Sprintf("%s-acme-%s", gateway, hostHash(h))
+}
+
+// setChallenge sets the annotation of the Gateway to the key authorization of a challenge, or removes it
+// if empty.
+func (c *CertificateController) setChallenge(cfg model.Config, annotation, keyAuth string) error {
+	current := c.store.Get(schemas.Gateway.Type, cfg.Name, cfg.Namespace)
+	if current == nil {
+		return fmt.Errorf("gateway %s/%s not found", cfg.Namespace, cfg.Name)
+	}
+	if current.Annotations[annotation] == keyAuth {
+		return nil
+	}
+	updated := *current
+	updated.Annotations = make(map[string]string, len(current.Annotations)+1)
+	for k, v := range current.Annotations {
+		updated.Annotations[k] = v
+	}
+	if keyAuth == "" {
+		delete(updated.Annotations, annotation)
+	} else {
+		updated.Annotations[annotation] = keyAuth
+	}
+	_, err := c.store.Update(updated)
+	return err
+}
+
+// setDNSRecord publishes the TXT record answering the DNS-01 challenge of the host of the Gateway in a
+// DNSEndpoint, or removes it if the record is empty.
+func (c *CertificateController) setDNSRecord(cfg model.Config, h, record string) error {
+	resource := c.dynamic.Resource(dnsEndpointResource).Namespace(cfg.Namespace)
+	name := dnsRecordName(cfg.Name, h)
+	if record == "" {
+		if err := resource.Delete(name, &metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	endpoint := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": dnsEndpointResource.GroupVersion().String(),
+		"kind":       "DNSEndpoint",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": cfg.Namespace,
+			"labels":    map[string]interface{}{ACMELabel: cfg.Name},
+		},
+		"spec": map[string]interface{}{
+			"endpoints": []interface{}{
+				map[string]interface{}{
+					"dnsName":    dnsChallengePrefix + h,
+					"recordType": "TXT",
+					"recordTTL":  int64(dnsChallengeTTL),
+					"targets":    []interface{}{record},
+				},
+			},
+		},
+	}}
+	// replace the record left by an interrupted issuance, if any
+	existing, err := resource.Get(name, metav1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = resource.Create(endpoint, metav1.CreateOptions{})
+	case err == nil:
+		endpoint.SetResourceVersion(existing.GetResourceVersion())
+		_, err = resource.Update(endpoint, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// acmeClient returns the client of the ACME account of Pilot, registering it on first use.
+func (c *CertificateController) acmeClient(ctx context.Context) (*acme.Client, error) {
+	if c.acme != nil {
+		return c.acme, nil
+	}
+
+	secret, err := c.client.CoreV1().Secrets(c.namespace).Get(acmeAccountSecret, metav1.GetOptions{})
+	if err == nil {
+		block, _ := pem.Decode(secret.Data[acmeAccountKey])
+		if block == nil {
+			return nil, fmt.Errorf("no key in secret %s/%s", c.namespace, acmeAccountSecret)
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		c.acme = &acme.Client{Key: key, DirectoryURL: c.directory}
+		return c.acme, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{Key: key, DirectoryURL: c.directory}
+	var contact []string
+	if c.email != "" {
+		contact = []string{"mailto:" + c.email}
+	}
+	if _, err := client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS); err != nil {
+		return nil, fmt.Errorf("registering the ACME account: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.client.CoreV1().Secrets(c.namespace).Create(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: acmeAccountSecret, Namespace: c.namespace},
+		Data: map[string][]byte{
+			acmeAccountKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	c.acme = client
+	return client, nil
+}
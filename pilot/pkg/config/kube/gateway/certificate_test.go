@@ -0,0 +1,520 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func acmeGatewayConfig() model.Config {
+	c := gatewayConfig("")
+	c.Annotations = map[string]string{ACMEAnnotation: "true"}
+	c.Spec = &networking.Gateway{
+		Selector: map[string]string{"istio": "edge"},
+		Servers: []*networking.Server{
+			{
+				Hosts: []string{"ingress/example.org", "*.example.org"},
+				Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+				Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "example"},
+			},
+			{
+				Hosts: []string{"www.example.org"},
+				Port:  &networking.Port{Name: "https-www", Number: 443, Protocol: "HTTPS"},
+				Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "example"},
+			},
+			{
+				Hosts: []string{"internal.example.org"},
+				Port:  &networking.Port{Name: "https-internal", Number: 8443, Protocol: "HTTPS"},
+				Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_PASSTHROUGH},
+			},
+			{
+				Hosts: []string{"example.org"},
+				Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+			},
+		},
+	}
+	return c
+}
+
+func TestACMECertificates(t *testing.T) {
+	got := acmeCertificates(acmeGatewayConfig())
+	want := map[string][]string{"example": {"example.org", "www.example.org"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got certificates %v, want %v", got, want)
+	}
+}
+
+func tlsSecret(t *testing.T, notAfter time.Time, hosts ...string) *corev1.Secret {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		Data: map[string][]byte{corev1.TLSCertKey: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})},
+	}
+}
+
+func TestNeedsCertificate(t *testing.T) {
+	now := time.Now()
+	hosts := []string{"example.org", "www.example.org"}
+	cases := []struct {
+		name   string
+		secret *corev1.Secret
+		want   bool
+	}{
+		{"no secret", nil, true},
+		{"no certificate", &corev1.Secret{}, true},
+		{"valid", tlsSecret(t, now.Add(60*24*time.Hour), hosts...), false},
+		{"expiring", tlsSecret(t, now.Add(10*24*time.Hour), hosts...), true},
+		{"missing host", tlsSecret(t, now.Add(60*24*time.Hour), "example.org"), true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsCertificate(tt.secret, hosts, now); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newCertificateController(t *testing.T, challengeType string) (*CertificateController, *fake.Clientset, model.ConfigStoreCache) {
+	t.Helper()
+	client := fake.NewSimpleClientset()
+	store := memory.NewController(memory.Make(schema.Set{schemas.Gateway}))
+	c, err := NewCertificateController(client, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()), store,
+		"https://acme.invalid/directory", "", challengeType, "istio-system")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, client, store
+}
+
+func TestNewCertificateControllerChallengeType(t *testing.T) {
+	store := memory.NewController(memory.Make(schema.Set{schemas.Gateway}))
+	if _, err := NewCertificateController(fake.NewSimpleClientset(), nil, store, "https://acme.invalid/directory", "",
+		"tls-alpn-01", "istio-system"); err == nil {
+		t.Errorf("expected an error for an unsupported challenge type")
+	}
+}
+
+func TestCertificateControllerChallenge(t *testing.T) {
+	c, _, store := newCertificateController(t, HTTP01Challenge)
+	gw := acmeGatewayConfig()
+	if _, err := store.Create(gw); err != nil {
+		t.Fatal(err)
+	}
+
+	annotation := challengeAnnotation("example.org")
+	if err := c.setChallenge(gw, annotation, "token.thumbprint"); err != nil {
+		t.Fatal(err)
+	}
+	current := store.Get(schemas.Gateway.Type, gw.Name, gw.Namespace)
+	if current.Annotations[annotation] != "token.thumbprint" || current.Annotations[ACMEAnnotation] != "true" {
+		t.Fatalf("expected the challenge to be added to the annotations, got %v", current.Annotations)
+	}
+	merged := model.MergeGateways(*current)
+	if want := map[string]string{"token": "token.thumbprint"}; !reflect.DeepEqual(merged.ACMEChallenges, want) {
+		t.Errorf("got challenges %v, want %v", merged.ACMEChallenges, want)
+	}
+
+	if err := c.setChallenge(gw, annotation, ""); err != nil {
+		t.Fatal(err)
+	}
+	current = store.Get(schemas.Gateway.Type, gw.Name, gw.Namespace)
+	if _, ok := current.Annotations[annotation]; ok {
+		t.Errorf("expected the challenge to be removed from the annotations, got %v", current.Annotations)
+	}
+}
+
+func TestCertificateControllerUnmanagedSecret(t *testing.T) {
+	c, client, _ := newCertificateController(t, HTTP01Challenge)
+	c.leading = 1
+	existing := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "ingress"}}
+	if _, err := client.CoreV1().Secrets("ingress").Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	// the certificate is not issued over the existing secret: the ACME directory would not be reachable
+	if err := c.handle(acmeGatewayConfig(), model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	secret, err := client.CoreV1().Secrets("ingress").Get("example", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret.Data) != 0 {
+		t.Errorf("expected the existing secret to be kept, got %v", secret.Data)
+	}
+}
+
+func TestCertificateControllerDNSRecord(t *testing.T) {
+	c, _, _ := newCertificateController(t, DNS01Challenge)
+	gw := acmeGatewayConfig()
+	resource := c.dynamic.Resource(dnsEndpointResource).Namespace(gw.Namespace)
+	name := dnsRecordName(gw.Name, "example.org")
+
+	for _, record := range []string{"stale", "digest"} {
+		if err := c.setDNSRecord(gw, "example.org", record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	endpoint, err := resource.Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := endpoint.GetLabels()[ACMELabel]; got != gw.Name {
+		t.Errorf("got label %q, want %q", got, gw.Name)
+	}
+	endpoints, _, _ := unstructured.NestedSlice(endpoint.Object, "spec", "endpoints")
+	want := []interface{}{
+		map[string]interface{}{
+			"dnsName":    "_acme-challenge.example.org",
+			"recordType": "TXT",
+			"recordTTL":  int64(dnsChallengeTTL),
+			"targets":    []interface{}{"digest"},
+		},
+	}
+	if !reflect.DeepEqual(endpoints, want) {
+		t.Errorf("got endpoints %v, want %v", endpoints, want)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := c.setDNSRecord(gw, "example.org", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := resource.Get(name, metav1.GetOptions{}); err == nil {
+		t.Errorf("expected the DNS record to be removed")
+	}
+}
+
+func TestCertificateControllerChanged(t *testing.T) {
+	c, _, _ := newCertificateController(t, HTTP01Challenge)
+	gw := acmeGatewayConfig()
+	if !c.changed(gw, model.EventAdd) {
+		t.Errorf("expected an added gateway to be changed")
+	}
+
+	challenged := gw
+	challenged.Annotations = map[string]string{ACMEAnnotation: "true", challengeAnnotation("example.org"): "token.thumbprint"}
+	if c.changed(challenged, model.EventUpdate) {
+		t.Errorf("expected the update of a challenge annotation to be ignored")
+	}
+
+	updated := challenged
+	updated.Spec = &networking.Gateway{Selector: map[string]string{"istio": "ingressgateway"}}
+	if !c.changed(updated, model.EventUpdate) {
+		t.Errorf("expected the update of the spec to be handled")
+	}
+
+	c.changed(updated, model.EventDelete)
+	if !c.changed(updated, model.EventAdd) {
+		t.Errorf("expected a gateway added again to be changed")
+	}
+}
+
+// fakeACMEServer is an RFC 8555 directory validating the challenges as soon as they are accepted, and
+// issuing the certificates with a self-signed CA.
+type fakeACMEServer struct {
+	*httptest.Server
+	t *testing.T
+
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	mu sync.Mutex
+	// hosts are the hosts of the authorizations, by id
+	hosts []string
+	// validated are the ids of the authorizations whose challenge was accepted
+	validated map[int]bool
+	// accepted are called with the host of the challenges when they are accepted
+	accepted func(host string)
+	cert     []byte
+}
+
+func newFakeACMEServer(t *testing.T, accepted func(host string)) *fakeACMEServer {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	s := &fakeACMEServer{t: t, caKey: caKey, caCert: caCert, validated: make(map[int]bool), accepted: accepted}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+// payload returns the payload of the JWS of the request, whose signature is not checked.
+func (s *fakeACMEServer) payload(r *http.Request, v interface{}) {
+	var jws struct {
+		Payload string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		s.t.Errorf("invalid JWS: %v", err)
+		return
+	}
+	b, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil || v == nil {
+		return
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		s.t.Errorf("invalid payload %s: %v", b, err)
+	}
+}
+
+func (s *fakeACMEServer) writeJSON(w http.ResponseWriter, status int, location string, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if location != "" {
+		w.Header().Set("Location", s.URL+location)
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (s *fakeACMEServer) order() map[string]interface{} {
+	status := "ready"
+	authorizations := make([]string, 0, len(s.hosts))
+	for id := range s.hosts {
+		authorizations = append(authorizations, fmt.Sprintf("%s/authz/%d", s.URL, id))
+		if !s.validated[id] {
+			status = "pending"
+		}
+	}
+	order := map[string]interface{}{"status": status, "authorizations": authorizations, "finalize": s.URL + "/finalize"}
+	if s.cert != nil {
+		order["status"] = "valid"
+		order["certificate"] = s.URL + "/cert"
+	}
+	return order
+}
+
+func (s *fakeACMEServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", time.Now().UnixNano()))
+
+	var id int
+	switch {
+	case r.URL.Path == "/directory":
+		s.writeJSON(w, http.StatusOK, "", map[string]string{
+			"newNonce":   s.URL + "/nonce",
+			"newAccount": s.URL + "/account",
+			"newOrder":   s.URL + "/new-order",
+			"revokeCert": s.URL + "/revoke",
+		})
+	case r.URL.Path == "/nonce":
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == "/account":
+		s.payload(r, nil)
+		s.writeJSON(w, http.StatusCreated, "/account/1", map[string]string{"status": "valid"})
+	case r.URL.Path == "/new-order":
+		var req struct {
+			Identifiers []struct{ Value string } `json:"identifiers"`
+		}
+		s.payload(r, &req)
+		for _, identifier := range req.Identifiers {
+			s.hosts = append(s.hosts, identifier.Value)
+		}
+		s.writeJSON(w, http.StatusCreated, "/order", s.order())
+	case r.URL.Path == "/order":
+		s.payload(r, nil)
+		s.writeJSON(w, http.StatusOK, "/order", s.order())
+	case scanID(r.URL.Path, "/authz/%d", &id):
+		s.payload(r, nil)
+		status := "pending"
+		if s.validated[id] {
+			status = "valid"
+		}
+		challenges := []map[string]string{}
+		for _, typ := range []string{HTTP01Challenge, DNS01Challenge} {
+			challenges = append(challenges, map[string]string{
+				"type":   typ,
+				"url":    fmt.Sprintf("%s/challenge/%d/%s", s.URL, id, typ),
+				"token":  "token",
+				"status": status,
+			})
+		}
+		s.writeJSON(w, http.StatusOK, "", map[string]interface{}{
+			"identifier": map[string]string{"type": "dns", "value": s.hosts[id]},
+			"status":     status,
+			"challenges": challenges,
+		})
+	case scanID(r.URL.Path, "/challenge/%d/", &id):
+		s.payload(r, nil)
+		s.accepted(s.hosts[id])
+		s.validated[id] = true
+		s.writeJSON(w, http.StatusOK, "", map[string]string{"type": r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:],
+			"url": s.URL + r.URL.Path, "token": "token", "status": "valid"})
+	case r.URL.Path == "/finalize":
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		s.payload(r, &req)
+		s.cert = s.sign(req.CSR)
+		s.writeJSON(w, http.StatusOK, "/order", s.order())
+	case r.URL.Path == "/cert":
+		s.payload(r, nil)
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		_, _ = w.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.cert}))
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func scanID(path, format string, id *int) bool {
+	n, _ := fmt.Sscanf(path, format, id)
+	return n == 1
+}
+
+// sign returns the DER of the certificate issued for the CSR, encoded with base64url.
+func (s *fakeACMEServer) sign(encoded string) []byte {
+	der, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, s.caCert, csr.PublicKey, s.caKey)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCertificateControllerIssue(t *testing.T) {
+	defer func(httpDelay, dnsDelay time.Duration) {
+		challengePropagationDelay, dnsChallengePropagationDelay = httpDelay, dnsDelay
+	}(challengePropagationDelay, dnsChallengePropagationDelay)
+	challengePropagationDelay, dnsChallengePropagationDelay = 0, 0
+
+	for _, challengeType := range []string{HTTP01Challenge, DNS01Challenge} {
+		t.Run(challengeType, func(t *testing.T) {
+			c, client, store := newCertificateController(t, challengeType)
+			c.leading = 1
+			gw := acmeGatewayConfig()
+			if _, err := store.Create(gw); err != nil {
+				t.Fatal(err)
+			}
+
+			// the response to the challenge of every host is published when it is accepted
+			var published []string
+			server := newFakeACMEServer(t, func(host string) {
+				if challengeType == DNS01Challenge {
+					_, err := c.dynamic.Resource(dnsEndpointResource).Namespace(gw.Namespace).
+						Get(dnsRecordName(gw.Name, host), metav1.GetOptions{})
+					if err == nil {
+						published = append(published, host)
+					}
+					return
+				}
+				current := store.Get(schemas.Gateway.Type, gw.Name, gw.Namespace)
+				if current.Annotations[challengeAnnotation(host)] != "" {
+					published = append(published, host)
+				}
+			})
+			defer server.Close()
+			c.directory = server.URL + "/directory"
+
+			if err := c.handle(gw, model.EventAdd); err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"example.org", "www.example.org"}; !reflect.DeepEqual(published, want) {
+				t.Errorf("got challenges published for %v, want %v", published, want)
+			}
+
+			secret, err := client.CoreV1().Secrets(gw.Namespace).Get("example", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if secret.Labels[ACMELabel] != gw.Name {
+				t.Errorf("got labels %v, want %s=%s", secret.Labels, ACMELabel, gw.Name)
+			}
+			if needsCertificate(secret, []string{"example.org", "www.example.org"}, time.Now()) {
+				t.Errorf("expected the secret to hold a valid certificate of the hosts")
+			}
+
+			// the responses are removed once the hosts are validated
+			current := store.Get(schemas.Gateway.Type, gw.Name, gw.Namespace)
+			if !reflect.DeepEqual(current.Annotations, map[string]string{ACMEAnnotation: "true"}) {
+				t.Errorf("got annotations %v, want the challenges removed", current.Annotations)
+			}
+			endpoints, err := c.dynamic.Resource(dnsEndpointResource).Namespace(gw.Namespace).List(metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(endpoints.Items) != 0 {
+				t.Errorf("got DNS records %v, want the challenges removed", endpoints.Items)
+			}
+		})
+	}
+}
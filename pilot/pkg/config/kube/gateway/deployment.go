@@ -0,0 +1,334 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gateway provisions the deployments and certificates of the gateways managed by Pilot.
+package gateway
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/kube"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/kube/inject"
+	"istio.io/pkg/log"
+)
+
+const (
+	// ClassAnnotation is the annotation of the Gateways selecting the class of the controller
+	// provisioning their deployment.
+	ClassAnnotation = "gateway.istio.io/class"
+
+	// ManagedLabel is the label of the deployment, service and autoscaler provisioned for a Gateway,
+	// set to its name.
+	ManagedLabel = "gateway.istio.io/managed"
+
+	minReplicas          = 1
+	maxReplicas          = 5
+	targetCPUUtilization = 80
+)
+
+var errorDelay = time.Second
+
+// DeploymentController provisions a Deployment, a Service and a HorizontalPodAutoscaler for every
+// Gateway of its class, named after the Gateway in its namespace. The pods run the gateway template
+// of the injector and carry the selector of the Gateway, so that it applies to them. The objects are
+// updated with the Gateway, and deleted with it or when it leaves the class.
+type DeploymentController struct {
+	client kubernetes.Interface
+	store  model.ConfigStoreCache
+	class  string
+	queue  kube.Queue
+}
+
+// NewDeploymentController creates a controller provisioning the deployments of the Gateways of the
+// class, watched in the store.
+func NewDeploymentController(client kubernetes.Interface, store model.ConfigStoreCache, class string) *DeploymentController {
+	d := &DeploymentController{
+		client: client,
+		store:  store,
+		class:  class,
+		queue:  kube.NewQueue(errorDelay),
+	}
+	store.RegisterEventHandler(schemas.Gateway.Type, func(c model.Config, event model.Event) {
+		d.queue.Push(kube.NewTask(d.handle, c, event))
+	})
+	return d
+}
+
+// Run provisions the deployments until the stop channel is closed. Once the store has synced, the
+// objects of the Gateways deleted while not running are deleted.
+func (d *DeploymentController) Run(stop <-chan struct{}) {
+	if !cache.WaitForCacheSync(stop, d.store.HasSynced) {
+		return
+	}
+	d.queue.Push(kube.NewTask(func(interface{}, model.Event) error {
+		return d.deleteOrphans()
+	}, nil, model.EventDelete))
+	d.queue.Run(stop)
+}
+
+func (d *DeploymentController) handle(obj interface{}, event model.Event) error {
+	c := obj.(model.Config)
+	switch {
+	case event != model.EventDelete && d.managed(c):
+		return d.apply(c)
+	case event == model.EventAdd:
+		return nil
+	default:
+		return d.delete(c.Name, c.Namespace)
+	}
+}
+
+func (d *DeploymentController) managed(c model.Config) bool {
+	return c.Annotations[ClassAnnotation] == d.class
+}
+
+// deleteOrphans deletes the objects provisioned for the Gateways which no longer exist or left the
+// class.
+func (d *DeploymentController) deleteOrphans() error {
+	deployments, err := d.client.AppsV1().Deployments(metav1.NamespaceAll).List(metav1.ListOptions{LabelSelector: ManagedLabel})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		name := deployment.Labels[ManagedLabel]
+		if c := d.store.Get(schemas.Gateway.Type, name, deployment.Namespace); c != nil && d.managed(*c) {
+			continue
+		}
+		if err := d.delete(name, deployment.Namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply creates or updates the objects provisioned for the Gateway.
+func (d *DeploymentController) apply(c model.Config) error {
+	gw := c.Spec.(*networking.Gateway)
+	if len(gw.Selector) == 0 {
+		log.Warnf("Not provisioning gateway %s/%s: it has no selector", c.Namespace, c.Name)
+		return nil
+	}
+	ports := servicePorts(gw)
+
+	deployment, err := d.client.AppsV1().Deployments(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	create := errors.IsNotFound(err)
+	if create {
+		deployment = &appsv1.Deployment{ObjectMeta: objectMeta(c)}
+	} else if err != nil {
+		return err
+	} else if !isManaged(deployment.ObjectMeta, c.Name) {
+		log.Warnf("Not provisioning gateway %s/%s: deployment %s is not managed by Pilot", c.Namespace, c.Name, c.Name)
+		return nil
+	}
+	deployment.Spec = deploymentSpec(c, gw, ports)
+	if create {
+		_, err = d.client.AppsV1().Deployments(c.Namespace).Create(deployment)
+	} else {
+		_, err = d.client.AppsV1().Deployments(c.Namespace).Update(deployment)
+	}
+	if err != nil {
+		return err
+	}
+
+	service, err := d.client.CoreV1().Services(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	create = errors.IsNotFound(err)
+	if create {
+		service = &corev1.Service{ObjectMeta: objectMeta(c)}
+	} else if err != nil {
+		return err
+	} else if !isManaged(service.ObjectMeta, c.Name) {
+		log.Warnf("Not provisioning gateway %s/%s: service %s is not managed by Pilot", c.Namespace, c.Name, c.Name)
+		return nil
+	}
+	// the cluster IP and the node ports are allocated once
+	service.Spec.Type = corev1.ServiceTypeLoadBalancer
+	service.Spec.Selector = map[string]string{ManagedLabel: c.Name}
+	service.Spec.Ports = mergeNodePorts(ports, service.Spec.Ports)
+	if create {
+		_, err = d.client.CoreV1().Services(c.Namespace).Create(service)
+	} else {
+		_, err = d.client.CoreV1().Services(c.Namespace).Update(service)
+	}
+	if err != nil {
+		return err
+	}
+
+	hpa, err := d.client.AutoscalingV1().HorizontalPodAutoscalers(c.Namespace).Get(c.Name, metav1.GetOptions{})
+	create = errors.IsNotFound(err)
+	if create {
+		hpa = &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: objectMeta(c)}
+	} else if err != nil {
+		return err
+	} else if !isManaged(hpa.ObjectMeta, c.Name) {
+		log.Warnf("Not provisioning gateway %s/%s: autoscaler %s is not managed by Pilot", c.Namespace, c.Name, c.Name)
+		return nil
+	}
+	min, cpu := int32(minReplicas), int32(targetCPUUtilization)
+	hpa.Spec = autoscalingv1.HorizontalPodAutoscalerSpec{
+		ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Name:       c.Name,
+		},
+		MinReplicas:                    &min,
+		MaxReplicas:                    maxReplicas,
+		TargetCPUUtilizationPercentage: &cpu,
+	}
+	if create {
+		_, err = d.client.AutoscalingV1().HorizontalPodAutoscalers(c.Namespace).Create(hpa)
+	} else {
+		_, err = d.client.AutoscalingV1().HorizontalPodAutoscalers(c.Namespace).Update(hpa)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("Provisioned gateway %s/%s", c.Namespace, c.Name)
+	return nil
+}
+
+// delete deletes the objects provisioned for the Gateway, if any.
+func (d *DeploymentController) delete(name, namespace string) error {
+	if hpa, err := d.client.AutoscalingV1().HorizontalPodAutoscalers(namespace).Get(name, metav1.GetOptions{}); err == nil {
+		if isManaged(hpa.ObjectMeta, name) {
+			if err := d.client.AutoscalingV1().HorizontalPodAutoscalers(namespace).Delete(name, nil); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	if service, err := d.client.CoreV1().Services(namespace).Get(name, metav1.GetOptions{}); err == nil {
+		if isManaged(service.ObjectMeta, name) {
+			if err := d.client.CoreV1().Services(namespace).Delete(name, nil); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+	deployment, err := d.client.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !isManaged(deployment.ObjectMeta, name) {
+		return nil
+	}
+	propagation := metav1.DeletePropagationForeground
+	if err := d.client.AppsV1().Deployments(namespace).Delete(name,
+		&metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	log.Infof("Deleted the deployment of gateway %s/%s", namespace, name)
+	return nil
+}
+
+func isManaged(meta metav1.ObjectMeta, name string) bool {
+	return meta.Labels[ManagedLabel] == name
+}
+
+func objectMeta(c model.Config) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      c.Name,
+		Namespace: c.Namespace,
+		Labels:    map[string]string{ManagedLabel: c.Name},
+	}
+}
+
+func deploymentSpec(c model.Config, gw *networking.Gateway, ports []corev1.ServicePort) appsv1.DeploymentSpec {
+	podLabels := map[string]string{
+		ManagedLabel:                 c.Name,
+		inject.GatewayInjectionLabel: "true",
+	}
+	for k, v := range gw.Selector {
+		podLabels[k] = v
+	}
+	containerPorts := make([]corev1.ContainerPort, 0, len(ports))
+	for _, p := range ports {
+		containerPorts = append(containerPorts, corev1.ContainerPort{
+			Name:          p.Name,
+			ContainerPort: p.Port,
+			Protocol:      corev1.ProtocolTCP,
+		})
+	}
+	return appsv1.DeploymentSpec{
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{ManagedLabel: c.Name}},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: inject.ProxyContainerName,
+					// the proxy is completed by the gateway template of the injector
+					Image: inject.AutoImage,
+					Ports: containerPorts,
+					// the autoscaler scales on the cpu utilization
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+					},
+				}},
+			},
+		},
+	}
+}
+
+// servicePorts returns the ports of the servers of the Gateway, sorted by number.
+func servicePorts(gw *networking.Gateway) []corev1.ServicePort {
+	seen := make(map[uint32]bool)
+	var ports []corev1.ServicePort
+	for _, s := range gw.Servers {
+		if s.Port == nil || seen[s.Port.Number] {
+			continue
+		}
+		seen[s.Port.Number] = true
+		ports = append(ports, corev1.ServicePort{
+			// the protocol prefix selects the protocol of the port in the mesh
+			Name:       fmt.Sprintf("%s-%d", strings.ToLower(s.Port.Protocol), s.Port.Number),
+			Port:       int32(s.Port.Number),
+			TargetPort: intstr.FromInt(int(s.Port.Number)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].Port < ports[j].Port })
+	return ports
+}
+
+// mergeNodePorts keeps the node ports allocated to the current ports of the service.
+func mergeNodePorts(ports, current []corev1.ServicePort) []corev1.ServicePort {
+	nodePorts := make(map[int32]int32, len(current))
+	for _, p := range current {
+		nodePorts[p.Port] = p.NodePort
+	}
+	for i := range ports {
+		ports[i].NodePort = nodePorts[ports[i].Port]
+	}
+	return ports
+}
@@ -0,0 +1,194 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/kube/inject"
+)
+
+func gatewayConfig(class string) model.Config {
+	return model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.Gateway.Type,
+			Group:       schemas.Gateway.Group,
+			Version:     schemas.Gateway.Version,
+			Name:        "edge",
+			Namespace:   "ingress",
+			Annotations: map[string]string{ClassAnnotation: class},
+		},
+		Spec: &networking.Gateway{
+			Selector: map[string]string{"istio": "edge"},
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"example.org"},
+					Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_PASSTHROUGH},
+				},
+				{
+					Hosts: []string{"example.org"},
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+				},
+				{
+					Hosts: []string{"internal.example.org"},
+					Port:  &networking.Port{Name: "https-internal", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_PASSTHROUGH},
+				},
+			},
+		},
+	}
+}
+
+func newController() (*DeploymentController, *fake.Clientset, model.ConfigStoreCache) {
+	client := fake.NewSimpleClientset()
+	store := memory.NewController(memory.Make(schema.Set{schemas.Gateway}))
+	return NewDeploymentController(client, store, "managed"), client, store
+}
+
+func TestDeploymentController(t *testing.T) {
+	d, client, _ := newController()
+
+	if err := d.handle(gatewayConfig("managed"), model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	deployment, err := client.AppsV1().Deployments("ingress").Get("edge", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := deployment.Spec.Template
+	if pod.Labels["istio"] != "edge" || pod.Labels[inject.GatewayInjectionLabel] != "true" || pod.Labels[ManagedLabel] != "edge" {
+		t.Errorf("expected the pods to be selected by the gateway and injected as a gateway, got %v", pod.Labels)
+	}
+	if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Image != inject.AutoImage {
+		t.Errorf("expected a proxy completed by the injector, got %v", pod.Spec.Containers)
+	}
+	if len(pod.Spec.Containers[0].Ports) != 2 {
+		t.Errorf("expected a container port per server port, got %v", pod.Spec.Containers[0].Ports)
+	}
+
+	service, err := client.CoreV1().Services("ingress").Get("edge", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		t.Errorf("got service type %v, want LoadBalancer", service.Spec.Type)
+	}
+	var names []string
+	for _, p := range service.Spec.Ports {
+		names = append(names, p.Name)
+	}
+	if len(names) != 2 || names[0] != "http-80" || names[1] != "https-443" {
+		t.Errorf("got service ports %v, want [http-80 https-443]", names)
+	}
+
+	hpa, err := client.AutoscalingV1().HorizontalPodAutoscalers("ingress").Get("edge", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hpa.Spec.ScaleTargetRef.Name != "edge" || hpa.Spec.ScaleTargetRef.Kind != "Deployment" {
+		t.Errorf("expected the autoscaler to scale the deployment, got %v", hpa.Spec.ScaleTargetRef)
+	}
+
+	// the node ports allocated to the service are kept by the updates
+	service.Spec.Ports[0].NodePort = 31080
+	if _, err := client.CoreV1().Services("ingress").Update(service); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.handle(gatewayConfig("managed"), model.EventUpdate); err != nil {
+		t.Fatal(err)
+	}
+	service, err = client.CoreV1().Services("ingress").Get("edge", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if service.Spec.Ports[0].NodePort != 31080 {
+		t.Errorf("expected the node port to be kept, got %v", service.Spec.Ports)
+	}
+
+	// leaving the class tears the gateway down
+	if err := d.handle(gatewayConfig("other"), model.EventUpdate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AppsV1().Deployments("ingress").Get("edge", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the deployment to be deleted, got %v", err)
+	}
+	if _, err := client.CoreV1().Services("ingress").Get("edge", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the service to be deleted, got %v", err)
+	}
+	if _, err := client.AutoscalingV1().HorizontalPodAutoscalers("ingress").Get("edge", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the autoscaler to be deleted, got %v", err)
+	}
+}
+
+func TestDeploymentControllerUnmanaged(t *testing.T) {
+	d, client, _ := newController()
+	existing := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "ingress"}}
+	if _, err := client.AppsV1().Deployments("ingress").Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.handle(gatewayConfig("managed"), model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CoreV1().Services("ingress").Get("edge", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the gateway not to be provisioned over an existing deployment, got %v", err)
+	}
+
+	if err := d.handle(gatewayConfig("managed"), model.EventDelete); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AppsV1().Deployments("ingress").Get("edge", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the existing deployment to be kept, got %v", err)
+	}
+}
+
+func TestDeploymentControllerDeleteOrphans(t *testing.T) {
+	d, client, store := newController()
+	gw := gatewayConfig("managed")
+	if err := d.handle(gw, model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	orphan := gatewayConfig("managed")
+	orphan.Name = "deleted"
+	if err := d.handle(orphan, model.EventAdd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(gw); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.deleteOrphans(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AppsV1().Deployments("ingress").Get("edge", metav1.GetOptions{}); err != nil {
+		t.Errorf("expected the deployment of the gateway to be kept, got %v", err)
+	}
+	if _, err := client.AppsV1().Deployments("ingress").Get("deleted", metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("expected the deployment of the deleted gateway to be deleted, got %v", err)
+	}
+}
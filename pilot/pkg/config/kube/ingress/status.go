@@ -41,6 +41,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry/kube"
 	controller2 "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/pkg/env"
 	"istio.io/pkg/log"
 )
@@ -50,6 +51,10 @@ const (
 	ingressElectionID = "istio-ingress-controller-leader"
 )
 
+// ingressGatewayLabels select the pods of the gateway serving the ingresses, the way the
+// istio-autogenerated-k8s-ingress Gateway installed by default selects them.
+var ingressGatewayLabels = map[string]string{constants.IstioLabel: "ingressgateway"}
+
 // StatusSyncer keeps the status IP in each Ingress resource updated
 type StatusSyncer struct {
 	client kubernetes.Interface
@@ -180,11 +185,32 @@ func NewStatusSyncer(mesh *meshconfig.MeshConfig,
 		return st.updateStatus(sliceToStatus(addrs))
 	})
 
+	// The status of the new or updated ingresses is written right away rather than at the next interval.
+	// Writing the status updates the ingress again, which is a no-op once its status is up to date.
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			st.onIngressEvent(obj, model.EventAdd)
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			st.onIngressEvent(cur, model.EventUpdate)
+		},
+	})
+
 	return &st, nil
 }
 
+// onIngressEvent queues the update of the ingresses status, if this instance is the status update leader.
+func (s *StatusSyncer) onIngressEvent(obj interface{}, event model.Event) {
+	if s.elector == nil || !s.elector.IsLeader() {
+		return
+	}
+	s.queue.Push(kube.NewTask(s.handler.Apply, obj, event))
+}
+
 // updateStatus updates ingress status with the list of IP
 func (s *StatusSyncer) updateStatus(status []coreV1.LoadBalancerIngress) error {
+	sort.SliceStable(status, lessLoadBalancerIngress(status))
+
 	ingressStore := s.informer.GetStore()
 	for _, obj := range ingressStore.List() {
 		currIng := obj.(*v1beta1.Ingress)
@@ -192,15 +218,17 @@ func (s *StatusSyncer) updateStatus(status []coreV1.LoadBalancerIngress) error {
 			continue
 		}
 
-		curIPs := currIng.Status.LoadBalancer.Ingress
-		sort.SliceStable(status, lessLoadBalancerIngress(status))
+		curIPs := make([]coreV1.LoadBalancerIngress, len(currIng.Status.LoadBalancer.Ingress))
+		copy(curIPs, currIng.Status.LoadBalancer.Ingress)
 		sort.SliceStable(curIPs, lessLoadBalancerIngress(curIPs))
 
 		if ingressSliceEqual(status, curIPs) {
-			log.Infof("skipping update of Ingress %v/%v (no change)", currIng.Namespace, currIng.Name)
-			return nil
+			log.Debugf("skipping update of Ingress %v/%v (no change)", currIng.Namespace, currIng.Name)
+			continue
 		}
 
+		// the objects of the informer store are shared and must not be modified
+		currIng = currIng.DeepCopy()
 		currIng.Status.LoadBalancer.Ingress = status
 
 		ingClient := s.client.ExtensionsV1beta1().Ingresses(currIng.Namespace)
@@ -243,8 +271,7 @@ func (s *StatusSyncer) runningAddresses(ingressNs string) ([]string, error) {
 
 	// get information about all the pods running the ingress controller (gateway)
 	pods, err := s.client.CoreV1().Pods(ingressNamespace).List(metaV1.ListOptions{
-		// TODO: make it a const or maybe setting ( unless we remove k8s ingress support first)
-		LabelSelector: labels.SelectorFromSet(map[string]string{"app": "ingressgateway"}).String(),
+		LabelSelector: labels.SelectorFromSet(ingressGatewayLabels).String(),
 	})
 	if err != nil {
 		return nil, err
@@ -63,7 +63,8 @@ func makeFakeClient() *fake.Clientset {
 					Name:      "ingressgateway",
 					Namespace: "istio-system",
 					Labels: map[string]string{
-						"app": "ingressgateway",
+						"app":   "istio-ingressgateway",
+						"istio": "ingressgateway",
 					},
 				},
 				Spec: coreV1.PodSpec{
@@ -258,3 +259,58 @@ func TestRunningAddressesWithPod(t *testing.T) {
 		t.Errorf("Address is not correctly set to node ip %v %v", address, nodeIP)
 	}
 }
+
+func TestUpdateStatus(t *testing.T) {
+	status := []coreV1.LoadBalancerIngress{{IP: serviceIP}}
+	ingresses := []*extensions.Ingress{
+		{
+			ObjectMeta: metaV1.ObjectMeta{Name: "up-to-date", Namespace: testNamespace},
+			Status:     extensions.IngressStatus{LoadBalancer: coreV1.LoadBalancerStatus{Ingress: status}},
+		},
+		{
+			ObjectMeta: metaV1.ObjectMeta{Name: "new", Namespace: testNamespace},
+		},
+		{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:        "other-class",
+				Namespace:   testNamespace,
+				Annotations: map[string]string{kube.IngressClassAnnotation: "nginx"},
+			},
+		},
+	}
+
+	client := makeFakeClient()
+	syncer, err := makeStatusSyncer(t, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ing := range ingresses {
+		if _, err := client.ExtensionsV1beta1().Ingresses(testNamespace).Create(ing); err != nil {
+			t.Fatal(err)
+		}
+		if err := syncer.informer.GetStore().Add(ing); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := syncer.updateStatus(sliceToStatus([]string{serviceIP})); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ing := range ingresses {
+		got, err := client.ExtensionsV1beta1().Ingresses(testNamespace).Get(ing.Name, metaV1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := status
+		if ing.Name == "other-class" {
+			want = nil
+		}
+		if !ingressSliceEqual(got.Status.LoadBalancer.Ingress, want) {
+			t.Errorf("got status %v of ingress %s, want %v", got.Status.LoadBalancer.Ingress, ing.Name, want)
+		}
+	}
+	if len(ingresses[1].Status.LoadBalancer.Ingress) != 0 {
+		t.Error("the ingress of the informer store was modified")
+	}
+}
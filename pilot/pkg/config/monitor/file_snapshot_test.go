@@ -77,7 +77,8 @@ func TestFileSnapshotNoFilter(t *testing.T) {
 
 	gateway := configs[0].Spec.(*networking.Gateway)
 	g.Expect(gateway.Servers[0].Port.Number).To(gomega.Equal(uint32(80)))
-	g.Expect(gateway.Servers[0].Port.Protocol).To(gomega.Equal("http"))
+	// The protocol is defaulted to its documented upper case spelling.
+	g.Expect(gateway.Servers[0].Port.Protocol).To(gomega.Equal("HTTP"))
 	g.Expect(gateway.Servers[0].Hosts).To(gomega.Equal([]string{"*.example.com"}))
 }
 
@@ -15,16 +15,24 @@
 package monitor
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/gogo/protobuf/proto"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema"
 	"istio.io/pkg/log"
 )
 
+// watchDebounceDelay is how long the Monitor waits after a change of the watched files before taking a snapshot,
+// so that a burst of changes, e.g. the files of a config map being updated, results in a single update.
+const watchDebounceDelay = 100 * time.Millisecond
+
 // Monitor will poll a config function in order to update a ConfigStore as
 // changes are found.
 type Monitor struct {
@@ -33,6 +41,8 @@ type Monitor struct {
 	checkDuration   time.Duration
 	configs         []*model.Config
 	getSnapshotFunc func() ([]*model.Config, error)
+	// root is the directory watched for changes, if any.
+	root string
 }
 
 // NewMonitor creates a Monitor and will delegate to a passed in controller.
@@ -48,6 +58,15 @@ func NewMonitor(name string, delegateStore model.ConfigStore, checkInterval time
 	return monitor
 }
 
+// NewFileMonitor creates a Monitor of the config yaml files in the root directory and its subdirectories.
+// The files are read as soon as they change, and every checkInterval in case a change was missed.
+func NewFileMonitor(name string, delegateStore model.ConfigStore, checkInterval time.Duration, root string,
+	descriptor schema.Set) *Monitor {
+	monitor := NewMonitor(name, delegateStore, checkInterval, NewFileSnapshot(root, descriptor).ReadConfigFiles)
+	monitor.root = root
+	return monitor
+}
+
 // Start starts a new Monitor. Immediately checks the Monitor getSnapshotFunc
 // and updates the controller. It then kicks off an asynchronous event loop that
 // periodically polls the getSnapshotFunc for changes until a close event is sent.
@@ -55,20 +74,66 @@ func (m *Monitor) Start(stop <-chan struct{}) {
 	m.checkAndUpdate()
 	tick := time.NewTicker(m.checkDuration)
 
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	var watcher *fsnotify.Watcher
+	if m.root != "" {
+		var err error
+		if watcher, err = fsnotify.NewWatcher(); err != nil {
+			log.Warnf("cannot watch %s for %s, polling every %v: %v", m.root, m.name, m.checkDuration, err)
+		} else {
+			m.watchDirs(watcher)
+			events, errs = watcher.Events, watcher.Errors
+		}
+	}
+
 	// Run the close loop asynchronously.
 	go func() {
+		var debounce <-chan time.Time
 		for {
 			select {
 			case <-stop:
 				tick.Stop()
+				if watcher != nil {
+					_ = watcher.Close()
+				}
 				return
 			case <-tick.C:
 				m.checkAndUpdate()
+			case e := <-events:
+				log.Debugf("%s: %v", m.name, e)
+				if debounce == nil {
+					debounce = time.After(watchDebounceDelay)
+				}
+			case err := <-errs:
+				log.Warnf("error watching %s for %s: %v", m.root, m.name, err)
+			case <-debounce:
+				debounce = nil
+				// New subdirectories are only watched once they are added to the watcher.
+				m.watchDirs(watcher)
+				m.checkAndUpdate()
 			}
 		}
 	}()
 }
 
+// watchDirs adds the root directory and its subdirectories to the watcher, which does not watch the
+// subdirectories of the watched directories.
+func (m *Monitor) watchDirs(watcher *fsnotify.Watcher) {
+	err := filepath.Walk(m.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Warnf("cannot watch %s for %s, polling every %v: %v", m.root, m.name, m.checkDuration, err)
+	}
+}
+
 func (m *Monitor) checkAndUpdate() {
 	newConfigs, err := m.getSnapshotFunc()
 	//If an error exists then log it and return to running the check and update
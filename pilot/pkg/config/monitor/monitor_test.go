@@ -16,6 +16,10 @@ package monitor_test
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -187,3 +191,42 @@ func TestMonitorForError(t *testing.T) {
 		return nil
 	}).Should(gomega.Succeed())
 }
+
+func TestFileMonitorForChange(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	ts := &testState{
+		ConfigFiles: map[string][]byte{
+			"gateway.yml":         []byte(gatewayYAML),
+			"virtual_service.yml": []byte(virtualServiceYAML),
+		},
+	}
+	ts.testSetup(t)
+	defer ts.testTeardown(t)
+
+	store := memory.Make(schemas.Istio)
+	// Poll rarely, so that the updates can only come from watching the directory.
+	mon := monitor.NewFileMonitor("", store, time.Hour, ts.rootPath, nil)
+	stop := make(chan struct{})
+	defer close(stop)
+	mon.Start(stop)
+
+	c, err := store.List(schemas.Gateway.Type, "")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(c).To(gomega.HaveLen(1))
+
+	// Subdirectories are watched too.
+	dir := filepath.Join(ts.rootPath, "routes")
+	g.Expect(os.Mkdir(dir, 0700)).To(gomega.Succeed())
+	time.Sleep(checkInterval)
+	otherVirtualServiceYAML := strings.Replace(virtualServiceYAML, "route-for-myapp", "other-route", 1)
+	g.Expect(ioutil.WriteFile(filepath.Join(dir, "virtual_service.yml"), []byte(otherVirtualServiceYAML), 0600)).To(gomega.Succeed())
+	g.Eventually(func() ([]model.Config, error) {
+		return store.List(schemas.VirtualService.Type, "")
+	}).Should(gomega.HaveLen(2))
+
+	g.Expect(os.Remove(filepath.Join(ts.rootPath, "virtual_service.yml"))).To(gomega.Succeed())
+	g.Eventually(func() ([]model.Config, error) {
+		return store.List(schemas.VirtualService.Type, "")
+	}).Should(gomega.HaveLen(1))
+}
@@ -0,0 +1,221 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+
+	"istio.io/pkg/env"
+)
+
+// DynamicVar is a feature flag read from its environment variable, whose value can be overridden at runtime by
+// the runtime flags of Pilot, without restart. The override is removed with the flag from the runtime flags.
+type DynamicVar struct {
+	Name        string
+	Description string
+
+	// lookup returns the value of the environment variable, or its default
+	lookup func() interface{}
+	parse  func(string) (interface{}, error)
+
+	// override holds the dynamicValue overriding the environment variable
+	override atomic.Value
+}
+
+type dynamicValue struct {
+	value interface{}
+	set   bool
+}
+
+// DynamicFlag is the current value of a dynamic feature flag.
+type DynamicFlag struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Overridden  bool   `json:"overridden"`
+	Description string `json:"description"`
+}
+
+var (
+	dynamicVarsMutex sync.Mutex
+	dynamicVars      = make(map[string]*DynamicVar)
+)
+
+func (v *DynamicVar) get() interface{} {
+	if o, ok := v.override.Load().(dynamicValue); ok && o.set {
+		return o.value
+	}
+	return v.lookup()
+}
+
+// DynamicDurationVar is a dynamic feature flag holding a duration.
+type DynamicDurationVar struct{ *DynamicVar }
+
+// Get returns the current value of the flag.
+func (v DynamicDurationVar) Get() time.Duration {
+	return v.get().(time.Duration)
+}
+
+// DynamicIntVar is a dynamic feature flag holding an integer.
+type DynamicIntVar struct{ *DynamicVar }
+
+// Get returns the current value of the flag.
+func (v DynamicIntVar) Get() int {
+	return v.get().(int)
+}
+
+// DynamicBoolVar is a dynamic feature flag holding a boolean.
+type DynamicBoolVar struct{ *DynamicVar }
+
+// Get returns the current value of the flag.
+func (v DynamicBoolVar) Get() bool {
+	return v.get().(bool)
+}
+
+// DynamicFloatVar is a dynamic feature flag holding a float.
+type DynamicFloatVar struct{ *DynamicVar }
+
+// Get returns the current value of the flag.
+func (v DynamicFloatVar) Get() float64 {
+	return v.get().(float64)
+}
+
+func registerDynamicVar(name, description string, lookup func() interface{},
+	parse func(string) (interface{}, error)) *DynamicVar {
+	v := &DynamicVar{
+		Name:        name,
+		Description: description,
+		lookup:      lookup,
+		parse:       parse,
+	}
+	dynamicVarsMutex.Lock()
+	dynamicVars[name] = v
+	dynamicVarsMutex.Unlock()
+	return v
+}
+
+// registerDynamicDurationVar registers a dynamic feature flag holding a positive duration.
+func registerDynamicDurationVar(name string, defaultValue time.Duration, description string) DynamicDurationVar {
+	e := env.RegisterDurationVar(name, defaultValue, description)
+	return DynamicDurationVar{registerDynamicVar(name, description,
+		func() interface{} { return e.Get() },
+		func(s string) (interface{}, error) {
+			d, err := time.ParseDuration(s)
+			if err == nil && d <= 0 {
+				err = fmt.Errorf("duration %v is not positive", d)
+			}
+			return d, err
+		})}
+}
+
+// registerDynamicIntVar registers a dynamic feature flag holding a positive integer.
+func registerDynamicIntVar(name string, defaultValue int, description string) DynamicIntVar {
+	e := env.RegisterIntVar(name, defaultValue, description)
+	return DynamicIntVar{registerDynamicVar(name, description,
+		func() interface{} { return e.Get() },
+		func(s string) (interface{}, error) {
+			i, err := strconv.Atoi(s)
+			if err == nil && i <= 0 {
+				err = fmt.Errorf("%d is not positive", i)
+			}
+			return i, err
+		})}
+}
+
+// registerDynamicBoolVar registers a dynamic feature flag holding a boolean.
+func registerDynamicBoolVar(name string, defaultValue bool, description string) DynamicBoolVar {
+	e := env.RegisterBoolVar(name, defaultValue, description)
+	return DynamicBoolVar{registerDynamicVar(name, description,
+		func() interface{} { return e.Get() },
+		func(s string) (interface{}, error) { return strconv.ParseBool(s) })}
+}
+
+// registerDynamicPercentageVar registers a dynamic feature flag holding a percentage between 0 and 100.
+func registerDynamicPercentageVar(name string, defaultValue float64, description string) DynamicFloatVar {
+	e := env.RegisterFloatVar(name, defaultValue, description)
+	return DynamicFloatVar{registerDynamicVar(name, description,
+		func() interface{} { return e.Get() },
+		func(s string) (interface{}, error) {
+			f, err := strconv.ParseFloat(s, 64)
+			if err == nil && (f < 0.0 || f > 100.0) {
+				err = fmt.Errorf("%v is not a percentage", f)
+			}
+			return f, err
+		})}
+}
+
+// ApplyDynamicFlags overrides the dynamic feature flags named by the keys of the map with their values, and
+// removes the overrides of the other flags. Nothing is applied if a flag is unknown or has an invalid value.
+// It returns the names of the flags whose value changed.
+func ApplyDynamicFlags(flags map[string]string) ([]string, error) {
+	dynamicVarsMutex.Lock()
+	defer dynamicVarsMutex.Unlock()
+
+	var errs error
+	overrides := make(map[string]interface{}, len(flags))
+	for name, value := range flags {
+		v, f := dynamicVars[name]
+		if !f {
+			errs = multierror.Append(errs, fmt.Errorf("%s is not a dynamic feature flag", name))
+			continue
+		}
+		parsed, err := v.parse(value)
+		if err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("invalid %s %q: %v", name, value, err))
+			continue
+		}
+		overrides[name] = parsed
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	var changed []string
+	for name, v := range dynamicVars {
+		previous := v.get()
+		o, set := overrides[name]
+		v.override.Store(dynamicValue{value: o, set: set})
+		if v.get() != previous {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// DynamicFlags returns the current values of the dynamic feature flags, sorted by name.
+func DynamicFlags() []DynamicFlag {
+	dynamicVarsMutex.Lock()
+	defer dynamicVarsMutex.Unlock()
+
+	flags := make([]DynamicFlag, 0, len(dynamicVars))
+	for name, v := range dynamicVars {
+		o, _ := v.override.Load().(dynamicValue)
+		flags = append(flags, DynamicFlag{
+			Name:        name,
+			Value:       fmt.Sprint(v.get()),
+			Overridden:  o.set,
+			Description: v.Description,
+		})
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
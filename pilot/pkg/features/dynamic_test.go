@@ -0,0 +1,119 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package features
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyDynamicFlags(t *testing.T) {
+	defer func() {
+		if _, err := ApplyDynamicFlags(nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tests := []struct {
+		name    string
+		flags   map[string]string
+		changed []string
+		wantErr bool
+	}{
+		{
+			name:    "override",
+			flags:   map[string]string{DebounceAfter.Name: "1s", PushThrottle.Name: "10"},
+			changed: []string{DebounceAfter.Name, PushThrottle.Name},
+		},
+		{
+			name:    "same values",
+			flags:   map[string]string{DebounceAfter.Name: "1000ms", PushThrottle.Name: "10"},
+			changed: nil,
+		},
+		{
+			name:    "unknown flag",
+			flags:   map[string]string{"PILOT_UNKNOWN": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid duration",
+			flags:   map[string]string{DebounceMax.Name: "-1s"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid percentage",
+			flags:   map[string]string{TraceSampling.Name: "101"},
+			wantErr: true,
+		},
+		{
+			name:    "revert",
+			flags:   map[string]string{PushThrottle.Name: "10"},
+			changed: []string{DebounceAfter.Name},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := ApplyDynamicFlags(tt.flags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyDynamicFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(changed, tt.changed) {
+				t.Fatalf("ApplyDynamicFlags() changed = %v, want %v", changed, tt.changed)
+			}
+		})
+	}
+
+	// Invalid flags are not applied
+	if got := PushThrottle.Get(); got != 10 {
+		t.Errorf("PushThrottle.Get() = %v, want 10", got)
+	}
+	if got := DebounceAfter.Get(); got != 100*time.Millisecond {
+		t.Errorf("DebounceAfter.Get() = %v, want 100ms", got)
+	}
+}
+
+func TestDynamicFlags(t *testing.T) {
+	os.Setenv(DebounceMax.Name, "5s")
+	defer os.Unsetenv(DebounceMax.Name)
+	if _, err := ApplyDynamicFlags(map[string]string{EnableEDSDebounce.Name: "false"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if _, err := ApplyDynamicFlags(nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	want := map[string]DynamicFlag{
+		DebounceMax.Name:       {Name: DebounceMax.Name, Value: "5s"},
+		EnableEDSDebounce.Name: {Name: EnableEDSDebounce.Name, Value: "false", Overridden: true},
+	}
+	for _, flag := range DynamicFlags() {
+		w, f := want[flag.Name]
+		if !f {
+			continue
+		}
+		flag.Description = ""
+		if flag != w {
+			t.Errorf("DynamicFlags() got %+v, want %+v", flag, w)
+		}
+		delete(want, flag.Name)
+	}
+	if len(want) != 0 {
+		t.Errorf("DynamicFlags() is missing %v", want)
+	}
+}
@@ -35,17 +35,26 @@ var (
 		"Sets the maximum number of concurrent grpc streams.",
 	).Get()
 
-	TraceSampling = env.RegisterFloatVar(
+	TraceSampling = registerDynamicPercentageVar(
 		"PILOT_TRACE_SAMPLING",
 		100.0,
 		"Sets the mesh-wide trace sampling percentage. Should be 0.0 - 100.0. Precision to 0.01. "+
 			"Default is 100, not recommended for production use.",
-	).Get()
+	)
 
-	PushThrottle = env.RegisterIntVar(
+	PushThrottle = registerDynamicIntVar(
 		"PILOT_PUSH_THROTTLE",
 		100,
 		"Limits the number of concurrent pushes allowed. On larger machines this can be increased for faster pushes",
+	)
+
+	// PushGenerationWorkers limits the number of proxies whose config is generated concurrently.
+	// Defaults to 0, meaning the number of CPUs usable by Pilot.
+	PushGenerationWorkers = env.RegisterIntVar(
+		"PILOT_PUSH_GENERATION_WORKERS",
+		0,
+		"Limits the number of proxies whose config is generated concurrently, among the concurrent pushes "+
+			"allowed by PILOT_PUSH_THROTTLE. Defaults to the number of CPUs usable by Pilot.",
 	).Get()
 
 	// DebugConfigs controls saving snapshots of configs for /debug/adsz.
@@ -53,22 +62,22 @@ var (
 	// For larger clusters it can increase memory use and GC - useful for small tests.
 	DebugConfigs = env.RegisterBoolVar("PILOT_DEBUG_ADSZ_CONFIG", false, "").Get()
 
-	DebounceAfter = env.RegisterDurationVar(
+	DebounceAfter = registerDynamicDurationVar(
 		"PILOT_DEBOUNCE_AFTER",
 		100*time.Millisecond,
 		"The delay added to config/registry events for debouncing. This will delay the push by "+
 			"at least this internal. If no change is detected within this period, the push will happen, "+
 			" otherwise we'll keep delaying until things settle, up to a max of PILOT_DEBOUNCE_MAX.",
-	).Get()
+	)
 
-	DebounceMax = env.RegisterDurationVar(
+	DebounceMax = registerDynamicDurationVar(
 		"PILOT_DEBOUNCE_MAX",
 		10*time.Second,
 		"The maximum amount of time to wait for events while debouncing. If events keep showing up with no breaks "+
 			"for this time, we'll trigger a push.",
-	).Get()
+	)
 
-	EnableEDSDebounce = env.RegisterBoolVar(
+	EnableEDSDebounce = registerDynamicBoolVar(
 		"PILOT_ENABLE_EDS_DEBOUNCE",
 		true,
 		"If enabled, Pilot will include EDS pushes in the push debouncing, configured by PILOT_DEBOUNCE_AFTER and PILOT_DEBOUNCE_MAX."+
@@ -117,6 +126,15 @@ var (
 		return time.Second * time.Duration(terminationDrainDurationVar.Get())
 	}
 
+	HTTPDrainTimeout = env.RegisterDurationVar(
+		"PILOT_HTTP_DRAIN_TIMEOUT",
+		0,
+		"The time the HTTP/2 and gRPC connections of a listener drained on a push that changes or removes it "+
+			"are given to complete their requests, between the GOAWAY announcing the shutdown and the final one, "+
+			"within the drain duration of the proxy. If not set, Envoy's default of 5s is used. Pods can override "+
+			"it with the sidecar.istio.io/httpDrainTimeout annotation.",
+	).Get()
+
 	EnableFallthroughRoute = env.RegisterBoolVar(
 		"PILOT_ENABLE_FALLTHROUGH_ROUTE",
 		true,
@@ -246,6 +264,86 @@ var (
 		"If enabled, Pilot will keep track of old versions of distributed config for this duration.",
 	).Get()
 
+	ConfigHistorySize = env.RegisterIntVar(
+		"PILOT_CONFIG_HISTORY_SIZE",
+		1000,
+		"Number of the latest config changes recorded by Pilot, shown by /debug/configz?history.",
+	).Get()
+
+	DebugProfilingToken = env.RegisterStringVar(
+		"PILOT_DEBUG_PROFILING_TOKEN",
+		"",
+		"If set, the profiling endpoints under /debug/pprof require the requests to carry the header "+
+			"'Authorization: Bearer <token>'. Otherwise, they only accept the requests from the loopback interface, "+
+			"e.g. through kubectl port-forward.",
+	).Get()
+
+	DebugProfilingMaxDuration = env.RegisterDurationVar(
+		"PILOT_DEBUG_PROFILING_MAX_DURATION",
+		time.Minute,
+		"The maximum duration of the CPU profiles and the execution traces captured through /debug/pprof.",
+	).Get()
+
+	EnableDebugAuth = env.RegisterBoolVar(
+		"PILOT_ENABLE_DEBUG_AUTH",
+		false,
+		"If enabled, the debug endpoints under /debug and /introspect only serve the callers authenticated by "+
+			"their client certificate, over mTLS, or by the Kubernetes token of their 'Authorization: Bearer <token>' "+
+			"header, reviewed through the TokenReview API. The requests from the loopback interface are always served.",
+	).Get()
+
+	DebugReaders = env.RegisterStringVar(
+		"PILOT_DEBUG_READERS",
+		"",
+		"Comma separated identities allowed to read the debug endpoints when PILOT_ENABLE_DEBUG_AUTH is set: "+
+			"Kubernetes user names, e.g. system:serviceaccount:istio-system:istioctl, or SPIFFE URIs. "+
+			"If empty, any authenticated caller can read them. The admins can read them too.",
+	).Get()
+
+	DebugAdmins = env.RegisterStringVar(
+		"PILOT_DEBUG_ADMINS",
+		"",
+		"Comma separated identities allowed to use the debug endpoints changing the state of Pilot, like pushing "+
+			"to all the proxies with /debug/adsz?push=true, when PILOT_ENABLE_DEBUG_AUTH is set. "+
+			"If empty, they are only served to the loopback interface.",
+	).Get()
+
+	WorkloadEntryAutoRegistration = env.RegisterBoolVar(
+		"PILOT_ENABLE_WORKLOAD_ENTRY_AUTOREGISTRATION",
+		false,
+		"If enabled, Pilot creates the WorkloadEntry of the proxies connecting with a reference to a WorkloadGroup "+
+			"in their AUTO_REGISTER_GROUP metadata and a client certificate of the namespace of the group.",
+	).Get()
+
+	WorkloadEntryCleanupGracePeriod = env.RegisterDurationVar(
+		"PILOT_WORKLOAD_ENTRY_GRACE_PERIOD",
+		10*time.Second,
+		"How long an automatically registered WorkloadEntry is kept after its proxy disconnected, "+
+			"so that a reconnecting proxy does not make its endpoint flap.",
+	).Get()
+
+	EnableStatus = env.RegisterBoolVar(
+		"PILOT_ENABLE_STATUS",
+		false,
+		"If enabled, Pilot will write in the status of the networking resources how many of the connected "+
+			"proxies have acked their latest version. Requires the config distribution tracking.",
+	).Get()
+
+	StatusUpdateInterval = env.RegisterDurationVar(
+		"PILOT_STATUS_UPDATE_INTERVAL",
+		5*time.Second,
+		"Interval at which each Pilot reports the config distribution to its proxies, and the statuses of the "+
+			"networking resources are updated. The config translation errors are emitted as events at the same interval.",
+	).Get()
+
+	EnableConfigErrorEvents = env.RegisterBoolVar(
+		"PILOT_ENABLE_CONFIG_ERROR_EVENTS",
+		true,
+		"If enabled, Pilot will emit a warning event on the config resources dropped or altered during their "+
+			"translation to Envoy config, e.g. a route with an invalid regex, in addition to the "+
+			"pilot_config_translation_errors metric.",
+	).Get()
+
 	EnableUnsafeRegex = env.RegisterBoolVar(
 		"PILOT_ENABLE_UNSAFE_REGEX",
 		false,
@@ -253,6 +351,113 @@ var (
 			"but the older, deprecated regex field. This should only be enabled to support "+
 			"legacy deployments that have not yet been migrated to the new safe regular expressions.",
 	)
+
+	LocalityLbPreferLocalCluster = env.RegisterBoolVar(
+		"PILOT_LOCALITY_LB_PREFER_LOCAL_CLUSTER",
+		true,
+		"If enabled, locality failover ranks the endpoints of other clusters and networks below all the endpoints "+
+			"of the proxy's own cluster: traffic goes to the local cluster first, then to the remote clusters of "+
+			"the same region, then anywhere.",
+	).Get()
+
+	MirrorRemoteServices = env.RegisterBoolVar(
+		"PILOT_MIRROR_REMOTE_SERVICES",
+		false,
+		"If enabled, pilot creates a selector-less Service in the local cluster for every service that only "+
+			"exists in remote clusters, so that its cluster.local name resolves for the local workloads.",
+	).Get()
+
+	MixerTCPReportInterval = env.RegisterDurationVar(
+		"PILOT_MIXER_TCP_REPORT_INTERVAL",
+		0,
+		"The interval at which the Mixer TCP filter reports connection metrics for long-lived connections. "+
+			"If unset, the proxy default of 10 seconds is used. Proxies can override it with the "+
+			"MIXER_TCP_REPORT_INTERVAL node metadata.",
+	).Get()
+
+	GatewayNumTrustedProxies = env.RegisterIntVar(
+		"PILOT_GATEWAY_NUM_TRUSTED_PROXIES",
+		0,
+		"The number of trusted proxies, e.g. cloud load balancers, in front of the gateways. The client address "+
+			"is then taken from the X-Forwarded-For header, that many hops before the gateway. Gateways can "+
+			"override it with the NUM_TRUSTED_PROXIES node metadata.",
+	).Get()
+
+	GatewayForwardClientCertDetails = env.RegisterStringVar(
+		"PILOT_GATEWAY_FORWARD_CLIENT_CERT",
+		"",
+		"How the gateways handle the X-Forwarded-Client-Cert header: SANITIZE, FORWARD_ONLY, APPEND_FORWARD, "+
+			"SANITIZE_SET or ALWAYS_FORWARD_ONLY. If unset, SANITIZE_SET is used. Gateways can override it with "+
+			"the FORWARD_CLIENT_CERT node metadata.",
+	).Get()
+
+	ManagedGatewayClass = env.RegisterStringVar(
+		"PILOT_MANAGED_GATEWAY_CLASS",
+		"",
+		"If set, Pilot provisions a Deployment, a Service and a HorizontalPodAutoscaler for every Gateway "+
+			"annotated with gateway.istio.io/class set to this class, and deletes them with the Gateway.",
+	).Get()
+
+	EnableACME = env.RegisterBoolVar(
+		"PILOT_ENABLE_ACME",
+		false,
+		"If enabled, Pilot provisions and renews via ACME the certificates of the HTTPS servers of the Gateways "+
+			"annotated with gateway.istio.io/acme, in the secrets named by their credentialName.",
+	).Get()
+
+	ACMEDirectoryURL = env.RegisterStringVar(
+		"PILOT_ACME_DIRECTORY_URL",
+		"https://acme-v02.api.letsencrypt.org/directory",
+		"The URL of the ACME (RFC 8555) directory issuing the certificates of the Gateways, when enabled.",
+	).Get()
+
+	ACMEEmail = env.RegisterStringVar(
+		"PILOT_ACME_EMAIL",
+		"",
+		"The contact email of the ACME account of Pilot, if any.",
+	).Get()
+
+	ACMEChallengeType = env.RegisterStringVar(
+		"PILOT_ACME_CHALLENGE",
+		"http-01",
+		"The type of the challenges validating the hosts of the ACME certificates: http-01, answered by the "+
+			"Gateways on port 80, or dns-01, answered by TXT records published as external-dns DNSEndpoint resources.",
+	).Get()
+
+	NackQuarantineThreshold = env.RegisterIntVar(
+		"PILOT_NACK_QUARANTINE_THRESHOLD",
+		3,
+		"Number of consecutive rejections of a resource type by a proxy after which the type is quarantined for "+
+			"the proxy: the pushes of the type triggered by config changes are skipped until the quarantine expires, "+
+			"instead of generating config the proxy keeps rejecting. 0 disables the quarantine.",
+	).Get()
+
+	NackBackoff = env.RegisterDurationVar(
+		"PILOT_NACK_BACKOFF",
+		10*time.Second,
+		"Duration of the first quarantine of a resource type repeatedly rejected by a proxy. It doubles each time "+
+			"the proxy rejects the type again, up to PILOT_NACK_MAX_BACKOFF, and is reset when the proxy accepts it.",
+	).Get()
+
+	NackMaxBackoff = env.RegisterDurationVar(
+		"PILOT_NACK_MAX_BACKOFF",
+		5*time.Minute,
+		"The maximum duration of the quarantine of a resource type repeatedly rejected by a proxy.",
+	).Get()
+
+	MaxProxyVersionSkew = env.RegisterIntVar(
+		"PILOT_MAX_PROXY_VERSION_SKEW",
+		2,
+		"Number of minor versions the proxies can lag behind Pilot. Pilot warns about the connections of older proxies "+
+			"and reports them as unsupported in /debug/version_skew, shown by istioctl experimental version-skew.",
+	).Get()
+
+	RejectUnsupportedProxies = env.RegisterBoolVar(
+		"PILOT_REJECT_UNSUPPORTED_PROXIES",
+		false,
+		"If enabled, Pilot refuses the connections of the proxies lagging more than PILOT_MAX_PROXY_VERSION_SKEW "+
+			"minor versions behind it, instead of only warning about them.",
+	).Get()
 )
 
 var (
@@ -48,9 +48,9 @@ type AuthorizationPolicies struct {
 	// Maps from namespace to the v1beta1 Authorization policies.
 	namespaceToV1beta1Policies map[string][]Config
 
-	// The name of the root namespace. Policy in the root namespace applies to workloads in all
+	// The names of the root namespaces. Policy in a root namespace applies to workloads in all
 	// namespaces. Only used for v1beta1 Authorization policy.
-	rootNamespace string
+	rootNamespaces []string
 }
 
 // GetAuthorizationPolicies gets the authorization policies in the mesh.
@@ -58,7 +58,7 @@ func GetAuthorizationPolicies(env *Environment) (*AuthorizationPolicies, error)
 	policy := &AuthorizationPolicies{
 		namespaceToV1alpha1Policies: map[string]*RolesAndBindings{},
 		namespaceToV1beta1Policies:  map[string][]Config{},
-		rootNamespace:               env.Mesh.GetRootNamespace(),
+		rootNamespaces:              env.RootNamespaces(),
 	}
 
 	rbacConfig := env.IstioConfigStore.ClusterRbacConfig()
@@ -162,19 +162,16 @@ func (policy *AuthorizationPolicies) ListServiceRoleBindings(ns string) map[stri
 	return rolesAndBindings.Bindings
 }
 
-// ListAuthorizationPolicies returns the AuthorizationPolicy for the workload in root namespace and the config namespace.
+// ListAuthorizationPolicies returns the AuthorizationPolicy for the workload in root namespaces and the config namespace.
 func (policy *AuthorizationPolicies) ListAuthorizationPolicies(configNamespace string,
 	workloadLabels labels.Collection) []Config {
 	if policy == nil {
 		return nil
 	}
 
-	var namespaces []string
-	if policy.rootNamespace != "" {
-		namespaces = append(namespaces, policy.rootNamespace)
-	}
-	// To prevent duplicate policies in case root namespace equals proxy's namespace.
-	if configNamespace != policy.rootNamespace {
+	namespaces := append([]string(nil), policy.rootNamespaces...)
+	// To prevent duplicate policies in case a root namespace equals proxy's namespace.
+	if !containsNamespace(policy.rootNamespaces, configNamespace) {
 		namespaces = append(namespaces, configNamespace)
 	}
 
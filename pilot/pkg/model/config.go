@@ -20,8 +20,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/mitchellh/copystructure"
-
 	"github.com/gogo/protobuf/proto"
 
 	mccpb "istio.io/api/mixer/v1/config/client"
@@ -209,6 +207,10 @@ type IstioConfigStore interface {
 	// EnvoyFilter lists the envoy filter configuration bound to the specified workload labels
 	EnvoyFilter(workloadLabels labels.Collection) *Config
 
+	// HTTPAPISpecByDestination selects Mixerclient HTTP API Specs
+	// associated with destination service instances.
+	HTTPAPISpecByDestination(instance *ServiceInstance) []Config
+
 	// QuotaSpecByDestination selects Mixerclient quota specifications
 	// associated with destination service instances.
 	QuotaSpecByDestination(instance *ServiceInstance) []Config
@@ -468,14 +470,10 @@ func MatchesDestHost(destinationHost string, meta ConfigMeta, svc *mccpb.IstioSe
 	return false
 }
 
-func recordSpecRef(refs map[string]bool, bindingNamespace string, quotas []*mccpb.QuotaSpecBinding_QuotaSpecReference) {
-	for _, spec := range quotas {
-		namespace := spec.Namespace
-		if namespace == "" {
-			namespace = bindingNamespace
-		}
-		refs[key(spec.Name, namespace)] = true
-	}
+// specReference is a reference of a binding to a HTTPAPISpec or a QuotaSpec.
+type specReference struct {
+	name      string
+	namespace string
 }
 
 // key creates a key from a reference's name and namespace.
@@ -483,15 +481,23 @@ func key(name, namespace string) string {
 	return name + "/" + namespace
 }
 
-// findQuotaSpecRefs returns a set of quotaSpec reference names
-func findQuotaSpecRefs(instance *ServiceInstance, bindings []Config) map[string]bool {
-	// Build the set of quota spec references bound to the service instance.
+// findSpecRefs returns the set of spec reference names bound to the service instance by the bindings,
+// whose services and spec references are returned by bound.
+func findSpecRefs(instance *ServiceInstance, bindings []Config,
+	bound func(binding Config) ([]*mccpb.IstioService, []specReference)) map[string]bool {
+	// Build the set of spec references bound to the service instance.
 	refs := make(map[string]bool)
 	for _, binding := range bindings {
-		b := binding.Spec.(*mccpb.QuotaSpecBinding)
-		for _, service := range b.Services {
+		services, specs := bound(binding)
+		for _, service := range services {
 			if MatchesDestHost(string(instance.Service.Hostname), binding.ConfigMeta, service) {
-				recordSpecRef(refs, binding.Namespace, b.QuotaSpecs)
+				for _, spec := range specs {
+					namespace := spec.namespace
+					if namespace == "" {
+						namespace = binding.Namespace
+					}
+					refs[key(spec.name, namespace)] = true
+				}
 				// found a binding that matches the instance.
 				break
 			}
@@ -501,28 +507,28 @@ func findQuotaSpecRefs(instance *ServiceInstance, bindings []Config) map[string]
 	return refs
 }
 
-// QuotaSpecByDestination selects Mixerclient quota specifications
-// associated with destination service instances.
-func (store *istioConfigStore) QuotaSpecByDestination(instance *ServiceInstance) []Config {
-	log.Debugf("QuotaSpecByDestination(%v)", instance)
-	bindings, err := store.List(schemas.QuotaSpecBinding.Type, NamespaceAll)
+// specsByDestination selects the specs bound to the service instance by the bindings, whose services
+// and spec references are returned by bound.
+func (store *istioConfigStore) specsByDestination(instance *ServiceInstance, bindingSchema, specSchema schema.Instance,
+	bound func(binding Config) ([]*mccpb.IstioService, []specReference)) []Config {
+	bindings, err := store.List(bindingSchema.Type, NamespaceAll)
 	if err != nil {
-		log.Warnf("Unable to fetch QuotaSpecBindings: %v", err)
+		log.Warnf("Unable to fetch %ss: %v", bindingSchema.VariableName, err)
 		return nil
 	}
 
-	log.Debugf("QuotaSpecByDestination bindings[%d] %v", len(bindings), bindings)
-	specs, err := store.List(schemas.QuotaSpec.Type, NamespaceAll)
+	log.Debugf("%sByDestination bindings[%d] %v", specSchema.VariableName, len(bindings), bindings)
+	specs, err := store.List(specSchema.Type, NamespaceAll)
 	if err != nil {
-		log.Warnf("Unable to fetch QuotaSpecs: %v", err)
+		log.Warnf("Unable to fetch %ss: %v", specSchema.VariableName, err)
 		return nil
 	}
 
-	log.Debugf("QuotaSpecByDestination specs[%d] %v", len(specs), specs)
+	log.Debugf("%sByDestination specs[%d] %v", specSchema.VariableName, len(specs), specs)
 
-	// Build the set of quota spec references bound to the service instance.
-	refs := findQuotaSpecRefs(instance, bindings)
-	log.Debugf("QuotaSpecByDestination refs:%v", refs)
+	// Build the set of spec references bound to the service instance.
+	refs := findSpecRefs(instance, bindings, bound)
+	log.Debugf("%sByDestination refs:%v", specSchema.VariableName, refs)
 
 	// Append any spec that is in the set of references.
 	// Remove matching specs from refs so refs only contains dangling references.
@@ -536,11 +542,41 @@ func (store *istioConfigStore) QuotaSpecByDestination(instance *ServiceInstance)
 	}
 
 	if len(refs) > 0 {
-		log.Warnf("Some matched QuotaSpecs were not found: %v", refs)
+		log.Warnf("Some matched %ss were not found: %v", specSchema.VariableName, refs)
 	}
 	return out
 }
 
+// HTTPAPISpecByDestination selects Mixerclient HTTP API Specs
+// associated with destination service instances.
+func (store *istioConfigStore) HTTPAPISpecByDestination(instance *ServiceInstance) []Config {
+	log.Debugf("HTTPAPISpecByDestination(%v)", instance)
+	return store.specsByDestination(instance, schemas.HTTPAPISpecBinding, schemas.HTTPAPISpec,
+		func(binding Config) ([]*mccpb.IstioService, []specReference) {
+			b := binding.Spec.(*mccpb.HTTPAPISpecBinding)
+			refs := make([]specReference, 0, len(b.ApiSpecs))
+			for _, spec := range b.ApiSpecs {
+				refs = append(refs, specReference{name: spec.Name, namespace: spec.Namespace})
+			}
+			return b.Services, refs
+		})
+}
+
+// QuotaSpecByDestination selects Mixerclient quota specifications
+// associated with destination service instances.
+func (store *istioConfigStore) QuotaSpecByDestination(instance *ServiceInstance) []Config {
+	log.Debugf("QuotaSpecByDestination(%v)", instance)
+	return store.specsByDestination(instance, schemas.QuotaSpecBinding, schemas.QuotaSpec,
+		func(binding Config) ([]*mccpb.IstioService, []specReference) {
+			b := binding.Spec.(*mccpb.QuotaSpecBinding)
+			refs := make([]specReference, 0, len(b.QuotaSpecs))
+			for _, spec := range b.QuotaSpecs {
+				refs = append(refs, specReference{name: spec.Name, namespace: spec.Namespace})
+			}
+			return b.Services, refs
+		})
+}
+
 func (store *istioConfigStore) ServiceRoles(namespace string) []Config {
 	roles, err := store.List(schemas.ServiceRole.Type, namespace)
 	if err != nil {
@@ -602,24 +638,59 @@ func (store *istioConfigStore) AuthorizationPolicies(namespace string) []Config
 	return authorizationPolicies
 }
 
+// SortHTTPAPISpec sorts a slice in a stable manner.
+func SortHTTPAPISpec(specs []Config) {
+	sortSpecs(specs, func(spec proto.Message) bool {
+		s, _ := spec.(*mccpb.HTTPAPISpec)
+		return s != nil
+	})
+}
+
 // SortQuotaSpec sorts a slice in a stable manner.
 func SortQuotaSpec(specs []Config) {
-	sort.Slice(specs, func(i, j int) bool {
+	sortSpecs(specs, func(spec proto.Message) bool {
+		s, _ := spec.(*mccpb.QuotaSpec)
+		return s != nil
+	})
+}
+
+// sortSpecs sorts the specs by key, after the specs of incompatible types, which are sorted last.
+func sortSpecs(specs []Config, compatible func(spec proto.Message) bool) {
+	sort.SliceStable(specs, func(i, j int) bool {
 		// protect against incompatible types
-		irule, _ := specs[i].Spec.(*mccpb.QuotaSpec)
-		jrule, _ := specs[j].Spec.(*mccpb.QuotaSpec)
-		return irule == nil || jrule == nil || (specs[i].Key() < specs[j].Key())
+		icompatible, jcompatible := compatible(specs[i].Spec), compatible(specs[j].Spec)
+		if icompatible != jcompatible {
+			return icompatible
+		}
+		return icompatible && specs[i].Key() < specs[j].Key()
 	})
 }
 
+// DeepCopy creates a clone of Config. The spec is copied by the merge functions generated for the config protos
+// rather than by reflection, since copying the configs is one of the main costs of building the PushContext.
 func (config Config) DeepCopy() Config {
-	copied, err := copystructure.Copy(config)
-	if err != nil {
-		// There are 2 locations where errors are generated in copystructure.Copy:
-		//  * The reflection walk over the structure fails, which should never happen
-		//  * A configurable copy function returns an error. This is only used for copying times, which never returns an error.
-		// Therefore, this should never happen
-		panic(err)
+	out := Config{ConfigMeta: config.ConfigMeta.DeepCopy()}
+	if config.Spec != nil {
+		out.Spec = proto.Clone(config.Spec)
 	}
-	return copied.(Config)
+	return out
+}
+
+// DeepCopy creates a clone of ConfigMeta.
+func (meta ConfigMeta) DeepCopy() ConfigMeta {
+	out := meta
+	out.Labels = copyStringMap(meta.Labels)
+	out.Annotations = copyStringMap(meta.Annotations)
+	return out
+}
+
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
 }
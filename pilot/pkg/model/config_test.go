@@ -24,6 +24,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 
+	mpb "istio.io/api/mixer/v1"
 	mccpb "istio.io/api/mixer/v1/config/client"
 	networking "istio.io/api/networking/v1alpha3"
 	rbacproto "istio.io/api/rbac/v1alpha1"
@@ -605,6 +606,107 @@ func TestIstioConfigStore_QuotaSpecByDestination(t *testing.T) {
 	}
 }
 
+func TestIstioConfigStore_HTTPAPISpecByDestination(t *testing.T) {
+	ns := "ns1"
+	l := &fakeStore{
+		cfg: map[string][]model.Config{
+			schemas.HTTPAPISpecBinding.Type: {
+				{
+					ConfigMeta: model.ConfigMeta{
+						Namespace: ns,
+						Domain:    "cluster.local",
+					},
+					Spec: &mccpb.HTTPAPISpecBinding{
+						Services: []*mccpb.IstioService{
+							{
+								Name:      "a",
+								Namespace: ns,
+							},
+						},
+						ApiSpecs: []*mccpb.HTTPAPISpecReference{
+							{
+								Name: "users",
+							},
+							{
+								Name: "does-not-exist",
+							},
+						},
+					},
+				},
+			},
+			schemas.HTTPAPISpec.Type: {
+				{
+					ConfigMeta: model.ConfigMeta{
+						Name:      "users",
+						Namespace: ns,
+					},
+					Spec: &mccpb.HTTPAPISpec{
+						Patterns: []*mccpb.HTTPAPISpecPattern{
+							{
+								Attributes: &mpb.Attributes{
+									Attributes: map[string]*mpb.Attributes_AttributeValue{
+										"api.operation": {
+											Value: &mpb.Attributes_AttributeValue_StringValue{StringValue: "GetUser"},
+										},
+									},
+								},
+								HttpMethod: "GET",
+								Pattern:    &mccpb.HTTPAPISpecPattern_UriTemplate{UriTemplate: "/users/{id}"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ii := model.MakeIstioStore(l)
+	cfgs := ii.HTTPAPISpecByDestination(&model.ServiceInstance{
+		Service: &model.Service{
+			Hostname: host.Name("a." + ns + ".svc.cluster.local"),
+		},
+	})
+
+	if len(cfgs) != 1 {
+		t.Fatalf("did not find 1 matched HTTPAPISpec")
+	}
+}
+
+func TestSortSpecs(t *testing.T) {
+	newSpec := func(name string, spec proto.Message) model.Config {
+		return model.Config{ConfigMeta: model.ConfigMeta{Name: name, Namespace: "default"}, Spec: spec}
+	}
+	names := func(specs []model.Config) []string {
+		out := make([]string, 0, len(specs))
+		for _, spec := range specs {
+			out = append(out, spec.Name)
+		}
+		return out
+	}
+
+	httpAPISpecs := []model.Config{
+		newSpec("c", &mccpb.HTTPAPISpec{}),
+		newSpec("invalid1", &mccpb.QuotaSpec{}),
+		newSpec("a", &mccpb.HTTPAPISpec{}),
+		newSpec("invalid2", nil),
+		newSpec("b", &mccpb.HTTPAPISpec{}),
+	}
+	model.SortHTTPAPISpec(httpAPISpecs)
+	if got, want := names(httpAPISpecs), []string{"a", "b", "c", "invalid1", "invalid2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortHTTPAPISpec got %v, want %v", got, want)
+	}
+
+	quotaSpecs := []model.Config{
+		newSpec("invalid1", &mccpb.HTTPAPISpec{}),
+		newSpec("b", &mccpb.QuotaSpec{}),
+		newSpec("invalid2", nil),
+		newSpec("a", &mccpb.QuotaSpec{}),
+	}
+	model.SortQuotaSpec(quotaSpecs)
+	if got, want := names(quotaSpecs), []string{"a", "b", "invalid1", "invalid2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SortQuotaSpec got %v, want %v", got, want)
+	}
+}
+
 func TestMatchesDestHost(t *testing.T) {
 	for _, tst := range []struct {
 		destinationHost string
@@ -807,18 +909,52 @@ func TestDeepCopy(t *testing.T) {
 		ConfigMeta: model.ConfigMeta{
 			Name:              "name1",
 			Namespace:         "zzz",
+			Labels:            map[string]string{"app": "test"},
 			CreationTimestamp: time.Now(),
 		},
-		Spec: &networking.Gateway{},
+		Spec: &networking.Gateway{Selector: map[string]string{"istio": "ingressgateway"}},
 	}
 
 	copied := cfg.DeepCopy()
 
-	if !(cfg.Spec.String() == copied.Spec.String() &&
-		cfg.Namespace == copied.Namespace &&
-		cfg.Name == copied.Name &&
-		cfg.CreationTimestamp == copied.CreationTimestamp) {
+	if !reflect.DeepEqual(cfg, copied) {
 		t.Fatalf("cloned config is not identical")
 	}
 
+	copied.Labels["app"] = "changed"
+	copied.Spec.(*networking.Gateway).Selector["istio"] = "changed"
+	if cfg.Labels["app"] != "test" || cfg.Spec.(*networking.Gateway).Selector["istio"] != "ingressgateway" {
+		t.Fatalf("changing the clone changed the original config: %v", cfg)
+	}
+
+	if empty := (model.Config{}).DeepCopy(); empty.Spec != nil || empty.Labels != nil {
+		t.Fatalf("cloned empty config is not empty: %v", empty)
+	}
+}
+
+func BenchmarkDeepCopy(b *testing.B) {
+	cfg := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.VirtualService.Type,
+			Name:              "reviews",
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "reviews"},
+			CreationTimestamp: time.Now(),
+		},
+		Spec: &networking.VirtualService{
+			Hosts:    []string{"reviews"},
+			Gateways: []string{"mesh"},
+			Http: []*networking.HTTPRoute{{
+				Match: []*networking.HTTPMatchRequest{{Uri: &networking.StringMatch{
+					MatchType: &networking.StringMatch_Prefix{Prefix: "/v2"}}}},
+				Route: []*networking.HTTPRouteDestination{
+					{Destination: &networking.Destination{Host: "reviews", Subset: "v1"}, Weight: 50},
+					{Destination: &networking.Destination{Host: "reviews", Subset: "v2"}, Weight: 50},
+				},
+			}},
+		},
+	}
+	for n := 0; n < b.N; n++ {
+		_ = cfg.DeepCopy()
+	}
 }
@@ -30,6 +30,7 @@ import (
 	meshconfig "istio.io/api/mesh/v1alpha1"
 
 	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
 )
 
 // Environment provides an aggregate environmental API for Pilot
@@ -43,6 +44,9 @@ type Environment struct {
 	// Mesh is the mesh config (to be merged into the config store)
 	Mesh *meshconfig.MeshConfig
 
+	// MeshExtensions are the mesh config fields not part of the MeshConfig API yet.
+	MeshExtensions *mesh.Extensions
+
 	// PushContext holds informations during push generation. It is reset on config change, at the beginning
 	// of the pushAll. It will hold all errors and stats and possibly caches needed during the entire cache computation.
 	// DO NOT USE EXCEPT FOR TESTS AND HANDLING OF NEW CONNECTIONS.
@@ -57,6 +61,17 @@ type Environment struct {
 	// routable L3 network. A single routable L3 network can have one or more
 	// service registries.
 	MeshNetworks *meshconfig.MeshNetworks
+
+	// Namespaces provides the labels of the namespaces, if known by the registries.
+	Namespaces NamespaceDiscovery
+}
+
+// NamespaceLabels returns the labels of the namespace, or nil if unknown.
+func (e *Environment) NamespaceLabels(namespace string) labels.Instance {
+	if e.Namespaces == nil {
+		return nil
+	}
+	return e.Namespaces.NamespaceLabels(namespace)
 }
 
 // Proxy contains information about an specific instance of a proxy (envoy sidecar, gateway,
@@ -114,6 +129,10 @@ type Proxy struct {
 
 	// Istio version associated with the Proxy
 	IstioVersion *IstioVersion
+
+	// Envoy version of the Proxy, parsed from the build version it reports.
+	// Nil when the Proxy does not report a parsable build version.
+	EnvoyVersion *IstioVersion
 }
 
 var (
@@ -210,6 +229,10 @@ type NodeMetadata struct {
 	// traffic interception mode at the proxy
 	InterceptionMode TrafficInterceptionMode `json:"INTERCEPTION_MODE,omitempty"`
 
+	// Generator is the generator of the xDS configuration of the client, if it is not an Envoy
+	// proxy, such as "grpc" for a gRPC application using its built-in xDS client.
+	Generator string `json:"GENERATOR,omitempty"`
+
 	// ServiceAccount specifies the service account which is running the workload.
 	ServiceAccount string `json:"SERVICE_ACCOUNT,omitempty"`
 
@@ -244,6 +267,10 @@ type NodeMetadata struct {
 	// WorkloadName specifies the name of the workload represented by this node.
 	WorkloadName string `json:"WORKLOAD_NAME,omitempty"`
 
+	// AutoRegisterGroup is the name of the WorkloadGroup of the namespace of the proxy that the
+	// WorkloadEntry of the workload is created from, when it connects to Pilot.
+	AutoRegisterGroup string `json:"AUTO_REGISTER_GROUP,omitempty"`
+
 	// Owner specifies the workload owner (opaque string). Typically, this is the owning controller of
 	// of the workload instance (ex: k8s deployment for a k8s pod).
 	Owner string `json:"OWNER,omitempty"`
@@ -262,10 +289,49 @@ type NodeMetadata struct {
 	PolicyCheckBaseRetryWaitTime string `json:"policy.istio.io/checkBaseRetryWaitTime,omitempty"`
 	PolicyCheckMaxRetryWaitTime  string `json:"policy.istio.io/checkMaxRetryWaitTime,omitempty"`
 
+	// MixerTCPReportInterval is the interval at which the Mixer TCP filter reports metrics for
+	// open connections, overriding the Pilot default.
+	MixerTCPReportInterval string `json:"MIXER_TCP_REPORT_INTERVAL,omitempty"`
+
+	// NumTrustedProxies is the number of trusted proxies in front of a gateway, overriding the
+	// Pilot default.
+	NumTrustedProxies string `json:"NUM_TRUSTED_PROXIES,omitempty"`
+
+	// ForwardClientCertDetails is how a gateway handles the X-Forwarded-Client-Cert header,
+	// overriding the Pilot default.
+	ForwardClientCertDetails string `json:"FORWARD_CLIENT_CERT,omitempty"`
+
+	// TraceSampling overrides the percentage of requests traced by the proxy.
+	TraceSampling string `json:"sidecar.istio.io/traceSampling,omitempty"`
+
 	StatsInclusionPrefixes string `json:"sidecar.istio.io/statsInclusionPrefixes,omitempty"`
 	StatsInclusionRegexps  string `json:"sidecar.istio.io/statsInclusionRegexps,omitempty"`
 	StatsInclusionSuffixes string `json:"sidecar.istio.io/statsInclusionSuffixes,omitempty"`
 
+	// ExtraStatTags are the names of the extra dimensions of the standard metrics reported by the stats filter,
+	// separated by commas. They are extracted as tags from the names of the stats of the proxy.
+	ExtraStatTags string `json:"EXTRA_STAT_TAGS,omitempty"`
+
+	// MaxDownstreamConnections limits the number of connections accepted by all the listeners of the proxy.
+	MaxDownstreamConnections string `json:"sidecar.istio.io/maxDownstreamConnections,omitempty"`
+	// MaxInboundConnections limits the number of connections accepted by the inbound listener of the sidecar.
+	MaxInboundConnections string `json:"sidecar.istio.io/maxInboundConnections,omitempty"`
+	// MaxOutboundConnections limits the number of connections accepted by the outbound listener of the sidecar.
+	MaxOutboundConnections string `json:"sidecar.istio.io/maxOutboundConnections,omitempty"`
+
+	// OverloadMaxHeapSize is the maximum heap size of the proxy, enabling its overload manager.
+	OverloadMaxHeapSize string `json:"sidecar.istio.io/overloadMaxHeapSize,omitempty"`
+	// OverloadShrinkHeapThreshold is the percentage of the maximum heap size at which the proxy
+	// returns its free memory to the system.
+	OverloadShrinkHeapThreshold string `json:"sidecar.istio.io/overloadShrinkHeapThreshold,omitempty"`
+	// OverloadStopAcceptingConnectionsThreshold is the percentage of the maximum heap size at which
+	// the proxy stops accepting new connections.
+	OverloadStopAcceptingConnectionsThreshold string `json:"sidecar.istio.io/overloadStopAcceptingConnectionsThreshold,omitempty"`
+
+	// HTTPDrainTimeout is the time, in duration format (30s), the HTTP/2 connections of a listener that
+	// is drained are given to complete their requests between the first GOAWAY and the final one.
+	HTTPDrainTimeout string `json:"sidecar.istio.io/httpDrainTimeout,omitempty"`
+
 	// TLSServerCertChain is the absolute path to server cert-chain file
 	TLSServerCertChain string `json:"TLS_SERVER_CERT_CHAIN,omitempty"`
 	// TLSServerKey is the absolute path to server private key file
@@ -350,6 +416,11 @@ var (
 	MaxIstioVersion = &IstioVersion{Major: 65535, Minor: 65535, Patch: 65535}
 )
 
+// String returns the version formatted as major.minor.patch
+func (pversion *IstioVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", pversion.Major, pversion.Minor, pversion.Patch)
+}
+
 // Compare returns -1/0/1 if version is less than, equal or greater than inv
 // To compare only on major, call this function with { X, -1, -1}.
 // to compare only on major & minor, call this function with {X, Y, -1}.
@@ -622,6 +693,16 @@ func ParseIstioVersion(ver string) *IstioVersion {
 	return &IstioVersion{Major: major, Minor: minor, Patch: patch}
 }
 
+// ParseEnvoyVersion parses the build version reported by Envoy in its node, formatted as
+// <revision>/<version>/<status>/<build type>/<ssl>, and returns nil when the version is not parsable.
+func ParseEnvoyVersion(buildVersion string) *IstioVersion {
+	parts := strings.Split(buildVersion, "/")
+	if len(parts) < 2 || !istioVersionRegexp.MatchString(parts[1]) {
+		return nil
+	}
+	return ParseIstioVersion(parts[1])
+}
+
 // GetOrDefault returns either the value, or the default if the value is empty. Useful when retrieving node metadata fields.
 func GetOrDefault(s string, def string) string {
 	if len(s) > 0 {
@@ -742,3 +823,12 @@ func (node *Proxy) GetInterceptionMode() TrafficInterceptionMode {
 
 	return InterceptionRedirect
 }
+
+// GRPCGenerator is the generator of the gRPC applications using the built-in xDS client of gRPC,
+// without a sidecar.
+const GRPCGenerator = "grpc"
+
+// IsProxylessGRPC returns true if the client is a gRPC application rather than an Envoy proxy.
+func (node *Proxy) IsProxylessGRPC() bool {
+	return node != nil && node.Metadata != nil && node.Metadata.Generator == GRPCGenerator
+}
@@ -404,3 +404,39 @@ func Test_parseIstioVersion(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseEnvoyVersion(t *testing.T) {
+	tests := []struct {
+		name         string
+		buildVersion string
+		want         *model.IstioVersion
+	}{
+		{
+			name:         "release",
+			buildVersion: "e349fb6139e4b7a59a9a359be0ea45dd61e589c5/1.12.2/Clean/RELEASE/BoringSSL",
+			want:         &model.IstioVersion{Major: 1, Minor: 12, Patch: 2},
+		},
+		{
+			name:         "dev",
+			buildVersion: "73f240a29bece92a8882a36893ccce07b4a54664/1.13.0-dev/Clean/RELEASE/BoringSSL",
+			want:         &model.IstioVersion{Major: 1, Minor: 13, Patch: 0},
+		},
+		{
+			name:         "empty",
+			buildVersion: "",
+			want:         nil,
+		},
+		{
+			name:         "junk",
+			buildVersion: "e349fb6139e4b7a59a9a359be0ea45dd61e589c5/junk/Clean/RELEASE/BoringSSL",
+			want:         nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.ParseEnvoyVersion(tt.buildVersion); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseEnvoyVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
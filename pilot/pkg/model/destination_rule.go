@@ -47,6 +47,14 @@ func (ps *PushContext) combineSingleDestinationRule(
 						subset.Name, string(resolvedHost)))
 			}
 		}
+		// The TLS mode of the incoming rule is ignored if the combined rule already has a top level policy.
+		if combinedTLS, tls := combinedRule.TrafficPolicy.GetTls(), rule.TrafficPolicy.GetTls(); combinedTLS != nil && tls != nil &&
+			combinedTLS.Mode != tls.Mode {
+			ps.Add(ConflictingDestinationRuleTLSModes, string(resolvedHost), nil,
+				fmt.Sprintf("TLS mode %s of destination rule %s/%s conflicts with TLS mode %s of destination rule %s/%s for %s",
+					tls.Mode, destRuleConfig.Namespace, destRuleConfig.Name,
+					combinedTLS.Mode, mdr.config.Namespace, mdr.config.Name, string(resolvedHost)))
+		}
 		// If there is no top level policy and the incoming rule has top level
 		// traffic policy, use the one from the incoming rule.
 		if combinedRule.TrafficPolicy == nil && rule.TrafficPolicy != nil {
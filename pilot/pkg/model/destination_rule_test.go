@@ -0,0 +1,62 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func newTLSDestinationRule(name, host string, mode networking.TLSSettings_TLSmode) Config {
+	return Config{
+		ConfigMeta: ConfigMeta{
+			Type:      schemas.DestinationRule.Type,
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: &networking.DestinationRule{
+			Host:          host,
+			TrafficPolicy: &networking.TrafficPolicy{Tls: &networking.TLSSettings{Mode: mode}},
+		},
+	}
+}
+
+func TestConflictingDestinationRuleTLSModes(t *testing.T) {
+	ps := NewPushContext()
+	ps.SetDestinationRules([]Config{
+		newTLSDestinationRule("reviews-mtls", "reviews.default.svc.cluster.local", networking.TLSSettings_ISTIO_MUTUAL),
+		newTLSDestinationRule("reviews-disable", "reviews.default.svc.cluster.local", networking.TLSSettings_DISABLE),
+		newTLSDestinationRule("ratings-mtls", "ratings.default.svc.cluster.local", networking.TLSSettings_ISTIO_MUTUAL),
+		newTLSDestinationRule("ratings-mtls-2", "ratings.default.svc.cluster.local", networking.TLSSettings_ISTIO_MUTUAL),
+	})
+
+	conflicts := ps.ProxyStatus[ConflictingDestinationRuleTLSModes.Name()]
+	if len(conflicts) != 1 {
+		t.Fatalf("got conflicts %v, want only the one of reviews", conflicts)
+	}
+	if _, f := conflicts["reviews.default.svc.cluster.local"]; !f {
+		t.Errorf("got conflicts %v, want the one of reviews", conflicts)
+	}
+
+	// The traffic policy of the oldest destination rule is kept, the rules created at the same time being
+	// ordered by name.
+	cfg := ps.namespaceLocalDestRules["default"].destRule["reviews.default.svc.cluster.local"].config
+	if mode := cfg.Spec.(*networking.DestinationRule).TrafficPolicy.Tls.Mode; mode != networking.TLSSettings_DISABLE {
+		t.Errorf("got TLS mode %v, want DISABLE", mode)
+	}
+}
@@ -31,7 +31,7 @@ import (
 // TODO: do we need a `func (m *MergedGateway) MergeInto(gateway *networking.Gateway)`?
 type MergedGateway struct {
 	// maps from physical port to virtual servers
-	Servers map[uint32][]*networking.Server
+	Servers map[ServerPort][]*networking.Server
 
 	// maps from server to the owning gateway name
 	// Needed to select the set of virtual services that apply to a port
@@ -46,6 +46,94 @@ type MergedGateway struct {
 	// Inverse of ServersByRouteName. Returning this as part of merge result allows to keep route name generation logic
 	// encapsulated within the model and, as a side effect, to avoid generating route names twice.
 	RouteNamesByServer map[*networking.Server]string
+
+	// maps from the servers with tls.httpsRedirect set to the redirect they answer with
+	HTTPSRedirects map[*networking.Server]HTTPSRedirect
+
+	// maps from the tokens of the pending ACME HTTP-01 challenges of the gateways to their key authorization
+	ACMEChallenges map[string]string
+
+	// maps from the names of the gateways restricting the namespaces of their virtual services to their policy
+	BindingPolicies map[string]*gateway.BindingPolicy
+
+	// conflicts are the hosts declared on the same port by the servers of different gateways
+	conflicts []gatewayServerConflict
+}
+
+// ServerPort is the physical port of a set of merged servers: the servers with the same port number
+// and bind address share a listener, while the servers binding different addresses of the gateway
+// can reuse the same port number.
+type ServerPort struct {
+	// Number is the port number of the servers.
+	Number uint32
+	// Bind is the address the servers bind to, the wildcard address of the proxy if empty.
+	Bind string
+}
+
+// serverPort returns the physical port of the server.
+func serverPort(s *networking.Server) ServerPort {
+	return ServerPort{Number: s.Port.Number, Bind: s.Bind}
+}
+
+const (
+	// HTTPSRedirectPortAnnotation is the gateway annotation overriding the port the servers of the gateway
+	// with tls.httpsRedirect redirect the requests to.
+	HTTPSRedirectPortAnnotation = "gateway.istio.io/https-redirect-port"
+	// HTTPSRedirectCodeAnnotation is the gateway annotation overriding the status code of the redirects
+	// of the servers of the gateway with tls.httpsRedirect.
+	HTTPSRedirectCodeAnnotation = "gateway.istio.io/https-redirect-code"
+
+	// DefaultHTTPSRedirectPort is the port the requests are redirected to by default.
+	DefaultHTTPSRedirectPort = 443
+	// DefaultHTTPSRedirectCode is the status code of the redirects by default.
+	DefaultHTTPSRedirectCode = 301
+)
+
+// ACMEChallengeAnnotationPrefix is the prefix of the gateway annotations holding the key authorizations
+// of the pending ACME HTTP-01 challenges for the hosts of the gateway, answered by its HTTP servers.
+const ACMEChallengeAnnotationPrefix = "acme.gateway.istio.io/"
+
+// HTTPSRedirect is the redirect of the plain text requests of a server with tls.httpsRedirect to HTTPS.
+type HTTPSRedirect struct {
+	// Port is the port the requests are redirected to.
+	Port uint32
+	// Code is the status code of the redirect, one of 301, 302, 303, 307 or 308.
+	Code uint32
+}
+
+// validHTTPSRedirectCodes are the redirect status codes supported by Envoy.
+var validHTTPSRedirectCodes = map[uint32]bool{301: true, 302: true, 303: true, 307: true, 308: true}
+
+// httpsRedirectForGateway returns the redirect of the servers of the gateway with tls.httpsRedirect,
+// ignoring the invalid annotations.
+func httpsRedirectForGateway(gatewayConfig Config) HTTPSRedirect {
+	redirect := HTTPSRedirect{Port: DefaultHTTPSRedirectPort, Code: DefaultHTTPSRedirectCode}
+	if v, ok := gatewayConfig.Annotations[HTTPSRedirectPortAnnotation]; ok {
+		port, err := strconv.ParseUint(v, 10, 16)
+		if err != nil || port == 0 {
+			log.Warnf("ignoring invalid %s annotation %q of gateway %s/%s",
+				HTTPSRedirectPortAnnotation, v, gatewayConfig.Namespace, gatewayConfig.Name)
+		} else {
+			redirect.Port = uint32(port)
+		}
+	}
+	if v, ok := gatewayConfig.Annotations[HTTPSRedirectCodeAnnotation]; ok {
+		code, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || !validHTTPSRedirectCodes[uint32(code)] {
+			log.Warnf("ignoring invalid %s annotation %q of gateway %s/%s",
+				HTTPSRedirectCodeAnnotation, v, gatewayConfig.Namespace, gatewayConfig.Name)
+		} else {
+			redirect.Code = uint32(code)
+		}
+	}
+	return redirect
+}
+
+// gatewayServerConflict is a host declared on the same port by the servers of two gateways.
+type gatewayServerConflict struct {
+	port     uint32
+	host     string
+	gateways [2]string
 }
 
 var (
@@ -72,14 +160,19 @@ func recordRejectedConfig(gatewayName string) {
 // If servers with different protocols attempt to listen on the same port, one of the protocols will be chosen at random.
 func MergeGateways(gateways ...Config) *MergedGateway {
 	names := make(map[string]bool, len(gateways))
-	gatewayPorts := make(map[uint32]bool)
-	servers := make(map[uint32][]*networking.Server)
-	tlsServers := make(map[uint32][]*networking.Server)
-	plaintextServers := make(map[uint32][]*networking.Server)
+	gatewayPorts := make(map[ServerPort]bool)
+	servers := make(map[ServerPort][]*networking.Server)
+	tlsServers := make(map[ServerPort][]*networking.Server)
+	plaintextServers := make(map[ServerPort][]*networking.Server)
 	serversByRouteName := make(map[string][]*networking.Server)
 	routeNamesByServer := make(map[*networking.Server]string)
 	gatewayNameForServer := make(map[*networking.Server]string)
-	tlsHostsByPort := map[uint32]map[string]struct{}{} // port -> host -> exists
+	httpsRedirects := make(map[*networking.Server]HTTPSRedirect)
+	acmeChallenges := make(map[string]string)
+	bindingPolicies := make(map[string]*gateway.BindingPolicy)
+	tlsHostsByPort := map[ServerPort]map[string]struct{}{}   // port -> host -> exists
+	gatewaysByPortHost := map[ServerPort]map[string]string{} // port -> host -> gateway name
+	var conflicts []gatewayServerConflict
 
 	log.Debugf("MergeGateways: merging %d gateways", len(gateways))
 	for _, gatewayConfig := range gateways {
@@ -88,26 +181,45 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 
 		gatewayCfg := gatewayConfig.Spec.(*networking.Gateway)
 		log.Debugf("MergeGateways: merging gateway %q into %v:\n%v", gatewayName, names, gatewayCfg)
+		redirect := httpsRedirectForGateway(gatewayConfig)
+		policy, err := gateway.NewBindingPolicy(gatewayConfig.Namespace, gatewayConfig.Annotations)
+		if err != nil {
+			log.Warnf("gateway %s: %v", gatewayName, err)
+		}
+		if policy != nil {
+			bindingPolicies[gatewayName] = policy
+		}
+		for k, v := range gatewayConfig.Annotations {
+			// the key authorization of a challenge starts with its token
+			if strings.HasPrefix(k, ACMEChallengeAnnotationPrefix) && strings.Contains(v, ".") {
+				acmeChallenges[v[:strings.Index(v, ".")]] = v
+			}
+		}
 		for _, s := range gatewayCfg.Servers {
 			sanitizeServerHostNamespace(s, gatewayConfig.Namespace)
 			gatewayNameForServer[s] = gatewayName
+			if s.Tls != nil && s.Tls.HttpsRedirect {
+				httpsRedirects[s] = redirect
+			}
 			log.Debugf("MergeGateways: gateway %q processing server %v", gatewayName, s.Hosts)
 			p := protocol.Parse(s.Port.Protocol)
+			sp := serverPort(s)
+			conflicts = append(conflicts, checkServerConflicts(s, gatewayName, gatewaysByPortHost)...)
 
 			if s.Tls != nil {
 				// Envoy will reject config that has multiple filter chain matches with the same matching rules
 				// To avoid this, we need to make sure we don't have duplicated hosts, which will become
 				// SNI filter chain matches
-				if tlsHostsByPort[s.Port.Number] == nil {
-					tlsHostsByPort[s.Port.Number] = map[string]struct{}{}
+				if tlsHostsByPort[sp] == nil {
+					tlsHostsByPort[sp] = map[string]struct{}{}
 				}
-				if duplicateHosts := checkDuplicates(s.Hosts, tlsHostsByPort[s.Port.Number]); len(duplicateHosts) != 0 {
+				if duplicateHosts := checkDuplicates(s.Hosts, tlsHostsByPort[sp]); len(duplicateHosts) != 0 {
 					log.Debugf("skipping server on gateway %s, duplicate host names: %v", gatewayName, duplicateHosts)
 					recordRejectedConfig(gatewayName)
 					continue
 				}
 			}
-			if gatewayPorts[s.Port.Number] {
+			if gatewayPorts[sp] {
 				// We have two servers on the same port. Should we merge?
 				// 1. Yes if both servers are plain text and HTTP
 				// 2. Yes if both servers are using TLS
@@ -115,7 +227,7 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 				//    for each server (as each server ends up as a separate http connection manager due to filter chain match
 				// 3. No for everything else.
 
-				if server, exists := plaintextServers[s.Port.Number]; exists {
+				if server, exists := plaintextServers[sp]; exists {
 					currentProto := protocol.Parse(server[0].Port.Protocol)
 					if currentProto != p || !p.IsHTTP() {
 						log.Debugf("skipping server on gateway %s port %s.%d.%s: conflict with existing server %s.%d.%s",
@@ -166,14 +278,14 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 						continue
 					}
 
-					tlsServers[s.Port.Number] = append(tlsServers[s.Port.Number], s)
+					tlsServers[sp] = append(tlsServers[sp], s)
 				}
 			} else {
-				gatewayPorts[s.Port.Number] = true
+				gatewayPorts[sp] = true
 				if gateway.IsTLSServer(s) {
-					tlsServers[s.Port.Number] = []*networking.Server{s}
+					tlsServers[sp] = []*networking.Server{s}
 				} else {
-					plaintextServers[s.Port.Number] = []*networking.Server{s}
+					plaintextServers[sp] = []*networking.Server{s}
 				}
 
 				if gateway.IsHTTPServer(s) {
@@ -199,7 +311,33 @@ func MergeGateways(gateways ...Config) *MergedGateway {
 		GatewayNameForServer: gatewayNameForServer,
 		ServersByRouteName:   serversByRouteName,
 		RouteNamesByServer:   routeNamesByServer,
+		HTTPSRedirects:       httpsRedirects,
+		ACMEChallenges:       acmeChallenges,
+		BindingPolicies:      bindingPolicies,
+		conflicts:            conflicts,
+	}
+}
+
+// checkServerConflicts returns the hosts of the server already declared on its port by another gateway.
+func checkServerConflicts(s *networking.Server, gatewayName string, gatewaysByPortHost map[ServerPort]map[string]string) []gatewayServerConflict {
+	sp := serverPort(s)
+	if gatewaysByPortHost[sp] == nil {
+		gatewaysByPortHost[sp] = map[string]string{}
 	}
+	var conflicts []gatewayServerConflict
+	for _, h := range s.Hosts {
+		// The namespace of a host restricts the virtual services bound to it, not the traffic it matches.
+		if i := strings.Index(h, "/"); i >= 0 {
+			h = h[i+1:]
+		}
+		other, exists := gatewaysByPortHost[sp][h]
+		if !exists {
+			gatewaysByPortHost[sp][h] = gatewayName
+		} else if other != gatewayName {
+			conflicts = append(conflicts, gatewayServerConflict{port: s.Port.Number, host: h, gateways: [2]string{other, gatewayName}})
+		}
+	}
+	return conflicts
 }
 
 // checkDuplicates returns all of the hosts provided that are already known
@@ -223,7 +361,8 @@ func checkDuplicates(hosts []string, knownHosts map[string]struct{}) []string {
 // gatewayRDSRouteName generates the RDS route config name for gateway's servers.
 // Unlike sidecars where the RDS route name is the listener port number, gateways have a different
 // structure for RDS.
-// HTTP servers have route name set to http.<portNumber>.
+// HTTP servers have route name set to http.<portNumber>, or http.<portNumber>.<bind> if they bind a
+// specific address of the gateway.
 //   Multiple HTTP servers can exist on the same port and the code will combine all of them into
 //   one single RDS payload for http.<portNumber>
 // HTTPS servers with TLS termination (i.e. envoy decoding the content, and making outbound http calls to backends)
@@ -249,6 +388,9 @@ func checkDuplicates(hosts []string, knownHosts map[string]struct{}) []string {
 func gatewayRDSRouteName(server *networking.Server, cfg Config) string {
 	p := protocol.Parse(server.Port.Protocol)
 	if p.IsHTTP() {
+		if server.Bind != "" {
+			return fmt.Sprintf("http.%d.%s", server.Port.Number, server.Bind)
+		}
 		return fmt.Sprintf("http.%d", server.Port.Number)
 	}
 
@@ -265,8 +407,9 @@ func gatewayRDSRouteName(server *networking.Server, cfg Config) string {
 func ParseGatewayRDSRouteName(name string) (portNumber int, portName, gatewayName string) {
 	parts := strings.Split(name, ".")
 	if strings.HasPrefix(name, "http.") {
-		// this is a http gateway. Parse port number and return empty string for rest
-		if len(parts) == 2 {
+		// this is a http gateway. Parse port number and return empty string for rest, including the
+		// bind address if any
+		if len(parts) >= 2 {
 			portNumber, _ = strconv.Atoi(parts[1])
 		}
 	} else if strings.HasPrefix(name, "https.") {
@@ -26,6 +26,10 @@ func TestMergeGateways(t *testing.T) {
 	configGw2 := makeConfig("foo2", "not-default", "*", "name2", "http", 7, "ingressgateway2")
 	configGw3 := makeConfig("foo3", "not-default", "*", "name3", "http", 8, "ingressgateway")
 	configGw4 := makeConfig("foo4", "not-default-2", "*", "name4", "tcp", 8, "ingressgateway")
+	configGw5 := makeConfig("foo5", "not-default", "foo.bar.com", "name5", "http", 7, "ingressgateway")
+	configGw5.Spec.(*networking.Gateway).Servers[0].Bind = "10.0.0.1"
+	configGw6 := makeConfig("foo6", "not-default-2", "*", "name6", "tcp", 8, "ingressgateway")
+	configGw6.Spec.(*networking.Gateway).Servers[0].Bind = "10.0.0.1"
 
 	tests := []struct {
 		name        string
@@ -33,6 +37,7 @@ func TestMergeGateways(t *testing.T) {
 		serversNum  int
 		routesNum   int
 		gatewaysNum int
+		conflicts   int
 	}{
 		{
 			"single-server-config",
@@ -40,6 +45,7 @@ func TestMergeGateways(t *testing.T) {
 			1,
 			1,
 			1,
+			0,
 		},
 		{
 			"same-server-config",
@@ -47,6 +53,7 @@ func TestMergeGateways(t *testing.T) {
 			1,
 			1,
 			2,
+			0,
 		},
 		{
 			"multi-server-config",
@@ -54,6 +61,7 @@ func TestMergeGateways(t *testing.T) {
 			2,
 			2,
 			3,
+			0,
 		},
 		{
 			"http-tcp-server-config",
@@ -61,6 +69,7 @@ func TestMergeGateways(t *testing.T) {
 			2,
 			1,
 			2,
+			0,
 		},
 		{
 			"tcp-tcp-server-config",
@@ -68,6 +77,7 @@ func TestMergeGateways(t *testing.T) {
 			1,
 			0,
 			2,
+			1,
 		},
 		{
 			"tcp-tcp-server-config",
@@ -75,6 +85,23 @@ func TestMergeGateways(t *testing.T) {
 			1,
 			1,
 			2,
+			1,
+		},
+		{
+			"http-bind-server-config",
+			[]Config{configGw1, configGw5},
+			2,
+			2,
+			2,
+			0,
+		},
+		{
+			"tcp-tcp-bind-server-config",
+			[]Config{configGw4, configGw6},
+			2,
+			0,
+			2,
+			0,
 		},
 	}
 
@@ -90,6 +117,9 @@ func TestMergeGateways(t *testing.T) {
 			if len(mgw.GatewayNameForServer) != tt.gatewaysNum {
 				t.Errorf("Incorrect number of gateways. Expected: %v Got: %d", tt.gatewaysNum, len(mgw.GatewayNameForServer))
 			}
+			if len(mgw.conflicts) != tt.conflicts {
+				t.Errorf("Incorrect number of conflicts. Expected: %v Got: %v", tt.conflicts, mgw.conflicts)
+			}
 		})
 	}
 }
@@ -138,6 +168,13 @@ func TestParseGatewayRDSRouteName(t *testing.T) {
 			wantPortName:   "",
 			wantGateway:    "",
 		},
+		{
+			name:           "gateway http rds name with bind",
+			args:           args{"http.80.10.0.0.1"},
+			wantPortNumber: 80,
+			wantPortName:   "",
+			wantGateway:    "",
+		},
 		{
 			name:           "https rds name",
 			args:           args{"https.443.app1.gw1.ns1"},
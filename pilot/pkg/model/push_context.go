@@ -16,7 +16,9 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +31,7 @@ import (
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/virtualservice"
 	"istio.io/istio/pkg/config/visibility"
 	"istio.io/pkg/monitoring"
 )
@@ -44,6 +47,10 @@ type PushContext struct {
 	// by the ID.
 	ProxyStatus map[string]map[string]ProxyPushStatus
 
+	// configErrors are the errors of the translation of the config resources, keyed by the resource and
+	// the reason. Protected by proxyStatusMutex.
+	configErrors map[string]ConfigError
+
 	// Mutex is used to protect the below store.
 	// All data is set when the PushContext object is populated in `InitContext`,
 	// data should not be changed by plugins.
@@ -69,6 +76,11 @@ type PushContext struct {
 	// VirtualService related
 	privateVirtualServicesByNamespace map[string][]Config
 	publicVirtualServices             []Config
+	// the positions of the virtual services in the lists above, indexed by the gateways they bind to
+	privateVirtualServicesByGateway map[string]map[string][]int
+	publicVirtualServicesByGateway  map[string][]int
+	// the regex rewrites of the HTTP routes of the virtual services, keyed by virtual service then route name
+	uriRegexRewritesByVirtualService map[string]map[string]*virtualservice.URIRegexRewrite
 
 	// destination rules are of three types:
 	//  namespaceLocalDestRules: all public/private dest rules pertaining to a service defined in a given namespace
@@ -82,8 +94,12 @@ type PushContext struct {
 
 	// sidecars for each namespace
 	sidecarsByNamespace map[string][]*SidecarScope
+	// sidecar scopes computed for this push, reused by the next push if their imports did not change
+	sidecarScopeCache *sidecarScopeCache
 	// envoy filters for each namespace including global config namespace
 	envoyFiltersByNamespace map[string][]*EnvoyFilterWrapper
+	// telemetry resources for each namespace including global config namespace
+	telemetriesByNamespace map[string][]*TelemetryWrapper
 	// gateways for each namespace
 	gatewaysByNamespace map[string][]Config
 	allGateways         []Config
@@ -110,10 +126,41 @@ type PushContext struct {
 type processedDestRules struct {
 	// List of dest rule hosts. We match with the most specific host first
 	hosts []host.Name
+	// The wildcard hosts of the list above, the only ones matching a hostname besides the hostname itself
+	wildcardHosts []host.Name
 	// Map of dest rule host and the merged destination rules for that host
 	destRule map[host.Name]*combinedDestinationRule
 }
 
+// mostSpecificHost returns the most specific dest rule host matching the hostname. A hostname which
+// is not wildcarded is looked up first in the dest rules, and then matched against the wildcard hosts,
+// instead of all the hosts.
+func (p *processedDestRules) mostSpecificHost(hostname host.Name) (host.Name, bool) {
+	if p == nil {
+		return "", false
+	}
+	if strings.HasPrefix(string(hostname), "*") {
+		return MostSpecificHostMatch(hostname, p.hosts)
+	}
+	if _, exists := p.destRule[hostname]; exists {
+		return hostname, true
+	}
+	return MostSpecificHostMatch(hostname, p.wildcardHosts)
+}
+
+// sortHosts sorts the hosts from the most specific to the least specific, and indexes the wildcard hosts.
+func (p *processedDestRules) sortHosts() {
+	sort.Sort(host.Names(p.hosts))
+	p.wildcardHosts = nil
+	for i, h := range p.hosts {
+		if strings.HasPrefix(string(h), "*") {
+			// The wildcard hosts are sorted last.
+			p.wildcardHosts = p.hosts[i:]
+			break
+		}
+	}
+}
+
 type processedAuthnPolicies struct {
 	policies map[host.Name][]*authnPolicyByPort
 	// default cluster-scoped (global) policy to be used if no other authn policy is found
@@ -180,6 +227,12 @@ type PushRequest struct {
 	// Applicable only when Full is set to true.
 	ConfigTypesUpdated map[string]struct{}
 
+	// ConfigNamespacesUpdated contains the namespaces of the configs that have changed.
+	// This is used to rebuild only the per-namespace indexes of the PushContext touched by the update.
+	// If this is empty, the configs of all namespaces are considered changed.
+	// Applicable only when Full is set to true.
+	ConfigNamespacesUpdated map[string]struct{}
+
 	// EdsUpdates keeps track of all service updated since last full push.
 	// Key is the hostname (serviceName).
 	// This is used by incremental eds.
@@ -255,6 +308,17 @@ func (first *PushRequest) Merge(other *PushRequest) *PushRequest {
 		}
 	}
 
+	// Merge the namespaces of the config updates
+	if len(first.ConfigNamespacesUpdated) > 0 && len(other.ConfigNamespacesUpdated) > 0 {
+		merged.ConfigNamespacesUpdated = make(map[string]struct{})
+		for update := range first.ConfigNamespacesUpdated {
+			merged.ConfigNamespacesUpdated[update] = struct{}{}
+		}
+		for update := range other.ConfigNamespacesUpdated {
+			merged.ConfigNamespacesUpdated[update] = struct{}{}
+		}
+	}
+
 	return merged
 }
 
@@ -265,6 +329,17 @@ type ProxyPushStatus struct {
 	Message string `json:"message,omitempty"`
 }
 
+// ConfigError is an error of the translation of a config resource to Envoy config, dropping or
+// altering part of the resource, e.g. a route of a virtual service with an invalid regex.
+type ConfigError struct {
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Reason is a short CamelCase identifier of the error, e.g. InvalidRegex.
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
 type combinedDestinationRule struct {
 	subsets map[string]struct{} // list of subsets seen so far
 	// We are not doing ports
@@ -292,6 +367,47 @@ func (ps *PushContext) Add(metric monitoring.Metric, key string, proxy *Proxy, m
 	metricMap[key] = ev
 }
 
+// AddConfigError records an error of the translation of the config resource for the proxy,
+// reported in the ConfigTranslationErrors metric and as an event on the resource.
+func (ps *PushContext) AddConfigError(config ConfigMeta, proxy *Proxy, reason, msg string) {
+	key := config.Key() + "/" + reason
+	ps.Add(ConfigTranslationErrors, key, proxy, msg)
+	if ps == nil {
+		return
+	}
+	ps.proxyStatusMutex.Lock()
+	defer ps.proxyStatusMutex.Unlock()
+	if ps.configErrors == nil {
+		ps.configErrors = map[string]ConfigError{}
+	}
+	ps.configErrors[key] = ConfigError{
+		Type:      config.Type,
+		Namespace: config.Namespace,
+		Name:      config.Name,
+		Reason:    reason,
+		Message:   msg,
+	}
+}
+
+// ConfigErrors returns the errors of the translation of the config resources recorded during the push.
+func (ps *PushContext) ConfigErrors() []ConfigError {
+	if ps == nil {
+		return nil
+	}
+	ps.proxyStatusMutex.RLock()
+	defer ps.proxyStatusMutex.RUnlock()
+	keys := make([]string, 0, len(ps.configErrors))
+	for key := range ps.configErrors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]ConfigError, 0, len(keys))
+	for _, key := range keys {
+		out = append(out, ps.configErrors[key])
+	}
+	return out
+}
+
 var (
 
 	// EndpointNoPod tracks endpoints without an associated pod. This is an error condition, since
@@ -379,6 +495,41 @@ var (
 		"Duplicate subsets across destination rules for same host",
 	)
 
+	// ConflictingGatewayServers tracks the hosts declared on the same port by the servers of
+	// different gateways selecting the same workload. Only the first server is used.
+	ConflictingGatewayServers = monitoring.NewGauge(
+		"pilot_conflict_gateway_servers",
+		"Hosts declared on the same port by the servers of several gateways of a workload.",
+	)
+
+	// ConflictingGatewayVirtualServiceHosts tracks the hosts of a gateway defined by several
+	// virtual services. Their routes are combined, and the first matching route wins.
+	ConflictingGatewayVirtualServiceHosts = monitoring.NewGauge(
+		"pilot_conflict_gateway_vservice_hosts",
+		"Hosts of a gateway defined by several virtual services.",
+	)
+
+	// RejectedGatewayVirtualServiceBindings tracks the virtual services not allowed to bind to a gateway
+	// by the namespace restrictions of its annotations. Their routes are not applied to the gateway.
+	RejectedGatewayVirtualServiceBindings = monitoring.NewGauge(
+		"pilot_rejected_gateway_vservice_bindings",
+		"Virtual services not allowed to bind to a gateway.",
+	)
+
+	// ConflictingDestinationRuleTLSModes tracks the hosts whose destination rules set different
+	// TLS modes. The traffic policy of the first destination rule is used.
+	ConflictingDestinationRuleTLSModes = monitoring.NewGauge(
+		"pilot_conflict_destrule_tls_modes",
+		"Hosts whose destination rules set conflicting TLS modes.",
+	)
+
+	// ConfigTranslationErrors tracks the config resources dropped or altered during their translation
+	// to Envoy config, e.g. a route with an invalid regex. An event is emitted on the resources.
+	ConfigTranslationErrors = monitoring.NewGauge(
+		"pilot_config_translation_errors",
+		"Config resources dropped or altered during their translation to Envoy config.",
+	)
+
 	// totalVirtualServices tracks the total number of virtual service
 	totalVirtualServices = monitoring.NewGauge(
 		"pilot_virt_services",
@@ -406,6 +557,11 @@ var (
 		ProxyStatusClusterNoInstances,
 		DuplicatedDomains,
 		DuplicatedSubsets,
+		ConflictingGatewayServers,
+		ConflictingGatewayVirtualServiceHosts,
+		RejectedGatewayVirtualServiceBindings,
+		ConflictingDestinationRuleTLSModes,
+		ConfigTranslationErrors,
 	}
 )
 
@@ -432,6 +588,7 @@ func NewPushContext() *PushContext {
 		},
 		sidecarsByNamespace:           map[string][]*SidecarScope{},
 		envoyFiltersByNamespace:       map[string][]*EnvoyFilterWrapper{},
+		telemetriesByNamespace:        map[string][]*TelemetryWrapper{},
 		gatewaysByNamespace:           map[string][]Config{},
 		allGateways:                   []Config{},
 		ServiceByHostnameAndNamespace: map[host.Name]map[string]*Service{},
@@ -502,17 +659,20 @@ func (ps *PushContext) Services(proxy *Proxy) []*Service {
 // This replaces store.VirtualServices. Used only by the gateways
 // Sidecars use the egressListener.VirtualServices().
 func (ps *PushContext) VirtualServices(proxy *Proxy, gateways map[string]bool) []Config {
+	if proxy != nil {
+		// Look up the virtual services bound to the gateways in the index, first private then public.
+		out := selectVirtualServicesByGateway(ps.privateVirtualServicesByNamespace[proxy.ConfigNamespace],
+			ps.privateVirtualServicesByGateway[proxy.ConfigNamespace], gateways)
+		return append(out, selectVirtualServicesByGateway(ps.publicVirtualServices, ps.publicVirtualServicesByGateway, gateways)...)
+	}
+
 	configs := make([]Config, 0)
 	out := make([]Config, 0)
 
 	// filter out virtual services not reachable
 	// First private virtual service
-	if proxy == nil {
-		for _, virtualSvcs := range ps.privateVirtualServicesByNamespace {
-			configs = append(configs, virtualSvcs...)
-		}
-	} else {
-		configs = append(configs, ps.privateVirtualServicesByNamespace[proxy.ConfigNamespace]...)
+	for _, virtualSvcs := range ps.privateVirtualServicesByNamespace {
+		configs = append(configs, virtualSvcs...)
 	}
 	// Second public virtual service
 	configs = append(configs, ps.publicVirtualServices...)
@@ -542,6 +702,43 @@ func (ps *PushContext) VirtualServices(proxy *Proxy, gateways map[string]bool) [
 	return out
 }
 
+// URIRegexRewrites returns the regex rewrites of the HTTP routes of the virtual service, by route name.
+// The virtual services not indexed by the push context, e.g. built by the tests, have their annotations parsed.
+func (ps *PushContext) URIRegexRewrites(virtualService Config) map[string]*virtualservice.URIRegexRewrite {
+	if ps != nil && ps.uriRegexRewritesByVirtualService != nil {
+		return ps.uriRegexRewritesByVirtualService[virtualService.Key()]
+	}
+	rewrites, err := virtualservice.ParseURIRegexRewrites(virtualService.Annotations)
+	if err != nil {
+		ps.AddConfigError(virtualService.ConfigMeta, nil, "InvalidRegexRewrite", fmt.Sprintf("regex rewrites ignored: %v", err))
+	}
+	return rewrites
+}
+
+// selectVirtualServicesByGateway returns the virtual services bound to the gateways, in their order in configs,
+// given the positions of the virtual services in configs indexed by gateway.
+func selectVirtualServicesByGateway(configs []Config, byGateway map[string][]int, gateways map[string]bool) []Config {
+	var positions []int
+	for gateway := range gateways {
+		if gateways[gateway] {
+			positions = append(positions, byGateway[gateway]...)
+		}
+	}
+	if len(gateways) > 1 {
+		// A virtual service may be bound to several of the gateways.
+		sort.Ints(positions)
+	}
+
+	out := make([]Config, 0, len(positions))
+	for i, position := range positions {
+		if i > 0 && positions[i-1] == position {
+			continue
+		}
+		out = append(out, configs[position])
+	}
+	return out
+}
+
 // getSidecarScope returns a SidecarScope object associated with the
 // proxy. The SidecarScope object is a semi-processed view of the service
 // registry, and config state associated with the sidecar crd. The scope contains
@@ -605,7 +802,7 @@ func (ps *PushContext) GetAllSidecarScopes() map[string][]*SidecarScope {
 func (ps *PushContext) DestinationRule(proxy *Proxy, service *Service) *Config {
 	// FIXME: this code should be removed once the EDS issue is fixed
 	if proxy == nil {
-		if hostname, ok := MostSpecificHostMatch(service.Hostname, ps.allExportedDestRules.hosts); ok {
+		if hostname, ok := ps.allExportedDestRules.mostSpecificHost(service.Hostname); ok {
 			return ps.allExportedDestRules.destRule[hostname].config
 		}
 		return nil
@@ -619,7 +816,7 @@ func (ps *PushContext) DestinationRule(proxy *Proxy, service *Service) *Config {
 		return proxy.SidecarScope.DestinationRule(service.Hostname)
 	}
 
-	// If the proxy config namespace is same as the root config namespace
+	// If the proxy config namespace is one of the root config namespaces
 	// look for dest rules in the service's namespace first. This hack is needed
 	// because sometimes, istio-system tends to become the root config namespace.
 	// Destination rules are defined here for global purposes. We dont want these
@@ -627,11 +824,11 @@ func (ps *PushContext) DestinationRule(proxy *Proxy, service *Service) *Config {
 	// proxies like the istio-ingressgateway or istio-egressgateway.
 	// If there are no service specific dest rules, we will end up picking up the same
 	// rules anyway, later in the code
-	if proxy.ConfigNamespace != ps.Env.Mesh.RootNamespace {
+	if !ps.Env.IsRootNamespace(proxy.ConfigNamespace) {
 		// search through the DestinationRules in proxy's namespace first
 		if ps.namespaceLocalDestRules[proxy.ConfigNamespace] != nil {
-			if hostname, ok := MostSpecificHostMatch(service.Hostname,
-				ps.namespaceLocalDestRules[proxy.ConfigNamespace].hosts); ok {
+			if hostname, ok := ps.namespaceLocalDestRules[proxy.ConfigNamespace].mostSpecificHost(
+				service.Hostname); ok {
 				return ps.namespaceLocalDestRules[proxy.ConfigNamespace].destRule[hostname].config
 			}
 		}
@@ -654,19 +851,21 @@ func (ps *PushContext) DestinationRule(proxy *Proxy, service *Service) *Config {
 	// if no private/public rule matched in the calling proxy's namespace,
 	// check the target service's namespace for public rules
 	if svcNs != "" && ps.namespaceExportedDestRules[svcNs] != nil {
-		if hostname, ok := MostSpecificHostMatch(service.Hostname,
-			ps.namespaceExportedDestRules[svcNs].hosts); ok {
+		if hostname, ok := ps.namespaceExportedDestRules[svcNs].mostSpecificHost(service.Hostname); ok {
 			return ps.namespaceExportedDestRules[svcNs].destRule[hostname].config
 		}
 	}
 
 	// if no public/private rule in calling proxy's namespace matched, and no public rule in the
-	// target service's namespace matched, search for any public destination rule in the config root namespace
-	// NOTE: This does mean that we are effectively ignoring private dest rules in the config root namespace
-	if ps.namespaceExportedDestRules[ps.Env.Mesh.RootNamespace] != nil {
-		if hostname, ok := MostSpecificHostMatch(service.Hostname,
-			ps.namespaceExportedDestRules[ps.Env.Mesh.RootNamespace].hosts); ok {
-			return ps.namespaceExportedDestRules[ps.Env.Mesh.RootNamespace].destRule[hostname].config
+	// target service's namespace matched, search for any public destination rule in the config root namespaces,
+	// in their order of precedence.
+	// NOTE: This does mean that we are effectively ignoring private dest rules in the config root namespaces
+	for _, rootNamespace := range ps.Env.RootNamespaces() {
+		if ps.namespaceExportedDestRules[rootNamespace] != nil {
+			if hostname, ok := ps.namespaceExportedDestRules[rootNamespace].mostSpecificHost(
+				service.Hostname); ok {
+				return ps.namespaceExportedDestRules[rootNamespace].destRule[hostname].config
+			}
 		}
 	}
 
@@ -754,6 +953,10 @@ func (ps *PushContext) createNewContext(env *Environment) error {
 		return err
 	}
 
+	if err := ps.initTelemetries(env); err != nil {
+		return err
+	}
+
 	if err := ps.initGateways(env); err != nil {
 		return err
 	}
@@ -771,7 +974,7 @@ func (ps *PushContext) updateContext(
 	pushReq *PushRequest) error {
 
 	var servicesChanged, virtualServicesChanged, destinationRulesChanged, gatewayChanged,
-		authnChanged, authzChanged, envoyFiltersChanged, sidecarsChanged bool
+		authnChanged, authzChanged, envoyFiltersChanged, sidecarsChanged, telemetriesChanged bool
 
 	for k := range pushReq.ConfigTypesUpdated {
 		switch k {
@@ -787,6 +990,8 @@ func (ps *PushContext) updateContext(
 			sidecarsChanged = true
 		case schemas.EnvoyFilter.Type:
 			envoyFiltersChanged = true
+		case schemas.Telemetry.Type:
+			telemetriesChanged = true
 		case schemas.ServiceRoleBinding.Type, schemas.ServiceRole.Type,
 			schemas.ClusterRbacConfig.Type, schemas.RbacConfig.Type,
 			schemas.AuthorizationPolicy.Type:
@@ -809,12 +1014,20 @@ func (ps *PushContext) updateContext(
 	}
 
 	if virtualServicesChanged {
-		if err := ps.initVirtualServices(env); err != nil {
+		// Only the virtual services of the namespaces of the changed configs are processed again, if they are known.
+		if len(pushReq.ConfigNamespacesUpdated) > 0 {
+			if err := ps.updateVirtualServices(env, oldPushContext, pushReq.ConfigNamespacesUpdated); err != nil {
+				return err
+			}
+		} else if err := ps.initVirtualServices(env); err != nil {
 			return err
 		}
 	} else {
 		ps.privateVirtualServicesByNamespace = oldPushContext.privateVirtualServicesByNamespace
 		ps.publicVirtualServices = oldPushContext.publicVirtualServices
+		ps.privateVirtualServicesByGateway = oldPushContext.privateVirtualServicesByGateway
+		ps.publicVirtualServicesByGateway = oldPushContext.publicVirtualServicesByGateway
+		ps.uriRegexRewritesByVirtualService = oldPushContext.uriRegexRewritesByVirtualService
 	}
 
 	if destinationRulesChanged {
@@ -852,6 +1065,14 @@ func (ps *PushContext) updateContext(
 		ps.envoyFiltersByNamespace = oldPushContext.envoyFiltersByNamespace
 	}
 
+	if telemetriesChanged {
+		if err := ps.initTelemetries(env); err != nil {
+			return err
+		}
+	} else {
+		ps.telemetriesByNamespace = oldPushContext.telemetriesByNamespace
+	}
+
 	if gatewayChanged {
 		if err := ps.initGateways(env); err != nil {
 			return err
@@ -862,13 +1083,19 @@ func (ps *PushContext) updateContext(
 	}
 
 	// Must be initialized in the end
-	// Sidecars need to be updated if services, virtual services, destination rules, or the sidecar configs change
-	if servicesChanged || virtualServicesChanged || destinationRulesChanged || sidecarsChanged {
-		if err := ps.initSidecarScopes(env); err != nil {
+	// Sidecars need to be updated if services, virtual services, destination rules, the sidecar configs or the mesh
+	// config change
+	meshChanged, err := oldPushContext.sidecarScopeCache.meshConfigChanged(env)
+	if err != nil {
+		return err
+	}
+	if servicesChanged || virtualServicesChanged || destinationRulesChanged || sidecarsChanged || meshChanged {
+		if err := ps.updateSidecarScopes(env, oldPushContext); err != nil {
 			return err
 		}
 	} else {
 		ps.sidecarsByNamespace = oldPushContext.sidecarsByNamespace
+		ps.sidecarScopeCache = oldPushContext.sidecarScopeCache
 	}
 
 	return nil
@@ -1012,13 +1239,53 @@ func (ps *PushContext) initVirtualServices(env *Environment) error {
 
 	totalVirtualServices.Record(float64(len(virtualServices)))
 
-	// TODO(rshriram): parse each virtual service and maintain a map of the
-	// virtualservice name, the list of registry hosts in the VS and non
-	// registry DNS names in the VS.  This should cut down processing in
-	// the RDS code. See separateVSHostsAndServices in route/route.go
 	sortConfigByCreationTime(vservices)
+	resolveVirtualServiceShortnames(vservices)
+	ps.indexVirtualServices(vservices)
 
-	// convert all shortnames in virtual services into FQDNs
+	return nil
+}
+
+// updateVirtualServices rebuilds the virtual service index for the virtual services of the given namespaces,
+// and reuses the virtual services of the other namespaces, already processed, from the old push context.
+func (ps *PushContext) updateVirtualServices(env *Environment, oldPushContext *PushContext,
+	namespaces map[string]struct{}) error {
+	ps.privateVirtualServicesByNamespace = map[string][]Config{}
+	ps.publicVirtualServices = []Config{}
+
+	vservices := make([]Config, 0)
+	for ns := range namespaces {
+		virtualServices, err := env.List(schemas.VirtualService.Type, ns)
+		if err != nil {
+			return err
+		}
+		for i := range virtualServices {
+			vservices = append(vservices, virtualServices[i].DeepCopy())
+		}
+	}
+	resolveVirtualServiceShortnames(vservices)
+
+	for ns, configs := range oldPushContext.privateVirtualServicesByNamespace {
+		if _, f := namespaces[ns]; !f {
+			vservices = append(vservices, configs...)
+		}
+	}
+	for _, virtualService := range oldPushContext.publicVirtualServices {
+		if _, f := namespaces[virtualService.Namespace]; !f {
+			vservices = append(vservices, virtualService)
+		}
+	}
+
+	totalVirtualServices.Record(float64(len(vservices)))
+
+	sortConfigByCreationTime(vservices)
+	ps.indexVirtualServices(vservices)
+
+	return nil
+}
+
+// resolveVirtualServiceShortnames converts all shortnames in virtual services into FQDNs.
+func resolveVirtualServiceShortnames(vservices []Config) {
 	for _, r := range vservices {
 		rule := r.Spec.(*networking.VirtualService)
 		// resolve top level hosts
@@ -1074,7 +1341,11 @@ func (ps *PushContext) initVirtualServices(env *Environment) error {
 			}
 		}
 	}
+}
 
+// indexVirtualServices indexes the virtual services, sorted by creation time, by their visibility and the
+// gateways they bind to.
+func (ps *PushContext) indexVirtualServices(vservices []Config) {
 	for _, virtualService := range vservices {
 		ns := virtualService.Namespace
 		rule := virtualService.Spec.(*networking.VirtualService)
@@ -1101,7 +1372,48 @@ func (ps *PushContext) initVirtualServices(env *Environment) error {
 		}
 	}
 
-	return nil
+	ps.privateVirtualServicesByGateway = make(map[string]map[string][]int, len(ps.privateVirtualServicesByNamespace))
+	for ns, configs := range ps.privateVirtualServicesByNamespace {
+		ps.privateVirtualServicesByGateway[ns] = indexVirtualServicesByGateway(configs)
+	}
+	ps.publicVirtualServicesByGateway = indexVirtualServicesByGateway(ps.publicVirtualServices)
+
+	ps.uriRegexRewritesByVirtualService = make(map[string]map[string]*virtualservice.URIRegexRewrite)
+	for _, virtualService := range vservices {
+		rewrites, err := virtualservice.ParseURIRegexRewrites(virtualService.Annotations)
+		if err != nil {
+			// the invalid annotations are rejected by the validation, they may only come from other config sources
+			ps.AddConfigError(virtualService.ConfigMeta, nil, "InvalidRegexRewrite", fmt.Sprintf("regex rewrites ignored: %v", err))
+			continue
+		}
+		if rewrites != nil {
+			ps.uriRegexRewritesByVirtualService[virtualService.Key()] = rewrites
+		}
+	}
+}
+
+// indexVirtualServicesByGateway returns the positions of the virtual services, indexed by the gateways they
+// bind to. The virtual services without gateways bind to the mesh gateway.
+func indexVirtualServicesByGateway(vservices []Config) map[string][]int {
+	out := map[string][]int{}
+	for i, cfg := range vservices {
+		rule := cfg.Spec.(*networking.VirtualService)
+		if len(rule.Gateways) == 0 {
+			out[constants.IstioMeshGateway] = append(out[constants.IstioMeshGateway], i)
+			continue
+		}
+		for _, g := range rule.Gateways {
+			if g != constants.IstioMeshGateway {
+				// note: Gateway names do _not_ use wildcard matching, so we do not use Name.Matches here
+				g = resolveGatewayName(g, cfg.ConfigMeta)
+			}
+			if positions := out[g]; len(positions) > 0 && positions[len(positions)-1] == i {
+				continue
+			}
+			out[g] = append(out[g], i)
+		}
+	}
+	return out
 }
 
 func (ps *PushContext) initDefaultExportMaps() {
@@ -1147,6 +1459,21 @@ func (ps *PushContext) initDefaultExportMaps() {
 // with the proxy and derive listeners/routes/clusters based on the sidecar
 // scope.
 func (ps *PushContext) initSidecarScopes(env *Environment) error {
+	return ps.updateSidecarScopes(env, nil)
+}
+
+// updateSidecarScopes builds the sidecar scopes like initSidecarScopes, but reuses the sidecar scopes
+// of the old push context whose Sidecar resource and imported configs did not change.
+func (ps *PushContext) updateSidecarScopes(env *Environment, oldPushContext *PushContext) error {
+	cache, err := newSidecarScopeCache(ps, env)
+	if err != nil {
+		return err
+	}
+	var oldCache *sidecarScopeCache
+	if oldPushContext != nil {
+		oldCache = oldPushContext.sidecarScopeCache
+	}
+
 	sidecarConfigs, err := env.List(schemas.Sidecar.Type, NamespaceAll)
 	if err != nil {
 		return err
@@ -1176,19 +1503,21 @@ func (ps *PushContext) initSidecarScopes(env *Environment) error {
 	for _, sidecarConfig := range sidecarConfigs {
 		sidecarConfig := sidecarConfig
 		ps.sidecarsByNamespace[sidecarConfig.Namespace] = append(ps.sidecarsByNamespace[sidecarConfig.Namespace],
-			ConvertToSidecarScope(ps, &sidecarConfig, sidecarConfig.Namespace))
+			cache.sidecarScope(ps, oldCache, &sidecarConfig, sidecarConfig.Namespace))
 	}
 
 	// Hold reference root namespace's sidecar config
 	// Root namespace can have only one sidecar config object
 	// Currently we expect that it has no workloadSelectors
+	// With several root namespaces, the sidecar config of the one with the highest precedence is used.
 	var rootNSConfig *Config
-	if env.Mesh.RootNamespace != "" {
+rootNamespaces:
+	for _, rootNamespace := range env.RootNamespaces() {
 		for _, sidecarConfig := range sidecarConfigs {
-			if sidecarConfig.Namespace == env.Mesh.RootNamespace &&
+			if sidecarConfig.Namespace == rootNamespace &&
 				sidecarConfig.Spec.(*networking.Sidecar).WorkloadSelector == nil {
 				rootNSConfig = &sidecarConfig
-				break
+				break rootNamespaces
 			}
 		}
 	}
@@ -1199,10 +1528,11 @@ func (ps *PushContext) initSidecarScopes(env *Environment) error {
 	for _, nsMap := range ps.ServiceByHostnameAndNamespace {
 		for ns := range nsMap {
 			if _, exist := sidecarsWithoutSelectorByNamespace[ns]; !exist {
-				ps.sidecarsByNamespace[ns] = append(ps.sidecarsByNamespace[ns], ConvertToSidecarScope(ps, rootNSConfig, ns))
+				ps.sidecarsByNamespace[ns] = append(ps.sidecarsByNamespace[ns], cache.sidecarScope(ps, oldCache, rootNSConfig, ns))
 			}
 		}
 	}
+	ps.sidecarScopeCache = cache
 
 	return nil
 }
@@ -1338,12 +1668,12 @@ func (ps *PushContext) SetDestinationRules(configs []Config) {
 	// presort it so that we don't sort it for each DestinationRule call.
 	// sort.Sort for Hostnames will automatically sort from the most specific to least specific
 	for ns := range namespaceLocalDestRules {
-		sort.Sort(host.Names(namespaceLocalDestRules[ns].hosts))
+		namespaceLocalDestRules[ns].sortHosts()
 	}
 	for ns := range namespaceExportedDestRules {
-		sort.Sort(host.Names(namespaceExportedDestRules[ns].hosts))
+		namespaceExportedDestRules[ns].sortHosts()
 	}
-	sort.Sort(host.Names(allExportedDestRules.hosts))
+	allExportedDestRules.sortHosts()
 
 	ps.namespaceLocalDestRules = namespaceLocalDestRules
 	ps.namespaceExportedDestRules = namespaceExportedDestRules
@@ -1386,20 +1716,23 @@ func (ps *PushContext) EnvoyFilters(proxy *Proxy) []*EnvoyFilterWrapper {
 	}
 	out := make([]*EnvoyFilterWrapper, 0)
 	// EnvoyFilters supports inheritance (global ones plus namespace local ones).
-	// First get all the filter configs from the config root namespace
-	// and then add the ones from proxy's own namespace
-	if ps.Env.Mesh.RootNamespace != "" {
+	// First get all the filter configs from the config root namespaces
+	// and then add the ones from proxy's own namespace. The root namespaces
+	// are added by increasing precedence, so that the patches of the ones
+	// with the highest precedence are applied last.
+	rootNamespaces := ps.Env.RootNamespaces()
+	for i := len(rootNamespaces) - 1; i >= 0; i-- {
 		// if there is no workload selector, the config applies to all workloads
 		// if there is a workload selector, check for matching workload labels
-		for _, efw := range ps.envoyFiltersByNamespace[ps.Env.Mesh.RootNamespace] {
+		for _, efw := range ps.envoyFiltersByNamespace[rootNamespaces[i]] {
 			if efw.workloadSelector == nil || proxy.WorkloadLabels.IsSupersetOf(efw.workloadSelector) {
 				out = append(out, efw)
 			}
 		}
 	}
 
-	// To prevent duplicate envoyfilters in case root namespace equals proxy's namespace
-	if proxy.ConfigNamespace != ps.Env.Mesh.RootNamespace {
+	// To prevent duplicate envoyfilters in case a root namespace equals proxy's namespace
+	if !ps.Env.IsRootNamespace(proxy.ConfigNamespace) {
 		for _, efw := range ps.envoyFiltersByNamespace[proxy.ConfigNamespace] {
 			if efw.workloadSelector == nil || proxy.WorkloadLabels.IsSupersetOf(efw.workloadSelector) {
 				out = append(out, efw)
@@ -1410,6 +1743,75 @@ func (ps *PushContext) EnvoyFilters(proxy *Proxy) []*EnvoyFilterWrapper {
 	return out
 }
 
+// pre computes telemetry resources per namespace
+func (ps *PushContext) initTelemetries(env *Environment) error {
+	telemetryConfigs, err := env.List(schemas.Telemetry.Type, NamespaceAll)
+	if err != nil {
+		return err
+	}
+
+	sortConfigByCreationTime(telemetryConfigs)
+
+	ps.telemetriesByNamespace = make(map[string][]*TelemetryWrapper)
+	for _, telemetryConfig := range telemetryConfigs {
+		tw := convertToTelemetryWrapper(&telemetryConfig)
+		ps.telemetriesByNamespace[telemetryConfig.Namespace] = append(ps.telemetriesByNamespace[telemetryConfig.Namespace], tw)
+	}
+	return nil
+}
+
+// Telemetry returns the telemetry configuration in effect for the proxy, or nil if no Telemetry
+// resource applies to it. The mesh-wide resources of the root namespaces, each overriding the ones of
+// lower precedence, are overridden by the namespace-wide resource of the proxy namespace, which in
+// turn is overridden by a resource selecting the proxy workload. If several resources apply at the
+// same level, the oldest one wins.
+func (ps *PushContext) Telemetry(proxy *Proxy) *ProxyTelemetry {
+	// this should never happen
+	if proxy == nil {
+		return nil
+	}
+
+	var matched []*TelemetryWrapper
+	rootNamespaces := ps.Env.RootNamespaces()
+	for i := len(rootNamespaces) - 1; i >= 0; i-- {
+		if rootNamespaces[i] == proxy.ConfigNamespace {
+			continue
+		}
+		if tw := oldestTelemetry(ps.telemetriesByNamespace[rootNamespaces[i]], false, nil); tw != nil {
+			matched = append(matched, tw)
+		}
+	}
+	if tw := oldestTelemetry(ps.telemetriesByNamespace[proxy.ConfigNamespace], false, nil); tw != nil {
+		matched = append(matched, tw)
+	}
+	if tw := oldestTelemetry(ps.telemetriesByNamespace[proxy.ConfigNamespace], true, proxy.WorkloadLabels); tw != nil {
+		matched = append(matched, tw)
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+
+	out := &ProxyTelemetry{}
+	for _, tw := range matched {
+		out.merge(tw.Spec)
+	}
+	return out
+}
+
+// oldestTelemetry returns the first namespace-wide resource, or the first resource whose selector
+// matches workloadLabels if selected is true.
+func oldestTelemetry(telemetries []*TelemetryWrapper, selected bool, workloadLabels labels.Collection) *TelemetryWrapper {
+	for _, tw := range telemetries {
+		if !selected && tw.workloadSelector == nil {
+			return tw
+		}
+		if selected && tw.workloadSelector != nil && workloadLabels.IsSupersetOf(tw.workloadSelector) {
+			return tw
+		}
+	}
+	return nil
+}
+
 // pre computes gateways per namespace
 func (ps *PushContext) initGateways(env *Environment) error {
 	gatewayConfigs, err := env.List(schemas.Gateway.Type, NamespaceAll)
@@ -1457,8 +1859,73 @@ func (ps *PushContext) mergeGateways(proxy *Proxy) *MergedGateway {
 		}
 	}
 
+	if gw := ps.networkGateway(proxy, out); gw != nil {
+		out = append(out, *gw)
+	}
+
 	if len(out) == 0 {
 		return nil
 	}
-	return MergeGateways(out...)
+	merged := MergeGateways(out...)
+	for _, c := range merged.conflicts {
+		ps.Add(ConflictingGatewayServers, fmt.Sprintf("%s:%d", c.host, c.port), proxy,
+			fmt.Sprintf("Host %s declared on port %d by gateway %s conflicts with gateway %s", c.host, c.port, c.gateways[1], c.gateways[0]))
+	}
+	return merged
+}
+
+// networkGateway returns the Gateway generated for a gateway running in sni-dnat router mode, which
+// bridges its network to the other networks of the mesh. The gateway accepts the mTLS traffic of the
+// other networks on the port configured for it in the mesh networks, and routes it based on SNI. Nothing
+// is generated if one of the configured Gateways already has a server on that port.
+func (ps *PushContext) networkGateway(proxy *Proxy, configs []Config) *Config {
+	if proxy.Metadata == nil || proxy.GetRouterMode() != SniDnatRouter {
+		return nil
+	}
+
+	port := uint32(constants.DefaultNetworkGatewayPort)
+	if ps.Env != nil && ps.Env.MeshNetworks != nil {
+		if network, f := ps.Env.MeshNetworks.Networks[proxy.Metadata.Network]; f {
+		gateways:
+			for _, gw := range network.Gateways {
+				for _, si := range proxy.ServiceInstances {
+					if si.Service != nil && string(si.Service.Hostname) == gw.GetRegistryServiceName() {
+						port = gw.Port
+						break gateways
+					}
+				}
+			}
+		}
+	}
+
+	for _, cfg := range configs {
+		for _, server := range cfg.Spec.(*networking.Gateway).Servers {
+			if server.GetPort().GetNumber() == port {
+				return nil
+			}
+		}
+	}
+
+	return &Config{
+		ConfigMeta: ConfigMeta{
+			Type:      schemas.Gateway.Type,
+			Group:     schemas.Gateway.Group,
+			Version:   schemas.Gateway.Version,
+			Name:      constants.IstioNetworkGatewayName,
+			Namespace: proxy.ConfigNamespace,
+		},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{{
+				Port: &networking.Port{
+					Number:   port,
+					Protocol: string(protocol.TLS),
+					Name:     "tls-network",
+				},
+				Hosts: []string{"*.local"},
+				Tls: &networking.Server_TLSOptions{
+					Mode: networking.Server_TLSOptions_AUTO_PASSTHROUGH,
+				},
+			}},
+		},
+	}
 }
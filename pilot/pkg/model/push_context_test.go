@@ -20,6 +20,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+
 	authn "istio.io/api/authentication/v1alpha1"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
@@ -29,6 +31,8 @@ import (
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/virtualservice"
+	"istio.io/istio/pkg/config/visibility"
 )
 
 func TestMergeUpdateRequest(t *testing.T) {
@@ -60,27 +64,36 @@ func TestMergeUpdateRequest(t *testing.T) {
 		{
 			"simple merge",
 			&PushRequest{
-				Full:               true,
-				Push:               push0,
-				Start:              t0,
-				NamespacesUpdated:  map[string]struct{}{"ns1": {}},
-				ConfigTypesUpdated: map[string]struct{}{"cfg1": {}},
+				Full:                    true,
+				Push:                    push0,
+				Start:                   t0,
+				NamespacesUpdated:       map[string]struct{}{"ns1": {}},
+				ConfigTypesUpdated:      map[string]struct{}{"cfg1": {}},
+				ConfigNamespacesUpdated: map[string]struct{}{"ns1": {}},
 			},
 			&PushRequest{
-				Full:               false,
-				Push:               push1,
-				Start:              t1,
-				NamespacesUpdated:  map[string]struct{}{"ns2": {}},
-				ConfigTypesUpdated: map[string]struct{}{"cfg2": {}},
+				Full:                    false,
+				Push:                    push1,
+				Start:                   t1,
+				NamespacesUpdated:       map[string]struct{}{"ns2": {}},
+				ConfigTypesUpdated:      map[string]struct{}{"cfg2": {}},
+				ConfigNamespacesUpdated: map[string]struct{}{"ns2": {}},
 			},
 			PushRequest{
-				Full:               true,
-				Push:               push1,
-				Start:              t0,
-				NamespacesUpdated:  map[string]struct{}{"ns1": {}, "ns2": {}},
-				ConfigTypesUpdated: map[string]struct{}{"cfg1": {}, "cfg2": {}},
+				Full:                    true,
+				Push:                    push1,
+				Start:                   t0,
+				NamespacesUpdated:       map[string]struct{}{"ns1": {}, "ns2": {}},
+				ConfigTypesUpdated:      map[string]struct{}{"cfg1": {}, "cfg2": {}},
+				ConfigNamespacesUpdated: map[string]struct{}{"ns1": {}, "ns2": {}},
 			},
 		},
+		{
+			"skip config namespaces merge: one empty",
+			&PushRequest{Full: true, ConfigNamespacesUpdated: map[string]struct{}{"ns1": {}}},
+			&PushRequest{Full: true},
+			PushRequest{Full: true},
+		},
 		{
 			"incremental eds merge",
 			&PushRequest{Full: false, EdsUpdates: map[string]struct{}{"svc-1": {}}},
@@ -488,6 +501,85 @@ func TestEnvoyFilters(t *testing.T) {
 
 }
 
+func TestNetworkGateway(t *testing.T) {
+	eastWest := &Service{Hostname: "istio-eastwestgateway.istio-system.svc.cluster.local"}
+	push := &PushContext{
+		Env: &Environment{
+			MeshNetworks: &meshconfig.MeshNetworks{
+				Networks: map[string]*meshconfig.Network{
+					"network1": {
+						Gateways: []*meshconfig.Network_IstioNetworkGateway{{
+							Gw: &meshconfig.Network_IstioNetworkGateway_RegistryServiceName{
+								RegistryServiceName: string(eastWest.Hostname),
+							},
+							Port: 16443,
+						}},
+					},
+				},
+			},
+		},
+	}
+	userGateway := Config{
+		ConfigMeta: ConfigMeta{Name: "cross-network", Namespace: "istio-system"},
+		Spec: &networking.Gateway{
+			Servers: []*networking.Server{{
+				Port:  &networking.Port{Number: 15443, Protocol: "TLS", Name: "tls"},
+				Hosts: []string{"*.local"},
+				Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_AUTO_PASSTHROUGH},
+			}},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		proxy   *Proxy
+		configs []Config
+		port    uint32
+	}{
+		{
+			name:  "standard gateway",
+			proxy: &Proxy{Metadata: &NodeMetadata{Network: "network1"}},
+		},
+		{
+			name:  "default port",
+			proxy: &Proxy{Metadata: &NodeMetadata{RouterMode: string(SniDnatRouter)}},
+			port:  constants.DefaultNetworkGatewayPort,
+		},
+		{
+			name: "port from mesh networks",
+			proxy: &Proxy{
+				Metadata:         &NodeMetadata{RouterMode: string(SniDnatRouter), Network: "network1"},
+				ServiceInstances: []*ServiceInstance{{Service: eastWest}},
+			},
+			port: 16443,
+		},
+		{
+			name:    "configured by the user",
+			proxy:   &Proxy{Metadata: &NodeMetadata{RouterMode: string(SniDnatRouter)}},
+			configs: []Config{userGateway},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			gw := push.networkGateway(tt.proxy, tt.configs)
+			if tt.port == 0 {
+				if gw != nil {
+					t.Fatalf("expected no network gateway, got %v", gw)
+				}
+				return
+			}
+			if gw == nil {
+				t.Fatalf("expected a network gateway")
+			}
+			server := gw.Spec.(*networking.Gateway).Servers[0]
+			if server.Port.Number != tt.port || server.Tls.Mode != networking.Server_TLSOptions_AUTO_PASSTHROUGH {
+				t.Errorf("unexpected network gateway server %v", server)
+			}
+		})
+	}
+}
+
 func TestSidecarScope(t *testing.T) {
 	ps := NewPushContext()
 	env := &Environment{Mesh: &meshconfig.MeshConfig{RootNamespace: "istio-system"}}
@@ -577,6 +669,203 @@ func TestSidecarScope(t *testing.T) {
 	}
 }
 
+func TestUpdateContextNamespaces(t *testing.T) {
+	newConfig := func(typ, name, namespace string, spec proto.Message) Config {
+		return Config{ConfigMeta: ConfigMeta{Type: typ, Name: name, Namespace: namespace}, Spec: spec}
+	}
+	configStore := newFakeStore()
+	for _, c := range []Config{
+		newConfig(schemas.VirtualService.Type, "reviews", "ns1", &networking.VirtualService{Hosts: []string{"reviews"}}),
+		newConfig(schemas.VirtualService.Type, "ratings", "ns2",
+			&networking.VirtualService{Hosts: []string{"ratings"}, ExportTo: []string{"."}}),
+		newConfig(schemas.Sidecar.Type, "default", "ns1", &networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*"}}},
+		}),
+		newConfig(schemas.Sidecar.Type, "default", "ns2", &networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*"}}},
+		}),
+	} {
+		_, _ = configStore.Create(c)
+	}
+	env := &Environment{
+		Mesh:             &meshconfig.MeshConfig{RootNamespace: "istio-system"},
+		IstioConfigStore: &istioConfigStore{ConfigStore: configStore},
+	}
+
+	old := NewPushContext()
+	old.Env = env
+	old.initDefaultExportMaps()
+	if err := old.initVirtualServices(env); err != nil {
+		t.Fatal(err)
+	}
+	if err := old.initSidecarScopes(env); err != nil {
+		t.Fatal(err)
+	}
+	old.initDone = true
+
+	_, _ = configStore.Create(newConfig(schemas.VirtualService.Type, "details", "ns2",
+		&networking.VirtualService{Hosts: []string{"details"}}))
+	push := NewPushContext()
+	if err := push.InitContext(env, old, &PushRequest{
+		Full:                    true,
+		ConfigTypesUpdated:      map[string]struct{}{schemas.VirtualService.Type: {}, schemas.Sidecar.Type: {}},
+		ConfigNamespacesUpdated: map[string]struct{}{"ns2": {}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	full := NewPushContext()
+	full.Env = env
+	full.initDefaultExportMaps()
+	if err := full.initVirtualServices(env); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(push.publicVirtualServices, full.publicVirtualServices) ||
+		!reflect.DeepEqual(push.privateVirtualServicesByNamespace, full.privateVirtualServicesByNamespace) {
+		t.Errorf("got virtual services %v %v, want %v %v", push.publicVirtualServices, push.privateVirtualServicesByNamespace,
+			full.publicVirtualServices, full.privateVirtualServicesByNamespace)
+	}
+	// The processed virtual services of the namespaces not updated are reused.
+	for _, vs := range push.publicVirtualServices {
+		if vs.Namespace == "ns1" && vs.Spec != old.publicVirtualServices[0].Spec {
+			t.Errorf("virtual service ns1/reviews not reused")
+		}
+	}
+
+	// Only the sidecar scope importing the changed virtual services is rebuilt.
+	if push.sidecarsByNamespace["ns1"][0] != old.sidecarsByNamespace["ns1"][0] {
+		t.Errorf("sidecar scope of ns1 rebuilt")
+	}
+	if push.sidecarsByNamespace["ns2"][0] == old.sidecarsByNamespace["ns2"][0] {
+		t.Errorf("sidecar scope of ns2 not rebuilt")
+	}
+}
+
+func TestVirtualServicesByGateway(t *testing.T) {
+	ps := NewPushContext()
+	ps.defaultVirtualServiceExportTo = map[visibility.Instance]bool{visibility.Public: true}
+	vservices := []Config{}
+	for i, vs := range []struct {
+		name, namespace string
+		gateways        []string
+		exportTo        []string
+	}{
+		{"mesh", "ns1", nil, nil},
+		{"gw", "ns1", []string{"gw"}, nil},
+		{"gw-and-mesh", "ns1", []string{"ns1/gw", constants.IstioMeshGateway}, nil},
+		{"other-gw", "ns2", []string{"gw"}, nil},
+		{"both-gw", "ns2", []string{"ns1/gw", "gw"}, nil},
+		{"private", "ns1", []string{"gw"}, []string{"."}},
+		{"private-other", "ns2", []string{"ns1/gw"}, []string{"."}},
+	} {
+		vservices = append(vservices, Config{
+			ConfigMeta: ConfigMeta{
+				Name:              vs.name,
+				Namespace:         vs.namespace,
+				CreationTimestamp: time.Unix(int64(i), 0),
+			},
+			Spec: &networking.VirtualService{Hosts: []string{"foo.com"}, Gateways: vs.gateways, ExportTo: vs.exportTo},
+		})
+	}
+	resolveVirtualServiceShortnames(vservices)
+	ps.indexVirtualServices(vservices)
+
+	cases := []struct {
+		proxyNamespace string
+		gateways       []string
+		want           []string
+	}{
+		{"ns1", []string{constants.IstioMeshGateway}, []string{"mesh", "gw-and-mesh"}},
+		{"ns1", []string{"ns1/gw"}, []string{"private", "gw", "gw-and-mesh", "both-gw"}},
+		{"ns2", []string{"ns2/gw"}, []string{"other-gw", "both-gw"}},
+		{"ns2", []string{"ns1/gw", "ns2/gw"}, []string{"private-other", "gw", "gw-and-mesh", "other-gw", "both-gw"}},
+		{"ns1", []string{constants.IstioMeshGateway, "ns1/gw"}, []string{"private", "mesh", "gw", "gw-and-mesh", "both-gw"}},
+		{"ns3", []string{"ns3/gw"}, []string{}},
+	}
+	for _, tt := range cases {
+		gateways := map[string]bool{}
+		for _, g := range tt.gateways {
+			gateways[g] = true
+		}
+		got := []string{}
+		for _, cfg := range ps.VirtualServices(&Proxy{ConfigNamespace: tt.proxyNamespace}, gateways) {
+			got = append(got, cfg.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("VirtualServices(%s, %v) got %v, want %v", tt.proxyNamespace, tt.gateways, got, tt.want)
+		}
+	}
+}
+
+func TestDestinationRuleHostIndex(t *testing.T) {
+	ps := NewPushContext()
+	ps.defaultDestinationRuleExportTo = map[visibility.Instance]bool{visibility.Public: true}
+	configs := []Config{}
+	for _, h := range []string{"*.foo.com", "a.foo.com", "*.com", "b.bar.com", "*"} {
+		configs = append(configs, Config{
+			ConfigMeta: ConfigMeta{Name: h, Namespace: "ns1"},
+			Spec:       &networking.DestinationRule{Host: h},
+		})
+	}
+	ps.SetDestinationRules(configs)
+
+	for _, needle := range []host.Name{"a.foo.com", "b.foo.com", "b.bar.com", "c.bar.com", "x.org", "*.foo.com", "*.bar.com", "*"} {
+		want, wantOk := MostSpecificHostMatch(needle, ps.allExportedDestRules.hosts)
+		got, ok := ps.allExportedDestRules.mostSpecificHost(needle)
+		if got != want || ok != wantOk {
+			t.Errorf("mostSpecificHost(%s) got %s %v, want %s %v", needle, got, ok, want, wantOk)
+		}
+	}
+}
+
+func TestURIRegexRewrites(t *testing.T) {
+	newVirtualService := func(name, annotation string) Config {
+		return Config{
+			ConfigMeta: ConfigMeta{
+				Type:        schemas.VirtualService.Type,
+				Name:        name,
+				Namespace:   "ns1",
+				Annotations: map[string]string{virtualservice.URIRegexRewriteAnnotation: annotation},
+			},
+			Spec: &networking.VirtualService{Hosts: []string{name}},
+		}
+	}
+	valid := newVirtualService("reviews", `{"v1": {"pattern": "^/v1/(.*)$", "substitution": "/api/\\1"}}`)
+	invalid := newVirtualService("ratings", `{"v1": {"pattern": "^/v1/(.*$"}}`)
+	configStore := newFakeStore()
+	for _, c := range []Config{valid, invalid} {
+		_, _ = configStore.Create(c)
+	}
+	env := &Environment{
+		Mesh:             &meshconfig.MeshConfig{RootNamespace: "istio-system"},
+		IstioConfigStore: &istioConfigStore{ConfigStore: configStore},
+	}
+	ps := NewPushContext()
+	ps.Env = env
+	ps.initDefaultExportMaps()
+	if err := ps.initVirtualServices(env); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]*virtualservice.URIRegexRewrite{"v1": {Pattern: "^/v1/(.*)$", Substitution: "/api/\\1"}}
+	if got := ps.URIRegexRewrites(valid); !reflect.DeepEqual(got, want) {
+		t.Errorf("got regex rewrites %v, want %v", got, want)
+	}
+	// the rewrites are parsed once, when the virtual services are indexed
+	ps.uriRegexRewritesByVirtualService[valid.Key()]["v1"].Substitution = "/"
+	if got := ps.URIRegexRewrites(valid)["v1"].Substitution; got != "/" {
+		t.Errorf("got substitution %q, want the parsed rewrite", got)
+	}
+
+	if got := ps.URIRegexRewrites(invalid); got != nil {
+		t.Errorf("got regex rewrites %v for an invalid annotation, want none", got)
+	}
+	configErrors := ps.ConfigErrors()
+	if len(configErrors) != 1 || configErrors[0].Name != "ratings" || configErrors[0].Reason != "InvalidRegexRewrite" {
+		t.Errorf("got config errors %v, want an InvalidRegexRewrite error for ratings", configErrors)
+	}
+}
+
 func scopeToSidecar(scope *SidecarScope) string {
 	if scope == nil || scope.Config == nil {
 		return ""
@@ -0,0 +1,51 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// RootNamespaces returns the config root namespaces of the mesh, whose configs apply to the workloads of all the
+// namespaces. They are ordered by decreasing precedence: the root namespace of the mesh config comes first, then
+// the additionalRootNamespaces of the mesh config in their configured order.
+func (e *Environment) RootNamespaces() []string {
+	var out []string
+	if e.Mesh != nil && e.Mesh.RootNamespace != "" {
+		out = append(out, e.Mesh.RootNamespace)
+	}
+	for _, ns := range e.MeshExtensions.GetAdditionalRootNamespaces() {
+		if !containsNamespace(out, ns) {
+			out = append(out, ns)
+		}
+	}
+	return out
+}
+
+// IsRootNamespace returns true if the configs of the namespace apply mesh-wide.
+func (e *Environment) IsRootNamespace(namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+	if e.Mesh != nil && e.Mesh.RootNamespace == namespace {
+		return true
+	}
+	return containsNamespace(e.MeshExtensions.GetAdditionalRootNamespaces(), namespace)
+}
+
+func containsNamespace(namespaces []string, namespace string) bool {
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func rootNamespacesEnv(rootNamespace string, additionalRootNamespaces ...string) *Environment {
+	return &Environment{
+		Mesh:           &meshconfig.MeshConfig{RootNamespace: rootNamespace},
+		MeshExtensions: &mesh.Extensions{AdditionalRootNamespaces: additionalRootNamespaces},
+	}
+}
+
+func TestRootNamespaces(t *testing.T) {
+	env := rootNamespacesEnv("istio-system", "security", "istio-system", "platform")
+	if got, want := env.RootNamespaces(), []string{"istio-system", "security", "platform"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got root namespaces %v, want %v", got, want)
+	}
+	for ns, want := range map[string]bool{"istio-system": true, "security": true, "platform": true, "default": false, "": false} {
+		if got := env.IsRootNamespace(ns); got != want {
+			t.Errorf("IsRootNamespace(%q) = %v, want %v", ns, got, want)
+		}
+	}
+
+	env = rootNamespacesEnv("", "security", "istio-system", "platform")
+	if got, want := env.RootNamespaces(), []string{"security", "istio-system", "platform"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got root namespaces %v, want %v", got, want)
+	}
+}
+
+func newDestinationRule(name, namespace, host string) Config {
+	return Config{
+		ConfigMeta: ConfigMeta{
+			Type:      schemas.DestinationRule.Type,
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: &networking.DestinationRule{Host: host, ExportTo: []string{"*"}},
+	}
+}
+
+func TestRootNamespacesDestinationRule(t *testing.T) {
+	ps := NewPushContext()
+	ps.Env = rootNamespacesEnv("istio-system", "security")
+	ps.SetDestinationRules([]Config{
+		newDestinationRule("mesh-reviews", "istio-system", "reviews.default.svc.cluster.local"),
+		newDestinationRule("security-reviews", "security", "reviews.default.svc.cluster.local"),
+		newDestinationRule("security-ratings", "security", "ratings.default.svc.cluster.local"),
+	})
+
+	proxy := &Proxy{ConfigNamespace: "default"}
+	for hostname, want := range map[string]string{
+		"reviews.default.svc.cluster.local": "mesh-reviews",
+		"ratings.default.svc.cluster.local": "security-ratings",
+	} {
+		got := ps.DestinationRule(proxy, &Service{Hostname: host.Name(hostname)})
+		if got == nil || got.Name != want {
+			t.Errorf("got destination rule %v for %s, want %s", got, hostname, want)
+		}
+	}
+}
+
+func TestRootNamespacesEnvoyFilters(t *testing.T) {
+	mesh := &EnvoyFilterWrapper{}
+	security := &EnvoyFilterWrapper{}
+	local := &EnvoyFilterWrapper{}
+	ps := &PushContext{
+		Env: rootNamespacesEnv("istio-system", "security"),
+		envoyFiltersByNamespace: map[string][]*EnvoyFilterWrapper{
+			"istio-system": {mesh},
+			"security":     {security},
+			"default":      {local},
+		},
+	}
+
+	// The patches of the root namespaces with the highest precedence are applied last.
+	checkFilters(t, ps.EnvoyFilters(&Proxy{ConfigNamespace: "default", WorkloadLabels: labels.Collection{}}),
+		security, mesh, local)
+	checkFilters(t, ps.EnvoyFilters(&Proxy{ConfigNamespace: "security", WorkloadLabels: labels.Collection{}}),
+		security, mesh)
+}
+
+func checkFilters(t *testing.T, got []*EnvoyFilterWrapper, want ...*EnvoyFilterWrapper) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d envoy filters, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("envoy filter %d is not the expected one", i)
+		}
+	}
+}
@@ -32,7 +32,6 @@ import (
 	"time"
 
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
-	"github.com/mitchellh/copystructure"
 
 	authn "istio.io/api/authentication/v1alpha1"
 
@@ -129,6 +128,9 @@ const (
 	//
 	// Note: because k8s labels does not support `/`, so we use `.` instead in k8s.
 	LocalityLabel = "istio-locality"
+	// NetworkLabel indicates the network of an instance whose registry does not know it, e.g. a
+	// WorkloadEntry without an explicit network.
+	NetworkLabel = "topology.istio.io/network"
 	// k8s istio-locality label separator
 	k8sSeparator = "."
 )
@@ -376,6 +378,9 @@ type IstioEndpoint struct {
 
 	// TLSMode endpoint is injected with istio sidecar and ready to configure Istio mTLS
 	TLSMode string
+
+	// ClusterID is the cluster (or registry shard) the endpoint was discovered in.
+	ClusterID string
 }
 
 // ServiceAttributes represents a group of custom attributes of the service.
@@ -478,6 +483,12 @@ type ServiceDiscovery interface {
 	GetIstioServiceAccounts(svc *Service, ports []int) []string
 }
 
+// NamespaceDiscovery provides the labels of the namespaces of a registry.
+type NamespaceDiscovery interface {
+	// NamespaceLabels returns the labels of the namespace, or nil if unknown.
+	NamespaceLabels(namespace string) labels.Instance
+}
+
 // Match returns true if port matches with authentication port selector criteria.
 func (port Port) Match(portSelector *authn.PortSelector) bool {
 	if portSelector == nil {
@@ -690,35 +701,72 @@ func GetTLSModeFromEndpointLabels(labels map[string]string) string {
 }
 
 // DeepCopy creates a clone of Service.
-// TODO : See if there is any efficient alternative to this function - copystructure can not be used as is because
-// Service has sync.RWMutex that can not be copied.
 func (s *Service) DeepCopy() *Service {
-	attrs := copyInternal(s.Attributes)
-	ports := copyInternal(s.Ports)
-	accounts := copyInternal(s.ServiceAccounts)
-	clusterVIPs := copyInternal(s.ClusterVIPs)
+	return &Service{
+		Attributes:      s.Attributes.DeepCopy(),
+		Ports:           s.Ports.DeepCopy(),
+		ServiceAccounts: copyStrings(s.ServiceAccounts),
+		CreationTime:    s.CreationTime,
+		Hostname:        s.Hostname,
+		Address:         s.Address,
+		ClusterVIPs:     copyStringMap(s.ClusterVIPs),
+		Resolution:      s.Resolution,
+		MeshExternal:    s.MeshExternal,
+	}
+}
 
+// shallowCopy creates a copy of Service sharing its attributes, ports, accounts and VIPs with the original.
+// It is enough when the copy only differs from the original by the fields replaced in it.
+func (s *Service) shallowCopy() *Service {
 	return &Service{
-		Attributes:      attrs.(ServiceAttributes),
-		Ports:           ports.(PortList),
-		ServiceAccounts: accounts.([]string),
+		Attributes:      s.Attributes,
+		Ports:           s.Ports,
+		ServiceAccounts: s.ServiceAccounts,
 		CreationTime:    s.CreationTime,
 		Hostname:        s.Hostname,
 		Address:         s.Address,
-		ClusterVIPs:     clusterVIPs.(map[string]string),
+		ClusterVIPs:     s.ClusterVIPs,
 		Resolution:      s.Resolution,
 		MeshExternal:    s.MeshExternal,
 	}
 }
 
-func copyInternal(v interface{}) interface{} {
-	copied, err := copystructure.Copy(v)
-	if err != nil {
-		// There are 2 locations where errors are generated in copystructure.Copy:
-		//  * The reflection walk over the structure fails, which should never happen
-		//  * A configurable copy function returns an error. This is only used for copying times, which never returns an error.
-		// Therefore, this should never happen
-		panic(err)
+// DeepCopy creates a clone of ServiceAttributes.
+func (s ServiceAttributes) DeepCopy() ServiceAttributes {
+	out := s
+	if s.ExportTo != nil {
+		out.ExportTo = make(map[visibility.Instance]bool, len(s.ExportTo))
+		for k, v := range s.ExportTo {
+			out.ExportTo[k] = v
+		}
+	}
+	if s.ClusterExternalAddresses != nil {
+		out.ClusterExternalAddresses = make(map[string][]string, len(s.ClusterExternalAddresses))
+		for k, v := range s.ClusterExternalAddresses {
+			out.ClusterExternalAddresses[k] = copyStrings(v)
+		}
+	}
+	return out
+}
+
+// DeepCopy creates a clone of PortList.
+func (ports PortList) DeepCopy() PortList {
+	if ports == nil {
+		return nil
+	}
+	out := make(PortList, len(ports))
+	for i, port := range ports {
+		if port != nil {
+			p := *port
+			out[i] = &p
+		}
+	}
+	return out
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
 	}
-	return copied
+	return append(make([]string, 0, len(in)), in...)
 }
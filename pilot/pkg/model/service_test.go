@@ -15,10 +15,14 @@
 package model
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/visibility"
 )
 
 var validServiceKeys = map[string]struct {
@@ -263,3 +267,37 @@ func TestGetLocality(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceDeepCopy(t *testing.T) {
+	svc := &Service{
+		Attributes: ServiceAttributes{
+			Name:                     "reviews",
+			Namespace:                "default",
+			ExportTo:                 map[visibility.Instance]bool{visibility.Public: true},
+			ClusterExternalAddresses: map[string][]string{"cluster-1": {"1.1.1.1"}},
+		},
+		Ports:           PortList{{Name: "http", Port: 80, Protocol: protocol.HTTP}},
+		ServiceAccounts: []string{"spiffe://cluster.local/ns/default/sa/reviews"},
+		CreationTime:    time.Now(),
+		Hostname:        "reviews.default.svc.cluster.local",
+		Address:         "10.0.0.1",
+		ClusterVIPs:     map[string]string{"cluster-1": "10.0.0.1"},
+		Resolution:      DNSLB,
+		MeshExternal:    true,
+	}
+
+	copied := svc.DeepCopy()
+	if !reflect.DeepEqual(copied, svc) {
+		t.Fatalf("got copy %+v, want %+v", copied, svc)
+	}
+
+	copied.Attributes.ExportTo[visibility.Private] = true
+	copied.Attributes.ClusterExternalAddresses["cluster-1"][0] = "2.2.2.2"
+	copied.Ports[0].Port = 8080
+	copied.ServiceAccounts[0] = "changed"
+	copied.ClusterVIPs["cluster-2"] = "10.0.0.2"
+	if svc.Attributes.ExportTo[visibility.Private] || svc.Attributes.ClusterExternalAddresses["cluster-1"][0] != "1.1.1.1" ||
+		svc.Ports[0].Port != 80 || svc.ServiceAccounts[0] == "changed" || len(svc.ClusterVIPs) != 1 {
+		t.Errorf("changing the copy changed the original service: %+v", svc)
+	}
+}
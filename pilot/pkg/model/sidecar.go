@@ -15,6 +15,7 @@
 package model
 
 import (
+	"strconv"
 	"strings"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
@@ -29,6 +30,9 @@ const (
 	wildcardNamespace = "*"
 	currentNamespace  = "."
 	wildcardService   = host.Name("*")
+	// wildcardEgressListener designates the egress listener without port in the
+	// egressOutboundTrafficPolicy annotation.
+	wildcardEgressListener = "*"
 )
 
 // SidecarScope is a wrapper over the Sidecar resource with some
@@ -134,6 +138,12 @@ type IstioEgressListenerWrapper struct {
 	// a private virtual service for serviceA from the local namespace,
 	// with a different path rewrite or no path rewrites.
 	virtualServices []Config
+
+	// OutboundTrafficPolicy defines the outbound traffic policy of this
+	// listener. It is the one of the sidecar scope, unless overridden for
+	// the listener by the networking.istio.io/egressOutboundTrafficPolicy
+	// annotation of the Sidecar.
+	OutboundTrafficPolicy *networking.OutboundTrafficPolicy
 }
 
 func createNamespaceForHostname(egress []*IstioEgressListenerWrapper) map[host.Name]string {
@@ -185,6 +195,7 @@ func DefaultSidecarScopeForNamespace(ps *PushContext, configNamespace string) *S
 			Mode: networking.OutboundTrafficPolicy_Mode(ps.Env.Mesh.OutboundTrafficPolicy.Mode),
 		}
 	}
+	defaultEgressListener.OutboundTrafficPolicy = out.OutboundTrafficPolicy
 
 	return out
 }
@@ -258,6 +269,14 @@ func ConvertToSidecarScope(ps *PushContext, sidecarConfig *Config, configNamespa
 		out.OutboundTrafficPolicy = r.OutboundTrafficPolicy
 	}
 
+	listenerPolicies := parseEgressOutboundTrafficPolicies(sidecarConfig)
+	for _, listener := range out.EgressListeners {
+		listener.OutboundTrafficPolicy = out.OutboundTrafficPolicy
+		if policy, f := listenerPolicies[egressListenerKey(listener.IstioListener)]; f {
+			listener.OutboundTrafficPolicy = policy
+		}
+	}
+
 	out.Config = sidecarConfig
 	if len(r.Ingress) > 0 {
 		out.HasCustomIngressListeners = true
@@ -266,6 +285,46 @@ func ConvertToSidecarScope(ps *PushContext, sidecarConfig *Config, configNamespa
 	return out
 }
 
+// parseEgressOutboundTrafficPolicies returns the outbound traffic policies of the egress listeners of the
+// Sidecar, indexed by port, set by its networking.istio.io/egressOutboundTrafficPolicy annotation in the form
+// 9080=REGISTRY_ONLY,*=ALLOW_ANY, where "*" designates the egress listener without port. Invalid entries
+// are ignored.
+func parseEgressOutboundTrafficPolicies(sidecarConfig *Config) map[string]*networking.OutboundTrafficPolicy {
+	value := sidecarConfig.Annotations[constants.SidecarEgressOutboundTrafficPolicyAnnotation]
+	if value == "" {
+		return nil
+	}
+	out := make(map[string]*networking.OutboundTrafficPolicy)
+	for _, entry := range strings.Split(value, ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 {
+			log.Warnf("Ignoring invalid outbound traffic policy %q of sidecar %s/%s", entry, sidecarConfig.Namespace, sidecarConfig.Name)
+			continue
+		}
+		listener, mode := strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])
+		if _, err := strconv.ParseUint(listener, 10, 32); err != nil && listener != wildcardEgressListener {
+			log.Warnf("Ignoring outbound traffic policy of invalid egress listener %q of sidecar %s/%s",
+				listener, sidecarConfig.Namespace, sidecarConfig.Name)
+			continue
+		}
+		m, f := networking.OutboundTrafficPolicy_Mode_value[mode]
+		if !f {
+			log.Warnf("Ignoring invalid outbound traffic policy mode %q of sidecar %s/%s", mode, sidecarConfig.Namespace, sidecarConfig.Name)
+			continue
+		}
+		out[listener] = &networking.OutboundTrafficPolicy{Mode: networking.OutboundTrafficPolicy_Mode(m)}
+	}
+	return out
+}
+
+// egressListenerKey returns the key of the egress listener in the egressOutboundTrafficPolicy annotation.
+func egressListenerKey(istioListener *networking.IstioEgressListener) string {
+	if istioListener == nil || istioListener.Port == nil {
+		return wildcardEgressListener
+	}
+	return strconv.Itoa(int(istioListener.Port.Number))
+}
+
 func convertIstioListenerToWrapper(ps *PushContext, configNamespace string,
 	istioListener *networking.IstioEgressListener) *IstioEgressListenerWrapper {
 
@@ -507,10 +566,9 @@ func (ilw *IstioEgressListenerWrapper) selectServices(services []*Service, confi
 					if portMatched {
 						for _, port := range s.Ports {
 							if port.Port == int(ilw.IstioListener.Port.GetNumber()) {
-								ports := []*Port{}
-								sc := s.DeepCopy()
-								ports = append(ports, port)
-								sc.Ports = ports
+								// Only the ports differ, the rest of the service is shared.
+								sc := s.shallowCopy()
+								sc.Ports = PortList{port}
 								importedServices = append(importedServices, sc)
 								hostFound = true
 								break
@@ -0,0 +1,275 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"hash"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// sidecarScopeCache holds the sidecar scopes computed for a push context, so that the next push context reuses
+// the scopes whose Sidecar resource and imported configs did not change instead of computing them again.
+// The configs imported by a scope are identified by the hashes of the namespaces it imports: the hash of a
+// namespace covers its services, its virtual services and the destination rules that apply to its services.
+// The scopes also depend on the mesh config, e.g. its outbound traffic policy and root namespaces: none of them is
+// reused once it changes.
+type sidecarScopeCache struct {
+	// meshConfigHash is the hash of the mesh config and its extensions.
+	meshConfigHash uint64
+	// namespaceHashes are the hashes of the configs imported from each namespace.
+	namespaceHashes map[string]uint64
+	// meshHash is the hash of the configs of all the namespaces, imported by the scopes with wildcard imports.
+	meshHash uint64
+	scopes   map[sidecarScopeKey]cachedSidecarScope
+}
+
+// sidecarScopeKey identifies a sidecar scope by its config namespace and the version of its Sidecar resource.
+type sidecarScopeKey struct {
+	configNamespace string
+	// sidecar is empty for the default sidecar scopes.
+	sidecar string
+}
+
+type cachedSidecarScope struct {
+	scope *SidecarScope
+	// importHash is the hash of the configs imported by the scope when it was computed.
+	importHash uint64
+}
+
+// newSidecarScopeCache hashes the configs of each namespace of the push context, whose services, virtual services
+// and destination rules must be initialized.
+func newSidecarScopeCache(ps *PushContext, env *Environment) (*sidecarScopeCache, error) {
+	c := &sidecarScopeCache{
+		namespaceHashes: make(map[string]uint64),
+		scopes:          make(map[sidecarScopeKey]cachedSidecarScope),
+	}
+	var err error
+	if c.meshConfigHash, err = meshConfigHash(env); err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	// The hashes of the configs of a namespace are summed, so that they do not depend on the order of the configs.
+	add := func(namespace string, write func(h hash.Hash64)) {
+		h.Reset()
+		write(h)
+		c.namespaceHashes[namespace] += h.Sum64()
+	}
+
+	for _, services := range ps.ServiceByHostnameAndNamespace {
+		for ns, svc := range services {
+			if svc != nil {
+				add(ns, svc.hash)
+			}
+		}
+	}
+
+	for i := range ps.publicVirtualServices {
+		vs := &ps.publicVirtualServices[i]
+		add(vs.Namespace, vs.hashVersion)
+	}
+	for _, configs := range ps.privateVirtualServicesByNamespace {
+		for i := range configs {
+			vs := &configs[i]
+			add(vs.Namespace, vs.hashVersion)
+		}
+	}
+
+	// A destination rule is imported from the namespaces of the services it applies to.
+	destinationRules, err := env.List(schemas.DestinationRule.Type, NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	for i := range destinationRules {
+		dr := &destinationRules[i]
+		drHost := ResolveShortnameToFQDN(dr.Spec.(*networking.DestinationRule).Host, dr.ConfigMeta)
+		namespaces := make(map[string]struct{})
+		if strings.HasPrefix(string(drHost), "*") {
+			for hostname, services := range ps.ServiceByHostnameAndNamespace {
+				if drHost.Matches(hostname) {
+					for ns := range services {
+						namespaces[ns] = struct{}{}
+					}
+				}
+			}
+		} else {
+			for ns := range ps.ServiceByHostnameAndNamespace[drHost] {
+				namespaces[ns] = struct{}{}
+			}
+		}
+		for ns := range namespaces {
+			add(ns, dr.hashVersion)
+		}
+	}
+
+	namespaces := make([]string, 0, len(c.namespaceHashes))
+	for ns := range c.namespaceHashes {
+		namespaces = append(namespaces, ns)
+	}
+	c.meshHash = c.importHash(namespaces)
+
+	return c, nil
+}
+
+// meshConfigHash returns the hash of the mesh config and its extensions.
+func meshConfigHash(env *Environment) (uint64, error) {
+	h := fnv.New64a()
+	if env.Mesh != nil {
+		_, _ = h.Write([]byte(env.Mesh.String()))
+	}
+	extensions, err := json.Marshal(env.MeshExtensions)
+	if err != nil {
+		return 0, err
+	}
+	_, _ = h.Write(extensions)
+	return h.Sum64(), nil
+}
+
+// meshConfigChanged returns whether the mesh config changed since the cache was built.
+func (c *sidecarScopeCache) meshConfigChanged(env *Environment) (bool, error) {
+	if c == nil {
+		return true, nil
+	}
+	hash, err := meshConfigHash(env)
+	if err != nil {
+		return false, err
+	}
+	return hash != c.meshConfigHash, nil
+}
+
+// importHash returns the hash of the configs imported from the namespaces.
+func (c *sidecarScopeCache) importHash(namespaces []string) uint64 {
+	sort.Strings(namespaces)
+	h := fnv.New64a()
+	for _, ns := range namespaces {
+		_, _ = h.Write([]byte(ns))
+		_, _ = h.Write([]byte(strconv.FormatUint(c.namespaceHashes[ns], 16)))
+	}
+	return h.Sum64()
+}
+
+// sidecarScope returns the sidecar scope of the Sidecar resource, nil for the default sidecar scope, in the config
+// namespace. The scope is taken from the cache, or from the cache of the old push context if neither the configs it
+// imports nor the mesh config changed, and is computed otherwise.
+func (c *sidecarScopeCache) sidecarScope(ps *PushContext, old *sidecarScopeCache,
+	sidecarConfig *Config, configNamespace string) *SidecarScope {
+	key := sidecarScopeKey{configNamespace: configNamespace}
+	var importHash uint64
+	if sidecarConfig == nil {
+		importHash = c.meshHash
+	} else {
+		key.sidecar = sidecarConfig.Namespace + "/" + sidecarConfig.Name + "@" + sidecarConfig.ResourceVersion
+		if namespaces, all := importedNamespaces(sidecarConfig, configNamespace); all {
+			importHash = c.meshHash
+		} else {
+			importHash = c.importHash(namespaces)
+		}
+	}
+
+	if cached, f := c.scopes[key]; f {
+		return cached.scope
+	}
+	cached, f := cachedSidecarScope{}, false
+	if old != nil && old.meshConfigHash == c.meshConfigHash {
+		cached, f = old.scopes[key]
+	}
+	if !f || cached.importHash != importHash {
+		cached = cachedSidecarScope{
+			scope:      ConvertToSidecarScope(ps, sidecarConfig, configNamespace),
+			importHash: importHash,
+		}
+	}
+	c.scopes[key] = cached
+	return cached.scope
+}
+
+// importedNamespaces returns the namespaces the egress listeners of the Sidecar resource import configs from,
+// or whether they import the configs of all the namespaces.
+func importedNamespaces(sidecarConfig *Config, configNamespace string) ([]string, bool) {
+	seen := make(map[string]struct{})
+	namespaces := make([]string, 0)
+	for _, egress := range sidecarConfig.Spec.(*networking.Sidecar).Egress {
+		for _, h := range egress.Hosts {
+			ns := strings.SplitN(h, "/", 2)[0]
+			switch ns {
+			case wildcardNamespace:
+				return nil, true
+			case currentNamespace:
+				ns = configNamespace
+			}
+			if _, f := seen[ns]; !f {
+				seen[ns] = struct{}{}
+				namespaces = append(namespaces, ns)
+			}
+		}
+	}
+	return namespaces, false
+}
+
+// hashVersion writes the identity and the version of the config to the hash.
+func (c *Config) hashVersion(h hash.Hash64) {
+	for _, s := range []string{c.Type, c.Namespace, c.Name, c.ResourceVersion} {
+		_, _ = h.Write([]byte(s))
+		_, _ = h.Write([]byte{0})
+	}
+}
+
+// hash writes the fields of the service used by the sidecar scopes and the generation of the proxy config to the
+// hash.
+func (s *Service) hash(h hash.Hash64) {
+	write := func(values ...string) {
+		for _, v := range values {
+			_, _ = h.Write([]byte(v))
+			_, _ = h.Write([]byte{0})
+		}
+	}
+	write(string(s.Hostname), s.Address, s.Resolution.String(), strconv.FormatBool(s.MeshExternal),
+		s.CreationTime.String(), s.Attributes.ServiceRegistry, s.Attributes.Name, s.Attributes.Namespace, s.Attributes.UID)
+	for _, p := range s.Ports {
+		write(p.Name, strconv.Itoa(p.Port), string(p.Protocol))
+	}
+	clusters := make([]string, 0, len(s.ClusterVIPs))
+	for cluster := range s.ClusterVIPs {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		write(cluster, s.ClusterVIPs[cluster])
+	}
+	exportTo := make([]string, 0, len(s.Attributes.ExportTo))
+	for e := range s.Attributes.ExportTo {
+		exportTo = append(exportTo, string(e))
+	}
+	sort.Strings(exportTo)
+	write(exportTo...)
+	clusters = clusters[:0]
+	for cluster := range s.Attributes.ClusterExternalAddresses {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		write(cluster)
+		write(s.Attributes.ClusterExternalAddresses[cluster]...)
+	}
+	write(s.ServiceAccounts...)
+}
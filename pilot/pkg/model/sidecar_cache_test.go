@@ -0,0 +1,220 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func newSidecarCacheService(hostname, namespace string, port int) *Service {
+	return &Service{
+		Hostname:   host.Name(hostname),
+		Ports:      PortList{{Name: "http", Port: port, Protocol: protocol.HTTP}},
+		Attributes: ServiceAttributes{Name: hostname, Namespace: namespace},
+	}
+}
+
+func newSidecarCacheConfig(typ, name, namespace, version string, spec proto.Message) Config {
+	return Config{
+		ConfigMeta: ConfigMeta{Type: typ, Name: name, Namespace: namespace, ResourceVersion: version},
+		Spec:       spec,
+	}
+}
+
+// newSidecarCachePush builds the sidecar scopes of a push context with the services and configs, reusing the
+// sidecar scopes of the old push context. The mesh config is modified by meshFn if set.
+func newSidecarCachePush(t *testing.T, services []*Service, configs []Config, meshFn func(*Environment), old *PushContext) *PushContext {
+	t.Helper()
+	configStore := newFakeStore()
+	for _, c := range configs {
+		_, _ = configStore.Create(c)
+	}
+	env := &Environment{
+		Mesh:             &meshconfig.MeshConfig{RootNamespace: "istio-system"},
+		IstioConfigStore: &istioConfigStore{ConfigStore: configStore},
+	}
+	if meshFn != nil {
+		meshFn(env)
+	}
+	ps := NewPushContext()
+	ps.Env = env
+	ps.initDefaultExportMaps()
+	for _, s := range services {
+		ps.publicServices = append(ps.publicServices, s)
+		ps.ServiceByHostnameAndNamespace[s.Hostname] = map[string]*Service{s.Attributes.Namespace: s}
+	}
+	if err := ps.initVirtualServices(env); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.initDestinationRules(env); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.updateSidecarScopes(env, old); err != nil {
+		t.Fatal(err)
+	}
+	return ps
+}
+
+func TestSidecarScopeCache(t *testing.T) {
+	baseServices := func() []*Service {
+		return []*Service{
+			newSidecarCacheService("reviews.ns1.svc.cluster.local", "ns1", 80),
+			newSidecarCacheService("ratings.ns2.svc.cluster.local", "ns2", 80),
+			newSidecarCacheService("details.ns3.svc.cluster.local", "ns3", 80),
+		}
+	}
+	baseConfigs := func() []Config {
+		return []Config{
+			// ns1 imports its own configs, ns2 imports the configs of ns1, ns3 imports all the configs.
+			newSidecarCacheConfig(schemas.Sidecar.Type, "default", "ns1", "1", &networking.Sidecar{
+				Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*"}}},
+			}),
+			newSidecarCacheConfig(schemas.Sidecar.Type, "default", "ns2", "1", &networking.Sidecar{
+				Egress: []*networking.IstioEgressListener{{Hosts: []string{"ns1/*"}}},
+			}),
+		}
+	}
+
+	cases := []struct {
+		name     string
+		services func([]*Service) []*Service
+		configs  func([]Config) []Config
+		mesh     func(*Environment)
+		rebuilt  map[string]bool
+	}{
+		{
+			name:    "no change",
+			rebuilt: map[string]bool{"ns1": false, "ns2": false, "ns3": false},
+		},
+		{
+			name: "service added to a namespace not imported",
+			services: func(services []*Service) []*Service {
+				return append(services, newSidecarCacheService("productpage.ns2.svc.cluster.local", "ns2", 9080))
+			},
+			rebuilt: map[string]bool{"ns1": false, "ns2": false, "ns3": true},
+		},
+		{
+			name: "imported service changed",
+			services: func(services []*Service) []*Service {
+				services[0] = newSidecarCacheService("reviews.ns1.svc.cluster.local", "ns1", 8080)
+				return services
+			},
+			rebuilt: map[string]bool{"ns1": true, "ns2": true, "ns3": true},
+		},
+		{
+			name: "destination rule for an imported service added to another namespace",
+			configs: func(configs []Config) []Config {
+				return append(configs, newSidecarCacheConfig(schemas.DestinationRule.Type, "reviews", "ns3", "1",
+					&networking.DestinationRule{Host: "reviews.ns1.svc.cluster.local"}))
+			},
+			rebuilt: map[string]bool{"ns1": true, "ns2": true, "ns3": true},
+		},
+		{
+			name: "sidecar updated",
+			configs: func(configs []Config) []Config {
+				configs[1].ResourceVersion = "2"
+				return configs
+			},
+			rebuilt: map[string]bool{"ns1": false, "ns2": true, "ns3": false},
+		},
+		{
+			name: "root namespace sidecar added",
+			configs: func(configs []Config) []Config {
+				return append(configs, newSidecarCacheConfig(schemas.Sidecar.Type, "default", "istio-system", "1",
+					&networking.Sidecar{Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*"}}}}))
+			},
+			rebuilt: map[string]bool{"ns1": false, "ns2": false, "ns3": true},
+		},
+		{
+			name: "outbound traffic policy changed",
+			mesh: func(env *Environment) {
+				env.Mesh.OutboundTrafficPolicy = &meshconfig.MeshConfig_OutboundTrafficPolicy{
+					Mode: meshconfig.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY,
+				}
+			},
+			rebuilt: map[string]bool{"ns1": true, "ns2": true, "ns3": true},
+		},
+		{
+			name: "additional root namespace added",
+			mesh: func(env *Environment) {
+				env.MeshExtensions = &mesh.Extensions{AdditionalRootNamespaces: []string{"ns3"}}
+			},
+			rebuilt: map[string]bool{"ns1": true, "ns2": true, "ns3": true},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old := newSidecarCachePush(t, baseServices(), baseConfigs(), nil, nil)
+
+			services, configs := baseServices(), baseConfigs()
+			if c.services != nil {
+				services = c.services(services)
+			}
+			if c.configs != nil {
+				configs = c.configs(configs)
+			}
+			push := newSidecarCachePush(t, services, configs, c.mesh, old)
+
+			for ns, rebuilt := range c.rebuilt {
+				if got := push.sidecarsByNamespace[ns][0] != old.sidecarsByNamespace[ns][0]; got != rebuilt {
+					t.Errorf("sidecar scope of %s rebuilt: %v, want %v", ns, got, rebuilt)
+				}
+			}
+		})
+	}
+}
+
+func TestSidecarScopeCacheMeshConfigChanged(t *testing.T) {
+	old := newSidecarCachePush(t, []*Service{newSidecarCacheService("reviews.ns1.svc.cluster.local", "ns1", 80)},
+		[]Config{newSidecarCacheConfig(schemas.Sidecar.Type, "default", "ns1", "1", &networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{{Hosts: []string{"./*"}}},
+		})}, nil, nil)
+	old.initDone = true
+
+	// The mesh config is reloaded while a push not affecting the sidecar scopes is pending.
+	env := old.Env
+	env.Mesh = &meshconfig.MeshConfig{
+		RootNamespace: "istio-system",
+		OutboundTrafficPolicy: &meshconfig.MeshConfig_OutboundTrafficPolicy{
+			Mode: meshconfig.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY,
+		},
+	}
+	push := NewPushContext()
+	if err := push.InitContext(env, old, &PushRequest{
+		Full:               true,
+		ConfigTypesUpdated: map[string]struct{}{schemas.EnvoyFilter.Type: {}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	scope := push.sidecarsByNamespace["ns1"][0]
+	if scope == old.sidecarsByNamespace["ns1"][0] {
+		t.Fatalf("sidecar scope of ns1 not rebuilt")
+	}
+	if got := scope.OutboundTrafficPolicy.GetMode(); got != networking.OutboundTrafficPolicy_REGISTRY_ONLY {
+		t.Errorf("got outbound traffic policy %v, want %v", got, networking.OutboundTrafficPolicy_REGISTRY_ONLY)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
 
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/mesh"
 )
@@ -928,3 +929,68 @@ outboundTrafficPolicy:
 		})
 	}
 }
+
+func TestEgressListenerOutboundTrafficPolicy(t *testing.T) {
+	ps := NewPushContext()
+	m := mesh.DefaultMeshConfig()
+	ps.Env = &Environment{
+		Mesh: &m,
+	}
+
+	sidecar := &Config{
+		ConfigMeta: ConfigMeta{
+			Name:      "foo",
+			Namespace: "payments",
+			Annotations: map[string]string{
+				constants.SidecarEgressOutboundTrafficPolicyAnnotation: "9080=REGISTRY_ONLY, 8080=BLOCK,foo=ALLOW_ANY,9090",
+			},
+		},
+		Spec: &networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{
+				{
+					Port:  &networking.Port{Number: 9080, Protocol: "HTTP", Name: "http"},
+					Hosts: []string{"payments/*"},
+				},
+				{
+					Port:  &networking.Port{Number: 8080, Protocol: "HTTP", Name: "http-alt"},
+					Hosts: []string{"payments/*"},
+				},
+				{
+					Hosts: []string{"*/*"},
+				},
+			},
+			OutboundTrafficPolicy: &networking.OutboundTrafficPolicy{
+				Mode: networking.OutboundTrafficPolicy_ALLOW_ANY,
+			},
+		},
+	}
+
+	expected := []networking.OutboundTrafficPolicy_Mode{
+		networking.OutboundTrafficPolicy_REGISTRY_ONLY,
+		networking.OutboundTrafficPolicy_ALLOW_ANY,
+		networking.OutboundTrafficPolicy_ALLOW_ANY,
+	}
+	sidecarScope := ConvertToSidecarScope(ps, sidecar, sidecar.Namespace)
+	for i, listener := range sidecarScope.EgressListeners {
+		if listener.OutboundTrafficPolicy.Mode != expected[i] {
+			t.Errorf("Unexpected outbound traffic policy of egress listener %d, want %v, found %v",
+				i, expected[i], listener.OutboundTrafficPolicy.Mode)
+		}
+	}
+
+	// The catch all egress listener can be locked down, keeping the passthrough of the other ones.
+	sidecar.Annotations[constants.SidecarEgressOutboundTrafficPolicyAnnotation] = "*=REGISTRY_ONLY"
+	sidecarScope = ConvertToSidecarScope(ps, sidecar, sidecar.Namespace)
+	if mode := sidecarScope.EgressListeners[0].OutboundTrafficPolicy.Mode; mode != networking.OutboundTrafficPolicy_ALLOW_ANY {
+		t.Errorf("Unexpected outbound traffic policy of the port egress listener, want ALLOW_ANY, found %v", mode)
+	}
+	if mode := sidecarScope.EgressListeners[2].OutboundTrafficPolicy.Mode; mode != networking.OutboundTrafficPolicy_REGISTRY_ONLY {
+		t.Errorf("Unexpected outbound traffic policy of the catch all egress listener, want REGISTRY_ONLY, found %v", mode)
+	}
+
+	defaultScope := DefaultSidecarScopeForNamespace(ps, "default")
+	if !reflect.DeepEqual(defaultScope.EgressListeners[0].OutboundTrafficPolicy, defaultScope.OutboundTrafficPolicy) {
+		t.Errorf("Unexpected outbound traffic policy of the default egress listener, want %v, found %v",
+			defaultScope.OutboundTrafficPolicy, defaultScope.EgressListeners[0].OutboundTrafficPolicy)
+	}
+}
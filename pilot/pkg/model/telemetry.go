@@ -0,0 +1,234 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/labels"
+)
+
+const (
+	// defaultAccessLogFile is where access logs are written when a Telemetry resource turns on
+	// the envoy access log provider and the mesh does not configure an access log file.
+	defaultAccessLogFile = "/dev/stdout"
+)
+
+// TelemetryWrapper is a wrapper for the Telemetry api object with pre-processed data
+type TelemetryWrapper struct {
+	workloadSelector labels.Instance
+	Spec             *telemetry.Telemetry
+}
+
+// ProxyTelemetry is the telemetry configuration in effect for a proxy, obtained by combining the
+// Telemetry resources of the root namespace, the proxy namespace and the proxy workload. A nil
+// section means that no Telemetry resource configures it and the mesh defaults apply.
+type ProxyTelemetry struct {
+	Tracing       *telemetry.Tracing
+	Metrics       *telemetry.Metrics
+	AccessLogging *telemetry.AccessLogging
+}
+
+// convertToTelemetryWrapper converts from Telemetry config to TelemetryWrapper object
+func convertToTelemetryWrapper(local *Config) *TelemetryWrapper {
+	spec := local.Spec.(*telemetry.Telemetry)
+
+	out := &TelemetryWrapper{Spec: spec}
+	if spec.Selector != nil && len(spec.Selector.MatchLabels) > 0 {
+		out.workloadSelector = spec.Selector.MatchLabels
+	}
+	return out
+}
+
+// merge overlays the sections of spec that are set on top of t.
+func (t *ProxyTelemetry) merge(spec *telemetry.Telemetry) {
+	if in := spec.Tracing; in != nil {
+		out := &telemetry.Tracing{}
+		if t.Tracing != nil {
+			*out = *t.Tracing
+		}
+		if len(in.Providers) > 0 {
+			// selecting providers turns the section back on unless it is explicitly disabled again
+			out.Providers = in.Providers
+			out.DisableSpanReporting = nil
+		}
+		if in.RandomSamplingPercentage != nil {
+			out.RandomSamplingPercentage = in.RandomSamplingPercentage
+		}
+		if in.DisableSpanReporting != nil {
+			out.DisableSpanReporting = in.DisableSpanReporting
+		}
+		if len(in.RequestHeadersForTags) > 0 {
+			out.RequestHeadersForTags = in.RequestHeadersForTags
+		}
+		if len(in.CustomTags) > 0 {
+			out.CustomTags = in.CustomTags
+		}
+		t.Tracing = out
+	}
+
+	if in := spec.Metrics; in != nil {
+		out := &telemetry.Metrics{}
+		if t.Metrics != nil {
+			*out = *t.Metrics
+		}
+		if len(in.Providers) > 0 {
+			out.Providers = in.Providers
+			out.Disabled = nil
+		}
+		if in.Disabled != nil {
+			out.Disabled = in.Disabled
+		}
+		t.Metrics = out
+	}
+
+	if in := spec.AccessLogging; in != nil {
+		out := &telemetry.AccessLogging{}
+		if t.AccessLogging != nil {
+			*out = *t.AccessLogging
+		}
+		if len(in.Providers) > 0 {
+			out.Providers = in.Providers
+			out.Disabled = nil
+		}
+		if in.Disabled != nil {
+			out.Disabled = in.Disabled
+		}
+		if in.Filter != nil {
+			out.Filter = in.Filter
+		}
+		t.AccessLogging = out
+	}
+}
+
+// AccessLogFile returns the path of the file access log for the proxy, or an empty string if the
+// file access log is turned off.
+func (t *ProxyTelemetry) AccessLogFile(mesh *meshconfig.MeshConfig) string {
+	if t == nil || t.AccessLogging == nil {
+		return mesh.AccessLogFile
+	}
+	al := t.AccessLogging
+	if al.Disabled.GetValue() {
+		return ""
+	}
+	if len(al.Providers) == 0 && al.Disabled == nil {
+		return mesh.AccessLogFile
+	}
+	if len(al.Providers) > 0 && !hasTelemetryProvider(al.Providers, constants.TelemetryProviderEnvoy) {
+		return ""
+	}
+	if mesh.AccessLogFile == "" {
+		return defaultAccessLogFile
+	}
+	return mesh.AccessLogFile
+}
+
+// AccessLogServiceEnabled returns true if the proxy should stream access logs to the gRPC access
+// log service configured in its bootstrap.
+func (t *ProxyTelemetry) AccessLogServiceEnabled(mesh *meshconfig.MeshConfig) bool {
+	if t == nil || t.AccessLogging == nil {
+		return mesh.EnableEnvoyAccessLogService
+	}
+	al := t.AccessLogging
+	if al.Disabled.GetValue() {
+		return false
+	}
+	if len(al.Providers) > 0 {
+		return hasTelemetryProvider(al.Providers, constants.TelemetryProviderEnvoyALS)
+	}
+	return mesh.EnableEnvoyAccessLogService
+}
+
+// AccessLogFilter returns the filter restricting the access logs of the proxy, or nil if every
+// request is logged.
+func (t *ProxyTelemetry) AccessLogFilter() *telemetry.AccessLogFilter {
+	if t == nil || t.AccessLogging == nil {
+		return nil
+	}
+	return t.AccessLogging.Filter
+}
+
+// TracingEnabled returns true if the proxy should generate spans.
+func (t *ProxyTelemetry) TracingEnabled(mesh *meshconfig.MeshConfig) bool {
+	if t == nil || t.Tracing == nil {
+		return mesh.EnableTracing
+	}
+	tr := t.Tracing
+	if tr.DisableSpanReporting.GetValue() {
+		return false
+	}
+	if len(tr.Providers) > 0 {
+		return hasTelemetryProvider(tr.Providers, constants.TelemetryProviderEnvoy)
+	}
+	if tr.DisableSpanReporting != nil || tr.RandomSamplingPercentage != nil {
+		return true
+	}
+	return mesh.EnableTracing
+}
+
+// TracingRandomSampling returns the percentage of requests the proxy should trace, falling back to
+// defaultSampling if no Telemetry resource overrides it.
+func (t *ProxyTelemetry) TracingRandomSampling(defaultSampling float64) float64 {
+	if t == nil || t.Tracing == nil || t.Tracing.RandomSamplingPercentage == nil {
+		return defaultSampling
+	}
+	return t.Tracing.RandomSamplingPercentage.Value
+}
+
+// TracingRequestHeadersForTags returns the request headers that are added to spans as tags.
+func (t *ProxyTelemetry) TracingRequestHeadersForTags() []string {
+	if t == nil || t.Tracing == nil {
+		return nil
+	}
+	return t.Tracing.RequestHeadersForTags
+}
+
+// TracingCustomTags returns the literal and environment tags that are added to spans.
+func (t *ProxyTelemetry) TracingCustomTags() []*telemetry.CustomTag {
+	if t == nil || t.Tracing == nil {
+		return nil
+	}
+	return t.Tracing.CustomTags
+}
+
+// MetricsEnabled returns true if the proxy should report metrics to the given provider, falling
+// back to defaultEnabled if no Telemetry resource configures metrics.
+func (t *ProxyTelemetry) MetricsEnabled(provider string, defaultEnabled bool) bool {
+	if t == nil || t.Metrics == nil {
+		return defaultEnabled
+	}
+	m := t.Metrics
+	if m.Disabled.GetValue() {
+		return false
+	}
+	if len(m.Providers) > 0 {
+		return hasTelemetryProvider(m.Providers, provider)
+	}
+	if m.Disabled != nil {
+		return true
+	}
+	return defaultEnabled
+}
+
+func hasTelemetryProvider(providers []*telemetry.ProviderRef, name string) bool {
+	for _, p := range providers {
+		if p.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
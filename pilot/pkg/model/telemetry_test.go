@@ -0,0 +1,248 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/api/type/v1beta1"
+
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func newTelemetryConfig(name, namespace string, created time.Time, spec *telemetry.Telemetry) Config {
+	return Config{
+		ConfigMeta: ConfigMeta{
+			Type:              schemas.Telemetry.Type,
+			Group:             schemas.Telemetry.Group,
+			Version:           schemas.Telemetry.Version,
+			Name:              name,
+			Namespace:         namespace,
+			CreationTimestamp: created,
+		},
+		Spec: spec,
+	}
+}
+
+func TestTelemetry(t *testing.T) {
+	now := time.Now()
+	configStore := newFakeStore()
+	for _, cfg := range []Config{
+		newTelemetryConfig("mesh", "istio-system", now, &telemetry.Telemetry{
+			Tracing: &telemetry.Tracing{
+				RandomSamplingPercentage: &types.DoubleValue{Value: 1},
+				RequestHeadersForTags:    []string{"x-tenant-id"},
+				CustomTags:               []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme"}},
+			},
+			AccessLogging: &telemetry.AccessLogging{
+				Disabled: &types.BoolValue{Value: true},
+				Filter:   &telemetry.AccessLogFilter{MinStatusCode: 400},
+			},
+		}),
+		newTelemetryConfig("namespace", "bookinfo", now, &telemetry.Telemetry{
+			Metrics: &telemetry.Metrics{
+				Disabled: &types.BoolValue{Value: true},
+			},
+			AccessLogging: &telemetry.AccessLogging{
+				Filter: &telemetry.AccessLogFilter{MinStatusCode: 500},
+			},
+		}),
+		newTelemetryConfig("newer-namespace", "bookinfo", now.Add(time.Second), &telemetry.Telemetry{
+			Metrics: &telemetry.Metrics{
+				Disabled: &types.BoolValue{Value: false},
+			},
+		}),
+		newTelemetryConfig("reviews", "bookinfo", now, &telemetry.Telemetry{
+			Selector: &v1beta1.WorkloadSelector{
+				MatchLabels: map[string]string{"app": "reviews"},
+			},
+			Tracing: &telemetry.Tracing{
+				RandomSamplingPercentage: &types.DoubleValue{Value: 100},
+				RequestHeadersForTags:    []string{"x-tenant-id", "x-build-id"},
+				CustomTags:               []*telemetry.CustomTag{{Tag: "build", Environment: "BUILD_ID"}},
+			},
+			AccessLogging: &telemetry.AccessLogging{
+				Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoy}},
+			},
+		}),
+	} {
+		_, _ = configStore.Create(cfg)
+	}
+
+	ps := NewPushContext()
+	env := &Environment{
+		Mesh:             &meshconfig.MeshConfig{RootNamespace: "istio-system"},
+		IstioConfigStore: &istioConfigStore{ConfigStore: configStore},
+	}
+	ps.Env = env
+	if err := ps.initTelemetries(env); err != nil {
+		t.Fatalf("init telemetries failed: %v", err)
+	}
+
+	cases := []struct {
+		name           string
+		proxy          *Proxy
+		expectNil      bool
+		sampling       float64
+		tags           []string
+		customTags     []*telemetry.CustomTag
+		accessLogFile  string
+		minStatusCode  uint32
+		metricsEnabled bool
+	}{
+		{
+			name:           "workload selected",
+			proxy:          &Proxy{ConfigNamespace: "bookinfo", WorkloadLabels: labels.Collection{{"app": "reviews"}}},
+			sampling:       100,
+			tags:           []string{"x-tenant-id", "x-build-id"},
+			customTags:     []*telemetry.CustomTag{{Tag: "build", Environment: "BUILD_ID"}},
+			accessLogFile:  "/dev/stdout",
+			minStatusCode:  500,
+			metricsEnabled: false,
+		},
+		{
+			name:           "namespace only",
+			proxy:          &Proxy{ConfigNamespace: "bookinfo", WorkloadLabels: labels.Collection{{"app": "ratings"}}},
+			sampling:       1,
+			tags:           []string{"x-tenant-id"},
+			customTags:     []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme"}},
+			accessLogFile:  "",
+			minStatusCode:  500,
+			metricsEnabled: false,
+		},
+		{
+			name:           "root namespace only",
+			proxy:          &Proxy{ConfigNamespace: "default"},
+			sampling:       1,
+			tags:           []string{"x-tenant-id"},
+			customTags:     []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme"}},
+			accessLogFile:  "",
+			minStatusCode:  400,
+			metricsEnabled: true,
+		},
+		{
+			name:           "proxy in root namespace",
+			proxy:          &Proxy{ConfigNamespace: "istio-system"},
+			sampling:       1,
+			tags:           []string{"x-tenant-id"},
+			customTags:     []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme"}},
+			accessLogFile:  "",
+			minStatusCode:  400,
+			metricsEnabled: true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ps.Telemetry(tt.proxy)
+			if got == nil {
+				t.Fatalf("expected telemetry configuration")
+			}
+			if s := got.TracingRandomSampling(50); s != tt.sampling {
+				t.Errorf("got sampling %v, want %v", s, tt.sampling)
+			}
+			if tags := got.TracingRequestHeadersForTags(); !reflect.DeepEqual(tags, tt.tags) {
+				t.Errorf("got tags %v, want %v", tags, tt.tags)
+			}
+			if tags := got.TracingCustomTags(); !reflect.DeepEqual(tags, tt.customTags) {
+				t.Errorf("got custom tags %v, want %v", tags, tt.customTags)
+			}
+			if !got.TracingEnabled(env.Mesh) {
+				t.Errorf("expected tracing to be enabled")
+			}
+			if f := got.AccessLogFile(env.Mesh); f != tt.accessLogFile {
+				t.Errorf("got access log file %q, want %q", f, tt.accessLogFile)
+			}
+			if c := got.AccessLogFilter().GetMinStatusCode(); c != tt.minStatusCode {
+				t.Errorf("got access log filter status code %d, want %d", c, tt.minStatusCode)
+			}
+			if m := got.MetricsEnabled(constants.TelemetryProviderMixer, true); m != tt.metricsEnabled {
+				t.Errorf("got metrics enabled %v, want %v", m, tt.metricsEnabled)
+			}
+		})
+	}
+
+	ps.Env = &Environment{Mesh: &meshconfig.MeshConfig{}}
+	if got := ps.Telemetry(&Proxy{ConfigNamespace: "default"}); got != nil {
+		t.Errorf("expected no telemetry configuration without root namespace, got %v", got)
+	}
+}
+
+func TestProxyTelemetryDefaults(t *testing.T) {
+	mesh := &meshconfig.MeshConfig{
+		AccessLogFile:               "/dev/stderr",
+		EnableTracing:               true,
+		EnableEnvoyAccessLogService: true,
+	}
+	var none *ProxyTelemetry
+	if f := none.AccessLogFile(mesh); f != "/dev/stderr" {
+		t.Errorf("got access log file %q, want mesh default", f)
+	}
+	if !none.AccessLogServiceEnabled(mesh) {
+		t.Errorf("expected mesh access log service default")
+	}
+	if !none.TracingEnabled(mesh) {
+		t.Errorf("expected mesh tracing default")
+	}
+	if f := none.AccessLogFilter(); f != nil {
+		t.Errorf("expected no access log filter, got %v", f)
+	}
+	if s := none.TracingRandomSampling(1); s != 1 {
+		t.Errorf("got sampling %v, want default", s)
+	}
+	if !none.MetricsEnabled(constants.TelemetryProviderMixer, true) {
+		t.Errorf("expected metrics default")
+	}
+
+	disabled := &ProxyTelemetry{
+		Tracing: &telemetry.Tracing{DisableSpanReporting: &types.BoolValue{Value: true}},
+		Metrics: &telemetry.Metrics{
+			Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoy}},
+		},
+		AccessLogging: &telemetry.AccessLogging{Disabled: &types.BoolValue{Value: true}},
+	}
+	if f := disabled.AccessLogFile(mesh); f != "" {
+		t.Errorf("expected access log to be disabled, got %q", f)
+	}
+	if disabled.AccessLogServiceEnabled(mesh) {
+		t.Errorf("expected access log service to be disabled")
+	}
+	if disabled.TracingEnabled(mesh) {
+		t.Errorf("expected tracing to be disabled")
+	}
+	if disabled.MetricsEnabled(constants.TelemetryProviderMixer, true) {
+		t.Errorf("expected mixer metrics to be disabled")
+	}
+
+	als := &ProxyTelemetry{
+		AccessLogging: &telemetry.AccessLogging{
+			Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoyALS}},
+		},
+	}
+	if f := als.AccessLogFile(mesh); f != "" {
+		t.Errorf("expected file access log to be disabled, got %q", f)
+	}
+	if !als.AccessLogServiceEnabled(&meshconfig.MeshConfig{}) {
+		t.Errorf("expected access log service to be enabled")
+	}
+}
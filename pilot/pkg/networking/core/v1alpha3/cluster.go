@@ -198,6 +198,7 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(env *model.Environme
 
 	for _, service := range push.Services(proxy) {
 		destRule := push.DestinationRule(proxy, service)
+		dynamicForwardProxy := isDynamicForwardProxyService(env, service)
 		for _, port := range service.Ports {
 			if port.Protocol == protocol.UDP {
 				continue
@@ -249,6 +250,9 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(env *model.Environme
 			}
 
 			applyTrafficPolicy(opts, proxy)
+			if dynamicForwardProxy {
+				applyDynamicForwardProxy(env, defaultCluster)
+			}
 			defaultCluster.Metadata = clusterMetadata
 			for _, subset := range destinationRule.Subsets {
 				subsetClusterName := model.BuildSubsetKey(model.TrafficDirectionOutbound, subset.Name, service.Hostname, port.Port)
@@ -296,6 +300,9 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(env *model.Environme
 					serviceMTLSMode: serviceMTLSMode,
 				}
 				applyTrafficPolicy(opts, proxy)
+				if dynamicForwardProxy {
+					applyDynamicForwardProxy(env, subsetCluster)
+				}
 
 				updateEds(subsetCluster)
 
@@ -410,6 +417,8 @@ func updateEds(cluster *apiv2.Cluster) {
 		if v.Type != apiv2.Cluster_EDS {
 			return
 		}
+	case *apiv2.Cluster_ClusterType:
+		return
 	}
 	cluster.EdsClusterConfig = &apiv2.Cluster_EdsClusterConfig{
 		ServiceName: cluster.Name,
@@ -458,7 +467,7 @@ func buildLocalityLbEndpoints(env *model.Environment, proxyNetworkView map[strin
 		if instance.Endpoint.LbWeight > 0 {
 			ep.LoadBalancingWeight.Value = instance.Endpoint.LbWeight
 		}
-		ep.Metadata = util.BuildLbEndpointMetadata(instance.Endpoint.UID, instance.Endpoint.Network, instance.TLSMode)
+		ep.Metadata = util.BuildLbEndpointMetadata(instance.Endpoint.UID, instance.Endpoint.Network, "", instance.TLSMode)
 		locality := instance.GetLocality()
 		lbEndpoints[locality] = append(lbEndpoints[locality], ep)
 	}
@@ -1027,7 +1036,7 @@ func applyLoadBalancer(cluster *apiv2.Cluster, lb *networking.LoadBalancerSettin
 	if lb != nil && lb.LocalityLbSetting != nil {
 		localityLbSettings = lb.LocalityLbSetting
 	}
-	applyLocalityLBSetting(proxy.Locality, cluster, localityLbSettings)
+	applyLocalityLBSetting(proxy, cluster, localityLbSettings)
 
 	if lb == nil {
 		return
@@ -1084,18 +1093,22 @@ func applyLoadBalancer(cluster *apiv2.Cluster, lb *networking.LoadBalancerSettin
 }
 
 func applyLocalityLBSetting(
-	locality *core.Locality,
+	proxy *model.Proxy,
 	cluster *apiv2.Cluster,
 	localityLB *networking.LocalityLoadBalancerSetting,
 ) {
-	if locality == nil || localityLB == nil {
+	if proxy.Locality == nil || localityLB == nil {
 		return
 	}
 
+	var network string
+	if proxy.Metadata != nil {
+		network = proxy.Metadata.Network
+	}
 	// Failover should only be applied with outlier detection, or traffic will never failover.
 	enabledFailover := cluster.OutlierDetection != nil
 	if cluster.LoadAssignment != nil {
-		loadbalancer.ApplyLocalityLBSetting(locality, cluster.LoadAssignment, localityLB, enabledFailover)
+		loadbalancer.ApplyLocalityLBSetting(proxy.Locality, network, proxy.ClusterID, cluster.LoadAssignment, localityLB, enabledFailover)
 	}
 }
 
@@ -2012,3 +2012,59 @@ func TestAutoMTLSClusterPerPortStrictMode(t *testing.T) {
 		g.Expect(cluster.TlsContext).To(BeNil())
 	}
 }
+
+func TestBuildDynamicForwardProxyClusters(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	port := &model.Port{Name: "http", Port: 80, Protocol: protocol.HTTP}
+	service := &model.Service{
+		Hostname:     "*.example.com",
+		Address:      constants.UnspecifiedIP,
+		ClusterVIPs:  make(map[string]string),
+		Ports:        model.PortList{port},
+		Resolution:   model.DNSLB,
+		MeshExternal: true,
+		Attributes:   model.ServiceAttributes{Namespace: TestServiceNamespace},
+	}
+	// a DNS ServiceEntry without endpoints is addressed by its host
+	instances := []*model.ServiceInstance{{
+		Service:  service,
+		Endpoint: model.NetworkEndpoint{Address: "*.example.com", Port: 80, ServicePort: port},
+	}}
+
+	serviceDiscovery := &fakes.ServiceDiscovery{}
+	serviceDiscovery.ServicesReturns([]*model.Service{service}, nil)
+	serviceDiscovery.InstancesByPortReturns(instances, nil)
+	env := newTestEnvironment(serviceDiscovery, testMesh, &fakes.IstioConfigStore{})
+
+	proxy := &model.Proxy{
+		ClusterID:    "some-cluster-id",
+		Type:         model.SidecarProxy,
+		IPAddresses:  []string{"6.6.6.6"},
+		DNSDomain:    "com",
+		Metadata:     &model.NodeMetadata{},
+		IstioVersion: model.MaxIstioVersion,
+	}
+	proxy.SetSidecarScope(env.PushContext)
+
+	g.Expect(hasDynamicForwardProxyServices(env, env.PushContext, proxy)).To(BeTrue())
+
+	clusters := NewConfigGenerator([]plugin.Plugin{}).BuildClusters(env, proxy, env.PushContext)
+	var cluster *apiv2.Cluster
+	for _, c := range clusters {
+		if c.Name == "outbound|80||*.example.com" {
+			cluster = c
+		}
+	}
+	g.Expect(cluster).NotTo(BeNil())
+	g.Expect(cluster.GetClusterType().GetName()).To(Equal(DynamicForwardProxyClusterType))
+	g.Expect(cluster.LbPolicy).To(Equal(apiv2.Cluster_CLUSTER_PROVIDED))
+	g.Expect(cluster.LoadAssignment).To(BeNil())
+	g.Expect(cluster.EdsClusterConfig).To(BeNil())
+
+	// with endpoints, the wildcard host is not resolved by the proxy
+	instances[0].Endpoint.Address = "10.0.0.1"
+	env = newTestEnvironment(serviceDiscovery, testMesh, &fakes.IstioConfigStore{})
+	proxy.SetSidecarScope(env.PushContext)
+	g.Expect(hasDynamicForwardProxyServices(env, env.PushContext, proxy)).To(BeFalse())
+}
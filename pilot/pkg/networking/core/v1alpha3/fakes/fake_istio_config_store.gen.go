@@ -116,6 +116,17 @@ type IstioConfigStore struct {
 		result1 string
 		result2 error
 	}
+	HTTPAPISpecByDestinationStub        func(*model.ServiceInstance) []model.Config
+	hTTPAPISpecByDestinationMutex       sync.RWMutex
+	hTTPAPISpecByDestinationArgsForCall []struct {
+		arg1 *model.ServiceInstance
+	}
+	hTTPAPISpecByDestinationReturns struct {
+		result1 []model.Config
+	}
+	hTTPAPISpecByDestinationReturnsOnCall map[int]struct {
+		result1 []model.Config
+	}
 	ListStub        func(string, string) ([]model.Config, error)
 	listMutex       sync.RWMutex
 	listArgsForCall []struct {
@@ -745,6 +756,66 @@ func (fake *IstioConfigStore) GetResourceAtVersionReturnsOnCall(i int, result1 s
 	}{result1, result2}
 }
 
+func (fake *IstioConfigStore) HTTPAPISpecByDestination(arg1 *model.ServiceInstance) []model.Config {
+	fake.hTTPAPISpecByDestinationMutex.Lock()
+	ret, specificReturn := fake.hTTPAPISpecByDestinationReturnsOnCall[len(fake.hTTPAPISpecByDestinationArgsForCall)]
+	fake.hTTPAPISpecByDestinationArgsForCall = append(fake.hTTPAPISpecByDestinationArgsForCall, struct {
+		arg1 *model.ServiceInstance
+	}{arg1})
+	fake.recordInvocation("HTTPAPISpecByDestination", []interface{}{arg1})
+	fake.hTTPAPISpecByDestinationMutex.Unlock()
+	if fake.HTTPAPISpecByDestinationStub != nil {
+		return fake.HTTPAPISpecByDestinationStub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	fakeReturns := fake.hTTPAPISpecByDestinationReturns
+	return fakeReturns.result1
+}
+
+func (fake *IstioConfigStore) HTTPAPISpecByDestinationCallCount() int {
+	fake.hTTPAPISpecByDestinationMutex.RLock()
+	defer fake.hTTPAPISpecByDestinationMutex.RUnlock()
+	return len(fake.hTTPAPISpecByDestinationArgsForCall)
+}
+
+func (fake *IstioConfigStore) HTTPAPISpecByDestinationCalls(stub func(*model.ServiceInstance) []model.Config) {
+	fake.hTTPAPISpecByDestinationMutex.Lock()
+	defer fake.hTTPAPISpecByDestinationMutex.Unlock()
+	fake.HTTPAPISpecByDestinationStub = stub
+}
+
+func (fake *IstioConfigStore) HTTPAPISpecByDestinationArgsForCall(i int) *model.ServiceInstance {
+	fake.hTTPAPISpecByDestinationMutex.RLock()
+	defer fake.hTTPAPISpecByDestinationMutex.RUnlock()
+	argsForCall := fake.hTTPAPISpecByDestinationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *IstioConfigStore) HTTPAPISpecByDestinationReturns(result1 []model.Config) {
+	fake.hTTPAPISpecByDestinationMutex.Lock()
+	defer fake.hTTPAPISpecByDestinationMutex.Unlock()
+	fake.HTTPAPISpecByDestinationStub = nil
+	fake.hTTPAPISpecByDestinationReturns = struct {
+		result1 []model.Config
+	}{result1}
+}
+
+func (fake *IstioConfigStore) HTTPAPISpecByDestinationReturnsOnCall(i int, result1 []model.Config) {
+	fake.hTTPAPISpecByDestinationMutex.Lock()
+	defer fake.hTTPAPISpecByDestinationMutex.Unlock()
+	fake.HTTPAPISpecByDestinationStub = nil
+	if fake.hTTPAPISpecByDestinationReturnsOnCall == nil {
+		fake.hTTPAPISpecByDestinationReturnsOnCall = make(map[int]struct {
+			result1 []model.Config
+		})
+	}
+	fake.hTTPAPISpecByDestinationReturnsOnCall[i] = struct {
+		result1 []model.Config
+	}{result1}
+}
+
 func (fake *IstioConfigStore) List(arg1 string, arg2 string) ([]model.Config, error) {
 	fake.listMutex.Lock()
 	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
@@ -1229,6 +1300,8 @@ func (fake *IstioConfigStore) Invocations() map[string][][]interface{} {
 	defer fake.getMutex.RUnlock()
 	fake.getResourceAtVersionMutex.RLock()
 	defer fake.getResourceAtVersionMutex.RUnlock()
+	fake.hTTPAPISpecByDestinationMutex.RLock()
+	defer fake.hTTPAPISpecByDestinationMutex.RUnlock()
 	fake.listMutex.RLock()
 	defer fake.listMutex.RUnlock()
 	fake.quotaSpecByDestinationMutex.RLock()
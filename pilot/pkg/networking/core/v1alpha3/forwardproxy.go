@@ -0,0 +1,110 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	apiv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	dfpcluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/dynamic_forward_proxy/v2alpha"
+	dfpcommon "github.com/envoyproxy/go-control-plane/envoy/config/common/dynamic_forward_proxy/v2alpha"
+	dfpfilter "github.com/envoyproxy/go-control-plane/envoy/config/filter/http/dynamic_forward_proxy/v2alpha"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/conversion"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/util/gogo"
+)
+
+const (
+	// DynamicForwardProxyClusterType is the custom cluster type of the clusters resolving the
+	// upstream host from the request.
+	DynamicForwardProxyClusterType = "envoy.clusters.dynamic_forward_proxy"
+
+	// DynamicForwardProxyFilterName is the HTTP filter populating the DNS cache of the dynamic
+	// forward proxy clusters.
+	DynamicForwardProxyFilterName = "envoy.filters.http.dynamic_forward_proxy"
+
+	// dynamicForwardProxyDNSCache is the DNS cache shared by the filter and the clusters. Envoy
+	// requires all the users of a cache to agree on its configuration.
+	dynamicForwardProxyDNSCache = "dynamic_forward_proxy_cache_config"
+)
+
+// isDynamicForwardProxyService returns true for the services of the DNS ServiceEntries with a
+// wildcard host and no endpoints, e.g. *.example.com. The upstream host of these services is
+// only known once the request is received, so their clusters resolve it from the Host header.
+func isDynamicForwardProxyService(env *model.Environment, service *model.Service) bool {
+	if service.Resolution != model.DNSLB || !service.Hostname.IsWildCarded() || len(service.Ports) == 0 {
+		return false
+	}
+	// Without endpoints, the ServiceEntry has a single instance per port addressed by the host.
+	instances, err := env.InstancesByPort(service, service.Ports[0].Port, nil)
+	if err != nil || len(instances) == 0 {
+		return false
+	}
+	return instances[0].Endpoint.Address == string(service.Hostname)
+}
+
+// hasDynamicForwardProxyServices returns true if the proxy sees a dynamic forward proxy service.
+func hasDynamicForwardProxyServices(env *model.Environment, push *model.PushContext, proxy *model.Proxy) bool {
+	if push == nil {
+		return false
+	}
+	for _, service := range push.Services(proxy) {
+		if isDynamicForwardProxyService(env, service) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildDNSCacheConfig(env *model.Environment) *dfpcommon.DnsCacheConfig {
+	return &dfpcommon.DnsCacheConfig{
+		Name:            dynamicForwardProxyDNSCache,
+		DnsLookupFamily: apiv2.Cluster_V4_ONLY,
+		DnsRefreshRate:  gogo.DurationToProtoDuration(env.Mesh.DnsRefreshRate),
+	}
+}
+
+// applyDynamicForwardProxy turns the cluster of a dynamic forward proxy service into a
+// dynamic forward proxy cluster. It is applied after the traffic policy, which may have set
+// a load balancer and a DNS configuration meaningless for such a cluster.
+func applyDynamicForwardProxy(env *model.Environment, cluster *apiv2.Cluster) {
+	cluster.ClusterDiscoveryType = &apiv2.Cluster_ClusterType{
+		ClusterType: &apiv2.Cluster_CustomClusterType{
+			Name:        DynamicForwardProxyClusterType,
+			TypedConfig: util.MessageToAny(&dfpcluster.ClusterConfig{DnsCacheConfig: buildDNSCacheConfig(env)}),
+		},
+	}
+	cluster.LbPolicy = apiv2.Cluster_CLUSTER_PROVIDED
+	cluster.LoadAssignment = nil
+	cluster.DnsLookupFamily = apiv2.Cluster_AUTO
+	cluster.DnsRefreshRate = nil
+	cluster.RespectDnsTtl = false
+	cluster.LbConfig = nil
+}
+
+// buildDynamicForwardProxyFilter builds the HTTP filter resolving the Host header of the requests
+// routed to a dynamic forward proxy cluster. It must precede the router filter.
+func buildDynamicForwardProxyFilter(env *model.Environment, node *model.Proxy) *http_conn.HttpFilter {
+	config := &dfpfilter.FilterConfig{DnsCacheConfig: buildDNSCacheConfig(env)}
+	filter := &http_conn.HttpFilter{Name: DynamicForwardProxyFilterName}
+	if util.IsXDSMarshalingToAnyEnabled(node) {
+		filter.ConfigType = &http_conn.HttpFilter_TypedConfig{TypedConfig: util.MessageToAny(config)}
+	} else {
+		s, _ := conversion.MessageToStruct(config)
+		filter.ConfigType = &http_conn.HttpFilter_Config{Config: s}
+	}
+	return filter
+}
@@ -61,13 +61,18 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(
 	actualWildcard, _ := getActualWildcardAndLocalHost(node)
 	errs := &multierror.Error{}
 	listeners := make([]*xdsapi.Listener, 0, len(mergedGateway.Servers))
-	for portNumber, servers := range mergedGateway.Servers {
+	for serverPort, servers := range mergedGateway.Servers {
 		// on a given port, we can either have plain text HTTP servers or
 		// HTTPS/TLS servers with SNI. We cannot have a mix of http and https server on same port.
+		portNumber := serverPort.Number
+		bind := actualWildcard
+		if serverPort.Bind != "" {
+			bind = serverPort.Bind
+		}
 		opts := buildListenerOpts{
 			env:        env,
 			proxy:      node,
-			bind:       actualWildcard,
+			bind:       bind,
 			port:       int(portNumber),
 			bindToPort: true,
 		}
@@ -238,9 +243,31 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(env *model.Env
 	}
 
 	vHostDedupMap := make(map[host.Name]*route.VirtualHost)
+	// the virtual service defining each virtual host first
+	vHostVirtualServices := make(map[host.Name]string)
+	// the hosts of the servers redirecting to HTTPS are answered with a redirect whatever their virtual services
+	redirectedHosts := make(map[host.Name]bool)
 	for _, server := range servers {
+		redirect, ok := merged.HTTPSRedirects[server]
+		if !ok {
+			continue
+		}
+		for _, h := range server.Hosts {
+			hostname := host.Name(h[strings.Index(h, "/")+1:])
+			if redirectedHosts[hostname] {
+				continue
+			}
+			vHostDedupMap[hostname] = buildGatewayHTTPSRedirectVHost(node, hostname, port, redirect)
+			redirectedHosts[hostname] = true
+		}
+	}
+	for _, server := range servers {
+		if _, ok := merged.HTTPSRedirects[server]; ok {
+			continue
+		}
 		gatewayName := merged.GatewayNameForServer[server]
-		virtualServices := push.VirtualServices(node, map[string]bool{gatewayName: true})
+		virtualServices := bindableVirtualServices(env, node, push, server,
+			push.VirtualServices(node, map[string]bool{gatewayName: true}))
 		for _, virtualService := range virtualServices {
 			virtualServiceHosts := host.NewNames(virtualService.Spec.(*networking.VirtualService).Hosts)
 			serverHosts := host.NamesForNamespace(server.Hosts, virtualService.Namespace)
@@ -259,8 +286,17 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(env *model.Env
 				continue
 			}
 
+			virtualServiceName := virtualService.Namespace + "/" + virtualService.Name
 			for _, hostname := range intersectingHosts {
+				if redirectedHosts[hostname] {
+					continue
+				}
 				if vHost, exists := vHostDedupMap[hostname]; exists {
+					if other := vHostVirtualServices[hostname]; other != virtualServiceName {
+						push.Add(model.ConflictingGatewayVirtualServiceHosts, vHost.Name, node,
+							fmt.Sprintf("Host %s defined by virtual service %s conflicts with virtual service %s",
+								vHost.Name, virtualServiceName, other))
+					}
 					vHost.Routes = istio_route.CombineVHostRoutes(vHost.Routes, routes)
 				} else {
 					newVHost := &route.VirtualHost{
@@ -268,10 +304,8 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(env *model.Env
 						Domains: []string{string(hostname), fmt.Sprintf("%s:%d", hostname, port)},
 						Routes:  routes,
 					}
-					if server.Tls != nil && server.Tls.HttpsRedirect {
-						newVHost.RequireTls = route.VirtualHost_ALL
-					}
 					vHostDedupMap[hostname] = newVHost
+					vHostVirtualServices[hostname] = virtualServiceName
 				}
 			}
 		}
@@ -307,6 +341,13 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(env *model.Env
 		}
 	}
 
+	// the plain text servers answer the ACME HTTP-01 challenges for the hosts of the gateways
+	if len(merged.ACMEChallenges) > 0 && protocol.Parse(servers[0].Port.Protocol).IsHTTP() {
+		for _, vh := range virtualHosts {
+			vh.Routes = append(buildGatewayACMEChallengeRoutes(node, merged.ACMEChallenges), vh.Routes...)
+		}
+	}
+
 	util.SortVirtualHosts(virtualHosts)
 
 	routeCfg := &xdsapi.RouteConfiguration{
@@ -332,6 +373,113 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(env *model.Env
 	return routeCfg
 }
 
+// httpsRedirectRouteName is the name of the route redirecting the requests of a gateway server to HTTPS.
+const httpsRedirectRouteName = "https_redirect"
+
+// httpsRedirectResponseCodes maps the status codes of the HTTPS redirects to their Envoy enum.
+var httpsRedirectResponseCodes = map[uint32]route.RedirectAction_RedirectResponseCode{
+	301: route.RedirectAction_MOVED_PERMANENTLY,
+	302: route.RedirectAction_FOUND,
+	303: route.RedirectAction_SEE_OTHER,
+	307: route.RedirectAction_TEMPORARY_REDIRECT,
+	308: route.RedirectAction_PERMANENT_REDIRECT,
+}
+
+// buildGatewayHTTPSRedirectVHost builds the virtual host redirecting all the plain text requests for the
+// host to HTTPS, on the port and with the status code of the redirect.
+func buildGatewayHTTPSRedirectVHost(node *model.Proxy, hostname host.Name, port int,
+	redirect model.HTTPSRedirect) *route.VirtualHost {
+	r := &route.Route{
+		Match: &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+		},
+		Action: &route.Route_Redirect{
+			Redirect: &route.RedirectAction{
+				SchemeRewriteSpecifier: &route.RedirectAction_HttpsRedirect{HttpsRedirect: true},
+				PortRedirect:           redirect.Port,
+				ResponseCode:           httpsRedirectResponseCodes[redirect.Code],
+			},
+		},
+	}
+	if util.IsIstioVersionGE13(node) {
+		r.Name = httpsRedirectRouteName
+	}
+	return &route.VirtualHost{
+		Name:    fmt.Sprintf("%s:%d", hostname, port),
+		Domains: []string{string(hostname), fmt.Sprintf("%s:%d", hostname, port)},
+		Routes:  []*route.Route{r},
+	}
+}
+
+const (
+	// acmeChallengeRouteName is the name of the routes answering the ACME HTTP-01 challenges.
+	acmeChallengeRouteName = "acme_challenge"
+	// acmeChallengePathPrefix is the path of the ACME HTTP-01 challenges, followed by their token.
+	acmeChallengePathPrefix = "/.well-known/acme-challenge/"
+)
+
+// buildGatewayACMEChallengeRoutes builds the routes answering the ACME HTTP-01 challenges with their
+// key authorization, by their token.
+func buildGatewayACMEChallengeRoutes(node *model.Proxy, challenges map[string]string) []*route.Route {
+	tokens := make([]string, 0, len(challenges))
+	for token := range challenges {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+
+	routes := make([]*route.Route, 0, len(tokens))
+	for _, token := range tokens {
+		r := &route.Route{
+			Match: &route.RouteMatch{
+				PathSpecifier: &route.RouteMatch_Path{Path: acmeChallengePathPrefix + token},
+			},
+			Action: &route.Route_DirectResponse{
+				DirectResponse: &route.DirectResponseAction{
+					Status: 200,
+					Body: &core.DataSource{
+						Specifier: &core.DataSource_InlineString{InlineString: challenges[token]},
+					},
+				},
+			},
+		}
+		if util.IsIstioVersionGE13(node) {
+			r.Name = acmeChallengeRouteName
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// gatewayTopology returns the number of trusted proxies in front of the gateway and how it handles
+// the X-Forwarded-Client-Cert header, from the node metadata or else the Pilot defaults.
+func gatewayTopology(node *model.Proxy) (uint32, http_conn.HttpConnectionManager_ForwardClientCertDetails) {
+	numTrustedProxies := features.GatewayNumTrustedProxies
+	if len(node.Metadata.NumTrustedProxies) > 0 {
+		n, err := strconv.Atoi(node.Metadata.NumTrustedProxies)
+		if err != nil || n < 0 {
+			log.Warnf("invalid number of trusted proxies %q for %s", node.Metadata.NumTrustedProxies, node.ID)
+		} else {
+			numTrustedProxies = n
+		}
+	}
+	if numTrustedProxies < 0 {
+		numTrustedProxies = 0
+	}
+
+	forwardClientCertDetails := http_conn.HttpConnectionManager_SANITIZE_SET
+	for _, details := range []string{features.GatewayForwardClientCertDetails, node.Metadata.ForwardClientCertDetails} {
+		if details == "" {
+			continue
+		}
+		if v, f := http_conn.HttpConnectionManager_ForwardClientCertDetails_value[strings.ToUpper(details)]; f {
+			forwardClientCertDetails = http_conn.HttpConnectionManager_ForwardClientCertDetails(v)
+		} else {
+			log.Warnf("invalid client cert forwarding %q for %s", details, node.ID)
+		}
+	}
+	return uint32(numTrustedProxies), forwardClientCertDetails
+}
+
 // builds a HTTP connection manager for servers of type HTTP or HTTPS (mode: simple/mutual)
 func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 	node *model.Proxy, server *networking.Server, routeName string, sdsPath string) *filterChainOpts {
@@ -344,6 +492,8 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 		httpProtoOpts.AcceptHttp_10 = true
 	}
 
+	numTrustedProxies, forwardClientCertDetails := gatewayTopology(node)
+
 	// Are we processing plaintext servers or HTTPS servers?
 	// If plain text, we have to combine all servers into a single listener
 	if serverProto.IsHTTP() {
@@ -359,7 +509,7 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 				direction:        http_conn.HttpConnectionManager_Tracing_EGRESS, // viewed as from gateway to internal
 				connectionManager: &http_conn.HttpConnectionManager{
 					// Forward client cert if connection is mTLS
-					ForwardClientCertDetails: http_conn.HttpConnectionManager_SANITIZE_SET,
+					ForwardClientCertDetails: forwardClientCertDetails,
 					SetCurrentClientCertDetails: &http_conn.HttpConnectionManager_SetCurrentClientCertDetails{
 						Subject: proto.BoolTrue,
 						Cert:    true,
@@ -368,6 +518,7 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 					},
 					ServerName:          EnvoyServerName,
 					HttpProtocolOptions: httpProtoOpts,
+					XffNumTrustedHops:   numTrustedProxies,
 				},
 			},
 		}
@@ -394,7 +545,7 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 			direction:        http_conn.HttpConnectionManager_Tracing_EGRESS, // viewed as from gateway to internal
 			connectionManager: &http_conn.HttpConnectionManager{
 				// Forward client cert if connection is mTLS
-				ForwardClientCertDetails: http_conn.HttpConnectionManager_SANITIZE_SET,
+				ForwardClientCertDetails: forwardClientCertDetails,
 				SetCurrentClientCertDetails: &http_conn.HttpConnectionManager_SetCurrentClientCertDetails{
 					Subject: proto.BoolTrue,
 					Cert:    true,
@@ -403,6 +554,7 @@ func (configgen *ConfigGeneratorImpl) createGatewayHTTPFilterChainOpts(
 				},
 				ServerName:          EnvoyServerName,
 				HttpProtocolOptions: httpProtoOpts,
+				XffNumTrustedHops:   numTrustedProxies,
 			},
 		},
 	}
@@ -638,7 +790,8 @@ func buildGatewayNetworkFiltersFromTCPRoutes(node *model.Proxy, env *model.Envir
 		gatewayServerHosts[host.Name(hostname)] = true
 	}
 
-	virtualServices := push.VirtualServices(node, gatewaysForWorkload)
+	virtualServices := bindableVirtualServices(env, node, push, server,
+		push.VirtualServices(node, gatewaysForWorkload))
 	for _, v := range virtualServices {
 		vsvc := v.Spec.(*networking.VirtualService)
 		// We have two cases here:
@@ -690,7 +843,8 @@ func buildGatewayNetworkFiltersFromTLSRoutes(node *model.Proxy, env *model.Envir
 			networkFilters: buildOutboundAutoPassthroughFilterStack(env, node, port),
 		})
 	} else {
-		virtualServices := push.VirtualServices(node, gatewaysForWorkload)
+		virtualServices := bindableVirtualServices(env, node, push, server,
+			push.VirtualServices(node, gatewaysForWorkload))
 		for _, v := range virtualServices {
 			vsvc := v.Spec.(*networking.VirtualService)
 			// We have two cases here:
@@ -712,10 +866,12 @@ func buildGatewayNetworkFiltersFromTLSRoutes(node *model.Proxy, env *model.Envir
 				for _, match := range tls.Match {
 					if l4SingleMatch(convertTLSMatchToL4Match(match), server, gatewaysForWorkload) {
 						// the sni hosts in the match will become part of a filter chain match
+						networkFilters := buildGatewayPassthroughNetworkFilters(env, node, tls.Route, push, port,
+							v.ConfigMeta, match.SniHosts)
 						filterChains = append(filterChains, &filterChainOpts{
 							sniHosts:       match.SniHosts,
 							tlsContext:     nil, // NO TLS context because this is passthrough
-							networkFilters: buildOutboundNetworkFilters(env, node, tls.Route, push, port, v.ConfigMeta),
+							networkFilters: networkFilters,
 						})
 					}
 				}
@@ -726,6 +882,31 @@ func buildGatewayNetworkFiltersFromTLSRoutes(node *model.Proxy, env *model.Envir
 	return filterChains
 }
 
+// bindableVirtualServices returns the virtual services allowed to bind to the gateway of the server by the
+// namespace restrictions of its annotations, if any, and records the rejected ones.
+func bindableVirtualServices(env *model.Environment, node *model.Proxy, push *model.PushContext, server *networking.Server,
+	virtualServices []model.Config) []model.Config {
+	if node.MergedGateway == nil {
+		return virtualServices
+	}
+	gatewayName := node.MergedGateway.GatewayNameForServer[server]
+	policy := node.MergedGateway.BindingPolicies[gatewayName]
+	if policy == nil {
+		return virtualServices
+	}
+	out := make([]model.Config, 0, len(virtualServices))
+	for _, v := range virtualServices {
+		if policy.Allows(v.Namespace, env.NamespaceLabels(v.Namespace)) {
+			out = append(out, v)
+			continue
+		}
+		virtualServiceName := v.Namespace + "/" + v.Name
+		push.Add(model.RejectedGatewayVirtualServiceBindings, virtualServiceName, node,
+			fmt.Sprintf("Virtual service %s is not allowed to bind to gateway %s", virtualServiceName, gatewayName))
+	}
+	return out
+}
+
 // Select the virtualService's hosts that match the ones specified in the gateway server's hosts
 // based on the wildcard hostname match and the namespace match
 func pickMatchingGatewayHosts(gatewayServerHosts map[host.Name]bool, virtualService model.Config) map[string]host.Name {
@@ -16,10 +16,12 @@ package v1alpha3
 
 import (
 	"reflect"
+	"sort"
 	"testing"
 
 	auth "github.com/envoyproxy/go-control-plane/envoy/api/v2/auth"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
 	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 
 	networking "istio.io/api/networking/v1alpha3"
@@ -31,6 +33,7 @@ import (
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/security/model"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/gateway"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/schemas"
 	"istio.io/istio/pkg/proto"
@@ -619,6 +622,67 @@ func TestCreateGatewayHTTPFilterChainOpts(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "gateway topology",
+			node: &pilot_model.Proxy{
+				Metadata: &pilot_model.NodeMetadata{NumTrustedProxies: "2", ForwardClientCertDetails: "append_forward"},
+			},
+			server: &networking.Server{
+				Port: &networking.Port{},
+			},
+			routeName: "some-route",
+			result: &filterChainOpts{
+				sniHosts:   nil,
+				tlsContext: nil,
+				httpOpts: &httpListenerOpts{
+					rds:              "some-route",
+					useRemoteAddress: true,
+					direction:        http_conn.HttpConnectionManager_Tracing_EGRESS,
+					connectionManager: &http_conn.HttpConnectionManager{
+						ForwardClientCertDetails: http_conn.HttpConnectionManager_APPEND_FORWARD,
+						SetCurrentClientCertDetails: &http_conn.HttpConnectionManager_SetCurrentClientCertDetails{
+							Subject: proto.BoolTrue,
+							Cert:    true,
+							Uri:     true,
+							Dns:     true,
+						},
+						ServerName:          EnvoyServerName,
+						HttpProtocolOptions: &core.Http1ProtocolOptions{},
+						XffNumTrustedHops:   2,
+					},
+				},
+			},
+		},
+		{
+			name: "invalid gateway topology",
+			node: &pilot_model.Proxy{
+				Metadata: &pilot_model.NodeMetadata{NumTrustedProxies: "-1", ForwardClientCertDetails: "drop"},
+			},
+			server: &networking.Server{
+				Port: &networking.Port{},
+			},
+			routeName: "some-route",
+			result: &filterChainOpts{
+				sniHosts:   nil,
+				tlsContext: nil,
+				httpOpts: &httpListenerOpts{
+					rds:              "some-route",
+					useRemoteAddress: true,
+					direction:        http_conn.HttpConnectionManager_Tracing_EGRESS,
+					connectionManager: &http_conn.HttpConnectionManager{
+						ForwardClientCertDetails: http_conn.HttpConnectionManager_SANITIZE_SET,
+						SetCurrentClientCertDetails: &http_conn.HttpConnectionManager_SetCurrentClientCertDetails{
+							Subject: proto.BoolTrue,
+							Cert:    true,
+							Uri:     true,
+							Dns:     true,
+						},
+						ServerName:          EnvoyServerName,
+						HttpProtocolOptions: &core.Http1ProtocolOptions{},
+					},
+				},
+			},
+		},
 		{
 			name: "Duplicate hosts in TLS filterChain",
 			node: &pilot_model.Proxy{Metadata: &pilot_model.NodeMetadata{}},
@@ -949,6 +1013,279 @@ func TestGatewayHTTPRouteConfig(t *testing.T) {
 
 }
 
+func TestBuildGatewayListenersBind(t *testing.T) {
+	gateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:      "gateway",
+			Namespace: "default",
+		},
+		Spec: &networking.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"example.org"},
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+				},
+				{
+					Hosts: []string{"internal.example.org"},
+					Port:  &networking.Port{Name: "http-internal", Number: 80, Protocol: "HTTP"},
+					Bind:  "10.0.0.1",
+				},
+			},
+		},
+	}
+
+	configgen := NewConfigGenerator([]plugin.Plugin{})
+	env := buildEnv(t, []pilot_model.Config{gateway}, nil)
+	proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+	builder := configgen.buildGatewayListeners(&env, &proxy14Gateway, env.PushContext, &ListenerBuilder{})
+
+	listeners := make(map[string]string)
+	for _, l := range builder.gatewayListeners {
+		hcm := &http_conn.HttpConnectionManager{}
+		if err := getFilterConfig(l.FilterChains[0].Filters[0], hcm); err != nil {
+			t.Fatal(err)
+		}
+		listeners[l.Name] = hcm.GetRds().GetRouteConfigName()
+	}
+	want := map[string]string{
+		"0.0.0.0_80":  "http.80",
+		"10.0.0.1_80": "http.80.10.0.0.1",
+	}
+	if !reflect.DeepEqual(listeners, want) {
+		t.Errorf("got listeners %v, want %v", listeners, want)
+	}
+}
+
+func TestGatewayHTTPSRedirect(t *testing.T) {
+	gateway := func(annotations map[string]string) pilot_model.Config {
+		return pilot_model.Config{
+			ConfigMeta: pilot_model.ConfigMeta{
+				Name:        "gateway",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: &networking.Gateway{
+				Selector: map[string]string{"istio": "ingressgateway"},
+				Servers: []*networking.Server{
+					{
+						Hosts: []string{"example.org", "default/foo.example.org"},
+						Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+						Tls:   &networking.Server_TLSOptions{HttpsRedirect: true},
+					},
+				},
+			},
+		}
+	}
+	virtualService := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Type:      schemas.VirtualService.Type,
+			Name:      "virtual-service",
+			Namespace: "default",
+		},
+		Spec: &networking.VirtualService{
+			Hosts:    []string{"example.org"},
+			Gateways: []string{"gateway"},
+			Http: []*networking.HTTPRoute{
+				{
+					Route: []*networking.HTTPRouteDestination{
+						{Destination: &networking.Destination{Host: "example.org"}},
+					},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		name            string
+		virtualServices []pilot_model.Config
+		gateway         pilot_model.Config
+		port            uint32
+		code            route.RedirectAction_RedirectResponseCode
+	}{
+		{
+			"no virtual service",
+			nil,
+			gateway(nil),
+			443,
+			route.RedirectAction_MOVED_PERMANENTLY,
+		},
+		{
+			"virtual service routes are not used",
+			[]pilot_model.Config{virtualService},
+			gateway(nil),
+			443,
+			route.RedirectAction_MOVED_PERMANENTLY,
+		},
+		{
+			"custom port and code",
+			nil,
+			gateway(map[string]string{
+				pilot_model.HTTPSRedirectPortAnnotation: "8443",
+				pilot_model.HTTPSRedirectCodeAnnotation: "308",
+			}),
+			8443,
+			route.RedirectAction_PERMANENT_REDIRECT,
+		},
+		{
+			"invalid port and code are ignored",
+			nil,
+			gateway(map[string]string{
+				pilot_model.HTTPSRedirectPortAnnotation: "70000",
+				pilot_model.HTTPSRedirectCodeAnnotation: "200",
+			}),
+			443,
+			route.RedirectAction_MOVED_PERMANENTLY,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+			env := buildEnv(t, []pilot_model.Config{tt.gateway}, tt.virtualServices)
+			proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+			routeCfg := configgen.buildGatewayHTTPRouteConfig(&env, &proxy14Gateway, env.PushContext, "http.80")
+			if routeCfg == nil {
+				t.Fatal("got an empty route configuration")
+			}
+			var vhosts []string
+			for _, vh := range routeCfg.VirtualHosts {
+				vhosts = append(vhosts, vh.Name)
+				if len(vh.Routes) != 1 {
+					t.Fatalf("%s: got routes %v, want a single redirect", vh.Name, vh.Routes)
+				}
+				redirect := vh.Routes[0].GetRedirect()
+				if redirect == nil || !redirect.GetHttpsRedirect() {
+					t.Fatalf("%s: got route %v, want an HTTPS redirect", vh.Name, vh.Routes[0])
+				}
+				if redirect.PortRedirect != tt.port || redirect.ResponseCode != tt.code {
+					t.Errorf("%s: got redirect to port %d with %v, want port %d with %v",
+						vh.Name, redirect.PortRedirect, redirect.ResponseCode, tt.port, tt.code)
+				}
+			}
+			want := []string{"example.org:80", "foo.example.org:80"}
+			if !reflect.DeepEqual(vhosts, want) {
+				t.Errorf("got virtual hosts %v, want %v", vhosts, want)
+			}
+		})
+	}
+}
+
+func TestGatewayACMEChallengeRoutes(t *testing.T) {
+	gateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:      "gateway",
+			Namespace: "default",
+			Annotations: map[string]string{
+				pilot_model.ACMEChallengeAnnotationPrefix + "0123456789abcdef": "token.thumbprint",
+			},
+		},
+		Spec: &networking.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"example.org"},
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+					Tls:   &networking.Server_TLSOptions{HttpsRedirect: true},
+				},
+				{
+					Hosts: []string{"example.org"},
+					Port:  &networking.Port{Name: "https", Number: 443, Protocol: "HTTPS"},
+					Tls:   &networking.Server_TLSOptions{Mode: networking.Server_TLSOptions_SIMPLE, CredentialName: "example"},
+				},
+			},
+		},
+	}
+
+	configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+	env := buildEnv(t, []pilot_model.Config{gateway}, nil)
+	proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+
+	routeCfg := configgen.buildGatewayHTTPRouteConfig(&env, &proxy14Gateway, env.PushContext, "http.80")
+	if routeCfg == nil || len(routeCfg.VirtualHosts) != 1 {
+		t.Fatalf("got route configuration %v, want a single virtual host", routeCfg)
+	}
+	routes := routeCfg.VirtualHosts[0].Routes
+	if len(routes) != 2 || routes[1].GetRedirect() == nil {
+		t.Fatalf("got routes %v, want the challenge followed by the redirect", routes)
+	}
+	if path := routes[0].GetMatch().GetPath(); path != "/.well-known/acme-challenge/token" {
+		t.Errorf("got challenge path %q", path)
+	}
+	if body := routes[0].GetDirectResponse().GetBody().GetInlineString(); body != "token.thumbprint" {
+		t.Errorf("got challenge response %q, want the key authorization", body)
+	}
+
+	// the HTTPS servers do not answer the challenges
+	routeCfg = configgen.buildGatewayHTTPRouteConfig(&env, &proxy14Gateway, env.PushContext, "https.443.https.gateway.default")
+	if routeCfg == nil {
+		t.Fatal("got an empty route configuration")
+	}
+	for _, vh := range routeCfg.VirtualHosts {
+		for _, r := range vh.Routes {
+			if r.GetDirectResponse().GetBody() != nil {
+				t.Errorf("got challenge route %v on an HTTPS server", r)
+			}
+		}
+	}
+}
+
+func TestGatewayBindingPolicy(t *testing.T) {
+	gateway := pilot_model.Config{
+		ConfigMeta: pilot_model.ConfigMeta{
+			Name:        "gateway",
+			Namespace:   "default",
+			Annotations: map[string]string{gateway.AllowedNamespacesAnnotation: "web"},
+		},
+		Spec: &networking.Gateway{
+			Selector: map[string]string{"istio": "ingressgateway"},
+			Servers: []*networking.Server{
+				{
+					Hosts: []string{"*/*.example.org"},
+					Port:  &networking.Port{Name: "http", Number: 80, Protocol: "HTTP"},
+				},
+			},
+		},
+	}
+	virtualService := func(namespace string) pilot_model.Config {
+		return pilot_model.Config{
+			ConfigMeta: pilot_model.ConfigMeta{
+				Type:      schemas.VirtualService.Type,
+				Name:      "virtual-service",
+				Namespace: namespace,
+			},
+			Spec: &networking.VirtualService{
+				Hosts:    []string{namespace + ".example.org"},
+				Gateways: []string{"default/gateway"},
+				Http: []*networking.HTTPRoute{
+					{
+						Route: []*networking.HTTPRouteDestination{
+							{Destination: &networking.Destination{Host: namespace + ".example.org"}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	configgen := NewConfigGenerator([]plugin.Plugin{&fakePlugin{}})
+	env := buildEnv(t, []pilot_model.Config{gateway},
+		[]pilot_model.Config{virtualService("default"), virtualService("web"), virtualService("other")})
+	proxy14Gateway.SetGatewaysForProxy(env.PushContext)
+
+	routeCfg := configgen.buildGatewayHTTPRouteConfig(&env, &proxy14Gateway, env.PushContext, "http.80")
+	if routeCfg == nil {
+		t.Fatal("got an empty route configuration")
+	}
+	var vhosts []string
+	for _, vh := range routeCfg.VirtualHosts {
+		vhosts = append(vhosts, vh.Name)
+	}
+	sort.Strings(vhosts)
+	want := []string{"default.example.org:80", "web.example.org:80"}
+	if !reflect.DeepEqual(vhosts, want) {
+		t.Errorf("got virtual hosts %v, want %v", vhosts, want)
+	}
+}
+
 func buildEnv(t *testing.T, gateways []pilot_model.Config, virtualServices []pilot_model.Config) pilot_model.Environment {
 	serviceDiscovery := new(fakes.ServiceDiscovery)
 
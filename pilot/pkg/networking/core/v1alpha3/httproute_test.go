@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
@@ -257,6 +258,16 @@ func TestSidecarOutboundHTTPRouteConfig(t *testing.T) {
 			},
 		},
 	}
+	sidecarConfigWithListenerRegistryOnly := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Name:      "foo",
+			Namespace: "not-default",
+			Annotations: map[string]string{
+				constants.SidecarEgressOutboundTrafficPolicyAnnotation: "8080=REGISTRY_ONLY",
+			},
+		},
+		Spec: sidecarConfigWithAllowAny.Spec,
+	}
 	virtualServiceSpec1 := &networking.VirtualService{
 		Hosts:    []string{"test-private-2.com"},
 		Gateways: []string{"mesh"},
@@ -454,6 +465,36 @@ func TestSidecarOutboundHTTPRouteConfig(t *testing.T) {
 			fallthroughRoute: true,
 			registryOnly:     false,
 		},
+		{
+			name:                  "sidecar config with fallthrough and egress listener with registry only",
+			routeName:             "8080",
+			sidecarConfig:         sidecarConfigWithListenerRegistryOnly,
+			virtualServiceConfigs: nil,
+			expectedHosts: map[string]map[string]bool{
+				"test.com:8080": {"test.com:8080": true, "8.8.8.8:8080": true},
+				"block_all": {
+					"*": true,
+				},
+			},
+			fallthroughRoute: true,
+			registryOnly:     false,
+		},
+		{
+			name:                  "sidecar config with fallthrough and allow any for the other egress listeners",
+			routeName:             "80",
+			sidecarConfig:         sidecarConfigWithListenerRegistryOnly,
+			virtualServiceConfigs: nil,
+			expectedHosts: map[string]map[string]bool{
+				"test-private.com:80": {
+					"test-private.com": true, "test-private.com:80": true, "9.9.9.9": true, "9.9.9.9:80": true,
+				},
+				"allow_any": {
+					"*": true,
+				},
+			},
+			fallthroughRoute: true,
+			registryOnly:     true,
+		},
 
 		{
 			name:                  "wildcard egress importing from all namespaces: 9999",
@@ -20,7 +20,9 @@ import (
 	"net"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
@@ -46,6 +48,7 @@ import (
 	"istio.io/istio/pilot/pkg/networking/util"
 	authn_model "istio.io/istio/pilot/pkg/security/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	telemetryapi "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
@@ -118,11 +121,20 @@ const (
 		"%UPSTREAM_CLUSTER% %UPSTREAM_LOCAL_ADDRESS% %DOWNSTREAM_LOCAL_ADDRESS% " +
 		"%DOWNSTREAM_REMOTE_ADDRESS% %REQUESTED_SERVER_NAME% %ROUTE_NAME%\n"
 
+	// routeNameLogOperator is the access log operator of the name of the route.
+	routeNameLogOperator = "%ROUTE_NAME%"
+
 	// EnvoyServerName for istio's envoy
 	EnvoyServerName = "istio-envoy"
 
 	httpEnvoyAccessLogName = "http_envoy_accesslog"
 
+	tcpEnvoyAccessLogName = "tcp_envoy_accesslog"
+
+	// tcpGRPCAccessLog is the sink name of the TCP gRPC access log service, which is missing from
+	// the go-control-plane well known names.
+	tcpGRPCAccessLog = "envoy.tcp_grpc_access_log"
+
 	// EnvoyAccessLogCluster is the cluster name that has details for server implementing Envoy ALS.
 	// This cluster is created in bootstrap.
 	EnvoyAccessLogCluster = "envoy_accesslog_service"
@@ -318,28 +330,19 @@ func buildAccessLog(node *model.Proxy, fl *accesslogconfig.FileAccessLog, env *m
 
 		if env.Mesh.AccessLogFormat != "" {
 			formatString = env.Mesh.AccessLogFormat
+			// envoy does not terminate text log entries, so without a trailing newline
+			// all requests would end up on a single line
+			if !strings.HasSuffix(formatString, "\n") {
+				formatString += "\n"
+			}
 		}
 		fl.AccessLogFormat = &accesslogconfig.FileAccessLog_Format{
 			Format: formatString,
 		}
 	case meshconfig.MeshConfig_JSON:
 		var jsonLog *structpb.Struct
-		// TODO potential optimization to avoid recomputing the user provided format for every listener
-		// mesh AccessLogFormat field could change so need a way to have a cached value that can be cleared
-		// on changes
 		if env.Mesh.AccessLogFormat != "" {
-			jsonFields := map[string]string{}
-			err := json.Unmarshal([]byte(env.Mesh.AccessLogFormat), &jsonFields)
-			if err == nil {
-				jsonLog = &structpb.Struct{
-					Fields: make(map[string]*structpb.Value, len(jsonFields)),
-				}
-				for key, value := range jsonFields {
-					jsonLog.Fields[key] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: value}}
-				}
-			} else {
-				log.Errorf("error parsing provided json log format, default log format will be used: %v", err)
-			}
+			jsonLog = jsonAccessLogFormat(env.Mesh.AccessLogFormat)
 		}
 		if jsonLog == nil {
 			if util.IsIstioVersionGE13(node) {
@@ -356,6 +359,98 @@ func buildAccessLog(node *model.Proxy, fl *accesslogconfig.FileAccessLog, env *m
 	}
 }
 
+// buildAccessLogFilter converts the access log filter of the Telemetry resources into an Envoy filter
+// logging the requests matching any of its conditions. The status code condition is left out of the
+// filters of the TCP access logs, since the TCP connections have no status code.
+func buildAccessLogFilter(in *telemetryapi.AccessLogFilter, tcp bool) *accesslog.AccessLogFilter {
+	if in == nil {
+		return nil
+	}
+
+	var filters []*accesslog.AccessLogFilter
+	if in.MinStatusCode > 0 && !tcp {
+		filters = append(filters, &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_StatusCodeFilter{
+				StatusCodeFilter: &accesslog.StatusCodeFilter{
+					Comparison: &accesslog.ComparisonFilter{
+						Op:    accesslog.ComparisonFilter_GE,
+						Value: &core.RuntimeUInt32{DefaultValue: in.MinStatusCode, RuntimeKey: "access_log.min_status_code"},
+					},
+				},
+			},
+		})
+	}
+	if in.MinDuration != nil {
+		d, _ := ptypes.Duration(gogo.DurationToProtoDuration(in.MinDuration))
+		filters = append(filters, &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_DurationFilter{
+				DurationFilter: &accesslog.DurationFilter{
+					Comparison: &accesslog.ComparisonFilter{
+						Op:    accesslog.ComparisonFilter_GE,
+						Value: &core.RuntimeUInt32{DefaultValue: uint32(d / time.Millisecond), RuntimeKey: "access_log.min_duration"},
+					},
+				},
+			},
+		})
+	}
+	if len(in.ResponseFlags) > 0 {
+		filters = append(filters, &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_ResponseFlagFilter{
+				ResponseFlagFilter: &accesslog.ResponseFlagFilter{Flags: in.ResponseFlags},
+			},
+		})
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	default:
+		return &accesslog.AccessLogFilter{
+			FilterSpecifier: &accesslog.AccessLogFilter_OrFilter{
+				OrFilter: &accesslog.OrFilter{Filters: filters},
+			},
+		}
+	}
+}
+
+// cachedJSONAccessLogFormat holds the last user provided json log format along with its parsed
+// form. The same format applies to every listener, so it is only parsed again when the mesh
+// config changes it.
+var cachedJSONAccessLogFormat struct {
+	sync.Mutex
+	raw    string
+	format *structpb.Struct
+}
+
+// jsonAccessLogFormat converts the user provided json log format into the struct expected by
+// envoy. It returns nil if the format cannot be parsed, in which case the default format is used.
+func jsonAccessLogFormat(raw string) *structpb.Struct {
+	cachedJSONAccessLogFormat.Lock()
+	defer cachedJSONAccessLogFormat.Unlock()
+	if cachedJSONAccessLogFormat.raw == raw {
+		return cachedJSONAccessLogFormat.format
+	}
+
+	var jsonLog *structpb.Struct
+	jsonFields := map[string]string{}
+	if err := json.Unmarshal([]byte(raw), &jsonFields); err == nil {
+		jsonLog = &structpb.Struct{
+			Fields: make(map[string]*structpb.Value, len(jsonFields)),
+		}
+		for key, value := range jsonFields {
+			jsonLog.Fields[key] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: value}}
+		}
+	} else {
+		log.Errorf("error parsing provided json log format, default log format will be used: %v", err)
+	}
+
+	cachedJSONAccessLogFormat.raw = raw
+	cachedJSONAccessLogFormat.format = jsonLog
+	return jsonLog
+}
+
 var (
 	// TODO: gauge should be reset on refresh, not the best way to represent errors but better
 	// than nothing.
@@ -968,8 +1063,20 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundListeners(env *model.E
 			if bindToPort && bind == "" {
 				bind = actualLocalHostAddress
 			}
+
+			// Without traffic capture the listeners are bound to the ports of the pod, so the ports
+			// the workload listens on itself are left to it rather than failing the whole update.
+			var workloadPorts map[int]bool
+			if noneMode {
+				workloadPorts = getWorkloadPorts(node)
+			}
 			for _, service := range services {
 				for _, servicePort := range service.Ports {
+					if workloadPorts[servicePort.Port] {
+						log.Debugf("buildSidecarOutboundListeners: skipping port %d of %s, used by the workload of proxy %s",
+							servicePort.Port, service.Hostname, node.ID)
+						continue
+					}
 					listenerOpts := buildListenerOpts{
 						env:            env,
 						proxy:          node,
@@ -1805,9 +1912,18 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 	filters = append(filters,
 		&http_conn.HttpFilter{Name: wellknown.CORS},
 		&http_conn.HttpFilter{Name: wellknown.Fault},
-		&http_conn.HttpFilter{Name: wellknown.Router},
 	)
 
+	// the requests routed to the wildcard hosts of the DNS ServiceEntries are resolved from their Host header
+	if (pluginParams.ListenerCategory == networking.EnvoyFilter_SIDECAR_OUTBOUND ||
+		pluginParams.DeprecatedListenerCategory == networking.EnvoyFilter_DeprecatedListenerMatch_SIDECAR_OUTBOUND ||
+		pluginParams.DeprecatedListenerCategory == networking.EnvoyFilter_DeprecatedListenerMatch_GATEWAY) &&
+		hasDynamicForwardProxyServices(env, pluginParams.Push, pluginParams.Node) {
+		filters = append(filters, buildDynamicForwardProxyFilter(env, pluginParams.Node))
+	}
+
+	filters = append(filters, &http_conn.HttpFilter{Name: wellknown.Router})
+
 	if httpOpts.connectionManager == nil {
 		httpOpts.connectionManager = &http_conn.HttpConnectionManager{}
 	}
@@ -1836,6 +1952,11 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 	notimeout := ptypes.DurationProto(0 * time.Second)
 	connectionManager.StreamIdleTimeout = notimeout
 
+	// the HTTP/2 connections of the listener migrate with a GOAWAY when it is drained after a push
+	if drainTimeout := httpDrainTimeout(pluginParams.Node); drainTimeout > 0 {
+		connectionManager.DrainTimeout = ptypes.DurationProto(drainTimeout)
+	}
+
 	if httpOpts.rds != "" {
 		rds := &http_conn.HttpConnectionManager_Rds{
 			Rds: &http_conn.Rds{
@@ -1853,13 +1974,16 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 		connectionManager.RouteSpecifier = &http_conn.HttpConnectionManager_RouteConfig{RouteConfig: httpOpts.routeConfig}
 	}
 
-	if env.Mesh.AccessLogFile != "" {
+	telemetry := proxyTelemetry(pluginParams.Push, pluginParams.Node)
+
+	if accessLogFile := telemetry.AccessLogFile(env.Mesh); accessLogFile != "" {
 		fl := &accesslogconfig.FileAccessLog{
-			Path: env.Mesh.AccessLogFile,
+			Path: accessLogFile,
 		}
 
 		acc := &accesslog.AccessLog{
-			Name: wellknown.FileAccessLog,
+			Name:   wellknown.FileAccessLog,
+			Filter: buildAccessLogFilter(telemetry.AccessLogFilter(), false),
 		}
 
 		buildAccessLog(pluginParams.Node, fl, env)
@@ -1873,7 +1997,7 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 		connectionManager.AccessLog = append(connectionManager.AccessLog, acc)
 	}
 
-	if env.Mesh.EnableEnvoyAccessLogService {
+	if telemetry.AccessLogServiceEnabled(env.Mesh) {
 		fl := &accesslogconfig.HttpGrpcAccessLogConfig{
 			CommonConfig: &accesslogconfig.CommonGrpcAccessLogConfig{
 				LogName: httpEnvoyAccessLogName,
@@ -1890,7 +2014,8 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 		}
 
 		acc := &accesslog.AccessLog{
-			Name: wellknown.HTTPGRPCAccessLog,
+			Name:   wellknown.HTTPGRPCAccessLog,
+			Filter: buildAccessLogFilter(telemetry.AccessLogFilter(), false),
 		}
 
 		if util.IsXDSMarshalingToAnyEnabled(pluginParams.Node) {
@@ -1902,7 +2027,7 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 		connectionManager.AccessLog = append(connectionManager.AccessLog, acc)
 	}
 
-	if env.Mesh.EnableTracing {
+	if telemetry.TracingEnabled(env.Mesh) {
 		tc := authn_model.GetTraceConfig()
 		connectionManager.Tracing = &http_conn.HttpConnectionManager_Tracing{
 			OperationName: httpOpts.direction,
@@ -1910,11 +2035,13 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 				Value: tc.ClientSampling,
 			},
 			RandomSampling: &envoy_type.Percent{
-				Value: tc.RandomSampling,
+				Value: proxyTraceSampling(pluginParams.Node, telemetry.TracingRandomSampling(tc.RandomSampling)),
 			},
 			OverallSampling: &envoy_type.Percent{
 				Value: tc.OverallSampling,
 			},
+			RequestHeadersForTags: telemetry.TracingRequestHeadersForTags(),
+			XXX_unrecognized:      buildTracingCustomTags(pluginParams.Node, telemetry.TracingCustomTags()),
 		}
 		connectionManager.GenerateRequestId = proto.BoolTrue
 	}
@@ -1922,6 +2049,37 @@ func buildHTTPConnectionManager(pluginParams *plugin.InputParams, env *model.Env
 	return connectionManager
 }
 
+// httpDrainTimeout returns the drain timeout of the HTTP connection managers of the proxy: its
+// sidecar.istio.io/httpDrainTimeout annotation if valid, PILOT_HTTP_DRAIN_TIMEOUT otherwise.
+func httpDrainTimeout(node *model.Proxy) time.Duration {
+	if timeout, err := time.ParseDuration(node.Metadata.HTTPDrainTimeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return features.HTTPDrainTimeout
+}
+
+// proxyTelemetry returns the telemetry configuration selected for the proxy by Telemetry resources.
+func proxyTelemetry(push *model.PushContext, node *model.Proxy) *model.ProxyTelemetry {
+	if push == nil || push.Env == nil {
+		return nil
+	}
+	return push.Telemetry(node)
+}
+
+// proxyTraceSampling returns the trace sampling percentage set by the proxy annotation, falling back to
+// defaultSampling if it is not set or invalid.
+func proxyTraceSampling(node *model.Proxy, defaultSampling float64) float64 {
+	if node == nil || node.Metadata == nil || node.Metadata.TraceSampling == "" {
+		return defaultSampling
+	}
+	sampling, err := strconv.ParseFloat(node.Metadata.TraceSampling, 64)
+	if err != nil || sampling < 0.0 || sampling > 100.0 {
+		log.Warnf("invalid trace sampling %q for proxy %s", node.Metadata.TraceSampling, node.ID)
+		return defaultSampling
+	}
+	return sampling
+}
+
 // buildListener builds and initializes a Listener proto based on the provided opts. It does not set any filters.
 func buildListener(opts buildListenerOpts) *xdsapi.Listener {
 	filterChains := make([]*listener.FilterChain, 0, len(opts.filterChainOpts))
@@ -2134,6 +2292,15 @@ func buildCompleteFilterChain(pluginParams *plugin.InputParams, mutable *plugin.
 // depending on value of proxy's IPAddresses. This function checks each element
 // and if there is at least one ipv4 address other than 127.0.0.1, it will use ipv4 address,
 // if all addresses are ipv6  addresses then ipv6 address will be used to get wildcard and local host address.
+// getWorkloadPorts returns the ports the workload of the proxy listens on.
+func getWorkloadPorts(node *model.Proxy) map[int]bool {
+	ports := make(map[int]bool, len(node.ServiceInstances))
+	for _, instance := range node.ServiceInstances {
+		ports[instance.Endpoint.Port] = true
+	}
+	return ports
+}
+
 func getActualWildcardAndLocalHost(node *model.Proxy) (string, string) {
 	for i := 0; i < len(node.IPAddresses); i++ {
 		addr := net.ParseIP(node.IPAddresses[i])
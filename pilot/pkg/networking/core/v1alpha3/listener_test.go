@@ -23,7 +23,10 @@ import (
 	"time"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	accesslogconfig "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
+	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
 	http_filter "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
 	tcp_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
 	"github.com/envoyproxy/go-control-plane/pkg/conversion"
@@ -34,6 +37,7 @@ import (
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
+	api "istio.io/api/type/v1beta1"
 
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
@@ -41,6 +45,8 @@ import (
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
@@ -497,6 +503,37 @@ func TestOutboundListenerConfig_WithSidecar(t *testing.T) {
 	testOutboundListenerConfigWithSidecarWithUseRemoteAddress(t, services...)
 }
 
+func TestOutboundListenerConfig_WithInterceptionModeNone(t *testing.T) {
+	services := []*model.Service{
+		buildServiceWithPort("test1.com", 8080, protocol.HTTP, tnow),
+		buildServiceWithPort("test2.com", 9090, protocol.TCP, tnow)}
+
+	// the workload of the proxy listens on 8080 itself
+	defer func(instances []*model.ServiceInstance) { proxyInstances = instances }(proxyInstances)
+	proxyInstances = []*model.ServiceInstance{{
+		Endpoint: model.NetworkEndpoint{Address: "1.1.1.1", Port: 8080, ServicePort: services[0].Ports[0]},
+		Service:  services[0],
+	}}
+	noneProxy := proxy
+	noneProxy.Metadata = &model.NodeMetadata{
+		ConfigNamespace:  "not-default",
+		IstioVersion:     "1.3",
+		InterceptionMode: "NONE",
+	}
+
+	// the HTTP proxy listener is always enabled without traffic capture
+	listeners := buildOutboundListeners(&fakePlugin{}, &noneProxy, nil, nil, services...)
+	if len(listeners) != 2 {
+		t.Fatalf("expected %d listeners, found %d", 2, len(listeners))
+	}
+	if l := findListenerByPort(listeners, 9090); l == nil || l.Name != "127.0.0.1_9090" {
+		t.Fatalf("expected an outbound listener bound to 127.0.0.1:9090, found %v", l)
+	}
+	if l := findListenerByPort(listeners, 8080); l != nil {
+		t.Fatalf("expected no outbound listener on the port of the workload, found %s", l.Name)
+	}
+}
+
 func TestGetActualWildcardAndLocalHost(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1211,7 +1248,412 @@ func TestOutboundListenerAccessLogs(t *testing.T) {
 			if fc.AccessLog == nil {
 				t.Fatal("expected access log configuration")
 			}
+			found := false
+			for _, al := range fc.AccessLog {
+				if al.Name == tcpGRPCAccessLog {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected TCP access log service configuration, got %v", fc.AccessLog)
+			}
+		}
+	}
+}
+
+func TestBuildAccessLogFormat(t *testing.T) {
+	customJSON := `{"trace_id": "%REQ(X-B3-TRACEID)%", "route_name": "%ROUTE_NAME%", "upstream_host": "%UPSTREAM_HOST%"}`
+	cases := []struct {
+		name       string
+		encoding   meshconfig.MeshConfig_AccessLogEncoding
+		format     string
+		wantText   string
+		wantFields map[string]string
+	}{
+		{
+			name:     "default text",
+			encoding: meshconfig.MeshConfig_TEXT,
+			wantText: EnvoyTextLogFormat13,
+		},
+		{
+			name:     "custom text",
+			encoding: meshconfig.MeshConfig_TEXT,
+			format:   "%START_TIME% %REQ(X-B3-TRACEID)%\n",
+			wantText: "%START_TIME% %REQ(X-B3-TRACEID)%\n",
+		},
+		{
+			name:     "custom text without newline",
+			encoding: meshconfig.MeshConfig_TEXT,
+			format:   "%START_TIME% %REQ(X-B3-TRACEID)%",
+			wantText: "%START_TIME% %REQ(X-B3-TRACEID)%\n",
+		},
+		{
+			name:     "custom json",
+			encoding: meshconfig.MeshConfig_JSON,
+			format:   customJSON,
+			wantFields: map[string]string{
+				"trace_id":      "%REQ(X-B3-TRACEID)%",
+				"route_name":    "%ROUTE_NAME%",
+				"upstream_host": "%UPSTREAM_HOST%",
+			},
+		},
+		{
+			name:     "custom json from cache",
+			encoding: meshconfig.MeshConfig_JSON,
+			format:   customJSON,
+			wantFields: map[string]string{
+				"trace_id":      "%REQ(X-B3-TRACEID)%",
+				"route_name":    "%ROUTE_NAME%",
+				"upstream_host": "%UPSTREAM_HOST%",
+			},
+		},
+		{
+			name:     "invalid json",
+			encoding: meshconfig.MeshConfig_JSON,
+			format:   `{"code": 200}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			env := buildListenerEnv(nil)
+			env.Mesh.AccessLogEncoding = tt.encoding
+			env.Mesh.AccessLogFormat = tt.format
+			fl := &accesslogconfig.FileAccessLog{}
+			buildAccessLog(&proxy14, fl, &env)
+
+			if tt.encoding == meshconfig.MeshConfig_TEXT {
+				if got := fl.GetFormat(); got != tt.wantText {
+					t.Errorf("got text format %q, want %q", got, tt.wantText)
+				}
+				return
+			}
+
+			got := fl.GetJsonFormat()
+			if tt.wantFields == nil {
+				if got != EnvoyJSONLogFormat13 {
+					t.Errorf("expected the default json format, got %v", got)
+				}
+				return
+			}
+			if len(got.GetFields()) != len(tt.wantFields) {
+				t.Fatalf("got json fields %v, want %v", got.GetFields(), tt.wantFields)
+			}
+			for key, want := range tt.wantFields {
+				if v := got.Fields[key].GetStringValue(); v != want {
+					t.Errorf("got %q for field %s, want %q", v, key, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildAccessLogFilter(t *testing.T) {
+	statusCode := &accesslog.AccessLogFilter{
+		FilterSpecifier: &accesslog.AccessLogFilter_StatusCodeFilter{
+			StatusCodeFilter: &accesslog.StatusCodeFilter{
+				Comparison: &accesslog.ComparisonFilter{
+					Op:    accesslog.ComparisonFilter_GE,
+					Value: &core.RuntimeUInt32{DefaultValue: 400, RuntimeKey: "access_log.min_status_code"},
+				},
+			},
+		},
+	}
+	duration := &accesslog.AccessLogFilter{
+		FilterSpecifier: &accesslog.AccessLogFilter_DurationFilter{
+			DurationFilter: &accesslog.DurationFilter{
+				Comparison: &accesslog.ComparisonFilter{
+					Op:    accesslog.ComparisonFilter_GE,
+					Value: &core.RuntimeUInt32{DefaultValue: 1500, RuntimeKey: "access_log.min_duration"},
+				},
+			},
+		},
+	}
+	responseFlags := &accesslog.AccessLogFilter{
+		FilterSpecifier: &accesslog.AccessLogFilter_ResponseFlagFilter{
+			ResponseFlagFilter: &accesslog.ResponseFlagFilter{Flags: []string{"UH", "UF"}},
+		},
+	}
+
+	cases := []struct {
+		name string
+		in   *telemetry.AccessLogFilter
+		tcp  bool
+		want *accesslog.AccessLogFilter
+	}{
+		{
+			name: "no filter",
+		},
+		{
+			name: "empty filter",
+			in:   &telemetry.AccessLogFilter{},
+		},
+		{
+			name: "errors only",
+			in:   &telemetry.AccessLogFilter{MinStatusCode: 400},
+			want: statusCode,
+		},
+		{
+			name: "errors only for tcp",
+			in:   &telemetry.AccessLogFilter{MinStatusCode: 400},
+			tcp:  true,
+		},
+		{
+			name: "all conditions",
+			in: &telemetry.AccessLogFilter{
+				MinStatusCode: 400,
+				MinDuration:   types.DurationProto(1500 * time.Millisecond),
+				ResponseFlags: []string{"UH", "UF"},
+			},
+			want: &accesslog.AccessLogFilter{
+				FilterSpecifier: &accesslog.AccessLogFilter_OrFilter{
+					OrFilter: &accesslog.OrFilter{Filters: []*accesslog.AccessLogFilter{statusCode, duration, responseFlags}},
+				},
+			},
+		},
+		{
+			name: "all conditions for tcp",
+			in: &telemetry.AccessLogFilter{
+				MinStatusCode: 400,
+				MinDuration:   types.DurationProto(1500 * time.Millisecond),
+				ResponseFlags: []string{"UH", "UF"},
+			},
+			tcp: true,
+			want: &accesslog.AccessLogFilter{
+				FilterSpecifier: &accesslog.AccessLogFilter_OrFilter{
+					OrFilter: &accesslog.OrFilter{Filters: []*accesslog.AccessLogFilter{duration, responseFlags}},
+				},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildAccessLogFilter(tt.in, tt.tcp); !proto.Equal(got, tt.want) {
+				t.Errorf("got filter %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPConnectionManagerTelemetry(t *testing.T) {
+	env := buildListenerEnv(nil)
+	env.Mesh.AccessLogFile = ""
+	env.Mesh.EnableTracing = false
+	env.Mesh.EnableEnvoyAccessLogService = false
+	env.Mesh.RootNamespace = "istio-system"
+	env.IstioConfigStore = &fakes.IstioConfigStore{
+		ListStub: func(typ, namespace string) ([]model.Config, error) {
+			if typ != schemas.Telemetry.Type {
+				return nil, nil
+			}
+			return []model.Config{{
+				ConfigMeta: model.ConfigMeta{
+					Type:      schemas.Telemetry.Type,
+					Name:      "reviews-debug",
+					Namespace: "not-default",
+				},
+				Spec: &telemetry.Telemetry{
+					Selector: &api.WorkloadSelector{
+						MatchLabels: map[string]string{"app": "reviews"},
+					},
+					Tracing: &telemetry.Tracing{
+						RandomSamplingPercentage: &types.DoubleValue{Value: 100},
+						RequestHeadersForTags:    []string{"x-tenant-id"},
+						CustomTags: []*telemetry.CustomTag{
+							{Tag: "tenant", Literal: "acme"},
+							{Tag: "build", Environment: "BUILD_ID", DefaultValue: "unknown"},
+						},
+					},
+					AccessLogging: &telemetry.AccessLogging{
+						Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoy}},
+						Filter:    &telemetry.AccessLogFilter{MinStatusCode: 400},
+					},
+				},
+			}, {
+				ConfigMeta: model.ConfigMeta{
+					Type:      schemas.Telemetry.Type,
+					Name:      "ratings-als",
+					Namespace: "not-default",
+				},
+				Spec: &telemetry.Telemetry{
+					Selector: &api.WorkloadSelector{
+						MatchLabels: map[string]string{"app": "ratings"},
+					},
+					AccessLogging: &telemetry.AccessLogging{
+						Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoyALS}},
+					},
+				},
+			}}, nil
+		},
+	}
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatalf("error in initializing push context: %s", err)
+	}
+
+	cases := []struct {
+		name      string
+		labels    labels.Collection
+		accessLog bool
+		als       bool
+		tracing   bool
+	}{
+		{
+			name:      "selected workload",
+			labels:    labels.Collection{{"app": "reviews"}},
+			accessLog: true,
+			tracing:   true,
+		},
+		{
+			name:   "access log service workload",
+			labels: labels.Collection{{"app": "ratings"}},
+			als:    true,
+		},
+		{
+			name:   "other workload",
+			labels: labels.Collection{{"app": "details"}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			node := proxy
+			node.WorkloadLabels = tt.labels
+			params := &plugin.InputParams{
+				Node: &node,
+				Push: env.PushContext,
+			}
+			hcm := buildHTTPConnectionManager(params, &env, &httpListenerOpts{direction: http_filter.HttpConnectionManager_Tracing_EGRESS}, nil)
+
+			fileLog, als := false, false
+			for _, al := range hcm.AccessLog {
+				switch al.Name {
+				case xdsutil.FileAccessLog:
+					fileLog = true
+					if code := al.GetFilter().GetStatusCodeFilter().GetComparison().GetValue().GetDefaultValue(); code != 400 {
+						t.Errorf("expected the file access log to be filtered on status code 400, got %v", al.Filter)
+					}
+				case xdsutil.HTTPGRPCAccessLog:
+					als = true
+				}
+			}
+			if fileLog != tt.accessLog {
+				t.Errorf("expected file access log %v, got %v", tt.accessLog, fileLog)
+			}
+			if als != tt.als {
+				t.Errorf("expected access log service %v, got %v", tt.als, als)
+			}
+			if (hcm.Tracing != nil) != tt.tracing {
+				t.Fatalf("expected tracing %v, got %v", tt.tracing, hcm.Tracing)
+			}
+			if tt.tracing && hcm.Tracing.RandomSampling.Value != 100 {
+				t.Errorf("expected random sampling 100, got %v", hcm.Tracing.RandomSampling.Value)
+			}
+			if tt.tracing && !reflect.DeepEqual(hcm.Tracing.RequestHeadersForTags, []string{"x-tenant-id"}) {
+				t.Errorf("expected x-tenant-id tag, got %v", hcm.Tracing.RequestHeadersForTags)
+			}
+			if tt.tracing {
+				want := []*envoyCustomTag{
+					{Tag: "tenant", Literal: &envoyCustomTagLiteral{Value: "acme"}},
+					{Tag: "build", Environment: &envoyCustomTagEnvironment{Name: "BUILD_ID", DefaultValue: "unknown"}},
+				}
+				if got := tracingCustomTags(t, hcm.Tracing); !reflect.DeepEqual(got, want) {
+					t.Errorf("got custom tags %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+// tracingCustomTags decodes the custom_tags field of the marshaled tracing configuration.
+func tracingCustomTags(t *testing.T, tracing *http_filter.HttpConnectionManager_Tracing) []*envoyCustomTag {
+	t.Helper()
+	b, err := proto.Marshal(tracing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tags []*envoyCustomTag
+	buf := proto.NewBuffer(b)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil || key == 0 {
+			break
+		}
+		var field []byte
+		switch key & 7 {
+		case proto.WireBytes:
+			field, err = buf.DecodeRawBytes(false)
+		case proto.WireVarint:
+			_, err = buf.DecodeVarint()
+		default:
+			t.Fatalf("unexpected wire type in %x", b)
 		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key>>3 != tracingCustomTagsField {
+			continue
+		}
+		tag := &envoyCustomTag{}
+		if err := proto.Unmarshal(field, tag); err != nil {
+			t.Fatal(err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func TestProxyTraceSampling(t *testing.T) {
+	cases := []struct {
+		name     string
+		sampling string
+		expect   float64
+	}{
+		{"unset", "", 1},
+		{"override", "0.1", 0.1},
+		{"out of range", "150", 1},
+		{"invalid", "all", 1},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			node := &model.Proxy{Metadata: &model.NodeMetadata{TraceSampling: tt.sampling}}
+			if got := proxyTraceSampling(node, 1); got != tt.expect {
+				t.Errorf("got sampling %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestHTTPDrainTimeout(t *testing.T) {
+	defaultTimeout := features.HTTPDrainTimeout
+	features.HTTPDrainTimeout = 10 * time.Second
+	defer func() { features.HTTPDrainTimeout = defaultTimeout }()
+
+	env := buildListenerEnv(nil)
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatalf("error in initializing push context: %s", err)
+	}
+
+	cases := []struct {
+		name    string
+		timeout string
+		expect  time.Duration
+	}{
+		{"unset", "", 10 * time.Second},
+		{"override", "30s", 30 * time.Second},
+		{"invalid", "forever", 10 * time.Second},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			node := proxy
+			node.Metadata = &model.NodeMetadata{HTTPDrainTimeout: tt.timeout}
+			params := &plugin.InputParams{
+				Node: &node,
+				Push: env.PushContext,
+			}
+			hcm := buildHTTPConnectionManager(params, &env, &httpListenerOpts{direction: http_filter.HttpConnectionManager_Tracing_EGRESS}, nil)
+			if got, _ := ptypes.Duration(hcm.DrainTimeout); got != tt.expect {
+				t.Errorf("got drain timeout %v, want %v", got, tt.expect)
+			}
+		})
 	}
 }
 
@@ -21,14 +21,26 @@ import (
 
 	apiv2 "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	"github.com/golang/protobuf/ptypes/wrappers"
 
 	"istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
+// remotePriorityOffset is added to the locality priority of endpoints that are outside of the
+// network or cluster of the proxy, so that they rank below every endpoint of the proxy's own
+// cluster while keeping their relative locality order.
+const remotePriorityOffset = 5
+
+// ApplyLocalityLBSetting applies the locality load balancing settings to the load assignment,
+// relative to a proxy in the given locality, network and cluster. The network and cluster may be
+// empty if unknown.
 func ApplyLocalityLBSetting(
 	locality *core.Locality,
+	network string,
+	clusterID string,
 	loadAssignment *apiv2.ClusterLoadAssignment,
 	localityLB *v1alpha3.LocalityLoadBalancerSetting,
 	enableFailover bool,
@@ -42,7 +54,10 @@ func ApplyLocalityLBSetting(
 		applyLocalityWeight(locality, loadAssignment, localityLB.GetDistribute())
 	} else if enableFailover {
 		// Failover needs outlier detection, otherwise Envoy will never drop down to a lower priority.
-		applyLocalityFailover(locality, loadAssignment, localityLB.GetFailover())
+		if features.LocalityLbPreferLocalCluster {
+			splitRemoteEndpoints(network, clusterID, loadAssignment)
+		}
+		applyLocalityFailover(locality, network, clusterID, loadAssignment, localityLB.GetFailover())
 	}
 }
 
@@ -106,6 +121,8 @@ func applyLocalityWeight(
 // set locality loadbalancing priority
 func applyLocalityFailover(
 	locality *core.Locality,
+	network string,
+	clusterID string,
 	loadAssignment *apiv2.ClusterLoadAssignment,
 	failover []*v1alpha3.LocalityLoadBalancerSetting_Failover) {
 	// key is priority, value is the index of the LocalityLbEndpoints in ClusterLoadAssignment
@@ -130,6 +147,12 @@ func applyLocalityFailover(
 				}
 			}
 		}
+		// endpoints in another network or cluster are only used once the proxy's own
+		// cluster has no healthy endpoints left: local cluster, then the remote clusters
+		// of the same region, then anywhere.
+		if features.LocalityLbPreferLocalCluster && isRemote(network, clusterID, localityEndpoint) {
+			priority += remotePriorityOffset
+		}
 		loadAssignment.Endpoints[i].Priority = uint32(priority)
 		priorityMap[priority] = append(priorityMap[priority], i)
 	}
@@ -154,3 +177,88 @@ func applyLocalityFailover(
 	}
 
 }
+
+// splitRemoteEndpoints moves the endpoints that are outside of the network or cluster of the proxy
+// into LocalityLbEndpoints of their own, so they can be assigned a different priority than the
+// local endpoints of the same locality. The load assignment is expected to be a copy owned by the
+// caller; the LocalityLbEndpoints themselves are not mutated.
+func splitRemoteEndpoints(network, clusterID string, loadAssignment *apiv2.ClusterLoadAssignment) {
+	if network == "" && clusterID == "" {
+		return
+	}
+	var out []*endpoint.LocalityLbEndpoints
+	for i, localityEndpoint := range loadAssignment.Endpoints {
+		var local, remote []*endpoint.LbEndpoint
+		for _, lbEp := range localityEndpoint.LbEndpoints {
+			if isRemoteEndpoint(network, clusterID, lbEp) {
+				remote = append(remote, lbEp)
+			} else {
+				local = append(local, lbEp)
+			}
+		}
+		if len(remote) == 0 || len(local) == 0 {
+			if out != nil {
+				out = append(out, localityEndpoint)
+			}
+			continue
+		}
+		if out == nil {
+			out = make([]*endpoint.LocalityLbEndpoints, 0, len(loadAssignment.Endpoints)+1)
+			out = append(out, loadAssignment.Endpoints[:i]...)
+		}
+		out = append(out,
+			copyLocalityLbEndpoints(localityEndpoint, local),
+			copyLocalityLbEndpoints(localityEndpoint, remote))
+	}
+	if out != nil {
+		loadAssignment.Endpoints = out
+	}
+}
+
+func copyLocalityLbEndpoints(base *endpoint.LocalityLbEndpoints, lbEndpoints []*endpoint.LbEndpoint) *endpoint.LocalityLbEndpoints {
+	out := *base
+	out.LbEndpoints = lbEndpoints
+	if base.LoadBalancingWeight != nil {
+		var weight uint32
+		for _, lbEp := range lbEndpoints {
+			if lbEp.LoadBalancingWeight != nil {
+				weight += lbEp.LoadBalancingWeight.Value
+			} else {
+				weight++
+			}
+		}
+		out.LoadBalancingWeight = &wrappers.UInt32Value{Value: weight}
+	}
+	return &out
+}
+
+// isRemote returns true if all the endpoints of the LocalityLbEndpoints are outside of the network
+// or cluster of the proxy. After splitRemoteEndpoints, the endpoints of a locality are either all
+// local or all remote.
+func isRemote(network, clusterID string, localityEndpoint *endpoint.LocalityLbEndpoints) bool {
+	if len(localityEndpoint.LbEndpoints) == 0 {
+		return false
+	}
+	for _, lbEp := range localityEndpoint.LbEndpoints {
+		if !isRemoteEndpoint(network, clusterID, lbEp) {
+			return false
+		}
+	}
+	return true
+}
+
+// isRemoteEndpoint checks the network and cluster recorded in the istio metadata of the endpoint.
+// Endpoints without that metadata are considered local.
+func isRemoteEndpoint(network, clusterID string, lbEp *endpoint.LbEndpoint) bool {
+	if network != "" {
+		if epNetwork := util.IstioMetadata(lbEp.Metadata, "network"); epNetwork != "" && epNetwork != network {
+			return true
+		}
+	}
+	if clusterID != "" {
+		if epCluster := util.IstioMetadata(lbEp.Metadata, "cluster"); epCluster != "" && epCluster != clusterID {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,7 @@ import (
 	envoycore "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	"github.com/gogo/protobuf/types"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	. "github.com/onsi/gomega"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
@@ -29,6 +30,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
+	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
 )
@@ -65,7 +67,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 			t.Run(tt.name, func(t *testing.T) {
 				env := buildEnvForClustersWithDistribute(tt.distribute)
 				cluster := buildFakeCluster()
-				ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+				ApplyLocalityLBSetting(locality, "", "", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
 				weights := make([]int, 0)
 				for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 					weights = append(weights, int(localityEndpoint.LoadBalancingWeight.GetValue()))
@@ -81,7 +83,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewGomegaWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildFakeCluster()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, "", "", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality.Region == locality.Region {
 				if localityEndpoint.Locality.Zone == locality.Zone {
@@ -107,7 +109,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewGomegaWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildSmallCluster()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, "", "", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality.Region == locality.Region {
 				if localityEndpoint.Locality.Zone == locality.Zone {
@@ -133,7 +135,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewGomegaWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildSmallClusterWithNilLocalities()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, "", "", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality == nil {
 				g.Expect(localityEndpoint.Priority).To(Equal(uint32(2)))
@@ -155,6 +157,49 @@ func TestApplyLocalitySetting(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Failover: remote clusters and networks", func(t *testing.T) {
+		env := buildEnvForClustersWithFailover()
+		cluster := buildMultiClusterCluster()
+		ApplyLocalityLBSetting(locality, "network1", "cluster1", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+
+		got := map[string]uint32{}
+		weights := map[string]uint32{}
+		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
+			for _, lbEp := range localityEndpoint.LbEndpoints {
+				addr := lbEp.GetEndpoint().GetAddress().GetSocketAddress().GetAddress()
+				got[addr] = localityEndpoint.Priority
+				weights[addr] = localityEndpoint.LoadBalancingWeight.GetValue()
+			}
+		}
+		expected := map[string]uint32{
+			// local cluster, ordered by locality
+			"10.0.0.1": 0,
+			"10.0.0.2": 1,
+			"10.0.0.4": 2,
+			// same region in a remote cluster of the network
+			"10.0.0.3": 3,
+			// gateway of a remote network in the same region
+			"1.1.1.1": 3,
+			// anywhere else
+			"10.0.0.5": 4,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got priorities %v expected %v", got, expected)
+		}
+		if weights["10.0.0.1"] != 1 || weights["1.1.1.1"] != 4 {
+			t.Errorf("Got weights %v", weights)
+		}
+	})
+
+	t.Run("Failover: remote clusters without proxy cluster", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		env := buildEnvForClustersWithFailover()
+		cluster := buildMultiClusterCluster()
+		ApplyLocalityLBSetting(locality, "", "", cluster.LoadAssignment, env.Mesh.LocalityLbSetting, true)
+		g.Expect(cluster.LoadAssignment.Endpoints).To(HaveLen(4))
+		g.Expect(cluster.LoadAssignment.Endpoints[0].Priority).To(Equal(uint32(0)))
+	})
 }
 
 func buildEnvForClustersWithDistribute(distribute []*networking.LocalityLoadBalancerSetting_Distribute) *model.Environment {
@@ -369,6 +414,67 @@ func buildSmallCluster() *apiv2.Cluster {
 	}
 }
 
+func buildLbEndpoint(address, network, clusterID string, weight uint32) *endpoint.LbEndpoint {
+	return &endpoint.LbEndpoint{
+		HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+			Endpoint: &endpoint.Endpoint{
+				Address: util.BuildAddress(address, 80),
+			},
+		},
+		LoadBalancingWeight: &wrappers.UInt32Value{Value: weight},
+		Metadata:            util.BuildLbEndpointMetadata("", network, clusterID, ""),
+	}
+}
+
+func buildMultiClusterCluster() *apiv2.Cluster {
+	return &apiv2.Cluster{
+		Name: "outbound|8080||test.example.org",
+		LoadAssignment: &apiv2.ClusterLoadAssignment{
+			ClusterName: "outbound|8080||test.example.org",
+			Endpoints: []*endpoint.LocalityLbEndpoints{
+				{
+					Locality: &envoycore.Locality{
+						Region:  "region1",
+						Zone:    "zone1",
+						SubZone: "subzone1",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{
+						buildLbEndpoint("10.0.0.1", "network1", "cluster1", 1),
+						buildLbEndpoint("10.0.0.3", "network1", "cluster2", 1),
+						buildLbEndpoint("1.1.1.1", "network2", "", 3),
+					},
+					LoadBalancingWeight: &wrappers.UInt32Value{Value: 5},
+				},
+				{
+					Locality: &envoycore.Locality{
+						Region: "region1",
+						Zone:   "zone2",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{
+						buildLbEndpoint("10.0.0.2", "network1", "cluster1", 1),
+					},
+				},
+				{
+					Locality: &envoycore.Locality{
+						Region: "region2",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{
+						buildLbEndpoint("10.0.0.4", "network1", "cluster1", 1),
+					},
+				},
+				{
+					Locality: &envoycore.Locality{
+						Region: "region2",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{
+						buildLbEndpoint("10.0.0.5", "network1", "cluster2", 1),
+					},
+				},
+			},
+		},
+	}
+}
+
 func buildSmallClusterWithNilLocalities() *apiv2.Cluster {
 	return &apiv2.Cluster{
 		Name: "outbound|8080||test.example.org",
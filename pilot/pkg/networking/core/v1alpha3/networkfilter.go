@@ -16,8 +16,10 @@ package v1alpha3
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
 	accesslogconfig "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
 	accesslog "github.com/envoyproxy/go-control-plane/envoy/config/filter/accesslog/v2"
@@ -28,6 +30,7 @@ import (
 	"github.com/envoyproxy/go-control-plane/pkg/conversion"
 	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
+	structpb "github.com/golang/protobuf/ptypes/struct"
 
 	networking "istio.io/api/networking/v1alpha3"
 
@@ -56,15 +59,27 @@ func buildInboundNetworkFilters(env *model.Environment, node *model.Proxy, insta
 
 // setAccessLog sets the AccessLog configuration in the given TcpProxy instance.
 func setAccessLog(env *model.Environment, node *model.Proxy, config *tcp_proxy.TcpProxy) {
-	if env.Mesh.AccessLogFile != "" {
+	setRouteAccessLog(env, node, config, "")
+}
+
+// setRouteAccessLog sets the AccessLog configuration in the given TcpProxy instance. The
+// %ROUTE_NAME% of the file access log format, only known by Envoy for HTTP, is replaced by the
+// given route name if any.
+func setRouteAccessLog(env *model.Environment, node *model.Proxy, config *tcp_proxy.TcpProxy, routeName string) {
+	telemetry := proxyTelemetry(env.PushContext, node)
+	if accessLogFile := telemetry.AccessLogFile(env.Mesh); accessLogFile != "" {
 		fl := &accesslogconfig.FileAccessLog{
-			Path: env.Mesh.AccessLogFile,
+			Path: accessLogFile,
 		}
 
 		acc := &accesslog.AccessLog{
-			Name: wellknown.FileAccessLog,
+			Name:   wellknown.FileAccessLog,
+			Filter: buildAccessLogFilter(telemetry.AccessLogFilter(), true),
 		}
 		buildAccessLog(node, fl, env)
+		if routeName != "" {
+			setAccessLogRouteName(fl, routeName)
+		}
 
 		if util.IsXDSMarshalingToAnyEnabled(node) {
 			acc.ConfigType = &accesslog.AccessLog_TypedConfig{TypedConfig: util.MessageToAny(fl)}
@@ -75,13 +90,65 @@ func setAccessLog(env *model.Environment, node *model.Proxy, config *tcp_proxy.T
 
 		config.AccessLog = append(config.AccessLog, acc)
 	}
+
+	if telemetry.AccessLogServiceEnabled(env.Mesh) {
+		fl := &accesslogconfig.TcpGrpcAccessLogConfig{
+			CommonConfig: &accesslogconfig.CommonGrpcAccessLogConfig{
+				LogName: tcpEnvoyAccessLogName,
+				GrpcService: &core.GrpcService{
+					TargetSpecifier: &core.GrpcService_EnvoyGrpc_{
+						EnvoyGrpc: &core.GrpcService_EnvoyGrpc{
+							ClusterName: EnvoyAccessLogCluster,
+						},
+					},
+				},
+			},
+		}
+
+		acc := &accesslog.AccessLog{
+			Name:   tcpGRPCAccessLog,
+			Filter: buildAccessLogFilter(telemetry.AccessLogFilter(), true),
+		}
+
+		if util.IsXDSMarshalingToAnyEnabled(node) {
+			acc.ConfigType = &accesslog.AccessLog_TypedConfig{TypedConfig: util.MessageToAny(fl)}
+		} else {
+			acc.ConfigType = &accesslog.AccessLog_Config{Config: util.MessageToStruct(fl)}
+		}
+
+		config.AccessLog = append(config.AccessLog, acc)
+	}
+}
+
+// setAccessLogRouteName replaces the %ROUTE_NAME% of the format of the file access log by the
+// route name. The json formats are shared by all the listeners, so they are copied.
+func setAccessLogRouteName(fl *accesslogconfig.FileAccessLog, routeName string) {
+	switch format := fl.AccessLogFormat.(type) {
+	case *accesslogconfig.FileAccessLog_Format:
+		format.Format = strings.Replace(format.Format, routeNameLogOperator, routeName, -1)
+	case *accesslogconfig.FileAccessLog_JsonFormat:
+		jsonLog := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(format.JsonFormat.Fields))}
+		for key, value := range format.JsonFormat.Fields {
+			if str, ok := value.Kind.(*structpb.Value_StringValue); ok && strings.Contains(str.StringValue, routeNameLogOperator) {
+				value = &structpb.Value{Kind: &structpb.Value_StringValue{
+					StringValue: strings.Replace(str.StringValue, routeNameLogOperator, routeName, -1),
+				}}
+			}
+			jsonLog.Fields[key] = value
+		}
+		fl.AccessLogFormat = &accesslogconfig.FileAccessLog_JsonFormat{JsonFormat: jsonLog}
+	}
 }
 
 // setAccessLogAndBuildTCPFilter sets the AccessLog configuration in the given
 // TcpProxy instance and builds a TCP filter out of it.
 func setAccessLogAndBuildTCPFilter(env *model.Environment, node *model.Proxy, config *tcp_proxy.TcpProxy) *listener.Filter {
 	setAccessLog(env, node, config)
+	return buildTCPFilter(node, config)
+}
 
+// buildTCPFilter builds a TCP filter out of the given TcpProxy instance.
+func buildTCPFilter(node *model.Proxy, config *tcp_proxy.TcpProxy) *listener.Filter {
 	tcpFilter := &listener.Filter{
 		Name: wellknown.TCPProxy,
 	}
@@ -98,6 +165,13 @@ func setAccessLogAndBuildTCPFilter(env *model.Environment, node *model.Proxy, co
 func buildOutboundNetworkFiltersWithSingleDestination(env *model.Environment, node *model.Proxy,
 	clusterName string, port *model.Port) []*listener.Filter {
 
+	tcpProxy := buildSingleDestinationTCPProxy(node, clusterName)
+	tcpFilter := setAccessLogAndBuildTCPFilter(env, node, tcpProxy)
+	return buildNetworkFiltersStack(node, port, tcpFilter, clusterName, clusterName)
+}
+
+// buildSingleDestinationTCPProxy builds a TcpProxy forwarding to a single cluster.
+func buildSingleDestinationTCPProxy(node *model.Proxy, clusterName string) *tcp_proxy.TcpProxy {
 	tcpProxy := &tcp_proxy.TcpProxy{
 		StatPrefix:       clusterName,
 		ClusterSpecifier: &tcp_proxy.TcpProxy_Cluster{Cluster: clusterName},
@@ -108,15 +182,13 @@ func buildOutboundNetworkFiltersWithSingleDestination(env *model.Environment, no
 	if idleTimeout > 0 && err == nil {
 		tcpProxy.IdleTimeout = ptypes.DurationProto(idleTimeout)
 	}
-
-	tcpFilter := setAccessLogAndBuildTCPFilter(env, node, tcpProxy)
-	return buildNetworkFiltersStack(node, port, tcpFilter, clusterName, clusterName)
+	return tcpProxy
 }
 
-// buildOutboundNetworkFiltersWithWeightedClusters takes a set of weighted
-// destination routes and builds a stack of network filters.
-func buildOutboundNetworkFiltersWithWeightedClusters(env *model.Environment, node *model.Proxy, routes []*networking.RouteDestination,
-	push *model.PushContext, port *model.Port, configMeta model.ConfigMeta) []*listener.Filter {
+// buildWeightedClustersTCPProxy takes a set of weighted destination routes and builds a TcpProxy
+// forwarding to their clusters. It returns the TcpProxy and the name of its first cluster.
+func buildWeightedClustersTCPProxy(node *model.Proxy, routes []*networking.RouteDestination,
+	push *model.PushContext, port *model.Port, configMeta model.ConfigMeta) (*tcp_proxy.TcpProxy, string) {
 
 	statPrefix := fmt.Sprintf("%s.%s", configMeta.Name, configMeta.Namespace)
 	clusterSpecifier := &tcp_proxy.TcpProxy_WeightedClusters{
@@ -146,9 +218,7 @@ func buildOutboundNetworkFiltersWithWeightedClusters(env *model.Environment, nod
 	}
 
 	// TODO: Need to handle multiple cluster names for Redis
-	clusterName := clusterSpecifier.WeightedClusters.Clusters[0].Name
-	tcpFilter := setAccessLogAndBuildTCPFilter(env, node, proxyConfig)
-	return buildNetworkFiltersStack(node, port, tcpFilter, statPrefix, clusterName)
+	return proxyConfig, clusterSpecifier.WeightedClusters.Clusters[0].Name
 }
 
 // buildNetworkFiltersStack builds a slice of network filters based on
@@ -184,12 +254,36 @@ func buildOutboundNetworkFilters(env *model.Environment, node *model.Proxy,
 	routes []*networking.RouteDestination, push *model.PushContext,
 	port *model.Port, configMeta model.ConfigMeta) []*listener.Filter {
 
+	tcpProxy, clusterName := buildOutboundTCPProxy(node, routes, push, port, configMeta)
+	tcpFilter := setAccessLogAndBuildTCPFilter(env, node, tcpProxy)
+	return buildNetworkFiltersStack(node, port, tcpFilter, tcpProxy.StatPrefix, clusterName)
+}
+
+// buildGatewayPassthroughNetworkFilters generates the TCP proxy network filter of a TLS passthrough
+// filter chain of a gateway. As the connections are routed on their SNI only, which misroutes are
+// invisible otherwise, the stats of the filter chain are prefixed with its SNI hosts and the
+// virtual service routing them, and its access logs name the virtual service as their route.
+func buildGatewayPassthroughNetworkFilters(env *model.Environment, node *model.Proxy,
+	routes []*networking.RouteDestination, push *model.PushContext,
+	port *model.Port, configMeta model.ConfigMeta, sniHosts []string) []*listener.Filter {
+
+	routeName := fmt.Sprintf("%s.%s", configMeta.Name, configMeta.Namespace)
+	tcpProxy, clusterName := buildOutboundTCPProxy(node, routes, push, port, configMeta)
+	tcpProxy.StatPrefix = fmt.Sprintf("%s.%s", strings.Join(sniHosts, "_"), routeName)
+	setRouteAccessLog(env, node, tcpProxy, routeName)
+	return buildNetworkFiltersStack(node, port, buildTCPFilter(node, tcpProxy), tcpProxy.StatPrefix, clusterName)
+}
+
+// buildOutboundTCPProxy builds the TcpProxy forwarding to the destinations of the routes. It
+// returns the TcpProxy and the name of its first cluster.
+func buildOutboundTCPProxy(node *model.Proxy, routes []*networking.RouteDestination, push *model.PushContext,
+	port *model.Port, configMeta model.ConfigMeta) (*tcp_proxy.TcpProxy, string) {
 	if len(routes) == 1 {
 		service := node.SidecarScope.ServiceForHostname(host.Name(routes[0].Destination.Host), push.ServiceByHostnameAndNamespace)
 		clusterName := istio_route.GetDestinationCluster(routes[0].Destination, service, port.Port)
-		return buildOutboundNetworkFiltersWithSingleDestination(env, node, clusterName, port)
+		return buildSingleDestinationTCPProxy(node, clusterName), clusterName
 	}
-	return buildOutboundNetworkFiltersWithWeightedClusters(env, node, routes, push, port, configMeta)
+	return buildWeightedClustersTCPProxy(node, routes, push, port, configMeta)
 }
 
 // buildMongoFilter builds an outbound Envoy MongoProxy filter.
@@ -15,12 +15,21 @@
 package v1alpha3
 
 import (
+	"strings"
 	"testing"
 
 	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	accesslogconfig "github.com/envoyproxy/go-control-plane/envoy/config/accesslog/v2"
 	redis_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/redis_proxy/v2"
+	tcp_proxy "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/tcp_proxy/v2"
 	xdsutil "github.com/envoyproxy/go-control-plane/pkg/wellknown"
 	"github.com/golang/protobuf/ptypes"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/protocol"
 )
 
 func TestBuildRedisFilter(t *testing.T) {
@@ -54,3 +63,69 @@ func TestBuildRedisFilter(t *testing.T) {
 		t.Errorf("redis filter type is %T not listener.Filter_Config ", redisFilter.ConfigType)
 	}
 }
+
+func TestBuildGatewayPassthroughNetworkFilters(t *testing.T) {
+	env := buildListenerEnv(nil)
+	env.Mesh.EnableEnvoyAccessLogService = false
+	if err := env.PushContext.InitContext(&env, nil, nil); err != nil {
+		t.Fatalf("error in initializing push context: %s", err)
+	}
+	node := &model.Proxy{
+		Type:         model.Router,
+		Metadata:     &model.NodeMetadata{},
+		IstioVersion: &model.IstioVersion{Major: 1, Minor: 4},
+		SidecarScope: model.DefaultSidecarScopeForNamespace(env.PushContext, "edge"),
+	}
+
+	routes := []*networking.RouteDestination{{
+		Destination: &networking.Destination{Host: "backend.example.org", Port: &networking.PortSelector{Number: 443}},
+	}}
+	port := &model.Port{Name: "tls", Port: 443, Protocol: protocol.TLS}
+	meta := model.ConfigMeta{Name: "passthrough", Namespace: "edge"}
+
+	filters := buildGatewayPassthroughNetworkFilters(&env, node, routes, env.PushContext, port, meta,
+		[]string{"a.example.org", "b.example.org"})
+	if len(filters) != 1 || filters[0].Name != xdsutil.TCPProxy {
+		t.Fatalf("expected a single tcp proxy filter, got %v", filters)
+	}
+	tcpProxy := &tcp_proxy.TcpProxy{}
+	if err := ptypes.UnmarshalAny(filters[0].GetTypedConfig(), tcpProxy); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a.example.org_b.example.org.passthrough.edge"; tcpProxy.StatPrefix != want {
+		t.Errorf("got stat prefix %q, want %q", tcpProxy.StatPrefix, want)
+	}
+	if want := "outbound|443||backend.example.org"; tcpProxy.GetCluster() != want {
+		t.Errorf("got cluster %q, want %q", tcpProxy.GetCluster(), want)
+	}
+	if len(tcpProxy.AccessLog) != 1 {
+		t.Fatalf("expected a file access log, got %v", tcpProxy.AccessLog)
+	}
+	fl := &accesslogconfig.FileAccessLog{}
+	if err := ptypes.UnmarshalAny(tcpProxy.AccessLog[0].GetTypedConfig(), fl); err != nil {
+		t.Fatal(err)
+	}
+	if format := fl.GetFormat(); strings.Contains(format, routeNameLogOperator) ||
+		!strings.HasSuffix(format, "%REQUESTED_SERVER_NAME% passthrough.edge\n") {
+		t.Errorf("expected the route to be logged, got %q", format)
+	}
+}
+
+func TestSetAccessLogRouteName(t *testing.T) {
+	env := buildListenerEnv(nil)
+	env.Mesh.AccessLogEncoding = meshconfig.MeshConfig_JSON
+
+	fl := &accesslogconfig.FileAccessLog{}
+	buildAccessLog(&model.Proxy{IstioVersion: &model.IstioVersion{Major: 1, Minor: 4}}, fl, &env)
+	setAccessLogRouteName(fl, "passthrough.edge")
+
+	if got := fl.GetJsonFormat().Fields["route_name"].GetStringValue(); got != "passthrough.edge" {
+		t.Errorf("got route name %q, want passthrough.edge", got)
+	}
+	if got := fl.GetJsonFormat().Fields["requested_server_name"].GetStringValue(); got != "%REQUESTED_SERVER_NAME%" {
+		t.Errorf("got requested server name %q", got)
+	}
+	if got := EnvoyJSONLogFormat13.Fields["route_name"].GetStringValue(); got != routeNameLogOperator {
+		t.Errorf("expected the default json format to be left unchanged, got %q", got)
+	}
+}
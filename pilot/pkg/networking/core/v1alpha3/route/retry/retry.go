@@ -23,6 +23,7 @@ import (
 	"github.com/golang/protobuf/ptypes/wrappers"
 
 	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
@@ -86,6 +87,17 @@ func ConvertPolicy(in *networking.HTTPRetry) *route.RetryPolicy {
 	return out
 }
 
+// ForProxy drops from the policy the fields that the proxy does not support, so that the
+// proxies older than the default policy do not reject the routes using it.
+func ForProxy(policy *route.RetryPolicy, node *model.Proxy) *route.RetryPolicy {
+	if policy == nil || util.IsRetryHostPredicateSupported(node) {
+		return policy
+	}
+	policy.RetryHostPredicate = nil
+	policy.HostSelectionRetryMaxAttempts = 0
+	return policy
+}
+
 func parseRetryOn(retryOn string) (string, []uint32) {
 	codes := make([]uint32, 0)
 	tojoin := make([]string, 0)
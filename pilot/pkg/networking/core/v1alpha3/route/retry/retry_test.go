@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/route/retry"
 )
 
@@ -75,6 +76,28 @@ func TestRetryWithAllFieldsSet(t *testing.T) {
 	g.Expect(policy.RetryHostPredicate).To(Equal(retry.DefaultPolicy().RetryHostPredicate))
 }
 
+func TestRetryForProxyWithoutHostPredicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	// Envoy 1.8 does not support the retry host predicates.
+	node := &model.Proxy{EnvoyVersion: &model.IstioVersion{Major: 1, Minor: 8, Patch: 0}}
+
+	policy := retry.ForProxy(retry.DefaultPolicy(), node)
+	g.Expect(policy).To(Not(BeNil()))
+	g.Expect(policy.RetryOn).To(Equal(retry.DefaultPolicy().RetryOn))
+	g.Expect(policy.RetryHostPredicate).To(BeNil())
+	g.Expect(policy.HostSelectionRetryMaxAttempts).To(Equal(int64(0)))
+}
+
+func TestRetryForProxyWithHostPredicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	node := &model.Proxy{EnvoyVersion: &model.IstioVersion{Major: 1, Minor: 12, Patch: 2}}
+
+	policy := retry.ForProxy(retry.DefaultPolicy(), node)
+	g.Expect(*policy).To(Equal(*retry.DefaultPolicy()))
+}
+
 func TestRetryOnWithEmptyParts(t *testing.T) {
 	g := NewGomegaWithT(t)
 
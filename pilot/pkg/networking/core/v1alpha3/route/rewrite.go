@@ -0,0 +1,54 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route
+
+import (
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/golang/protobuf/proto"
+
+	"istio.io/istio/pkg/config/virtualservice"
+)
+
+// routeActionRegexRewriteField is the number of the regex_rewrite field of the route action, which the
+// go-control-plane version in use does not define yet.
+const routeActionRegexRewriteField = 32
+
+// envoyRegexMatchAndSubstitute mirrors the envoy.type.matcher.RegexMatchAndSubstitute message.
+type envoyRegexMatchAndSubstitute struct {
+	Pattern      *matcher.RegexMatcher `protobuf:"bytes,1,opt,name=pattern,proto3"`
+	Substitution string                `protobuf:"bytes,2,opt,name=substitution,proto3"`
+}
+
+func (m *envoyRegexMatchAndSubstitute) Reset()         { *m = envoyRegexMatchAndSubstitute{} }
+func (m *envoyRegexMatchAndSubstitute) String() string { return proto.CompactTextString(m) }
+func (*envoyRegexMatchAndSubstitute) ProtoMessage()    {}
+
+// translateURIRegexRewrite returns the regex_rewrite field of the route action, encoded in the wire format,
+// to be set as unrecognized fields of the route action.
+func translateURIRegexRewrite(rewrite *virtualservice.URIRegexRewrite) ([]byte, error) {
+	b, err := proto.Marshal(&envoyRegexMatchAndSubstitute{
+		Pattern: &matcher.RegexMatcher{
+			EngineType: &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}},
+			Regex:      rewrite.Pattern,
+		},
+		Substitution: rewrite.Substitution,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := proto.EncodeVarint(routeActionRegexRewriteField<<3 | proto.WireBytes)
+	out = append(out, proto.EncodeVarint(uint64(len(b)))...)
+	return append(out, b...), nil
+}
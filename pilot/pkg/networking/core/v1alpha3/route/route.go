@@ -16,6 +16,7 @@ package route
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -94,9 +95,18 @@ func BuildSidecarVirtualHostsFromConfigAndRegistry(
 
 	out := make([]VirtualHostWrapper, 0)
 
+	// The services with wildcard hostnames are the only ones matching a virtual service host
+	// besides the service with the same hostname.
+	wildcardServices := make([]*model.Service, 0)
+	for svcHost, svc := range serviceRegistry {
+		if strings.HasPrefix(string(svcHost), "*") {
+			wildcardServices = append(wildcardServices, svc)
+		}
+	}
+
 	// translate all virtual service configs into virtual hosts
 	for _, virtualService := range virtualServices {
-		wrappers := buildSidecarVirtualHostsForVirtualService(node, push, virtualService, serviceRegistry, listenPort)
+		wrappers := buildSidecarVirtualHostsForVirtualService(node, push, virtualService, serviceRegistry, wildcardServices, listenPort)
 		if len(wrappers) == 0 {
 			// If none of the routes matched by source (i.e. proxyLabels), then discard this entire virtual service
 			continue
@@ -141,9 +151,9 @@ func BuildSidecarVirtualHostsFromConfigAndRegistry(
 }
 
 // separateVSHostsAndServices splits the virtual service hosts into services (if they are found in the registry) and
-// plain non-registry hostnames
+// plain non-registry hostnames. wildcardServices are the services of the registry with a wildcard hostname.
 func separateVSHostsAndServices(virtualService model.Config,
-	serviceRegistry map[host.Name]*model.Service) ([]string, []*model.Service) {
+	serviceRegistry map[host.Name]*model.Service, wildcardServices []*model.Service) ([]string, []*model.Service) {
 	rule := virtualService.Spec.(*networking.VirtualService)
 	hosts := make([]string, 0)
 	servicesInVirtualService := make([]*model.Service, 0)
@@ -151,14 +161,27 @@ func separateVSHostsAndServices(virtualService model.Config,
 		// Say host is *.global
 		vsHostname := host.Name(hostname)
 		foundSvcMatch := false
-		// TODO: Optimize me. This is O(n2) or worse. Need to prune at top level in config
-		// Say we have services *.foo.global, *.bar.global
-		for svcHost, svc := range serviceRegistry {
-			// *.foo.global matches *.global
-			if svcHost.Matches(vsHostname) {
+		if strings.HasPrefix(hostname, "*") {
+			// Say we have services *.foo.global, *.bar.global
+			for svcHost, svc := range serviceRegistry {
+				// *.foo.global matches *.global
+				if svcHost.Matches(vsHostname) {
+					servicesInVirtualService = append(servicesInVirtualService, svc)
+					foundSvcMatch = true
+				}
+			}
+		} else {
+			// Say host is foo.global: look up the service foo.global, and match the services *.global
+			if svc, exists := serviceRegistry[vsHostname]; exists {
 				servicesInVirtualService = append(servicesInVirtualService, svc)
 				foundSvcMatch = true
 			}
+			for _, svc := range wildcardServices {
+				if svc.Hostname.Matches(vsHostname) {
+					servicesInVirtualService = append(servicesInVirtualService, svc)
+					foundSvcMatch = true
+				}
+			}
 		}
 		if !foundSvcMatch {
 			hosts = append(hosts, hostname)
@@ -175,8 +198,9 @@ func buildSidecarVirtualHostsForVirtualService(
 	push *model.PushContext,
 	virtualService model.Config,
 	serviceRegistry map[host.Name]*model.Service,
+	wildcardServices []*model.Service,
 	listenPort int) []VirtualHostWrapper {
-	hosts, servicesInVirtualService := separateVSHostsAndServices(virtualService, serviceRegistry)
+	hosts, servicesInVirtualService := separateVSHostsAndServices(virtualService, serviceRegistry, wildcardServices)
 
 	// Now group these services by port so that we can infer the destination.port if the user
 	// doesn't specify any port for a multiport service. We need to know the destination port in
@@ -328,8 +352,15 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 		return nil
 	}
 
+	// Envoy rejects the whole route configuration of the listener when one of the regexes is invalid
+	if err := validateRegexes(match, node); err != nil {
+		push.AddConfigError(virtualService.ConfigMeta, node, "InvalidRegex",
+			fmt.Sprintf("HTTP route %q dropped: %v", in.Name, err))
+		return nil
+	}
+
 	out := &route.Route{
-		Match:    translateRouteMatch(match),
+		Match:    translateRouteMatch(match, node),
 		Metadata: util.BuildConfigInfoMetadata(virtualService.ConfigMeta),
 	}
 
@@ -369,14 +400,16 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 			action.Redirect.ResponseCode = route.RedirectAction_PERMANENT_REDIRECT
 		default:
 			log.Warnf("Redirect Code %d are not yet supported", in.Redirect.RedirectCode)
-			action = nil
+			push.AddConfigError(virtualService.ConfigMeta, node, "UnsupportedRedirectCode",
+				fmt.Sprintf("HTTP route %q dropped: redirect code %d is not supported", in.Name, in.Redirect.RedirectCode))
+			return nil
 		}
 
 		out.Action = action
 	} else {
 		action := &route.RouteAction{
 			Cors:        translateCORSPolicy(in.CorsPolicy, node),
-			RetryPolicy: retry.ConvertPolicy(in.Retries),
+			RetryPolicy: retry.ForProxy(retry.ConvertPolicy(in.Retries), node),
 		}
 
 		if in.Timeout != nil {
@@ -401,7 +434,27 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 			}
 		}
 
-		requestHeadersToAdd := translateAppendHeaders(in.Headers.GetRequest().GetSet(), false)
+		// the regex rewrite of the route replaces its URI prefix rewrite, for the proxies supporting it
+		if rewrite := push.URIRegexRewrites(virtualService)[in.Name]; rewrite != nil && in.Name != "" &&
+			util.IsRegexRewriteSupported(node) {
+			regexRewrite, err := translateURIRegexRewrite(rewrite)
+			if err != nil {
+				log.Warnf("failed to translate the regex rewrite of HTTP route %q: %v", in.Name, err)
+			} else {
+				action.PrefixRewrite = ""
+				action.XXX_unrecognized = regexRewrite
+			}
+		}
+
+		// the authority set by the headers of the route is rewritten like the authority of the rewrite
+		requestHeadersToSet, authority := dropAuthorityHeader(in.Headers.GetRequest().GetSet())
+		if authority != "" && in.Rewrite.GetAuthority() == "" {
+			action.HostRewriteSpecifier = &route.RouteAction_HostRewrite{
+				HostRewrite: authority,
+			}
+		}
+
+		requestHeadersToAdd := translateAppendHeaders(requestHeadersToSet, false)
 		requestHeadersToAdd = append(requestHeadersToAdd, translateAppendHeaders(in.Headers.GetRequest().GetAdd(), true)...)
 		requestHeadersToAdd = append(requestHeadersToAdd, translateAppendHeaders(in.AppendRequestHeaders, true)...)
 		requestHeadersToAdd = append(requestHeadersToAdd, translateAppendHeaders(in.AppendHeaders, true)...)
@@ -441,6 +494,8 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 
 		// TODO: eliminate this logic and use the total_weight option in envoy route
 		weighted := make([]*route.WeightedCluster_ClusterWeight, 0)
+		// the authority each weighted cluster rewrites the requests to, if any
+		authorities := make([]string, 0)
 		for _, dst := range in.Route {
 			weight := &wrappers.UInt32Value{Value: uint32(dst.Weight)}
 			if dst.Weight == 0 {
@@ -453,7 +508,8 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 				}
 			}
 
-			requestHeadersToAdd := translateAppendHeaders(dst.Headers.GetRequest().GetSet(), false)
+			requestHeadersToSet, authority := dropAuthorityHeader(dst.Headers.GetRequest().GetSet())
+			requestHeadersToAdd := translateAppendHeaders(requestHeadersToSet, false)
 			requestHeadersToAdd = append(requestHeadersToAdd, translateAppendHeaders(dst.Headers.GetRequest().GetAdd(), true)...)
 			requestHeadersToAdd = append(requestHeadersToAdd, translateAppendHeaders(dst.AppendRequestHeaders, true)...)
 			responseHeadersToAdd := translateAppendHeaders(dst.Headers.GetResponse().GetSet(), false)
@@ -479,6 +535,7 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 			}
 
 			weighted = append(weighted, clusterWeight)
+			authorities = append(authorities, authority)
 
 			var configNamespace string
 			if serviceRegistry[hostname] != nil {
@@ -497,7 +554,19 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 			out.RequestHeadersToRemove = append(out.RequestHeadersToRemove, weighted[0].RequestHeadersToRemove...)
 			out.ResponseHeadersToAdd = append(out.ResponseHeadersToAdd, weighted[0].ResponseHeadersToAdd...)
 			out.ResponseHeadersToRemove = append(out.ResponseHeadersToRemove, weighted[0].ResponseHeadersToRemove...)
+			if authorities[0] != "" {
+				action.HostRewriteSpecifier = &route.RouteAction_HostRewrite{
+					HostRewrite: authorities[0],
+				}
+			}
 		} else {
+			// the weighted clusters cannot rewrite the host, their authority is set as a header instead
+			for i, authority := range authorities {
+				if authority != "" {
+					weighted[i].RequestHeadersToAdd = append(weighted[i].RequestHeadersToAdd,
+						translateAppendHeaders(map[string]string{authorityHeader: authority}, false)...)
+				}
+			}
 			action.ClusterSpecifier = &route.RouteAction_WeightedClusters{
 				WeightedClusters: &route.WeightedCluster{
 					Clusters: weighted,
@@ -510,10 +579,19 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 		Operation: getRouteOperation(out, virtualService.Name, port),
 	}
 	if fault := in.Fault; fault != nil {
+		translated := translateFault(fault)
+		if fault.Delay != nil && translated.GetDelay() == nil {
+			push.AddConfigError(virtualService.ConfigMeta, node, "UnsupportedFault",
+				fmt.Sprintf("HTTP route %q: delay fault dropped, only fixed delays are supported", in.Name))
+		}
+		if fault.Abort != nil && translated.GetAbort() == nil {
+			push.AddConfigError(virtualService.ConfigMeta, node, "UnsupportedFault",
+				fmt.Sprintf("HTTP route %q: abort fault dropped, only HTTP status aborts are supported", in.Name))
+		}
 		if util.IsXDSMarshalingToAnyEnabled(node) {
-			out.TypedPerFilterConfig[xdsutil.Fault] = util.MessageToAny(translateFault(in.Fault))
+			out.TypedPerFilterConfig[xdsutil.Fault] = util.MessageToAny(translated)
 		} else {
-			out.PerFilterConfig[xdsutil.Fault] = util.MessageToStruct(translateFault(in.Fault))
+			out.PerFilterConfig[xdsutil.Fault] = util.MessageToStruct(translated)
 		}
 	}
 
@@ -543,6 +621,31 @@ func (b SortHeaderValueOption) Swap(i, j int) {
 	b[i], b[j] = b[j], b[i]
 }
 
+// authorityHeader is the pseudo header of the authority of the HTTP requests.
+const authorityHeader = ":authority"
+
+// dropAuthorityHeader returns the headers without the authority header, either :authority or Host,
+// and the authority they set if any. Envoy rewrites the authority with a host rewrite rather than a header.
+func dropAuthorityHeader(headers map[string]string) (map[string]string, string) {
+	authority := ""
+	for key, value := range headers {
+		// :authority takes precedence over Host
+		if strings.EqualFold(key, authorityHeader) || (strings.EqualFold(key, "host") && authority == "") {
+			authority = value
+		}
+	}
+	if authority == "" {
+		return headers, ""
+	}
+	out := make(map[string]string, len(headers))
+	for key, value := range headers {
+		if !strings.EqualFold(key, authorityHeader) && !strings.EqualFold(key, "host") {
+			out[key] = value
+		}
+	}
+	return out, authority
+}
+
 // translateAppendHeaders translates headers
 func translateAppendHeaders(headers map[string]string, appendFlag bool) []*core.HeaderValueOption {
 	headerValueOptionList := make([]*core.HeaderValueOption, 0, len(headers))
@@ -560,14 +663,14 @@ func translateAppendHeaders(headers map[string]string, appendFlag bool) []*core.
 }
 
 // translateRouteMatch translates match condition
-func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
+func translateRouteMatch(in *networking.HTTPMatchRequest, node *model.Proxy) *route.RouteMatch {
 	out := &route.RouteMatch{PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"}}
 	if in == nil {
 		return out
 	}
 
 	for name, stringMatch := range in.Headers {
-		matcher := translateHeaderMatch(name, stringMatch)
+		matcher := translateHeaderMatch(name, stringMatch, node)
 		out.Headers = append(out.Headers, &matcher)
 	}
 
@@ -583,7 +686,7 @@ func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
 		case *networking.StringMatch_Prefix:
 			out.PathSpecifier = &route.RouteMatch_Prefix{Prefix: m.Prefix}
 		case *networking.StringMatch_Regex:
-			if features.EnableUnsafeRegex.Get() {
+			if !useSafeRegex(node) {
 				out.PathSpecifier = &route.RouteMatch_Regex{Regex: m.Regex}
 			} else {
 				out.PathSpecifier = &route.RouteMatch_SafeRegex{
@@ -599,17 +702,17 @@ func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
 	out.CaseSensitive = &wrappers.BoolValue{Value: !in.IgnoreUriCase}
 
 	if in.Method != nil {
-		matcher := translateHeaderMatch(HeaderMethod, in.Method)
+		matcher := translateHeaderMatch(HeaderMethod, in.Method, node)
 		out.Headers = append(out.Headers, &matcher)
 	}
 
 	if in.Authority != nil {
-		matcher := translateHeaderMatch(HeaderAuthority, in.Authority)
+		matcher := translateHeaderMatch(HeaderAuthority, in.Authority, node)
 		out.Headers = append(out.Headers, &matcher)
 	}
 
 	if in.Scheme != nil {
-		matcher := translateHeaderMatch(HeaderScheme, in.Scheme)
+		matcher := translateHeaderMatch(HeaderScheme, in.Scheme, node)
 		out.Headers = append(out.Headers, &matcher)
 	}
 
@@ -621,6 +724,40 @@ func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
 	return out
 }
 
+// useSafeRegex returns whether the regexes sent to the proxy use the safe regex matchers. The unsafe ones
+// are used instead when they are enabled, or when the proxy is too old to support the safe ones.
+func useSafeRegex(node *model.Proxy) bool {
+	return !features.EnableUnsafeRegex.Get() && util.IsSafeRegexSupported(node)
+}
+
+// validateRegexes verifies that the regexes of the match condition compile with RE2, the engine of the safe regexes.
+// The unsafe regexes use the ECMAScript grammar of Envoy, and are not verified.
+func validateRegexes(in *networking.HTTPMatchRequest, node *model.Proxy) error {
+	if in == nil || !useSafeRegex(node) {
+		return nil
+	}
+	names := []string{"uri", HeaderMethod, HeaderAuthority, HeaderScheme}
+	matches := []*networking.StringMatch{in.Uri, in.Method, in.Authority, in.Scheme}
+	headers := make([]string, 0, len(in.Headers))
+	for name := range in.Headers {
+		headers = append(headers, name)
+	}
+	sort.Strings(headers)
+	for _, name := range headers {
+		names = append(names, name)
+		matches = append(matches, in.Headers[name])
+	}
+
+	for i, m := range matches {
+		if regex := m.GetRegex(); regex != "" {
+			if _, err := regexp.Compile(regex); err != nil {
+				return fmt.Errorf("invalid regex %q for %s: %v", regex, names[i], err)
+			}
+		}
+	}
+	return nil
+}
+
 // translateQueryParamMatch translates a StringMatch to a QueryParameterMatcher.
 func translateQueryParamMatch(name string, in *networking.StringMatch) route.QueryParameterMatcher {
 	out := route.QueryParameterMatcher{
@@ -639,7 +776,7 @@ func translateQueryParamMatch(name string, in *networking.StringMatch) route.Que
 }
 
 // translateHeaderMatch translates to HeaderMatcher
-func translateHeaderMatch(name string, in *networking.StringMatch) route.HeaderMatcher {
+func translateHeaderMatch(name string, in *networking.StringMatch, node *model.Proxy) route.HeaderMatcher {
 	out := route.HeaderMatcher{
 		Name: name,
 	}
@@ -652,7 +789,7 @@ func translateHeaderMatch(name string, in *networking.StringMatch) route.HeaderM
 		// Golang has a slightly different regex grammar
 		out.HeaderMatchSpecifier = &route.HeaderMatcher_PrefixMatch{PrefixMatch: m.Prefix}
 	case *networking.StringMatch_Regex:
-		if features.EnableUnsafeRegex.Get() {
+		if !useSafeRegex(node) {
 			out.HeaderMatchSpecifier = &route.HeaderMatcher_RegexMatch{RegexMatch: m.Regex}
 		} else {
 			out.HeaderMatchSpecifier = &route.HeaderMatcher_SafeRegexMatch{
@@ -732,7 +869,7 @@ func BuildDefaultHTTPInboundRoute(node *model.Proxy, clusterName string, operati
 	notimeout := ptypes.DurationProto(0 * time.Second)
 
 	val := &route.Route{
-		Match: translateRouteMatch(nil),
+		Match: translateRouteMatch(nil, node),
 		Decorator: &route.Decorator{
 			Operation: operation,
 		},
@@ -757,7 +894,7 @@ func BuildDefaultHTTPOutboundRoute(node *model.Proxy, clusterName string, operat
 	out := BuildDefaultHTTPInboundRoute(node, clusterName, operation)
 
 	// Add a default retry policy for outbound routes.
-	out.GetRoute().RetryPolicy = retry.DefaultPolicy()
+	out.GetRoute().RetryPolicy = retry.ForProxy(retry.DefaultPolicy(), node)
 	return out
 }
 
@@ -21,6 +21,8 @@ import (
 	"time"
 
 	envoyroute "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/onsi/gomega"
 
@@ -33,6 +35,7 @@ import (
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/config/virtualservice"
 )
 
 func TestBuildHTTPRoutes(t *testing.T) {
@@ -90,6 +93,19 @@ func TestBuildHTTPRoutes(t *testing.T) {
 		g.Expect(routes[0].GetMatch().GetRegex()).To(gomega.Equal("\\/(.?)\\/status"))
 	})
 
+	t.Run("for virtual service with regex matching on URI for proxy without safe regex", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+		oldNode := *node
+		oldNode.EnvoyVersion = &model.IstioVersion{Major: 1, Minor: 11}
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(&oldNode, nil, virtualServiceWithRegexMatchingOnURI, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(1))
+		g.Expect(routes[0].GetMatch().GetSafeRegex()).To(gomega.BeNil())
+		//nolint: staticcheck
+		g.Expect(routes[0].GetMatch().GetRegex()).To(gomega.Equal("\\/(.?)\\/status"))
+	})
+
 	t.Run("for virtual service with regex matching on header", func(t *testing.T) {
 		g := gomega.NewGomegaWithT(t)
 
@@ -99,6 +115,68 @@ func TestBuildHTTPRoutes(t *testing.T) {
 		g.Expect(routes[0].GetMatch().GetHeaders()[0].GetSafeRegexMatch().GetRegex()).To(gomega.Equal("Bearer .+?\\..+?\\..+?"))
 	})
 
+	t.Run("for virtual service with invalid regex matching on URI", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+		push := model.NewPushContext()
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, push, virtualServiceWithInvalidRegex, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(1))
+		g.Expect(routes[0].GetMatch().GetPrefix()).To(gomega.Equal("/"))
+
+		configErrors := push.ConfigErrors()
+		g.Expect(len(configErrors)).To(gomega.Equal(1))
+		g.Expect(configErrors[0].Name).To(gomega.Equal("acme"))
+		g.Expect(configErrors[0].Reason).To(gomega.Equal("InvalidRegex"))
+	})
+
+	t.Run("for virtual service with regex rewrite", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, virtualServiceWithRegexRewrite, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(2))
+		g.Expect(routes[0].GetRoute().GetPrefixRewrite()).To(gomega.BeEmpty())
+		pattern, substitution := regexRewrite(t, routes[0].GetRoute())
+		g.Expect(pattern.GetRegex()).To(gomega.Equal("^/v1/(.*)$"))
+		g.Expect(pattern.GetGoogleRe2()).NotTo(gomega.BeNil())
+		g.Expect(substitution).To(gomega.Equal("/api/\\1"))
+		g.Expect(routes[1].GetRoute().GetPrefixRewrite()).To(gomega.Equal("/api"))
+		g.Expect(routes[1].GetRoute().XXX_unrecognized).To(gomega.BeEmpty())
+	})
+
+	t.Run("for virtual service with regex rewrite and proxy not supporting it", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+		oldNode := *node
+		oldNode.EnvoyVersion = &model.IstioVersion{Major: 1, Minor: 13}
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(&oldNode, nil, virtualServiceWithRegexRewrite, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(2))
+		g.Expect(routes[0].GetRoute().GetPrefixRewrite()).To(gomega.Equal("/"))
+		g.Expect(routes[0].GetRoute().XXX_unrecognized).To(gomega.BeEmpty())
+	})
+
+	t.Run("for virtual service with invalid regex rewrite", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+		meshConfig := mesh.DefaultMeshConfig()
+		push := model.NewPushContext()
+		push.Env = &model.Environment{Mesh: &meshConfig}
+		vs := virtualServiceWithRegexRewrite
+		vs.Annotations = map[string]string{virtualservice.URIRegexRewriteAnnotation: `{"v1": {"pattern": "^/v1/(.*$"}}`}
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, push, vs, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(2))
+		g.Expect(routes[0].GetRoute().GetPrefixRewrite()).To(gomega.Equal("/"))
+		g.Expect(routes[0].GetRoute().XXX_unrecognized).To(gomega.BeEmpty())
+
+		configErrors := push.ConfigErrors()
+		g.Expect(len(configErrors)).To(gomega.Equal(1))
+		g.Expect(configErrors[0].Name).To(gomega.Equal("acme"))
+		g.Expect(configErrors[0].Reason).To(gomega.Equal("InvalidRegexRewrite"))
+	})
+
 	t.Run("for virtual service with unsafe regex matching on header", func(t *testing.T) {
 		os.Setenv(features.EnableUnsafeRegex.Name, "true")
 		defer os.Unsetenv(features.EnableUnsafeRegex.Name)
@@ -111,6 +189,33 @@ func TestBuildHTTPRoutes(t *testing.T) {
 		g.Expect(routes[0].GetMatch().GetHeaders()[0].GetRegexMatch()).To(gomega.Equal("Bearer .+?\\..+?\\..+?"))
 	})
 
+	t.Run("for virtual service with authority set on the destination", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, virtualServiceWithDestinationAuthority, serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(1))
+		g.Expect(routes[0].GetRoute().GetHostRewrite()).To(gomega.Equal("v1.example.org"))
+		g.Expect(routes[0].GetRequestHeadersToAdd()).To(gomega.HaveLen(1))
+		g.Expect(routes[0].GetRequestHeadersToAdd()[0].GetHeader().GetKey()).To(gomega.Equal("x-version"))
+	})
+
+	t.Run("for virtual service with authorities set on weighted destinations", func(t *testing.T) {
+		g := gomega.NewGomegaWithT(t)
+
+		routes, err := route.BuildHTTPRoutesForVirtualService(node, nil, virtualServiceWithWeightedDestinationAuthorities,
+			serviceRegistry, 8080, gatewayNames)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(len(routes)).To(gomega.Equal(1))
+		g.Expect(routes[0].GetRoute().GetHostRewrite()).To(gomega.Equal("route.example.org"))
+		clusters := routes[0].GetRoute().GetWeightedClusters().GetClusters()
+		g.Expect(clusters).To(gomega.HaveLen(2))
+		g.Expect(clusters[0].GetRequestHeadersToAdd()).To(gomega.HaveLen(1))
+		g.Expect(clusters[0].GetRequestHeadersToAdd()[0].GetHeader().GetKey()).To(gomega.Equal(":authority"))
+		g.Expect(clusters[0].GetRequestHeadersToAdd()[0].GetHeader().GetValue()).To(gomega.Equal("v1.example.org"))
+		g.Expect(clusters[1].GetRequestHeadersToAdd()).To(gomega.BeEmpty())
+	})
+
 	t.Run("for virtual service with ring hash", func(t *testing.T) {
 		g := gomega.NewGomegaWithT(t)
 
@@ -481,6 +586,166 @@ var virtualServicePlain = model.Config{
 	},
 }
 
+var virtualServiceWithDestinationAuthority = model.Config{
+	ConfigMeta: model.ConfigMeta{
+		Type:    schemas.VirtualService.Type,
+		Version: schemas.VirtualService.Version,
+		Name:    "acme",
+	},
+	Spec: &networking.VirtualService{
+		Hosts:    []string{},
+		Gateways: []string{"some-gateway"},
+		Http: []*networking.HTTPRoute{
+			{
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "*.example.org",
+						},
+						Headers: &networking.Headers{
+							Request: &networking.Headers_HeaderOperations{
+								Set: map[string]string{"Host": "v1.example.org", "x-version": "v1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+var virtualServiceWithWeightedDestinationAuthorities = model.Config{
+	ConfigMeta: model.ConfigMeta{
+		Type:    schemas.VirtualService.Type,
+		Version: schemas.VirtualService.Version,
+		Name:    "acme",
+	},
+	Spec: &networking.VirtualService{
+		Hosts:    []string{},
+		Gateways: []string{"some-gateway"},
+		Http: []*networking.HTTPRoute{
+			{
+				Headers: &networking.Headers{
+					Request: &networking.Headers_HeaderOperations{
+						Set: map[string]string{":authority": "route.example.org"},
+					},
+				},
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host:   "*.example.org",
+							Subset: "v1",
+						},
+						Headers: &networking.Headers{
+							Request: &networking.Headers_HeaderOperations{
+								Set: map[string]string{":authority": "v1.example.org"},
+							},
+						},
+						Weight: 50,
+					},
+					{
+						Destination: &networking.Destination{
+							Host:   "*.example.org",
+							Subset: "v2",
+						},
+						Weight: 50,
+					},
+				},
+			},
+		},
+	},
+}
+
+var virtualServiceWithRegexRewrite = model.Config{
+	ConfigMeta: model.ConfigMeta{
+		Type:        schemas.VirtualService.Type,
+		Version:     schemas.VirtualService.Version,
+		Name:        "acme",
+		Annotations: map[string]string{virtualservice.URIRegexRewriteAnnotation: `{"v1": {"pattern": "^/v1/(.*)$", "substitution": "/api/\\1"}}`},
+	},
+	Spec: &networking.VirtualService{
+		Hosts:    []string{},
+		Gateways: []string{"some-gateway"},
+		Http: []*networking.HTTPRoute{
+			{
+				Name: "v1",
+				Match: []*networking.HTTPMatchRequest{
+					{
+						Uri: &networking.StringMatch{
+							MatchType: &networking.StringMatch_Prefix{Prefix: "/v1/"},
+						},
+					},
+				},
+				Rewrite: &networking.HTTPRewrite{Uri: "/"},
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "*.example.org",
+						},
+					},
+				},
+			},
+			{
+				Name: "v2",
+				Match: []*networking.HTTPMatchRequest{
+					{
+						Uri: &networking.StringMatch{
+							MatchType: &networking.StringMatch_Prefix{Prefix: "/v2"},
+						},
+					},
+				},
+				Rewrite: &networking.HTTPRewrite{Uri: "/api"},
+				Route: []*networking.HTTPRouteDestination{
+					{
+						Destination: &networking.Destination{
+							Host: "*.example.org",
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// regexRewrite decodes the regex_rewrite field of a route action.
+func regexRewrite(t *testing.T, action *envoyroute.RouteAction) (*matcher.RegexMatcher, string) {
+	t.Helper()
+	buf := proto.NewBuffer(action.XXX_unrecognized)
+	if key, err := buf.DecodeVarint(); err != nil || key != 32<<3|proto.WireBytes {
+		t.Fatalf("expected a regex rewrite, got %x", action.XXX_unrecognized)
+	}
+	field, err := buf.DecodeRawBytes(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := &matcher.RegexMatcher{}
+	substitution := ""
+	buf = proto.NewBuffer(field)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil || key == 0 {
+			break
+		}
+		switch key >> 3 {
+		case 1:
+			b, err := buf.DecodeRawBytes(false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := proto.Unmarshal(b, pattern); err != nil {
+				t.Fatal(err)
+			}
+		case 2:
+			if substitution, err = buf.DecodeStringBytes(); err != nil {
+				t.Fatal(err)
+			}
+		default:
+			t.Fatalf("unexpected field %d in %x", key>>3, field)
+		}
+	}
+	return pattern, substitution
+}
+
 var virtualServiceWithRedirect = model.Config{
 	ConfigMeta: model.ConfigMeta{
 		Type:    schemas.VirtualService.Type,
@@ -533,6 +798,44 @@ var virtualServiceWithRegexMatchingOnURI = model.Config{
 	},
 }
 
+var virtualServiceWithInvalidRegex = model.Config{
+	ConfigMeta: model.ConfigMeta{
+		Type:    schemas.VirtualService.Type,
+		Version: schemas.VirtualService.Version,
+		Name:    "acme",
+	},
+	Spec: &networking.VirtualService{
+		Hosts:    []string{},
+		Gateways: []string{"some-gateway"},
+		Http: []*networking.HTTPRoute{
+			{
+				Name: "invalid",
+				Match: []*networking.HTTPMatchRequest{
+					{
+						Uri: &networking.StringMatch{
+							MatchType: &networking.StringMatch_Regex{
+								Regex: "/status(",
+							},
+						},
+					},
+				},
+				Redirect: &networking.HTTPRedirect{
+					Uri:          "example.org",
+					Authority:    "some-authority.default.svc.cluster.local",
+					RedirectCode: 308,
+				},
+			},
+			{
+				Redirect: &networking.HTTPRedirect{
+					Uri:          "example.org",
+					Authority:    "some-authority.default.svc.cluster.local",
+					RedirectCode: 308,
+				},
+			},
+		},
+	},
+}
+
 var virtualServiceWithRegexMatchingOnHeader = model.Config{
 	ConfigMeta: model.ConfigMeta{
 		Type:    schemas.VirtualService.Type,
@@ -0,0 +1,96 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"github.com/golang/protobuf/proto"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	telemetryapi "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+	"istio.io/pkg/log"
+)
+
+// tracingCustomTagsField is the number of the custom_tags field of the tracing configuration of the
+// HTTP connection manager, which the go-control-plane version in use does not define yet.
+const tracingCustomTagsField = 8
+
+// envoyCustomTag mirrors the envoy.type.tracing.v2.CustomTag message. Only the literal and environment
+// tag types are defined, the request header tags are set with request_headers_for_tags.
+type envoyCustomTag struct {
+	Tag         string                     `protobuf:"bytes,1,opt,name=tag,proto3"`
+	Literal     *envoyCustomTagLiteral     `protobuf:"bytes,2,opt,name=literal,proto3"`
+	Environment *envoyCustomTagEnvironment `protobuf:"bytes,3,opt,name=environment,proto3"`
+}
+
+func (m *envoyCustomTag) Reset()         { *m = envoyCustomTag{} }
+func (m *envoyCustomTag) String() string { return proto.CompactTextString(m) }
+func (*envoyCustomTag) ProtoMessage()    {}
+
+// envoyCustomTagLiteral mirrors the envoy.type.tracing.v2.CustomTag.Literal message.
+type envoyCustomTagLiteral struct {
+	Value string `protobuf:"bytes,1,opt,name=value,proto3"`
+}
+
+func (m *envoyCustomTagLiteral) Reset()         { *m = envoyCustomTagLiteral{} }
+func (m *envoyCustomTagLiteral) String() string { return proto.CompactTextString(m) }
+func (*envoyCustomTagLiteral) ProtoMessage()    {}
+
+// envoyCustomTagEnvironment mirrors the envoy.type.tracing.v2.CustomTag.Environment message.
+type envoyCustomTagEnvironment struct {
+	Name         string `protobuf:"bytes,1,opt,name=name,proto3"`
+	DefaultValue string `protobuf:"bytes,2,opt,name=default_value,json=defaultValue,proto3"`
+}
+
+func (m *envoyCustomTagEnvironment) Reset()         { *m = envoyCustomTagEnvironment{} }
+func (m *envoyCustomTagEnvironment) String() string { return proto.CompactTextString(m) }
+func (*envoyCustomTagEnvironment) ProtoMessage()    {}
+
+// buildTracingCustomTags returns the custom_tags field of the tracing configuration of the HTTP
+// connection manager, encoded in the wire format, to be set as unrecognized fields of the tracing
+// configuration. The tags are dropped when the connection manager is not marshaled to Any, as its
+// conversion to Struct drops the unrecognized fields, and for the proxies not supporting them.
+func buildTracingCustomTags(node *model.Proxy, tags []*telemetryapi.CustomTag) []byte {
+	if len(tags) == 0 {
+		return nil
+	}
+	if !util.IsXDSMarshalingToAnyEnabled(node) {
+		log.Warnf("dropping the custom span tags of proxy %s: they require the xDS marshaling to Any", node.ID)
+		return nil
+	}
+	if !util.IsTracingCustomTagsSupported(node) {
+		log.Warnf("dropping the custom span tags of proxy %s: not supported by Envoy %v", node.ID, node.EnvoyVersion)
+		return nil
+	}
+
+	var out []byte
+	for _, t := range tags {
+		tag := &envoyCustomTag{Tag: t.Tag}
+		if t.Environment != "" {
+			tag.Environment = &envoyCustomTagEnvironment{Name: t.Environment, DefaultValue: t.DefaultValue}
+		} else {
+			tag.Literal = &envoyCustomTagLiteral{Value: t.Literal}
+		}
+		b, err := proto.Marshal(tag)
+		if err != nil {
+			log.Warnf("failed to marshal span tag %s: %v", t.Tag, err)
+			continue
+		}
+		out = append(out, proto.EncodeVarint(tracingCustomTagsField<<3|proto.WireBytes)...)
+		out = append(out, proto.EncodeVarint(uint64(len(b)))...)
+		out = append(out, b...)
+	}
+	return out
+}
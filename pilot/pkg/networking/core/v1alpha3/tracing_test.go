@@ -0,0 +1,98 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	telemetryapi "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
+)
+
+func TestBuildTracingCustomTags(t *testing.T) {
+	tags := []*telemetryapi.CustomTag{
+		{Tag: "cluster", Literal: "east"},
+		{Tag: "pod", Environment: "POD_NAME", DefaultValue: "unknown"},
+	}
+
+	cases := []struct {
+		name         string
+		envoyVersion *model.IstioVersion
+		disableAny   bool
+		want         []*envoyCustomTag
+	}{
+		{
+			name: "supported",
+			want: []*envoyCustomTag{
+				{Tag: "cluster", Literal: &envoyCustomTagLiteral{Value: "east"}},
+				{Tag: "pod", Environment: &envoyCustomTagEnvironment{Name: "POD_NAME", DefaultValue: "unknown"}},
+			},
+		},
+		{
+			name:         "old proxy",
+			envoyVersion: &model.IstioVersion{Major: 1, Minor: 12},
+		},
+		{
+			name:       "marshaling to struct",
+			disableAny: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			defer func(disabled bool) { features.DisableXDSMarshalingToAny = disabled }(features.DisableXDSMarshalingToAny)
+			features.DisableXDSMarshalingToAny = c.disableAny
+
+			got := decodeTracingCustomTags(t, buildTracingCustomTags(&model.Proxy{ID: "proxy", EnvoyVersion: c.envoyVersion}, tags))
+			if len(got) != len(c.want) {
+				t.Fatalf("got %d tags, want %d", len(got), len(c.want))
+			}
+			for i := range got {
+				if !proto.Equal(got[i], c.want[i]) {
+					t.Errorf("got tag %v, want %v", got[i], c.want[i])
+				}
+			}
+		})
+	}
+}
+
+// decodeTracingCustomTags decodes the custom_tags fields encoded in the wire format.
+func decodeTracingCustomTags(t *testing.T, b []byte) []*envoyCustomTag {
+	t.Helper()
+	var out []*envoyCustomTag
+	buf := proto.NewBuffer(b)
+	for {
+		key, err := buf.DecodeVarint()
+		if err != nil {
+			break
+		}
+		if key != tracingCustomTagsField<<3|proto.WireBytes {
+			t.Fatalf("unexpected field key %d", key)
+		}
+		field, err := buf.DecodeRawBytes(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag := &envoyCustomTag{}
+		if err := proto.Unmarshal(field, tag); err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, tag)
+	}
+	return out
+}
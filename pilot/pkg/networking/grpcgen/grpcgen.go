@@ -0,0 +1,171 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcgen generates the xDS configuration of the gRPC applications using the built-in xDS
+// client of gRPC instead of a sidecar. gRPC requests a listener for each target it dials, named
+// "hostname:port", and only follows API listeners to the RDS, CDS and EDS resources it names.
+package grpcgen
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v2"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+	networking "istio.io/istio/pilot/pkg/networking/core"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/host"
+)
+
+// BuildListeners returns the API listeners of the targets visible to the client. Each one routes
+// the calls of the target with the route configuration of the same name.
+func BuildListeners(node *model.Proxy, names []string) []*xdsapi.Listener {
+	out := make([]*xdsapi.Listener, 0, len(names))
+	for _, name := range names {
+		hostname, port, err := parseTarget(name)
+		if err != nil {
+			log.Debugf("grpcgen: ignoring listener %s of %s: %v", name, node.ID, err)
+			continue
+		}
+		if findService(node, hostname, port) == nil {
+			log.Debugf("grpcgen: ignoring listener %s of %s: unknown service", name, node.ID)
+			continue
+		}
+		hcm := &http_conn.HttpConnectionManager{
+			RouteSpecifier: &http_conn.HttpConnectionManager_Rds{
+				Rds: &http_conn.Rds{
+					ConfigSource: &core.ConfigSource{
+						ConfigSourceSpecifier: &core.ConfigSource_Ads{Ads: &core.AggregatedConfigSource{}},
+					},
+					RouteConfigName: name,
+				},
+			},
+		}
+		out = append(out, &xdsapi.Listener{
+			Name:        name,
+			ApiListener: &listener.ApiListener{ApiListener: util.MessageToAny(hcm)},
+		})
+	}
+	return out
+}
+
+// BuildHTTPRoutes returns the route configurations of the targets, made of the virtual host of the
+// target in the route configuration a sidecar of the client would use for the port.
+func BuildHTTPRoutes(generator networking.ConfigGenerator, env *model.Environment, node *model.Proxy,
+	push *model.PushContext, names []string) []*xdsapi.RouteConfiguration {
+	byPort := make(map[int]*xdsapi.RouteConfiguration)
+	out := make([]*xdsapi.RouteConfiguration, 0, len(names))
+	for _, name := range names {
+		hostname, port, err := parseTarget(name)
+		if err != nil {
+			log.Debugf("grpcgen: ignoring route %s of %s: %v", name, node.ID, err)
+			continue
+		}
+		rc, f := byPort[port]
+		if !f {
+			if routes := generator.BuildHTTPRoutes(env, node, push, []string{strconv.Itoa(port)}); len(routes) > 0 {
+				rc = routes[0]
+			}
+			byPort[port] = rc
+		}
+		if rc == nil {
+			continue
+		}
+		for _, vh := range rc.VirtualHosts {
+			if hasDomain(vh, string(hostname)) {
+				out = append(out, &xdsapi.RouteConfiguration{
+					Name:         name,
+					VirtualHosts: []*route.VirtualHost{vh},
+				})
+				break
+			}
+		}
+	}
+	return out
+}
+
+// BuildClusters returns the clusters of the given names a sidecar of the client would use, when gRPC
+// supports them: it only resolves the endpoints of EDS clusters, and balances them round robin. Their
+// TLS settings are kept for mTLS, as the secrets they refer to, "default" and "ROOTCA", are also the
+// names of the certificate providers of the gRPC bootstrap generated by the injector.
+func BuildClusters(generator networking.ConfigGenerator, env *model.Environment, node *model.Proxy,
+	push *model.PushContext, names []string) []*xdsapi.Cluster {
+	if len(names) == 0 {
+		return nil
+	}
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		requested[name] = true
+	}
+
+	out := make([]*xdsapi.Cluster, 0, len(names))
+	for _, c := range generator.BuildClusters(env, node, push) {
+		if !requested[c.Name] {
+			continue
+		}
+		if c.GetType() != xdsapi.Cluster_EDS {
+			log.Debugf("grpcgen: ignoring cluster %s of %s: unsupported type %v", c.Name, node.ID, c.GetType())
+			continue
+		}
+		c.LbPolicy = xdsapi.Cluster_ROUND_ROBIN
+		out = append(out, c)
+	}
+	return out
+}
+
+// parseTarget returns the hostname and port of a "hostname:port" target.
+func parseTarget(name string) (host.Name, int, error) {
+	hostname, p, err := net.SplitHostPort(name)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", p)
+	}
+	return host.Name(hostname), port, nil
+}
+
+// findService returns the service of the hostname visible to the client, if it has the port.
+func findService(node *model.Proxy, hostname host.Name, port int) *model.Service {
+	if node.SidecarScope == nil {
+		return nil
+	}
+	for _, svc := range node.SidecarScope.Services() {
+		if svc.Hostname != hostname {
+			continue
+		}
+		if _, f := svc.Ports.GetByPort(port); f {
+			return svc
+		}
+	}
+	return nil
+}
+
+func hasDomain(vh *route.VirtualHost, domain string) bool {
+	for _, d := range vh.Domains {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
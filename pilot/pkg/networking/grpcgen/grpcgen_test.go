@@ -0,0 +1,147 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcgen
+
+import (
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/golang/protobuf/ptypes"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/protocol"
+)
+
+func buildTestEnv(t *testing.T) (*model.Environment, *model.Proxy) {
+	t.Helper()
+	services := []*model.Service{
+		{
+			Hostname:     host.Name("echo.default.svc.cluster.local"),
+			Address:      "10.0.0.1",
+			CreationTime: time.Now(),
+			Ports:        model.PortList{{Name: "grpc", Port: 7070, Protocol: protocol.GRPC}},
+			Attributes:   model.ServiceAttributes{Namespace: "default"},
+		},
+		{
+			Hostname:     host.Name("tcp.default.svc.cluster.local"),
+			Address:      "10.0.0.2",
+			CreationTime: time.Now(),
+			Ports:        model.PortList{{Name: "tcp", Port: 9090, Protocol: protocol.TCP}},
+			Attributes:   model.ServiceAttributes{Namespace: "default"},
+		},
+	}
+	serviceDiscovery := new(fakes.ServiceDiscovery)
+	serviceDiscovery.ServicesReturns(services, nil)
+
+	m := mesh.DefaultMeshConfig()
+	env := &model.Environment{
+		PushContext:      model.NewPushContext(),
+		ServiceDiscovery: serviceDiscovery,
+		IstioConfigStore: &fakes.IstioConfigStore{},
+		Mesh:             &m,
+	}
+	if err := env.PushContext.InitContext(env, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &model.Proxy{
+		Type:            model.SidecarProxy,
+		IPAddresses:     []string{"10.1.0.1"},
+		ID:              "grpc-app.default",
+		DNSDomain:       "default.svc.cluster.local",
+		ConfigNamespace: "default",
+		Metadata:        &model.NodeMetadata{Generator: model.GRPCGenerator},
+	}
+	node.SetSidecarScope(env.PushContext)
+	return env, node
+}
+
+func TestBuildListeners(t *testing.T) {
+	_, node := buildTestEnv(t)
+
+	listeners := BuildListeners(node, []string{
+		"echo.default.svc.cluster.local:7070",
+		"echo.default.svc.cluster.local:8080",
+		"unknown.default.svc.cluster.local:7070",
+		"echo.default.svc.cluster.local",
+	})
+	if len(listeners) != 1 {
+		t.Fatalf("expected only the listener of the known target, got %v", listeners)
+	}
+	l := listeners[0]
+	if l.Name != "echo.default.svc.cluster.local:7070" || l.ApiListener == nil {
+		t.Fatalf("expected an API listener of the target, got %v", l)
+	}
+	hcm := &http_conn.HttpConnectionManager{}
+	if err := ptypes.UnmarshalAny(l.ApiListener.ApiListener, hcm); err != nil {
+		t.Fatal(err)
+	}
+	if got := hcm.GetRds().GetRouteConfigName(); got != l.Name {
+		t.Errorf("expected the listener to route with %q, got %q", l.Name, got)
+	}
+}
+
+func TestBuildHTTPRoutes(t *testing.T) {
+	env, node := buildTestEnv(t)
+	generator := v1alpha3.NewConfigGenerator(nil)
+
+	routes := BuildHTTPRoutes(generator, env, node, env.PushContext, []string{
+		"echo.default.svc.cluster.local:7070",
+		"unknown.default.svc.cluster.local:7070",
+	})
+	if len(routes) != 1 {
+		t.Fatalf("expected only the route of the known target, got %v", routes)
+	}
+	rc := routes[0]
+	if rc.Name != "echo.default.svc.cluster.local:7070" || len(rc.VirtualHosts) != 1 {
+		t.Fatalf("expected a single virtual host for the target, got %v", rc)
+	}
+	if !hasDomain(rc.VirtualHosts[0], "echo.default.svc.cluster.local") {
+		t.Errorf("expected the virtual host of the target, got %v", rc.VirtualHosts[0].Domains)
+	}
+	if got := rc.VirtualHosts[0].Routes[0].GetRoute().GetCluster(); got != "outbound|7070||echo.default.svc.cluster.local" {
+		t.Errorf("expected the route to the cluster of the target, got %q", got)
+	}
+}
+
+func TestBuildClusters(t *testing.T) {
+	env, node := buildTestEnv(t)
+	generator := v1alpha3.NewConfigGenerator(nil)
+
+	clusters := BuildClusters(generator, env, node, env.PushContext, []string{
+		"outbound|7070||echo.default.svc.cluster.local",
+		"PassthroughCluster",
+	})
+	if len(clusters) != 1 {
+		t.Fatalf("expected only the EDS cluster, got %v", clusters)
+	}
+	c := clusters[0]
+	if c.Name != "outbound|7070||echo.default.svc.cluster.local" {
+		t.Errorf("unexpected cluster %q", c.Name)
+	}
+	if c.LbPolicy != xdsapi.Cluster_ROUND_ROBIN {
+		t.Errorf("expected round robin load balancing, got %v", c.LbPolicy)
+	}
+
+	if clusters := BuildClusters(generator, env, node, env.PushContext, nil); len(clusters) != 0 {
+		t.Errorf("expected no cluster without names, got %v", clusters)
+	}
+}
@@ -31,16 +31,19 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/duration"
 	pstruct "github.com/golang/protobuf/ptypes/struct"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/pkg/log"
 
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	mccpb "istio.io/istio/pilot/pkg/networking/plugin/mixer/client"
 	mpb "istio.io/istio/pilot/pkg/networking/plugin/mixer/client"
 	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 )
 
@@ -112,8 +115,18 @@ func createOutboundListenerAttributes(in *plugin.InputParams) attributes {
 	return attrs
 }
 
-func skipMixerHTTPFilter(dir direction, mesh *meshconfig.MeshConfig, node *model.Proxy) bool {
-	return disablePolicyChecks(dir, mesh, node) && mesh.GetDisableMixerHttpReports()
+func skipMixerHTTPFilter(dir direction, in *plugin.InputParams) bool {
+	return disablePolicyChecks(dir, in.Env.Mesh, in.Node) && disableHTTPReports(in)
+}
+
+// disableHTTPReports returns true if the proxy should not send HTTP reports, either because they are
+// disabled mesh wide or because a Telemetry resource turns off mixer metrics for the workload.
+func disableHTTPReports(in *plugin.InputParams) bool {
+	var telemetry *model.ProxyTelemetry
+	if in.Push != nil && in.Push.Env != nil {
+		telemetry = in.Push.Telemetry(in.Node)
+	}
+	return !telemetry.MetricsEnabled(constants.TelemetryProviderMixer, !in.Env.Mesh.GetDisableMixerHttpReports())
 }
 
 // OnOutboundListener implements the Callbacks interface method.
@@ -124,14 +137,14 @@ func (mixerplugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.Mu
 
 	attrs := createOutboundListenerAttributes(in)
 
-	skipHTTPFilter := skipMixerHTTPFilter(outbound, in.Env.Mesh, in.Node)
+	skipHTTPFilter := skipMixerHTTPFilter(outbound, in)
 
 	switch in.ListenerProtocol {
 	case plugin.ListenerProtocolHTTP:
 		if skipHTTPFilter {
 			return nil
 		}
-		httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node)
+		httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node, disableHTTPReports(in))
 		for cnum := range mutable.FilterChains {
 			mutable.FilterChains[cnum].HTTP = append(mutable.FilterChains[cnum].HTTP, httpFilter)
 		}
@@ -142,7 +155,7 @@ func (mixerplugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.Mu
 			// For gateways, due to TLS termination, a listener marked as TCP could very well
 			// be using a HTTP connection manager. So check the filterChain.listenerProtocol
 			// to decide the type of filter to attach
-			httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node)
+			httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node, disableHTTPReports(in))
 			for cnum := range mutable.FilterChains {
 				if mutable.FilterChains[cnum].ListenerProtocol == plugin.ListenerProtocolHTTP && !skipHTTPFilter {
 					mutable.FilterChains[cnum].HTTP = append(mutable.FilterChains[cnum].HTTP, httpFilter)
@@ -168,7 +181,7 @@ func (mixerplugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.Mu
 		return nil
 	case plugin.ListenerProtocolAuto:
 		tcpFilter := buildOutboundTCPFilter(in.Env.Mesh, attrs, in.Node, in.Service)
-		httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node)
+		httpFilter := buildOutboundHTTPFilter(in.Env.Mesh, attrs, in.Node, disableHTTPReports(in))
 		for cnum := range mutable.FilterChains {
 			switch mutable.FilterChains[cnum].ListenerProtocol {
 			case plugin.ListenerProtocolHTTP:
@@ -219,14 +232,14 @@ func (mixerplugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.Mut
 		}
 	}
 
-	skipHTTPFilter := skipMixerHTTPFilter(inbound, in.Env.Mesh, in.Node)
+	skipHTTPFilter := skipMixerHTTPFilter(inbound, in)
 
 	switch in.ListenerProtocol {
 	case plugin.ListenerProtocolHTTP:
 		if skipHTTPFilter {
 			return nil
 		}
-		filter := buildInboundHTTPFilter(in.Env.Mesh, attrs, in.Node)
+		filter := buildInboundHTTPFilter(in.Env.Mesh, attrs, in.Node, disableHTTPReports(in))
 		for cnum := range mutable.FilterChains {
 			mutable.FilterChains[cnum].HTTP = append(mutable.FilterChains[cnum].HTTP, filter)
 		}
@@ -238,7 +251,7 @@ func (mixerplugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.Mut
 		}
 		return nil
 	case plugin.ListenerProtocolAuto:
-		httpFilter := buildInboundHTTPFilter(in.Env.Mesh, attrs, in.Node)
+		httpFilter := buildInboundHTTPFilter(in.Env.Mesh, attrs, in.Node, disableHTTPReports(in))
 		tcpFilter := buildInboundTCPFilter(in.Env.Mesh, attrs, in.Node)
 		for cnum := range mutable.FilterChains {
 			switch mutable.FilterChains[cnum].ListenerProtocol {
@@ -430,13 +443,14 @@ func buildTransport(mesh *meshconfig.MeshConfig, node *model.Proxy) *mccpb.Trans
 	return res
 }
 
-func buildOutboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *model.Proxy) *http_conn.HttpFilter {
+func buildOutboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *model.Proxy,
+	disableReports bool) *http_conn.HttpFilter {
 	cfg := &mccpb.HttpClientConfig{
 		DefaultDestinationService: defaultConfig,
 		ServiceConfigs: map[string]*mccpb.ServiceConfig{
 			defaultConfig: {
 				DisableCheckCalls:  disablePolicyChecks(outbound, mesh, node),
-				DisableReportCalls: mesh.GetDisableMixerHttpReports(),
+				DisableReportCalls: disableReports,
 			},
 		},
 		MixerAttributes: &mpb.Attributes{Attributes: attrs},
@@ -460,13 +474,14 @@ func buildOutboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node
 	return out
 }
 
-func buildInboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *model.Proxy) *http_conn.HttpFilter {
+func buildInboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *model.Proxy,
+	disableReports bool) *http_conn.HttpFilter {
 	cfg := &mccpb.HttpClientConfig{
 		DefaultDestinationService: defaultConfig,
 		ServiceConfigs: map[string]*mccpb.ServiceConfig{
 			defaultConfig: {
 				DisableCheckCalls:  disablePolicyChecks(inbound, mesh, node),
-				DisableReportCalls: mesh.GetDisableMixerHttpReports(),
+				DisableReportCalls: disableReports,
 			},
 		},
 		MixerAttributes:           &mpb.Attributes{Attributes: attrs},
@@ -486,18 +501,19 @@ func buildInboundHTTPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node
 	return out
 }
 
-func addFilterConfigToRoute(in *plugin.InputParams, httpRoute *route.Route, attrs attributes, isXDSMarshalingToAnyEnabled bool) {
+func addFilterConfigToRoute(in *plugin.InputParams, httpRoute *route.Route, attrs attributes, isXDSMarshalingToAnyEnabled bool,
+	disableReports bool) {
 	if isXDSMarshalingToAnyEnabled {
 		httpRoute.TypedPerFilterConfig = addTypedServiceConfig(httpRoute.TypedPerFilterConfig, &mccpb.ServiceConfig{
 			DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-			DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+			DisableReportCalls: disableReports,
 			MixerAttributes:    &mpb.Attributes{Attributes: attrs},
 			ForwardAttributes:  &mpb.Attributes{Attributes: attrs},
 		})
 	} else {
 		httpRoute.PerFilterConfig = addServiceConfig(httpRoute.PerFilterConfig, &mccpb.ServiceConfig{
 			DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-			DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+			DisableReportCalls: disableReports,
 			MixerAttributes:    &mpb.Attributes{Attributes: attrs},
 			ForwardAttributes:  &mpb.Attributes{Attributes: attrs},
 		})
@@ -506,17 +522,18 @@ func addFilterConfigToRoute(in *plugin.InputParams, httpRoute *route.Route, attr
 
 func modifyOutboundRouteConfig(push *model.PushContext, in *plugin.InputParams, virtualHostname string, httpRoute *route.Route) *route.Route {
 	isXDSMarshalingToAnyEnabled := util.IsXDSMarshalingToAnyEnabled(in.Node)
+	disableReports := disableHTTPReports(in)
 
 	// default config, to be overridden by per-weighted cluster
 	if isXDSMarshalingToAnyEnabled {
 		httpRoute.TypedPerFilterConfig = addTypedServiceConfig(httpRoute.TypedPerFilterConfig, &mccpb.ServiceConfig{
 			DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-			DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+			DisableReportCalls: disableReports,
 		})
 	} else {
 		httpRoute.PerFilterConfig = addServiceConfig(httpRoute.PerFilterConfig, &mccpb.ServiceConfig{
 			DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-			DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+			DisableReportCalls: disableReports,
 		})
 	}
 	switch action := httpRoute.Action.(type) {
@@ -531,7 +548,7 @@ func modifyOutboundRouteConfig(push *model.PushContext, in *plugin.InputParams,
 				svc := in.Node.SidecarScope.ServiceForHostname(hostname, push.ServiceByHostnameAndNamespace)
 				attrs = addDestinationServiceAttributes(make(attributes), svc)
 			}
-			addFilterConfigToRoute(in, httpRoute, attrs, isXDSMarshalingToAnyEnabled)
+			addFilterConfigToRoute(in, httpRoute, attrs, isXDSMarshalingToAnyEnabled, disableReports)
 
 		case *route.RouteAction_WeightedClusters:
 			for _, weighted := range upstreams.WeightedClusters.Clusters {
@@ -541,14 +558,14 @@ func modifyOutboundRouteConfig(push *model.PushContext, in *plugin.InputParams,
 				if isXDSMarshalingToAnyEnabled {
 					weighted.TypedPerFilterConfig = addTypedServiceConfig(weighted.TypedPerFilterConfig, &mccpb.ServiceConfig{
 						DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-						DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+						DisableReportCalls: disableReports,
 						MixerAttributes:    &mpb.Attributes{Attributes: attrs},
 						ForwardAttributes:  &mpb.Attributes{Attributes: attrs},
 					})
 				} else {
 					weighted.PerFilterConfig = addServiceConfig(weighted.PerFilterConfig, &mccpb.ServiceConfig{
 						DisableCheckCalls:  disablePolicyChecks(outbound, in.Env.Mesh, in.Node),
-						DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+						DisableReportCalls: disableReports,
 						MixerAttributes:    &mpb.Attributes{Attributes: attrs},
 						ForwardAttributes:  &mpb.Attributes{Attributes: attrs},
 					})
@@ -564,7 +581,7 @@ func modifyOutboundRouteConfig(push *model.PushContext, in *plugin.InputParams,
 		if virtualHostname == util.BlackHoleRouteName {
 			hostname := host.Name(util.BlackHoleCluster)
 			attrs := addVirtualDestinationServiceAttributes(make(attributes), hostname)
-			addFilterConfigToRoute(in, httpRoute, attrs, isXDSMarshalingToAnyEnabled)
+			addFilterConfigToRoute(in, httpRoute, attrs, isXDSMarshalingToAnyEnabled, disableReports)
 		}
 	// route.Route_Redirect is not used currently, so no attributes are added here
 	case *route.Route_Redirect:
@@ -580,11 +597,23 @@ func buildInboundRouteConfig(in *plugin.InputParams, instance *model.ServiceInst
 	attrs := addDestinationServiceAttributes(make(attributes), instance.Service)
 	out := &mccpb.ServiceConfig{
 		DisableCheckCalls:  disablePolicyChecks(inbound, in.Env.Mesh, in.Node),
-		DisableReportCalls: in.Env.Mesh.GetDisableMixerHttpReports(),
+		DisableReportCalls: disableHTTPReports(in),
 		MixerAttributes:    &mpb.Attributes{Attributes: attrs},
 	}
 
 	if configStore != nil {
+		apiSpecs := configStore.HTTPAPISpecByDestination(instance)
+		model.SortHTTPAPISpec(apiSpecs)
+		for _, apiSpec := range apiSpecs {
+			bytes, _ := gogoproto.Marshal(apiSpec.Spec)
+			converted := &mccpb.HTTPAPISpec{}
+			if err := proto.Unmarshal(bytes, converted); err != nil {
+				log.Warnf("failing to convert from gogo to golang: %v", err)
+				continue
+			}
+			out.HttpApiSpec = append(out.HttpApiSpec, converted)
+		}
+
 		quotaSpecs := configStore.QuotaSpecByDestination(instance)
 		model.SortQuotaSpec(quotaSpecs)
 		for _, quotaSpec := range quotaSpecs {
@@ -611,6 +640,7 @@ func buildOutboundTCPFilter(mesh *meshconfig.MeshConfig, attrsIn attributes, nod
 		DisableCheckCalls: disablePolicyChecks(outbound, mesh, node),
 		MixerAttributes:   &mpb.Attributes{Attributes: attrs},
 		Transport:         buildTransport(mesh, node),
+		ReportInterval:    tcpReportInterval(node),
 	}
 	out := &listener.Filter{
 		Name: mixer,
@@ -630,6 +660,7 @@ func buildInboundTCPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *
 		DisableCheckCalls: disablePolicyChecks(inbound, mesh, node),
 		MixerAttributes:   &mpb.Attributes{Attributes: attrs},
 		Transport:         buildTransport(mesh, node),
+		ReportInterval:    tcpReportInterval(node),
 	}
 	out := &listener.Filter{
 		Name: mixer,
@@ -644,6 +675,24 @@ func buildInboundTCPFilter(mesh *meshconfig.MeshConfig, attrs attributes, node *
 	return out
 }
 
+// tcpReportInterval returns the interval at which the TCP filter reports metrics for open
+// connections, or nil to use the filter default.
+func tcpReportInterval(node *model.Proxy) *duration.Duration {
+	interval := features.MixerTCPReportInterval
+	if len(node.Metadata.MixerTCPReportInterval) > 0 {
+		dur, err := time.ParseDuration(node.Metadata.MixerTCPReportInterval)
+		if err != nil {
+			log.Warnf("unable to parse tcp report interval %q.", node.Metadata.MixerTCPReportInterval)
+		} else {
+			interval = dur
+		}
+	}
+	if interval <= 0 {
+		return nil
+	}
+	return ptypes.DurationProto(interval)
+}
+
 func addServiceConfig(filterConfigs map[string]*pstruct.Struct, config *mccpb.ServiceConfig) map[string]*pstruct.Struct {
 	if filterConfigs == nil {
 		filterConfigs = make(map[string]*pstruct.Struct)
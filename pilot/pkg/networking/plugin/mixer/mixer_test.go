@@ -22,11 +22,14 @@ import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/duration"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
+	mixerpb "istio.io/api/mixer/v1/config/client"
 	networking "istio.io/api/networking/v1alpha3"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/fakes"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	mccpb "istio.io/istio/pilot/pkg/networking/plugin/mixer/client"
 	"istio.io/istio/pkg/config/mesh"
@@ -105,6 +108,37 @@ func TestTransportConfig(t *testing.T) {
 	}
 }
 
+func TestTCPReportInterval(t *testing.T) {
+	cases := []struct {
+		name     string
+		interval string
+		expect   *duration.Duration
+	}{
+		{
+			name:   "default",
+			expect: nil,
+		},
+		{
+			name:     "node metadata override",
+			interval: "1m",
+			expect:   ptypes.DurationProto(1 * time.Minute),
+		},
+		{
+			name:     "invalid node metadata",
+			interval: "often",
+			expect:   nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			node := &model.Proxy{Metadata: &model.NodeMetadata{MixerTCPReportInterval: c.interval}}
+			if got := tcpReportInterval(node); !reflect.DeepEqual(got, c.expect) {
+				t.Errorf("got %v, expected %v", got, c.expect)
+			}
+		})
+	}
+}
+
 func Test_proxyVersionToString(t *testing.T) {
 	type args struct {
 		ver *model.IstioVersion
@@ -311,6 +345,43 @@ func TestOnInboundListenerSkipMixer(t *testing.T) {
 	}
 }
 
+func TestBuildInboundRouteConfigAPISpec(t *testing.T) {
+	spec := &mixerpb.HTTPAPISpec{
+		Patterns: []*mixerpb.HTTPAPISpecPattern{
+			{
+				HttpMethod: "GET",
+				Pattern:    &mixerpb.HTTPAPISpecPattern_UriTemplate{UriTemplate: "/users/{id}"},
+			},
+		},
+	}
+	configStore := &fakes.IstioConfigStore{}
+	configStore.HTTPAPISpecByDestinationReturns([]model.Config{{Spec: spec}})
+
+	m := mesh.DefaultMeshConfig()
+	in := &plugin.InputParams{
+		Env: &model.Environment{
+			Mesh:             &m,
+			IstioConfigStore: configStore,
+		},
+		Node: &model.Proxy{
+			ID:       "foo.bar",
+			Type:     model.SidecarProxy,
+			Metadata: &model.NodeMetadata{},
+		},
+	}
+	instance := &model.ServiceInstance{
+		Service: &model.Service{Hostname: "users.default.svc.cluster.local"},
+	}
+
+	out := buildInboundRouteConfig(in, instance)
+	if len(out.HttpApiSpec) != 1 {
+		t.Fatalf("expected 1 HTTP API spec, got %v", out.HttpApiSpec)
+	}
+	if got := out.HttpApiSpec[0].Patterns[0].GetUriTemplate(); got != "/users/{id}" {
+		t.Errorf("got uri template %q, want /users/{id}", got)
+	}
+}
+
 func testAddress() *core.Address {
 	return &core.Address{Address: &core.Address_SocketAddress{SocketAddress: &core.SocketAddress{
 		Address:       "127.0.0.1",
@@ -0,0 +1,237 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+const (
+	// wasmFilterName is the name of the filter running the in-proxy extensions.
+	wasmFilterName = "envoy.filters.http.wasm"
+
+	// nullVMRuntime runs the extensions compiled in the proxy.
+	nullVMRuntime = "envoy.wasm.runtime.null"
+
+	// metadataExchangeExtension exchanges the metadata of the workloads with their peers, which is the
+	// source of the peer dimensions of the standard metrics.
+	metadataExchangeExtension = "envoy.wasm.metadata_exchange"
+
+	// statsExtension reports the standard metrics.
+	statsExtension = "envoy.wasm.stats"
+
+	statsInbound  = "stats_inbound"
+	statsOutbound = "stats_outbound"
+
+	// statPrefix prefixes the names of the standard metrics.
+	statPrefix = "istio"
+
+	// wasmTypeURL is the type of the configuration of the filter running the in-proxy extensions.
+	wasmTypeURL = "type.googleapis.com/envoy.config.filter.http.wasm.v2.Wasm"
+
+	// typedStructTypeURL is the type of the typed configurations set as a Struct.
+	typedStructTypeURL = "type.googleapis.com/udpa.type.v1.TypedStruct"
+)
+
+// typedStruct mirrors the udpa.type.v1.TypedStruct message, which sets a typed configuration as a Struct.
+// The go-control-plane version in use defines neither the Wasm filter configuration nor this message.
+type typedStruct struct {
+	TypeURL string           `protobuf:"bytes,1,opt,name=type_url,json=typeUrl,proto3"`
+	Value   *structpb.Struct `protobuf:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *typedStruct) Reset()         { *m = typedStruct{} }
+func (m *typedStruct) String() string { return proto.CompactTextString(m) }
+func (*typedStruct) ProtoMessage()    {}
+
+// Plugin inserts the metadata exchange and stats filters in the HTTP filter chains, when the stats
+// filter is enabled in the mesh config.
+type Plugin struct{}
+
+// NewPlugin returns an instance of the stats plugin
+func NewPlugin() plugin.Plugin {
+	return Plugin{}
+}
+
+// statsConfig is the configuration of the stats extension.
+type statsConfig struct {
+	Debug      string         `json:"debug"`
+	StatPrefix string         `json:"stat_prefix"`
+	Metrics    []metricConfig `json:"metrics,omitempty"`
+}
+
+// metricConfig customizes the dimensions of the standard metrics.
+type metricConfig struct {
+	Dimensions   map[string]string `json:"dimensions,omitempty"`
+	TagsToRemove []string          `json:"tags_to_remove,omitempty"`
+}
+
+// dimensionExpression returns the expression evaluated by the stats extension for the value of a dimension.
+func dimensionExpression(source *mesh.DimensionSource) string {
+	if source.RequestHeader != "" {
+		return fmt.Sprintf("request.headers['%s']", source.RequestHeader)
+	}
+	return fmt.Sprintf("node.metadata['%s']", source.NodeMetadata)
+}
+
+// buildStatsConfiguration returns the JSON configuration of the stats extension.
+func buildStatsConfiguration(settings *mesh.StatsFilterSettings) (string, error) {
+	config := statsConfig{
+		Debug:      "false",
+		StatPrefix: statPrefix,
+	}
+	if len(settings.ExtraDimensions) > 0 || len(settings.RemovedDimensions) > 0 {
+		metric := metricConfig{
+			TagsToRemove: settings.RemovedDimensions,
+		}
+		if len(settings.ExtraDimensions) > 0 {
+			metric.Dimensions = make(map[string]string, len(settings.ExtraDimensions))
+			for name, source := range settings.ExtraDimensions {
+				metric.Dimensions[name] = dimensionExpression(source)
+			}
+		}
+		config.Metrics = []metricConfig{metric}
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func stringValue(s string) *structpb.Value {
+	return &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: s}}
+}
+
+func structValue(fields map[string]*structpb.Value) *structpb.Value {
+	return &structpb.Value{Kind: &structpb.Value_StructValue{StructValue: &structpb.Struct{Fields: fields}}}
+}
+
+// buildWasmFilter returns a filter running an extension compiled in the proxy. Its configuration is typed
+// when the proxy configuration is marshaled to Any.
+func buildWasmFilter(node *model.Proxy, rootID, configuration, extension string) (*http_conn.HttpFilter, error) {
+	vmConfig := map[string]*structpb.Value{
+		"runtime": stringValue(nullVMRuntime),
+		"code":    structValue(map[string]*structpb.Value{"inline_string": stringValue(extension)}),
+	}
+	config := map[string]*structpb.Value{
+		"configuration": stringValue(configuration),
+		"vm_config":     structValue(vmConfig),
+	}
+	if rootID != "" {
+		config["root_id"] = stringValue(rootID)
+		vmConfig["vm_id"] = stringValue(rootID)
+	}
+	wasm := &structpb.Struct{Fields: map[string]*structpb.Value{"config": structValue(config)}}
+
+	if !util.IsXDSMarshalingToAnyEnabled(node) {
+		return &http_conn.HttpFilter{
+			Name:       wasmFilterName,
+			ConfigType: &http_conn.HttpFilter_Config{Config: wasm},
+		}, nil
+	}
+	b, err := proto.Marshal(&typedStruct{TypeURL: wasmTypeURL, Value: wasm})
+	if err != nil {
+		return nil, err
+	}
+	return &http_conn.HttpFilter{
+		Name:       wasmFilterName,
+		ConfigType: &http_conn.HttpFilter_TypedConfig{TypedConfig: &any.Any{TypeUrl: typedStructTypeURL, Value: b}},
+	}, nil
+}
+
+// buildStatsFilters inserts the metadata exchange filter first and the stats filter last in the HTTP filter
+// chains, so that the peer metadata is known and the response is complete when the stats are reported.
+func buildStatsFilters(in *plugin.InputParams, mutable *plugin.MutableObjects, rootID string) error {
+	settings := in.Env.MeshExtensions.GetStatsFilter()
+	if settings == nil {
+		return nil
+	}
+	configuration, err := buildStatsConfiguration(settings)
+	if err != nil {
+		return fmt.Errorf("failed to build the stats filter configuration: %v", err)
+	}
+	for i := range mutable.FilterChains {
+		if mutable.FilterChains[i].ListenerProtocol != plugin.ListenerProtocolHTTP {
+			continue
+		}
+		metadataExchange, err := buildWasmFilter(in.Node, "", metadataExchangeExtension, metadataExchangeExtension)
+		if err != nil {
+			return fmt.Errorf("failed to build the metadata exchange filter: %v", err)
+		}
+		stats, err := buildWasmFilter(in.Node, rootID, configuration, statsExtension)
+		if err != nil {
+			return fmt.Errorf("failed to build the stats filter: %v", err)
+		}
+		mutable.FilterChains[i].HTTP = append([]*http_conn.HttpFilter{metadataExchange}, mutable.FilterChains[i].HTTP...)
+		mutable.FilterChains[i].HTTP = append(mutable.FilterChains[i].HTTP, stats)
+	}
+	return nil
+}
+
+// OnOutboundListener is called whenever a new outbound listener is added to the LDS output for a given service.
+// It inserts the outbound stats filters, which are also used by the gateways.
+func (Plugin) OnOutboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return buildStatsFilters(in, mutable, statsOutbound)
+}
+
+// OnInboundListener is called whenever a new listener is added to the LDS output for a given service.
+// It inserts the inbound stats filters.
+func (Plugin) OnInboundListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return buildStatsFilters(in, mutable, statsInbound)
+}
+
+// OnVirtualListener implements the Plugin interface method.
+func (Plugin) OnVirtualListener(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
+
+// OnInboundCluster implements the Plugin interface method.
+func (Plugin) OnInboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnOutboundRouteConfiguration implements the Plugin interface method.
+func (Plugin) OnOutboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+}
+
+// OnInboundRouteConfiguration implements the Plugin interface method.
+func (Plugin) OnInboundRouteConfiguration(in *plugin.InputParams, route *xdsapi.RouteConfiguration) {
+}
+
+// OnOutboundCluster implements the Plugin interface method.
+func (Plugin) OnOutboundCluster(in *plugin.InputParams, cluster *xdsapi.Cluster) {
+}
+
+// OnInboundFilterChains implements the Plugin interface method.
+func (Plugin) OnInboundFilterChains(in *plugin.InputParams) []plugin.FilterChain {
+	return nil
+}
+
+// OnInboundPassthrough implements the Plugin interface method.
+func (Plugin) OnInboundPassthrough(in *plugin.InputParams, mutable *plugin.MutableObjects) error {
+	return nil
+}
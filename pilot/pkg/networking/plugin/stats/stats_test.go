@@ -0,0 +1,168 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	http_conn "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/golang/protobuf/proto"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestBuildStatsConfiguration(t *testing.T) {
+	cases := []struct {
+		name     string
+		settings *mesh.StatsFilterSettings
+		expected string
+	}{
+		{
+			name:     "default dimensions",
+			settings: &mesh.StatsFilterSettings{},
+			expected: `{"debug":"false","stat_prefix":"istio"}`,
+		},
+		{
+			name: "extra dimensions",
+			settings: &mesh.StatsFilterSettings{
+				ExtraDimensions: map[string]*mesh.DimensionSource{
+					"request_user_agent": {RequestHeader: "user-agent"},
+					"source_mesh":        {NodeMetadata: "MESH_ID"},
+				},
+			},
+			expected: `{"debug":"false","stat_prefix":"istio","metrics":[{"dimensions":` +
+				`{"request_user_agent":"request.headers['user-agent']","source_mesh":"node.metadata['MESH_ID']"}}]}`,
+		},
+		{
+			name: "removed dimensions",
+			settings: &mesh.StatsFilterSettings{
+				RemovedDimensions: []string{"request_protocol", "response_flags"},
+			},
+			expected: `{"debug":"false","stat_prefix":"istio","metrics":[{"tags_to_remove":["request_protocol","response_flags"]}]}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := buildStatsConfiguration(c.settings)
+			if err != nil {
+				t.Fatalf("buildStatsConfiguration() failed: %v", err)
+			}
+			if got != c.expected {
+				t.Errorf("got configuration %s, want %s", got, c.expected)
+			}
+		})
+	}
+}
+
+func filterNames(filters []*http_conn.HttpFilter) []string {
+	names := make([]string, 0, len(filters))
+	for _, f := range filters {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// wasmConfig returns the configuration of a Wasm filter, typed or not.
+func wasmConfig(t *testing.T, f *http_conn.HttpFilter) *structpb.Struct {
+	t.Helper()
+	if f.GetTypedConfig() == nil {
+		return f.GetConfig()
+	}
+	if got := f.GetTypedConfig().TypeUrl; got != typedStructTypeURL {
+		t.Fatalf("got typed config %s, want %s", got, typedStructTypeURL)
+	}
+	typed := &typedStruct{}
+	if err := proto.Unmarshal(f.GetTypedConfig().Value, typed); err != nil {
+		t.Fatal(err)
+	}
+	if typed.TypeURL != wasmTypeURL {
+		t.Fatalf("got typed struct %s, want %s", typed.TypeURL, wasmTypeURL)
+	}
+	return typed.Value
+}
+
+func rootID(t *testing.T, f *http_conn.HttpFilter) string {
+	t.Helper()
+	config := wasmConfig(t, f).GetFields()["config"].GetStructValue().GetFields()
+	if got, want := config["vm_config"].GetStructValue().GetFields()["vm_id"].GetStringValue(), config["root_id"].GetStringValue(); got != want {
+		t.Errorf("got VM id %q, want %q", got, want)
+	}
+	return config["root_id"].GetStringValue()
+}
+
+func TestOnListener(t *testing.T) {
+	newMutable := func() *plugin.MutableObjects {
+		return &plugin.MutableObjects{
+			FilterChains: []plugin.FilterChain{
+				{ListenerProtocol: plugin.ListenerProtocolHTTP, HTTP: []*http_conn.HttpFilter{{Name: "istio_authn"}}},
+				{ListenerProtocol: plugin.ListenerProtocolTCP},
+			},
+		}
+	}
+
+	disabled := &plugin.InputParams{Env: &model.Environment{}}
+	mutable := newMutable()
+	if err := NewPlugin().OnOutboundListener(disabled, mutable); err != nil {
+		t.Fatalf("OnOutboundListener() failed: %v", err)
+	}
+	if !reflect.DeepEqual(mutable, newMutable()) {
+		t.Errorf("got filter chains %v with the stats filter disabled", mutable.FilterChains)
+	}
+
+	enabled := &plugin.InputParams{Env: &model.Environment{
+		MeshExtensions: &mesh.Extensions{StatsFilter: &mesh.StatsFilterSettings{}},
+	}}
+	for _, c := range []struct {
+		name       string
+		onListener func(*plugin.InputParams, *plugin.MutableObjects) error
+		rootID     string
+		disableAny bool
+	}{
+		{"outbound", NewPlugin().OnOutboundListener, statsOutbound, false},
+		{"inbound", NewPlugin().OnInboundListener, statsInbound, false},
+		{"outbound marshaling to struct", NewPlugin().OnOutboundListener, statsOutbound, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			defer func(disabled bool) { features.DisableXDSMarshalingToAny = disabled }(features.DisableXDSMarshalingToAny)
+			features.DisableXDSMarshalingToAny = c.disableAny
+
+			mutable := newMutable()
+			if err := c.onListener(enabled, mutable); err != nil {
+				t.Fatalf("failed to build the listener: %v", err)
+			}
+			http := mutable.FilterChains[0].HTTP
+			if got, want := filterNames(http), []string{wasmFilterName, "istio_authn", wasmFilterName}; !reflect.DeepEqual(got, want) {
+				t.Fatalf("got HTTP filters %v, want %v", got, want)
+			}
+			if typed := http[2].GetTypedConfig() != nil; typed == c.disableAny {
+				t.Errorf("got typed config %v with the marshaling to Any disabled %v", typed, c.disableAny)
+			}
+			if got := rootID(t, http[0]); got != "" {
+				t.Errorf("got metadata exchange root id %q, want none", got)
+			}
+			if got := rootID(t, http[2]); got != c.rootID {
+				t.Errorf("got stats root id %q, want %q", got, c.rootID)
+			}
+			if len(mutable.FilterChains[1].HTTP) != 0 {
+				t.Errorf("got HTTP filters %v in a TCP filter chain", filterNames(mutable.FilterChains[1].HTTP))
+			}
+		})
+	}
+}
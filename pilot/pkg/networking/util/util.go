@@ -270,6 +270,37 @@ func IsIstioVersionGE14(node *model.Proxy) bool {
 		node.IstioVersion.Compare(&model.IstioVersion{Major: 1, Minor: 4, Patch: -1}) >= 0
 }
 
+// IsEnvoyVersionGE checks whether the Envoy version of the proxy is greater than or equals major.minor.
+// The proxies not reporting their Envoy version are assumed to run the latest version.
+func IsEnvoyVersionGE(node *model.Proxy, major, minor int) bool {
+	return node.EnvoyVersion == nil ||
+		node.EnvoyVersion.Compare(&model.IstioVersion{Major: major, Minor: minor, Patch: -1}) >= 0
+}
+
+// IsSafeRegexSupported checks whether the proxy supports the safe regex matchers, added in Envoy 1.12.
+// The older proxies reject the routes using them, and are sent the deprecated regex matchers instead.
+func IsSafeRegexSupported(node *model.Proxy) bool {
+	return IsEnvoyVersionGE(node, 1, 12)
+}
+
+// IsRetryHostPredicateSupported checks whether the proxy supports the retry host predicates and the
+// host selection retry attempts, added in Envoy 1.9.
+func IsRetryHostPredicateSupported(node *model.Proxy) bool {
+	return IsEnvoyVersionGE(node, 1, 9)
+}
+
+// IsTracingCustomTagsSupported checks whether the proxy supports the custom tags of the tracing configuration
+// of the HTTP connection managers, added in Envoy 1.13.
+func IsTracingCustomTagsSupported(node *model.Proxy) bool {
+	return IsEnvoyVersionGE(node, 1, 13)
+}
+
+// IsRegexRewriteSupported checks whether the proxy supports the regex rewrites of the route actions, added in
+// Envoy 1.14. The older proxies ignore them, and are sent the URI prefix rewrites instead.
+func IsRegexRewriteSupported(node *model.Proxy) bool {
+	return IsEnvoyVersionGE(node, 1, 14)
+}
+
 // IsXDSMarshalingToAnyEnabled controls whether "marshaling to Any" feature is enabled.
 func IsXDSMarshalingToAnyEnabled(node *model.Proxy) bool {
 	return !features.DisableXDSMarshalingToAny
@@ -548,8 +579,8 @@ func HandleCrash(handlers ...func()) {
 }
 
 // BuildLbEndpointMetadata adds metadata values to a lb endpoint
-func BuildLbEndpointMetadata(uid string, network string, tlsMode string) *core.Metadata {
-	if uid == "" && network == "" && tlsMode == model.DisabledTLSModeLabel {
+func BuildLbEndpointMetadata(uid string, network string, clusterID string, tlsMode string) *core.Metadata {
+	if uid == "" && network == "" && clusterID == "" && tlsMode == model.DisabledTLSModeLabel {
 		return nil
 	}
 
@@ -557,7 +588,7 @@ func BuildLbEndpointMetadata(uid string, network string, tlsMode string) *core.M
 		FilterMetadata: map[string]*pstruct.Struct{},
 	}
 
-	if uid != "" || network != "" {
+	if uid != "" || network != "" || clusterID != "" {
 		metadata.FilterMetadata[IstioMetadataKey] = &pstruct.Struct{
 			Fields: map[string]*pstruct.Value{},
 		}
@@ -569,6 +600,10 @@ func BuildLbEndpointMetadata(uid string, network string, tlsMode string) *core.M
 		if network != "" {
 			metadata.FilterMetadata[IstioMetadataKey].Fields["network"] = &pstruct.Value{Kind: &pstruct.Value_StringValue{StringValue: network}}
 		}
+
+		if clusterID != "" {
+			metadata.FilterMetadata[IstioMetadataKey].Fields["cluster"] = &pstruct.Value{Kind: &pstruct.Value_StringValue{StringValue: clusterID}}
+		}
 	}
 
 	if tlsMode != "" {
@@ -582,9 +617,33 @@ func BuildLbEndpointMetadata(uid string, network string, tlsMode string) *core.M
 	return metadata
 }
 
-// IsAllowAnyOutbound checks if allow_any is enabled for outbound traffic
+// IstioMetadata returns the string value of the given key in the istio filter metadata, or an
+// empty string if it is not set.
+func IstioMetadata(metadata *core.Metadata, key string) string {
+	if metadata == nil || metadata.FilterMetadata[IstioMetadataKey] == nil {
+		return ""
+	}
+	if v := metadata.FilterMetadata[IstioMetadataKey].Fields[key]; v != nil {
+		return v.GetStringValue()
+	}
+	return ""
+}
+
+// IsAllowAnyOutbound checks if allow_any is enabled for outbound traffic, as set for the catch all
+// egress listener
 func IsAllowAnyOutbound(node *model.Proxy) bool {
-	return node.SidecarScope != nil &&
-		node.SidecarScope.OutboundTrafficPolicy != nil &&
-		node.SidecarScope.OutboundTrafficPolicy.Mode == networking.OutboundTrafficPolicy_ALLOW_ANY
+	return IsAllowAnyOutboundForListener(node, 0, "")
+}
+
+// IsAllowAnyOutboundForListener checks if allow_any is enabled for the outbound traffic of the egress
+// listener of the port, or of the bind for unix domain sockets
+func IsAllowAnyOutboundForListener(node *model.Proxy, port int, bind string) bool {
+	if node.SidecarScope == nil {
+		return false
+	}
+	policy := node.SidecarScope.OutboundTrafficPolicy
+	if listener := node.SidecarScope.GetEgressListenerForRDS(port, bind); listener != nil && listener.OutboundTrafficPolicy != nil {
+		policy = listener.OutboundTrafficPolicy
+	}
+	return policy != nil && policy.Mode == networking.OutboundTrafficPolicy_ALLOW_ANY
 }
@@ -701,3 +701,40 @@ func TestIsAllowAnyOutbound(t *testing.T) {
 		})
 	}
 }
+
+func TestIsEnvoyVersionGE(t *testing.T) {
+	tests := []struct {
+		name   string
+		node   *model.Proxy
+		result bool
+	}{
+		{
+			name:   "UnknownVersion",
+			node:   &model.Proxy{},
+			result: true,
+		},
+		{
+			name:   "OlderMinor",
+			node:   &model.Proxy{EnvoyVersion: &model.IstioVersion{Major: 1, Minor: 11, Patch: 2}},
+			result: false,
+		},
+		{
+			name:   "SameMinor",
+			node:   &model.Proxy{EnvoyVersion: &model.IstioVersion{Major: 1, Minor: 12, Patch: 0}},
+			result: true,
+		},
+		{
+			name:   "NewerMajor",
+			node:   &model.Proxy{EnvoyVersion: &model.IstioVersion{Major: 2, Minor: 0, Patch: 0}},
+			result: true,
+		},
+	}
+	for i := range tests {
+		t.Run(tests[i].name, func(t *testing.T) {
+			out := IsEnvoyVersionGE(tests[i].node, 1, 12)
+			if out != tests[i].result {
+				t.Errorf("Expected %t but got %t for test case: %v\n", tests[i].result, out, tests[i].node)
+			}
+		})
+	}
+}
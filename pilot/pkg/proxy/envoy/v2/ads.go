@@ -96,6 +96,11 @@ type XdsConnection struct {
 	// current list of clusters monitored by the client
 	Clusters []string
 
+	// ListenerNames and ClusterNames are the listeners and clusters requested by name by proxyless
+	// gRPC clients, which only receive the resources they ask for.
+	ListenerNames []string
+	ClusterNames  []string
+
 	// Both ADS and EDS streams implement this interface
 	stream DiscoveryStream
 
@@ -107,9 +112,22 @@ type XdsConnection struct {
 	// CDSWatch is set if the remote server is watching Clusters
 	CDSWatch bool
 
+	// proxyOutdated is set if a push was skipped because the proxy was not watching any of the
+	// pushed types, leaving the state of the node outdated until the next request.
+	proxyOutdated bool
+
 	// added will be true if at least one discovery request was received, and the connection
 	// is added to the map of active.
 	added bool
+
+	// recordResponses is set if the last CDS, LDS and RDS responses sent to the client, and the
+	// last ones it ACKed, are recorded for /debug/config_diff.
+	recordResponses bool
+	sentResponses   map[string]*xdsapi.DiscoveryResponse
+	ackedResponses  map[string]*xdsapi.DiscoveryResponse
+
+	// nackBackoffs tracks the types the client rejected, and their quarantine.
+	nackBackoffs map[XdsType]*nackBackoff
 }
 
 // XdsEvent represents a config or registry event that results in a push.
@@ -193,6 +211,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 		return err
 	}
 	con := newXdsConnection(peerAddr, stream)
+	con.recordResponses = s.DebugConfigs
 
 	// Do not call: defer close(con.pushChannel) !
 	// the push channel will be garbage collected when the connection is no longer used.
@@ -224,16 +243,28 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 				}
 			}
 
+			// The pushes skipped while the proxy was not watching the pushed types did not update the node.
+			if con.proxyOutdated {
+				if err := s.updateProxy(con, s.globalPushContext()); err != nil {
+					return err
+				}
+			}
+
 			switch discReq.TypeUrl {
 			case ClusterType:
-				if con.CDSWatch {
+				// Proxyless gRPC clients request new clusters as they dial new targets.
+				namesChanged := con.node.IsProxylessGRPC() && !listEqualUnordered(con.ClusterNames, discReq.GetResourceNames())
+				if con.CDSWatch && !namesChanged {
 					// Already received a cluster watch request, this is an ACK
 					if discReq.ErrorDetail != nil {
 						errCode := codes.Code(discReq.ErrorDetail.Code)
 						adsLog.Warnf("ADS:CDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 						incrementXDSRejects(cdsReject, con.node.ID, errCode.String())
+						s.recordNack(con, CDS)
 					} else if discReq.ResponseNonce != "" {
 						con.ClusterNonceAcked = discReq.ResponseNonce
+						con.recordAck(ClusterType, discReq.ResponseNonce)
+						con.resetNacks(CDS)
 					}
 					adsLog.Debugf("ADS:CDS: ACK %s %s %s %s", peerAddr, con.ConID, discReq.VersionInfo, discReq.ResponseNonce)
 					continue
@@ -243,26 +274,33 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 				// soon as the CDS push is returned.
 				adsLog.Infof("ADS:CDS: REQ %v %s %v version:%s", peerAddr, con.ConID, time.Since(t0), discReq.VersionInfo)
 				con.CDSWatch = true
+				con.ClusterNames = discReq.GetResourceNames()
 				err := s.pushCds(con, s.globalPushContext(), versionInfo())
 				if err != nil {
 					return err
 				}
 
 			case ListenerType:
-				if con.LDSWatch {
+				// Proxyless gRPC clients request a new listener for each target they dial.
+				namesChanged := con.node.IsProxylessGRPC() && !listEqualUnordered(con.ListenerNames, discReq.GetResourceNames())
+				if con.LDSWatch && !namesChanged {
 					// Already received a cluster watch request, this is an ACK
 					if discReq.ErrorDetail != nil {
 						errCode := codes.Code(discReq.ErrorDetail.Code)
 						adsLog.Warnf("ADS:LDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 						incrementXDSRejects(ldsReject, con.node.ID, errCode.String())
+						s.recordNack(con, LDS)
 					} else if discReq.ResponseNonce != "" {
 						con.ListenerNonceAcked = discReq.ResponseNonce
+						con.recordAck(ListenerType, discReq.ResponseNonce)
+						con.resetNacks(LDS)
 					}
 					adsLog.Debugf("ADS:LDS: ACK %s %s %s %s", peerAddr, con.ConID, discReq.VersionInfo, discReq.ResponseNonce)
 					continue
 				}
 				adsLog.Debugf("ADS:LDS: REQ %s %v", con.ConID, peerAddr)
 				con.LDSWatch = true
+				con.ListenerNames = discReq.GetResourceNames()
 				err := s.pushLds(con, s.globalPushContext(), versionInfo())
 				if err != nil {
 					return err
@@ -273,6 +311,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 					errCode := codes.Code(discReq.ErrorDetail.Code)
 					adsLog.Warnf("ADS:RDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 					incrementXDSRejects(rdsReject, con.node.ID, errCode.String())
+					s.recordNack(con, RDS)
 					continue
 				}
 				routes := discReq.GetResourceNames()
@@ -293,6 +332,8 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 							con.mu.Lock()
 							con.RouteNonceAcked = discReq.ResponseNonce
 							con.mu.Unlock()
+							con.recordAck(RouteType, discReq.ResponseNonce)
+							con.resetNacks(RDS)
 							continue
 						}
 					} else if len(routes) == 0 {
@@ -314,6 +355,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 					errCode := codes.Code(discReq.ErrorDetail.Code)
 					adsLog.Warnf("ADS:EDS: ACK ERROR %v %s %s:%s", peerAddr, con.ConID, errCode.String(), discReq.ErrorDetail.GetMessage())
 					incrementXDSRejects(edsReject, con.node.ID, errCode.String())
+					s.recordNack(con, EDS)
 					continue
 				}
 				clusters := discReq.GetResourceNames()
@@ -322,6 +364,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 					con.mu.Lock()
 					con.EndpointNonceAcked = discReq.ResponseNonce
 					con.mu.Unlock()
+					con.resetNacks(EDS)
 					continue
 				}
 
@@ -342,6 +385,7 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 						}
 						edsClusterMutex.RUnlock()
 						con.mu.Unlock()
+						con.resetNacks(EDS)
 					}
 					continue
 				}
@@ -368,6 +412,9 @@ func (s *DiscoveryServer) StreamAggregatedResources(stream ads.AggregatedDiscove
 				con.mu.Unlock()
 				s.addCon(con.ConID, con)
 				defer s.removeCon(con.ConID, con)
+				if key := s.registerWorkload(con); key != "" {
+					defer s.autoRegistration.unregister(key)
+				}
 			} else {
 				con.mu.Unlock()
 			}
@@ -429,6 +476,11 @@ func (s *DiscoveryServer) initConnectionNode(node *core.Node, con *XdsConnection
 	if err != nil {
 		return err
 	}
+	if err := checkProxyVersion(nt); err != nil {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	// Track the Envoy version of the proxy, to only generate the configuration it supports
+	nt.EnvoyVersion = model.ParseEnvoyVersion(node.BuildVersion)
 	// Update the config namespace associated with this proxy
 	nt.ConfigNamespace = model.GetProxyConfigNamespace(nt)
 
@@ -468,53 +520,58 @@ func (s *DiscoveryServer) initConnectionNode(node *core.Node, con *XdsConnection
 	return nil
 }
 
-// DeltaAggregatedResources is not implemented.
-func (s *DiscoveryServer) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
-	return status.Errorf(codes.Unimplemented, "not implemented")
-}
-
 // Compute and send the new configuration for a connection. This is blocking and may be slow
 // for large configs. The method will hold a lock on con.pushMutex.
 func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) error {
 	// TODO: update the service deps based on NetworkScope
 
 	if pushEv.edsUpdatedServices != nil {
+		if !con.watching(EDS) {
+			adsLog.Debugf("Skipping EDS push to %v, no endpoints watched", con.ConID)
+			return nil
+		}
 		if !ProxyNeedsPush(con.node, pushEv) {
 			adsLog.Debugf("Skipping EDS push to %v, no updates required", con.ConID)
 			return nil
 		}
+		if con.skipQuarantined(EDS) {
+			adsLog.Debugf("Skipping EDS push to %v, quarantined", con.ConID)
+			return nil
+		}
 		// Push only EDS. This is indexed already - push immediately
 		// (may need a throttle)
-		if len(con.Clusters) > 0 {
-			if err := s.pushEds(pushEv.push, con, versionInfo(), pushEv.edsUpdatedServices); err != nil {
-				return err
-			}
+		if err := s.pushEds(pushEv.push, con, versionInfo(), pushEv.edsUpdatedServices); err != nil {
+			return err
 		}
 		return nil
 	}
 
-	// TODO: remove this ?
-	if err := con.node.SetWorkloadLabels(s.Env); err != nil {
-		return err
-	}
-
-	if err := con.node.SetServiceInstances(pushEv.push.Env); err != nil {
-		return err
+	// Skip the proxies not watching any of the types to push, such as gateways without listeners or idle
+	// proxies which have not requested their config yet, without precomputing their state.
+	pushTypes := PushTypeFor(con.node, pushEv)
+	// The types the proxy keeps rejecting are only pushed again once their quarantine expires.
+	for typ, push := range pushTypes {
+		if push && con.watching(typ) && con.skipQuarantined(typ) {
+			adsLog.Debugf("Skipping %s push to %v, quarantined", typ, con.ConID)
+			pushTypes[typ] = false
+		}
 	}
-	if util.IsLocalityEmpty(con.node.Locality) {
-		// Get the locality from the proxy's service instances.
-		// We expect all instances to have the same locality. So its enough to look at the first instance
-		if len(con.node.ServiceInstances) > 0 {
-			con.node.Locality = util.ConvertLocality(con.node.ServiceInstances[0].GetLocality())
+	watched := false
+	for typ, push := range pushTypes {
+		if push && con.watching(typ) {
+			watched = true
+			break
 		}
 	}
+	if !watched {
+		adsLog.Debugf("Skipping push to %v, no pushed types watched", con.ConID)
+		con.proxyOutdated = true
+		return nil
+	}
 
-	// Precompute the sidecar scope and merged gateways associated with this proxy.
-	// Saves compute cycles in networking code. Though this might be redundant sometimes, we still
-	// have to compute this because as part of a config change, a new Sidecar could become
-	// applicable to this proxy
-	con.node.SetSidecarScope(pushEv.push)
-	con.node.SetGatewaysForProxy(pushEv.push)
+	if err := s.updateProxy(con, pushEv.push); err != nil {
+		return err
+	}
 
 	// This depends on SidecarScope updates, so it should be called after SetSidecarScope.
 	if !ProxyNeedsPush(con.node, pushEv) {
@@ -526,28 +583,27 @@ func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) e
 
 	// check version, suppress if changed.
 	currentVersion := versionInfo()
-	pushTypes := PushTypeFor(con.node, pushEv)
 
-	if con.CDSWatch && pushTypes[CDS] {
+	if con.watching(CDS) && pushTypes[CDS] {
 		err := s.pushCds(con, pushEv.push, currentVersion)
 		if err != nil {
 			return err
 		}
 	}
 
-	if len(con.Clusters) > 0 && pushTypes[EDS] {
+	if con.watching(EDS) && pushTypes[EDS] {
 		err := s.pushEds(pushEv.push, con, currentVersion, nil)
 		if err != nil {
 			return err
 		}
 	}
-	if con.LDSWatch && pushTypes[LDS] {
+	if con.watching(LDS) && pushTypes[LDS] {
 		err := s.pushLds(con, pushEv.push, currentVersion)
 		if err != nil {
 			return err
 		}
 	}
-	if len(con.Routes) > 0 && pushTypes[RDS] {
+	if con.watching(RDS) && pushTypes[RDS] {
 		err := s.pushRoute(con, pushEv.push, currentVersion)
 		if err != nil {
 			return err
@@ -557,6 +613,49 @@ func (s *DiscoveryServer) pushConnection(con *XdsConnection, pushEv *XdsEvent) e
 	return nil
 }
 
+// updateProxy precomputes the state of the proxy used to generate its config.
+func (s *DiscoveryServer) updateProxy(con *XdsConnection, push *model.PushContext) error {
+	// TODO: remove this ?
+	if err := con.node.SetWorkloadLabels(s.Env); err != nil {
+		return err
+	}
+
+	if err := con.node.SetServiceInstances(push.Env); err != nil {
+		return err
+	}
+	if util.IsLocalityEmpty(con.node.Locality) {
+		// Get the locality from the proxy's service instances.
+		// We expect all instances to have the same locality. So its enough to look at the first instance
+		if len(con.node.ServiceInstances) > 0 {
+			con.node.Locality = util.ConvertLocality(con.node.ServiceInstances[0].GetLocality())
+		}
+	}
+
+	// Precompute the sidecar scope and merged gateways associated with this proxy.
+	// Saves compute cycles in networking code. Though this might be redundant sometimes, we still
+	// have to compute this because as part of a config change, a new Sidecar could become
+	// applicable to this proxy
+	con.node.SetSidecarScope(push)
+	con.node.SetGatewaysForProxy(push)
+	con.proxyOutdated = false
+	return nil
+}
+
+// watching returns whether the proxy subscribed to the type.
+func (con *XdsConnection) watching(typ XdsType) bool {
+	switch typ {
+	case CDS:
+		return con.CDSWatch
+	case EDS:
+		return len(con.Clusters) > 0
+	case LDS:
+		return con.LDSWatch
+	case RDS:
+		return len(con.Routes) > 0
+	}
+	return false
+}
+
 func adsClientCount() int {
 	var n int
 	adsClientsMutex.RLock()
@@ -672,6 +771,7 @@ func (s *DiscoveryServer) addCon(conID string, con *XdsConnection) {
 	xdsClients.Record(float64(len(adsClients)))
 	if con.node != nil {
 		node := con.node
+		recordProxyVersion(node, 1)
 
 		if _, ok := adsSidecarIDConnectionsMap[node.ID]; !ok {
 			adsSidecarIDConnectionsMap[node.ID] = map[string]*XdsConnection{conID: con}
@@ -694,6 +794,9 @@ func (s *DiscoveryServer) removeCon(conID string, con *XdsConnection) {
 		totalXDSInternalErrors.Increment()
 	} else {
 		delete(adsClients, conID)
+		if con.node != nil {
+			recordProxyVersion(con.node, -1)
+		}
 	}
 
 	xdsClients.Record(float64(len(adsClients)))
@@ -707,6 +810,21 @@ func (s *DiscoveryServer) removeCon(conID string, con *XdsConnection) {
 	}
 }
 
+// recordAck records the last response of the type sent to the client as applied by it, if the ACK
+// is for this response.
+func (conn *XdsConnection) recordAck(typeURL, nonce string) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	res := conn.sentResponses[typeURL]
+	if res == nil || res.Nonce != nonce {
+		return
+	}
+	if conn.ackedResponses == nil {
+		conn.ackedResponses = map[string]*xdsapi.DiscoveryResponse{}
+	}
+	conn.ackedResponses[typeURL] = res
+}
+
 // Send with timeout
 func (conn *XdsConnection) send(res *xdsapi.DiscoveryResponse) error {
 	done := make(chan error, 1)
@@ -714,6 +832,11 @@ func (conn *XdsConnection) send(res *xdsapi.DiscoveryResponse) error {
 	t := time.NewTimer(SendTimeout)
 	go func() {
 		err := conn.stream.Send(res)
+		if err == errUnchangedResponse {
+			// Nothing was sent to the delta client, which still ACKs the last nonce it received.
+			done <- nil
+			return
+		}
 		done <- err
 		conn.mu.Lock()
 		if res.Nonce != "" {
@@ -731,6 +854,12 @@ func (conn *XdsConnection) send(res *xdsapi.DiscoveryResponse) error {
 		if res.TypeUrl == RouteType {
 			conn.RouteVersionInfoSent = res.VersionInfo
 		}
+		if conn.recordResponses && err == nil && res.TypeUrl != EndpointType {
+			if conn.sentResponses == nil {
+				conn.sentResponses = map[string]*xdsapi.DiscoveryResponse{}
+			}
+			conn.sentResponses[res.TypeUrl] = res
+		}
 		conn.mu.Unlock()
 	}()
 	select {
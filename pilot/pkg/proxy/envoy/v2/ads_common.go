@@ -42,7 +42,7 @@ Loop:
 			if proxy.Type == model.Router {
 				return true
 			}
-		case schemas.QuotaSpec.Type, schemas.QuotaSpecBinding.Type:
+		case schemas.QuotaSpec.Type, schemas.QuotaSpecBinding.Type, schemas.HTTPAPISpec.Type, schemas.HTTPAPISpecBinding.Type:
 			if proxy.Type == model.SidecarProxy {
 				return true
 			}
@@ -87,6 +87,20 @@ const (
 	RDS
 )
 
+func (t XdsType) String() string {
+	switch t {
+	case CDS:
+		return "cds"
+	case EDS:
+		return "eds"
+	case LDS:
+		return "lds"
+	case RDS:
+		return "rds"
+	}
+	return "unknown"
+}
+
 // TODO: merge with ProxyNeedsPush
 func PushTypeFor(proxy *model.Proxy, pushEv *XdsEvent) map[XdsType]bool {
 	out := map[XdsType]bool{}
@@ -131,6 +145,9 @@ func PushTypeFor(proxy *model.Proxy, pushEv *XdsEvent) map[XdsType]bool {
 				out[RDS] = true
 			case schemas.QuotaSpec.Type, schemas.QuotaSpecBinding.Type:
 				out[RDS] = true
+			case schemas.HTTPAPISpec.Type, schemas.HTTPAPISpecBinding.Type:
+				// api specs are added to the inbound routes, which are part of the listeners
+				out[LDS] = true
 			case schemas.AuthenticationPolicy.Type, schemas.AuthenticationMeshPolicy.Type:
 				out[CDS] = true
 				out[EDS] = true
@@ -138,6 +155,9 @@ func PushTypeFor(proxy *model.Proxy, pushEv *XdsEvent) map[XdsType]bool {
 			case schemas.ServiceRole.Type, schemas.ServiceRoleBinding.Type, schemas.RbacConfig.Type,
 				schemas.ClusterRbacConfig.Type, schemas.AuthorizationPolicy.Type:
 				out[LDS] = true
+			case schemas.Telemetry.Type:
+				out[LDS] = true
+				out[RDS] = true
 			default:
 				out[CDS] = true
 				out[EDS] = true
@@ -171,7 +191,8 @@ func PushTypeFor(proxy *model.Proxy, pushEv *XdsEvent) map[XdsType]bool {
 				out[EDS] = true
 				out[LDS] = true
 				out[RDS] = true
-			case schemas.Sidecar.Type, schemas.QuotaSpec.Type, schemas.QuotaSpecBinding.Type:
+			case schemas.Sidecar.Type, schemas.QuotaSpec.Type, schemas.QuotaSpecBinding.Type,
+				schemas.HTTPAPISpec.Type, schemas.HTTPAPISpecBinding.Type:
 				// do not push for gateway
 			case schemas.AuthenticationPolicy.Type, schemas.AuthenticationMeshPolicy.Type:
 				out[CDS] = true
@@ -180,6 +201,9 @@ func PushTypeFor(proxy *model.Proxy, pushEv *XdsEvent) map[XdsType]bool {
 			case schemas.ServiceRole.Type, schemas.ServiceRoleBinding.Type, schemas.RbacConfig.Type,
 				schemas.ClusterRbacConfig.Type, schemas.AuthorizationPolicy.Type:
 				out[LDS] = true
+			case schemas.Telemetry.Type:
+				out[LDS] = true
+				out[RDS] = true
 			default:
 				out[CDS] = true
 				out[EDS] = true
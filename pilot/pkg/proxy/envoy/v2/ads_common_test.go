@@ -38,6 +38,8 @@ func TestProxyNeedsPush(t *testing.T) {
 		{"gateway config for gateway", gateway, nil, []string{schemas.Gateway.Type}, true},
 		{"quotaspec config for sidecar", sidecar, nil, []string{schemas.QuotaSpec.Type}, true},
 		{"quotaspec config for gateway", gateway, nil, []string{schemas.QuotaSpec.Type}, false},
+		{"httpapispec config for sidecar", sidecar, nil, []string{schemas.HTTPAPISpec.Type}, true},
+		{"httpapispec config for gateway", gateway, nil, []string{schemas.HTTPAPISpecBinding.Type}, false},
 	}
 
 	for _, tt := range cases {
@@ -119,6 +121,30 @@ func TestPushTypeFor(t *testing.T) {
 			configTypes: []string{schemas.AuthorizationPolicy.Type},
 			expect:      map[XdsType]bool{LDS: true},
 		},
+		{
+			name:        "httpapispec updated",
+			proxy:       sidecar,
+			configTypes: []string{schemas.HTTPAPISpec.Type},
+			expect:      map[XdsType]bool{LDS: true},
+		},
+		{
+			name:        "httpapispecbinding updated",
+			proxy:       gateway,
+			configTypes: []string{schemas.HTTPAPISpecBinding.Type},
+			expect:      map[XdsType]bool{},
+		},
+		{
+			name:        "telemetry updated",
+			proxy:       sidecar,
+			configTypes: []string{schemas.Telemetry.Type},
+			expect:      map[XdsType]bool{LDS: true, RDS: true},
+		},
+		{
+			name:        "telemetry updated",
+			proxy:       gateway,
+			configTypes: []string{schemas.Telemetry.Type},
+			expect:      map[XdsType]bool{LDS: true, RDS: true},
+		},
 		{
 			name:        "authenticationpolicy updated",
 			proxy:       sidecar,
@@ -193,3 +219,44 @@ func BenchmarkListEquals(b *testing.B) {
 		listEqualUnordered(l, notEqual)
 	}
 }
+
+func TestPushConnectionSkipsUnwatchedTypes(t *testing.T) {
+	// The discovery server has no environment: precomputing the state of the proxy would fail.
+	s := &DiscoveryServer{}
+	gateway := &model.Proxy{Type: model.Router, IPAddresses: []string{"127.0.0.1"}}
+	con := &XdsConnection{ConID: "gateway", node: gateway, Routes: []string{"http.80"}}
+
+	// A destination rule change only pushes the clusters and endpoints, not watched by the gateway.
+	pushEv := &XdsEvent{configTypesUpdated: map[string]struct{}{schemas.DestinationRule.Type: {}}}
+	if err := s.pushConnection(con, pushEv); err != nil {
+		t.Fatalf("pushConnection() => %v", err)
+	}
+	if !con.proxyOutdated {
+		t.Errorf("expected the proxy to be outdated after a skipped push")
+	}
+
+	// EDS pushes are skipped for the proxies without watched clusters.
+	pushEv = &XdsEvent{edsUpdatedServices: map[string]struct{}{"foo.bar": {}}}
+	if err := s.pushConnection(con, pushEv); err != nil {
+		t.Fatalf("pushConnection() => %v", err)
+	}
+}
+
+func TestXdsConnectionWatching(t *testing.T) {
+	con := &XdsConnection{}
+	for _, typ := range []XdsType{CDS, EDS, LDS, RDS} {
+		if con.watching(typ) {
+			t.Errorf("new connection is watching %v", typ)
+		}
+	}
+
+	con.CDSWatch = true
+	con.LDSWatch = true
+	con.Clusters = []string{"outbound|80||foo.bar"}
+	con.Routes = []string{"80"}
+	for _, typ := range []XdsType{CDS, EDS, LDS, RDS} {
+		if !con.watching(typ) {
+			t.Errorf("connection is not watching %v", typ)
+		}
+	}
+}
@@ -16,11 +16,13 @@ package v2_test
 import (
 	"fmt"
 	"io/ioutil"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
 
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	v2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
 	"istio.io/istio/pkg/test/env"
@@ -28,6 +30,7 @@ import (
 	"istio.io/istio/tests/util"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 )
 
 const (
@@ -180,6 +183,78 @@ func TestAdsClusterUpdate(t *testing.T) {
 	sendEDSReqAndVerify(cluster2)
 }
 
+// Incremental xDS clients only receive the clusters which changed since the last response.
+func TestAdsDelta(t *testing.T) {
+	server, tearDown := initLocalPilotTestEnv(t)
+	defer tearDown()
+
+	deltastr, cancel, err := connectDeltaADS(util.MockPilotGrpcAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+
+	err = deltastr.Send(&xdsapi.DeltaDiscoveryRequest{
+		Node:    &core.Node{Id: sidecarID(app3Ip, "app3"), Metadata: nodeMetadata},
+		TypeUrl: v2.ClusterType,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := deltaReceive(deltastr, 15*time.Second)
+	if err != nil {
+		t.Fatal("Recv failed", err)
+	}
+	if res.TypeUrl != v2.ClusterType || len(res.Resources) == 0 || len(res.RemovedResources) != 0 {
+		t.Fatalf("Expecting all the clusters, got %v", res)
+	}
+	for _, r := range res.Resources {
+		if r.Name == "" || r.Version == "" {
+			t.Errorf("Expecting named and versioned clusters, got %v", r)
+		}
+	}
+	err = deltastr.Send(&xdsapi.DeltaDiscoveryRequest{TypeUrl: v2.ClusterType, ResponseNonce: res.Nonce})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.EnvoyXdsServer.MemRegistry.AddService("adsdelta.default.svc.cluster.local", &model.Service{
+		Hostname: "adsdelta.default.svc.cluster.local",
+		Address:  "10.11.0.2",
+		Ports:    testPorts(0),
+	})
+	server.EnvoyXdsServer.ClearCache()
+
+	res, err = deltaReceive(deltastr, 15*time.Second)
+	if err != nil {
+		t.Fatal("Recv2 failed", err)
+	}
+	if len(res.Resources) == 0 {
+		t.Fatal("Expecting the clusters of the new service")
+	}
+	for _, r := range res.Resources {
+		if !strings.Contains(r.Name, "adsdelta.default.svc.cluster.local") {
+			t.Errorf("Expecting only the clusters of the new service, got %s", r.Name)
+		}
+	}
+
+	server.EnvoyXdsServer.MemRegistry.RemoveService("adsdelta.default.svc.cluster.local")
+	server.EnvoyXdsServer.ClearCache()
+
+	res, err = deltaReceive(deltastr, 15*time.Second)
+	if err != nil {
+		t.Fatal("Recv3 failed", err)
+	}
+	if len(res.Resources) != 0 || len(res.RemovedResources) == 0 {
+		t.Fatalf("Expecting the clusters of the service to be removed, got %v", res)
+	}
+	for _, name := range res.RemovedResources {
+		if !strings.Contains(name, "adsdelta.default.svc.cluster.local") {
+			t.Errorf("Expecting only the clusters of the removed service, got %s", name)
+		}
+	}
+}
+
 func TestAdsUpdate(t *testing.T) {
 	server, tearDown := initLocalPilotTestEnv(t)
 	defer tearDown()
@@ -264,7 +339,7 @@ func TestEnvoyRDSProtocolError(t *testing.T) {
 	defer cancel()
 
 	// wait for debounce
-	time.Sleep(3 * v2.DebounceAfter)
+	time.Sleep(3 * features.DebounceAfter.Get())
 
 	err = sendRDSReq(gatewayID(gatewayIP), []string{routeA, routeB}, "", edsstr)
 	if err != nil {
@@ -320,7 +395,7 @@ func TestEnvoyRDSUpdatedRouteRequest(t *testing.T) {
 	defer cancel()
 
 	// wait for debounce
-	time.Sleep(3 * v2.DebounceAfter)
+	time.Sleep(3 * features.DebounceAfter.Get())
 
 	err = sendRDSReq(gatewayID(gatewayIP), []string{routeA}, "", edsstr)
 	if err != nil {
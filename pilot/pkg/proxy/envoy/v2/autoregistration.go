@@ -0,0 +1,192 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/external"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// autoRegistration creates the WorkloadEntries of the workloads, typically VMs, whose proxy connects
+// with a reference to a WorkloadGroup, and deletes them once their proxies stayed disconnected for
+// the grace period.
+type autoRegistration struct {
+	store       model.ConfigStore
+	gracePeriod time.Duration
+
+	mutex sync.Mutex
+	// connections counts the connected proxies of each registered WorkloadEntry, keyed by namespace/name.
+	connections map[string]int
+	// cleanups holds the pending deletions of the WorkloadEntries whose proxies all disconnected.
+	cleanups map[string]*time.Timer
+}
+
+func newAutoRegistration(store model.ConfigStore, gracePeriod time.Duration) *autoRegistration {
+	return &autoRegistration{
+		store:       store,
+		gracePeriod: gracePeriod,
+		connections: map[string]int{},
+		cleanups:    map[string]*time.Timer{},
+	}
+}
+
+// register creates the WorkloadEntry of a proxy connecting with a WorkloadGroup in its metadata and
+// returns its key, or an empty key if the proxy does not reference any group. The proxy must present a
+// client certificate for the namespace of the group, and for the service account of its template if set.
+func (a *autoRegistration) register(proxy *model.Proxy, identities []string) (string, error) {
+	group := proxy.Metadata.AutoRegisterGroup
+	if group == "" {
+		return "", nil
+	}
+	if len(proxy.IPAddresses) == 0 {
+		return "", fmt.Errorf("proxy %s has no address", proxy.ID)
+	}
+	namespace := proxy.ConfigNamespace
+	groupCfg := a.store.Get(schemas.WorkloadGroup.Type, group, namespace)
+	if groupCfg == nil {
+		return "", fmt.Errorf("workload group %s/%s not found", namespace, group)
+	}
+	var serviceAccount string
+	if template := groupCfg.Spec.(*workload.WorkloadGroup).Template; template != nil {
+		serviceAccount = template.ServiceAccount
+	}
+	if !authorizedIdentity(identities, namespace, serviceAccount) {
+		return "", fmt.Errorf("identities %v of proxy %s are not allowed to join workload group %s/%s",
+			identities, proxy.ID, namespace, group)
+	}
+
+	name := workloadEntryName(group, proxy.IPAddresses[0])
+	key := namespace + "/" + name
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if cleanup, f := a.cleanups[key]; f {
+		cleanup.Stop()
+		delete(a.cleanups, key)
+	}
+	if a.connections[key] == 0 {
+		existing := a.store.Get(schemas.WorkloadEntry.Type, name, namespace)
+		if existing != nil && existing.Annotations[constants.WorkloadGroupAnnotation] != group {
+			return "", fmt.Errorf("workload entry %s was not registered from workload group %s", key, group)
+		}
+		if existing == nil {
+			entry := external.NewWorkloadEntry(*groupCfg, name, proxy.IPAddresses[0])
+			entry.Spec.(*workload.WorkloadEntry).Network = proxy.Metadata.Network
+			if _, err := a.store.Create(entry); err != nil {
+				return "", fmt.Errorf("failed to create workload entry %s: %v", key, err)
+			}
+			adsLog.Infof("ADS: registered workload entry %s for proxy %s", key, proxy.ID)
+		}
+	}
+	a.connections[key]++
+	return key, nil
+}
+
+// unregister releases the WorkloadEntry of a disconnected proxy. The entry is deleted after the grace
+// period, unless a proxy of the workload reconnects in the meantime.
+func (a *autoRegistration) unregister(key string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.connections[key]--
+	if a.connections[key] > 0 {
+		return
+	}
+	delete(a.connections, key)
+
+	var cleanup *time.Timer
+	cleanup = time.AfterFunc(a.gracePeriod, func() {
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+		// The workload reconnected, possibly disconnecting again, while this deletion was waiting for the lock.
+		if a.cleanups[key] != cleanup {
+			return
+		}
+		delete(a.cleanups, key)
+
+		parts := strings.SplitN(key, "/", 2)
+		if err := a.store.Delete(schemas.WorkloadEntry.Type, parts[1], parts[0]); err != nil {
+			adsLog.Warnf("ADS: failed to delete workload entry %s: %v", key, err)
+			return
+		}
+		adsLog.Infof("ADS: deleted workload entry %s of disconnected workload", key)
+	})
+	a.cleanups[key] = cleanup
+}
+
+// workloadEntryName names the WorkloadEntry of the workload of a group with the given address.
+func workloadEntryName(group, address string) string {
+	return group + "-" + strings.NewReplacer(".", "-", ":", "-").Replace(address)
+}
+
+// authorizedIdentity returns true if one of the SPIFFE identities, of the form
+// spiffe://<trust domain>/ns/<namespace>/sa/<service account>, belongs to the namespace and, if set,
+// to the service account.
+func authorizedIdentity(identities []string, namespace, serviceAccount string) bool {
+	for _, id := range identities {
+		if !strings.HasPrefix(id, "spiffe://") {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(id, "spiffe://"), "/")
+		if len(parts) != 5 || parts[1] != "ns" || parts[3] != "sa" {
+			continue
+		}
+		if parts[2] == namespace && (serviceAccount == "" || parts[4] == serviceAccount) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerIdentities returns the URI SANs of the verified client certificate of a stream, if any.
+func peerIdentities(ctx context.Context) []string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	var out []string
+	for _, uri := range tlsInfo.State.VerifiedChains[0][0].URIs {
+		out = append(out, uri.String())
+	}
+	return out
+}
+
+// registerWorkload creates the WorkloadEntry of the proxy of a new connection, if it references a
+// WorkloadGroup, and returns its key.
+func (s *DiscoveryServer) registerWorkload(con *XdsConnection) string {
+	if s.autoRegistration == nil {
+		return ""
+	}
+	key, err := s.autoRegistration.register(con.node, peerIdentities(con.stream.Context()))
+	if err != nil {
+		adsLog.Warnf("ADS: failed to register the workload of %s: %v", con.ConID, err)
+	}
+	return key
+}
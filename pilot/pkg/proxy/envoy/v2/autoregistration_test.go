@@ -0,0 +1,160 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/test/mock"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+const testIdentity = "spiffe://cluster.local/ns/vm/sa/httpbin"
+
+func newTestAutoRegistration(t *testing.T, gracePeriod time.Duration) (*autoRegistration, model.ConfigStore) {
+	t.Helper()
+	store := memory.Make(schemas.Istio)
+	_, err := store.Create(model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.WorkloadGroup.Type,
+			Group:     schemas.WorkloadGroup.Group + constants.IstioAPIGroupDomain,
+			Version:   schemas.WorkloadGroup.Version,
+			Name:      "httpbin",
+			Namespace: "vm",
+		},
+		Spec: mock.ExampleWorkloadGroup,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newAutoRegistration(store, gracePeriod), store
+}
+
+func vmProxy(group, ip string) *model.Proxy {
+	return &model.Proxy{
+		ID:              "vm-" + ip,
+		IPAddresses:     []string{ip},
+		ConfigNamespace: "vm",
+		Metadata:        &model.NodeMetadata{AutoRegisterGroup: group, Network: "vm-network"},
+	}
+}
+
+func TestAutoRegistration(t *testing.T) {
+	a, store := newTestAutoRegistration(t, time.Hour)
+
+	key, err := a.register(vmProxy("httpbin", "10.0.0.1"), []string{testIdentity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "vm/httpbin-10-0-0-1" {
+		t.Fatalf("unexpected key %q", key)
+	}
+	entry := store.Get(schemas.WorkloadEntry.Type, "httpbin-10-0-0-1", "vm")
+	if entry == nil {
+		t.Fatal("workload entry not created")
+	}
+	if entry.Annotations[constants.WorkloadGroupAnnotation] != "httpbin" {
+		t.Errorf("unexpected annotations %v", entry.Annotations)
+	}
+	spec := entry.Spec.(*workload.WorkloadEntry)
+	if spec.Address != "10.0.0.1" || spec.Network != "vm-network" || spec.ServiceAccount != "httpbin" {
+		t.Errorf("unexpected workload entry %v", spec)
+	}
+
+	// A second connection of the same workload shares the entry.
+	if _, err := a.register(vmProxy("httpbin", "10.0.0.1"), []string{testIdentity}); err != nil {
+		t.Fatal(err)
+	}
+	a.unregister(key)
+	a.unregister(key)
+	if len(a.cleanups) != 1 || len(a.connections) != 0 {
+		t.Fatalf("expected a pending cleanup, got %v %v", a.cleanups, a.connections)
+	}
+
+	// Reconnecting within the grace period cancels the cleanup.
+	if _, err := a.register(vmProxy("httpbin", "10.0.0.1"), []string{testIdentity}); err != nil {
+		t.Fatal(err)
+	}
+	if len(a.cleanups) != 0 || a.connections[key] != 1 {
+		t.Fatalf("expected the cleanup to be cancelled, got %v %v", a.cleanups, a.connections)
+	}
+	if store.Get(schemas.WorkloadEntry.Type, "httpbin-10-0-0-1", "vm") == nil {
+		t.Fatal("workload entry deleted")
+	}
+}
+
+func TestAutoRegistrationCleanup(t *testing.T) {
+	a, store := newTestAutoRegistration(t, time.Millisecond)
+
+	key, err := a.register(vmProxy("httpbin", "10.0.0.1"), []string{testIdentity})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.unregister(key)
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Get(schemas.WorkloadEntry.Type, "httpbin-10-0-0-1", "vm") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("workload entry not deleted after the grace period")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAutoRegistrationRejected(t *testing.T) {
+	a, store := newTestAutoRegistration(t, time.Hour)
+	if _, err := store.Create(model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.WorkloadEntry.Type,
+			Group:     schemas.WorkloadEntry.Group + constants.IstioAPIGroupDomain,
+			Version:   schemas.WorkloadEntry.Version,
+			Name:      "httpbin-10-0-0-2",
+			Namespace: "vm",
+		},
+		Spec: &workload.WorkloadEntry{Address: "10.0.0.2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		proxy      *model.Proxy
+		identities []string
+	}{
+		{"no identity", vmProxy("httpbin", "10.0.0.1"), nil},
+		{"other namespace", vmProxy("httpbin", "10.0.0.1"), []string{"spiffe://cluster.local/ns/default/sa/httpbin"}},
+		{"other service account", vmProxy("httpbin", "10.0.0.1"), []string{"spiffe://cluster.local/ns/vm/sa/default"}},
+		{"missing group", vmProxy("ratings", "10.0.0.1"), []string{testIdentity}},
+		{"manual entry", vmProxy("httpbin", "10.0.0.2"), []string{testIdentity}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if key, err := a.register(c.proxy, c.identities); err == nil {
+				t.Fatalf("expected an error, got key %q", key)
+			}
+		})
+	}
+	if store.Get(schemas.WorkloadEntry.Type, "httpbin-10-0-0-1", "vm") != nil {
+		t.Error("workload entry created for a rejected proxy")
+	}
+
+	if key, err := a.register(vmProxy("", "10.0.0.1"), nil); key != "" || err != nil {
+		t.Errorf("expected proxies without group to be ignored, got %q %v", key, err)
+	}
+}
@@ -144,7 +144,7 @@ func BenchmarkEDS(b *testing.B) {
 					clonedCLA := util.CloneClusterLoadAssignment(l)
 					l = &clonedCLA
 
-					loadbalancer.ApplyLocalityLBSetting(proxy.Locality, l, s.Env.Mesh.LocalityLbSetting, true)
+					loadbalancer.ApplyLocalityLBSetting(proxy.Locality, "", "", l, s.Env.Mesh.LocalityLbSetting, true)
 					loadAssignments = append(loadAssignments, l)
 				}
 				response = endpointDiscoveryResponse(loadAssignments, version, push.Version)
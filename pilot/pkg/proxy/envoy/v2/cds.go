@@ -20,6 +20,7 @@ import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/grpcgen"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
@@ -48,7 +49,10 @@ func (conn *XdsConnection) clusters(response []*xdsapi.Cluster, noncePrefix stri
 func (s *DiscoveryServer) pushCds(con *XdsConnection, push *model.PushContext, version string) error {
 	// TODO: Modify interface to take services, and config instead of making library query registry
 	pushStart := time.Now()
-	rawClusters := s.generateRawClusters(con.node, push)
+	var rawClusters []*xdsapi.Cluster
+	s.generationWorkers.run(func() {
+		rawClusters = s.generateRawClusters(con, push)
+	})
 
 	if s.DebugConfigs {
 		con.CDSClusters = rawClusters
@@ -69,8 +73,14 @@ func (s *DiscoveryServer) pushCds(con *XdsConnection, push *model.PushContext, v
 	return nil
 }
 
-func (s *DiscoveryServer) generateRawClusters(node *model.Proxy, push *model.PushContext) []*xdsapi.Cluster {
-	rawClusters := s.ConfigGenerator.BuildClusters(s.Env, node, push)
+func (s *DiscoveryServer) generateRawClusters(con *XdsConnection, push *model.PushContext) []*xdsapi.Cluster {
+	node := con.node
+	var rawClusters []*xdsapi.Cluster
+	if node.IsProxylessGRPC() {
+		rawClusters = grpcgen.BuildClusters(s.ConfigGenerator, s.Env, node, push, con.ClusterNames)
+	} else {
+		rawClusters = s.ConfigGenerator.BuildClusters(s.Env, node, push)
+	}
 
 	for _, c := range rawClusters {
 		if err := c.Validate(); err != nil {
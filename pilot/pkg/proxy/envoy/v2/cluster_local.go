@@ -0,0 +1,42 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+)
+
+// isClusterLocal returns true if traffic to the host must stay within the cluster of the proxy, as
+// configured by the serviceSettings of the mesh config.
+func isClusterLocal(env *model.Environment, hostname host.Name) bool {
+	for _, h := range env.MeshExtensions.ClusterLocalHosts() {
+		if hostname.SubsetOf(h) {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyClusterID returns the cluster of the proxy, or an empty string if it is not known.
+func proxyClusterID(proxy *model.Proxy) string {
+	if proxy.ClusterID != "" {
+		return proxy.ClusterID
+	}
+	if proxy.Metadata != nil {
+		return proxy.Metadata.ClusterID
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestIsClusterLocal(t *testing.T) {
+	env := &model.Environment{MeshExtensions: &mesh.Extensions{
+		ServiceSettings: []*mesh.ServiceSettings{{
+			Settings: mesh.ServiceSettingsOptions{ClusterLocal: true},
+			Hosts:    []string{"*.kube-system.svc.cluster.local", "db.default.svc.cluster.local"},
+		}},
+	}}
+
+	cases := []struct {
+		hostname host.Name
+		expected bool
+	}{
+		{"kube-dns.kube-system.svc.cluster.local", true},
+		{"db.default.svc.cluster.local", true},
+		{"reviews.default.svc.cluster.local", false},
+		{"kube-system.svc.cluster.local", false},
+	}
+	for _, c := range cases {
+		if got := isClusterLocal(env, c.hostname); got != c.expected {
+			t.Errorf("isClusterLocal(%s): got %v, expected %v", c.hostname, got, c.expected)
+		}
+	}
+}
+
+func TestBuildLocalityLbEndpointsFromShards_ClusterLocal(t *testing.T) {
+	shards := &EndpointShards{
+		Shards: map[string][]*model.IstioEndpoint{
+			"cluster-1": {{Address: "10.0.0.1", EndpointPort: 80, ServicePortName: "http"}},
+			"cluster-2": {{Address: "10.0.0.2", EndpointPort: 80, ServicePortName: "http"}},
+		},
+	}
+	port := &model.Port{Name: "http", Port: 80}
+
+	cases := []struct {
+		name      string
+		clusterID string
+		expected  []string
+	}{
+		{"all clusters", "", []string{"10.0.0.1", "10.0.0.2"}},
+		{"local cluster", "cluster-2", []string{"10.0.0.2"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			locEps := buildLocalityLbEndpointsFromShards(shards, port, nil, "outbound|80||db.default.svc.cluster.local",
+				c.clusterID, model.NewPushContext())
+			var got []string
+			for _, locEp := range locEps {
+				for _, lbEp := range locEp.LbEndpoints {
+					got = append(got, lbEp.GetEndpoint().Address.GetSocketAddress().Address)
+				}
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("got endpoints %v, expected %v", got, c.expected)
+			}
+		})
+	}
+}
+
+func TestProxyClusterID(t *testing.T) {
+	if got := proxyClusterID(&model.Proxy{ClusterID: "cluster-1", Metadata: &model.NodeMetadata{ClusterID: "cluster-2"}}); got != "cluster-1" {
+		t.Errorf("got %q, expected the cluster of the registry", got)
+	}
+	if got := proxyClusterID(&model.Proxy{Metadata: &model.NodeMetadata{ClusterID: "cluster-2"}}); got != "cluster-2" {
+		t.Errorf("got %q, expected the cluster from the node metadata", got)
+	}
+}
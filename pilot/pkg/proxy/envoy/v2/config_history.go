@@ -0,0 +1,89 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"sync"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// ConfigChange is a change of a config resource processed by the config controllers.
+type ConfigChange struct {
+	Time            time.Time `json:"time"`
+	Event           string    `json:"event"`
+	Type            string    `json:"type"`
+	Namespace       string    `json:"namespace,omitempty"`
+	Name            string    `json:"name"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+	// Origin is the config source the change comes from, e.g. the cluster or the MCP server.
+	Origin string `json:"origin,omitempty"`
+}
+
+// ConfigHistory records the latest config changes in a ring buffer, so that the changes made right before an
+// incident can be looked up from Pilot.
+type ConfigHistory struct {
+	mu      sync.RWMutex
+	changes []ConfigChange
+	// next is the index of the next change to record, which is the oldest one once the buffer is full.
+	next int
+	full bool
+}
+
+// NewConfigHistory creates a history recording the given number of changes. No change is recorded if the size is
+// not positive.
+func NewConfigHistory(size int) *ConfigHistory {
+	if size < 0 {
+		size = 0
+	}
+	return &ConfigHistory{changes: make([]ConfigChange, size)}
+}
+
+// Record records a change of the config coming from the origin.
+func (h *ConfigHistory) Record(origin string, c model.Config, event model.Event) {
+	if len(h.changes) == 0 {
+		return
+	}
+	change := ConfigChange{
+		Time:            time.Now(),
+		Event:           event.String(),
+		Type:            c.Type,
+		Namespace:       c.Namespace,
+		Name:            c.Name,
+		ResourceVersion: c.ResourceVersion,
+		Origin:          origin,
+	}
+
+	h.mu.Lock()
+	h.changes[h.next] = change
+	h.next = (h.next + 1) % len(h.changes)
+	if h.next == 0 {
+		h.full = true
+	}
+	h.mu.Unlock()
+}
+
+// Changes returns the recorded changes, the oldest first.
+func (h *ConfigHistory) Changes() []ConfigChange {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.full {
+		return append([]ConfigChange(nil), h.changes[:h.next]...)
+	}
+	out := make([]ConfigChange, 0, len(h.changes))
+	out = append(out, h.changes[h.next:]...)
+	return append(out, h.changes[:h.next]...)
+}
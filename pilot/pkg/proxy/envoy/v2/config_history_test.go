@@ -0,0 +1,94 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func historyNames(changes []ConfigChange) []string {
+	names := make([]string, 0, len(changes))
+	for _, c := range changes {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+func recordChanges(h *ConfigHistory, from, to int) {
+	for i := from; i < to; i++ {
+		h.Record("Kubernetes", model.Config{ConfigMeta: model.ConfigMeta{
+			Type:            "virtual-service",
+			Name:            fmt.Sprintf("vs%d", i),
+			Namespace:       "default",
+			ResourceVersion: fmt.Sprint(i),
+		}}, model.EventUpdate)
+	}
+}
+
+func TestConfigHistory(t *testing.T) {
+	h := NewConfigHistory(3)
+	if got := h.Changes(); len(got) != 0 {
+		t.Fatalf("got changes %v, want none", got)
+	}
+
+	recordChanges(h, 0, 2)
+	if got, want := historyNames(h.Changes()), []string{"vs0", "vs1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The oldest changes are dropped once the buffer is full.
+	recordChanges(h, 2, 5)
+	if got, want := historyNames(h.Changes()), []string{"vs2", "vs3", "vs4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	disabled := NewConfigHistory(0)
+	recordChanges(disabled, 0, 2)
+	if got := disabled.Changes(); len(got) != 0 {
+		t.Errorf("got changes %v, want none", got)
+	}
+}
+
+func TestConfigzHistory(t *testing.T) {
+	s := &DiscoveryServer{ConfigHistory: NewConfigHistory(10)}
+	recordChanges(s.ConfigHistory, 0, 2)
+
+	req, err := http.NewRequest("GET", "/debug/configz?history", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	s.configz(rr, req)
+
+	var got []ConfigChange
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d changes, want 2", len(got))
+	}
+	c := got[1]
+	if c.Event != "update" || c.Type != "virtual-service" || c.Namespace != "default" || c.Name != "vs1" ||
+		c.ResourceVersion != "1" || c.Origin != "Kubernetes" || c.Time.IsZero() {
+		t.Errorf("got change %+v", c)
+	}
+}
@@ -19,14 +19,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/http/pprof"
 	"sort"
 
 	"istio.io/istio/pilot/pkg/features"
 
 	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v2alpha"
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
 	"github.com/golang/protobuf/ptypes/any"
+	"github.com/pmezard/go-difflib/difflib"
 
 	authn "istio.io/api/authentication/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
@@ -63,29 +66,31 @@ func (s *DiscoveryServer) InitDebug(mux *http.ServeMux, sctl *aggregate.Controll
 	})
 
 	if enableProfiling {
-		mux.HandleFunc("/debug/pprof/", pprof.Index)
-		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
-		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
-		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
-		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		s.initProfiling(mux)
 	}
 
 	mux.HandleFunc("/ready", s.ready)
 
-	mux.HandleFunc("/debug/edsz", s.edsz)
-	mux.HandleFunc("/debug/adsz", s.adsz)
-	mux.HandleFunc("/debug/cdsz", cdsz)
-	mux.HandleFunc("/debug/syncz", Syncz)
-	mux.HandleFunc("/debug/config_distribution", s.distributedVersions)
-
-	mux.HandleFunc("/debug/registryz", s.registryz)
-	mux.HandleFunc("/debug/endpointz", s.endpointz)
-	mux.HandleFunc("/debug/endpointShardz", s.endpointShardz)
-	mux.HandleFunc("/debug/configz", s.configz)
-
-	mux.HandleFunc("/debug/authenticationz", s.Authenticationz)
-	mux.HandleFunc("/debug/config_dump", s.ConfigDump)
-	mux.HandleFunc("/debug/push_status", s.PushStatusHandler)
+	// The endpoints are guarded by guardDebug, and the ones triggering a push require admin access.
+	mux.HandleFunc("/debug/edsz", s.guardDebug(adminIfPush, s.edsz))
+	mux.HandleFunc("/debug/adsz", s.guardDebug(adminIfPush, s.adsz))
+	mux.HandleFunc("/debug/cdsz", s.guardDebug(nil, cdsz))
+	mux.HandleFunc("/debug/syncz", s.guardDebug(nil, Syncz))
+	mux.HandleFunc("/debug/config_distribution", s.guardDebug(nil, s.distributedVersions))
+
+	mux.HandleFunc("/debug/registryz", s.guardDebug(nil, s.registryz))
+	mux.HandleFunc("/debug/endpointz", s.guardDebug(nil, s.endpointz))
+	mux.HandleFunc("/debug/endpointShardz", s.guardDebug(nil, s.endpointShardz))
+	mux.HandleFunc("/debug/configz", s.guardDebug(nil, s.configz))
+	mux.HandleFunc("/debug/flagz", s.guardDebug(nil, flagz))
+
+	mux.HandleFunc("/debug/authenticationz", s.guardDebug(nil, s.Authenticationz))
+	mux.HandleFunc("/debug/config_dump", s.guardDebug(nil, s.ConfigDump))
+	mux.HandleFunc("/debug/config_diff", s.guardDebug(nil, s.ConfigDiff))
+	mux.HandleFunc("/debug/push_status", s.guardDebug(nil, s.PushStatusHandler))
+	mux.HandleFunc("/debug/version_skew", s.guardDebug(nil, versionSkewz))
+
+	s.initIntrospection(mux, sctl)
 }
 
 // SyncStatus is the synchronization status between Pilot and a given Envoy
@@ -93,6 +98,8 @@ type SyncStatus struct {
 	ProxyID         string `json:"proxy,omitempty"`
 	ProxyVersion    string `json:"proxy_version,omitempty"`
 	IstioVersion    string `json:"istio_version,omitempty"`
+	ClusterID       string `json:"cluster_id,omitempty"`
+	Network         string `json:"network,omitempty"`
 	ClusterSent     string `json:"cluster_sent,omitempty"`
 	ClusterAcked    string `json:"cluster_acked,omitempty"`
 	ListenerSent    string `json:"listener_sent,omitempty"`
@@ -102,6 +109,8 @@ type SyncStatus struct {
 	EndpointSent    string `json:"endpoint_sent,omitempty"`
 	EndpointAcked   string `json:"endpoint_acked,omitempty"`
 	EndpointPercent int    `json:"endpoint_percent,omitempty"`
+	// Quarantined lists the types not pushed to the proxy anymore, as it kept rejecting them.
+	Quarantined []string `json:"quarantined,omitempty"`
 }
 
 // Syncz dumps the synchronization status of all Envoys connected to this Pilot instance
@@ -111,9 +120,16 @@ func Syncz(w http.ResponseWriter, _ *http.Request) {
 	for _, con := range adsClients {
 		con.mu.RLock()
 		if con.node != nil {
+			proxyVersion := ""
+			if con.node.EnvoyVersion != nil {
+				proxyVersion = con.node.EnvoyVersion.String()
+			}
 			syncz = append(syncz, SyncStatus{
 				ProxyID:         con.node.ID,
+				ProxyVersion:    proxyVersion,
 				IstioVersion:    con.node.Metadata.IstioVersion,
+				ClusterID:       proxyClusterID(con.node),
+				Network:         con.node.Metadata.Network,
 				ClusterSent:     con.ClusterNonceSent,
 				ClusterAcked:    con.ClusterNonceAcked,
 				ListenerSent:    con.ListenerNonceSent,
@@ -123,6 +139,7 @@ func Syncz(w http.ResponseWriter, _ *http.Request) {
 				EndpointSent:    con.EndpointNonceSent,
 				EndpointAcked:   con.EndpointNonceAcked,
 				EndpointPercent: con.EndpointPercent,
+				Quarantined:     con.quarantinedTypes(),
 			})
 		}
 		con.mu.RUnlock()
@@ -160,6 +177,14 @@ func (s *DiscoveryServer) registryz(w http.ResponseWriter, req *http.Request) {
 	_, _ = fmt.Fprintln(w, "{}]")
 }
 
+// flagz dumps the current values of the dynamic feature flags, which can be overridden by the runtime flags.
+func flagz(w http.ResponseWriter, req *http.Request) {
+	_ = req.ParseForm()
+	w.Header().Add("Content-Type", "application/json")
+	out, _ := json.MarshalIndent(features.DynamicFlags(), " ", " ")
+	_, _ = w.Write(out)
+}
+
 // Dumps info about the endpoint shards, tracked using the new direct interface.
 // Legacy registry provides are synced to the new data structure as well, during
 // the full push.
@@ -172,6 +197,34 @@ func (s *DiscoveryServer) endpointShardz(w http.ResponseWriter, req *http.Reques
 	_, _ = w.Write(out)
 }
 
+// endpointzInstance is a service instance in the /debug/endpointz output, along with the cluster it was
+// discovered in.
+type endpointzInstance struct {
+	*model.ServiceInstance
+	Cluster string `json:"cluster,omitempty"`
+	Network string `json:"network,omitempty"`
+}
+
+// endpointClusters returns the cluster each endpoint of the service was discovered in, keyed by the
+// network and address of the endpoint.
+func (s *DiscoveryServer) endpointClusters(svc *model.Service) map[string]string {
+	out := map[string]string{}
+	s.mutex.RLock()
+	shards := s.EndpointShardsByService[string(svc.Hostname)][svc.Attributes.Namespace]
+	s.mutex.RUnlock()
+	if shards == nil {
+		return out
+	}
+	shards.mutex.RLock()
+	defer shards.mutex.RUnlock()
+	for clusterID, endpoints := range shards.Shards {
+		for _, ep := range endpoints {
+			out[ep.Network+"/"+ep.Address] = clusterID
+		}
+	}
+	return out
+}
+
 // Endpoint debugging
 func (s *DiscoveryServer) endpointz(w http.ResponseWriter, req *http.Request) {
 	_ = req.ParseForm()
@@ -180,15 +233,16 @@ func (s *DiscoveryServer) endpointz(w http.ResponseWriter, req *http.Request) {
 	if brief != "" {
 		svc, _ := s.Env.ServiceDiscovery.Services()
 		for _, ss := range svc {
+			clusters := s.endpointClusters(ss)
 			for _, p := range ss.Ports {
 				all, err := s.Env.ServiceDiscovery.InstancesByPort(ss, p.Port, nil)
 				if err != nil {
 					return
 				}
 				for _, svc := range all {
-					_, _ = fmt.Fprintf(w, "%s:%s %v %s:%d %v %s\n", ss.Hostname,
+					_, _ = fmt.Fprintf(w, "%s:%s %v %s:%d %v %s %s %s\n", ss.Hostname,
 						p.Name, svc.Endpoint.Family, svc.Endpoint.Address, svc.Endpoint.Port, svc.Labels,
-						svc.ServiceAccount)
+						svc.ServiceAccount, clusters[svc.Endpoint.Network+"/"+svc.Endpoint.Address], svc.Endpoint.Network)
 				}
 			}
 		}
@@ -198,6 +252,7 @@ func (s *DiscoveryServer) endpointz(w http.ResponseWriter, req *http.Request) {
 	svc, _ := s.Env.ServiceDiscovery.Services()
 	_, _ = fmt.Fprint(w, "[\n")
 	for _, ss := range svc {
+		clusters := s.endpointClusters(ss)
 		for _, p := range ss.Ports {
 			all, err := s.Env.ServiceDiscovery.InstancesByPort(ss, p.Port, nil)
 			if err != nil {
@@ -205,7 +260,11 @@ func (s *DiscoveryServer) endpointz(w http.ResponseWriter, req *http.Request) {
 			}
 			_, _ = fmt.Fprintf(w, "\n{\"svc\": \"%s:%s\", \"ep\": [\n", ss.Hostname, p.Name)
 			for _, svc := range all {
-				b, err := json.MarshalIndent(svc, "  ", "  ")
+				b, err := json.MarshalIndent(endpointzInstance{
+					ServiceInstance: svc,
+					Cluster:         clusters[svc.Endpoint.Network+"/"+svc.Endpoint.Address],
+					Network:         svc.Endpoint.Network,
+				}, "  ", "  ")
 				if err != nil {
 					return
 				}
@@ -294,9 +353,19 @@ func (s *DiscoveryServer) getResourceVersion(nonce, key string, cache map[string
 	return result
 }
 
-// Config debugging.
+// Config debugging. With the history parameter, shows the latest config changes instead, the oldest first.
 func (s *DiscoveryServer) configz(w http.ResponseWriter, req *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
+	if _, ok := req.URL.Query()["history"]; ok {
+		out, err := json.MarshalIndent(s.ConfigHistory.Changes(), "", "  ")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, "unable to marshal config history: %v", err)
+			return
+		}
+		_, _ = w.Write(out)
+		return
+	}
 	_, _ = fmt.Fprintf(w, "\n[\n")
 	for _, typ := range s.Env.IstioConfigStore.ConfigDescriptor() {
 		cfg, _ := s.Env.IstioConfigStore.List(typ.Type, "")
@@ -546,7 +615,7 @@ func (s *DiscoveryServer) ConfigDump(w http.ResponseWriter, req *http.Request) {
 // It is used in debugging to create a consistent object for comparison between Envoy and Pilot outputs
 func (s *DiscoveryServer) configDump(conn *XdsConnection) (*adminapi.ConfigDump, error) {
 	dynamicActiveClusters := []*adminapi.ClustersConfigDump_DynamicCluster{}
-	clusters := s.generateRawClusters(conn.node, s.globalPushContext())
+	clusters := s.generateRawClusters(conn, s.globalPushContext())
 
 	for _, cs := range clusters {
 		dynamicActiveClusters = append(dynamicActiveClusters, &adminapi.ClustersConfigDump_DynamicCluster{Cluster: cs})
@@ -592,6 +661,194 @@ func (s *DiscoveryServer) configDump(conn *XdsConnection) (*adminapi.ConfigDump,
 	return configDump, nil
 }
 
+// ProxyConfigDiff is the drift between the configuration Pilot currently generates for a proxy and the
+// configuration the proxy last ACKed.
+type ProxyConfigDiff struct {
+	ProxyID   string        `json:"proxy"`
+	Clusters  *ResourceDiff `json:"clusters"`
+	Listeners *ResourceDiff `json:"listeners"`
+	Routes    *ResourceDiff `json:"routes"`
+}
+
+// ResourceDiff is the drift of the resources of a type.
+type ResourceDiff struct {
+	// Pending is set if the proxy did not ACK the last resources of the type pushed to it, e.g. because it
+	// rejected them.
+	Pending bool `json:"pending,omitempty"`
+	// Missing are the resources generated by Pilot but not applied by the proxy.
+	Missing []string `json:"missing,omitempty"`
+	// Stale are the resources applied by the proxy but no longer generated by Pilot.
+	Stale []string `json:"stale,omitempty"`
+	// Changed are the unified diffs, from the applied to the generated resource, of the resources which differ.
+	Changed map[string]string `json:"changed,omitempty"`
+}
+
+// ConfigDiff generates the configuration Pilot would currently push to the specified proxy, and diffs it
+// against the configuration the proxy last ACKed. The ACKed configuration is only recorded when
+// PILOT_DEBUG_ADSZ_CONFIG is set.
+func (s *DiscoveryServer) ConfigDiff(w http.ResponseWriter, req *http.Request) {
+	proxyID := req.URL.Query().Get("proxyID")
+	if proxyID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("You must provide a proxyID in the query string"))
+		return
+	}
+	if !s.DebugConfigs {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("The configuration ACKed by the proxies is only recorded with PILOT_DEBUG_ADSZ_CONFIG=true"))
+		return
+	}
+	conn := mostRecentConnection(proxyID)
+	if conn == nil {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("Proxy not connected to this Pilot instance"))
+		return
+	}
+
+	diff, err := s.configDiff(conn)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+	out, err := json.MarshalIndent(diff, "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal config diff: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+// mostRecentConnection returns the most recent connection of the proxy, if it is connected.
+func mostRecentConnection(proxyID string) *XdsConnection {
+	adsClientsMutex.RLock()
+	defer adsClientsMutex.RUnlock()
+	mostRecent := ""
+	for key := range adsSidecarIDConnectionsMap[proxyID] {
+		if mostRecent == "" || key > mostRecent {
+			mostRecent = key
+		}
+	}
+	if mostRecent == "" {
+		return nil
+	}
+	return adsSidecarIDConnectionsMap[proxyID][mostRecent]
+}
+
+func (s *DiscoveryServer) configDiff(conn *XdsConnection) (*ProxyConfigDiff, error) {
+	push := s.globalPushContext()
+	conn.mu.RLock()
+	sent := make(map[string]*xdsapi.DiscoveryResponse, len(conn.sentResponses))
+	for typeURL, res := range conn.sentResponses {
+		sent[typeURL] = res
+	}
+	acked := make(map[string]*xdsapi.DiscoveryResponse, len(conn.ackedResponses))
+	for typeURL, res := range conn.ackedResponses {
+		acked[typeURL] = res
+	}
+	conn.mu.RUnlock()
+
+	diff := &ProxyConfigDiff{ProxyID: conn.node.ID}
+	var err error
+	generated := make([]proto.Message, 0)
+	for _, c := range s.generateRawClusters(conn, push) {
+		generated = append(generated, c)
+	}
+	if diff.Clusters, err = diffResources(generated, sent[ClusterType], acked[ClusterType], &xdsapi.Cluster{}); err != nil {
+		return nil, err
+	}
+	generated = generated[:0]
+	for _, l := range s.generateRawListeners(conn, push) {
+		generated = append(generated, l)
+	}
+	if diff.Listeners, err = diffResources(generated, sent[ListenerType], acked[ListenerType], &xdsapi.Listener{}); err != nil {
+		return nil, err
+	}
+	generated = generated[:0]
+	for _, r := range s.generateRawRoutes(conn, push) {
+		generated = append(generated, r)
+	}
+	if diff.Routes, err = diffResources(generated, sent[RouteType], acked[RouteType], &xdsapi.RouteConfiguration{}); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// namedResource is implemented by the clusters, listeners and route configurations.
+type namedResource interface {
+	proto.Message
+	GetName() string
+}
+
+// diffResources diffs the generated resources against the ones of the last ACKed response, decoded
+// into copies of the prototype.
+func diffResources(generated []proto.Message, sent, acked *xdsapi.DiscoveryResponse, prototype proto.Message) (*ResourceDiff, error) {
+	jsonm := &jsonpb.Marshaler{Indent: "  "}
+	toJSON := func(msg proto.Message) (string, error) {
+		out, err := jsonm.MarshalToString(msg)
+		if err != nil {
+			return "", err
+		}
+		return out + "\n", nil
+	}
+
+	applied := map[string]string{}
+	for _, res := range acked.GetResources() {
+		msg := proto.Clone(prototype)
+		if err := ptypes.UnmarshalAny(res, msg); err != nil {
+			return nil, err
+		}
+		out, err := toJSON(msg)
+		if err != nil {
+			return nil, err
+		}
+		applied[msg.(namedResource).GetName()] = out
+	}
+
+	diff := &ResourceDiff{Pending: sent != acked}
+	seen := map[string]bool{}
+	for _, msg := range generated {
+		name := msg.(namedResource).GetName()
+		seen[name] = true
+		out, err := toJSON(msg)
+		if err != nil {
+			return nil, err
+		}
+		old, f := applied[name]
+		if !f {
+			diff.Missing = append(diff.Missing, name)
+			continue
+		}
+		if old == out {
+			continue
+		}
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(old),
+			FromFile: "Applied " + name,
+			B:        difflib.SplitLines(out),
+			ToFile:   "Generated " + name,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if diff.Changed == nil {
+			diff.Changed = map[string]string{}
+		}
+		diff.Changed[name] = text
+	}
+	for name := range applied {
+		if !seen[name] {
+			diff.Stale = append(diff.Stale, name)
+		}
+	}
+	sort.Strings(diff.Missing)
+	sort.Strings(diff.Stale)
+	return diff, nil
+}
+
 // PushStatusHandler dumps the last PushContext
 func (s *DiscoveryServer) PushStatusHandler(w http.ResponseWriter, req *http.Request) {
 	if model.LastPushStatus == nil {
@@ -636,10 +893,6 @@ func writeAllADS(w io.Writer) {
 	_, _ = fmt.Fprint(w, "]\n")
 }
 
-func (s *DiscoveryServer) ready(w http.ResponseWriter, req *http.Request) {
-	w.WriteHeader(200)
-}
-
 // edsz implements a status and debug interface for EDS.
 // It is mapped to /debug/edsz on the monitor port (15014).
 func (s *DiscoveryServer) edsz(w http.ResponseWriter, req *http.Request) {
@@ -23,6 +23,9 @@ import (
 	"testing"
 	"time"
 
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+
 	authn "istio.io/api/authentication/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/istioctl/pkg/util/configdump"
@@ -291,6 +294,91 @@ func getConfigDump(t *testing.T, s *v2.DiscoveryServer, proxyID string, wantCode
 	return got
 }
 
+func TestConfigDiff(t *testing.T) {
+	s, tearDown := initLocalPilotTestEnv(t)
+	defer tearDown()
+
+	proxyID := "diffApp-644fc65469-96dza.testns"
+	getConfigDiff(t, s.EnvoyXdsServer, "", http.StatusBadRequest)
+	getConfigDiff(t, s.EnvoyXdsServer, proxyID, http.StatusBadRequest)
+	s.EnvoyXdsServer.DebugConfigs = true
+	defer func() { s.EnvoyXdsServer.DebugConfigs = false }()
+	getConfigDiff(t, s.EnvoyXdsServer, proxyID, http.StatusNotFound)
+
+	envoy, cancel, err := connectADS(util.MockPilotGrpcAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cancel()
+	if err := sendCDSReq(sidecarID(app3Ip, "diffApp"), envoy); err != nil {
+		t.Fatal(err)
+	}
+	res, err := adsReceive(envoy, 5*time.Second)
+	if err != nil {
+		t.Fatal("Recv failed", err)
+	}
+
+	// the clusters were pushed, but not ACKed yet
+	var diff *v2.ProxyConfigDiff
+	for i := 0; i < 50; i++ {
+		if diff = getConfigDiff(t, s.EnvoyXdsServer, proxyID, http.StatusOK); diff.Clusters.Pending {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if !diff.Clusters.Pending || len(diff.Clusters.Missing) == 0 {
+		t.Fatalf("expected the unACKed clusters to be missing, got %+v", diff.Clusters)
+	}
+
+	err = envoy.Send(&xdsapi.DiscoveryRequest{
+		ResponseNonce: res.Nonce,
+		Node: &core.Node{
+			Id:       sidecarID(app3Ip, "diffApp"),
+			Metadata: nodeMetadata,
+		},
+		TypeUrl: v2.ClusterType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if diff = getConfigDiff(t, s.EnvoyXdsServer, proxyID, http.StatusOK); !diff.Clusters.Pending {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if diff.Clusters.Pending || len(diff.Clusters.Missing) > 0 || len(diff.Clusters.Stale) > 0 || len(diff.Clusters.Changed) > 0 {
+		t.Errorf("expected the ACKed clusters to match, got %+v", diff.Clusters)
+	}
+	if len(diff.Listeners.Missing) == 0 || diff.Listeners.Pending {
+		t.Errorf("expected the listeners never pushed to be missing, got %+v", diff.Listeners)
+	}
+}
+
+func getConfigDiff(t *testing.T, s *v2.DiscoveryServer, proxyID string, wantCode int) *v2.ProxyConfigDiff {
+	t.Helper()
+	path := "/config_diff"
+	if proxyID != "" {
+		path += fmt.Sprintf("?proxyID=%v", proxyID)
+	}
+	req, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	http.HandlerFunc(s.ConfigDiff).ServeHTTP(rr, req)
+	if rr.Code != wantCode {
+		t.Fatalf("wanted response code %v, got %v: %s", wantCode, rr.Code, rr.Body.String())
+	}
+	if wantCode > 399 {
+		return nil
+	}
+	got := &v2.ProxyConfigDiff{}
+	if err := json.Unmarshal(rr.Body.Bytes(), got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
 // TestAuthenticationZ tests the /debug/authenticationz handle. Due to the limitation of the test setup,
 // this test converts only one simple scenario. See TestAnalyzeMTLSSettings for more
 func TestAuthenticationZ(t *testing.T) {
@@ -0,0 +1,155 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DebugAuthenticator authenticates the callers of the debug endpoints.
+type DebugAuthenticator interface {
+	// Authenticate returns the identity of the caller of the request.
+	Authenticate(r *http.Request) (string, error)
+}
+
+type debugAuthenticator struct {
+	client kubernetes.Interface
+}
+
+// NewDebugAuthenticator returns a DebugAuthenticator identifying the callers by the SPIFFE URI of their verified
+// client certificate, when served over mTLS, or else by the Kubernetes user name of the bearer token of their
+// Authorization header, reviewed through the TokenReview API. Bearer tokens are rejected if client is nil.
+func NewDebugAuthenticator(client kubernetes.Interface) DebugAuthenticator {
+	return &debugAuthenticator{client: client}
+}
+
+func (a *debugAuthenticator) Authenticate(r *http.Request) (string, error) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		for _, uri := range r.TLS.VerifiedChains[0][0].URIs {
+			if uri.Scheme == "spiffe" {
+				return uri.String(), nil
+			}
+		}
+	}
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", errors.New("no client certificate or bearer token")
+	}
+	if a.client == nil {
+		return "", errors.New("bearer tokens are not supported without Kubernetes")
+	}
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{
+			Token: strings.TrimPrefix(header, "Bearer "),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to review the token: %v", err)
+	}
+	if review.Status.Error != "" {
+		return "", fmt.Errorf("failed to review the token: %v", review.Status.Error)
+	}
+	if !review.Status.Authenticated {
+		return "", errors.New("the token is not authenticated")
+	}
+	return review.Status.User.Username, nil
+}
+
+// debugAuthorization lists the identities allowed to read the debug endpoints, and to use the admin ones.
+type debugAuthorization struct {
+	// readers is empty if any authenticated caller can read the debug endpoints.
+	readers map[string]struct{}
+	admins  map[string]struct{}
+}
+
+func newDebugAuthorization(readers, admins string) *debugAuthorization {
+	return &debugAuthorization{
+		readers: identitySet(readers),
+		admins:  identitySet(admins),
+	}
+}
+
+func identitySet(identities string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, identity := range strings.Split(identities, ",") {
+		if identity = strings.TrimSpace(identity); identity != "" {
+			set[identity] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (a *debugAuthorization) allowed(identity string, admin bool) bool {
+	if _, f := a.admins[identity]; f {
+		return true
+	}
+	if admin {
+		return false
+	}
+	if len(a.readers) == 0 {
+		return true
+	}
+	_, f := a.readers[identity]
+	return f
+}
+
+// adminIfPush is the access of the debug endpoints triggering a push to all the proxies with ?push.
+func adminIfPush(r *http.Request) bool {
+	return r.FormValue("push") != ""
+}
+
+// guardDebug serves the requests to a debug endpoint only to the authorized callers, if PILOT_ENABLE_DEBUG_AUTH
+// is set. requiresAdmin tells whether a request changes the state of Pilot; nil for the read-only endpoints.
+// The requests from the loopback interface, e.g. through kubectl port-forward, are always served.
+func (s *DiscoveryServer) guardDebug(requiresAdmin func(*http.Request) bool, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.debugAuthorization == nil || isLoopback(r) {
+			handler(w, r)
+			return
+		}
+
+		identity, err := s.DebugAuthenticator.Authenticate(r)
+		if err != nil {
+			adsLog.Debugf("unauthenticated request to %s from %s: %v", r.URL.Path, r.RemoteAddr, err)
+			http.Error(w, fmt.Sprintf("unauthenticated: %v", err), http.StatusUnauthorized)
+			return
+		}
+		admin := requiresAdmin != nil && requiresAdmin(r)
+		if !s.debugAuthorization.allowed(identity, admin) {
+			adsLog.Infof("unauthorized request to %s from %s (admin %v)", r.URL.Path, identity, admin)
+			http.Error(w, fmt.Sprintf("%s is not authorized", identity), http.StatusForbidden)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func isLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,120 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestGuardDebug(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "reader-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:istio-system:reader"
+		case "admin-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:istio-system:admin"
+		case "other-token":
+			review.Status.Authenticated = true
+			review.Status.User.Username = "system:serviceaccount:default:other"
+		}
+		return true, review, nil
+	})
+	s := &DiscoveryServer{
+		DebugAuthenticator: NewDebugAuthenticator(client),
+		debugAuthorization: newDebugAuthorization(
+			"system:serviceaccount:istio-system:reader, spiffe://cluster.local/ns/istio-system/sa/reader",
+			"system:serviceaccount:istio-system:admin"),
+	}
+
+	cases := []struct {
+		name       string
+		remoteAddr string
+		token      string
+		spiffe     string
+		push       bool
+		wantCode   int
+	}{
+		{"loopback", "127.0.0.1:1234", "", "", true, http.StatusOK},
+		{"no credentials", "10.0.0.1:1234", "", "", false, http.StatusUnauthorized},
+		{"invalid token", "10.0.0.1:1234", "invalid-token", "", false, http.StatusUnauthorized},
+		{"reader", "10.0.0.1:1234", "reader-token", "", false, http.StatusOK},
+		{"reader push", "10.0.0.1:1234", "reader-token", "", true, http.StatusForbidden},
+		{"admin push", "10.0.0.1:1234", "admin-token", "", true, http.StatusOK},
+		{"admin read", "10.0.0.1:1234", "admin-token", "", false, http.StatusOK},
+		{"not a reader", "10.0.0.1:1234", "other-token", "", false, http.StatusForbidden},
+		{"reader certificate", "10.0.0.1:1234", "", "spiffe://cluster.local/ns/istio-system/sa/reader", false, http.StatusOK},
+		{"other certificate", "10.0.0.1:1234", "", "spiffe://cluster.local/ns/default/sa/other", false, http.StatusForbidden},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			served := false
+			handler := s.guardDebug(adminIfPush, func(w http.ResponseWriter, r *http.Request) {
+				served = true
+			})
+
+			target := "/debug/adsz"
+			if tt.push {
+				target += "?push=true"
+			}
+			req := httptest.NewRequest("GET", target, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.spiffe != "" {
+				uri, _ := url.Parse(tt.spiffe)
+				req.TLS = &tls.ConnectionState{
+					VerifiedChains: [][]*x509.Certificate{{{URIs: []*url.URL{uri}}}},
+				}
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got code %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if served != (tt.wantCode == http.StatusOK) {
+				t.Errorf("got served %v with code %d", served, rec.Code)
+			}
+		})
+	}
+}
+
+func TestGuardDebugDisabled(t *testing.T) {
+	s := &DiscoveryServer{DebugAuthenticator: NewDebugAuthenticator(nil)}
+	handler := s.guardDebug(adminIfPush, func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest("GET", "/debug/adsz?push=true", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got code %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+
+	"istio.io/istio/pilot/pkg/util/sets"
+)
+
+// errUnchangedResponse is returned by the delta streams for the responses which were not sent because
+// no resource changed, so the nonce and version of the last response actually sent are kept.
+var errUnchangedResponse = errors.New("no resource changed")
+
+// DeltaAggregatedResources implements the incremental ADS interface. The delta requests and responses
+// are translated to the state of the world ones, so the incremental clients share the push path of the
+// others, but only receive the resources which changed since the versions they already have.
+func (s *DiscoveryServer) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	return s.StreamAggregatedResources(newDeltaStream(stream))
+}
+
+// deltaStream adapts an incremental ADS stream to the DiscoveryStream interface.
+type deltaStream struct {
+	ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer
+
+	// Mutex to protect the resources, updated by both the receive and the send goroutines.
+	mu sync.Mutex
+
+	// resources holds, for each type URL, the resources of the type known by the client.
+	resources map[string]*deltaResources
+}
+
+// deltaResources tracks the resources of a type the client subscribed to, and their versions.
+type deltaResources struct {
+	// subscribed are the names of the resources the client subscribed to. It is empty for the
+	// wildcard subscriptions Envoy uses for clusters and listeners.
+	subscribed sets.Set
+
+	// sent are the versions of the resources sent to the client, and acked the ones it applied.
+	sent  map[string]string
+	acked map[string]string
+
+	// nonce and version are the nonce and the version of the last response sent.
+	nonce   string
+	version string
+
+	// responded is set once a response of the type was sent, as the first one is always sent to
+	// complete the initial fetch of the client, even if it already has all the resources.
+	responded bool
+}
+
+func newDeltaStream(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) *deltaStream {
+	return &deltaStream{
+		AggregatedDiscoveryService_DeltaAggregatedResourcesServer: stream,
+		resources: map[string]*deltaResources{},
+	}
+}
+
+// resourcesOf returns the resources of the type known by the client. The caller must hold the mutex.
+func (d *deltaStream) resourcesOf(typeURL string) *deltaResources {
+	r := d.resources[typeURL]
+	if r == nil {
+		r = &deltaResources{
+			subscribed: sets.NewSet(),
+			sent:       map[string]string{},
+			acked:      map[string]string{},
+		}
+		d.resources[typeURL] = r
+	}
+	return r
+}
+
+// Recv receives the next delta request, and returns it as a request for all the resources the client
+// is subscribed to.
+func (d *deltaStream) Recv() (*xdsapi.DiscoveryRequest, error) {
+	req, err := d.AggregatedDiscoveryService_DeltaAggregatedResourcesServer.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r := d.resourcesOf(req.TypeUrl)
+
+	// The versions of the resources the client already has after a reconnect, which are not sent again
+	// unless they changed.
+	for name, version := range req.InitialResourceVersions {
+		r.sent[name] = version
+		r.acked[name] = version
+	}
+	r.subscribed.Insert(req.ResourceNamesSubscribe...)
+	for _, name := range req.ResourceNamesUnsubscribe {
+		delete(r.subscribed, name)
+		delete(r.sent, name)
+		delete(r.acked, name)
+	}
+
+	if req.ResponseNonce != "" && req.ResponseNonce == r.nonce {
+		if req.ErrorDetail != nil {
+			// The client rejected the whole response and kept the previous versions of the resources,
+			// which are sent again on the next push.
+			r.sent = copyVersions(r.acked)
+		} else {
+			r.acked = copyVersions(r.sent)
+		}
+	}
+
+	out := &xdsapi.DiscoveryRequest{
+		Node:          req.Node,
+		TypeUrl:       req.TypeUrl,
+		ResourceNames: r.subscribed.UnsortedList(),
+		ErrorDetail:   req.ErrorDetail,
+	}
+	sort.Strings(out.ResourceNames)
+	// A change of the subscription is a new request rather than the ACK of the last response.
+	if len(req.ResourceNamesSubscribe) == 0 && len(req.ResourceNamesUnsubscribe) == 0 {
+		out.ResponseNonce = req.ResponseNonce
+		out.VersionInfo = r.version
+	}
+	return out, nil
+}
+
+// Send sends the resources of the response whose version changed since they were last sent to the
+// client, along with the names of the clusters and listeners which were removed. errUnchangedResponse is
+// returned if no resource changed, and nothing was sent.
+func (d *deltaStream) Send(res *xdsapi.DiscoveryResponse) error {
+	d.mu.Lock()
+	r := d.resourcesOf(res.TypeUrl)
+
+	out := &xdsapi.DeltaDiscoveryResponse{
+		SystemVersionInfo: res.VersionInfo,
+		TypeUrl:           res.TypeUrl,
+		Nonce:             res.Nonce,
+	}
+	names := make(map[string]struct{}, len(res.Resources))
+	for _, resource := range res.Resources {
+		name, err := resourceName(resource)
+		if err != nil {
+			d.mu.Unlock()
+			return err
+		}
+		names[name] = struct{}{}
+		version := resourceVersion(resource)
+		if r.sent[name] == version {
+			deltaUnchangedResources.Increment()
+			continue
+		}
+		r.sent[name] = version
+		out.Resources = append(out.Resources, &xdsapi.Resource{
+			Name:     name,
+			Version:  version,
+			Resource: resource,
+		})
+	}
+
+	// The CDS and LDS responses hold all the clusters and listeners of the proxy, so the ones missing
+	// were removed. The routes and endpoints are only removed when the client unsubscribes from them.
+	if res.TypeUrl == ClusterType || res.TypeUrl == ListenerType {
+		for name := range r.sent {
+			if _, f := names[name]; !f {
+				out.RemovedResources = append(out.RemovedResources, name)
+				delete(r.sent, name)
+			}
+		}
+		sort.Strings(out.RemovedResources)
+	}
+
+	if r.responded && len(out.Resources) == 0 && len(out.RemovedResources) == 0 {
+		d.mu.Unlock()
+		return errUnchangedResponse
+	}
+	r.responded = true
+	r.nonce = res.Nonce
+	r.version = res.VersionInfo
+	d.mu.Unlock()
+
+	return d.AggregatedDiscoveryService_DeltaAggregatedResourcesServer.Send(out)
+}
+
+// resourceName returns the name of a resource of a discovery response.
+func resourceName(resource *any.Any) (string, error) {
+	var err error
+	switch resource.TypeUrl {
+	case ClusterType:
+		c := &xdsapi.Cluster{}
+		err = proto.Unmarshal(resource.Value, c)
+		return c.Name, err
+	case ListenerType:
+		l := &xdsapi.Listener{}
+		err = proto.Unmarshal(resource.Value, l)
+		return l.Name, err
+	case RouteType:
+		r := &xdsapi.RouteConfiguration{}
+		err = proto.Unmarshal(resource.Value, r)
+		return r.Name, err
+	case EndpointType:
+		cla := &xdsapi.ClusterLoadAssignment{}
+		err = proto.Unmarshal(resource.Value, cla)
+		return cla.ClusterName, err
+	}
+	return "", fmt.Errorf("unknown resource type %s", resource.TypeUrl)
+}
+
+// resourceVersion returns the version of a resource, a hash of its content. The resources are
+// marshaled deterministically, so it only changes with them, even across Pilot instances.
+func resourceVersion(resource *any.Any) string {
+	h := fnv.New64a()
+	_, _ = h.Write(resource.Value)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func copyVersions(versions map[string]string) map[string]string {
+	out := make(map[string]string, len(versions))
+	for name, version := range versions {
+		out[name] = version
+	}
+	return out
+}
@@ -0,0 +1,276 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"github.com/golang/protobuf/ptypes/any"
+	"google.golang.org/genproto/googleapis/rpc/status"
+
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+// fakeDeltaStream records the responses sent on an incremental ADS stream, and returns the queued requests.
+type fakeDeltaStream struct {
+	ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer
+	requests  []*xdsapi.DeltaDiscoveryRequest
+	responses []*xdsapi.DeltaDiscoveryResponse
+}
+
+func (f *fakeDeltaStream) Send(res *xdsapi.DeltaDiscoveryResponse) error {
+	f.responses = append(f.responses, res)
+	return nil
+}
+
+func (f *fakeDeltaStream) Recv() (*xdsapi.DeltaDiscoveryRequest, error) {
+	req := f.requests[0]
+	f.requests = f.requests[1:]
+	return req, nil
+}
+
+func clusterResponse(nonce string, clusters ...*xdsapi.Cluster) *xdsapi.DiscoveryResponse {
+	res := &xdsapi.DiscoveryResponse{TypeUrl: ClusterType, VersionInfo: nonce, Nonce: nonce}
+	for _, c := range clusters {
+		res.Resources = append(res.Resources, util.MessageToAny(c))
+	}
+	return res
+}
+
+func sentResources(res *xdsapi.DeltaDiscoveryResponse) []string {
+	names := []string{}
+	for _, r := range res.Resources {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestDeltaStreamClusters(t *testing.T) {
+	fake := &fakeDeltaStream{}
+	d := newDeltaStream(fake)
+
+	a := &xdsapi.Cluster{Name: "a"}
+	b := &xdsapi.Cluster{Name: "b"}
+	c := &xdsapi.Cluster{Name: "c"}
+	updatedA := &xdsapi.Cluster{Name: "a", AltStatName: "updated"}
+
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{Node: &core.Node{Id: "node"}, TypeUrl: ClusterType})
+	req, err := d.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Node.Id != "node" || req.TypeUrl != ClusterType || len(req.ResourceNames) != 0 || req.ResponseNonce != "" {
+		t.Fatalf("unexpected wildcard request %v", req)
+	}
+
+	steps := []struct {
+		name     string
+		ack      *xdsapi.DeltaDiscoveryRequest
+		response *xdsapi.DiscoveryResponse
+		sent     []string
+		removed  []string
+	}{
+		{
+			name:     "initial response",
+			response: clusterResponse("1", a, b),
+			sent:     []string{"a", "b"},
+		},
+		{
+			name:     "unchanged clusters",
+			ack:      &xdsapi.DeltaDiscoveryRequest{TypeUrl: ClusterType, ResponseNonce: "1"},
+			response: clusterResponse("2", a, b),
+		},
+		{
+			name:     "updated, added and removed clusters",
+			response: clusterResponse("3", updatedA, c),
+			sent:     []string{"a", "c"},
+			removed:  []string{"b"},
+		},
+		{
+			name: "rejected response is sent again",
+			ack: &xdsapi.DeltaDiscoveryRequest{TypeUrl: ClusterType, ResponseNonce: "3",
+				ErrorDetail: &status.Status{Message: "rejected"}},
+			response: clusterResponse("4", updatedA, c),
+			sent:     []string{"a", "c"},
+			removed:  []string{"b"},
+		},
+		{
+			name:     "acked response",
+			ack:      &xdsapi.DeltaDiscoveryRequest{TypeUrl: ClusterType, ResponseNonce: "4"},
+			response: clusterResponse("5", updatedA, c),
+		},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if step.ack != nil {
+				fake.requests = append(fake.requests, step.ack)
+				req, err := d.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if req.ResponseNonce != step.ack.ResponseNonce || req.ErrorDetail != step.ack.ErrorDetail {
+					t.Fatalf("unexpected ACK %v", req)
+				}
+			}
+			sent := len(fake.responses)
+			err := d.Send(step.response)
+			if step.sent == nil && step.removed == nil {
+				if err != errUnchangedResponse {
+					t.Fatalf("got error %v, want %v", err, errUnchangedResponse)
+				}
+				if len(fake.responses) != sent {
+					t.Fatalf("unexpected response %v", fake.responses[sent])
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(fake.responses) != sent+1 {
+				t.Fatal("expected a response")
+			}
+			res := fake.responses[sent]
+			if res.Nonce != step.response.Nonce || res.SystemVersionInfo != step.response.VersionInfo {
+				t.Errorf("got nonce %s and version %s, want %s", res.Nonce, res.SystemVersionInfo, step.response.Nonce)
+			}
+			if got := sentResources(res); !reflect.DeepEqual(got, step.sent) {
+				t.Errorf("got resources %v, want %v", got, step.sent)
+			}
+			if !reflect.DeepEqual(res.RemovedResources, step.removed) {
+				t.Errorf("got removed resources %v, want %v", res.RemovedResources, step.removed)
+			}
+		})
+	}
+}
+
+func TestDeltaStreamSubscriptions(t *testing.T) {
+	fake := &fakeDeltaStream{}
+	d := newDeltaStream(fake)
+
+	cla := func(name string) *any.Any {
+		return util.MessageToAny(&xdsapi.ClusterLoadAssignment{ClusterName: name})
+	}
+
+	// The client reconnects with the version of x it received from another Pilot.
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{
+		TypeUrl:                 EndpointType,
+		ResourceNamesSubscribe:  []string{"y", "x"},
+		InitialResourceVersions: map[string]string{"x": resourceVersion(cla("x"))},
+		ResponseNonce:           "stale",
+	})
+	req, err := d.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.ResourceNames, []string{"x", "y"}) || req.ResponseNonce != "" {
+		t.Fatalf("unexpected request %v", req)
+	}
+
+	if err := d.Send(&xdsapi.DiscoveryResponse{TypeUrl: EndpointType, Nonce: "1", Resources: []*any.Any{cla("x"), cla("y")}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sentResources(fake.responses[0]); !reflect.DeepEqual(got, []string{"y"}) {
+		t.Fatalf("got resources %v, want [y]", got)
+	}
+
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{
+		TypeUrl:                  EndpointType,
+		ResourceNamesUnsubscribe: []string{"x"},
+		ResourceNamesSubscribe:   []string{"z"},
+		ResponseNonce:            "1",
+	})
+	req, err = d.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(req.ResourceNames, []string{"y", "z"}) || req.ResponseNonce != "" {
+		t.Fatalf("unexpected request %v", req)
+	}
+
+	// The endpoints are only removed by unsubscribing, and y is not sent again.
+	if err := d.Send(&xdsapi.DiscoveryResponse{TypeUrl: EndpointType, Nonce: "2", Resources: []*any.Any{cla("y"), cla("z")}}); err != nil {
+		t.Fatal(err)
+	}
+	res := fake.responses[1]
+	if got := sentResources(res); !reflect.DeepEqual(got, []string{"z"}) || len(res.RemovedResources) != 0 {
+		t.Fatalf("got resources %v and removed %v, want [z]", got, res.RemovedResources)
+	}
+
+	// x is sent again once the client subscribes to it, as it forgot it when unsubscribing.
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{
+		TypeUrl:                EndpointType,
+		ResourceNamesSubscribe: []string{"x"},
+		ResponseNonce:          "2",
+	})
+	if _, err := d.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Send(&xdsapi.DiscoveryResponse{TypeUrl: EndpointType, Nonce: "3", Resources: []*any.Any{cla("x"), cla("y"), cla("z")}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := sentResources(fake.responses[2]); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Fatalf("got resources %v, want [x]", got)
+	}
+}
+
+func TestDeltaStreamUnchangedResponseAck(t *testing.T) {
+	fake := &fakeDeltaStream{}
+	con := newXdsConnection("", newDeltaStream(fake))
+	route := util.MessageToAny(&xdsapi.RouteConfiguration{Name: "80"})
+
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{TypeUrl: RouteType, ResourceNamesSubscribe: []string{"80"}})
+	if _, err := con.stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := con.send(&xdsapi.DiscoveryResponse{TypeUrl: RouteType, VersionInfo: "v1", Nonce: "1",
+		Resources: []*any.Any{route}}); err != nil {
+		t.Fatal(err)
+	}
+	// The route did not change, so the second push is not sent to the client.
+	if err := con.send(&xdsapi.DiscoveryResponse{TypeUrl: RouteType, VersionInfo: "v2", Nonce: "2",
+		Resources: []*any.Any{route}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.responses) != 1 {
+		t.Fatalf("got %d responses, want 1", len(fake.responses))
+	}
+
+	// The nonce and version are recorded by the sending goroutine, after the send returns.
+	retry.UntilSuccessOrFail(t, func() error {
+		con.mu.RLock()
+		defer con.mu.RUnlock()
+		if con.RouteNonceSent != "1" || con.RouteVersionInfoSent != "v1" {
+			return fmt.Errorf("got nonce %q and version %q sent, want 1 and v1", con.RouteNonceSent, con.RouteVersionInfoSent)
+		}
+		return nil
+	}, retry.Timeout(time.Second))
+
+	// The client ACKs the last response it received, which is not stale.
+	fake.requests = append(fake.requests, &xdsapi.DeltaDiscoveryRequest{TypeUrl: RouteType, ResponseNonce: "1"})
+	req, err := con.stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.ResponseNonce != con.RouteNonceSent || req.VersionInfo != con.RouteVersionInfoSent {
+		t.Fatalf("got ACK of nonce %q and version %q, want 1 and v1", req.ResponseNonce, req.VersionInfo)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	"github.com/google/uuid"
 	"go.uber.org/atomic"
@@ -37,19 +38,6 @@ var (
 	versionNum = atomic.NewUint64(0)
 
 	periodicRefreshMetrics = 10 * time.Second
-
-	// DebounceAfter is the delay added to events to wait
-	// after a registry/config event for debouncing.
-	// This will delay the push by at least this interval, plus
-	// the time getting subsequent events. If no change is
-	// detected the push will happen, otherwise we'll keep
-	// delaying until things settle.
-	DebounceAfter time.Duration
-
-	// DebounceMax is the maximum time to wait for events
-	// while debouncing. Defaults to 10 seconds. If events keep
-	// showing up with no break for this time, we'll trigger a push.
-	DebounceMax time.Duration
 )
 
 const (
@@ -67,11 +55,6 @@ const (
 	RouteType = typePrefix + "RouteConfiguration"
 )
 
-func init() {
-	DebounceAfter = features.DebounceAfter
-	DebounceMax = features.DebounceMax
-}
-
 // DiscoveryServer is Pilot's gRPC implementation for Envoy's v2 xds APIs
 type DiscoveryServer struct {
 	// Env is the model environment.
@@ -84,7 +67,19 @@ type DiscoveryServer struct {
 	// APIs and service registry info
 	ConfigGenerator core.ConfigGenerator
 
-	concurrentPushLimit chan struct{}
+	// concurrentPushLimit limits the number of concurrent pushes to PILOT_PUSH_THROTTLE.
+	concurrentPushLimit *pushSemaphore
+
+	// readinessChecks gate the readiness probe, protected by readinessMutex.
+	readinessChecks []readinessCheck
+	readinessMutex  sync.RWMutex
+
+	// DebugAuthenticator authenticates the callers of the debug endpoints, if PILOT_ENABLE_DEBUG_AUTH is set.
+	// It only accepts client certificates by default; the bootstrap adds the Kubernetes tokens.
+	DebugAuthenticator DebugAuthenticator
+
+	// debugAuthorization is nil if the debug endpoints are served to any caller.
+	debugAuthorization *debugAuthorization
 
 	// DebugConfigs controls saving snapshots of configs for /debug/adsz.
 	// Defaults to false, can be enabled with PILOT_DEBUG_ADSZ_CONFIG=1
@@ -98,6 +93,9 @@ type DiscoveryServer struct {
 	// incremental updates. This is keyed by service and namespace
 	EndpointShardsByService map[string]map[string]*EndpointShards
 
+	// endpointInterner deduplicates the strings and labels of the endpoints stored in the shards.
+	endpointInterner *endpointInterner
+
 	pushChannel chan *model.PushRequest
 
 	// mutex used for config update scheduling (former cache update mutex)
@@ -105,6 +103,18 @@ type DiscoveryServer struct {
 
 	// pushQueue is the buffer that used after debounce and before the real xds push.
 	pushQueue *PushQueue
+
+	// generationWorkers bounds the number of proxies whose config is generated concurrently.
+	generationWorkers *workerPool
+
+	// pushProfiler captures the profiles of the full pushes requested through /debug/pprof/push.
+	pushProfiler *pushProfiler
+
+	// ConfigHistory records the latest config changes, for /debug/configz?history.
+	ConfigHistory *ConfigHistory
+
+	// autoRegistration creates the WorkloadEntries of the connecting workloads, if enabled.
+	autoRegistration *autoRegistration
 }
 
 // EndpointShards holds the set of endpoint shards of a service. Registries update
@@ -125,6 +135,11 @@ type EndpointShards struct {
 	// Due to the larger time, it is still possible that connection errors will occur while
 	// CDS is updated.
 	ServiceAccounts map[string]bool
+
+	// localityEndpoints caches the endpoints of each service port, grouped by locality, and
+	// subsetEndpoints the views of the subsets derived from them. See subsetLocalityLbEndpoints.
+	localityEndpoints map[localityEndpointsKey][]*localityEndpoints
+	subsetEndpoints   map[subsetEndpointsKey][]*endpoint.LocalityLbEndpoints
 }
 
 // NewDiscoveryServer creates DiscoveryServer that sources data from Pilot's internal mesh data structures
@@ -133,10 +148,23 @@ func NewDiscoveryServer(env *model.Environment, generator core.ConfigGenerator)
 		Env:                     env,
 		ConfigGenerator:         generator,
 		EndpointShardsByService: map[string]map[string]*EndpointShards{},
-		concurrentPushLimit:     make(chan struct{}, features.PushThrottle),
+		endpointInterner:        newEndpointInterner(),
+		concurrentPushLimit:     newPushSemaphore(features.PushThrottle.Get),
 		pushChannel:             make(chan *model.PushRequest, 10),
 		pushQueue:               NewPushQueue(),
+		generationWorkers:       newWorkerPool(features.PushGenerationWorkers),
+		pushProfiler:            &pushProfiler{},
 		DebugConfigs:            features.DebugConfigs,
+		ConfigHistory:           NewConfigHistory(features.ConfigHistorySize),
+		DebugAuthenticator:      NewDebugAuthenticator(nil),
+	}
+
+	if features.EnableDebugAuth {
+		out.debugAuthorization = newDebugAuthorization(features.DebugReaders, features.DebugAdmins)
+	}
+
+	if features.WorkloadEntryAutoRegistration {
+		out.autoRegistration = newAutoRegistration(env.IstioConfigStore, features.WorkloadEntryCleanupGracePeriod)
 	}
 
 	// Flush cached discovery responses when detecting jwt public key change.
@@ -191,6 +219,11 @@ func (s *DiscoveryServer) Push(req *model.PushRequest) {
 		go s.AdsPushAll(versionInfo(), req)
 		return
 	}
+	s.pushProfiler.pushStarted()
+
+	// Release the interned values of the endpoints deleted since the previous full push.
+	s.endpointInterner.rotate()
+
 	// Reset the status during the push.
 	oldPushContext := s.globalPushContext()
 	if oldPushContext != nil {
@@ -245,6 +278,11 @@ func (s *DiscoveryServer) globalPushContext() *model.PushContext {
 	return s.Env.PushContext
 }
 
+// ConfigErrors returns the errors of the translation of the config resources recorded by the latest push.
+func (s *DiscoveryServer) ConfigErrors() []model.ConfigError {
+	return s.globalPushContext().ConfigErrors()
+}
+
 // ClearCache is wrapper for clearCache method, used when new controller gets
 // instantiated dynamically
 func (s *DiscoveryServer) ClearCache() {
@@ -287,11 +325,14 @@ func debounce(ch chan *model.PushRequest, stopCh <-chan struct{}, pushFn func(re
 		freeCh <- struct{}{}
 	}
 
+	// The debounce delays are read at each change, so that they can be updated at runtime.
+	var debounceAfter time.Duration
+
 	pushWorker := func() {
 		eventDelay := time.Since(startDebounce)
 		quietTime := time.Since(lastConfigUpdateTime)
 		// it has been too long or quiet enough
-		if eventDelay >= DebounceMax || quietTime >= DebounceAfter {
+		if eventDelay >= features.DebounceMax.Get() || quietTime >= debounceAfter {
 			if req != nil {
 				pushCounter++
 				adsLog.Infof("Push debounce stable[%d] %d: %v since last change, %v since last push, full=%v",
@@ -304,7 +345,7 @@ func debounce(ch chan *model.PushRequest, stopCh <-chan struct{}, pushFn func(re
 				debouncedEvents = 0
 			}
 		} else {
-			timeChan = time.After(DebounceAfter - quietTime)
+			timeChan = time.After(debounceAfter - quietTime)
 		}
 	}
 
@@ -321,8 +362,9 @@ func debounce(ch chan *model.PushRequest, stopCh <-chan struct{}, pushFn func(re
 			}
 
 			lastConfigUpdateTime = time.Now()
+			debounceAfter = features.DebounceAfter.Get()
 			if debouncedEvents == 0 {
-				timeChan = time.After(DebounceAfter)
+				timeChan = time.After(debounceAfter)
 				startDebounce = lastConfigUpdateTime
 			}
 			debouncedEvents++
@@ -338,23 +380,56 @@ func debounce(ch chan *model.PushRequest, stopCh <-chan struct{}, pushFn func(re
 	}
 }
 
-func doSendPushes(stopCh <-chan struct{}, semaphore chan struct{}, queue *PushQueue) {
+// pushSemaphore limits the number of concurrent pushes. Unlike a buffered channel, its limit
+// can change at runtime: a lower limit blocks new pushes until enough in flight pushes are done.
+type pushSemaphore struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	limit    func() int
+	inFlight int
+}
+
+func newPushSemaphore(limit func() int) *pushSemaphore {
+	s := &pushSemaphore{limit: limit}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+// acquire blocks until the number of pushes in flight is below the limit.
+func (s *pushSemaphore) acquire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for s.inFlight >= s.limit() {
+		s.cond.Wait()
+	}
+	s.inFlight++
+}
+
+// release signals that a push is done.
+func (s *pushSemaphore) release() {
+	s.mutex.Lock()
+	s.inFlight--
+	s.mutex.Unlock()
+	s.cond.Broadcast()
+}
+
+func doSendPushes(stopCh <-chan struct{}, semaphore *pushSemaphore, queue *PushQueue) {
 	for {
 		select {
 		case <-stopCh:
 			return
 		default:
-			// We can send to it until it is full, then it will block until a pushes finishes and reads from it.
+			// We can acquire it until the limit is reached, then it will block until a push finishes and releases it.
 			// This limits the number of pushes that can happen concurrently
-			semaphore <- struct{}{}
+			semaphore.acquire()
 
 			// Get the next proxy to push. This will block if there are no updates required.
 			client, info := queue.Dequeue()
 
-			// Signals that a push is done by reading from the semaphore, allowing another send on it.
+			// Signals that a push is done by releasing the semaphore, allowing another push.
 			doneFunc := func() {
 				queue.MarkDone(client)
-				<-semaphore
+				semaphore.release()
 			}
 
 			proxiesQueueTime.Record(time.Since(info.Start).Seconds())
@@ -61,7 +61,7 @@ func wgDoneOrTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
 
 func TestSendPushesManyPushes(t *testing.T) {
 	stopCh := make(chan struct{})
-	semaphore := make(chan struct{}, 2)
+	semaphore := newPushSemaphore(func() int { return 2 })
 	queue := NewPushQueue()
 
 	proxies := createProxies(5)
@@ -102,9 +102,36 @@ func TestSendPushesManyPushes(t *testing.T) {
 	}
 }
 
+func TestPushSemaphoreResize(t *testing.T) {
+	limit := int32(1)
+	semaphore := newPushSemaphore(func() int { return int(atomic.LoadInt32(&limit)) })
+	semaphore.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		semaphore.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired above the limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// A higher limit applies to the next acquire, and to the waiting ones once a push is done
+	atomic.StoreInt32(&limit, 3)
+	semaphore.acquire()
+	semaphore.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("not acquired below the limit")
+	}
+}
+
 func TestSendPushesSinglePush(t *testing.T) {
 	stopCh := make(chan struct{})
-	semaphore := make(chan struct{}, 2)
+	semaphore := newPushSemaphore(func() int { return 2 })
 	queue := NewPushQueue()
 
 	proxies := createProxies(5)
@@ -168,18 +195,27 @@ func (h *fakeStream) Context() context.Context {
 
 func TestDebounce(t *testing.T) {
 	// This test tests the timeout and debouncing of config updates
-	// If it is flaking, DebounceAfter may need to be increased, or the code refactored to mock time.
+	// If it is flaking, debounceAfter may need to be increased, or the code refactored to mock time.
 	// For now, this seems to work well
-	DebounceAfter = time.Millisecond * 50
-	DebounceMax = DebounceAfter * 2
-	syncPushTime := 2 * DebounceMax
-	if err := os.Setenv(features.EnableEDSDebounce.Name, "false"); err != nil {
-		t.Fatal(err)
+	debounceAfter := time.Millisecond * 50
+	debounceMax := debounceAfter * 2
+	syncPushTime := 2 * debounceMax
+	env := map[string]string{
+		features.DebounceAfter.Name:     debounceAfter.String(),
+		features.DebounceMax.Name:       debounceMax.String(),
+		features.EnableEDSDebounce.Name: "false",
 	}
-	defer func() {
-		if err := os.Unsetenv(features.EnableEDSDebounce.Name); err != nil {
+	for name, value := range env {
+		if err := os.Setenv(name, value); err != nil {
 			t.Fatal(err)
 		}
+	}
+	defer func() {
+		for name := range env {
+			if err := os.Unsetenv(name); err != nil {
+				t.Fatal(err)
+			}
+		}
 	}()
 
 	tests := []struct {
@@ -233,13 +269,13 @@ func TestDebounce(t *testing.T) {
 			test: func(updateCh chan *model.PushRequest, expect func(partial, full int32)) {
 				// Send many requests within debounce window
 				updateCh <- &model.PushRequest{Full: true}
-				time.Sleep(DebounceAfter / 2)
+				time.Sleep(debounceAfter / 2)
 				updateCh <- &model.PushRequest{Full: true}
-				time.Sleep(DebounceAfter / 2)
+				time.Sleep(debounceAfter / 2)
 				updateCh <- &model.PushRequest{Full: true}
-				time.Sleep(DebounceAfter / 2)
+				time.Sleep(debounceAfter / 2)
 				updateCh <- &model.PushRequest{Full: true}
-				time.Sleep(DebounceAfter / 2)
+				time.Sleep(debounceAfter / 2)
 				expect(0, 1)
 			},
 		},
@@ -247,7 +283,7 @@ func TestDebounce(t *testing.T) {
 			name: "Should push synchronously after debounce",
 			test: func(updateCh chan *model.PushRequest, expect func(partial, full int32)) {
 				updateCh <- &model.PushRequest{Full: true}
-				time.Sleep(DebounceAfter + 10*time.Millisecond)
+				time.Sleep(debounceAfter + 10*time.Millisecond)
 				updateCh <- &model.PushRequest{Full: true}
 				expect(0, 2)
 			},
@@ -303,7 +339,7 @@ func TestDebounce(t *testing.T) {
 						}
 						return nil
 					}
-				}, retry.Timeout(DebounceAfter*8), retry.Delay(DebounceAfter/2))
+				}, retry.Timeout(debounceAfter*8), retry.Delay(debounceAfter/2))
 				if err != nil {
 					t.Error(err)
 				}
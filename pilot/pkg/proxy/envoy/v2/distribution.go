@@ -0,0 +1,76 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+// ConfigDistribution returns the number of proxies connected to this server and, for each of the given config
+// resource keys, the number of these proxies having acked each resource version. A proxy has acked a version of
+// a resource when the clusters, listeners and routes it acked last were all generated from that version. The
+// versions are looked up in the config ledger, from the nonces of the acked responses: a proxy whose acked
+// config is older than the retention of the ledger is not counted for any version.
+func (s *DiscoveryServer) ConfigDistribution(keys []string) (int, map[string]map[string]int) {
+	var ackedNonces [][]string
+	adsClientsMutex.RLock()
+	for _, con := range adsClients {
+		con.mu.RLock()
+		if con.node != nil {
+			ackedNonces = append(ackedNonces, []string{con.ClusterNonceAcked, con.ListenerNonceAcked, con.RouteNonceAcked})
+		}
+		con.mu.RUnlock()
+	}
+	adsClientsMutex.RUnlock()
+
+	acked := make(map[string]map[string]int, len(keys))
+	for _, key := range keys {
+		knownVersions := make(map[string]string)
+		versions := make(map[string]int)
+		for _, nonces := range ackedNonces {
+			if version := s.ackedResourceVersion(nonces, key, knownVersions); version != "" {
+				versions[version]++
+			}
+		}
+		acked[key] = versions
+	}
+	return len(ackedNonces), acked
+}
+
+// ackedResourceVersion returns the version of the resource the acked nonces of a proxy were all generated from,
+// or an empty string if they were generated from different versions, or if a version is not known.
+func (s *DiscoveryServer) ackedResourceVersion(nonces []string, key string, cache map[string]string) string {
+	result := ""
+	for _, nonce := range nonces {
+		if nonce == "" {
+			continue
+		}
+		if len(nonce) < VersionLen {
+			return ""
+		}
+		configVersion := nonce[:VersionLen]
+		version, ok := cache[configVersion]
+		if !ok {
+			var err error
+			version, err = s.Env.IstioConfigStore.GetResourceAtVersion(configVersion, key)
+			if err != nil {
+				adsLog.Debugf("Unable to retrieve resource %s at version %s: %v", key, configVersion, err)
+				version = ""
+			}
+			cache[configVersion] = version
+		}
+		if version == "" || (result != "" && version != result) {
+			return ""
+		}
+		result = version
+	}
+	return result
+}
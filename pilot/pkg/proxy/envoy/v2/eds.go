@@ -98,7 +98,7 @@ func loadAssignment(c *EdsCluster) *xdsapi.ClusterLoadAssignment {
 
 // buildEnvoyLbEndpoint packs the endpoint based on istio info.
 func buildEnvoyLbEndpoint(uid string, family model.AddressFamily, address string, port uint32,
-	network string, weight uint32, tlsMode string) *endpoint.LbEndpoint {
+	network string, clusterID string, weight uint32, tlsMode string) *endpoint.LbEndpoint {
 
 	var addr core.Address
 	switch family {
@@ -135,7 +135,7 @@ func buildEnvoyLbEndpoint(uid string, family model.AddressFamily, address string
 	// Istio telemetry depends on the metadata value being set for endpoints in the mesh.
 	// Istio endpoint level tls transport socket configuation depends on this logic
 	// Do not remove
-	ep.Metadata = util.BuildLbEndpointMetadata(uid, network, tlsMode)
+	ep.Metadata = util.BuildLbEndpointMetadata(uid, network, clusterID, tlsMode)
 
 	return ep
 }
@@ -166,7 +166,7 @@ func networkEndpointToEnvoyEndpoint(e *model.NetworkEndpoint, tlsMode string) (*
 	// Istio telemetry depends on the metadata value being set for endpoints in the mesh.
 	// Istio endpoint level tls transport socket configuation depends on this logic
 	// Do not remove
-	ep.Metadata = util.BuildLbEndpointMetadata(e.UID, e.Network, tlsMode)
+	ep.Metadata = util.BuildLbEndpointMetadata(e.UID, e.Network, "", tlsMode)
 
 	return ep, nil
 }
@@ -224,7 +224,7 @@ func (s *DiscoveryServer) updateClusterInc(push *model.PushContext, clusterName
 		return s.updateCluster(push, clusterName, edsCluster)
 	}
 
-	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, push)
+	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, "", push)
 	// There is a chance multiple goroutines will update the cluster at the same time.
 	// This could be prevented by a lock - but because the update may be slow, it may be
 	// better to accept the extra computations.
@@ -499,8 +499,15 @@ func (s *DiscoveryServer) edsUpdate(clusterID, serviceName string, namespace str
 		}
 	}
 
+	// Tag the endpoints with the cluster they come from, to tell in the debug output which
+	// cluster serves the traffic.
+	for _, e := range istioEndpoints {
+		e.ClusterID = clusterID
+	}
+	s.endpointInterner.internEndpoints(istioEndpoints)
 	ep.mutex.Lock()
 	ep.Shards[clusterID] = istioEndpoints
+	ep.clearCache()
 	ep.mutex.Unlock()
 
 	// for internal update: this called by DiscoveryServer.Push --> updateServiceShards,
@@ -526,6 +533,7 @@ func (s *DiscoveryServer) deleteEndpointShards(cluster, serviceName, namespace s
 	if s.EndpointShardsByService[serviceName][namespace] != nil {
 		s.EndpointShardsByService[serviceName][namespace].mutex.Lock()
 		delete(s.EndpointShardsByService[serviceName][namespace].Shards, cluster)
+		s.EndpointShardsByService[serviceName][namespace].clearCache()
 		s.EndpointShardsByService[serviceName][namespace].mutex.Unlock()
 	}
 }
@@ -536,6 +544,7 @@ func (s *DiscoveryServer) deleteService(cluster, serviceName, namespace string)
 	if s.EndpointShardsByService[serviceName][namespace] != nil {
 		s.EndpointShardsByService[serviceName][namespace].mutex.Lock()
 		delete(s.EndpointShardsByService[serviceName][namespace].Shards, cluster)
+		s.EndpointShardsByService[serviceName][namespace].clearCache()
 		svcShards := len(s.EndpointShardsByService[serviceName][namespace].Shards)
 		s.EndpointShardsByService[serviceName][namespace].mutex.Unlock()
 		if svcShards == 0 {
@@ -660,7 +669,13 @@ func (s *DiscoveryServer) loadAssignmentsForClusterIsolated(proxy *model.Proxy,
 		return s.loadAssignmentsForClusterLegacy(push, clusterName)
 	}
 
-	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, push)
+	// Cluster-local services only use the endpoints in the cluster of the proxy.
+	var clusterID string
+	if isClusterLocal(push.Env, hostname) {
+		clusterID = proxyClusterID(proxy)
+	}
+
+	locEps := buildLocalityLbEndpointsFromShards(se, svcPort, subsetLabels, clusterName, clusterID, push)
 
 	return &xdsapi.ClusterLoadAssignment{
 		ClusterName: clusterName,
@@ -672,9 +687,47 @@ func (s *DiscoveryServer) loadAssignmentsForClusterIsolated(proxy *model.Proxy,
 // a client connects, for incremental updates and for full periodic updates.
 func (s *DiscoveryServer) pushEds(push *model.PushContext, con *XdsConnection, version string, edsUpdatedServices map[string]struct{}) error {
 	pushStart := time.Now()
-	loadAssignments := make([]*xdsapi.ClusterLoadAssignment, 0)
+	var loadAssignments []*xdsapi.ClusterLoadAssignment
+	s.generationWorkers.run(func() {
+		loadAssignments = s.generateEndpoints(push, con, edsUpdatedServices)
+	})
 	endpoints := 0
 	empty := make([]string, 0)
+	for _, l := range loadAssignments {
+		for _, e := range l.Endpoints {
+			endpoints += len(e.LbEndpoints)
+		}
+
+		if len(l.Endpoints) == 0 {
+			empty = append(empty, l.ClusterName)
+		}
+	}
+
+	response := endpointDiscoveryResponse(loadAssignments, version, push.Version)
+	err := con.send(response)
+	edsPushTime.Record(time.Since(pushStart).Seconds())
+	if err != nil {
+		adsLog.Warnf("EDS: Send failure %s: %v", con.ConID, err)
+		recordSendError(edsSendErrPushes, err)
+		return err
+	}
+	edsPushes.Increment()
+
+	if edsUpdatedServices == nil {
+		adsLog.Infof("EDS: PUSH for node:%s clusters:%d endpoints:%d empty:%v",
+			con.node.ID, len(con.Clusters), endpoints, empty)
+	} else {
+		adsLog.Infof("EDS: PUSH INC for node:%s clusters:%d endpoints:%d empty:%v",
+			con.node.ID, len(con.Clusters), endpoints, empty)
+	}
+	return nil
+}
+
+// generateEndpoints returns the load assignments of the clusters watched by the connection. If
+// edsUpdatedServices is not nil, only the clusters of the updated services are returned.
+func (s *DiscoveryServer) generateEndpoints(push *model.PushContext, con *XdsConnection,
+	edsUpdatedServices map[string]struct{}) []*xdsapi.ClusterLoadAssignment {
+	loadAssignments := make([]*xdsapi.ClusterLoadAssignment, 0)
 
 	// All clusters that this endpoint is watching. For 1.0 - it's typically all clusters in the mesh.
 	// For 1.1+Sidecar - it's the small set of explicitly imported clusters, using the isolated DestinationRules
@@ -720,37 +773,13 @@ func (s *DiscoveryServer) pushEds(push *model.PushContext, con *XdsConnection, v
 			if loadBalancerSettings != nil && loadBalancerSettings.LocalityLbSetting != nil {
 				localityLbSettings = loadBalancerSettings.LocalityLbSetting
 			}
-			loadbalancer.ApplyLocalityLBSetting(con.node.Locality, l, localityLbSettings, enableFailover)
-		}
-
-		for _, e := range l.Endpoints {
-			endpoints += len(e.LbEndpoints)
+			loadbalancer.ApplyLocalityLBSetting(con.node.Locality, con.node.Metadata.Network, proxyClusterID(con.node),
+				l, localityLbSettings, enableFailover)
 		}
 
-		if len(l.Endpoints) == 0 {
-			empty = append(empty, clusterName)
-		}
 		loadAssignments = append(loadAssignments, l)
 	}
-
-	response := endpointDiscoveryResponse(loadAssignments, version, push.Version)
-	err := con.send(response)
-	edsPushTime.Record(time.Since(pushStart).Seconds())
-	if err != nil {
-		adsLog.Warnf("EDS: Send failure %s: %v", con.ConID, err)
-		recordSendError(edsSendErrPushes, err)
-		return err
-	}
-	edsPushes.Increment()
-
-	if edsUpdatedServices == nil {
-		adsLog.Infof("EDS: PUSH for node:%s clusters:%d endpoints:%d empty:%v",
-			con.node.ID, len(con.Clusters), endpoints, empty)
-	} else {
-		adsLog.Infof("EDS: PUSH INC for node:%s clusters:%d endpoints:%d empty:%v",
-			con.node.ID, len(con.Clusters), endpoints, empty)
-	}
-	return nil
+	return loadAssignments
 }
 
 // getDestinationRule gets the DestinationRule for a given hostname. As an optimization, this also gets the service port,
@@ -886,57 +915,19 @@ func endpointDiscoveryResponse(loadAssignments []*xdsapi.ClusterLoadAssignment,
 	return out
 }
 
-// build LocalityLbEndpoints for a cluster from existing EndpointShards.
+// build LocalityLbEndpoints for a cluster from existing EndpointShards. If clusterID is set, only the
+// endpoints of that cluster are used.
 func buildLocalityLbEndpointsFromShards(
 	shards *EndpointShards,
 	svcPort *model.Port,
 	epLabels labels.Collection,
 	clusterName string,
+	clusterID string,
 	push *model.PushContext) []*endpoint.LocalityLbEndpoints {
-	localityEpMap := make(map[string]*endpoint.LocalityLbEndpoints)
-
 	shards.mutex.Lock()
-	// The shards are updated independently, now need to filter and merge
-	// for this cluster
-	for _, endpoints := range shards.Shards {
-		for _, ep := range endpoints {
-			if svcPort.Name != ep.ServicePortName {
-				continue
-			}
-			// Port labels
-			if !epLabels.HasSubsetOf(ep.Labels) {
-				continue
-			}
-
-			locLbEps, found := localityEpMap[ep.Locality]
-			if !found {
-				locLbEps = &endpoint.LocalityLbEndpoints{
-					Locality:    util.ConvertLocality(ep.Locality),
-					LbEndpoints: make([]*endpoint.LbEndpoint, 0, len(endpoints)),
-				}
-				localityEpMap[ep.Locality] = locLbEps
-			}
-			if ep.EnvoyEndpoint == nil {
-				ep.EnvoyEndpoint = buildEnvoyLbEndpoint(ep.UID, ep.Family, ep.Address, ep.EndpointPort, ep.Network, ep.LbWeight, ep.TLSMode)
-			}
-			locLbEps.LbEndpoints = append(locLbEps.LbEndpoints, ep.EnvoyEndpoint)
-
-		}
-	}
+	locEps := shards.subsetLocalityLbEndpoints(svcPort.Name, clusterID, epLabels)
 	shards.mutex.Unlock()
 
-	locEps := make([]*endpoint.LocalityLbEndpoints, 0, len(localityEpMap))
-	for _, locLbEps := range localityEpMap {
-		var weight uint32
-		for _, ep := range locLbEps.LbEndpoints {
-			weight += ep.LoadBalancingWeight.GetValue()
-		}
-		locLbEps.LoadBalancingWeight = &wrappers.UInt32Value{
-			Value: weight,
-		}
-		locEps = append(locEps, locLbEps)
-	}
-
 	if len(locEps) == 0 {
 		push.Add(model.ProxyStatusClusterNoInstances, clusterName, nil, "")
 	}
@@ -0,0 +1,164 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"strconv"
+	"strings"
+
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// localityEndpointsKey identifies the endpoints of a service port in the shards, of all the clusters
+// if clusterID is empty.
+type localityEndpointsKey struct {
+	portName  string
+	clusterID string
+}
+
+// subsetEndpointsKey identifies the endpoints of a service port selected by the labels of a subset.
+type subsetEndpointsKey struct {
+	localityEndpointsKey
+	labels string
+}
+
+// localityEndpoints are the endpoints of a service port in a locality.
+type localityEndpoints struct {
+	locality    string
+	endpoints   []*model.IstioEndpoint
+	lbEndpoints []*endpoint.LbEndpoint
+}
+
+// clearCache drops the endpoints built from the shards. It must be called, with the mutex held, when the
+// shards are updated.
+func (e *EndpointShards) clearCache() {
+	e.localityEndpoints = nil
+	e.subsetEndpoints = nil
+}
+
+// subsetLocalityLbEndpoints returns the endpoints of the service port selected by the subset labels,
+// grouped by locality. The endpoints are derived from the endpoints of the service port, which are
+// built once for all the subsets, and are cached until the shards are updated: they are shared by
+// all the clusters of the subset and must not be modified. The mutex must be held.
+func (e *EndpointShards) subsetLocalityLbEndpoints(portName, clusterID string,
+	epLabels labels.Collection) []*endpoint.LocalityLbEndpoints {
+	key := subsetEndpointsKey{
+		localityEndpointsKey: localityEndpointsKey{portName: portName, clusterID: clusterID},
+		labels:               collectionKey(epLabels),
+	}
+	if locEps, f := e.subsetEndpoints[key]; f {
+		return locEps
+	}
+
+	portEndpoints := e.portLocalityEndpoints(key.localityEndpointsKey)
+	locEps := make([]*endpoint.LocalityLbEndpoints, 0, len(portEndpoints))
+	for _, l := range portEndpoints {
+		lbEndpoints := l.lbEndpoints
+		if len(epLabels) > 0 {
+			lbEndpoints = make([]*endpoint.LbEndpoint, 0, len(l.endpoints))
+			for i, ep := range l.endpoints {
+				// Port labels
+				if epLabels.HasSubsetOf(ep.Labels) {
+					lbEndpoints = append(lbEndpoints, l.lbEndpoints[i])
+				}
+			}
+			if len(lbEndpoints) == 0 {
+				continue
+			}
+		}
+
+		var weight uint32
+		for _, ep := range lbEndpoints {
+			weight += ep.LoadBalancingWeight.GetValue()
+		}
+		locEps = append(locEps, &endpoint.LocalityLbEndpoints{
+			Locality:    util.ConvertLocality(l.locality),
+			LbEndpoints: lbEndpoints,
+			LoadBalancingWeight: &wrappers.UInt32Value{
+				Value: weight,
+			},
+		})
+	}
+
+	if e.subsetEndpoints == nil {
+		e.subsetEndpoints = make(map[subsetEndpointsKey][]*endpoint.LocalityLbEndpoints)
+	}
+	e.subsetEndpoints[key] = locEps
+	return locEps
+}
+
+// portLocalityEndpoints returns the endpoints of the service port grouped by locality, from the cache
+// if they were already built. The mutex must be held.
+func (e *EndpointShards) portLocalityEndpoints(key localityEndpointsKey) []*localityEndpoints {
+	if portEndpoints, f := e.localityEndpoints[key]; f {
+		return portEndpoints
+	}
+
+	portEndpoints := make([]*localityEndpoints, 0)
+	byLocality := make(map[string]*localityEndpoints)
+	// The shards are updated independently, now need to filter and merge
+	// for this cluster
+	for shardCluster, endpoints := range e.Shards {
+		if key.clusterID != "" && shardCluster != key.clusterID {
+			continue
+		}
+		for _, ep := range endpoints {
+			if key.portName != ep.ServicePortName {
+				continue
+			}
+
+			l, found := byLocality[ep.Locality]
+			if !found {
+				l = &localityEndpoints{locality: ep.Locality}
+				byLocality[ep.Locality] = l
+				portEndpoints = append(portEndpoints, l)
+			}
+			if ep.EnvoyEndpoint == nil {
+				ep.EnvoyEndpoint = buildEnvoyLbEndpoint(ep.UID, ep.Family, ep.Address, ep.EndpointPort, ep.Network, shardCluster, ep.LbWeight, ep.TLSMode)
+			}
+			l.endpoints = append(l.endpoints, ep)
+			l.lbEndpoints = append(l.lbEndpoints, ep.EnvoyEndpoint)
+		}
+	}
+	// The endpoints are shared by the views of the subsets without labels: appending to them must
+	// not modify the cache.
+	for _, l := range portEndpoints {
+		l.lbEndpoints = l.lbEndpoints[:len(l.lbEndpoints):len(l.lbEndpoints)]
+	}
+
+	if e.localityEndpoints == nil {
+		e.localityEndpoints = make(map[localityEndpointsKey][]*localityEndpoints)
+	}
+	e.localityEndpoints[key] = portEndpoints
+	return portEndpoints
+}
+
+// collectionKey returns the labels of the collection as a string, different for labels selecting
+// different endpoints.
+func collectionKey(c labels.Collection) string {
+	var b strings.Builder
+	for _, l := range c {
+		k := labelsKey(l)
+		b.WriteString(strconv.Itoa(len(k)))
+		b.WriteByte(':')
+		b.WriteString(k)
+	}
+	return b.String()
+}
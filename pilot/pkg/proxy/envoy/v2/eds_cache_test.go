@@ -0,0 +1,94 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/labels"
+)
+
+func TestBuildLocalityLbEndpointsFromShardsCache(t *testing.T) {
+	s := NewDiscoveryServer(&model.Environment{}, nil)
+	newEndpoint := func(address, locality, version string) *model.IstioEndpoint {
+		return &model.IstioEndpoint{
+			Address:         address,
+			ServicePortName: "http",
+			EndpointPort:    80,
+			Locality:        locality,
+			Labels:          map[string]string{"version": version},
+		}
+	}
+	s.edsUpdate("cluster-1", "cache.com", "", []*model.IstioEndpoint{
+		newEndpoint("10.0.0.1", "region1/zone1", "v1"),
+		newEndpoint("10.0.0.2", "region1/zone1", "v2"),
+		newEndpoint("10.0.0.3", "region1/zone2", "v1"),
+	}, true)
+	shards := s.EndpointShardsByService["cache.com"][""]
+	port := &model.Port{Name: "http", Port: 80}
+	build := func(version string) []*endpoint.LocalityLbEndpoints {
+		var subset labels.Collection
+		if version != "" {
+			subset = labels.Collection{{"version": version}}
+		}
+		return buildLocalityLbEndpointsFromShards(shards, port, subset, "outbound|80|"+version+"|cache.com", "", model.NewPushContext())
+	}
+	addresses := func(locEps []*endpoint.LocalityLbEndpoints) map[string][]string {
+		got := map[string][]string{}
+		for _, locEp := range locEps {
+			locality := locEp.Locality.Region + "/" + locEp.Locality.Zone
+			for _, lbEp := range locEp.LbEndpoints {
+				got[locality] = append(got[locality], lbEp.GetEndpoint().Address.GetSocketAddress().Address)
+			}
+			if int(locEp.LoadBalancingWeight.GetValue()) != len(locEp.LbEndpoints) {
+				t.Errorf("got weight %d for %d endpoints in %s", locEp.LoadBalancingWeight.GetValue(), len(locEp.LbEndpoints), locality)
+			}
+		}
+		return got
+	}
+
+	all, v1, v2 := build(""), build("v1"), build("v2")
+	for _, c := range []struct {
+		got  []*endpoint.LocalityLbEndpoints
+		want map[string][]string
+	}{
+		{all, map[string][]string{"region1/zone1": {"10.0.0.1", "10.0.0.2"}, "region1/zone2": {"10.0.0.3"}}},
+		{v1, map[string][]string{"region1/zone1": {"10.0.0.1"}, "region1/zone2": {"10.0.0.3"}}},
+		{v2, map[string][]string{"region1/zone1": {"10.0.0.2"}}},
+	} {
+		if got := addresses(c.got); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("got endpoints %v, want %v", got, c.want)
+		}
+	}
+
+	// The endpoints of the subsets are derived from the endpoints of the port, and cached.
+	if v1[0].LbEndpoints[0] != all[0].LbEndpoints[0] && v1[0].LbEndpoints[0] != all[1].LbEndpoints[0] {
+		t.Errorf("endpoints of the subset not shared with the endpoints of the port")
+	}
+	if again := build("v1"); again[0] != v1[0] {
+		t.Errorf("endpoints of the subset not cached")
+	}
+
+	// Updating the shards drops the cache.
+	s.edsUpdate("cluster-1", "cache.com", "", []*model.IstioEndpoint{newEndpoint("10.0.0.4", "region1/zone1", "v1")}, true)
+	want := map[string][]string{"region1/zone1": {"10.0.0.4"}}
+	if got := addresses(build("v1")); !reflect.DeepEqual(got, want) {
+		t.Errorf("got endpoints %v after update, want %v", got, want)
+	}
+}
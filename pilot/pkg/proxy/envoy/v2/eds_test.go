@@ -266,6 +266,29 @@ func TestDeleteService(t *testing.T) {
 	}
 }
 
+func TestEDSUpdateTagsClusterID(t *testing.T) {
+	server, tearDown := initLocalPilotTestEnv(t)
+	defer tearDown()
+
+	_ = server.EnvoyXdsServer.EDSUpdate("cluster-2", "clusterid.com", "",
+		[]*model.IstioEndpoint{
+			{
+				Address:         "10.10.1.1",
+				ServicePortName: "http",
+				EndpointPort:    80,
+				Network:         "network-2",
+			},
+		})
+
+	shards := server.EnvoyXdsServer.EndpointShardsByService["clusterid.com"][""]
+	if shards == nil || len(shards.Shards["cluster-2"]) != 1 {
+		t.Fatalf("expected the endpoint in the shard of cluster-2, got %v", shards)
+	}
+	if got := shards.Shards["cluster-2"][0].ClusterID; got != "cluster-2" {
+		t.Errorf("got cluster %q, expected the endpoint to be tagged with cluster-2", got)
+	}
+}
+
 func adsConnectAndWait(t *testing.T, ip int) *adsc.ADSC {
 	adscConn, err := adsc.Dial(util.MockPilotGrpcAddr, "", &adsc.Config{
 		IP: testIP(uint32(ip)),
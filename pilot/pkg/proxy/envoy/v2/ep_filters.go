@@ -16,7 +16,9 @@ package v2
 
 import (
 	"net"
+	"strconv"
 
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/api/v2/endpoint"
 	"github.com/golang/protobuf/ptypes/wrappers"
 
@@ -68,11 +70,13 @@ func EndpointsByNetworkFilter(endpoints []*endpoint.LocalityLbEndpoints, conn *X
 		for _, lbEp := range ep.LbEndpoints {
 			epNetwork := istioMetadata(lbEp, "network")
 			if epNetwork == network {
-				// This is a local endpoint
-				lbEp.LoadBalancingWeight = &wrappers.UInt32Value{
+				// This is a local endpoint. The endpoints are shared by all the proxies, so the
+				// weight is set on a copy.
+				localEp := *lbEp
+				localEp.LoadBalancingWeight = &wrappers.UInt32Value{
 					Value: uint32(multiples),
 				}
-				lbEndpoints = append(lbEndpoints, lbEp)
+				lbEndpoints = append(lbEndpoints, &localEp)
 			} else {
 				// Remote endpoint. Increase the weight counter
 				remoteEps[epNetwork]++
@@ -83,7 +87,9 @@ func EndpointsByNetworkFilter(endpoints []*endpoint.LocalityLbEndpoints, conn *X
 
 		// Iterate over all networks that have the cluster endpoint (weight>0) and
 		// for each one of those add a new endpoint that points to the network's
-		// gateway with the relevant weight
+		// gateway with the relevant weight. Networks reached through the same gateway
+		// address share a single endpoint carrying the sum of their weights.
+		gwEpsByAddress := map[string]*endpoint.LbEndpoint{}
 		for network, w := range remoteEps {
 			networkConf, found := env.MeshNetworks.Networks[network]
 			if !found {
@@ -97,35 +103,38 @@ func EndpointsByNetworkFilter(endpoints []*endpoint.LocalityLbEndpoints, conn *X
 			}
 
 			registryName := getNetworkRegistry(networkConf)
-			gwEps := make([]*endpoint.LbEndpoint, 0)
+			gwAddresses := make([]*core.Address, 0)
 			// There may be multiples gateways for the network. Add an LbEndpoint for
 			// each one of them
 			for _, gw := range gws {
-				gwAddresses := getGatewayAddresses(gw, registryName, env)
-				// If gateway addresses are found, create an endpoint for each one of them
-				if len(gwAddresses) > 0 {
-					for _, gwAddr := range gwAddresses {
-						epAddr := util.BuildAddress(gwAddr, gw.Port)
-						gwEp := &endpoint.LbEndpoint{
-							HostIdentifier: &endpoint.LbEndpoint_Endpoint{
-								Endpoint: &endpoint.Endpoint{
-									Address: epAddr,
-								},
-							},
-							LoadBalancingWeight: &wrappers.UInt32Value{
-								Value: w,
-							},
-						}
-						gwEps = append(gwEps, gwEp)
-					}
+				for _, gwAddr := range getGatewayAddresses(gw, registryName, env) {
+					gwAddresses = append(gwAddresses, util.BuildAddress(gwAddr, gw.Port))
 				}
 			}
-			if len(gwEps) == 0 {
+			if len(gwAddresses) == 0 {
 				continue
 			}
-			weight := w * uint32(multiples/len(gwEps))
-			for _, gwEp := range gwEps {
-				gwEp.LoadBalancingWeight.Value = weight
+			weight := w * uint32(multiples/len(gwAddresses))
+			for _, epAddr := range gwAddresses {
+				sa := epAddr.GetSocketAddress()
+				key := net.JoinHostPort(sa.GetAddress(), strconv.Itoa(int(sa.GetPortValue())))
+				if gwEp, f := gwEpsByAddress[key]; f {
+					gwEp.LoadBalancingWeight.Value += weight
+					continue
+				}
+				gwEp := &endpoint.LbEndpoint{
+					HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+						Endpoint: &endpoint.Endpoint{
+							Address: epAddr,
+						},
+					},
+					LoadBalancingWeight: &wrappers.UInt32Value{
+						Value: weight,
+					},
+					// The network lets locality failover rank the gateway below the local endpoints.
+					Metadata: util.BuildLbEndpointMetadata("", network, "", ""),
+				}
+				gwEpsByAddress[key] = gwEp
 				lbEndpoints = append(lbEndpoints, gwEp)
 			}
 		}
@@ -15,6 +15,7 @@
 package v2
 
 import (
+	"reflect"
 	"sort"
 	"testing"
 
@@ -350,6 +351,62 @@ func TestEndpointsByNetworkFilter_RegistryServiceName(t *testing.T) {
 	}
 }
 
+func TestEndpointsByNetworkFilter_SharedGateway(t *testing.T) {
+	sharedGateway := []*meshconfig.Network_IstioNetworkGateway{
+		{
+			Gw: &meshconfig.Network_IstioNetworkGateway_Address{
+				Address: "2.2.2.2",
+			},
+			Port: 80,
+		},
+	}
+	env := &model.Environment{
+		ServiceDiscovery: NewMemServiceDiscovery(nil, 0),
+		MeshNetworks: &meshconfig.MeshNetworks{
+			Networks: map[string]*meshconfig.Network{
+				"network1": {},
+				"network2": {Gateways: sharedGateway},
+				"network3": {Gateways: sharedGateway},
+			},
+		},
+	}
+	endpoints := []*endpoint.LocalityLbEndpoints{{
+		LbEndpoints: createLbEndpoints([]*LbEpInfo{
+			{network: "network1", address: "10.0.0.1"},
+			{network: "network2", address: "20.0.0.1"},
+			{network: "network3", address: "30.0.0.1"},
+			{network: "network3", address: "30.0.0.2"},
+		}),
+	}}
+
+	filtered := EndpointsByNetworkFilter(endpoints, xdsConnection("network1"), env)
+	if len(filtered) != 1 {
+		t.Fatalf("Unexpected number of filtered endpoints: got %v, want 1", len(filtered))
+	}
+	want := map[string]uint32{
+		"10.0.0.1": 1,
+		// the gateway is shared by network2 and network3, so it carries the weight of both
+		"2.2.2.2": 3,
+	}
+	got := map[string]uint32{}
+	for _, lbEp := range filtered[0].LbEndpoints {
+		got[lbEp.GetEndpoint().Address.GetSocketAddress().Address] = lbEp.LoadBalancingWeight.GetValue()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected endpoints: got %v, want %v", got, want)
+	}
+	if filtered[0].LoadBalancingWeight.GetValue() != 4 {
+		t.Errorf("Unexpected locality weight: got %v, want 4", filtered[0].LoadBalancingWeight.GetValue())
+	}
+
+	// the endpoints are shared by all proxies and must not be modified by the filter
+	for _, lbEp := range endpoints[0].LbEndpoints {
+		if lbEp.LoadBalancingWeight != nil {
+			t.Errorf("Unexpected weight set on input endpoint %v", lbEp.GetEndpoint().Address)
+		}
+	}
+}
+
 func xdsConnection(network string) *XdsConnection {
 	return &XdsConnection{
 		node: &model.Proxy{
@@ -0,0 +1,230 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/config/filter/network/http_connection_manager/v2"
+	"github.com/envoyproxy/go-control-plane/pkg/conversion"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+	"istio.io/istio/pilot/pkg/networking/plugin"
+	"istio.io/istio/pilot/pkg/networking/plugin/registry"
+	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/config/labels"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the xDS generation tests")
+
+// goldenPlugins are the plugins of the config generator of the golden tests, the default plugins of
+// Pilot.
+var goldenPlugins = []string{plugin.Authn, plugin.Authz, plugin.Health, plugin.Mixer}
+
+// TestGolden renders the full xDS output of a proxy for a set of configs, and compares it against the
+// golden files. Each case reads the configs of testdata/golden/<case>/config.yaml and checks the
+// cds.json, lds.json, rds.json and eds.json files of the directory. The golden files are updated
+// with -update, or with REFRESH_GOLDEN=true.
+func TestGolden(t *testing.T) {
+	cases := []struct {
+		name  string
+		proxy *model.Proxy
+	}{
+		{
+			name: "sidecar",
+			proxy: &model.Proxy{
+				Type:            model.SidecarProxy,
+				IPAddresses:     []string{"10.1.0.1"},
+				ID:              "productpage-v1.default",
+				DNSDomain:       "default.svc.cluster.local",
+				ConfigNamespace: "default",
+				IstioVersion:    model.MaxIstioVersion,
+				Metadata: &model.NodeMetadata{
+					Labels: labels.Instance{"app": "productpage", "version": "v1"},
+				},
+			},
+		},
+		{
+			name: "gateway",
+			proxy: &model.Proxy{
+				Type:            model.Router,
+				IPAddresses:     []string{"10.2.0.1"},
+				ID:              "istio-ingressgateway.istio-system",
+				DNSDomain:       "istio-system.svc.cluster.local",
+				ConfigNamespace: "istio-system",
+				IstioVersion:    model.MaxIstioVersion,
+				Metadata: &model.NodeMetadata{
+					Labels: labels.Instance{"istio": "ingressgateway"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", "golden", tt.name)
+			input, err := ioutil.ReadFile(filepath.Join(dir, "config.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			configs, _, err := crd.ParseInputs(string(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for typ, content := range renderXds(t, tt.proxy, configs...) {
+				compareGolden(t, content, filepath.Join(dir, typ+".json"))
+			}
+		})
+	}
+}
+
+// renderXds renders the CDS, LDS, RDS and EDS output of the proxy for the configs, as Pilot pushes it
+// when the proxy connects and watches all the clusters and routes it is given.
+func renderXds(t *testing.T, proxy *model.Proxy, configs ...model.Config) map[string][]byte {
+	t.Helper()
+	s := SetupDiscoveryServer(t, configs...)
+	s.ConfigGenerator = v1alpha3.NewConfigGenerator(registry.NewPlugins(goldenPlugins))
+
+	push := s.globalPushContext()
+	if err := proxy.SetServiceInstances(s.Env); err != nil {
+		t.Fatal(err)
+	}
+	if err := proxy.SetWorkloadLabels(s.Env); err != nil {
+		t.Fatal(err)
+	}
+	proxy.SetSidecarScope(push)
+	proxy.SetGatewaysForProxy(push)
+	con := &XdsConnection{node: proxy}
+
+	clusters := s.generateRawClusters(con, push)
+	listeners := s.generateRawListeners(con, push)
+
+	// Watch the routes of the HTTP listeners and the EDS clusters.
+	for _, l := range listeners {
+		con.Routes = append(con.Routes, routeNames(t, l)...)
+	}
+	for _, c := range clusters {
+		if c.GetType() == xdsapi.Cluster_EDS {
+			con.Clusters = append(con.Clusters, c.Name)
+		}
+	}
+	routes := s.generateRawRoutes(con, push)
+	endpoints := s.generateEndpoints(push, con, nil)
+
+	out := map[string][]byte{}
+	for typ, resources := range map[string][]proto.Message{
+		"cds": messages(clusters),
+		"lds": messages(listeners),
+		"rds": messages(routes),
+		"eds": messages(endpoints),
+	} {
+		out[typ] = marshalGolden(t, resources)
+	}
+	return out
+}
+
+// routeNames returns the names of the routes of the HTTP connection managers of the listener.
+func routeNames(t *testing.T, l *xdsapi.Listener) []string {
+	t.Helper()
+	var names []string
+	for _, chain := range l.FilterChains {
+		for _, filter := range chain.Filters {
+			if filter.Name != "envoy.http_connection_manager" {
+				continue
+			}
+			cm := &hcm.HttpConnectionManager{}
+			var err error
+			if filter.GetTypedConfig() != nil {
+				err = ptypes.UnmarshalAny(filter.GetTypedConfig(), cm)
+			} else {
+				err = conversion.StructToMessage(filter.GetConfig(), cm)
+			}
+			if err != nil {
+				t.Fatalf("invalid HTTP connection manager in listener %s: %v", l.Name, err)
+			}
+			if rds := cm.GetRds(); rds != nil {
+				names = append(names, rds.RouteConfigName)
+			}
+		}
+	}
+	return names
+}
+
+func messages(resources interface{}) []proto.Message {
+	var out []proto.Message
+	switch r := resources.(type) {
+	case []*xdsapi.Cluster:
+		for _, m := range r {
+			out = append(out, m)
+		}
+	case []*xdsapi.Listener:
+		for _, m := range r {
+			out = append(out, m)
+		}
+	case []*xdsapi.RouteConfiguration:
+		for _, m := range r {
+			out = append(out, m)
+		}
+	case []*xdsapi.ClusterLoadAssignment:
+		for _, m := range r {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// marshalGolden marshals the resources to a JSON array, one resource per element.
+func marshalGolden(t *testing.T, resources []proto.Message) []byte {
+	t.Helper()
+	m := &jsonpb.Marshaler{Indent: "  "}
+	var b bytes.Buffer
+	b.WriteString("[")
+	for i, r := range resources {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+		if err := m.Marshal(&b, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.WriteString("\n]\n")
+	return b.Bytes()
+}
+
+func compareGolden(t *testing.T, content []byte, goldenFile string) {
+	t.Helper()
+	if *updateGolden || util.Refresh() {
+		t.Logf("Refreshing golden file %s", goldenFile)
+		if err := ioutil.WriteFile(goldenFile, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	golden := util.ReadFile(goldenFile, t)
+	if err := util.Compare(content, golden); err != nil {
+		t.Errorf("Failed validating golden file %s:\n%v", goldenFile, err)
+	}
+}
@@ -86,6 +86,23 @@ func connectADS(url string) (ads.AggregatedDiscoveryService_StreamAggregatedReso
 	}, nil
 }
 
+func connectDeltaADS(url string) (ads.AggregatedDiscoveryService_DeltaAggregatedResourcesClient, util.TearDownFunc, error) {
+	conn, err := grpc.Dial(url, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		return nil, nil, fmt.Errorf("GRPC dial failed: %s", err)
+	}
+	xds := ads.NewAggregatedDiscoveryServiceClient(conn)
+	deltastr, err := xds.DeltaAggregatedResources(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("delta resources failed: %s", err)
+	}
+
+	return deltastr, func() {
+		_ = deltastr.CloseSend()
+		_ = conn.Close()
+	}, nil
+}
+
 func connectADSS(url string) (ads.AggregatedDiscoveryService_StreamAggregatedResourcesClient, util.TearDownFunc, error) {
 	certDir := env.IstioSrc + "/tests/testdata/certs/default/"
 
@@ -149,6 +166,24 @@ func adsReceive(ads ads.AggregatedDiscoveryService_StreamAggregatedResourcesClie
 	return ads.Recv()
 }
 
+func deltaReceive(deltastr ads.AggregatedDiscoveryService_DeltaAggregatedResourcesClient,
+	to time.Duration) (*xdsapi.DeltaDiscoveryResponse, error) {
+	done := make(chan int, 1)
+	t := time.NewTimer(to)
+	defer func() {
+		done <- 1
+	}()
+	go func() {
+		select {
+		case <-t.C:
+			_ = deltastr.CloseSend() // will result in the Recv returning as well
+		case <-done:
+			_ = t.Stop()
+		}
+	}()
+	return deltastr.Recv()
+}
+
 func sendEDSReq(clusters []string, node string, edsstr ads.AggregatedDiscoveryService_StreamAggregatedResourcesClient) error {
 	err := edsstr.Send(&xdsapi.DiscoveryRequest{
 		ResponseNonce: time.Now().String(),
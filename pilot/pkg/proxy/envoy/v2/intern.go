@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// endpointInterner deduplicates the strings and the labels of the endpoints stored in the endpoint shards.
+// The registries allocate the service account, network, locality and labels of each endpoint separately,
+// although they are equal for all the endpoints of a workload or a service: interning them keeps a single
+// copy, shared by the endpoints.
+//
+// The interned values are kept for two generations: rotating the generation releases the values that were
+// not used since the previous rotation, so that the values of deleted endpoints do not accumulate.
+type endpointInterner struct {
+	mutex sync.Mutex
+
+	strings         map[string]string
+	previousStrings map[string]string
+
+	// labels are keyed by their canonical string form, see labelsKey.
+	labels         map[string]map[string]string
+	previousLabels map[string]map[string]string
+}
+
+func newEndpointInterner() *endpointInterner {
+	return &endpointInterner{
+		strings:         map[string]string{},
+		previousStrings: map[string]string{},
+		labels:          map[string]map[string]string{},
+		previousLabels:  map[string]map[string]string{},
+	}
+}
+
+// internEndpoints replaces the strings and the labels of the endpoints with their interned values.
+// The labels of the endpoints must not be modified afterwards, as they may be shared.
+func (i *endpointInterner) internEndpoints(endpoints []*model.IstioEndpoint) {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	for _, e := range endpoints {
+		e.ServicePortName = i.intern(e.ServicePortName)
+		e.ServiceAccount = i.intern(e.ServiceAccount)
+		e.Network = i.intern(e.Network)
+		e.Locality = i.intern(e.Locality)
+		e.TLSMode = i.intern(e.TLSMode)
+		e.ClusterID = i.intern(e.ClusterID)
+		e.Attributes.ServiceRegistry = i.intern(e.Attributes.ServiceRegistry)
+		e.Attributes.Name = i.intern(e.Attributes.Name)
+		e.Attributes.Namespace = i.intern(e.Attributes.Namespace)
+		e.Labels = i.internLabels(e.Labels)
+	}
+}
+
+func (i *endpointInterner) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if interned, f := i.strings[s]; f {
+		return interned
+	}
+	if interned, f := i.previousStrings[s]; f {
+		i.strings[s] = interned
+		return interned
+	}
+	i.strings[s] = s
+	return s
+}
+
+func (i *endpointInterner) internLabels(l map[string]string) map[string]string {
+	if len(l) == 0 {
+		return l
+	}
+	key := labelsKey(l)
+	if interned, f := i.labels[key]; f {
+		return interned
+	}
+	if interned, f := i.previousLabels[key]; f {
+		i.labels[key] = interned
+		return interned
+	}
+	interned := make(map[string]string, len(l))
+	for k, v := range l {
+		interned[i.intern(k)] = i.intern(v)
+	}
+	i.labels[key] = interned
+	return interned
+}
+
+// rotate starts a new generation of interned values.
+func (i *endpointInterner) rotate() {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+
+	i.previousStrings, i.strings = i.strings, make(map[string]string, len(i.strings))
+	i.previousLabels, i.labels = i.labels, make(map[string]map[string]string, len(i.labels))
+}
+
+// labelsKey returns the labels as a string, with the labels sorted by key. Each key and value is prefixed with
+// its length, so that different labels never have the same string form.
+func labelsKey(l map[string]string) string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		for _, s := range []string{k, l[k]} {
+			b.WriteString(strconv.Itoa(len(s)))
+			b.WriteByte(':')
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
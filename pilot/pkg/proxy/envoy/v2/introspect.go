@@ -0,0 +1,311 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/pkg/env"
+)
+
+const (
+	// introspectionAPIVersion is the version of the introspection API, part of its paths.
+	introspectionAPIVersion = "v1"
+	introspectionPrefix     = "/introspect/" + introspectionAPIVersion + "/"
+)
+
+// introspectionTopic is a part of the internal state of Pilot exposed by the introspection API.
+type introspectionTopic struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+	get         func() (interface{}, error)
+}
+
+// introspectionResponse is the envelope of the responses of the introspection API.
+type introspectionResponse struct {
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Data       interface{} `json:"data"`
+}
+
+// FeatureFlag is a Pilot environment variable, as returned by the introspection API.
+type FeatureFlag struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	DefaultValue string `json:"defaultValue"`
+	// Value is the value set in the environment, if any.
+	Value       *string `json:"value,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Deprecated  bool    `json:"deprecated,omitempty"`
+}
+
+// RegistryInfo is a service registry, as returned by the introspection API.
+type RegistryInfo struct {
+	Name      string `json:"name"`
+	ClusterID string `json:"clusterID"`
+	Services  int    `json:"services"`
+	Error     string `json:"error,omitempty"`
+}
+
+// MemoryStats are the memory statistics of Pilot, as returned by the introspection API.
+type MemoryStats struct {
+	Alloc        uint64    `json:"alloc"`
+	TotalAlloc   uint64    `json:"totalAlloc"`
+	Sys          uint64    `json:"sys"`
+	HeapAlloc    uint64    `json:"heapAlloc"`
+	HeapInuse    uint64    `json:"heapInuse"`
+	HeapIdle     uint64    `json:"heapIdle"`
+	HeapReleased uint64    `json:"heapReleased"`
+	HeapObjects  uint64    `json:"heapObjects"`
+	StackInuse   uint64    `json:"stackInuse"`
+	NumGC        uint32    `json:"numGC"`
+	PauseTotal   string    `json:"pauseTotal"`
+	LastGC       time.Time `json:"lastGC"`
+	Goroutines   int       `json:"goroutines"`
+}
+
+var varTypeNames = map[env.VarType]string{
+	env.STRING:   "string",
+	env.BOOL:     "bool",
+	env.INT:      "int",
+	env.FLOAT:    "float",
+	env.DURATION: "duration",
+}
+
+// initIntrospection registers the introspection API, a versioned JSON API exposing the internal state of
+// Pilot to automation, replacing the ControlZ pages. /introspect/v1/ lists the topics, each served at
+// /introspect/v1/<topic>, and /introspect/v1/ui renders them all as a page.
+func (s *DiscoveryServer) initIntrospection(mux *http.ServeMux, sctl *aggregate.Controller) {
+	topics := []introspectionTopic{
+		{
+			Name:        "mesh",
+			Description: "The mesh config.",
+			get:         s.introspectMesh,
+		},
+		{
+			Name:        "features",
+			Description: "The feature flags, set through environment variables.",
+			get:         introspectFeatures,
+		},
+		{
+			Name:        "registries",
+			Description: "The service registries.",
+			get: func() (interface{}, error) {
+				return introspectRegistries(sctl), nil
+			},
+		},
+		{
+			Name: "services",
+			Description: "The services of all the registries, with the registries defining them, " +
+				"how they are merged and their conflicts.",
+			get: func() (interface{}, error) {
+				return introspectServices(sctl), nil
+			},
+		},
+		{
+			Name:        "memory",
+			Description: "The memory statistics.",
+			get: func() (interface{}, error) {
+				return introspectMemory(), nil
+			},
+		},
+	}
+	for i := range topics {
+		topic := topics[i]
+		topics[i].Path = introspectionPrefix + topic.Name
+		mux.HandleFunc(topics[i].Path, s.guardDebug(nil, func(w http.ResponseWriter, req *http.Request) {
+			data, err := topic.get()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeIntrospection(w, topic.Name, data)
+		}))
+	}
+
+	mux.HandleFunc(introspectionPrefix, s.guardDebug(nil, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != introspectionPrefix {
+			http.NotFound(w, req)
+			return
+		}
+		writeIntrospection(w, "topics", topics)
+	}))
+	mux.HandleFunc(introspectionPrefix+"ui", s.guardDebug(nil, func(w http.ResponseWriter, req *http.Request) {
+		introspectionUI(w, topics)
+	}))
+}
+
+func writeIntrospection(w http.ResponseWriter, kind string, data interface{}) {
+	out, err := json.MarshalIndent(introspectionResponse{
+		APIVersion: introspectionAPIVersion,
+		Kind:       kind,
+		Data:       data,
+	}, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+func (s *DiscoveryServer) introspectMesh() (interface{}, error) {
+	if s.Env == nil || s.Env.Mesh == nil {
+		return nil, nil
+	}
+	// The mesh config is a gogo proto message, following the proto3 JSON mapping.
+	out, err := (&jsonpb.Marshaler{}).MarshalToString(s.Env.Mesh)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(out), nil
+}
+
+func introspectFeatures() (interface{}, error) {
+	flags := make([]FeatureFlag, 0)
+	for _, v := range env.VarDescriptions() {
+		if v.Hidden {
+			continue
+		}
+		flag := FeatureFlag{
+			Name:         v.Name,
+			Type:         varTypeNames[v.Type],
+			DefaultValue: v.DefaultValue,
+			Description:  v.Description,
+			Deprecated:   v.Deprecated,
+		}
+		if value, ok := os.LookupEnv(v.Name); ok {
+			flag.Value = &value
+		}
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags, nil
+}
+
+func introspectRegistries(sctl *aggregate.Controller) []RegistryInfo {
+	registries := make([]RegistryInfo, 0)
+	if sctl == nil {
+		return registries
+	}
+	for _, registry := range sctl.GetRegistries() {
+		info := RegistryInfo{
+			Name:      string(registry.Name),
+			ClusterID: registry.ClusterID,
+		}
+		services, err := registry.Services()
+		if err != nil {
+			info.Error = err.Error()
+		}
+		info.Services = len(services)
+		registries = append(registries, info)
+	}
+	return registries
+}
+
+// introspectServices returns the provenance of the services. The registries failing to list their
+// services are reported by the registries topic.
+func introspectServices(sctl *aggregate.Controller) []aggregate.ServiceProvenance {
+	if sctl == nil {
+		return []aggregate.ServiceProvenance{}
+	}
+	services, err := sctl.Provenance()
+	if err != nil {
+		adsLog.Debugf("Failed to list the services of some registries: %v", err)
+	}
+	return services
+}
+
+func introspectMemory() MemoryStats {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	stats := MemoryStats{
+		Alloc:        ms.Alloc,
+		TotalAlloc:   ms.TotalAlloc,
+		Sys:          ms.Sys,
+		HeapAlloc:    ms.HeapAlloc,
+		HeapInuse:    ms.HeapInuse,
+		HeapIdle:     ms.HeapIdle,
+		HeapReleased: ms.HeapReleased,
+		HeapObjects:  ms.HeapObjects,
+		StackInuse:   ms.StackInuse,
+		NumGC:        ms.NumGC,
+		PauseTotal:   time.Duration(ms.PauseTotalNs).String(),
+		Goroutines:   runtime.NumGoroutine(),
+	}
+	if ms.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(ms.LastGC))
+	}
+	return stats
+}
+
+var introspectionUITemplate = template.Must(template.New("introspection").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Pilot introspection</title></head>
+<body>
+<h1>Pilot introspection</h1>
+<ul>
+{{range .}}<li><a href="#{{.Name}}">{{.Name}}</a>: {{.Description}} (<a href="{{.Path}}">JSON</a>)</li>
+{{end}}</ul>
+{{range .}}<h2 id="{{.Name}}">{{.Name}}</h2>
+<pre>{{.Content}}</pre>
+{{end}}</body>
+</html>
+`))
+
+// introspectionUI renders all the topics as a single page, for humans.
+func introspectionUI(w http.ResponseWriter, topics []introspectionTopic) {
+	type section struct {
+		introspectionTopic
+		Content string
+	}
+	sections := make([]section, 0, len(topics))
+	for _, topic := range topics {
+		var content string
+		data, err := topic.get()
+		if err != nil {
+			content = "error: " + err.Error()
+		} else {
+			var out bytes.Buffer
+			encoder := json.NewEncoder(&out)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(data); err != nil {
+				content = "error: " + err.Error()
+			} else {
+				content = strings.TrimSpace(out.String())
+			}
+		}
+		sections = append(sections, section{introspectionTopic: topic, Content: content})
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := introspectionUITemplate.Execute(w, sections); err != nil {
+		adsLog.Warnf("Failed to render the introspection UI: %v", err)
+	}
+}
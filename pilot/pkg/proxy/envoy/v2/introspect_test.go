@@ -0,0 +1,119 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pkg/config/host"
+)
+
+func TestIntrospection(t *testing.T) {
+	registry := NewMemServiceDiscovery(map[host.Name]*model.Service{
+		"foo.bar": {Hostname: "foo.bar"},
+	}, 0)
+	sctl := aggregate.NewController()
+	sctl.AddRegistry(aggregate.Registry{
+		ClusterID:        "cluster1",
+		Name:             serviceregistry.ServiceRegistry("mem"),
+		ServiceDiscovery: registry,
+		Controller:       registry.controller,
+	})
+	s := &DiscoveryServer{Env: &model.Environment{Mesh: &meshconfig.MeshConfig{IngressClass: "istio"}}}
+	mux := http.NewServeMux()
+	s.initIntrospection(mux, sctl)
+
+	get := func(path string, data interface{}) introspectionResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: got code %d", path, rec.Code)
+		}
+		resp := introspectionResponse{Data: data}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		if resp.APIVersion != "v1" {
+			t.Errorf("GET %s: got apiVersion %q", path, resp.APIVersion)
+		}
+		return resp
+	}
+
+	var topics []introspectionTopic
+	if resp := get("/introspect/v1/", &topics); resp.Kind != "topics" || len(topics) != 5 {
+		t.Errorf("got topics %v of kind %s", topics, resp.Kind)
+	}
+
+	var mesh map[string]interface{}
+	get("/introspect/v1/mesh", &mesh)
+	if mesh["ingressClass"] != "istio" {
+		t.Errorf("got mesh config %v", mesh)
+	}
+
+	var flags []FeatureFlag
+	get("/introspect/v1/features", &flags)
+	found := false
+	for _, flag := range flags {
+		if flag.Name == "PILOT_PUSH_THROTTLE" {
+			found = true
+			if flag.Type != "int" || flag.DefaultValue == "" {
+				t.Errorf("got feature flag %+v", flag)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("feature flag PILOT_PUSH_THROTTLE not found in %v", flags)
+	}
+
+	var registries []RegistryInfo
+	get("/introspect/v1/registries", &registries)
+	if len(registries) != 1 || registries[0].ClusterID != "cluster1" || registries[0].Services != 1 {
+		t.Errorf("got registries %+v", registries)
+	}
+
+	var services []aggregate.ServiceProvenance
+	get("/introspect/v1/services", &services)
+	if len(services) != 1 || services[0].Hostname != "foo.bar" || services[0].Sources[0].Registry != "mem" {
+		t.Errorf("got services %+v", services)
+	}
+
+	var memory MemoryStats
+	get("/introspect/v1/memory", &memory)
+	if memory.HeapAlloc == 0 || memory.Goroutines == 0 {
+		t.Errorf("got memory stats %+v", memory)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/introspect/v1/ui", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `<h2 id="registries">`) || !strings.Contains(body, "cluster1") {
+		t.Errorf("got UI %s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/introspect/v1/unknown", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("got code %d for an unknown topic", rec.Code)
+	}
+}
@@ -20,13 +20,17 @@ import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/grpcgen"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
 func (s *DiscoveryServer) pushLds(con *XdsConnection, push *model.PushContext, version string) error {
 	// TODO: Modify interface to take services, and config instead of making library query registry
 	pushStart := time.Now()
-	rawListeners := s.generateRawListeners(con, push)
+	var rawListeners []*xdsapi.Listener
+	s.generationWorkers.run(func() {
+		rawListeners = s.generateRawListeners(con, push)
+	})
 
 	if s.DebugConfigs {
 		con.LDSListeners = rawListeners
@@ -46,7 +50,12 @@ func (s *DiscoveryServer) pushLds(con *XdsConnection, push *model.PushContext, v
 }
 
 func (s *DiscoveryServer) generateRawListeners(con *XdsConnection, push *model.PushContext) []*xdsapi.Listener {
-	rawListeners := s.ConfigGenerator.BuildListeners(s.Env, con.node, push)
+	var rawListeners []*xdsapi.Listener
+	if con.node.IsProxylessGRPC() {
+		rawListeners = grpcgen.BuildListeners(con.node, con.ListenerNames)
+	} else {
+		rawListeners = s.ConfigGenerator.BuildListeners(s.Env, con.node, push)
+	}
 
 	for _, l := range rawListeners {
 		if err := l.Validate(); err != nil {
@@ -28,6 +28,7 @@ import (
 
 // memregistry is based on mock/discovery - it is used for testing and debugging v2.
 // In future (post 1.0) it may be used for representing remote pilots.
+// Tools embedding the discovery server should use the thread-safe memory.Registry instead.
 
 // MemServiceController is a mock service controller
 type MemServiceController struct {
@@ -25,6 +25,7 @@ var (
 	clusterTag = monitoring.MustCreateLabel("cluster")
 	nodeTag    = monitoring.MustCreateLabel("node")
 	typeTag    = monitoring.MustCreateLabel("type")
+	versionTag = monitoring.MustCreateLabel("version")
 
 	cdsReject = monitoring.NewGauge(
 		"pilot_xds_cds_reject",
@@ -62,6 +63,23 @@ var (
 		monitoring.WithLabels(nodeTag, errTag),
 	)
 
+	nackQuarantines = monitoring.NewSum(
+		"pilot_xds_nack_quarantines",
+		"Number of times the pushes of a type to a proxy were quarantined after repeated rejections.",
+		monitoring.WithLabels(typeTag),
+	)
+
+	quarantinedPushes = monitoring.NewSum(
+		"pilot_xds_quarantined_pushes",
+		"Number of pushes skipped because their type was quarantined for the proxy.",
+		monitoring.WithLabels(typeTag),
+	)
+
+	deltaUnchangedResources = monitoring.NewSum(
+		"pilot_xds_delta_unchanged_resources",
+		"Number of resources not sent to the incremental xDS clients, as they already had their version.",
+	)
+
 	rdsExpiredNonce = monitoring.NewSum(
 		"pilot_rds_expired_nonce",
 		"Total number of RDS messages with an expired nonce.",
@@ -84,6 +102,17 @@ var (
 		"Number of endpoints connected to this pilot using XDS.",
 	)
 
+	xdsClientVersions = monitoring.NewGauge(
+		"pilot_xds_proxy_versions",
+		"Number of proxies connected to this pilot using XDS, by Istio version.",
+		monitoring.WithLabels(versionTag),
+	)
+
+	unsupportedProxyRejects = monitoring.NewSum(
+		"pilot_xds_unsupported_proxy_rejects",
+		"Number of connections of proxies refused because their version is too old for pilot.",
+	)
+
 	xdsResponseWriteTimeouts = monitoring.NewSum(
 		"pilot_xds_write_timeout",
 		"Pilot XDS response write timeouts.",
@@ -120,6 +149,12 @@ var (
 	ldsPushTime = pushTime.With(typeTag.Value("lds"))
 	rdsPushTime = pushTime.With(typeTag.Value("rds"))
 
+	generationWaitTime = monitoring.NewDistribution(
+		"pilot_push_generation_wait_time",
+		"Time in seconds, a push waits for a worker to generate the config of a proxy.",
+		[]float64{.01, .1, 1, 3, 5, 10, 20},
+	)
+
 	// only supported dimension is millis, unfortunately. default to unitdimensionless.
 	proxiesQueueTime = monitoring.NewDistribution(
 		"pilot_proxy_queue_time",
@@ -176,16 +211,22 @@ func init() {
 		edsReject,
 		ldsReject,
 		rdsReject,
+		nackQuarantines,
+		quarantinedPushes,
+		deltaUnchangedResources,
 		edsInstances,
 		rdsExpiredNonce,
 		totalXDSRejects,
 		monServices,
 		xdsClients,
+		xdsClientVersions,
+		unsupportedProxyRejects,
 		xdsResponseWriteTimeouts,
 		pushes,
 		pushTime,
 		proxiesConvergeDelay,
 		proxiesQueueTime,
+		generationWaitTime,
 		pushContextErrors,
 		totalXDSInternalErrors,
 		inboundUpdates,
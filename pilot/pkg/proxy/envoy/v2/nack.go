@@ -0,0 +1,132 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"sort"
+	"time"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+// nackBackoff tracks the rejections of a resource type by a proxy. Once the proxy rejected the type
+// PILOT_NACK_QUARANTINE_THRESHOLD times in a row, the type is quarantined: the pushes of the type
+// triggered by config changes are skipped until the quarantine expires, so that a config the proxy
+// keeps rejecting, e.g. from a broken EnvoyFilter, is not generated and logged on every change.
+type nackBackoff struct {
+	// nacks is the number of consecutive rejections of the type.
+	nacks int
+	// backoff is the duration of the last quarantine, doubled on each new rejection.
+	backoff time.Duration
+	// until is the end of the last quarantine.
+	until time.Time
+	// skipped is set if a push of the type was skipped during the quarantine, and has to be retried.
+	skipped bool
+}
+
+// recordNack records a rejection of the type by the proxy, and quarantines the type if the proxy
+// rejected it too many times in a row.
+func (s *DiscoveryServer) recordNack(con *XdsConnection, typ XdsType) {
+	if features.NackQuarantineThreshold <= 0 {
+		return
+	}
+
+	con.mu.Lock()
+	if con.nackBackoffs == nil {
+		con.nackBackoffs = map[XdsType]*nackBackoff{}
+	}
+	b := con.nackBackoffs[typ]
+	if b == nil {
+		b = &nackBackoff{}
+		con.nackBackoffs[typ] = b
+	}
+	b.nacks++
+	if b.nacks < features.NackQuarantineThreshold {
+		con.mu.Unlock()
+		return
+	}
+	if b.backoff == 0 {
+		b.backoff = features.NackBackoff
+	} else {
+		b.backoff *= 2
+	}
+	if b.backoff > features.NackMaxBackoff {
+		b.backoff = features.NackMaxBackoff
+	}
+	b.until = time.Now().Add(b.backoff)
+	backoff, nacks := b.backoff, b.nacks
+	con.mu.Unlock()
+
+	adsLog.Warnf("ADS: quarantining the %s pushes to %s for %v after %d consecutive rejections", typ, con.ConID, backoff, nacks)
+	nackQuarantines.With(typeTag.Value(typ.String())).Increment()
+
+	// Retry the pushes skipped during the quarantine once it expires. A rejection of the retried push
+	// quarantines the type again, for twice as long.
+	time.AfterFunc(backoff, func() {
+		if con.endQuarantine(typ) {
+			s.pushQueue.Enqueue(con, &model.PushRequest{
+				Full:  true,
+				Push:  s.globalPushContext(),
+				Start: time.Now(),
+			})
+		}
+	})
+}
+
+// resetNacks ends the quarantine of the type, as the proxy accepted it.
+func (con *XdsConnection) resetNacks(typ XdsType) {
+	con.mu.Lock()
+	delete(con.nackBackoffs, typ)
+	con.mu.Unlock()
+}
+
+// skipQuarantined returns whether the push of the type to the proxy is skipped, as the type is quarantined.
+func (con *XdsConnection) skipQuarantined(typ XdsType) bool {
+	con.mu.Lock()
+	defer con.mu.Unlock()
+	b := con.nackBackoffs[typ]
+	if b == nil || !time.Now().Before(b.until) {
+		return false
+	}
+	b.skipped = true
+	quarantinedPushes.With(typeTag.Value(typ.String())).Increment()
+	return true
+}
+
+// endQuarantine returns whether pushes of the type were skipped during its quarantine, if it expired.
+func (con *XdsConnection) endQuarantine(typ XdsType) bool {
+	con.mu.Lock()
+	defer con.mu.Unlock()
+	b := con.nackBackoffs[typ]
+	if b == nil || time.Now().Before(b.until) || !b.skipped {
+		return false
+	}
+	b.skipped = false
+	return true
+}
+
+// quarantinedTypes returns the types currently quarantined for the proxy. The caller must hold con.mu.
+func (con *XdsConnection) quarantinedTypes() []string {
+	var out []string
+	now := time.Now()
+	for typ, b := range con.nackBackoffs {
+		if now.Before(b.until) {
+			out = append(out, typ.String())
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestNackQuarantine(t *testing.T) {
+	threshold, backoff, maxBackoff := features.NackQuarantineThreshold, features.NackBackoff, features.NackMaxBackoff
+	features.NackQuarantineThreshold = 2
+	features.NackBackoff = 100 * time.Millisecond
+	features.NackMaxBackoff = 150 * time.Millisecond
+	defer func() {
+		features.NackQuarantineThreshold, features.NackBackoff, features.NackMaxBackoff = threshold, backoff, maxBackoff
+	}()
+
+	s := &DiscoveryServer{
+		Env:       &model.Environment{PushContext: model.NewPushContext()},
+		pushQueue: NewPushQueue(),
+	}
+	con := &XdsConnection{ConID: "sidecar~1.1.1.1~app.default~default.svc.cluster.local-1"}
+
+	s.recordNack(con, LDS)
+	if con.skipQuarantined(LDS) {
+		t.Fatal("expected no quarantine below the threshold")
+	}
+	s.recordNack(con, LDS)
+	if !con.skipQuarantined(LDS) {
+		t.Fatal("expected the rejected type to be quarantined")
+	}
+	if con.skipQuarantined(CDS) {
+		t.Fatal("expected the other types to be pushed")
+	}
+	con.mu.RLock()
+	quarantined := con.quarantinedTypes()
+	con.mu.RUnlock()
+	if !reflect.DeepEqual(quarantined, []string{"lds"}) {
+		t.Fatalf("expected lds to be quarantined, got %v", quarantined)
+	}
+
+	// The skipped push is retried once the quarantine expires.
+	ExpectDequeue(t, s.pushQueue, con)
+	if con.skipQuarantined(LDS) {
+		t.Fatal("expected the quarantine to expire")
+	}
+
+	// A new rejection doubles the quarantine, up to the maximum backoff.
+	s.recordNack(con, LDS)
+	con.mu.RLock()
+	b := con.nackBackoffs[LDS].backoff
+	con.mu.RUnlock()
+	if b != features.NackMaxBackoff {
+		t.Fatalf("expected a backoff of %v, got %v", features.NackMaxBackoff, b)
+	}
+
+	con.resetNacks(LDS)
+	if con.skipQuarantined(LDS) {
+		t.Fatal("expected an ACK to end the quarantine")
+	}
+	// No push was skipped, so none is retried.
+	ExpectTimeout(t, s.pushQueue)
+}
+
+func TestNackQuarantineDisabled(t *testing.T) {
+	threshold := features.NackQuarantineThreshold
+	features.NackQuarantineThreshold = 0
+	defer func() { features.NackQuarantineThreshold = threshold }()
+
+	s := &DiscoveryServer{pushQueue: NewPushQueue()}
+	con := &XdsConnection{}
+	for i := 0; i < 10; i++ {
+		s.recordNack(con, CDS)
+	}
+	if con.skipQuarantined(CDS) {
+		t.Fatal("expected no quarantine when disabled")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	runtimepprof "runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"sync"
+	"time"
+
+	"istio.io/istio/pilot/pkg/features"
+)
+
+const (
+	cpuProfile = "cpu"
+	tracing    = "trace"
+)
+
+// initProfiling registers the profiling endpoints. Besides the standard pprof endpoints, /debug/pprof/push
+// captures a CPU profile or an execution trace of the next full push: from the start of the push until
+// all the proxies have been pushed.
+//
+// The endpoints only serve the requests authorized by guardProfiling, and the duration of the captures is
+// limited by PILOT_DEBUG_PROFILING_MAX_DURATION.
+func (s *DiscoveryServer) initProfiling(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", guardProfiling(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", guardProfiling(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", guardProfiling(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", guardProfiling(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", guardProfiling(pprof.Trace))
+	mux.HandleFunc("/debug/pprof/push", guardProfiling(s.pushProfile))
+}
+
+// guardProfiling only serves the requests carrying the token configured by PILOT_DEBUG_PROFILING_TOKEN,
+// or the requests from the loopback interface if no token is configured. It caps the duration requested
+// by the seconds parameter to PILOT_DEBUG_PROFILING_MAX_DURATION.
+func guardProfiling(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !profilingAuthorized(r) {
+			http.Error(w, "profiling not authorized", http.StatusForbidden)
+			return
+		}
+
+		maxSeconds := int(features.DebugProfilingMaxDuration / time.Second)
+		if maxSeconds < 1 {
+			maxSeconds = 1
+		}
+		// The CPU profiles last 30s by default.
+		seconds := 30
+		if v := r.FormValue("seconds"); v != "" {
+			if parsed, err := strconv.Atoi(v); err == nil {
+				seconds = parsed
+			}
+		}
+		if seconds > maxSeconds {
+			q := r.URL.Query()
+			q.Set("seconds", strconv.Itoa(maxSeconds))
+			r.URL.RawQuery = q.Encode()
+			r.Form = nil
+		}
+
+		handler(w, r)
+	}
+}
+
+func profilingAuthorized(r *http.Request) bool {
+	if token := features.DebugProfilingToken; token != "" {
+		return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) == 1
+	}
+	return isLoopback(r)
+}
+
+// pushProfile captures a CPU profile, or an execution trace with ?profile=trace, of the next full push. It
+// waits for the push to start for the number of seconds of the request, which also bounds the capture.
+func (s *DiscoveryServer) pushProfile(w http.ResponseWriter, r *http.Request) {
+	kind := r.FormValue("profile")
+	if kind == "" {
+		kind = cpuProfile
+	}
+	if kind != cpuProfile && kind != tracing {
+		http.Error(w, fmt.Sprintf("unknown profile %q, expected %q or %q", kind, cpuProfile, tracing), http.StatusBadRequest)
+		return
+	}
+	seconds, err := strconv.Atoi(r.FormValue("seconds"))
+	if err != nil || seconds <= 0 {
+		seconds = 30
+	}
+	deadline := time.Now().Add(time.Duration(seconds) * time.Second)
+
+	capture, err := s.pushProfiler.arm(kind)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	select {
+	case err := <-capture.started:
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to start the %s profile: %v", kind, err), http.StatusInternalServerError)
+			return
+		}
+	case <-time.After(time.Until(deadline)):
+		if s.pushProfiler.disarm(capture) {
+			http.Error(w, "no full push started", http.StatusGatewayTimeout)
+			return
+		}
+		// The push started meanwhile.
+		if err := <-capture.started; err != nil {
+			http.Error(w, fmt.Sprintf("failed to start the %s profile: %v", kind, err), http.StatusInternalServerError)
+			return
+		}
+	case <-r.Context().Done():
+		if !s.pushProfiler.disarm(capture) {
+			if err := <-capture.started; err == nil {
+				capture.stop()
+			}
+		}
+		return
+	}
+
+	// The push is done once all the proxies have been pushed.
+	for time.Now().Before(deadline) && !s.pushQueue.Idle() {
+		time.Sleep(100 * time.Millisecond)
+	}
+	capture.stop()
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"push-%s\"", kind))
+	_, _ = w.Write(capture.buf.Bytes())
+}
+
+// pushProfiler starts the capture requested through /debug/pprof/push when the next full push starts.
+type pushProfiler struct {
+	mutex sync.Mutex
+	next  *pushCapture
+}
+
+type pushCapture struct {
+	kind string
+	buf  bytes.Buffer
+	// started receives the result of starting the capture.
+	started chan error
+}
+
+// arm requests a capture of the next full push. Only one capture can be requested at a time.
+func (p *pushProfiler) arm(kind string) (*pushCapture, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.next != nil {
+		return nil, errors.New("a capture of the next push is already requested")
+	}
+	p.next = &pushCapture{kind: kind, started: make(chan error, 1)}
+	return p.next, nil
+}
+
+// disarm cancels the capture, and returns false if it already started.
+func (p *pushProfiler) disarm(c *pushCapture) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.next != c {
+		return false
+	}
+	p.next = nil
+	return true
+}
+
+// pushStarted starts the requested capture, if any. It is called at the start of the full pushes.
+func (p *pushProfiler) pushStarted() {
+	if p == nil {
+		return
+	}
+	p.mutex.Lock()
+	c := p.next
+	p.next = nil
+	p.mutex.Unlock()
+	if c == nil {
+		return
+	}
+
+	adsLog.Infof("Capturing a %s profile of the push", c.kind)
+	if c.kind == tracing {
+		c.started <- trace.Start(&c.buf)
+	} else {
+		c.started <- runtimepprof.StartCPUProfile(&c.buf)
+	}
+}
+
+func (c *pushCapture) stop() {
+	if c.kind == tracing {
+		trace.Stop()
+	} else {
+		runtimepprof.StopCPUProfile()
+	}
+}
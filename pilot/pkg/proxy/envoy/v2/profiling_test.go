@@ -0,0 +1,125 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/features"
+)
+
+func TestGuardProfiling(t *testing.T) {
+	defer func(token string, maxDuration time.Duration) {
+		features.DebugProfilingToken = token
+		features.DebugProfilingMaxDuration = maxDuration
+	}(features.DebugProfilingToken, features.DebugProfilingMaxDuration)
+	features.DebugProfilingMaxDuration = 10 * time.Second
+
+	cases := []struct {
+		name        string
+		token       string
+		remoteAddr  string
+		header      string
+		query       string
+		wantCode    int
+		wantSeconds string
+	}{
+		{"loopback", "", "127.0.0.1:1234", "", "seconds=5", http.StatusOK, "5"},
+		{"loopback ipv6", "", "[::1]:1234", "", "seconds=5", http.StatusOK, "5"},
+		{"remote without token", "", "10.0.0.1:1234", "", "seconds=5", http.StatusForbidden, ""},
+		{"remote with token", "secret", "10.0.0.1:1234", "Bearer secret", "seconds=5", http.StatusOK, "5"},
+		{"wrong token", "secret", "10.0.0.1:1234", "Bearer other", "seconds=5", http.StatusForbidden, ""},
+		{"loopback without token", "secret", "127.0.0.1:1234", "", "seconds=5", http.StatusForbidden, ""},
+		{"capped duration", "", "127.0.0.1:1234", "", "seconds=60", http.StatusOK, "10"},
+		{"capped default duration", "", "127.0.0.1:1234", "", "", http.StatusOK, "10"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			features.DebugProfilingToken = tt.token
+			var gotSeconds string
+			handler := guardProfiling(func(w http.ResponseWriter, r *http.Request) {
+				gotSeconds = r.FormValue("seconds")
+			})
+
+			req := httptest.NewRequest("GET", "/debug/pprof/profile?"+tt.query, nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("got code %d, want %d", rec.Code, tt.wantCode)
+			}
+			if gotSeconds != tt.wantSeconds {
+				t.Errorf("got seconds %q, want %q", gotSeconds, tt.wantSeconds)
+			}
+		})
+	}
+}
+
+func TestPushProfile(t *testing.T) {
+	s := &DiscoveryServer{pushQueue: NewPushQueue(), pushProfiler: &pushProfiler{}}
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		rec := httptest.NewRecorder()
+		s.pushProfile(rec, httptest.NewRequest("GET", "/debug/pprof/push?seconds=5", nil))
+		done <- rec
+	}()
+
+	// Wait for the capture to be requested before starting the push.
+	for {
+		s.pushProfiler.mutex.Lock()
+		armed := s.pushProfiler.next != nil
+		s.pushProfiler.mutex.Unlock()
+		if armed {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := s.pushProfiler.arm(cpuProfile); err == nil {
+		t.Errorf("expected a second capture request to be rejected")
+	}
+	s.pushProfiler.pushStarted()
+
+	select {
+	case rec := <-done:
+		if rec.Code != http.StatusOK {
+			t.Fatalf("got code %d: %s", rec.Code, rec.Body.String())
+		}
+		if rec.Body.Len() == 0 {
+			t.Errorf("got an empty profile")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the capture did not complete")
+	}
+
+	// Without a push, the request times out.
+	rec := httptest.NewRecorder()
+	s.pushProfile(rec, httptest.NewRequest("GET", "/debug/pprof/push?seconds=1&profile=trace", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("got code %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	s.pushProfiler.mutex.Lock()
+	defer s.pushProfiler.mutex.Unlock()
+	if s.pushProfiler.next != nil {
+		t.Errorf("expected the timed out capture to be cancelled")
+	}
+}
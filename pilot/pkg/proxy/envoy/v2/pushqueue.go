@@ -106,6 +106,13 @@ func (p *PushQueue) MarkDone(con *XdsConnection) {
 	}
 }
 
+// Idle returns whether no proxy is waiting to be pushed or being pushed.
+func (p *PushQueue) Idle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.connections) == 0 && len(p.inProgress) == 0
+}
+
 // Get number of pending proxies
 func (p *PushQueue) Pending() int {
 	p.mu.Lock()
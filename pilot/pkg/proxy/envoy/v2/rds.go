@@ -22,12 +22,16 @@ import (
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/grpcgen"
 	"istio.io/istio/pilot/pkg/networking/util"
 )
 
 func (s *DiscoveryServer) pushRoute(con *XdsConnection, push *model.PushContext, version string) error {
 	pushStart := time.Now()
-	rawRoutes := s.generateRawRoutes(con, push)
+	var rawRoutes []*xdsapi.RouteConfiguration
+	s.generationWorkers.run(func() {
+		rawRoutes = s.generateRawRoutes(con, push)
+	})
 	if s.DebugConfigs {
 		for _, r := range rawRoutes {
 			con.RouteConfigs[r.Name] = r
@@ -53,7 +57,12 @@ func (s *DiscoveryServer) pushRoute(con *XdsConnection, push *model.PushContext,
 }
 
 func (s *DiscoveryServer) generateRawRoutes(con *XdsConnection, push *model.PushContext) []*xdsapi.RouteConfiguration {
-	rawRoutes := s.ConfigGenerator.BuildHTTPRoutes(s.Env, con.node, push, con.Routes)
+	var rawRoutes []*xdsapi.RouteConfiguration
+	if con.node.IsProxylessGRPC() {
+		rawRoutes = grpcgen.BuildHTTPRoutes(s.ConfigGenerator, s.Env, con.node, push, con.Routes)
+	} else {
+		rawRoutes = s.ConfigGenerator.BuildHTTPRoutes(s.Env, con.node, push, con.Routes)
+	}
 	// Now validate each route
 	for _, r := range rawRoutes {
 		if err := r.Validate(); err != nil {
@@ -0,0 +1,55 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// readinessCheck tells whether a component required to serve the proxies is ready.
+type readinessCheck struct {
+	name  string
+	ready func() bool
+}
+
+// AddReadinessCheck gates the readiness probe on a component required to serve the proxies, e.g. the sync of
+// the caches of the service registries, so that the proxies connecting early do not get a partial config.
+func (s *DiscoveryServer) AddReadinessCheck(name string, ready func() bool) {
+	s.readinessMutex.Lock()
+	defer s.readinessMutex.Unlock()
+	s.readinessChecks = append(s.readinessChecks, readinessCheck{name: name, ready: ready})
+}
+
+// ready serves the readiness probe. Pilot is ready once all the readiness checks pass and the push context,
+// built from the synced caches, is initialized: the first proxies then get their config without waiting.
+func (s *DiscoveryServer) ready(w http.ResponseWriter, req *http.Request) {
+	s.readinessMutex.RLock()
+	checks := s.readinessChecks
+	s.readinessMutex.RUnlock()
+
+	for _, check := range checks {
+		if !check.ready() {
+			http.Error(w, fmt.Sprintf("%s not ready", check.name), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	// InitContext returns immediately if the context was already initialized.
+	if err := s.globalPushContext().InitContext(s.Env, nil, nil); err != nil {
+		http.Error(w, fmt.Sprintf("push context not ready: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
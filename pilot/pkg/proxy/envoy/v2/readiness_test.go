@@ -0,0 +1,63 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func TestReady(t *testing.T) {
+	m := mesh.DefaultMeshConfig()
+	env := &model.Environment{
+		Mesh:             &m,
+		IstioConfigStore: model.MakeIstioStore(memory.Make(schemas.Istio)),
+		ServiceDiscovery: aggregate.NewController(),
+		PushContext:      model.NewPushContext(),
+	}
+	s := NewDiscoveryServer(env, nil)
+
+	registriesSynced, configSynced := false, false
+	s.AddReadinessCheck("service registries", func() bool { return registriesSynced })
+	s.AddReadinessCheck("config stores", func() bool { return configSynced })
+
+	ready := func() int {
+		rec := httptest.NewRecorder()
+		s.ready(rec, httptest.NewRequest("GET", "/ready", nil))
+		return rec.Code
+	}
+
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Fatalf("got code %d before the sync, want %d", code, http.StatusServiceUnavailable)
+	}
+	registriesSynced = true
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Fatalf("got code %d before the sync of the config, want %d", code, http.StatusServiceUnavailable)
+	}
+	configSynced = true
+	if code := ready(); code != http.StatusOK {
+		t.Fatalf("got code %d after the sync, want %d", code, http.StatusOK)
+	}
+	if push := s.globalPushContext(); push.Env != env {
+		t.Error("push context not initialized when ready")
+	}
+}
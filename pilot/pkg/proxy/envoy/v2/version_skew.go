@@ -0,0 +1,166 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	istioversion "istio.io/pkg/version"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+const unknownVersion = "unknown"
+
+var (
+	// pilotVersion is the version of Pilot the versions of the proxies are compared to.
+	pilotVersion = model.ParseIstioVersion(istioversion.Info.Version)
+
+	// adsClientVersions counts the connected proxies per version, protected by adsClientsMutex.
+	adsClientVersions = map[string]int{}
+)
+
+// VersionSkewReport is the skew between the version of Pilot and the versions of the proxies connected to it.
+type VersionSkewReport struct {
+	PilotVersion string `json:"pilot_version"`
+	// MaxSkew is the number of minor versions the proxies can lag behind Pilot.
+	MaxSkew  int            `json:"max_skew"`
+	Versions []ProxyVersion `json:"versions"`
+	// Unsupported lists the proxies lagging more than MaxSkew minor versions behind Pilot.
+	Unsupported []string `json:"unsupported,omitempty"`
+}
+
+// ProxyVersion is a version of the proxies connected to Pilot.
+type ProxyVersion struct {
+	Version   string `json:"version"`
+	Proxies   int    `json:"proxies"`
+	Supported bool   `json:"supported"`
+}
+
+// knownVersion returns whether the version was parsed from a release version, unlike the versions of
+// development builds or missing versions.
+func knownVersion(v *model.IstioVersion) bool {
+	return v != nil && *v != *model.MaxIstioVersion
+}
+
+// proxyVersion returns the version of the proxy reported in its metadata.
+func proxyVersion(proxy *model.Proxy) string {
+	if !knownVersion(proxy.IstioVersion) {
+		return unknownVersion
+	}
+	return proxy.IstioVersion.String()
+}
+
+// unsupportedVersion returns whether the version lags more than PILOT_MAX_PROXY_VERSION_SKEW minor versions behind
+// Pilot. Unknown versions, e.g. of development builds, are supported.
+func unsupportedVersion(v *model.IstioVersion) bool {
+	if !knownVersion(pilotVersion) || !knownVersion(v) {
+		return false
+	}
+	if v.Major != pilotVersion.Major {
+		return v.Major < pilotVersion.Major
+	}
+	return pilotVersion.Minor-v.Minor > features.MaxProxyVersionSkew
+}
+
+// checkProxyVersion warns about the proxies lagging too far behind Pilot, and refuses them with
+// PILOT_REJECT_UNSUPPORTED_PROXIES.
+func checkProxyVersion(proxy *model.Proxy) error {
+	if !unsupportedVersion(proxy.IstioVersion) {
+		return nil
+	}
+	if features.RejectUnsupportedProxies {
+		unsupportedProxyRejects.Increment()
+		return fmt.Errorf("proxy version %s is more than %d minor versions behind Pilot %s",
+			proxyVersion(proxy), features.MaxProxyVersionSkew, pilotVersion)
+	}
+	adsLog.Warnf("ADS: proxy %s version %s is more than %d minor versions behind Pilot %s",
+		proxy.ID, proxyVersion(proxy), features.MaxProxyVersionSkew, pilotVersion)
+	return nil
+}
+
+// recordProxyVersion updates the number of connected proxies of the version of the proxy. The caller must hold
+// adsClientsMutex.
+func recordProxyVersion(proxy *model.Proxy, delta int) {
+	v := proxyVersion(proxy)
+	adsClientVersions[v] += delta
+	xdsClientVersions.With(versionTag.Value(v)).Record(float64(adsClientVersions[v]))
+	if adsClientVersions[v] <= 0 {
+		delete(adsClientVersions, v)
+	}
+}
+
+// versionSkew reports the versions of the proxies connected to Pilot, and the ones it does not support.
+func versionSkew() *VersionSkewReport {
+	out := &VersionSkewReport{
+		PilotVersion: unknownVersion,
+		MaxSkew:      features.MaxProxyVersionSkew,
+		Versions:     []ProxyVersion{},
+	}
+	if knownVersion(pilotVersion) {
+		out.PilotVersion = pilotVersion.String()
+	}
+
+	versions := map[string]*ProxyVersion{}
+	parsed := map[string]*model.IstioVersion{}
+	adsClientsMutex.RLock()
+	for _, con := range adsClients {
+		con.mu.RLock()
+		node := con.node
+		con.mu.RUnlock()
+		if node == nil {
+			continue
+		}
+		v := proxyVersion(node)
+		if versions[v] == nil {
+			versions[v] = &ProxyVersion{Version: v, Supported: !unsupportedVersion(node.IstioVersion)}
+			parsed[v] = model.MaxIstioVersion
+			if knownVersion(node.IstioVersion) {
+				parsed[v] = node.IstioVersion
+			}
+		}
+		versions[v].Proxies++
+		if unsupportedVersion(node.IstioVersion) {
+			out.Unsupported = append(out.Unsupported, node.ID)
+		}
+	}
+	adsClientsMutex.RUnlock()
+
+	for _, v := range versions {
+		out.Versions = append(out.Versions, *v)
+	}
+	// the oldest versions first, and the unknown ones last
+	sort.Slice(out.Versions, func(i, j int) bool {
+		return parsed[out.Versions[i].Version].Compare(parsed[out.Versions[j].Version]) < 0
+	})
+	sort.Strings(out.Unsupported)
+	return out
+}
+
+// versionSkewz reports the skew between the version of Pilot and the versions of the proxies connected to it.
+func versionSkewz(w http.ResponseWriter, _ *http.Request) {
+	out, err := json.MarshalIndent(versionSkew(), "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal version skew: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
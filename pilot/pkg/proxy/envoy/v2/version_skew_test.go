@@ -0,0 +1,114 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestUnsupportedVersion(t *testing.T) {
+	defaultVersion := pilotVersion
+	defer func() { pilotVersion = defaultVersion }()
+
+	cases := []struct {
+		name        string
+		pilot       string
+		proxy       string
+		unsupported bool
+	}{
+		{"same version", "1.5.0", "1.5.2", false},
+		{"newer proxy", "1.5.0", "1.6.0", false},
+		{"max skew", "1.5.0", "1.3.1", false},
+		{"too old", "1.5.0", "1.2.9", true},
+		{"older major", "2.0.0", "1.9.0", true},
+		{"unknown proxy", "1.5.0", "", false},
+		{"development proxy", "1.5.0", "master-20191120", false},
+		{"unknown pilot", "unknown", "1.0.0", false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			pilotVersion = model.ParseIstioVersion(tt.pilot)
+			if got := unsupportedVersion(model.ParseIstioVersion(tt.proxy)); got != tt.unsupported {
+				t.Errorf("got unsupported %v, want %v", got, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestCheckProxyVersion(t *testing.T) {
+	defaultVersion, reject := pilotVersion, features.RejectUnsupportedProxies
+	defer func() { pilotVersion, features.RejectUnsupportedProxies = defaultVersion, reject }()
+	pilotVersion = model.ParseIstioVersion("1.5.0")
+
+	proxy := &model.Proxy{ID: "old.default", IstioVersion: model.ParseIstioVersion("1.1.0")}
+	features.RejectUnsupportedProxies = false
+	if err := checkProxyVersion(proxy); err != nil {
+		t.Errorf("expected the proxy to only be warned about, got %v", err)
+	}
+	features.RejectUnsupportedProxies = true
+	if err := checkProxyVersion(proxy); err == nil {
+		t.Error("expected the proxy to be refused")
+	}
+	proxy.IstioVersion = model.ParseIstioVersion("1.4.0")
+	if err := checkProxyVersion(proxy); err != nil {
+		t.Errorf("expected the proxy to be accepted, got %v", err)
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	defaultVersion := pilotVersion
+	defer func() { pilotVersion = defaultVersion }()
+	pilotVersion = model.ParseIstioVersion("1.10.0")
+
+	cons := map[string]*XdsConnection{
+		"skew-1": {node: &model.Proxy{ID: "a.default", IstioVersion: model.ParseIstioVersion("1.10.0")}},
+		"skew-2": {node: &model.Proxy{ID: "b.default", IstioVersion: model.ParseIstioVersion("1.9.1")}},
+		"skew-3": {node: &model.Proxy{ID: "c.default", IstioVersion: model.ParseIstioVersion("1.9.1")}},
+		"skew-4": {node: &model.Proxy{ID: "d.default", IstioVersion: model.ParseIstioVersion("1.6.0")}},
+		"skew-5": {node: &model.Proxy{ID: "e.default", IstioVersion: model.ParseIstioVersion("")}},
+		"skew-6": {},
+	}
+	adsClientsMutex.Lock()
+	for id, con := range cons {
+		adsClients[id] = con
+	}
+	adsClientsMutex.Unlock()
+	defer func() {
+		adsClientsMutex.Lock()
+		for id := range cons {
+			delete(adsClients, id)
+		}
+		adsClientsMutex.Unlock()
+	}()
+
+	expected := &VersionSkewReport{
+		PilotVersion: "1.10.0",
+		MaxSkew:      features.MaxProxyVersionSkew,
+		Versions: []ProxyVersion{
+			{Version: "1.6.0", Proxies: 1, Supported: false},
+			{Version: "1.9.1", Proxies: 2, Supported: true},
+			{Version: "1.10.0", Proxies: 1, Supported: true},
+			{Version: unknownVersion, Proxies: 1, Supported: true},
+		},
+		Unsupported: []string{"d.default"},
+	}
+	if got := versionSkew(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("got version skew %+v, want %+v", got, expected)
+	}
+}
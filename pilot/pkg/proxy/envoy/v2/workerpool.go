@@ -0,0 +1,54 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"runtime"
+	"time"
+)
+
+// workerPool bounds the number of proxies whose config is generated concurrently. The pushes of up to
+// PILOT_PUSH_THROTTLE proxies are in flight at once, but generating their config is CPU bound: running
+// more generations than there are CPUs only slows all of them down.
+//
+// A push takes a worker for the generation of each type of config, and releases it while sending the
+// config: a proxy with a large config or a slow connection does not hold a worker for its whole push,
+// and the proxies waiting for a worker are granted one in the order they asked for it.
+type workerPool struct {
+	workers chan struct{}
+}
+
+// newWorkerPool returns a pool of the given number of workers, or of the number of CPUs usable by
+// the process if size is not positive.
+func newWorkerPool(size int) *workerPool {
+	if size <= 0 {
+		size = runtime.GOMAXPROCS(0)
+	}
+	return &workerPool{workers: make(chan struct{}, size)}
+}
+
+// run runs the job once a worker is available. The job runs on the calling goroutine, which holds the
+// worker until the job returns.
+func (p *workerPool) run(job func()) {
+	if p == nil {
+		job()
+		return
+	}
+	start := time.Now()
+	p.workers <- struct{}{}
+	defer func() { <-p.workers }()
+	generationWaitTime.Record(time.Since(start).Seconds())
+	job()
+}
@@ -0,0 +1,63 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	if got := cap(newWorkerPool(0).workers); got != runtime.GOMAXPROCS(0) {
+		t.Errorf("got %d workers by default, want the number of CPUs %d", got, runtime.GOMAXPROCS(0))
+	}
+
+	const size, jobs = 3, 20
+	p := newWorkerPool(size)
+	var mutex sync.Mutex
+	running, maxRunning, done := 0, 0, 0
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.run(func() {
+				mutex.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mutex.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				running--
+				done++
+				mutex.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+
+	if done != jobs {
+		t.Errorf("got %d jobs done, want %d", done, jobs)
+	}
+	if maxRunning > size {
+		t.Errorf("got %d jobs running concurrently, want at most %d", maxRunning, size)
+	}
+}
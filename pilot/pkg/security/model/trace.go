@@ -15,21 +15,25 @@
 package model
 
 import (
+	"sync"
+
 	"istio.io/istio/pilot/pkg/features"
 )
 
 // Default trace sampling, if not provided in env var.
 const traceSamplingDefault = 100.0
 
-var (
-	traceSampling = getTraceSampling()
-)
+// traceSamplingWarning reports an out of range trace sampling only once, as it is read for every listener.
+var traceSamplingWarning sync.Once
 
 // Return trace sampling if set correctly, or default if not.
+// The trace sampling can be changed at runtime, so it is read again on each call.
 func getTraceSampling() float64 {
-	f := features.TraceSampling
+	f := features.TraceSampling.Get()
 	if f < 0.0 || f > 100.0 {
-		log.Warnf("PILOT_TRACE_SAMPLING out of range: %v", f)
+		traceSamplingWarning.Do(func() {
+			log.Warnf("PILOT_TRACE_SAMPLING out of range: %v", f)
+		})
 		return traceSamplingDefault
 	}
 	return f
@@ -46,7 +50,7 @@ type TraceConfig struct {
 func GetTraceConfig() TraceConfig {
 	return TraceConfig{
 		ClientSampling:  100.0,
-		RandomSampling:  traceSampling,
+		RandomSampling:  getTraceSampling(),
 		OverallSampling: 100.0,
 	}
 }
@@ -48,6 +48,12 @@ var (
 type Controller struct {
 	registries []Registry
 	storeLock  sync.RWMutex
+
+	// The handlers appended to the aggregate and the stop channel it runs with, for the registries
+	// attached afterwards.
+	serviceHandlers  []func(*model.Service, model.Event)
+	instanceHandlers []func(*model.ServiceInstance, model.Event)
+	stop             <-chan struct{}
 }
 
 // NewController creates a new Aggregate controller
@@ -68,6 +74,31 @@ func (c *Controller) AddRegistry(registry Registry) {
 	c.registries = registries
 }
 
+// AttachRegistry adds a registry to the aggregated controller, possibly already running. Unlike with
+// AddRegistry, the handlers appended to the aggregated controller are appended to the registry, and
+// it is run with the aggregated controller.
+func (c *Controller) AttachRegistry(registry Registry) error {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+
+	for _, f := range c.serviceHandlers {
+		if err := registry.AppendServiceHandler(f); err != nil {
+			return err
+		}
+	}
+	for _, f := range c.instanceHandlers {
+		if err := registry.AppendInstanceHandler(f); err != nil {
+			return err
+		}
+	}
+	c.registries = append(c.registries, registry)
+	if c.stop != nil {
+		go registry.Run(c.stop)
+	}
+	log.Infof("Registry %s has been attached.", registry.Name)
+	return nil
+}
+
 // DeleteRegistry deletes specified registry from the aggregated controller
 func (c *Controller) DeleteRegistry(clusterID string) {
 	c.storeLock.Lock()
@@ -96,6 +127,18 @@ func (c *Controller) GetRegistries() []Registry {
 	return c.registries
 }
 
+// HasSynced returns true once the caches of all the registries have synced, for the registries
+// with caches, e.g. the Kubernetes informers.
+func (c *Controller) HasSynced() bool {
+	for _, r := range c.GetRegistries() {
+		if cache, ok := r.Controller.(interface{ HasSynced() bool }); ok && !cache.HasSynced() {
+			log.Debugf("registry %s of the cluster %s has not synced", r.Name, r.ClusterID)
+			return false
+		}
+	}
+	return true
+}
+
 // GetRegistryIndex returns the index of a registry
 func (c *Controller) GetRegistryIndex(clusterID string) (int, bool) {
 	for i, r := range c.registries {
@@ -111,6 +154,10 @@ func (c *Controller) Services() ([]*model.Service, error) {
 	// smap is a map of hostname (string) to service, used to identify services that
 	// are installed in multiple clusters.
 	smap := make(map[host.Name]*model.Service)
+	// sindex is the position of the services installed in multiple clusters in the result,
+	// and whether they were already replaced by a merged copy.
+	sindex := make(map[host.Name]int)
+	merged := make(map[host.Name]bool)
 
 	services := make([]*model.Service, 0)
 	var errs error
@@ -141,24 +188,21 @@ func (c *Controller) Services() ([]*model.Service, error) {
 					// the order is less clear.
 					sp = s
 					smap[s.Hostname] = sp
+					sindex[s.Hostname] = len(services)
 					services = append(services, sp)
+				} else if !merged[s.Hostname] {
+					// The service is installed in multiple clusters. The ports of the other clusters
+					// are merged into a copy, so the service of the first cluster is left unmodified.
+					first := sp
+					first.Mutex.RLock()
+					sp = first.DeepCopy()
+					first.Mutex.RUnlock()
+					smap[s.Hostname] = sp
+					services[sindex[s.Hostname]] = sp
+					merged[s.Hostname] = true
 				}
 
-				sp.Mutex.Lock()
-				// If the registry has a cluster ID, keep track of the cluster and the
-				// local address inside the cluster.
-				if sp.ClusterVIPs == nil {
-					sp.ClusterVIPs = make(map[string]string)
-				}
-				sp.ClusterVIPs[r.ClusterID] = s.Address
-
-				if s.Attributes.ClusterExternalAddresses != nil && len(s.Attributes.ClusterExternalAddresses[r.ClusterID]) > 0 {
-					if sp.Attributes.ClusterExternalAddresses == nil {
-						sp.Attributes.ClusterExternalAddresses = make(map[string][]string)
-					}
-					sp.Attributes.ClusterExternalAddresses[r.ClusterID] = s.Attributes.ClusterExternalAddresses[r.ClusterID]
-				}
-				sp.Mutex.Unlock()
+				mergeService(sp, s, r.ClusterID)
 			}
 		}
 		clusterAddressesMutex.Unlock()
@@ -166,22 +210,103 @@ func (c *Controller) Services() ([]*model.Service, error) {
 	return services, errs
 }
 
-// GetService retrieves a service by hostname if exists
+// GetService retrieves a service by hostname if exists. A service installed in multiple
+// clusters is merged from all of them.
 func (c *Controller) GetService(hostname host.Name) (*model.Service, error) {
 	var errs error
+	var out *model.Service
+	var outCluster string
+	merged := false
 	for _, r := range c.GetRegistries() {
 		service, err := r.GetService(hostname)
 		if err != nil {
 			errs = multierror.Append(errs, err)
-		} else if service != nil {
-			if errs != nil {
-				log.Warnf("GetService() found match but encountered an error: %v", errs)
+			continue
+		}
+		if service == nil {
+			continue
+		}
+		if out == nil {
+			out, outCluster = service, r.ClusterID
+			if r.ClusterID == "" {
+				break
 			}
-			return service, nil
+			continue
+		}
+		if r.ClusterID == "" {
+			continue
 		}
 
+		// The service is installed in multiple clusters, merge them into a copy.
+		clusterAddressesMutex.Lock()
+		if !merged {
+			first := out
+			first.Mutex.RLock()
+			out = first.DeepCopy()
+			first.Mutex.RUnlock()
+			mergeService(out, first, outCluster)
+			merged = true
+		}
+		mergeService(out, service, r.ClusterID)
+		clusterAddressesMutex.Unlock()
 	}
-	return nil, errs
+	if out == nil {
+		return nil, errs
+	}
+	if errs != nil {
+		log.Warnf("GetService() found match but encountered an error: %v", errs)
+	}
+	return out, nil
+}
+
+// mergeService merges the cluster specific attributes of src, the service installed in the given
+// cluster, into dst. Ports missing in dst are added so that the service exposes the ports of all clusters.
+func mergeService(dst, src *model.Service, clusterID string) {
+	if dst != src {
+		src.Mutex.RLock()
+		defer src.Mutex.RUnlock()
+	}
+	dst.Mutex.Lock()
+	defer dst.Mutex.Unlock()
+
+	// If the registry has a cluster ID, keep track of the cluster and the
+	// local address inside the cluster.
+	if dst.ClusterVIPs == nil {
+		dst.ClusterVIPs = make(map[string]string)
+	}
+	dst.ClusterVIPs[clusterID] = src.Address
+
+	if src.Attributes.ClusterExternalAddresses != nil && len(src.Attributes.ClusterExternalAddresses[clusterID]) > 0 {
+		if dst.Attributes.ClusterExternalAddresses == nil {
+			dst.Attributes.ClusterExternalAddresses = make(map[string][]string)
+		}
+		dst.Attributes.ClusterExternalAddresses[clusterID] = src.Attributes.ClusterExternalAddresses[clusterID]
+	}
+
+	if dst == src {
+		return
+	}
+	for _, port := range src.Ports {
+		if _, found := dst.Ports.Get(port.Name); !found {
+			if _, found := dst.Ports.GetByPort(port.Port); !found {
+				dst.Ports = append(dst.Ports, port)
+			}
+		}
+	}
+	for _, sa := range src.ServiceAccounts {
+		if !containsString(dst.ServiceAccounts, sa) {
+			dst.ServiceAccounts = append(dst.ServiceAccounts, sa)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
 
 // ManagementPorts retrieves set of health check ports by instance IP
@@ -284,8 +409,12 @@ func (c *Controller) GetProxyWorkloadLabels(proxy *model.Proxy) (labels.Collecti
 
 // Run starts all the controllers
 func (c *Controller) Run(stop <-chan struct{}) {
+	c.storeLock.Lock()
+	c.stop = stop
+	registries := c.registries
+	c.storeLock.Unlock()
 
-	for _, r := range c.GetRegistries() {
+	for _, r := range registries {
 		go r.Run(stop)
 	}
 
@@ -295,7 +424,11 @@ func (c *Controller) Run(stop <-chan struct{}) {
 
 // AppendServiceHandler implements a service catalog operation
 func (c *Controller) AppendServiceHandler(f func(*model.Service, model.Event)) error {
-	for _, r := range c.GetRegistries() {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+
+	c.serviceHandlers = append(c.serviceHandlers, f)
+	for _, r := range c.registries {
 		if err := r.AppendServiceHandler(f); err != nil {
 			log.Infof("Fail to append service handler to adapter %s", r.Name)
 			return err
@@ -306,7 +439,11 @@ func (c *Controller) AppendServiceHandler(f func(*model.Service, model.Event)) e
 
 // AppendInstanceHandler implements a service instance catalog operation
 func (c *Controller) AppendInstanceHandler(f func(*model.ServiceInstance, model.Event)) error {
-	for _, r := range c.GetRegistries() {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+
+	c.instanceHandlers = append(c.instanceHandlers, f)
+	for _, r := range c.registries {
 		if err := r.AppendInstanceHandler(f); err != nil {
 			log.Infof("Fail to append instance handler to adapter %s", r.Name)
 			return err
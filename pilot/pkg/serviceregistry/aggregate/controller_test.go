@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
@@ -165,6 +166,65 @@ func TestServicesForMultiCluster(t *testing.T) {
 	t.Logf("Return service ClusterVIPs match ground truth")
 }
 
+func TestMergeServicesForMultiCluster(t *testing.T) {
+	hostname := host.Name("hello.default.svc.cluster.local")
+	svc1 := &model.Service{
+		Hostname:        hostname,
+		Address:         "10.1.1.0",
+		Ports:           model.PortList{{Name: "http", Port: 80, Protocol: protocol.HTTP}},
+		ServiceAccounts: []string{"spiffe://cluster.local/ns/default/sa/hello"},
+	}
+	svc2 := &model.Service{
+		Hostname: hostname,
+		Address:  "10.1.2.0",
+		Ports: model.PortList{
+			{Name: "http", Port: 80, Protocol: protocol.HTTP},
+			{Name: "grpc", Port: 7070, Protocol: protocol.GRPC},
+		},
+		ServiceAccounts: []string{"spiffe://cluster.local/ns/default/sa/hello-v2"},
+	}
+
+	ctls := NewController()
+	for i, svc := range []*model.Service{svc1, svc2} {
+		ctls.AddRegistry(Registry{
+			Name:             serviceregistry.ServiceRegistry("mockAdapter"),
+			ClusterID:        fmt.Sprintf("cluster-%d", i+1),
+			ServiceDiscovery: memory.NewDiscovery(map[host.Name]*model.Service{hostname: svc}, 1),
+			Controller:       &MockController{},
+		})
+	}
+
+	services, err := ctls.Services()
+	if err != nil {
+		t.Fatalf("Services() encountered unexpected error: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected a single merged service, got %v", services)
+	}
+	get, err := ctls.GetService(hostname)
+	if err != nil {
+		t.Fatalf("GetService() encountered unexpected error: %v", err)
+	}
+
+	for _, svc := range []*model.Service{services[0], get} {
+		expectedVIPs := map[string]string{"cluster-1": "10.1.1.0", "cluster-2": "10.1.2.0"}
+		if !reflect.DeepEqual(svc.ClusterVIPs, expectedVIPs) {
+			t.Errorf("ClusterVIPs actual %v, expected %v", svc.ClusterVIPs, expectedVIPs)
+		}
+		if len(svc.Ports) != 2 {
+			t.Errorf("Expected the ports of both clusters, got %v", svc.Ports)
+		}
+		if len(svc.ServiceAccounts) != 2 {
+			t.Errorf("Expected the service accounts of both clusters, got %v", svc.ServiceAccounts)
+		}
+	}
+
+	// the services of the registries must not be modified by the merge
+	if len(svc1.Ports) != 1 || len(svc1.ServiceAccounts) != 1 {
+		t.Errorf("Service of cluster-1 was modified: %v %v", svc1.Ports, svc1.ServiceAccounts)
+	}
+}
+
 func TestServices(t *testing.T) {
 	aggregateCtl := buildMockController()
 	// List Services from aggregate controller
@@ -501,6 +561,64 @@ func TestAddRegistry(t *testing.T) {
 	}
 }
 
+// recordingController records the handlers appended to it and whether it runs.
+type recordingController struct {
+	serviceHandlers  int
+	instanceHandlers int
+	running          chan struct{}
+}
+
+func (c *recordingController) AppendServiceHandler(func(*model.Service, model.Event)) error {
+	c.serviceHandlers++
+	return nil
+}
+
+func (c *recordingController) AppendInstanceHandler(func(*model.ServiceInstance, model.Event)) error {
+	c.instanceHandlers++
+	return nil
+}
+
+func (c *recordingController) Run(<-chan struct{}) {
+	close(c.running)
+}
+
+func TestAttachRegistry(t *testing.T) {
+	ctrl := NewController()
+	_ = ctrl.AppendServiceHandler(func(*model.Service, model.Event) {})
+	_ = ctrl.AppendInstanceHandler(func(*model.ServiceInstance, model.Event) {})
+	stop := make(chan struct{})
+	defer close(stop)
+	go ctrl.Run(stop)
+
+	attached := &recordingController{running: make(chan struct{})}
+	// wait for the aggregate to run, so that the registry is run when attached
+	for {
+		ctrl.storeLock.RLock()
+		running := ctrl.stop != nil
+		ctrl.storeLock.RUnlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := ctrl.AttachRegistry(Registry{Name: "attached", Controller: attached}); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-attached.running:
+	case <-time.After(5 * time.Second):
+		t.Fatal("attached registry not run")
+	}
+	if attached.serviceHandlers != 1 || attached.instanceHandlers != 1 {
+		t.Errorf("got %d service and %d instance handlers, want 1 of each",
+			attached.serviceHandlers, attached.instanceHandlers)
+	}
+	if l := len(ctrl.GetRegistries()); l != 1 {
+		t.Errorf("got %d registries, want 1", l)
+	}
+}
+
 func TestDeleteRegistry(t *testing.T) {
 	registries := []Registry{
 		{
@@ -548,3 +666,31 @@ func TestGetRegistries(t *testing.T) {
 		}
 	}
 }
+
+// syncingController is a mock Controller with a cache
+type syncingController struct {
+	MockController
+	synced bool
+}
+
+func (c *syncingController) HasSynced() bool {
+	return c.synced
+}
+
+func TestHasSynced(t *testing.T) {
+	syncing := &syncingController{}
+	ctrl := buildMockController()
+	ctrl.AddRegistry(Registry{
+		Name:       "syncing",
+		ClusterID:  "cluster1",
+		Controller: syncing,
+	})
+
+	if ctrl.HasSynced() {
+		t.Fatal("HasSynced() = true before the registry synced")
+	}
+	syncing.synced = true
+	if !ctrl.HasSynced() {
+		t.Fatal("HasSynced() = false after all the registries synced")
+	}
+}
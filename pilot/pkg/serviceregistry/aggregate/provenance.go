@@ -0,0 +1,208 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+)
+
+// Merge decisions of the aggregate registry for a hostname.
+const (
+	// MergeNone is the merge decision of a hostname defined by a single registry.
+	MergeNone = "none"
+	// MergeClusters is the merge decision of a hostname defined by several Kubernetes clusters: the
+	// service of the first cluster provides the defaults, and the ports of the others are added to it
+	// unless their name or number is already taken.
+	MergeClusters = "merged-clusters"
+	// MergeDuplicated is the merge decision of a hostname defined by several registries that are not
+	// merged, e.g. Consul and ServiceEntries: each definition is returned as a separate service.
+	MergeDuplicated = "duplicated"
+)
+
+// ServiceSource is the definition of a hostname by one of the registries.
+type ServiceSource struct {
+	Registry     string   `json:"registry"`
+	ClusterID    string   `json:"clusterID,omitempty"`
+	Namespace    string   `json:"namespace,omitempty"`
+	Address      string   `json:"address,omitempty"`
+	Ports        []string `json:"ports"`
+	Resolution   string   `json:"resolution"`
+	MeshExternal bool     `json:"meshExternal,omitempty"`
+	// IgnoredPorts are the ports dropped when merging this definition into the one of the first cluster.
+	IgnoredPorts []string `json:"ignoredPorts,omitempty"`
+}
+
+// ServiceProvenance is a hostname of the aggregate registry, with the registries defining it.
+type ServiceProvenance struct {
+	Hostname string          `json:"hostname"`
+	Sources  []ServiceSource `json:"sources"`
+	Merge    string          `json:"merge"`
+	// Conflicts describes how the definitions disagree.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+var resolutionNames = map[model.Resolution]string{
+	model.ClientSideLB: "ClientSideLB",
+	model.DNSLB:        "DNSLB",
+	model.Passthrough:  "Passthrough",
+}
+
+// Provenance lists the hostnames of all the registries, sorted, with the definition of each registry,
+// the way the aggregate registry merges them and their conflicts. It is meant to debug where the
+// services sent to the proxies come from.
+func (c *Controller) Provenance() ([]ServiceProvenance, error) {
+	byHost := make(map[host.Name]*ServiceProvenance)
+	// merged holds the ports of the services merged across clusters, by hostname
+	merged := make(map[host.Name]model.PortList)
+
+	var errs error
+	for _, r := range c.GetRegistries() {
+		svcs, err := r.Services()
+		if err != nil {
+			errs = multierror.Append(errs, err)
+			continue
+		}
+		for _, s := range svcs {
+			s.Mutex.RLock()
+			source := ServiceSource{
+				Registry:     string(r.Name),
+				ClusterID:    r.ClusterID,
+				Namespace:    s.Attributes.Namespace,
+				Address:      s.Address,
+				Ports:        formatPorts(s.Ports),
+				Resolution:   resolutionNames[s.Resolution],
+				MeshExternal: s.MeshExternal,
+			}
+			ports := s.Ports
+			s.Mutex.RUnlock()
+
+			p, f := byHost[s.Hostname]
+			if !f {
+				p = &ServiceProvenance{Hostname: string(s.Hostname)}
+				byHost[s.Hostname] = p
+			}
+			if r.ClusterID != "" {
+				if first, f := merged[s.Hostname]; f {
+					dst := append(model.PortList{}, first...)
+					for _, port := range ports {
+						if existing, found := dst.Get(port.Name); found {
+							if existing.Port != port.Port {
+								source.IgnoredPorts = append(source.IgnoredPorts, formatPort(port))
+							}
+							continue
+						}
+						if _, found := dst.GetByPort(port.Port); found {
+							source.IgnoredPorts = append(source.IgnoredPorts, formatPort(port))
+							continue
+						}
+						dst = append(dst, port)
+					}
+					merged[s.Hostname] = dst
+				} else {
+					merged[s.Hostname] = ports
+				}
+			}
+			p.Sources = append(p.Sources, source)
+		}
+	}
+
+	out := make([]ServiceProvenance, 0, len(byHost))
+	for _, p := range byHost {
+		p.Merge, p.Conflicts = mergeDecision(p.Sources)
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Hostname < out[j].Hostname
+	})
+	return out, errs
+}
+
+// mergeDecision returns how the aggregate registry combines the definitions of a hostname, and how
+// they conflict.
+func mergeDecision(sources []ServiceSource) (string, []string) {
+	var conflicts []string
+	clusters := 0
+	unmerged := make([]string, 0)
+	for _, s := range sources {
+		if s.ClusterID != "" {
+			clusters++
+		} else {
+			unmerged = append(unmerged, s.Registry)
+		}
+		if len(s.IgnoredPorts) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("ports %s of cluster %s conflict with the ports of the other clusters",
+				strings.Join(s.IgnoredPorts, ", "), s.ClusterID))
+		}
+	}
+
+	first := sources[0]
+	for _, s := range sources[1:] {
+		if s.Namespace != first.Namespace {
+			conflicts = append(conflicts, fmt.Sprintf("namespace %q of %s differs from namespace %q of %s",
+				s.Namespace, sourceName(s), first.Namespace, sourceName(first)))
+		}
+		if s.Resolution != first.Resolution {
+			conflicts = append(conflicts, fmt.Sprintf("resolution %s of %s differs from resolution %s of %s",
+				s.Resolution, sourceName(s), first.Resolution, sourceName(first)))
+		}
+		if s.MeshExternal != first.MeshExternal {
+			conflicts = append(conflicts, fmt.Sprintf("%s and %s disagree on whether the service is external to the mesh",
+				sourceName(s), sourceName(first)))
+		}
+	}
+
+	definitions := len(unmerged)
+	if clusters > 0 {
+		definitions++
+	}
+	switch {
+	case definitions > 1:
+		if clusters > 0 {
+			unmerged = append(unmerged, "the Kubernetes clusters")
+		}
+		conflicts = append(conflicts, fmt.Sprintf("defined separately by %s", strings.Join(unmerged, ", ")))
+		return MergeDuplicated, conflicts
+	case clusters > 1:
+		return MergeClusters, conflicts
+	default:
+		return MergeNone, conflicts
+	}
+}
+
+func sourceName(s ServiceSource) string {
+	if s.ClusterID != "" {
+		return fmt.Sprintf("%s cluster %s", s.Registry, s.ClusterID)
+	}
+	return s.Registry
+}
+
+func formatPorts(ports model.PortList) []string {
+	out := make([]string, 0, len(ports))
+	for _, port := range ports {
+		out = append(out, formatPort(port))
+	}
+	return out
+}
+
+func formatPort(port *model.Port) string {
+	return fmt.Sprintf("%s:%d/%s", port.Name, port.Port, port.Protocol)
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aggregate
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pilot/pkg/serviceregistry/memory"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/protocol"
+)
+
+func TestProvenance(t *testing.T) {
+	ctls := buildMockControllerForMultiCluster()
+
+	// the service of the second cluster reuses the number of a port of the first one
+	svc, _ := discovery2.GetService(memory.HelloService.Hostname)
+	svc.Ports = append(svc.Ports, &model.Port{Name: "grpc", Port: 90, Protocol: protocol.GRPC})
+
+	external := memory.MakeService("hello.default.svc.cluster.local", "10.1.3.0")
+	external.Resolution = model.DNSLB
+	ctls.AddRegistry(Registry{
+		Name: serviceregistry.ServiceRegistry("mockAdapter3"),
+		ServiceDiscovery: memory.NewDiscovery(map[host.Name]*model.Service{
+			external.Hostname: external,
+		}, 2),
+		Controller: &MockController{},
+	})
+
+	provenance, err := ctls.Provenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provenance) != 2 {
+		t.Fatalf("got %d hostnames, want 2: %v", len(provenance), provenance)
+	}
+
+	got := provenance[0]
+	if got.Hostname != "hello.default.svc.cluster.local" || got.Merge != MergeDuplicated {
+		t.Errorf("unexpected provenance %+v", got)
+	}
+	if len(got.Sources) != 3 || got.Sources[1].ClusterID != "cluster-2" || got.Sources[2].Address != "10.1.3.0" {
+		t.Errorf("unexpected sources %+v", got.Sources)
+	}
+	if want := []string{"grpc:90/GRPC"}; !reflect.DeepEqual(got.Sources[1].IgnoredPorts, want) {
+		t.Errorf("got ignored ports %v, want %v", got.Sources[1].IgnoredPorts, want)
+	}
+	if len(got.Conflicts) != 3 {
+		t.Errorf("expected the port, resolution and duplicate conflicts, got %v", got.Conflicts)
+	}
+
+	got = provenance[1]
+	if got.Hostname != string(memory.WorldService.Hostname) || got.Merge != MergeNone || len(got.Conflicts) != 0 {
+		t.Errorf("unexpected provenance %+v", got)
+	}
+}
+
+func TestProvenanceMergedClusters(t *testing.T) {
+	provenance, err := buildMockControllerForMultiCluster().Provenance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := provenance[0]
+	if got.Merge != MergeClusters || len(got.Conflicts) != 0 {
+		t.Errorf("unexpected provenance %+v", got)
+	}
+}
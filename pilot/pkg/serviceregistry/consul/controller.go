@@ -16,6 +16,8 @@ package consul
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
 	"sync"
 
 	"github.com/hashicorp/consul/api"
@@ -28,30 +30,58 @@ import (
 	"istio.io/istio/pkg/spiffe"
 )
 
+// Options stores the configurable attributes of a Consul controller.
+type Options struct {
+	// ServerURL is the address of the Consul agent
+	ServerURL string
+	// Namespace is the Consul Enterprise namespace the services are read
+	// from. The namespace of the ACL token is used if empty.
+	Namespace string
+	// PassingOnly drops the endpoints failing any of their health checks
+	PassingOnly bool
+}
+
 // Controller communicates with Consul and monitors for changes
 type Controller struct {
 	client           *api.Client
 	monitor          Monitor
+	passingOnly      bool
 	services         map[string]*model.Service //key hostname value service
 	servicesList     []*model.Service
 	serviceInstances map[string][]*model.ServiceInstance //key hostname value serviceInstance array
-	cacheMutex       sync.Mutex
-	initDone         bool
+	// endpoints is keyed by service name, then by endpoint key
+	endpoints  map[string]map[string]*api.CatalogService
+	cacheMutex sync.Mutex
+	initDone   bool
 }
 
 // NewController creates a new Consul controller
 func NewController(addr string) (*Controller, error) {
+	return NewControllerWithOptions(Options{ServerURL: addr})
+}
+
+// NewControllerWithOptions creates a new Consul controller with the given options
+func NewControllerWithOptions(options Options) (*Controller, error) {
 	conf := api.DefaultConfig()
-	conf.Address = addr
+	conf.Address = options.ServerURL
+	if options.Namespace != "" {
+		httpClient, err := api.NewHttpClient(conf.Transport, conf.TLSConfig)
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = &namespaceTransport{namespace: options.Namespace, base: httpClient.Transport}
+		conf.HttpClient = httpClient
+	}
 
 	client, err := api.NewClient(conf)
-	monitor := NewConsulMonitor(client)
+	monitor := NewConsulMonitor(client, options.PassingOnly)
 	controller := Controller{
-		monitor: monitor,
-		client:  client,
+		monitor:     monitor,
+		client:      client,
+		passingOnly: options.PassingOnly,
 	}
 
-	//Watch the change events to refresh local caches
+	//Watch the change events to update local caches
 	monitor.AppendServiceHandler(controller.ServiceChanged)
 	monitor.AppendInstanceHandler(controller.InstanceChanged)
 	return &controller, err
@@ -244,6 +274,7 @@ func (c *Controller) initCache() error {
 
 	c.services = make(map[string]*model.Service)
 	c.serviceInstances = make(map[string][]*model.ServiceInstance)
+	c.endpoints = make(map[string]map[string]*api.CatalogService)
 
 	// get all services from consul
 	consulServices, err := c.getServices()
@@ -257,20 +288,21 @@ func (c *Controller) initCache() error {
 		if err != nil {
 			return err
 		}
+		// Services without any endpoint, e.g. because none of them passes its
+		// health checks, are left out until one shows up.
+		if len(endpoints) == 0 {
+			continue
+		}
 		c.services[serviceName] = convertService(endpoints)
 
-		instances := make([]*model.ServiceInstance, len(endpoints))
-		for i, endpoint := range endpoints {
-			instances[i] = convertInstance(endpoint)
+		c.endpoints[serviceName] = make(map[string]*api.CatalogService, len(endpoints))
+		for _, endpoint := range endpoints {
+			c.endpoints[serviceName][endpointKey(endpoint)] = endpoint
 		}
-		c.serviceInstances[serviceName] = instances
-	}
-
-	c.servicesList = make([]*model.Service, 0, len(c.services))
-	for _, value := range c.services {
-		c.servicesList = append(c.servicesList, value)
+		c.updateServiceInstances(serviceName)
 	}
 
+	c.updateServicesList()
 	c.initDone = true
 	return nil
 }
@@ -287,7 +319,7 @@ func (c *Controller) getServices() (map[string][]string, error) {
 
 // nolint: unparam
 func (c *Controller) getCatalogService(name string, q *api.QueryOptions) ([]*api.CatalogService, error) {
-	endpoints, _, err := c.client.Catalog().Service(name, "", q)
+	endpoints, _, err := fetchEndpoints(c.client, name, c.passingOnly, q)
 	if err != nil {
 		log.Warnf("Could not retrieve service catalog from consul: %v", err)
 		return nil, err
@@ -296,18 +328,91 @@ func (c *Controller) getCatalogService(name string, q *api.QueryOptions) ([]*api
 	return endpoints, nil
 }
 
-func (c *Controller) refreshCache() {
-	c.cacheMutex.Lock()
-	defer c.cacheMutex.Unlock()
-	c.initDone = false
+func (c *Controller) updateServicesList() {
+	c.servicesList = make([]*model.Service, 0, len(c.services))
+	for _, value := range c.services {
+		c.servicesList = append(c.servicesList, value)
+	}
+}
+
+// updateServiceInstances rebuilds the instances of a service from its endpoints
+func (c *Controller) updateServiceInstances(name string) {
+	endpoints := c.endpoints[name]
+	if len(endpoints) == 0 {
+		delete(c.endpoints, name)
+		delete(c.serviceInstances, name)
+		return
+	}
+
+	keys := make([]string, 0, len(endpoints))
+	for key := range endpoints {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	instances := make([]*model.ServiceInstance, 0, len(keys))
+	for _, key := range keys {
+		instances = append(instances, convertInstance(endpoints[key]))
+	}
+	c.serviceInstances[name] = instances
 }
 
+// InstanceChanged updates the cached instances of the service of the changed endpoint
 func (c *Controller) InstanceChanged(instance *api.CatalogService, event model.Event) error {
-	c.refreshCache()
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	// The whole cache is loaded on the next access anyway
+	if !c.initDone {
+		return nil
+	}
+
+	name := instance.ServiceName
+	switch event {
+	case model.EventDelete:
+		delete(c.endpoints[name], endpointKey(instance))
+	default:
+		if c.endpoints[name] == nil {
+			c.endpoints[name] = make(map[string]*api.CatalogService)
+		}
+		c.endpoints[name][endpointKey(instance)] = instance
+	}
+	c.updateServiceInstances(name)
 	return nil
 }
 
+// ServiceChanged updates the cached service built from the given endpoints
 func (c *Controller) ServiceChanged(instances []*api.CatalogService, event model.Event) error {
-	c.refreshCache()
+	c.cacheMutex.Lock()
+	defer c.cacheMutex.Unlock()
+	// The whole cache is loaded on the next access anyway
+	if !c.initDone || len(instances) == 0 {
+		return nil
+	}
+
+	name := instances[0].ServiceName
+	switch event {
+	case model.EventDelete:
+		delete(c.services, name)
+		delete(c.endpoints, name)
+		delete(c.serviceInstances, name)
+	default:
+		c.services[name] = convertService(instances)
+	}
+	c.updateServicesList()
 	return nil
 }
+
+// namespaceTransport scopes the requests to the Consul API to a namespace.
+// The Consul API client in use predates the namespaces, so the namespace
+// is set as a query parameter of every request.
+type namespaceTransport struct {
+	namespace string
+	base      http.RoundTripper
+}
+
+func (t *namespaceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	query.Set("ns", t.namespace)
+	req.URL.RawQuery = query.Encode()
+	return t.base.RoundTrip(req)
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -40,6 +41,9 @@ type mockServer struct {
 	rating      []*api.CatalogService
 	lock        sync.Mutex
 	consulIndex int
+	// failing lists the service addresses failing their health checks
+	failing   map[string]bool
+	namespace string
 }
 
 func newServer() *mockServer {
@@ -107,10 +111,43 @@ func newServer() *mockServer {
 			"rating":      {"version|v1"},
 		},
 		consulIndex: 1,
+		failing:     map[string]bool{},
 	}
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/v1/catalog/services" {
+		m.lock.Lock()
+		m.namespace = r.URL.Query().Get("ns")
+		m.lock.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/v1/health/service/") {
+			m.lock.Lock()
+			endpoints := map[string][]*api.CatalogService{
+				"productpage": m.productpage,
+				"reviews":     m.reviews,
+				"rating":      m.rating,
+			}[strings.TrimPrefix(r.URL.Path, "/v1/health/service/")]
+			entries := make([]*api.ServiceEntry, 0, len(endpoints))
+			for _, endpoint := range endpoints {
+				if _, passing := r.URL.Query()["passing"]; passing && m.failing[endpoint.ServiceAddress] {
+					continue
+				}
+				entries = append(entries, &api.ServiceEntry{
+					Node: &api.Node{ID: endpoint.ID, Node: endpoint.Node, Address: endpoint.Address},
+					Service: &api.AgentService{
+						ID:      endpoint.ServiceID,
+						Service: endpoint.ServiceName,
+						Tags:    endpoint.ServiceTags,
+						Meta:    endpoint.ServiceMeta,
+						Port:    endpoint.ServicePort,
+						Address: endpoint.ServiceAddress,
+					},
+				})
+			}
+			data, _ := json.Marshal(&entries)
+			w.Header().Set("X-Consul-Index", strconv.Itoa(m.consulIndex))
+			m.lock.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintln(w, string(data))
+		} else if r.URL.Path == "/v1/catalog/services" {
 			m.lock.Lock()
 			data, _ := json.Marshal(&m.services)
 			w.Header().Set("X-Consul-Index", strconv.Itoa(m.consulIndex))
@@ -599,3 +636,93 @@ func TestGetInstanceByCacheAfterChanged(t *testing.T) {
 		}
 	}
 }
+
+func TestInstancesPassingOnly(t *testing.T) {
+	ts := newServer()
+	defer ts.server.Close()
+	ts.failing["172.19.0.6"] = true
+	controller, err := NewControllerWithOptions(Options{ServerURL: ts.server.URL, PassingOnly: true})
+	if err != nil {
+		t.Errorf("could not create Consul Controller: %v", err)
+	}
+	svc := &model.Service{
+		Hostname: serviceHostname("reviews"),
+		Attributes: model.ServiceAttributes{
+			Name:      "reviews",
+			Namespace: model.IstioDefaultConfigNamespace,
+		},
+	}
+
+	instances, err := controller.InstancesByPort(svc, 0, labels.Collection{})
+	if err != nil {
+		t.Errorf("client encountered error during Instances(): %v", err)
+	}
+	if len(instances) != 2 {
+		t.Errorf("Instances() returned wrong # of service instances => %q, want 2", len(instances))
+	}
+	for _, inst := range instances {
+		if inst.Endpoint.Address == "172.19.0.6" {
+			t.Errorf("Instances() returned the instance failing its health checks: %v", inst.Endpoint)
+		}
+	}
+}
+
+func TestInstanceChanged(t *testing.T) {
+	ts := newServer()
+	defer ts.server.Close()
+	controller, err := NewController(ts.server.URL)
+	if err != nil {
+		t.Errorf("could not create Consul Controller: %v", err)
+	}
+	if _, err := controller.Services(); err != nil {
+		t.Fatalf("client encountered error during Services(): %v", err)
+	}
+	ts.server.Close()
+
+	svc := &model.Service{Hostname: serviceHostname("reviews")}
+	added := &api.CatalogService{
+		Node:           "istio-node",
+		ServiceID:      "reviews-id",
+		ServiceName:    "reviews",
+		ServiceTags:    []string{"version|v4"},
+		ServiceAddress: "172.19.0.9",
+		ServicePort:    9081,
+	}
+	_ = controller.InstanceChanged(added, model.EventAdd)
+	instances, err := controller.InstancesByPort(svc, 9081, labels.Collection{{"version": "v4"}})
+	if err != nil || len(instances) != 1 {
+		t.Errorf("InstancesByPort() => %v, %v, want the added instance", instances, err)
+	}
+
+	_ = controller.InstanceChanged(added, model.EventDelete)
+	instances, err = controller.InstancesByPort(svc, 0, labels.Collection{})
+	if err != nil || len(instances) != 3 {
+		t.Errorf("InstancesByPort() => %v, %v, want the 3 original instances", instances, err)
+	}
+
+	_ = controller.ServiceChanged(ts.reviews, model.EventDelete)
+	if service, _ := controller.GetService(serviceHostname("reviews")); service != nil {
+		t.Errorf("GetService() => %v, want the deleted service to be gone", service)
+	}
+	if services, _ := controller.Services(); len(services) != 2 {
+		t.Errorf("Services() returned wrong # of services: %d, want 2", len(services))
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	ts := newServer()
+	defer ts.server.Close()
+	controller, err := NewControllerWithOptions(Options{ServerURL: ts.server.URL, Namespace: "team-a"})
+	if err != nil {
+		t.Errorf("could not create Consul Controller: %v", err)
+	}
+
+	if _, err := controller.Services(); err != nil {
+		t.Errorf("client encountered error during Services(): %v", err)
+	}
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if ts.namespace != "team-a" {
+		t.Errorf("Consul was queried in namespace %q, want %q", ts.namespace, "team-a")
+	}
+}
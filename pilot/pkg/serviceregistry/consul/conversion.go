@@ -151,6 +151,37 @@ func convertInstance(instance *api.CatalogService) *model.ServiceInstance {
 	}
 }
 
+// convertServiceEntry converts an entry of the Consul health API into the
+// equivalent catalog entry
+func convertServiceEntry(entry *api.ServiceEntry) *api.CatalogService {
+	out := &api.CatalogService{}
+	if entry.Node != nil {
+		out.ID = entry.Node.ID
+		out.Node = entry.Node.Node
+		out.Address = entry.Node.Address
+		out.Datacenter = entry.Node.Datacenter
+		out.TaggedAddresses = entry.Node.TaggedAddresses
+		out.NodeMeta = entry.Node.Meta
+	}
+	if entry.Service != nil {
+		out.ServiceID = entry.Service.ID
+		out.ServiceName = entry.Service.Service
+		out.ServiceAddress = entry.Service.Address
+		out.ServiceTags = entry.Service.Tags
+		out.ServiceMeta = entry.Service.Meta
+		out.ServicePort = entry.Service.Port
+		out.ServiceWeights = api.Weights{
+			Passing: entry.Service.Weights.Passing,
+			Warning: entry.Service.Weights.Warning,
+		}
+		out.ServiceEnableTagOverride = entry.Service.EnableTagOverride
+		out.ServiceProxy = entry.Service.Proxy
+		out.CreateIndex = entry.Service.CreateIndex
+		out.ModifyIndex = entry.Service.ModifyIndex
+	}
+	return out
+}
+
 // serviceHostname produces FQDN for a consul service
 func serviceHostname(name string) host.Name {
 	// TODO include datacenter in Hostname?
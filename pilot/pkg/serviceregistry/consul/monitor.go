@@ -15,6 +15,9 @@
 package consul
 
 import (
+	"context"
+	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/hashicorp/consul/api"
@@ -33,115 +36,178 @@ type Monitor interface {
 // InstanceHandler processes service instance change events
 type InstanceHandler func(instance *api.CatalogService, event model.Event) error
 
-// ServiceHandler processes service change events
+// ServiceHandler processes service change events. The handlers are passed
+// all the endpoints of the service known at the time of the event.
 type ServiceHandler func(instances []*api.CatalogService, event model.Event) error
 
 type consulMonitor struct {
 	discovery        *api.Client
+	passingOnly      bool
 	instanceHandlers []InstanceHandler
 	serviceHandlers  []ServiceHandler
 }
 
+// serviceWatch is the running watch of a single Consul service
+type serviceWatch struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
 const (
-	refreshIdleTime    time.Duration = 5 * time.Second
 	periodicCheckTime  time.Duration = 2 * time.Second
 	blockQueryWaitTime time.Duration = 10 * time.Minute
 )
 
-// NewConsulMonitor watches for changes in Consul services and CatalogServices
-func NewConsulMonitor(client *api.Client) Monitor {
+// NewConsulMonitor watches for changes in Consul services and CatalogServices.
+// If passingOnly is set, the endpoints failing any health check are dropped.
+func NewConsulMonitor(client *api.Client, passingOnly bool) Monitor {
 	return &consulMonitor{
 		discovery:        client,
+		passingOnly:      passingOnly,
 		instanceHandlers: make([]InstanceHandler, 0),
 		serviceHandlers:  make([]ServiceHandler, 0),
 	}
 }
 
 func (m *consulMonitor) Start(stop <-chan struct{}) {
-	change := make(chan struct{})
-	go m.watchConsul(change, stop)
-	go m.updateRecord(change, stop)
+	go m.watchServices(stop)
 }
 
-func (m *consulMonitor) watchConsul(change chan struct{}, stop <-chan struct{}) {
-	var consulWaitIndex uint64
+// watchServices follows the list of services in the catalog, starting a
+// watch for every new service and stopping the watches of the removed ones.
+func (m *consulMonitor) watchServices(stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
 
+	watches := make(map[string]*serviceWatch)
+	var consulWaitIndex uint64
 	for {
-		select {
-		case <-stop:
+		queryOptions := &api.QueryOptions{
+			WaitIndex: consulWaitIndex,
+			WaitTime:  blockQueryWaitTime,
+		}
+		// This Consul REST API will block until service changes or timeout
+		services, queryMeta, err := m.discovery.Catalog().Services(queryOptions.WithContext(ctx))
+		if ctx.Err() != nil {
 			return
-		default:
-			queryOptions := api.QueryOptions{
-				WaitIndex: consulWaitIndex,
-				WaitTime:  blockQueryWaitTime,
+		}
+		if err != nil {
+			log.Warnf("Could not fetch services: %v", err)
+		} else if consulWaitIndex != queryMeta.LastIndex {
+			consulWaitIndex = nextWaitIndex(consulWaitIndex, queryMeta.LastIndex)
+			for name := range services {
+				if _, exists := watches[name]; !exists {
+					watchCtx, watchCancel := context.WithCancel(ctx)
+					watch := &serviceWatch{cancel: watchCancel, done: make(chan struct{})}
+					watches[name] = watch
+					go m.watchService(watchCtx, stop, name, watch.done)
+				}
 			}
-			// This Consul REST API will block until service changes or timeout
-			_, queryMeta, err := m.discovery.Catalog().Services(&queryOptions)
-			if err != nil {
-				log.Warnf("Could not fetch services: %v", err)
-			} else if consulWaitIndex != queryMeta.LastIndex {
-				consulWaitIndex = queryMeta.LastIndex
-				change <- struct{}{}
+			for name, watch := range watches {
+				if _, exists := services[name]; !exists {
+					// Wait for the deletion events of the service so that they are not
+					// reordered with the events of a service coming back under the same name.
+					watch.cancel()
+					<-watch.done
+					delete(watches, name)
+				}
 			}
-			time.Sleep(periodicCheckTime)
+		}
+		if !sleep(ctx, periodicCheckTime) {
+			return
 		}
 	}
 }
 
-func (m *consulMonitor) updateRecord(change <-chan struct{}, stop <-chan struct{}) {
-	lastChange := int64(0)
-	ticker := time.NewTicker(periodicCheckTime)
+// watchService follows the endpoints of a service with blocking queries and
+// notifies the handlers of their changes. Once the watch is cancelled
+// without the monitor being stopped, the service is deleted.
+func (m *consulMonitor) watchService(ctx context.Context, stop <-chan struct{}, name string, done chan<- struct{}) {
+	defer close(done)
 
+	endpoints := make(map[string]*api.CatalogService)
+	var consulWaitIndex uint64
 	for {
-		select {
-		case <-change:
-			lastChange = time.Now().Unix()
-		case <-ticker.C:
-			currentTime := time.Now().Unix()
-			if lastChange > 0 && currentTime-lastChange > int64(refreshIdleTime.Seconds()) {
-				log.Infof("Consul service changed")
-				m.updateServiceRecord()
-				m.updateInstanceRecord()
-				lastChange = int64(0)
-			}
-		case <-stop:
-			ticker.Stop()
-			return
+		queryOptions := &api.QueryOptions{
+			WaitIndex: consulWaitIndex,
+			WaitTime:  blockQueryWaitTime,
+		}
+		// This Consul REST API will block until the endpoints of the service change or timeout
+		instances, queryMeta, err := fetchEndpoints(m.discovery, name, m.passingOnly, queryOptions.WithContext(ctx))
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			log.Warnf("Could not fetch endpoints of service %s: %v", name, err)
+		} else if consulWaitIndex != queryMeta.LastIndex {
+			consulWaitIndex = nextWaitIndex(consulWaitIndex, queryMeta.LastIndex)
+			endpoints = m.updateEndpoints(endpoints, instances)
+		}
+		if !sleep(ctx, periodicCheckTime) {
+			break
 		}
 	}
+
+	select {
+	case <-stop:
+	default:
+		log.Infof("Consul service %s removed", name)
+		m.updateEndpoints(endpoints, nil)
+	}
 }
 
-func (m *consulMonitor) updateServiceRecord() {
-	// This is only a work-around solution currently
-	// Since Handler functions generally act as a refresher
-	// regardless of the input, thus passing in meaningless
-	// input should make functionalities work
-	//TODO
-	var obj []*api.CatalogService
-	var event model.Event
-	for _, f := range m.serviceHandlers {
-		go func(handler ServiceHandler) {
-			if err := handler(obj, event); err != nil {
-				log.Warnf("Error executing service handler function: %v", err)
-			}
-		}(f)
-	}
-}
-
-func (m *consulMonitor) updateInstanceRecord() {
-	// This is only a work-around solution currently
-	// Since Handler functions generally act as a refresher
-	// regardless of the input, thus passing in meaningless
-	// input should make functionalities work
-	// TODO
-	obj := &api.CatalogService{}
-	var event model.Event
-	for _, f := range m.instanceHandlers {
-		go func(handler InstanceHandler) {
-			if err := handler(obj, event); err != nil {
-				log.Warnf("Error executing instance handler function: %v", err)
-			}
-		}(f)
+// updateEndpoints diffs the endpoints of a service against the previous
+// ones, notifies the handlers of the changes and returns the endpoints by
+// key. A service is added along with its first endpoint and deleted along
+// with its last one.
+func (m *consulMonitor) updateEndpoints(prev map[string]*api.CatalogService,
+	instances []*api.CatalogService) map[string]*api.CatalogService {
+	current := make(map[string]*api.CatalogService, len(instances))
+	for _, instance := range instances {
+		current[endpointKey(instance)] = instance
+	}
+
+	if len(prev) == 0 && len(current) > 0 {
+		m.updateServiceRecord(instances, model.EventAdd)
+	} else if len(prev) > 0 && len(current) > 0 && serviceChanged(endpointList(prev), instances) {
+		m.updateServiceRecord(instances, model.EventUpdate)
+	}
+
+	for key, instance := range current {
+		if old, exists := prev[key]; !exists {
+			m.updateInstanceRecord(instance, model.EventAdd)
+		} else if !reflect.DeepEqual(old, instance) {
+			m.updateInstanceRecord(instance, model.EventUpdate)
+		}
+	}
+	for key, instance := range prev {
+		if _, exists := current[key]; !exists {
+			m.updateInstanceRecord(instance, model.EventDelete)
+		}
+	}
+
+	if len(prev) > 0 && len(current) == 0 {
+		m.updateServiceRecord(endpointList(prev), model.EventDelete)
+	}
+	return current
+}
+
+func (m *consulMonitor) updateServiceRecord(instances []*api.CatalogService, event model.Event) {
+	for _, handler := range m.serviceHandlers {
+		if err := handler(instances, event); err != nil {
+			log.Warnf("Error executing service handler function: %v", err)
+		}
+	}
+}
+
+func (m *consulMonitor) updateInstanceRecord(instance *api.CatalogService, event model.Event) {
+	for _, handler := range m.instanceHandlers {
+		if err := handler(instance, event); err != nil {
+			log.Warnf("Error executing instance handler function: %v", err)
+		}
 	}
 }
 
@@ -152,3 +218,75 @@ func (m *consulMonitor) AppendServiceHandler(h ServiceHandler) {
 func (m *consulMonitor) AppendInstanceHandler(h InstanceHandler) {
 	m.instanceHandlers = append(m.instanceHandlers, h)
 }
+
+// fetchEndpoints lists the endpoints of a service, either from the catalog
+// or, when only the passing endpoints are wanted, from the health API.
+func fetchEndpoints(client *api.Client, name string, passingOnly bool,
+	q *api.QueryOptions) ([]*api.CatalogService, *api.QueryMeta, error) {
+	if !passingOnly {
+		return client.Catalog().Service(name, "", q)
+	}
+
+	entries, queryMeta, err := client.Health().Service(name, "", true, q)
+	if err != nil {
+		return nil, nil, err
+	}
+	instances := make([]*api.CatalogService, 0, len(entries))
+	for _, entry := range entries {
+		instances = append(instances, convertServiceEntry(entry))
+	}
+	return instances, queryMeta, nil
+}
+
+// endpointKey identifies an endpoint of a service. The service ID alone is
+// not enough, as the same ID may be registered on several nodes or addresses.
+func endpointKey(instance *api.CatalogService) string {
+	return fmt.Sprintf("%s/%s/%s:%d", instance.Node, instance.ServiceID, instance.ServiceAddress, instance.ServicePort)
+}
+
+func endpointList(endpoints map[string]*api.CatalogService) []*api.CatalogService {
+	out := make([]*api.CatalogService, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		out = append(out, endpoint)
+	}
+	return out
+}
+
+// serviceChanged returns true if the service built from the new endpoints
+// differs from the one built from the old endpoints.
+func serviceChanged(prev, current []*api.CatalogService) bool {
+	oldService, newService := convertService(prev), convertService(current)
+	if oldService.MeshExternal != newService.MeshExternal || oldService.Resolution != newService.Resolution ||
+		len(oldService.Ports) != len(newService.Ports) {
+		return true
+	}
+	for _, port := range oldService.Ports {
+		if newPort, exists := newService.Ports.GetByPort(port.Port); !exists || *newPort != *port {
+			return true
+		}
+	}
+	return false
+}
+
+// nextWaitIndex returns the index to block on after a query. Consul may
+// report an index going backwards, e.g. after a snapshot restore, in which
+// case the blocking query has to start over.
+func nextWaitIndex(prev, last uint64) uint64 {
+	if last < prev {
+		return 0
+	}
+	return last
+}
+
+// sleep waits for the given duration and returns false if the context is
+// done in the meantime.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
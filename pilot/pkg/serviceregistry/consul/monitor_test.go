@@ -15,6 +15,9 @@
 package consul
 
 import (
+	"fmt"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -36,16 +39,16 @@ func TestController(t *testing.T) {
 		t.Errorf("could not create Consul Controller: %v", err)
 	}
 
-	updateChannel := make(chan struct{}, 10)
+	updateChannel := make(chan string, 20)
 
-	ctl := NewConsulMonitor(cl)
+	ctl := NewConsulMonitor(cl, false)
 	ctl.AppendInstanceHandler(func(instance *api.CatalogService, event model.Event) error {
-		updateChannel <- struct{}{}
+		updateChannel <- fmt.Sprintf("instance %s %s", event, instance.ServiceAddress)
 		return nil
 	})
 
 	ctl.AppendServiceHandler(func(instances []*api.CatalogService, event model.Event) error {
-		updateChannel <- struct{}{}
+		updateChannel <- fmt.Sprintf("service %s %s", event, instances[0].ServiceName)
 		return nil
 	})
 
@@ -53,27 +56,64 @@ func TestController(t *testing.T) {
 	go ctl.Start(stop)
 	defer close(stop)
 
-	expectNotify := func(t *testing.T, times int) {
+	expectNotify := func(t *testing.T, want ...string) {
 		t.Helper()
-		for i := 0; i < times; i++ {
+		got := make([]string, 0, len(want))
+		for range want {
 			select {
-			case <-updateChannel:
-				continue
+			case update := <-updateChannel:
+				got = append(got, update)
 			case <-time.After(notifyThreshold):
-				t.Fatalf("got %d notifications from controller, want %d", i, times)
+				t.Fatalf("got notifications %v from controller, want %v", got, want)
 			}
 		}
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got notifications %v from controller, want %v", got, want)
+		}
 	}
 
-	//The first query from monitor to Consul always doesn't block because the index is 0
-	expectNotify(t, 2)
+	//The first queries from monitor to Consul always don't block because the index is 0
+	expectNotify(t,
+		"service add productpage", "service add rating", "service add reviews",
+		"instance add 172.19.0.11", "instance add 172.19.0.12",
+		"instance add 172.19.0.6", "instance add 172.19.0.7", "instance add 172.19.0.8")
 
-	//There won't be any notifications if X-Consul-Index doesn't change
-	expectNotify(t, 0)
+	//X-Consul-Index change without any change of the endpoints doesn't trigger notifications
+	ts.lock.Lock()
+	ts.consulIndex++
+	ts.lock.Unlock()
 
-	//X-Consul-Index change means that the Consul Catalog changes, so there will be notifications
+	//Only the changed endpoints are notified, along with the service if its ports changed
 	ts.lock.Lock()
+	ts.reviews = []*api.CatalogService{
+		{
+			Node:           "istio-node",
+			Address:        "172.19.0.5",
+			ID:             "istio-node-id",
+			ServiceID:      "reviews-id",
+			ServiceName:    "reviews",
+			ServiceTags:    []string{"version|v1"},
+			ServiceAddress: "172.19.0.7",
+			ServicePort:    9081,
+		},
+	}
 	ts.consulIndex++
 	ts.lock.Unlock()
-	expectNotify(t, 2)
+	expectNotify(t, "service update reviews", "instance update 172.19.0.7",
+		"instance delete 172.19.0.6", "instance delete 172.19.0.8")
+
+	//Removing a service from the catalog deletes it along with its endpoints
+	ts.lock.Lock()
+	delete(ts.services, "rating")
+	ts.consulIndex++
+	ts.lock.Unlock()
+	expectNotify(t, "service delete rating", "instance delete 172.19.0.12")
+
+	select {
+	case update := <-updateChannel:
+		t.Fatalf("got unexpected notification %q", update)
+	case <-time.After(2 * periodicCheckTime):
+	}
 }
@@ -22,10 +22,13 @@ import (
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/visibility"
+	"istio.io/istio/pkg/spiffe"
 )
 
 func convertPort(port *networking.Port) *model.Port {
@@ -162,6 +165,96 @@ func convertEndpoint(service *model.Service, servicePort *networking.Port,
 	}
 }
 
+// The labels of the nodes giving the region and zone of their pods, by order of precedence.
+var (
+	regionLabels = []string{"failure-domain.beta.kubernetes.io/region", "failure-domain.kubernetes.io/region"}
+	zoneLabels   = []string{"failure-domain.beta.kubernetes.io/zone", "failure-domain.kubernetes.io/zone"}
+)
+
+// convertWorkloadEntry converts a WorkloadEntry selected by a ServiceEntry to an instance of one of its
+// services. The attributes of the instance are the ones of the entry, merged with the ones derived from
+// its metadata the way they are derived for a pod, the explicit ones taking precedence:
+//   - labels: the labels of the entry, over the labels of its metadata
+//   - locality: the locality of the entry, else the region and zone of its failure-domain labels. As for
+//     any instance, the istio-locality label overrides both.
+//   - network: the network of the entry, else its topology.istio.io/network label
+//   - service account: the service account of the entry, in its namespace
+func convertWorkloadEntry(service *model.Service, servicePort *networking.Port, cfg model.Config) *model.ServiceInstance {
+	we := cfg.Spec.(*workload.WorkloadEntry)
+	instancePort := we.Ports[servicePort.Name]
+	if instancePort == 0 {
+		instancePort = servicePort.Number
+	}
+
+	var sa string
+	if we.ServiceAccount != "" {
+		sa = spiffe.MustGenSpiffeURI(cfg.Namespace, we.ServiceAccount)
+	}
+
+	instanceLabels := workloadEntryLabels(cfg)
+	locality := we.Locality
+	if locality == "" {
+		locality = labelsLocality(instanceLabels)
+	}
+	network := we.Network
+	if network == "" {
+		network = instanceLabels[model.NetworkLabel]
+	}
+
+	return &model.ServiceInstance{
+		Endpoint: model.NetworkEndpoint{
+			Address:     we.Address,
+			Family:      model.AddressFamilyTCP,
+			Port:        int(instancePort),
+			ServicePort: convertPort(servicePort),
+			Network:     network,
+			Locality:    locality,
+			LbWeight:    we.Weight,
+		},
+		Service:        service,
+		Labels:         instanceLabels,
+		ServiceAccount: sa,
+		TLSMode:        model.GetTLSModeFromEndpointLabels(instanceLabels),
+	}
+}
+
+// workloadEntryLabels returns the labels of the metadata of a WorkloadEntry, overridden by the ones of
+// its spec.
+func workloadEntryLabels(cfg model.Config) labels.Instance {
+	we := cfg.Spec.(*workload.WorkloadEntry)
+	if len(cfg.Labels) == 0 {
+		return we.Labels
+	}
+	out := make(labels.Instance, len(cfg.Labels)+len(we.Labels))
+	for k, v := range cfg.Labels {
+		out[k] = v
+	}
+	for k, v := range we.Labels {
+		out[k] = v
+	}
+	return out
+}
+
+// labelsLocality returns the region/zone locality given by the failure-domain labels, or an empty
+// locality without them.
+func labelsLocality(instanceLabels labels.Instance) string {
+	var region, zone string
+	for _, l := range regionLabels {
+		if region = instanceLabels[l]; region != "" {
+			break
+		}
+	}
+	for _, l := range zoneLabels {
+		if zone = instanceLabels[l]; zone != "" {
+			break
+		}
+	}
+	if region == "" && zone == "" {
+		return ""
+	}
+	return region + "/" + zone
+}
+
 func convertInstances(cfg model.Config, services []*model.Service) []*model.ServiceInstance {
 	out := make([]*model.ServiceInstance, 0)
 	serviceEntry := cfg.Spec.(*networking.ServiceEntry)
@@ -195,3 +288,40 @@ func convertInstances(cfg model.Config, services []*model.Service) []*model.Serv
 	}
 	return out
 }
+
+// workloadEntryInstances converts the entries selected by the ServiceEntry, through its workload
+// selector annotation, to instances of its services.
+func workloadEntryInstances(cfg model.Config, entries []model.Config) []*model.ServiceInstance {
+	selector := cfg.Annotations[constants.ServiceEntryWorkloadSelectorAnnotation]
+	if selector == "" || len(entries) == 0 {
+		return nil
+	}
+	selectorLabels := labels.Parse(selector)
+
+	out := make([]*model.ServiceInstance, 0)
+	serviceEntry := cfg.Spec.(*networking.ServiceEntry)
+	for _, we := range entries {
+		if we.Namespace != cfg.Namespace || !selectorLabels.SubsetOf(workloadEntryLabels(we)) {
+			continue
+		}
+		for _, service := range convertServices(cfg) {
+			for _, port := range serviceEntry.Ports {
+				out = append(out, convertWorkloadEntry(service, port, we))
+			}
+		}
+	}
+	return out
+}
+
+// ConvertServices converts a ServiceEntry to the services of its hosts.
+func ConvertServices(cfg model.Config) []*model.Service {
+	return convertServices(cfg)
+}
+
+// ConvertInstances converts a ServiceEntry to the instances of its services: its endpoints, or the
+// WorkloadEntries it selects among the given ones.
+func ConvertInstances(cfg model.Config, workloadEntries []model.Config) []*model.ServiceInstance {
+	services := convertServices(cfg)
+	instances := convertInstances(cfg, services)
+	return append(instances, workloadEntryInstances(cfg, workloadEntries)...)
+}
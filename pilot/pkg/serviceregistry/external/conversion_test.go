@@ -25,6 +25,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pilot/test/util"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/protocol"
@@ -556,6 +557,123 @@ func TestConvertInstances(t *testing.T) {
 	}
 }
 
+func TestConvertWorkloadEntryAttributes(t *testing.T) {
+	selecting := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.ServiceEntry.Type,
+			Name:        "details",
+			Namespace:   "vms",
+			Annotations: map[string]string{constants.ServiceEntryWorkloadSelectorAnnotation: "app=details"},
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts:      []string{"details.vms.com"},
+			Ports:      []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+			Resolution: networking.ServiceEntry_STATIC,
+		},
+	}
+
+	cases := []struct {
+		name         string
+		metaLabels   map[string]string
+		entry        *workload.WorkloadEntry
+		wantLabels   map[string]string
+		wantLocality string
+		wantNetwork  string
+		wantSelected bool
+	}{
+		{
+			name:         "explicit attributes",
+			entry:        &workload.WorkloadEntry{Labels: map[string]string{"app": "details"}, Locality: "us/east", Network: "vpc"},
+			wantLabels:   map[string]string{"app": "details"},
+			wantLocality: "us/east",
+			wantNetwork:  "vpc",
+			wantSelected: true,
+		},
+		{
+			name: "attributes derived from the metadata",
+			metaLabels: map[string]string{
+				"app":     "details",
+				"version": "v1",
+				"failure-domain.beta.kubernetes.io/region": "eu",
+				"failure-domain.beta.kubernetes.io/zone":   "west",
+				model.NetworkLabel:                         "on-prem",
+			},
+			entry: &workload.WorkloadEntry{},
+			wantLabels: map[string]string{
+				"app":     "details",
+				"version": "v1",
+				"failure-domain.beta.kubernetes.io/region": "eu",
+				"failure-domain.beta.kubernetes.io/zone":   "west",
+				model.NetworkLabel:                         "on-prem",
+			},
+			wantLocality: "eu/west",
+			wantNetwork:  "on-prem",
+			wantSelected: true,
+		},
+		{
+			name: "explicit attributes take precedence",
+			metaLabels: map[string]string{
+				"version":                             "v1",
+				"failure-domain.kubernetes.io/region": "eu",
+				model.NetworkLabel:                    "on-prem",
+			},
+			entry: &workload.WorkloadEntry{
+				Labels:  map[string]string{"app": "details", "version": "v2"},
+				Network: "vpc",
+			},
+			wantLabels: map[string]string{
+				"app":                                 "details",
+				"version":                             "v2",
+				"failure-domain.kubernetes.io/region": "eu",
+				model.NetworkLabel:                    "on-prem",
+			},
+			wantLocality: "eu/",
+			wantNetwork:  "vpc",
+			wantSelected: true,
+		},
+		{
+			name:       "not selected",
+			metaLabels: map[string]string{"app": "ratings"},
+			entry:      &workload.WorkloadEntry{},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.entry.Address = "2.2.2.2"
+			we := model.Config{
+				ConfigMeta: model.ConfigMeta{
+					Type:      schemas.WorkloadEntry.Type,
+					Name:      "details-1",
+					Namespace: "vms",
+					Labels:    tt.metaLabels,
+				},
+				Spec: tt.entry,
+			}
+			instances := workloadEntryInstances(selecting, []model.Config{we})
+			if !tt.wantSelected {
+				if len(instances) != 0 {
+					t.Fatalf("expected the entry not to be selected, got %v", instances)
+				}
+				return
+			}
+			if len(instances) != 1 {
+				t.Fatalf("got %d instances, want 1", len(instances))
+			}
+			instance := instances[0]
+			if !instance.Labels.Equals(tt.wantLabels) {
+				t.Errorf("got labels %v, want %v", instance.Labels, tt.wantLabels)
+			}
+			if instance.Endpoint.Locality != tt.wantLocality {
+				t.Errorf("got locality %q, want %q", instance.Endpoint.Locality, tt.wantLocality)
+			}
+			if instance.Endpoint.Network != tt.wantNetwork {
+				t.Errorf("got network %q, want %q", instance.Endpoint.Network, tt.wantNetwork)
+			}
+		})
+	}
+}
+
 func compare(t *testing.T, actual, expected interface{}) error {
 	return util.Compare(jsonBytes(t, actual), jsonBytes(t, expected))
 }
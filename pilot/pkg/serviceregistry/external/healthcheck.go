@@ -0,0 +1,338 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/validation"
+	"istio.io/pkg/log"
+)
+
+const (
+	// Defaults of the readiness probe, the same as the kubelet uses for pods.
+	defaultProbeTimeout          = time.Second
+	defaultProbePeriod           = 10 * time.Second
+	defaultProbeSuccessThreshold = 1
+	defaultProbeFailureThreshold = 3
+
+	// healthCheckInterval is how often the probed endpoints are scanned for due probes.
+	healthCheckInterval = time.Second
+)
+
+// probeFunc executes a readiness probe against the workload at address.
+type probeFunc func(address string, probe *workload.ReadinessProbe) error
+
+// probeState is the readiness of a single probed endpoint.
+type probeState struct {
+	healthy   bool
+	nextProbe time.Time
+	successes int32
+	failures  int32
+}
+
+// probeTarget is an endpoint with a readiness probe: a WorkloadEntry, or a static endpoint of a
+// ServiceEntry annotated with a readiness probe.
+type probeTarget struct {
+	key     string
+	address string
+	probe   *workload.ReadinessProbe
+	// healthy is the readiness of the endpoint until its first probe.
+	healthy bool
+	// config is the WorkloadEntry or the ServiceEntry defining the endpoint.
+	config model.Config
+}
+
+// workloadHealthChecker executes the readiness probes of WorkloadEntries and of the static endpoints
+// of ServiceEntries. VMs and external services have no kubelet to take them out of rotation, so pilot
+// probes them itself and only sends the healthy ones to the proxies.
+type workloadHealthChecker struct {
+	mu     sync.RWMutex
+	states map[string]*probeState
+
+	probe probeFunc
+	now   func() time.Time
+	// onChange is called after the readiness of an endpoint changed.
+	onChange func(target probeTarget, healthy bool)
+}
+
+func newWorkloadHealthChecker(onChange func(probeTarget, bool)) *workloadHealthChecker {
+	return &workloadHealthChecker{
+		states:   map[string]*probeState{},
+		probe:    probeWorkload,
+		now:      time.Now,
+		onChange: onChange,
+	}
+}
+
+func workloadEntryKey(cfg model.Config) string {
+	return cfg.Namespace + "/" + cfg.Name
+}
+
+func serviceEntryEndpointKey(cfg model.Config, address string) string {
+	return cfg.Namespace + "/" + cfg.Name + "/" + address
+}
+
+// workloadEntryTargets returns the WorkloadEntries with a readiness probe.
+func workloadEntryTargets(entries []model.Config) []probeTarget {
+	out := make([]probeTarget, 0)
+	for _, cfg := range entries {
+		we := cfg.Spec.(*workload.WorkloadEntry)
+		if we.Probe == nil || we.Address == "" {
+			continue
+		}
+		out = append(out, probeTarget{
+			key:     workloadEntryKey(cfg),
+			address: we.Address,
+			probe:   we.Probe,
+			healthy: cfg.Annotations[constants.WorkloadEntryHealthAnnotation] != constants.WorkloadUnhealthy,
+			config:  cfg,
+		})
+	}
+	return out
+}
+
+// serviceEntryTargets returns the static endpoints of the ServiceEntries annotated with a readiness probe.
+func serviceEntryTargets(entries []model.Config) []probeTarget {
+	out := make([]probeTarget, 0)
+	for _, cfg := range entries {
+		probe := serviceEntryProbe(cfg)
+		if probe == nil {
+			continue
+		}
+		for _, ep := range cfg.Spec.(*networking.ServiceEntry).Endpoints {
+			if ep.Address == "" || strings.HasPrefix(ep.Address, model.UnixAddressPrefix) {
+				continue
+			}
+			out = append(out, probeTarget{
+				key:     serviceEntryEndpointKey(cfg, ep.Address),
+				address: ep.Address,
+				probe:   probe,
+				healthy: true,
+				config:  cfg,
+			})
+		}
+	}
+	return out
+}
+
+// serviceEntryProbe returns the readiness probe of the static endpoints of a ServiceEntry, or nil if it
+// has none or its annotation is invalid.
+func serviceEntryProbe(cfg model.Config) *workload.ReadinessProbe {
+	value := cfg.Annotations[constants.ServiceEntryReadinessProbeAnnotation]
+	if value == "" || cfg.Spec.(*networking.ServiceEntry).Resolution != networking.ServiceEntry_STATIC {
+		return nil
+	}
+	probe := &workload.ReadinessProbe{}
+	if err := jsonpb.UnmarshalString(value, probe); err != nil {
+		log.Warnf("invalid readiness probe annotation of service entry %s: %v", workloadEntryKey(cfg), err)
+		return nil
+	}
+	if err := validation.ValidateReadinessProbe(probe); err != nil {
+		log.Warnf("invalid readiness probe annotation of service entry %s: %v", workloadEntryKey(cfg), err)
+		return nil
+	}
+	return probe
+}
+
+// healthy returns false if the WorkloadEntry failed its readiness probe. Workloads without a probe,
+// or that have not been probed yet, are healthy unless their last recorded status says otherwise.
+func (h *workloadHealthChecker) healthy(cfg model.Config) bool {
+	h.mu.RLock()
+	state, f := h.states[workloadEntryKey(cfg)]
+	h.mu.RUnlock()
+	if f {
+		return state.healthy
+	}
+	return cfg.Annotations[constants.WorkloadEntryHealthAnnotation] != constants.WorkloadUnhealthy
+}
+
+// endpointHealthy returns false if the static endpoint of the ServiceEntry failed its readiness probe.
+func (h *workloadHealthChecker) endpointHealthy(cfg model.Config, address string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	state, f := h.states[serviceEntryEndpointKey(cfg, address)]
+	return !f || state.healthy
+}
+
+// check probes the targets whose probe is due, and forgets the state of the ones that are gone.
+// It returns once all the probes completed.
+func (h *workloadHealthChecker) check(targets []probeTarget) {
+	now := h.now()
+	seen := make(map[string]bool, len(targets))
+	due := make([]probeTarget, 0)
+
+	h.mu.Lock()
+	for _, target := range targets {
+		seen[target.key] = true
+		state, f := h.states[target.key]
+		if !f {
+			state = &probeState{
+				healthy:   target.healthy,
+				nextProbe: now.Add(time.Duration(target.probe.InitialDelaySeconds) * time.Second),
+			}
+			h.states[target.key] = state
+		}
+		if !now.Before(state.nextProbe) {
+			state.nextProbe = now.Add(durationOrDefault(target.probe.PeriodSeconds, defaultProbePeriod))
+			due = append(due, target)
+		}
+	}
+	for key := range h.states {
+		if !seen[key] {
+			delete(h.states, key)
+		}
+	}
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, target := range due {
+		wg.Add(1)
+		go func(target probeTarget) {
+			defer wg.Done()
+			err := h.probe(target.address, target.probe)
+			if err != nil {
+				log.Debugf("readiness probe of %s failed: %v", target.key, err)
+			}
+			h.record(target, err == nil)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// record accounts for the result of a probe, flipping the readiness of the endpoint once the
+// success or failure threshold is reached.
+func (h *workloadHealthChecker) record(target probeTarget, success bool) {
+	probe := target.probe
+
+	h.mu.Lock()
+	state, f := h.states[target.key]
+	if !f {
+		// removed while being probed
+		h.mu.Unlock()
+		return
+	}
+	changed := false
+	if success {
+		state.failures = 0
+		state.successes++
+		if !state.healthy && state.successes >= thresholdOrDefault(probe.SuccessThreshold, defaultProbeSuccessThreshold) {
+			state.healthy = true
+			changed = true
+		}
+	} else {
+		state.successes = 0
+		state.failures++
+		if state.healthy && state.failures >= thresholdOrDefault(probe.FailureThreshold, defaultProbeFailureThreshold) {
+			state.healthy = false
+			changed = true
+		}
+	}
+	healthy := state.healthy
+	h.mu.Unlock()
+
+	if changed {
+		log.Infof("endpoint %s is now %s", target.key, healthStatus(healthy))
+		if h.onChange != nil {
+			h.onChange(target, healthy)
+		}
+	}
+}
+
+func healthStatus(healthy bool) string {
+	if healthy {
+		return constants.WorkloadHealthy
+	}
+	return constants.WorkloadUnhealthy
+}
+
+func durationOrDefault(seconds int32, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func thresholdOrDefault(threshold, def int32) int32 {
+	if threshold <= 0 {
+		return def
+	}
+	return threshold
+}
+
+// probeWorkload executes the HTTP or TCP readiness probe against the workload.
+func probeWorkload(address string, probe *workload.ReadinessProbe) error {
+	timeout := durationOrDefault(probe.TimeoutSeconds, defaultProbeTimeout)
+	switch m := probe.HealthCheckMethod.(type) {
+	case *workload.ReadinessProbe_HttpGet:
+		host := address
+		if m.HttpGet.Host != "" {
+			host = m.HttpGet.Host
+		}
+		scheme := strings.ToLower(m.HttpGet.Scheme)
+		if scheme == "" {
+			scheme = "http"
+		}
+		path := m.HttpGet.Path
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		client := &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// like the kubelet, do not verify the certificate of the workload
+				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				DisableKeepAlives: true,
+			},
+		}
+		url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(m.HttpGet.Port))), path)
+		resp, err := client.Get(url)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+			return fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+		}
+		return nil
+	case *workload.ReadinessProbe_TcpSocket:
+		host := address
+		if m.TcpSocket.Host != "" {
+			host = m.TcpSocket.Host
+		}
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(int(m.TcpSocket.Port))), timeout)
+		if err != nil {
+			return err
+		}
+		_ = conn.Close()
+		return nil
+	default:
+		return fmt.Errorf("unsupported readiness probe %T", probe.HealthCheckMethod)
+	}
+}
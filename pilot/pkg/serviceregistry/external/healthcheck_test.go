@@ -0,0 +1,245 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func TestWorkloadHealthChecker(t *testing.T) {
+	we := makeWorkloadEntry("details-1", "3.3.3.3", map[string]string{"app": "details"}, nil)
+	we.Spec.(*workload.WorkloadEntry).Probe = &workload.ReadinessProbe{
+		InitialDelaySeconds: 5,
+		PeriodSeconds:       2,
+		FailureThreshold:    2,
+		HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+			TcpSocket: &workload.TCPHealthCheckConfig{Port: 8080},
+		},
+	}
+	now := time.Now()
+	var probeErr error
+	probes := 0
+	var changes []bool
+
+	h := newWorkloadHealthChecker(func(_ probeTarget, healthy bool) {
+		changes = append(changes, healthy)
+	})
+	h.now = func() time.Time { return now }
+	h.probe = func(address string, _ *workload.ReadinessProbe) error {
+		if address != "3.3.3.3" {
+			t.Errorf("probed %s", address)
+		}
+		probes++
+		return probeErr
+	}
+
+	cases := []struct {
+		name    string
+		advance time.Duration
+		fail    bool
+		probes  int
+		healthy bool
+		changes int
+	}{
+		{name: "initial delay", advance: 0, probes: 0, healthy: true},
+		{name: "first probe fails", advance: 5 * time.Second, fail: true, probes: 1, healthy: true},
+		{name: "not due yet", advance: time.Second, fail: true, probes: 1, healthy: true},
+		{name: "failure threshold", advance: time.Second, fail: true, probes: 2, healthy: false, changes: 1},
+		{name: "still failing", advance: 2 * time.Second, fail: true, probes: 3, healthy: false, changes: 1},
+		{name: "recovers", advance: 2 * time.Second, probes: 4, healthy: true, changes: 2},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			now = now.Add(tt.advance)
+			probeErr = nil
+			if tt.fail {
+				probeErr = errors.New("connection refused")
+			}
+			h.check(workloadEntryTargets([]model.Config{*we}))
+			if probes != tt.probes {
+				t.Errorf("got %d probes, want %d", probes, tt.probes)
+			}
+			if got := h.healthy(*we); got != tt.healthy {
+				t.Errorf("got healthy %v, want %v", got, tt.healthy)
+			}
+			if len(changes) != tt.changes {
+				t.Errorf("got %d changes, want %d", len(changes), tt.changes)
+			}
+		})
+	}
+
+	h.check(nil)
+	if len(h.states) != 0 {
+		t.Errorf("expected the state of removed workload entries to be dropped")
+	}
+	we.Annotations = map[string]string{constants.WorkloadEntryHealthAnnotation: constants.WorkloadUnhealthy}
+	if h.healthy(*we) {
+		t.Errorf("expected the recorded status to be used before the first probe")
+	}
+}
+
+func TestWorkloadHealthRecorded(t *testing.T) {
+	store, sd, stopFn := initServiceDiscovery()
+	defer stopFn()
+
+	we := makeWorkloadEntry("details-1", "3.3.3.3", map[string]string{"app": "details"}, nil)
+	createServiceEntries([]*model.Config{we}, store, t)
+
+	sd.workloadHealthChanged(*we, false)
+	got := store.Get(schemas.WorkloadEntry.Type, we.Name, we.Namespace)
+	if got == nil || got.Annotations[constants.WorkloadEntryHealthAnnotation] != constants.WorkloadUnhealthy {
+		t.Fatalf("expected the workload entry to be marked unhealthy, got %v", got)
+	}
+	sd.workloadHealthChanged(*we, true)
+	got = store.Get(schemas.WorkloadEntry.Type, we.Name, we.Namespace)
+	if got.Annotations[constants.WorkloadEntryHealthAnnotation] != constants.WorkloadHealthy {
+		t.Fatalf("expected the workload entry to be marked healthy, got %v", got.Annotations)
+	}
+}
+
+func TestServiceEntryEndpointHealth(t *testing.T) {
+	store, sd, stopFn := initServiceDiscovery()
+	defer stopFn()
+
+	withProbe := func(cfg *model.Config, probe string) *model.Config {
+		out := *cfg
+		out.Annotations = map[string]string{constants.ServiceEntryReadinessProbeAnnotation: probe}
+		return &out
+	}
+	probed := withProbe(httpStatic, `{"tcpSocket": {"port": 8080}, "failureThreshold": 1}`)
+
+	for _, tt := range []struct {
+		name    string
+		cfg     *model.Config
+		targets int
+	}{
+		{name: "probed", cfg: probed, targets: 3},
+		{name: "no annotation", cfg: httpStatic},
+		{name: "invalid annotation", cfg: withProbe(httpStatic, `{"tcpSocket": {}}`)},
+		{name: "dns resolution", cfg: withProbe(httpDNS, `{"tcpSocket": {"port": 8080}}`)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceEntryTargets([]model.Config{*tt.cfg}); len(got) != tt.targets {
+				t.Errorf("got %d probe targets, want %d", len(got), tt.targets)
+			}
+		})
+	}
+
+	createServiceEntries([]*model.Config{probed}, store, t)
+	svc := convertServices(*probed)[0]
+	instances, err := sd.InstancesByPort(svc, 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	if len(instances) != 3 {
+		t.Fatalf("got %d instances, want all the endpoints before the first probe", len(instances))
+	}
+
+	sd.health.probe = func(address string, probe *workload.ReadinessProbe) error {
+		if probe.GetTcpSocket().GetPort() != 8080 {
+			t.Errorf("unexpected probe %v", probe)
+		}
+		if address == "3.3.3.3" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	sd.health.check(serviceEntryTargets(store.ServiceEntries()))
+
+	instances, err = sd.InstancesByPort(svc, 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("got %d instances, want only the healthy endpoints", len(instances))
+	}
+	for _, instance := range instances {
+		if instance.Endpoint.Address == "3.3.3.3" {
+			t.Errorf("unexpected unhealthy instance %+v", instance)
+		}
+	}
+}
+
+func TestProbeWorkload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	host, portStr, _ := net.SplitHostPort(u.Host)
+	port, _ := strconv.Atoi(portStr)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	_ = closed.Close()
+
+	cases := []struct {
+		name  string
+		probe *workload.ReadinessProbe
+		ok    bool
+	}{
+		{
+			name: "http success",
+			probe: &workload.ReadinessProbe{HealthCheckMethod: &workload.ReadinessProbe_HttpGet{
+				HttpGet: &workload.HTTPHealthCheckConfig{Path: "healthz", Port: uint32(port)},
+			}},
+			ok: true,
+		},
+		{
+			name: "http error status",
+			probe: &workload.ReadinessProbe{HealthCheckMethod: &workload.ReadinessProbe_HttpGet{
+				HttpGet: &workload.HTTPHealthCheckConfig{Path: "/ready", Port: uint32(port)},
+			}},
+		},
+		{
+			name: "tcp success",
+			probe: &workload.ReadinessProbe{HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+				TcpSocket: &workload.TCPHealthCheckConfig{Port: uint32(port)},
+			}},
+			ok: true,
+		},
+		{
+			name: "tcp refused",
+			probe: &workload.ReadinessProbe{HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+				TcpSocket: &workload.TCPHealthCheckConfig{Port: uint32(closedPort)},
+			}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := probeWorkload(host, tt.probe)
+			if (err == nil) != tt.ok {
+				t.Errorf("got error %v, want success %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -15,13 +15,16 @@
 package external
 
 import (
+	"sort"
 	"sync"
 	"time"
 
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/schemas"
+	"istio.io/pkg/log"
 )
 
 // TODO: move this out of 'external' package. Either 'serviceentry' package or
@@ -46,6 +49,8 @@ type ServiceEntryStore struct {
 	changeMutex  sync.RWMutex
 	lastChange   time.Time
 	updateNeeded bool
+
+	health *workloadHealthChecker
 }
 
 // NewServiceDiscovery creates a new ServiceEntry discovery service
@@ -58,6 +63,7 @@ func NewServiceDiscovery(callbacks model.ConfigStoreCache, store model.IstioConf
 		instances:        map[host.Name]map[string][]*model.ServiceInstance{},
 		updateNeeded:     true,
 	}
+	c.health = newWorkloadHealthChecker(c.healthChanged)
 	if callbacks != nil {
 		callbacks.RegisterEventHandler(schemas.ServiceEntry.Type, func(config model.Config, event model.Event) {
 			// Recomputing the index here is too expensive.
@@ -77,6 +83,12 @@ func NewServiceDiscovery(callbacks model.ConfigStoreCache, store model.IstioConf
 				}
 			}
 		})
+		callbacks.RegisterEventHandler(schemas.WorkloadEntry.Type, func(model.Config, model.Event) {
+			c.changeMutex.Lock()
+			c.lastChange = time.Now()
+			c.updateNeeded = true
+			c.changeMutex.Unlock()
+		})
 	}
 
 	return c
@@ -94,8 +106,93 @@ func (d *ServiceEntryStore) AppendInstanceHandler(f func(*model.ServiceInstance,
 	return nil
 }
 
-// Run is used by some controllers to execute background jobs after init is done.
-func (d *ServiceEntryStore) Run(stop <-chan struct{}) {}
+// Run executes the readiness probes of the WorkloadEntries and of the static endpoints of the
+// ServiceEntries until stop is closed.
+func (d *ServiceEntryStore) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			entries, err := d.store.List(schemas.WorkloadEntry.Type, "")
+			if err != nil {
+				log.Warnf("failed to list workload entries: %v", err)
+				continue
+			}
+			targets := workloadEntryTargets(entries)
+			targets = append(targets, serviceEntryTargets(d.store.ServiceEntries())...)
+			d.health.check(targets)
+		}
+	}
+}
+
+// healthChanged rebuilds the endpoints after a probed endpoint became healthy or unhealthy.
+func (d *ServiceEntryStore) healthChanged(target probeTarget, healthy bool) {
+	if target.config.Type == schemas.WorkloadEntry.Type {
+		d.workloadHealthChanged(target.config, healthy)
+		return
+	}
+
+	d.changeMutex.Lock()
+	d.lastChange = time.Now()
+	d.updateNeeded = true
+	d.changeMutex.Unlock()
+
+	event := model.EventUpdate
+	if !healthy {
+		event = model.EventDelete
+	}
+	for _, instance := range convertInstances(target.config, nil) {
+		if instance.Endpoint.Address != target.address {
+			continue
+		}
+		for _, handler := range d.instanceHandlers {
+			go handler(instance, event)
+		}
+	}
+}
+
+// workloadHealthChanged rebuilds the endpoints after a WorkloadEntry became healthy or unhealthy, and
+// records its new status.
+func (d *ServiceEntryStore) workloadHealthChanged(cfg model.Config, healthy bool) {
+	d.changeMutex.Lock()
+	d.lastChange = time.Now()
+	d.updateNeeded = true
+	d.changeMutex.Unlock()
+
+	event := model.EventUpdate
+	if !healthy {
+		event = model.EventDelete
+	}
+	for _, se := range d.store.ServiceEntries() {
+		if se.Namespace != cfg.Namespace {
+			continue
+		}
+		for _, instance := range workloadEntryInstances(se, []model.Config{cfg}) {
+			for _, handler := range d.instanceHandlers {
+				go handler(instance, event)
+			}
+		}
+	}
+
+	status := healthStatus(healthy)
+	current := d.store.Get(schemas.WorkloadEntry.Type, cfg.Name, cfg.Namespace)
+	if current == nil || current.Annotations[constants.WorkloadEntryHealthAnnotation] == status {
+		return
+	}
+	updated := *current
+	updated.Annotations = make(map[string]string, len(current.Annotations)+1)
+	for k, v := range current.Annotations {
+		updated.Annotations[k] = v
+	}
+	updated.Annotations[constants.WorkloadEntryHealthAnnotation] = status
+	if _, err := d.store.Update(updated); err != nil {
+		// the config source may be read only, e.g. MCP
+		log.Debugf("failed to record health of workload entry %s: %v", workloadEntryKey(cfg), err)
+	}
+}
 
 // Services list declarations of all services in the system
 func (d *ServiceEntryStore) Services() ([]*model.Service, error) {
@@ -179,8 +276,41 @@ func (d *ServiceEntryStore) update() {
 	di := map[host.Name]map[string][]*model.ServiceInstance{}
 	dip := map[string][]*model.ServiceInstance{}
 
+	// WorkloadEntries by namespace, split by readiness
+	healthy := map[string][]model.Config{}
+	unhealthy := map[string][]model.Config{}
+	if entries, err := d.store.List(schemas.WorkloadEntry.Type, ""); err != nil {
+		log.Warnf("failed to list workload entries: %v", err)
+	} else {
+		for _, we := range entries {
+			if d.health.healthy(we) {
+				healthy[we.Namespace] = append(healthy[we.Namespace], we)
+			} else {
+				unhealthy[we.Namespace] = append(unhealthy[we.Namespace], we)
+			}
+		}
+	}
+
 	for _, cfg := range d.store.ServiceEntries() {
-		for _, instance := range convertInstances(cfg, nil) {
+		// Unhealthy workloads are kept out of the endpoints of the service, but their own sidecar
+		// still gets its inbound configuration.
+		for _, instance := range workloadEntryInstances(cfg, unhealthy[cfg.Namespace]) {
+			dip[instance.Endpoint.Address] = append(dip[instance.Endpoint.Address], instance)
+		}
+		instances := convertInstances(cfg, nil)
+		if cfg.Annotations[constants.ServiceEntryReadinessProbeAnnotation] != "" {
+			ready := make([]*model.ServiceInstance, 0, len(instances))
+			for _, instance := range instances {
+				if d.health.endpointHealthy(cfg, instance.Endpoint.Address) {
+					ready = append(ready, instance)
+				} else {
+					dip[instance.Endpoint.Address] = append(dip[instance.Endpoint.Address], instance)
+				}
+			}
+			instances = ready
+		}
+		instances = append(instances, workloadEntryInstances(cfg, healthy[cfg.Namespace])...)
+		for _, instance := range instances {
 
 			out, found := di[instance.Service.Hostname][instance.Service.Attributes.Namespace]
 			if !found {
@@ -255,9 +385,30 @@ func (d *ServiceEntryStore) GetProxyWorkloadLabels(proxy *model.Proxy) (labels.C
 	return out, nil
 }
 
-// GetIstioServiceAccounts implements model.ServiceAccounts operation TODOg
+// GetIstioServiceAccounts implements model.ServiceAccounts operation.
+// Plain service entries carry no identity, only the WorkloadEntries they select do.
 func (d *ServiceEntryStore) GetIstioServiceAccounts(svc *model.Service, ports []int) []string {
-	//for service entries, there is no istio auth, no service accounts, etc. It is just a
-	// service, with service instances, and dns.
-	return nil
+	d.update()
+	d.storeMutex.RLock()
+	defer d.storeMutex.RUnlock()
+
+	saSet := make(map[string]bool)
+	for _, instance := range d.instances[svc.Hostname][svc.Attributes.Namespace] {
+		if instance.ServiceAccount == "" {
+			continue
+		}
+		for _, port := range ports {
+			if portMatchSingle(instance, port) {
+				saSet[instance.ServiceAccount] = true
+				break
+			}
+		}
+	}
+
+	var out []string
+	for sa := range saSet {
+		out = append(out, sa)
+	}
+	sort.Strings(out)
+	return out
 }
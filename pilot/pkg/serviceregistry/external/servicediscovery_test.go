@@ -23,6 +23,7 @@ import (
 
 	"istio.io/istio/pilot/pkg/config/memory"
 	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
@@ -224,6 +225,85 @@ func TestNonServiceConfig(t *testing.T) {
 	}
 }
 
+func makeWorkloadEntry(name, address string, lbls map[string]string, annotations map[string]string) *model.Config {
+	return &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.WorkloadEntry.Type,
+			Name:              name,
+			Namespace:         "vms",
+			CreationTimestamp: GlobalTime,
+			Annotations:       annotations,
+		},
+		Spec: &workload.WorkloadEntry{
+			Address:        address,
+			Labels:         lbls,
+			Ports:          map[string]uint32{"http": 8080},
+			ServiceAccount: "details",
+		},
+	}
+}
+
+func TestServiceDiscoveryWorkloadEntries(t *testing.T) {
+	store, sd, stopFn := initServiceDiscovery()
+	defer stopFn()
+
+	selecting := &model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              schemas.ServiceEntry.Type,
+			Name:              "details",
+			Namespace:         "vms",
+			CreationTimestamp: GlobalTime,
+			Annotations:       map[string]string{constants.ServiceEntryWorkloadSelectorAnnotation: "app=details"},
+		},
+		Spec: &networking.ServiceEntry{
+			Hosts:      []string{"details.vms.com"},
+			Ports:      []*networking.Port{{Number: 80, Name: "http", Protocol: "http"}},
+			Location:   networking.ServiceEntry_MESH_INTERNAL,
+			Resolution: networking.ServiceEntry_STATIC,
+		},
+	}
+	createServiceEntries([]*model.Config{
+		selecting,
+		makeWorkloadEntry("details-1", "3.3.3.3", map[string]string{"app": "details", "version": "v1"}, nil),
+		makeWorkloadEntry("details-2", "4.4.4.4", map[string]string{"app": "details"},
+			map[string]string{constants.WorkloadEntryHealthAnnotation: constants.WorkloadUnhealthy}),
+		makeWorkloadEntry("ratings-1", "5.5.5.5", map[string]string{"app": "ratings"}, nil),
+	}, store, t)
+
+	svc := convertServices(*selecting)[0]
+	instances, err := sd.InstancesByPort(svc, 80, nil)
+	if err != nil {
+		t.Fatalf("InstancesByPort() encountered unexpected error: %v", err)
+	}
+	if len(instances) != 1 {
+		t.Fatalf("got %d instances, want only the healthy selected workload entry", len(instances))
+	}
+	got := instances[0]
+	if got.Endpoint.Address != "3.3.3.3" || got.Endpoint.Port != 8080 || got.Labels["version"] != "v1" {
+		t.Errorf("unexpected instance %+v", got)
+	}
+	if got.ServiceAccount != "spiffe://cluster.local/ns/vms/sa/details" {
+		t.Errorf("got service account %q", got.ServiceAccount)
+	}
+
+	// the unhealthy workload still gets the configuration for its own sidecar
+	proxyInstances, err := sd.GetProxyServiceInstances(&model.Proxy{IPAddresses: []string{"4.4.4.4"}})
+	if err != nil {
+		t.Fatalf("GetProxyServiceInstances() encountered unexpected error: %v", err)
+	}
+	if len(proxyInstances) != 1 || proxyInstances[0].Service.Hostname != "details.vms.com" {
+		t.Errorf("unexpected proxy instances %v", proxyInstances)
+	}
+
+	sas := sd.GetIstioServiceAccounts(svc, []int{80})
+	if len(sas) != 1 || sas[0] != "spiffe://cluster.local/ns/vms/sa/details" {
+		t.Errorf("got service accounts %v", sas)
+	}
+	if sas := sd.GetIstioServiceAccounts(convertServices(*tcpStatic)[0], []int{444}); sas != nil {
+		t.Errorf("expected no service accounts for plain service entries, got %v", sas)
+	}
+}
+
 func sortServices(services []*model.Service) {
 	sort.Slice(services, func(i, j int) bool { return services[i].Hostname < services[j].Hostname })
 	for _, service := range services {
@@ -0,0 +1,70 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+// NewWorkloadEntry stamps out of a WorkloadGroup the WorkloadEntry of a workload joining it with the given
+// address. The labels and annotations of the group metadata are merged over the ones of its template, its
+// probe takes precedence over the one of the template, and the entry is annotated with the name of the group.
+func NewWorkloadEntry(group model.Config, name, address string) model.Config {
+	wg := group.Spec.(*workload.WorkloadGroup)
+
+	we := &workload.WorkloadEntry{}
+	if wg.Template != nil {
+		we = proto.Clone(wg.Template).(*workload.WorkloadEntry)
+	}
+	we.Address = address
+	if wg.Probe != nil {
+		we.Probe = proto.Clone(wg.Probe).(*workload.ReadinessProbe)
+	}
+
+	labels := map[string]string{}
+	annotations := map[string]string{}
+	if wg.Metadata != nil {
+		if len(wg.Metadata.Labels) > 0 && we.Labels == nil {
+			we.Labels = make(map[string]string, len(wg.Metadata.Labels))
+		}
+		for k, v := range wg.Metadata.Labels {
+			we.Labels[k] = v
+			labels[k] = v
+		}
+		for k, v := range wg.Metadata.Annotations {
+			annotations[k] = v
+		}
+	}
+	annotations[constants.WorkloadGroupAnnotation] = group.Name
+
+	return model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:        schemas.WorkloadEntry.Type,
+			Group:       schemas.WorkloadEntry.Group + constants.IstioAPIGroupDomain,
+			Version:     schemas.WorkloadEntry.Version,
+			Name:        name,
+			Namespace:   group.Namespace,
+			Domain:      group.Domain,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: we,
+	}
+}
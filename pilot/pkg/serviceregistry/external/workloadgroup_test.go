@@ -0,0 +1,78 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package external
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func TestNewWorkloadEntry(t *testing.T) {
+	probe := &workload.ReadinessProbe{
+		HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+			TcpSocket: &workload.TCPHealthCheckConfig{Port: 8080},
+		},
+	}
+	group := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:      schemas.WorkloadGroup.Type,
+			Name:      "details",
+			Namespace: "bookinfo",
+		},
+		Spec: &workload.WorkloadGroup{
+			Metadata: &workload.ObjectMeta{
+				Labels:      map[string]string{"app": "details", "version": "v2"},
+				Annotations: map[string]string{"owner": "team-a"},
+			},
+			Template: &workload.WorkloadEntry{
+				Ports:          map[string]uint32{"http": 8080},
+				Labels:         map[string]string{"version": "v1", "tier": "backend"},
+				ServiceAccount: "details",
+			},
+			Probe: probe,
+		},
+	}
+
+	got := NewWorkloadEntry(group, "details-1-2-3-4", "1.2.3.4")
+
+	if got.Type != schemas.WorkloadEntry.Type || got.Name != "details-1-2-3-4" || got.Namespace != "bookinfo" {
+		t.Errorf("NewWorkloadEntry() => %v, want the details-1-2-3-4 WorkloadEntry of namespace bookinfo", got.ConfigMeta)
+	}
+	wantAnnotations := map[string]string{"owner": "team-a", constants.WorkloadGroupAnnotation: "details"}
+	if !reflect.DeepEqual(got.Annotations, wantAnnotations) {
+		t.Errorf("NewWorkloadEntry() annotations => %v, want %v", got.Annotations, wantAnnotations)
+	}
+	want := &workload.WorkloadEntry{
+		Address:        "1.2.3.4",
+		Ports:          map[string]uint32{"http": 8080},
+		Labels:         map[string]string{"app": "details", "version": "v2", "tier": "backend"},
+		ServiceAccount: "details",
+		Probe:          probe,
+	}
+	if !reflect.DeepEqual(got.Spec, want) {
+		t.Errorf("NewWorkloadEntry() spec => %v, want %v", got.Spec, want)
+	}
+
+	// The template of the group must be left untouched
+	template := group.Spec.(*workload.WorkloadGroup).Template
+	if template.Address != "" || template.Labels["version"] != "v1" {
+		t.Errorf("NewWorkloadEntry() modified the template of the group: %v", template)
+	}
+}
@@ -27,7 +27,10 @@ import (
 	"github.com/yl2chen/cidranger"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	listerv1 "k8s.io/client-go/listers/core/v1"
@@ -105,6 +108,11 @@ type Options struct {
 
 	// TrustDomain used in SPIFFE identity
 	TrustDomain string
+
+	// DiscoverySelectors are the label selectors of the namespaces whose services, endpoints and pods
+	// are discovered, from the mesh config. A namespace is discovered if its labels match any of them, or
+	// if there are none. The objects of the other namespaces are neither watched nor cached.
+	DiscoverySelectors []*metav1.LabelSelector
 }
 
 // Controller is a collection of synchronized resource watchers
@@ -118,6 +126,12 @@ type Controller struct {
 	endpoints cacheHandler
 	nodes     cacheHandler
 
+	// namespaces provide the labels of the namespaces, selecting the virtual services allowed to bind to gateways
+	namespaces cache.SharedIndexInformer
+
+	// discoverySelectors select the namespaces whose services, endpoints and pods are processed.
+	discoverySelectors []klabels.Selector
+
 	pods *PodCache
 
 	// Env is set by server to point to the environment, to allow the controller to
@@ -165,25 +179,80 @@ func NewController(client kubernetes.Interface, options Options) *Controller {
 		XDSUpdater:                 options.XDSUpdater,
 		servicesMap:                make(map[host.Name]*model.Service),
 		externalNameSvcInstanceMap: make(map[host.Name][]*model.ServiceInstance),
+		discoverySelectors:         parseDiscoverySelectors(options.DiscoverySelectors),
 	}
 
 	sharedInformers := informers.NewSharedInformerFactoryWithOptions(client, options.ResyncPeriod, informers.WithNamespace(options.WatchedNamespace))
 
-	svcInformer := sharedInformers.Core().V1().Services().Informer()
+	svcInformer := out.newDiscoveryInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Services(options.WatchedNamespace).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Services(options.WatchedNamespace).Watch(opts)
+		},
+	}, &v1.Service{}, options.ResyncPeriod, "Services")
 	out.services = out.createCacheHandler(svcInformer, "Services")
 
-	epInformer := sharedInformers.Core().V1().Endpoints().Informer()
+	epInformer := out.newDiscoveryInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Endpoints(options.WatchedNamespace).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Endpoints(options.WatchedNamespace).Watch(opts)
+		},
+	}, &v1.Endpoints{}, options.ResyncPeriod, "Endpoints")
 	out.endpoints = out.createEDSCacheHandler(epInformer, "Endpoints")
 
 	nodeInformer := sharedInformers.Core().V1().Nodes().Informer()
 	out.nodes = out.createCacheHandler(nodeInformer, "Nodes")
 
-	podInformer := sharedInformers.Core().V1().Pods().Informer()
+	podInformer := out.newDiscoveryInformer(&cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return client.CoreV1().Pods(options.WatchedNamespace).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return client.CoreV1().Pods(options.WatchedNamespace).Watch(opts)
+		},
+	}, &v1.Pod{}, options.ResyncPeriod, "Pod")
 	out.pods = newPodCache(out.createCacheHandler(podInformer, "Pod"), out)
 
+	out.namespaces = sharedInformers.Core().V1().Namespaces().Informer()
+	out.namespaces.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			// The objects of a new namespace may be cached before it is known.
+			if ns := obj.(*v1.Namespace); !out.selectsNamespace(ns) {
+				out.namespaceSelectionChanged(ns, false)
+			}
+		},
+		UpdateFunc: func(old, cur interface{}) {
+			oldNs, curNs := old.(*v1.Namespace), cur.(*v1.Namespace)
+			if !reflect.DeepEqual(oldNs.Labels, curNs.Labels) {
+				incrementEvent("Namespaces", "update")
+				out.queue.Push(kube.Task{Handler: out.namespaceLabelsChanged, Obj: cur, Event: model.EventUpdate})
+				if selected := out.selectsNamespace(curNs); selected != out.selectsNamespace(oldNs) {
+					log.Infof("Namespace %s selected by the discovery selectors: %v", curNs.Name, selected)
+					out.namespaceSelectionChanged(curNs, selected)
+				}
+			}
+		},
+	})
+
 	return out
 }
 
+// namespaceLabelsChanged pushes the gateways, whose virtual services may be selected by the labels of their
+// namespace.
+func (c *Controller) namespaceLabelsChanged(obj interface{}, _ model.Event) error {
+	if c.XDSUpdater != nil {
+		c.XDSUpdater.ConfigUpdate(&model.PushRequest{
+			Full:               true,
+			ConfigTypesUpdated: map[string]struct{}{schemas.Gateway.Type: {}},
+		})
+	}
+	return nil
+}
+
 // notify is the first handler in the handler chain.
 // Returning an error causes repeated execution of the entire chain.
 func (c *Controller) notify(obj interface{}, event model.Event) error {
@@ -204,7 +273,6 @@ func (c *Controller) createCacheHandler(informer cache.SharedIndexInformer, otyp
 
 	informer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			// TODO: filtering functions to skip over un-referenced resources (perf)
 			AddFunc: func(obj interface{}) {
 				incrementEvent(otype, "add")
 				c.queue.Push(kube.Task{Handler: handler.Apply, Obj: obj, Event: model.EventAdd})
@@ -248,7 +316,6 @@ func (c *Controller) createEDSCacheHandler(informer cache.SharedIndexInformer, o
 
 	informer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
-			// TODO: filtering functions to skip over un-referenced resources (perf)
 			AddFunc: func(obj interface{}) {
 				incrementEvent(otype, "add")
 				c.queue.Push(kube.Task{Handler: handler.Apply, Obj: obj, Event: model.EventAdd})
@@ -283,7 +350,8 @@ func (c *Controller) HasSynced() bool {
 	if !c.services.informer.HasSynced() ||
 		!c.endpoints.informer.HasSynced() ||
 		!c.pods.informer.HasSynced() ||
-		!c.nodes.informer.HasSynced() {
+		!c.nodes.informer.HasSynced() ||
+		!c.namespaces.HasSynced() {
 		return false
 	}
 	return true
@@ -296,6 +364,10 @@ func (c *Controller) Run(stop <-chan struct{}) {
 		c.queue.Run(stop)
 	}()
 
+	// The namespaces are synced first, as the discovery selectors filter the other resources by their labels.
+	go c.namespaces.Run(stop)
+	cache.WaitForCacheSync(stop, c.namespaces.HasSynced)
+
 	go c.services.informer.Run(stop)
 	go c.pods.informer.Run(stop)
 	go c.nodes.informer.Run(stop)
@@ -337,6 +409,15 @@ func (c *Controller) GetService(hostname host.Name) (*model.Service, error) {
 	return c.servicesMap[hostname], nil
 }
 
+// NamespaceLabels implements a namespace discovery operation.
+func (c *Controller) NamespaceLabels(namespace string) labels.Instance {
+	obj, exists, err := c.namespaces.GetStore().GetByKey(namespace)
+	if !exists || err != nil {
+		return nil
+	}
+	return obj.(*v1.Namespace).Labels
+}
+
 // GetPodLocality retrieves the locality for a pod.
 func (c *Controller) GetPodLocality(pod *v1.Pod) string {
 	// if pod has `istio-locality` label, skip below ops
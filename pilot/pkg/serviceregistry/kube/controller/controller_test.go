@@ -27,9 +27,11 @@ import (
 	coreV1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
 
 	"istio.io/api/annotation"
 	meshconfig "istio.io/api/mesh/v1alpha1"
@@ -1541,3 +1543,131 @@ func TestEndpointUpdate(t *testing.T) {
 		t.Errorf("Timeout xds push")
 	}
 }
+
+func TestNamespaceLabels(t *testing.T) {
+	controller, fx := newFakeController(t)
+	defer controller.Stop()
+
+	ns := &v1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "nsa", Labels: map[string]string{"team": "web"}}}
+	if _, err := controller.client.CoreV1().Namespaces().Create(ns); err != nil {
+		t.Fatalf("Cannot create namespace (error: %v)", err)
+	}
+	test.Eventually(t, "namespace labels", func() bool {
+		return reflect.DeepEqual(controller.NamespaceLabels("nsa"), labels.Instance{"team": "web"})
+	})
+	if l := controller.NamespaceLabels("nsb"); l != nil {
+		t.Errorf("got labels %v for an unknown namespace", l)
+	}
+
+	// the gateways are pushed when the labels of a namespace change
+	ns.Labels = map[string]string{"team": "api"}
+	if _, err := controller.client.CoreV1().Namespaces().Update(ns); err != nil {
+		t.Fatalf("Cannot update namespace (error: %v)", err)
+	}
+	if ev := fx.Wait("xds"); ev == nil {
+		t.Errorf("Timeout xds push")
+	}
+	if l := controller.NamespaceLabels("nsa"); !reflect.DeepEqual(l, labels.Instance{"team": "api"}) {
+		t.Errorf("got labels %v, want the updated ones", l)
+	}
+}
+
+func TestDiscoverySelectors(t *testing.T) {
+	if selectors := parseDiscoverySelectors([]*metaV1.LabelSelector{
+		{MatchLabels: map[string]string{"istio-discovery": "enabled"}},
+		{MatchExpressions: []metaV1.LabelSelectorRequirement{{Key: "env", Operator: "Unknown"}}},
+	}); len(selectors) != 1 {
+		t.Fatalf("got %d selectors, want only the valid one", len(selectors))
+	}
+
+	selected := &v1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "nsa", Labels: map[string]string{"istio-discovery": "enabled"}}}
+	other := &v1.Namespace{ObjectMeta: metaV1.ObjectMeta{Name: "nsb"}}
+	// The objects of nsb existing before the controller starts are filtered out of the initial lists.
+	existing := []runtime.Object{
+		&v1.Service{ObjectMeta: metaV1.ObjectMeta{Name: "existing", Namespace: "nsb"}},
+		&v1.Endpoints{ObjectMeta: metaV1.ObjectMeta{Name: "existing", Namespace: "nsb"}},
+		&v1.Pod{ObjectMeta: metaV1.ObjectMeta{Name: "existing", Namespace: "nsb"}},
+	}
+	fx := NewFakeXDS()
+	controller := NewController(fake.NewSimpleClientset(append(existing, selected, other)...), Options{
+		ResyncPeriod:       resync,
+		DomainSuffix:       domainSuffix,
+		XDSUpdater:         fx,
+		DiscoverySelectors: []*metaV1.LabelSelector{{MatchLabels: map[string]string{"istio-discovery": "enabled"}}},
+	})
+	_ = controller.AppendInstanceHandler(func(instance *model.ServiceInstance, event model.Event) {})
+	_ = controller.AppendServiceHandler(func(service *model.Service, event model.Event) {})
+	go controller.Run(controller.stop)
+	defer controller.Stop()
+	cache.WaitForCacheSync(controller.stop, controller.HasSynced)
+
+	stores := map[string]cache.Store{
+		"services":  controller.services.informer.GetStore(),
+		"endpoints": controller.endpoints.informer.GetStore(),
+		"pods":      controller.pods.informer.GetStore(),
+	}
+	cached := func(namespace, name string) []string {
+		var out []string
+		for kind, store := range stores {
+			if _, exists, _ := store.GetByKey(kube.KeyFunc(name, namespace)); exists {
+				out = append(out, kind)
+			}
+		}
+		return out
+	}
+	if got := cached("nsb", "existing"); len(got) != 0 {
+		t.Fatalf("Unexpected %v of a namespace not selected in the caches", got)
+	}
+
+	// The objects of nsb created afterwards are filtered out of the watches.
+	svc1 := kube.ServiceHostname("svc1", "nsa", domainSuffix)
+	svc2 := kube.ServiceHostname("svc2", "nsb", domainSuffix)
+	createService(controller, "svc2", "nsb", nil, []int32{8080}, map[string]string{"app": "prod-app"}, t)
+	createService(controller, "svc1", "nsa", nil, []int32{8080}, map[string]string{"app": "prod-app"}, t)
+	if ev := fx.Wait("service"); ev == nil || ev.ID != "svc1" {
+		t.Fatalf("Expecting only the service of the selected namespace, got %v", ev)
+	}
+	if svc, _ := controller.GetService(svc2); svc != nil {
+		t.Fatalf("Unexpected service %s of a namespace not selected", svc2)
+	}
+	if got := cached("nsb", "svc2"); len(got) != 0 {
+		t.Fatalf("Unexpected %v of a namespace not selected in the caches", got)
+	}
+
+	// the objects of a namespace are cached and discovered once it is selected, and removed once it is no longer
+	other.Labels = map[string]string{"istio-discovery": "enabled"}
+	if _, err := controller.client.CoreV1().Namespaces().Update(other); err != nil {
+		t.Fatalf("Cannot update namespace (error: %v)", err)
+	}
+	// the relist fires the events of the services of the selected namespace
+	events := map[string]bool{}
+	for len(events) < 2 {
+		ev := fx.Wait("service")
+		if ev == nil || (ev.ID != "existing" && ev.ID != "svc2") {
+			t.Fatalf("Expecting the events of the services of the selected namespace, got %v", ev)
+		}
+		events[ev.ID] = true
+	}
+	test.Eventually(t, "service of the selected namespace", func() bool {
+		svc, _ := controller.GetService(svc2)
+		return svc != nil
+	})
+	test.Eventually(t, "objects of the selected namespace in all the caches", func() bool {
+		return len(cached("nsb", "existing")) == len(stores)
+	})
+
+	selected.Labels = nil
+	if _, err := controller.client.CoreV1().Namespaces().Update(selected); err != nil {
+		t.Fatalf("Cannot update namespace (error: %v)", err)
+	}
+	if ev := fx.Wait("service"); ev == nil || ev.ID != "svc1" {
+		t.Fatalf("Expecting the event of the service of the unselected namespace, got %v", ev)
+	}
+	test.Eventually(t, "removal of the service of the unselected namespace", func() bool {
+		svc, _ := controller.GetService(svc1)
+		return svc == nil
+	})
+	test.Eventually(t, "removal of the objects of the unselected namespace from the caches", func() bool {
+		return len(cached("nsa", "svc1")) == 0
+	})
+}
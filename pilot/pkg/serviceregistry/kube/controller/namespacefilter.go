@@ -0,0 +1,235 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"istio.io/pkg/log"
+)
+
+// discoveryInformer is an informer of namespaced objects, which only lists, watches and caches the
+// objects of the namespaces selected by the discovery selectors.
+type discoveryInformer struct {
+	cache.SharedIndexInformer
+
+	mu sync.Mutex
+	// watch is the current watch of the informer, nil until it is started.
+	watch *filteredWatch
+	// relistPending is set when a relist is requested, until the objects are listed again.
+	relistPending bool
+}
+
+// filteredWatch forwards the events of the objects discovered by the controller. It ends with an expired
+// resource version error when a relist is requested, which makes the reflector of the informer list the
+// objects again and update the cache, firing the events of the objects added or removed by the filter.
+type filteredWatch struct {
+	watch.Interface
+
+	result   chan watch.Event
+	relist   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (c *Controller) newFilteredWatch(w watch.Interface, otype string) *filteredWatch {
+	fw := &filteredWatch{
+		Interface: w,
+		result:    make(chan watch.Event),
+		relist:    make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(fw.result)
+		for {
+			select {
+			case in, ok := <-w.ResultChan():
+				if !ok {
+					return
+				}
+				if !c.discovered(in.Object) {
+					incrementEvent(otype, "filtered")
+					continue
+				}
+				select {
+				case fw.result <- in:
+				case <-fw.done:
+					return
+				}
+			case <-fw.relist:
+				w.Stop()
+				expired := errors.NewResourceExpired("relisting the objects of the discovered namespaces")
+				select {
+				case fw.result <- watch.Event{Type: watch.Error, Object: &expired.ErrStatus}:
+				case <-fw.done:
+				}
+				return
+			case <-fw.done:
+				return
+			}
+		}
+	}()
+	return fw
+}
+
+// ResultChan implements watch.Interface.
+func (fw *filteredWatch) ResultChan() <-chan watch.Event {
+	return fw.result
+}
+
+// Stop implements watch.Interface.
+func (fw *filteredWatch) Stop() {
+	fw.stopOnce.Do(func() {
+		close(fw.done)
+		fw.Interface.Stop()
+	})
+}
+
+// requestRelist ends the watch with an expired resource version error.
+func (fw *filteredWatch) requestRelist() {
+	select {
+	case fw.relist <- struct{}{}:
+	default:
+	}
+}
+
+// parseDiscoverySelectors parses the label selectors of the discovered namespaces. The invalid
+// selectors are logged and ignored.
+func parseDiscoverySelectors(selectors []*metav1.LabelSelector) []klabels.Selector {
+	out := make([]klabels.Selector, 0, len(selectors))
+	for _, s := range selectors {
+		selector, err := metav1.LabelSelectorAsSelector(s)
+		if err != nil {
+			log.Errorf("Ignoring invalid discovery selector %v: %v", s, err)
+			continue
+		}
+		out = append(out, selector)
+	}
+	return out
+}
+
+// newDiscoveryInformer creates the informer of the objects, filtering the lists and watches of lw with the
+// discovery selectors.
+func (c *Controller) newDiscoveryInformer(lw *cache.ListWatch, objType runtime.Object, resyncPeriod time.Duration,
+	otype string) *discoveryInformer {
+	i := &discoveryInformer{}
+	filtered := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			i.mu.Lock()
+			i.relistPending = false
+			i.mu.Unlock()
+
+			list, err := lw.List(options)
+			if err != nil || len(c.discoverySelectors) == 0 {
+				return list, err
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+			discovered := make([]runtime.Object, 0, len(items))
+			for _, item := range items {
+				if c.discovered(item) {
+					discovered = append(discovered, item)
+				}
+			}
+			if err := meta.SetList(list, discovered); err != nil {
+				return nil, err
+			}
+			return list, nil
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.Watch(options)
+			if err != nil {
+				return w, err
+			}
+			fw := c.newFilteredWatch(w, otype)
+			i.mu.Lock()
+			defer i.mu.Unlock()
+			i.watch = fw
+			if i.relistPending {
+				// The selection changed after the objects were listed.
+				fw.requestRelist()
+			}
+			return fw, nil
+		},
+	}
+	i.SharedIndexInformer = cache.NewSharedIndexInformer(filtered, objType, resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	return i
+}
+
+// relist lists the objects again, updating the cache and firing the events of the objects of the namespaces
+// which became selected or unselected by the discovery selectors.
+func (i *discoveryInformer) relist() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.relistPending = true
+	if i.watch != nil {
+		i.watch.requestRelist()
+	}
+}
+
+// selectsNamespace returns whether the labels of a namespace match any of the discovery selectors,
+// or if there are none.
+func (c *Controller) selectsNamespace(ns *v1.Namespace) bool {
+	if len(c.discoverySelectors) == 0 {
+		return true
+	}
+	for _, selector := range c.discoverySelectors {
+		if selector.Matches(klabels.Set(ns.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// discovered returns whether the object is in a namespace selected by the discovery selectors. The
+// cluster scoped objects, and the ones of namespaces not known yet, are always discovered, so the
+// deletions are not missed when a namespace is removed before its content.
+func (c *Controller) discovered(obj interface{}) bool {
+	if len(c.discoverySelectors) == 0 {
+		return true
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil || m.GetNamespace() == "" {
+		return true
+	}
+	item, exists, err := c.namespaces.GetStore().GetByKey(m.GetNamespace())
+	if !exists || err != nil {
+		return true
+	}
+	return c.selectsNamespace(item.(*v1.Namespace))
+}
+
+// namespaceSelectionChanged relists the services, pods and endpoints when a namespace becomes selected or
+// unselected by the discovery selectors, so that the objects of the namespace, which were not watched while it
+// was not selected, are added to or removed from the caches.
+func (c *Controller) namespaceSelectionChanged(ns *v1.Namespace, selected bool) {
+	log.Debugf("Relisting the objects of namespace %s selected by the discovery selectors: %v", ns.Name, selected)
+	for _, h := range []cacheHandler{c.services, c.pods.cacheHandler, c.endpoints} {
+		h.informer.(*discoveryInformer).relist()
+	}
+}
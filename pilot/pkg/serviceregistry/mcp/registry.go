@@ -0,0 +1,238 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mcp implements a service registry fed over MCP by an external inventory system, for the
+// meshes whose source of truth of the services is not Kubernetes. The inventory serves the services as
+// ServiceEntries and their instances as their endpoints, or as WorkloadEntries selected through the
+// workload selector annotation of the ServiceEntries.
+package mcp
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/serviceregistry/external"
+	"istio.io/istio/pilot/pkg/serviceregistry/memory"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/mcp/sink"
+	"istio.io/pkg/log"
+)
+
+// Collections are the MCP collections the registry subscribes to.
+var Collections = []string{schemas.ServiceEntry.Collection, schemas.WorkloadEntry.Collection}
+
+var schemasByCollection = map[string]schema.Instance{
+	schemas.ServiceEntry.Collection:  schemas.ServiceEntry,
+	schemas.WorkloadEntry.Collection: schemas.WorkloadEntry,
+}
+
+// Registry is the service registry of the ServiceEntries and WorkloadEntries received over MCP. They
+// are converted to services and instances like the ServiceEntries of the config store, and kept in an
+// in-memory registry notifying the handlers and the XDSUpdater, for incremental EDS pushes. The
+// services are keyed by hostname: the last ServiceEntry of a host received wins.
+type Registry struct {
+	*memory.Registry
+	domainSuffix string
+
+	mutex sync.Mutex
+	// configs are the received configs, by collection and namespace/name
+	configs map[string]map[string]model.Config
+	// hostnames are the hostnames of the services of each ServiceEntry, by namespace/name
+	hostnames map[string][]host.Name
+	synced    uint32
+}
+
+var _ sink.Updater = &Registry{}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry(domainSuffix string) *Registry {
+	return &Registry{
+		Registry:     memory.NewRegistry(),
+		domainSuffix: domainSuffix,
+		configs: map[string]map[string]model.Config{
+			schemas.ServiceEntry.Collection:  {},
+			schemas.WorkloadEntry.Collection: {},
+		},
+		hostnames: map[string][]host.Name{},
+	}
+}
+
+// HasSynced returns true once the first change was applied.
+func (r *Registry) HasSynced() bool {
+	return atomic.LoadUint32(&r.synced) != 0
+}
+
+// Apply implements sink.Updater, updating the services and instances of the changed configs.
+func (r *Registry) Apply(change *sink.Change) error {
+	s, f := schemasByCollection[change.Collection]
+	if !f {
+		return fmt.Errorf("apply: collection not supported %s", change.Collection)
+	}
+	defer atomic.StoreUint32(&r.synced, 1)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	configs := r.configs[change.Collection]
+	changed := make(map[string]bool)
+	if !change.Incremental {
+		// The change is the full state, anything missing was deleted.
+		received := make(map[string]model.Config, len(change.Objects))
+		for _, obj := range change.Objects {
+			if cfg, ok := r.convertToConfig(s, obj); ok {
+				received[cfg.Namespace+"/"+cfg.Name] = cfg
+			}
+		}
+		for key := range configs {
+			if _, f := received[key]; !f {
+				changed[key] = true
+			}
+		}
+		for key, cfg := range received {
+			if old, f := configs[key]; !f || old.ResourceVersion != cfg.ResourceVersion {
+				changed[key] = true
+			}
+		}
+		r.configs[change.Collection] = received
+	} else {
+		for _, key := range change.Removed {
+			delete(configs, key)
+			changed[key] = true
+		}
+		for _, obj := range change.Objects {
+			if cfg, ok := r.convertToConfig(s, obj); ok {
+				key := cfg.Namespace + "/" + cfg.Name
+				configs[key] = cfg
+				changed[key] = true
+			}
+		}
+	}
+
+	if change.Collection == schemas.ServiceEntry.Collection {
+		for key := range changed {
+			r.updateServiceEntry(key, true)
+		}
+		return nil
+	}
+
+	// The instances of the ServiceEntries of the namespaces of the changed WorkloadEntries are updated.
+	namespaces := make(map[string]bool)
+	for key := range changed {
+		namespaces[strings.SplitN(key, "/", 2)[0]] = true
+	}
+	for key, cfg := range r.configs[schemas.ServiceEntry.Collection] {
+		if namespaces[cfg.Namespace] {
+			r.updateServiceEntry(key, false)
+		}
+	}
+	return nil
+}
+
+// updateServiceEntry updates the instances of the services of a ServiceEntry, and the services
+// themselves if the ServiceEntry changed.
+func (r *Registry) updateServiceEntry(key string, serviceChanged bool) {
+	cfg, exists := r.configs[schemas.ServiceEntry.Collection][key]
+	if !exists {
+		delete(r.hostnames, key)
+	} else {
+		var workloadEntries []model.Config
+		for _, we := range r.configs[schemas.WorkloadEntry.Collection] {
+			if we.Namespace == cfg.Namespace {
+				workloadEntries = append(workloadEntries, we)
+			}
+		}
+		instances := make(map[host.Name][]*model.ServiceInstance)
+		for _, instance := range external.ConvertInstances(cfg, workloadEntries) {
+			instances[instance.Service.Hostname] = append(instances[instance.Service.Hostname], instance)
+		}
+
+		hostnames := make([]host.Name, 0)
+		for _, svc := range external.ConvertServices(cfg) {
+			hostnames = append(hostnames, svc.Hostname)
+			if existing, _ := r.GetService(svc.Hostname); serviceChanged || existing == nil {
+				r.AddService(svc)
+			}
+			if err := r.SetInstances(svc.Hostname, instances[svc.Hostname]); err != nil {
+				log.Warnf("failed to set the instances of %s: %v", svc.Hostname, err)
+			}
+		}
+		r.hostnames[key] = hostnames
+	}
+
+	for _, hostname := range r.orphanHostnames() {
+		r.RemoveService(hostname)
+	}
+}
+
+// orphanHostnames returns the hostnames of the services no ServiceEntry defines anymore.
+func (r *Registry) orphanHostnames() []host.Name {
+	defined := make(map[host.Name]bool)
+	for _, hostnames := range r.hostnames {
+		for _, hostname := range hostnames {
+			defined[hostname] = true
+		}
+	}
+	out := make([]host.Name, 0)
+	services, _ := r.Services()
+	for _, svc := range services {
+		if !defined[svc.Hostname] {
+			out = append(out, svc.Hostname)
+		}
+	}
+	return out
+}
+
+func (r *Registry) convertToConfig(s schema.Instance, obj *sink.Object) (model.Config, bool) {
+	namespace, name := "", obj.Metadata.Name
+	if segments := strings.SplitN(obj.Metadata.Name, "/", 2); len(segments) == 2 {
+		namespace, name = segments[0], segments[1]
+	}
+	createTime := time.Now()
+	if obj.Metadata.CreateTime != nil {
+		var err error
+		if createTime, err = types.TimestampFromProto(obj.Metadata.CreateTime); err != nil {
+			log.Warnf("Discarding incoming MCP resource: invalid resource timestamp (%s/%s): %v", namespace, name, err)
+			return model.Config{}, false
+		}
+	}
+
+	cfg := model.Config{
+		ConfigMeta: model.ConfigMeta{
+			Type:              s.Type,
+			Group:             s.Group,
+			Version:           s.Version,
+			Name:              name,
+			Namespace:         namespace,
+			ResourceVersion:   obj.Metadata.Version,
+			CreationTimestamp: createTime,
+			Labels:            obj.Metadata.Labels,
+			Annotations:       obj.Metadata.Annotations,
+			Domain:            r.domainSuffix,
+		},
+		Spec: obj.Body,
+	}
+	if err := s.Validate(cfg.Name, cfg.Namespace, cfg.Spec); err != nil {
+		log.Warnf("Discarding incoming MCP resource: validation failed (%s/%s): %v", namespace, name, err)
+		return model.Config{}, false
+	}
+	return cfg, true
+}
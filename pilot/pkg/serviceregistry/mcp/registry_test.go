@@ -0,0 +1,177 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcp
+
+import (
+	"testing"
+
+	mcpapi "istio.io/api/mcp/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/schemas"
+	"istio.io/istio/pkg/mcp/sink"
+)
+
+// fakeXdsUpdater records the number of endpoints of the last EDS update of each hostname.
+type fakeXdsUpdater struct {
+	endpoints map[string]int
+}
+
+func (f *fakeXdsUpdater) EDSUpdate(_, hostname, _ string, entry []*model.IstioEndpoint) error {
+	f.endpoints[hostname] = len(entry)
+	return nil
+}
+
+func (f *fakeXdsUpdater) SvcUpdate(_, _, _ string, _ model.Event) {}
+
+func (f *fakeXdsUpdater) ConfigUpdate(*model.PushRequest) {}
+
+func (f *fakeXdsUpdater) ProxyUpdate(_, _ string) {}
+
+func serviceEntry(name, hostname, version string, endpoints ...string) *sink.Object {
+	se := &networking.ServiceEntry{
+		Hosts:      []string{hostname},
+		Ports:      []*networking.Port{{Number: 80, Name: "http", Protocol: "HTTP"}},
+		Location:   networking.ServiceEntry_MESH_INTERNAL,
+		Resolution: networking.ServiceEntry_STATIC,
+	}
+	for _, ep := range endpoints {
+		se.Endpoints = append(se.Endpoints, &networking.ServiceEntry_Endpoint{Address: ep})
+	}
+	return &sink.Object{
+		Metadata: &mcpapi.Metadata{
+			Name:        "inventory/" + name,
+			Version:     version,
+			Annotations: map[string]string{constants.ServiceEntryWorkloadSelectorAnnotation: "app=" + name},
+		},
+		Body: se,
+	}
+}
+
+func workloadEntry(name, app, address string) *sink.Object {
+	return &sink.Object{
+		Metadata: &mcpapi.Metadata{Name: "inventory/" + name, Version: "1"},
+		Body: &workload.WorkloadEntry{
+			Address: address,
+			Labels:  map[string]string{"app": app},
+		},
+	}
+}
+
+func instanceCount(t *testing.T, r *Registry, hostname string) int {
+	t.Helper()
+	svc, _ := r.GetService(host.Name(hostname))
+	if svc == nil {
+		return -1
+	}
+	instances, err := r.InstancesByPort(svc, 80, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(instances)
+}
+
+func TestRegistry(t *testing.T) {
+	updater := &fakeXdsUpdater{endpoints: map[string]int{}}
+	r := NewRegistry("cluster.local")
+	r.XDSUpdater = updater
+
+	if err := r.Apply(&sink.Change{
+		Collection: schemas.ServiceEntry.Collection,
+		Objects: []*sink.Object{
+			serviceEntry("ratings", "ratings.inventory", "1"),
+			serviceEntry("details", "details.inventory", "1", "10.0.0.1", "10.0.0.2"),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !r.HasSynced() {
+		t.Error("expected the registry to be synced")
+	}
+	if got := instanceCount(t, r, "details.inventory"); got != 2 {
+		t.Errorf("got %d instances of details, want its 2 endpoints", got)
+	}
+	if got := instanceCount(t, r, "ratings.inventory"); got != 0 {
+		t.Errorf("got %d instances of ratings, want 0", got)
+	}
+
+	// the instances of the WorkloadEntries are added incrementally
+	if err := r.Apply(&sink.Change{
+		Collection:  schemas.WorkloadEntry.Collection,
+		Incremental: true,
+		Objects: []*sink.Object{
+			workloadEntry("ratings-1", "ratings", "10.0.1.1"),
+			workloadEntry("ratings-2", "ratings", "10.0.1.2"),
+			workloadEntry("invalid", "ratings", ""),
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := instanceCount(t, r, "ratings.inventory"); got != 2 {
+		t.Errorf("got %d instances of ratings, want 2", got)
+	}
+	if updater.endpoints["ratings.inventory"] != 2 {
+		t.Errorf("got %d endpoints pushed for ratings, want 2", updater.endpoints["ratings.inventory"])
+	}
+	proxyInstances, _ := r.GetProxyServiceInstances(&model.Proxy{IPAddresses: []string{"10.0.1.1"}})
+	if len(proxyInstances) != 1 || proxyInstances[0].Service.Hostname != "ratings.inventory" {
+		t.Errorf("got proxy instances %v", proxyInstances)
+	}
+
+	if err := r.Apply(&sink.Change{
+		Collection:  schemas.WorkloadEntry.Collection,
+		Incremental: true,
+		Removed:     []string{"inventory/ratings-1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := instanceCount(t, r, "ratings.inventory"); got != 1 {
+		t.Errorf("got %d instances of ratings, want 1", got)
+	}
+
+	// a full state without details removes it
+	if err := r.Apply(&sink.Change{
+		Collection: schemas.ServiceEntry.Collection,
+		Objects:    []*sink.Object{serviceEntry("ratings", "ratings.inventory", "1")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := instanceCount(t, r, "details.inventory"); got != -1 {
+		t.Errorf("expected details to be removed, got %d instances", got)
+	}
+	if got := instanceCount(t, r, "ratings.inventory"); got != 1 {
+		t.Errorf("got %d instances of ratings, want 1", got)
+	}
+
+	// a ServiceEntry moving to another host removes the previous one
+	if err := r.Apply(&sink.Change{
+		Collection: schemas.ServiceEntry.Collection,
+		Objects:    []*sink.Object{serviceEntry("ratings", "ratings.v2.inventory", "2")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	services, _ := r.Services()
+	if len(services) != 1 || services[0].Hostname != "ratings.v2.inventory" {
+		t.Errorf("got services %v", services)
+	}
+
+	if err := r.Apply(&sink.Change{Collection: schemas.VirtualService.Collection}); err == nil {
+		t.Error("expected an error for an unsupported collection")
+	}
+}
@@ -0,0 +1,339 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// Registry is a thread-safe in-memory service registry, notifying its handlers of the changes of its
+// services and instances. Unlike the mock ServiceDiscovery, it is meant to be used outside of tests,
+// e.g. by the tools and perf harnesses embedding the discovery server with synthetic services.
+type Registry struct {
+	// ClusterID is the cluster of the registry, passed to the XDSUpdater.
+	ClusterID string
+	// XDSUpdater, if set, is notified of the changes of the endpoints, for incremental EDS pushes.
+	XDSUpdater model.XDSUpdater
+
+	mutex     sync.RWMutex
+	services  map[host.Name]*model.Service
+	instances map[host.Name][]*model.ServiceInstance
+
+	handlersMutex    sync.RWMutex
+	serviceHandlers  []func(*model.Service, model.Event)
+	instanceHandlers []func(*model.ServiceInstance, model.Event)
+}
+
+var _ model.ServiceDiscovery = &Registry{}
+var _ model.Controller = &Registry{}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		services:  map[host.Name]*model.Service{},
+		instances: map[host.Name][]*model.ServiceInstance{},
+	}
+}
+
+// AddService adds or replaces a service. The instances of the service it replaces are kept.
+func (r *Registry) AddService(svc *model.Service) {
+	r.mutex.Lock()
+	_, exists := r.services[svc.Hostname]
+	r.services[svc.Hostname] = svc
+	for i, instance := range r.instances[svc.Hostname] {
+		updated := *instance
+		updated.Service = svc
+		r.instances[svc.Hostname][i] = &updated
+	}
+	r.mutex.Unlock()
+
+	event := model.EventAdd
+	if exists {
+		event = model.EventUpdate
+	}
+	r.serviceChanged(svc, event)
+}
+
+// RemoveService removes a service and its instances.
+func (r *Registry) RemoveService(hostname host.Name) {
+	r.mutex.Lock()
+	svc, exists := r.services[hostname]
+	instances := r.instances[hostname]
+	delete(r.services, hostname)
+	delete(r.instances, hostname)
+	r.mutex.Unlock()
+	if !exists {
+		return
+	}
+
+	for _, instance := range instances {
+		r.instanceChanged(instance, model.EventDelete)
+	}
+	if len(instances) > 0 && r.XDSUpdater != nil {
+		_ = r.XDSUpdater.EDSUpdate(r.ClusterID, string(hostname), svc.Attributes.Namespace, nil)
+	}
+	r.serviceChanged(svc, model.EventDelete)
+}
+
+// AddInstance adds an instance of a service, replacing the one with the same address and port if any.
+// The service of the instance must have been added.
+func (r *Registry) AddInstance(instance *model.ServiceInstance) error {
+	hostname := instance.Service.Hostname
+	r.mutex.Lock()
+	svc, f := r.services[hostname]
+	if !f {
+		r.mutex.Unlock()
+		return fmt.Errorf("service %s not found", hostname)
+	}
+	instance.Service = svc
+	event := model.EventAdd
+	instances := make([]*model.ServiceInstance, 0, len(r.instances[hostname])+1)
+	for _, existing := range r.instances[hostname] {
+		if sameEndpoint(existing, instance) {
+			event = model.EventUpdate
+			continue
+		}
+		instances = append(instances, existing)
+	}
+	r.instances[hostname] = append(instances, instance)
+	endpoints := istioEndpoints(r.instances[hostname])
+	r.mutex.Unlock()
+
+	r.instanceChanged(instance, event)
+	r.endpointsChanged(svc, endpoints)
+	return nil
+}
+
+// RemoveInstance removes the instance of a service with the address and service port.
+func (r *Registry) RemoveInstance(hostname host.Name, address string, servicePort int) {
+	r.mutex.Lock()
+	svc := r.services[hostname]
+	var removed []*model.ServiceInstance
+	instances := make([]*model.ServiceInstance, 0, len(r.instances[hostname]))
+	for _, existing := range r.instances[hostname] {
+		if existing.Endpoint.Address == address && existing.Endpoint.ServicePort.Port == servicePort {
+			removed = append(removed, existing)
+			continue
+		}
+		instances = append(instances, existing)
+	}
+	r.instances[hostname] = instances
+	endpoints := istioEndpoints(instances)
+	r.mutex.Unlock()
+	if len(removed) == 0 {
+		return
+	}
+
+	for _, instance := range removed {
+		r.instanceChanged(instance, model.EventDelete)
+	}
+	r.endpointsChanged(svc, endpoints)
+}
+
+// SetInstances replaces all the instances of a service.
+func (r *Registry) SetInstances(hostname host.Name, instances []*model.ServiceInstance) error {
+	r.mutex.Lock()
+	svc, f := r.services[hostname]
+	if !f {
+		r.mutex.Unlock()
+		return fmt.Errorf("service %s not found", hostname)
+	}
+	previous := r.instances[hostname]
+	for _, instance := range instances {
+		instance.Service = svc
+	}
+	r.instances[hostname] = append([]*model.ServiceInstance{}, instances...)
+	endpoints := istioEndpoints(instances)
+	r.mutex.Unlock()
+
+	for _, instance := range previous {
+		r.instanceChanged(instance, model.EventDelete)
+	}
+	for _, instance := range instances {
+		r.instanceChanged(instance, model.EventAdd)
+	}
+	r.endpointsChanged(svc, endpoints)
+	return nil
+}
+
+func sameEndpoint(a, b *model.ServiceInstance) bool {
+	return a.Endpoint.Address == b.Endpoint.Address && a.Endpoint.Port == b.Endpoint.Port &&
+		a.Endpoint.ServicePort.Port == b.Endpoint.ServicePort.Port
+}
+
+func istioEndpoints(instances []*model.ServiceInstance) []*model.IstioEndpoint {
+	out := make([]*model.IstioEndpoint, 0, len(instances))
+	for _, instance := range instances {
+		out = append(out, &model.IstioEndpoint{
+			Labels:          instance.Labels,
+			Family:          instance.Endpoint.Family,
+			Address:         instance.Endpoint.Address,
+			ServicePortName: instance.Endpoint.ServicePort.Name,
+			ServiceAccount:  instance.ServiceAccount,
+			Network:         instance.Endpoint.Network,
+			Locality:        instance.Endpoint.Locality,
+			EndpointPort:    uint32(instance.Endpoint.Port),
+			LbWeight:        instance.Endpoint.LbWeight,
+			Attributes:      instance.Service.Attributes,
+		})
+	}
+	return out
+}
+
+func (r *Registry) serviceChanged(svc *model.Service, event model.Event) {
+	if r.XDSUpdater != nil {
+		r.XDSUpdater.SvcUpdate(r.ClusterID, string(svc.Hostname), svc.Attributes.Namespace, event)
+	}
+	r.handlersMutex.RLock()
+	defer r.handlersMutex.RUnlock()
+	for _, f := range r.serviceHandlers {
+		f(svc, event)
+	}
+}
+
+func (r *Registry) instanceChanged(instance *model.ServiceInstance, event model.Event) {
+	r.handlersMutex.RLock()
+	defer r.handlersMutex.RUnlock()
+	for _, f := range r.instanceHandlers {
+		f(instance, event)
+	}
+}
+
+func (r *Registry) endpointsChanged(svc *model.Service, endpoints []*model.IstioEndpoint) {
+	if r.XDSUpdater != nil {
+		_ = r.XDSUpdater.EDSUpdate(r.ClusterID, string(svc.Hostname), svc.Attributes.Namespace, endpoints)
+	}
+}
+
+// AppendServiceHandler implements model.Controller.
+func (r *Registry) AppendServiceHandler(f func(*model.Service, model.Event)) error {
+	r.handlersMutex.Lock()
+	defer r.handlersMutex.Unlock()
+	r.serviceHandlers = append(r.serviceHandlers, f)
+	return nil
+}
+
+// AppendInstanceHandler implements model.Controller.
+func (r *Registry) AppendInstanceHandler(f func(*model.ServiceInstance, model.Event)) error {
+	r.handlersMutex.Lock()
+	defer r.handlersMutex.Unlock()
+	r.instanceHandlers = append(r.instanceHandlers, f)
+	return nil
+}
+
+// Run implements model.Controller. The changes are notified synchronously, there is nothing to run.
+func (r *Registry) Run(<-chan struct{}) {}
+
+// Services implements model.ServiceDiscovery, sorted by hostname.
+func (r *Registry) Services() ([]*model.Service, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	out := make([]*model.Service, 0, len(r.services))
+	for _, svc := range r.services {
+		out = append(out, svc)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Hostname < out[j].Hostname
+	})
+	return out, nil
+}
+
+// GetService implements model.ServiceDiscovery.
+func (r *Registry) GetService(hostname host.Name) (*model.Service, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.services[hostname], nil
+}
+
+// InstancesByPort implements model.ServiceDiscovery.
+func (r *Registry) InstancesByPort(svc *model.Service, port int, labels labels.Collection) ([]*model.ServiceInstance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	out := make([]*model.ServiceInstance, 0)
+	for _, instance := range r.instances[svc.Hostname] {
+		if instance.Endpoint.ServicePort.Port == port && labels.HasSubsetOf(instance.Labels) {
+			out = append(out, instance)
+		}
+	}
+	return out, nil
+}
+
+// GetProxyServiceInstances implements model.ServiceDiscovery.
+func (r *Registry) GetProxyServiceInstances(proxy *model.Proxy) ([]*model.ServiceInstance, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	out := make([]*model.ServiceInstance, 0)
+	for _, instances := range r.instances {
+		for _, instance := range instances {
+			for _, ip := range proxy.IPAddresses {
+				if instance.Endpoint.Address == ip {
+					out = append(out, instance)
+					break
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// GetProxyWorkloadLabels implements model.ServiceDiscovery.
+func (r *Registry) GetProxyWorkloadLabels(proxy *model.Proxy) (labels.Collection, error) {
+	instances, _ := r.GetProxyServiceInstances(proxy)
+	out := make(labels.Collection, 0, len(instances))
+	for _, instance := range instances {
+		out = append(out, instance.Labels)
+	}
+	return out, nil
+}
+
+// ManagementPorts implements model.ServiceDiscovery.
+func (r *Registry) ManagementPorts(string) model.PortList {
+	return nil
+}
+
+// WorkloadHealthCheckInfo implements model.ServiceDiscovery.
+func (r *Registry) WorkloadHealthCheckInfo(string) model.ProbeList {
+	return nil
+}
+
+// GetIstioServiceAccounts implements model.ServiceDiscovery.
+func (r *Registry) GetIstioServiceAccounts(svc *model.Service, ports []int) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	saSet := make(map[string]bool)
+	for _, instance := range r.instances[svc.Hostname] {
+		if instance.ServiceAccount == "" {
+			continue
+		}
+		for _, port := range ports {
+			if instance.Endpoint.ServicePort.Port == port {
+				saSet[instance.ServiceAccount] = true
+				break
+			}
+		}
+	}
+	out := make([]string, 0, len(saSet))
+	for sa := range saSet {
+		out = append(out, sa)
+	}
+	sort.Strings(out)
+	return out
+}
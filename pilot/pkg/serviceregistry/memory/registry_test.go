@@ -0,0 +1,149 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// fakeXdsUpdater records the endpoints of the EDS updates.
+type fakeXdsUpdater struct {
+	mutex     sync.Mutex
+	endpoints map[string]int
+}
+
+func (f *fakeXdsUpdater) EDSUpdate(_, hostname, _ string, entry []*model.IstioEndpoint) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.endpoints[hostname] = len(entry)
+	return nil
+}
+
+func (f *fakeXdsUpdater) SvcUpdate(_, _, _ string, _ model.Event) {}
+
+func (f *fakeXdsUpdater) ConfigUpdate(*model.PushRequest) {}
+
+func (f *fakeXdsUpdater) ProxyUpdate(_, _ string) {}
+
+func makeRegistryInstance(svc *model.Service, address string, lbls labels.Instance) *model.ServiceInstance {
+	return &model.ServiceInstance{
+		Service: svc,
+		Endpoint: model.NetworkEndpoint{
+			Address:     address,
+			Port:        8080,
+			ServicePort: svc.Ports[0],
+		},
+		Labels: lbls,
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	updater := &fakeXdsUpdater{endpoints: map[string]int{}}
+	r := NewRegistry()
+	r.XDSUpdater = updater
+	var events []string
+	_ = r.AppendServiceHandler(func(svc *model.Service, event model.Event) {
+		events = append(events, fmt.Sprintf("service %s %s", event, svc.Hostname))
+	})
+	_ = r.AppendInstanceHandler(func(instance *model.ServiceInstance, event model.Event) {
+		events = append(events, fmt.Sprintf("instance %s %s", event, instance.Endpoint.Address))
+	})
+
+	svc := MakeService("hello.default.svc.cluster.local", "10.1.0.0")
+	if err := r.AddInstance(makeRegistryInstance(svc, "1.1.1.1", nil)); err == nil {
+		t.Error("expected an error adding an instance of an unknown service")
+	}
+	r.AddService(svc)
+	_ = r.AddInstance(makeRegistryInstance(svc, "1.1.1.1", labels.Instance{"version": "v1"}))
+	_ = r.AddInstance(makeRegistryInstance(svc, "1.1.1.2", labels.Instance{"version": "v2"}))
+	_ = r.AddInstance(makeRegistryInstance(svc, "1.1.1.2", labels.Instance{"version": "v3"}))
+
+	if got, _ := r.GetService(svc.Hostname); got != svc {
+		t.Errorf("got service %v", got)
+	}
+	instances, _ := r.InstancesByPort(svc, 80, labels.Collection{{"version": "v3"}})
+	if len(instances) != 1 || instances[0].Endpoint.Address != "1.1.1.2" {
+		t.Errorf("got instances %v", instances)
+	}
+	instances, _ = r.GetProxyServiceInstances(&model.Proxy{IPAddresses: []string{"1.1.1.1"}})
+	if len(instances) != 1 || instances[0].Labels["version"] != "v1" {
+		t.Errorf("got proxy instances %v", instances)
+	}
+	if updater.endpoints[string(svc.Hostname)] != 2 {
+		t.Errorf("got %d endpoints pushed, want 2", updater.endpoints[string(svc.Hostname)])
+	}
+
+	r.RemoveInstance(svc.Hostname, "1.1.1.1", 80)
+	if updater.endpoints[string(svc.Hostname)] != 1 {
+		t.Errorf("got %d endpoints pushed, want 1", updater.endpoints[string(svc.Hostname)])
+	}
+	r.RemoveService(svc.Hostname)
+	if services, _ := r.Services(); len(services) != 0 {
+		t.Errorf("got services %v", services)
+	}
+
+	want := []string{
+		"service add hello.default.svc.cluster.local",
+		"instance add 1.1.1.1",
+		"instance add 1.1.1.2",
+		"instance update 1.1.1.2",
+		"instance delete 1.1.1.1",
+		"instance delete 1.1.1.2",
+		"service delete hello.default.svc.cluster.local",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("got events\n%v\nwant\n%v", events, want)
+	}
+}
+
+func TestRegistryConcurrency(t *testing.T) {
+	r := NewRegistry()
+	svc := MakeService("hello.default.svc.cluster.local", "10.1.0.0")
+	r.AddService(svc)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			_ = r.AddInstance(makeRegistryInstance(svc, fmt.Sprintf("1.1.1.%d", i), nil))
+			r.AddService(MakeService(svc.Hostname, "10.1.0.0"))
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, _ = r.InstancesByPort(svc, 80, nil)
+			_, _ = r.GetProxyServiceInstances(&model.Proxy{IPAddresses: []string{"1.1.1.1"}})
+			_ = r.GetIstioServiceAccounts(svc, []int{80})
+		}()
+	}
+	wg.Wait()
+
+	got, _ := r.GetService(host.Name("hello.default.svc.cluster.local"))
+	instances, _ := r.InstancesByPort(got, 80, nil)
+	if len(instances) != 10 {
+		t.Errorf("got %d instances, want 10", len(instances))
+	}
+	for _, instance := range instances {
+		if instance.Service != got {
+			t.Errorf("instance %v not updated with the latest service", instance)
+		}
+	}
+}
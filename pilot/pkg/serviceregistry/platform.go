@@ -26,4 +26,7 @@ const (
 	ConsulRegistry ServiceRegistry = "Consul"
 	// MCPRegistry is a service registry backed by MCP ServiceEntries
 	MCPRegistry ServiceRegistry = "MCP"
+	// MCPSinkRegistry is a service registry backed by the ServiceEntries and WorkloadEntries served
+	// over MCP by an external inventory system
+	MCPSinkRegistry ServiceRegistry = "MCPSink"
 )
@@ -0,0 +1,87 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"sort"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// serviceDiscovery implements the parts of model.ServiceDiscovery that providers do not, from the ones
+// they do.
+type serviceDiscovery struct {
+	Provider
+}
+
+// NewServiceDiscovery returns the model.ServiceDiscovery of a provider, for the aggregate registry.
+// Providers implementing model.ServiceDiscovery themselves are returned as is.
+func NewServiceDiscovery(p Provider) model.ServiceDiscovery {
+	if sd, ok := p.(model.ServiceDiscovery); ok {
+		return sd
+	}
+	return &serviceDiscovery{Provider: p}
+}
+
+// GetProxyWorkloadLabels returns the labels of the instances co-located with the proxy.
+func (sd *serviceDiscovery) GetProxyWorkloadLabels(proxy *model.Proxy) (labels.Collection, error) {
+	instances, err := sd.GetProxyServiceInstances(proxy)
+	if err != nil {
+		return nil, err
+	}
+	out := make(labels.Collection, 0, len(instances))
+	for _, instance := range instances {
+		out = append(out, instance.Labels)
+	}
+	return out, nil
+}
+
+// ManagementPorts returns no ports, the workloads of providers are not health checked by their proxies.
+func (sd *serviceDiscovery) ManagementPorts(string) model.PortList {
+	return nil
+}
+
+// WorkloadHealthCheckInfo returns no probes, the workloads of providers are not health checked by
+// their proxies.
+func (sd *serviceDiscovery) WorkloadHealthCheckInfo(string) model.ProbeList {
+	return nil
+}
+
+// GetIstioServiceAccounts returns the service accounts of the instances of the service on the ports.
+func (sd *serviceDiscovery) GetIstioServiceAccounts(svc *model.Service, ports []int) []string {
+	saSet := make(map[string]bool)
+	for _, port := range ports {
+		instances, err := sd.InstancesByPort(svc, port, nil)
+		if err != nil {
+			continue
+		}
+		for _, instance := range instances {
+			if instance.ServiceAccount != "" {
+				saSet[instance.ServiceAccount] = true
+			}
+		}
+	}
+	for _, sa := range svc.ServiceAccounts {
+		saSet[sa] = true
+	}
+
+	out := make([]string, 0, len(saSet))
+	for sa := range saSet {
+		out = append(out, sa)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package provider defines the interface of the service registries implemented out of tree, e.g. for
+// AWS Cloud Map or Eureka. A provider registers a factory under the name of its registry, typically from
+// an init function of a package linked into pilot-discovery, like a database/sql driver:
+//
+//	func init() {
+//		provider.Register("Eureka", func(opts provider.Options) (provider.Provider, error) {
+//			return newEurekaRegistry(opts.Args["eurekaURL"], opts.DomainSuffix)
+//		})
+//	}
+//
+// The registry is then enabled with --registries=Eureka, its options set with --registryArgs.
+package provider
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// Provider is a service registry. It lists the services and their instances, and notifies the
+// handlers of their changes, so that Pilot pushes the new configuration to the proxies.
+type Provider interface {
+	// Services lists the services of the registry.
+	Services() ([]*model.Service, error)
+
+	// GetService returns the service with the hostname, or nil if the registry does not have it.
+	GetService(hostname host.Name) (*model.Service, error)
+
+	// InstancesByPort returns the instances of the service on the service port, with labels matching
+	// any of the label sets. All the instances match an empty collection.
+	InstancesByPort(svc *model.Service, servicePort int, labels labels.Collection) ([]*model.ServiceInstance, error)
+
+	// GetProxyServiceInstances returns the instances co-located with the proxy, i.e. sharing one of its
+	// IP addresses. Registries of services external to the mesh return none.
+	GetProxyServiceInstances(proxy *model.Proxy) ([]*model.ServiceInstance, error)
+
+	// AppendServiceHandler and AppendInstanceHandler register the handlers notified of the changes of
+	// the services and instances, and Run watches the changes until stop is closed.
+	model.Controller
+}
+
+// Options are the options of a provider.
+type Options struct {
+	// DomainSuffix is the DNS domain suffix of the mesh, e.g. cluster.local.
+	DomainSuffix string
+	// Args are the provider specific arguments, set with --registryArgs.
+	Args map[string]string
+}
+
+// Factory creates a provider.
+type Factory func(Options) (Provider, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = make(map[string]Factory)
+)
+
+// Register makes a provider available under the name of its registry. It panics if the name is
+// already taken, like the other registries of the process.
+func Register(name string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	if _, f := factories[name]; f {
+		panic(fmt.Sprintf("service registry provider %s registered twice", name))
+	}
+	factories[name] = factory
+}
+
+// Lookup returns the factory of the provider registered under the name.
+func Lookup(name string) (Factory, bool) {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+	factory, f := factories[name]
+	return factory, f
+}
+
+// Names returns the sorted names of the registered providers.
+func Names() []string {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+	out := make([]string, 0, len(factories))
+	for name := range factories {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
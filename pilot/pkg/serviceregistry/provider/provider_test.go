@@ -0,0 +1,122 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+)
+
+// fakeProvider is a provider with a single instance of a single service.
+type fakeProvider struct {
+	instance *model.ServiceInstance
+}
+
+func (p *fakeProvider) Services() ([]*model.Service, error) {
+	return []*model.Service{p.instance.Service}, nil
+}
+
+func (p *fakeProvider) GetService(hostname host.Name) (*model.Service, error) {
+	if hostname == p.instance.Service.Hostname {
+		return p.instance.Service, nil
+	}
+	return nil, nil
+}
+
+func (p *fakeProvider) InstancesByPort(svc *model.Service, _ int, _ labels.Collection) ([]*model.ServiceInstance, error) {
+	if svc.Hostname == p.instance.Service.Hostname {
+		return []*model.ServiceInstance{p.instance}, nil
+	}
+	return nil, nil
+}
+
+func (p *fakeProvider) GetProxyServiceInstances(proxy *model.Proxy) ([]*model.ServiceInstance, error) {
+	for _, ip := range proxy.IPAddresses {
+		if ip == p.instance.Endpoint.Address {
+			return []*model.ServiceInstance{p.instance}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *fakeProvider) AppendServiceHandler(func(*model.Service, model.Event)) error {
+	return nil
+}
+
+func (p *fakeProvider) AppendInstanceHandler(func(*model.ServiceInstance, model.Event)) error {
+	return nil
+}
+
+func (p *fakeProvider) Run(<-chan struct{}) {}
+
+func TestRegister(t *testing.T) {
+	Register("fake", func(opts Options) (Provider, error) {
+		return &fakeProvider{}, nil
+	})
+	defer func() {
+		factoriesMutex.Lock()
+		delete(factories, "fake")
+		factoriesMutex.Unlock()
+	}()
+
+	if _, f := Lookup("fake"); !f {
+		t.Error("provider not found")
+	}
+	if _, f := Lookup("unknown"); f {
+		t.Error("unexpected provider found")
+	}
+	if names := Names(); !reflect.DeepEqual(names, []string{"fake"}) {
+		t.Errorf("got names %v", names)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering twice to panic")
+		}
+	}()
+	Register("fake", func(opts Options) (Provider, error) {
+		return nil, nil
+	})
+}
+
+func TestNewServiceDiscovery(t *testing.T) {
+	svc := &model.Service{Hostname: "hello.eureka", ServiceAccounts: []string{"spiffe://cluster.local/ns/default/sa/a"}}
+	sd := NewServiceDiscovery(&fakeProvider{instance: &model.ServiceInstance{
+		Endpoint:       model.NetworkEndpoint{Address: "10.0.0.1", Port: 80},
+		Service:        svc,
+		Labels:         labels.Instance{"app": "hello"},
+		ServiceAccount: "spiffe://cluster.local/ns/default/sa/b",
+	}})
+
+	got, err := sd.GetProxyWorkloadLabels(&model.Proxy{IPAddresses: []string{"10.0.0.1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (labels.Collection{{"app": "hello"}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got labels %v, want %v", got, want)
+	}
+
+	sas := sd.GetIstioServiceAccounts(svc, []int{80})
+	if want := []string{"spiffe://cluster.local/ns/default/sa/a", "spiffe://cluster.local/ns/default/sa/b"}; !reflect.DeepEqual(sas, want) {
+		t.Errorf("got service accounts %v, want %v", sas, want)
+	}
+	if sd.ManagementPorts("10.0.0.1") != nil || sd.WorkloadHealthCheckInfo("10.0.0.1") != nil {
+		t.Error("expected no management ports nor probes")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+)
+
+const (
+	// eventComponent is the source component of the events.
+	eventComponent = "pilot"
+
+	// eventResendInterval is the interval at which an event is emitted again while the error persists, as the
+	// API server expires the events after an hour by default.
+	eventResendInterval = 30 * time.Minute
+)
+
+// ConfigErrorSource provides the errors of the translation of the config resources, e.g. the discovery server.
+type ConfigErrorSource interface {
+	ConfigErrors() []model.ConfigError
+}
+
+// EventEmitter periodically emits a warning event on the config resources whose translation to Envoy config
+// failed, so that the errors show in `kubectl describe` rather than only in the logs of Pilot.
+type EventEmitter struct {
+	recorder record.EventRecorder
+	store    model.ConfigStore
+	errors   ConfigErrorSource
+	interval time.Duration
+
+	// emitted is the time the event of each error was last emitted, by key of the error.
+	emitted map[string]time.Time
+}
+
+// NewEventEmitter creates an emitter for the Pilot replica running in the named pod.
+func NewEventEmitter(client kubernetes.Interface, podName string, store model.ConfigStore, errors ConfigErrorSource,
+	interval time.Duration) *EventEmitter {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: eventComponent, Host: podName})
+	return newEventEmitter(recorder, store, errors, interval)
+}
+
+func newEventEmitter(recorder record.EventRecorder, store model.ConfigStore, errors ConfigErrorSource,
+	interval time.Duration) *EventEmitter {
+	return &EventEmitter{
+		recorder: recorder,
+		store:    store,
+		errors:   errors,
+		interval: interval,
+		emitted:  make(map[string]time.Time),
+	}
+}
+
+// Run emits the events until the stop channel is closed.
+func (e *EventEmitter) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			e.emit(time.Now())
+		}
+	}
+}
+
+// emit emits an event for each error not emitted within the resend interval.
+func (e *EventEmitter) emit(now time.Time) {
+	for key, at := range e.emitted {
+		if now.Sub(at) >= eventResendInterval {
+			delete(e.emitted, key)
+		}
+	}
+
+	for _, ce := range e.errors.ConfigErrors() {
+		key := model.Key(ce.Type, ce.Name, ce.Namespace) + "/" + ce.Reason + "/" + ce.Message
+		if _, f := e.emitted[key]; f {
+			continue
+		}
+		s, f := e.store.ConfigDescriptor().GetByType(ce.Type)
+		if !f {
+			scope.Warnf("Unable to emit the event of %s/%s: unknown type %s", ce.Namespace, ce.Name, ce.Type)
+			continue
+		}
+		ref := &v1.ObjectReference{
+			APIVersion: crd.ResourceGroup(&s) + "/" + s.Version,
+			Kind:       crd.KebabCaseToCamelCase(s.Type),
+			Namespace:  ce.Namespace,
+			Name:       ce.Name,
+		}
+		e.recorder.Event(ref, v1.EventTypeWarning, ce.Reason, ce.Message)
+		e.emitted[key] = now
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+type fakeConfigErrors struct {
+	errors []model.ConfigError
+}
+
+func (f *fakeConfigErrors) ConfigErrors() []model.ConfigError {
+	return f.errors
+}
+
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case e := <-recorder.Events:
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEventEmitter(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	source := &fakeConfigErrors{
+		errors: []model.ConfigError{
+			{
+				Type:      schemas.VirtualService.Type,
+				Namespace: "default",
+				Name:      "reviews",
+				Reason:    "InvalidRegex",
+				Message:   `HTTP route "v2" dropped: invalid regex`,
+			},
+			{
+				Type:      "unknown-type",
+				Namespace: "default",
+				Name:      "unknown",
+				Reason:    "InvalidRegex",
+				Message:   "unknown type",
+			},
+		},
+	}
+	emitter := newEventEmitter(recorder, makeStore(t), source, time.Second)
+
+	now := time.Now()
+	emitter.emit(now)
+	events := drainEvents(recorder)
+	expected := `Warning InvalidRegex HTTP route "v2" dropped: invalid regex`
+	if len(events) != 1 || events[0] != expected {
+		t.Fatalf("expected the event %q, got %v", expected, events)
+	}
+
+	// The error persists: the event is not emitted again before the resend interval.
+	emitter.emit(now.Add(time.Minute))
+	if events := drainEvents(recorder); len(events) != 0 {
+		t.Errorf("expected no event within the resend interval, got %v", events)
+	}
+	emitter.emit(now.Add(eventResendInterval))
+	if events := drainEvents(recorder); len(events) != 1 {
+		t.Errorf("expected the event to be emitted again after the resend interval, got %v", events)
+	}
+
+	// A different error of the same resource is emitted.
+	source.errors[0].Message = `HTTP route "v3" dropped: invalid regex`
+	emitter.emit(now.Add(eventResendInterval + time.Minute))
+	if events := drainEvents(recorder); len(events) != 1 {
+		t.Errorf("expected the event of the new error, got %v", events)
+	}
+}
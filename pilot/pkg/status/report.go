@@ -0,0 +1,175 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package status writes in the status of the networking resources how many proxies have acked their latest
+// version. Each Pilot replica only knows the proxies connected to it: every replica publishes a Report in a config
+// map, and the replica elected as leader aggregates the reports into the statuses.
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/pkg/log"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schema"
+)
+
+const (
+	// ReportLabel is the label of the config maps holding the reports of the Pilot replicas.
+	ReportLabel = "istio.io/config-distribution-report"
+
+	// reportKey is the key of the report in the data of its config map.
+	reportKey = "report"
+)
+
+var scope = log.RegisterScope("status", "status of the config resources", 0)
+
+// Report is the distribution of the config resources to the proxies connected to a Pilot replica.
+type Report struct {
+	// Reporter is the name of the Pilot replica.
+	Reporter string `json:"reporter"`
+
+	// Timestamp is the time the report was generated at. The reports of the replicas no longer running are
+	// ignored once they are stale.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Proxies is the number of proxies connected to the replica.
+	Proxies int `json:"proxies"`
+
+	// Acked is, by key of the config resources, the number of proxies having acked each resource version.
+	Acked map[string]map[string]int `json:"acked,omitempty"`
+}
+
+// AckCounter counts the proxies having acked each version of the config resources, e.g. the discovery server.
+type AckCounter interface {
+	ConfigDistribution(keys []string) (int, map[string]map[string]int)
+}
+
+// Reporter periodically publishes the Report of a Pilot replica, in a config map owned by the pod of the replica.
+type Reporter struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+	store     model.ConfigStore
+	acks      AckCounter
+	interval  time.Duration
+
+	owner []metav1.OwnerReference
+}
+
+// NewReporter creates a reporter for the Pilot replica running in the named pod, publishing its reports in the
+// namespace.
+func NewReporter(client kubernetes.Interface, namespace, podName string, store model.ConfigStore, acks AckCounter,
+	interval time.Duration) *Reporter {
+	return &Reporter{
+		client:    client,
+		namespace: namespace,
+		name:      podName,
+		store:     store,
+		acks:      acks,
+		interval:  interval,
+	}
+}
+
+// Run publishes the reports until the stop channel is closed.
+func (r *Reporter) Run(stop <-chan struct{}) {
+	if pod, err := r.client.CoreV1().Pods(r.namespace).Get(r.name, metav1.GetOptions{}); err == nil {
+		// Let the config map be garbage collected with the pod.
+		r.owner = []metav1.OwnerReference{{
+			APIVersion: "v1",
+			Kind:       "Pod",
+			Name:       pod.Name,
+			UID:        pod.UID,
+		}}
+	} else {
+		scope.Warnf("Unable to get the pod %s/%s owning the distribution reports: %v", r.namespace, r.name, err)
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := r.publish(r.buildReport()); err != nil {
+				scope.Errorf("Unable to publish the config distribution report: %v", err)
+			}
+		}
+	}
+}
+
+func (r *Reporter) buildReport() Report {
+	var keys []string
+	for _, s := range r.store.ConfigDescriptor() {
+		if !isTracked(s) {
+			continue
+		}
+		configs, err := r.store.List(s.Type, model.NamespaceAll)
+		if err != nil {
+			scope.Warnf("Unable to list %s: %v", s.Type, err)
+			continue
+		}
+		for _, c := range configs {
+			keys = append(keys, model.Key(s.Type, c.Name, c.Namespace))
+		}
+	}
+
+	proxies, acked := r.acks.ConfigDistribution(keys)
+	return Report{
+		Reporter:  r.name,
+		Timestamp: time.Now(),
+		Proxies:   proxies,
+		Acked:     acked,
+	}
+}
+
+func (r *Reporter) publish(report Report) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            reportConfigMapName(r.name),
+			Namespace:       r.namespace,
+			Labels:          map[string]string{ReportLabel: "true"},
+			OwnerReferences: r.owner,
+		},
+		Data: map[string]string{reportKey: string(data)},
+	}
+	configMaps := r.client.CoreV1().ConfigMaps(r.namespace)
+	if _, err = configMaps.Update(cm); errors.IsNotFound(err) {
+		_, err = configMaps.Create(cm)
+	}
+	return err
+}
+
+func reportConfigMapName(reporter string) string {
+	return fmt.Sprintf("%s-distribution", reporter)
+}
+
+// isTracked tells whether the distribution of the resources of the type is written in their status.
+func isTracked(s schema.Instance) bool {
+	return s.Group == "networking" && !s.ClusterScoped
+}
@@ -0,0 +1,118 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	authn "istio.io/api/authentication/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+type fakeAcks struct {
+	proxies int
+	acked   map[string]map[string]int
+	keys    []string
+}
+
+func (f *fakeAcks) ConfigDistribution(keys []string) (int, map[string]map[string]int) {
+	f.keys = keys
+	return f.proxies, f.acked
+}
+
+func makeStore(t *testing.T) model.ConfigStore {
+	t.Helper()
+	store := memory.Make(schemas.Istio)
+	configs := []model.Config{
+		{
+			ConfigMeta: model.ConfigMeta{
+				Type:      schemas.VirtualService.Type,
+				Group:     "networking.istio.io",
+				Version:   schemas.VirtualService.Version,
+				Name:      "reviews",
+				Namespace: "default",
+			},
+			Spec: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Route: []*networking.HTTPRouteDestination{{
+						Destination: &networking.Destination{Host: "reviews"},
+					}},
+				}},
+			},
+		},
+		{
+			ConfigMeta: model.ConfigMeta{
+				Type:      schemas.AuthenticationPolicy.Type,
+				Group:     "authentication.istio.io",
+				Version:   schemas.AuthenticationPolicy.Version,
+				Name:      "default",
+				Namespace: "default",
+			},
+			Spec: &authn.Policy{},
+		},
+	}
+	for _, c := range configs {
+		if _, err := store.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestReporter(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	acks := &fakeAcks{
+		proxies: 3,
+		acked:   map[string]map[string]int{"virtual-service/default/reviews": {"1": 2}},
+	}
+	r := NewReporter(client, "istio-system", "istio-pilot-1", makeStore(t), acks, time.Second)
+
+	// The config map is created, then updated.
+	for i := 0; i < 2; i++ {
+		if err := r.publish(r.buildReport()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if want := []string{"virtual-service/default/reviews"}; !reflect.DeepEqual(acks.keys, want) {
+		t.Errorf("got keys %v, want only the ones of the networking resources %v", acks.keys, want)
+	}
+
+	cm, err := client.CoreV1().ConfigMaps("istio-system").Get("istio-pilot-1-distribution", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cm.Labels[ReportLabel] != "true" {
+		t.Errorf("got labels %v, want the report label", cm.Labels)
+	}
+	var report Report
+	if err := json.Unmarshal([]byte(cm.Data[reportKey]), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Reporter != "istio-pilot-1" || report.Proxies != 3 || !reflect.DeepEqual(report.Acked, acks.acked) {
+		t.Errorf("got report %+v", report)
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	istioschema "istio.io/istio/pkg/config/schema"
+)
+
+const (
+	// ElectionID is the name of the config map used to elect the Pilot replica writing the statuses.
+	ElectionID = "istio-pilot-status-leader"
+
+	// statusField is the field of the status of the resources holding their distribution.
+	statusField = "distribution"
+
+	// maxVersions is the number of resource versions of a resource known to carry the same config: the status
+	// writes change the resource version, and the proxies may have acked any of the recent ones.
+	maxVersions = 4
+)
+
+var leaseDuration = 30 * time.Second
+
+// Distribution is the distribution of a config resource, written in the distribution field of its status.
+type Distribution struct {
+	// ObservedGeneration is the generation of the resource the distribution was computed for.
+	ObservedGeneration int64 `json:"observedGeneration"`
+
+	// Reconciled is the number of proxies having acked the latest version of the resource.
+	Reconciled int `json:"reconciled"`
+
+	// Total is the number of proxies connected to the Pilot replicas.
+	Total int `json:"total"`
+
+	// Message summarizes the distribution, e.g. "Reconciled 118/120 proxies".
+	Message string `json:"message"`
+}
+
+// written is the distribution last written in the status of a resource.
+type written struct {
+	// versions are the resource versions carrying the config the distribution was computed for: the version
+	// the config was distributed with, followed by the ones of the status writes.
+	versions []string
+
+	distribution Distribution
+}
+
+// Writer aggregates the reports of the Pilot replicas into the status of the resources, while its replica is
+// elected as leader.
+type Writer struct {
+	client    kubernetes.Interface
+	dynamic   dynamic.Interface
+	namespace string
+	store     model.ConfigStore
+	interval  time.Duration
+
+	mu      sync.Mutex
+	written map[string]*written
+}
+
+// NewWriter creates a writer of the statuses of the resources of the store, aggregating the reports published in
+// the namespace.
+func NewWriter(client kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, store model.ConfigStore,
+	interval time.Duration) *Writer {
+	return &Writer{
+		client:    client,
+		dynamic:   dynamicClient,
+		namespace: namespace,
+		store:     store,
+		interval:  interval,
+	}
+}
+
+// Run runs for election among the replicas of Pilot as identity, and writes the statuses while leading, until the
+// stop channel is closed.
+func (w *Writer) Run(stop <-chan struct{}, identity string) error {
+	lock := &resourcelock.ConfigMapLock{
+		ConfigMapMeta: metav1.ObjectMeta{Namespace: w.namespace, Name: ElectionID},
+		Client:        w.client.CoreV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: identity,
+		},
+	}
+	le, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: leaseDuration,
+		RenewDeadline: leaseDuration / 2,
+		RetryPeriod:   leaseDuration / 4,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				scope.Infof("Started leading the status updates as %s", identity)
+				w.writeUntil(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				scope.Infof("Stopped leading the status updates as %s", identity)
+			},
+			OnNewLeader: func(leader string) {
+				scope.Infof("New leader of the status updates: %s", leader)
+			},
+		},
+		ReleaseOnCancel: true,
+		Name:            ElectionID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	// Run returns when the leadership is lost: run for election again until stopped.
+	for ctx.Err() == nil {
+		le.Run(ctx)
+	}
+	return nil
+}
+
+func (w *Writer) writeUntil(stop <-chan struct{}) {
+	w.mu.Lock()
+	// Another replica may have written the statuses since this one last lead.
+	w.written = make(map[string]*written)
+	w.mu.Unlock()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.update()
+		}
+	}
+}
+
+// update writes the status of the resources whose distribution changed since it was last written.
+func (w *Writer) update() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	reports, err := w.reports()
+	if err != nil {
+		scope.Errorf("Unable to read the config distribution reports: %v", err)
+		return
+	}
+	total := 0
+	for _, r := range reports {
+		total += r.Proxies
+	}
+
+	seen := make(map[string]bool, len(w.written))
+	storeVersion := w.store.Version()
+	for _, s := range w.store.ConfigDescriptor() {
+		if !isTracked(s) {
+			continue
+		}
+		configs, err := w.store.List(s.Type, model.NamespaceAll)
+		if err != nil {
+			scope.Warnf("Unable to list %s: %v", s.Type, err)
+			continue
+		}
+		for _, c := range configs {
+			key := model.Key(s.Type, c.Name, c.Namespace)
+			seen[key] = true
+
+			// The version in the ledger is the one the config was distributed with, which is not changed by
+			// the status writes.
+			version, err := w.store.GetResourceAtVersion(storeVersion, key)
+			if err != nil || version == "" {
+				version = c.ResourceVersion
+			}
+			wr := w.written[key]
+			if wr == nil || !contains(wr.versions, version) {
+				wr = &written{versions: []string{version}}
+				w.written[key] = wr
+			}
+
+			reconciled := 0
+			for _, r := range reports {
+				for _, v := range wr.versions {
+					reconciled += r.Acked[key][v]
+				}
+			}
+			if wr.distribution.Message != "" && wr.distribution.Reconciled == reconciled && wr.distribution.Total == total {
+				continue
+			}
+
+			d := Distribution{
+				Reconciled: reconciled,
+				Total:      total,
+				Message:    fmt.Sprintf("Reconciled %d/%d proxies", reconciled, total),
+			}
+			newVersion, err := w.writeStatus(s, c, &d)
+			if err != nil {
+				scope.Warnf("Unable to update the status of %s %s/%s: %v", s.Type, c.Namespace, c.Name, err)
+				continue
+			}
+			wr.distribution = d
+			wr.versions = append(wr.versions, newVersion)
+			if len(wr.versions) > maxVersions {
+				// Keep the version the config was distributed with.
+				wr.versions = append(wr.versions[:1], wr.versions[len(wr.versions)-maxVersions+1:]...)
+			}
+		}
+	}
+	for key := range w.written {
+		if !seen[key] {
+			delete(w.written, key)
+		}
+	}
+}
+
+// reports returns the reports of the replicas which are not stale.
+func (w *Writer) reports() ([]Report, error) {
+	configMaps, err := w.client.CoreV1().ConfigMaps(w.namespace).List(metav1.ListOptions{LabelSelector: ReportLabel})
+	if err != nil {
+		return nil, err
+	}
+	staleBefore := time.Now().Add(-3 * w.interval)
+	var reports []Report
+	for _, cm := range configMaps.Items {
+		var r Report
+		if err := json.Unmarshal([]byte(cm.Data[reportKey]), &r); err != nil {
+			scope.Warnf("Invalid config distribution report in %s: %v", cm.Name, err)
+			continue
+		}
+		if r.Timestamp.Before(staleBefore) {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// writeStatus writes the distribution in the status of the resource, keeping the other status fields, and returns
+// the new resource version.
+func (w *Writer) writeStatus(s istioschema.Instance, c model.Config, d *Distribution) (string, error) {
+	resource := w.dynamic.Resource(schema.GroupVersionResource{
+		Group:    crd.ResourceGroup(&s),
+		Version:  s.Version,
+		Resource: crd.ResourceName(s.Plural),
+	}).Namespace(c.Namespace)
+
+	u, err := resource.Get(c.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	d.ObservedGeneration = u.GetGeneration()
+
+	statusMap, ok := u.Object["status"].(map[string]interface{})
+	if !ok {
+		statusMap = make(map[string]interface{})
+	}
+	statusMap[statusField] = map[string]interface{}{
+		"observedGeneration": d.ObservedGeneration,
+		"reconciled":         int64(d.Reconciled),
+		"total":              int64(d.Total),
+		"message":            d.Message,
+	}
+	u.Object["status"] = statusMap
+
+	u, err = resource.UpdateStatus(u, metav1.UpdateOptions{})
+	if err != nil {
+		return "", err
+	}
+	return u.GetResourceVersion(), nil
+}
+
+func contains(versions []string, version string) bool {
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
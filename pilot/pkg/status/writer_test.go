@@ -0,0 +1,130 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/schemas"
+)
+
+func publishReport(t *testing.T, client kubernetes.Interface, report Report) {
+	t.Helper()
+	r := &Reporter{client: client, namespace: "istio-system", name: report.Reporter}
+	if err := r.publish(report); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	store := makeStore(t)
+	vs := store.Get(schemas.VirtualService.Type, "reviews", "default")
+	key := model.Key(schemas.VirtualService.Type, "reviews", "default")
+
+	client := fake.NewSimpleClientset()
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "networking.istio.io/v1alpha3",
+			"kind":       "VirtualService",
+			"metadata": map[string]interface{}{
+				"name":            "reviews",
+				"namespace":       "default",
+				"generation":      int64(2),
+				"resourceVersion": "10",
+			},
+			"status": map[string]interface{}{
+				"validationMessages": []interface{}{"kept"},
+			},
+		},
+	})
+	w := NewWriter(client, dynamicClient, "istio-system", store, time.Second)
+	w.written = make(map[string]*written)
+
+	publishReport(t, client, Report{
+		Reporter:  "istio-pilot-1",
+		Timestamp: time.Now(),
+		Proxies:   3,
+		Acked:     map[string]map[string]int{key: {vs.ResourceVersion: 2, "old": 1}},
+	})
+	publishReport(t, client, Report{
+		Reporter:  "istio-pilot-2",
+		Timestamp: time.Now(),
+		Proxies:   1,
+		Acked:     map[string]map[string]int{key: {vs.ResourceVersion: 1}},
+	})
+	publishReport(t, client, Report{
+		Reporter:  "istio-pilot-stale",
+		Timestamp: time.Now().Add(-time.Hour),
+		Proxies:   5,
+		Acked:     map[string]map[string]int{key: {vs.ResourceVersion: 5}},
+	})
+
+	gvr := schema.GroupVersionResource{Group: "networking.istio.io", Version: "v1alpha3", Resource: "virtualservices"}
+	checkStatus := func(want map[string]interface{}) {
+		t.Helper()
+		u, err := dynamicClient.Resource(gvr).Namespace("default").Get("reviews", metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		status := u.Object["status"].(map[string]interface{})
+		if !reflect.DeepEqual(status[statusField], want) {
+			t.Errorf("got distribution %v, want %v", status[statusField], want)
+		}
+		if status["validationMessages"] == nil {
+			t.Errorf("the other status fields were not kept: %v", status)
+		}
+	}
+
+	w.update()
+	checkStatus(map[string]interface{}{
+		"observedGeneration": int64(2),
+		"reconciled":         int64(3),
+		"total":              int64(4),
+		"message":            "Reconciled 3/4 proxies",
+	})
+
+	// The status is not written again while the distribution does not change.
+	actions := len(dynamicClient.Actions())
+	w.update()
+	if got := len(dynamicClient.Actions()); got != actions {
+		t.Errorf("got %d actions on the resources, want %d", got, actions)
+	}
+
+	// The proxies having acked the version of the status write carry the same config.
+	publishReport(t, client, Report{
+		Reporter:  "istio-pilot-1",
+		Timestamp: time.Now(),
+		Proxies:   3,
+		Acked:     map[string]map[string]int{key: {vs.ResourceVersion: 2, "10": 1}},
+	})
+	w.update()
+	checkStatus(map[string]interface{}{
+		"observedGeneration": int64(2),
+		"reconciled":         int64(4),
+		"total":              int64(4),
+		"message":            "Reconciled 4/4 proxies",
+	})
+}
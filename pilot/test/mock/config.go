@@ -34,6 +34,8 @@ import (
 	"istio.io/pkg/log"
 
 	"istio.io/istio/pilot/pkg/model"
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/schema"
 	"istio.io/istio/pkg/config/schemas"
@@ -221,6 +223,34 @@ var (
 			},
 		},
 	}
+
+	// ExampleWorkloadEntry is an example WorkloadEntry
+	ExampleWorkloadEntry = &workload.WorkloadEntry{
+		Address: "2.2.2.2",
+		Ports:   map[string]uint32{"http": 8080},
+		Labels:  map[string]string{"app": "httpbin"},
+	}
+
+	// ExampleWorkloadGroup is an example WorkloadGroup
+	ExampleWorkloadGroup = &workload.WorkloadGroup{
+		Metadata: &workload.ObjectMeta{Labels: map[string]string{"app": "httpbin"}},
+		Template: &workload.WorkloadEntry{
+			Ports:          map[string]uint32{"http": 8080},
+			ServiceAccount: "httpbin",
+		},
+	}
+
+	// ExampleTelemetry is an example Telemetry
+	ExampleTelemetry = &telemetry.Telemetry{
+		Selector: &api.WorkloadSelector{
+			MatchLabels: map[string]string{
+				"app": "httpbin",
+			},
+		},
+		AccessLogging: &telemetry.AccessLogging{
+			Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoy}},
+		},
+	}
 )
 
 // Make creates a mock config indexed by a number
@@ -451,6 +481,9 @@ func CheckIstioConfigTypes(store model.ConfigStore, namespace string, t *testing
 		{"RbacConfig", constants.DefaultRbacConfigName, schemas.RbacConfig, ExampleRbacConfig},
 		{"ClusterRbacConfig", constants.DefaultRbacConfigName, schemas.ClusterRbacConfig, ExampleRbacConfig},
 		{"AuthorizationPolicy", configName, schemas.AuthorizationPolicy, ExampleAuthorizationPolicy},
+		{"WorkloadEntry", configName, schemas.WorkloadEntry, ExampleWorkloadEntry},
+		{"WorkloadGroup", configName, schemas.WorkloadGroup, ExampleWorkloadGroup},
+		{"Telemetry", configName, schemas.Telemetry, ExampleTelemetry},
 	}
 
 	for _, c := range cases {
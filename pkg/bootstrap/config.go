@@ -21,19 +21,24 @@ import (
 	"net"
 	"os"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gogo/protobuf/types"
 	"golang.org/x/oauth2/google"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	meshAPI "istio.io/api/mesh/v1alpha1"
+	"istio.io/pkg/env"
 	"istio.io/pkg/log"
 
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pkg/bootstrap/option"
 	"istio.io/istio/pkg/bootstrap/platform"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/spiffe"
 )
 
@@ -54,9 +59,36 @@ const (
 	// "reporter" prefix is for istio standard metrics.
 	// "component" prefix is for istio_build metric.
 	v2Prefixes = "reporter=,component,"
+
+	// globalConnectionLimitKey is the runtime key of the limit of the connections accepted by all the listeners.
+	globalConnectionLimitKey = "overload.global_downstream_max_connections"
+	// listenerConnectionLimitKey is the runtime key of the limit of the connections accepted by a listener.
+	listenerConnectionLimitKey = "envoy.resource_limits.listener.%s.connection_limit"
+
+	// The names of the listeners Pilot generates for the traffic captured by the sidecars.
+	virtualInboundListenerName  = "virtualInbound"
+	virtualOutboundListenerName = "virtualOutbound"
+
+	// The default percentages of the maximum heap size triggering the actions of the overload manager.
+	defaultShrinkHeapThreshold               = "95"
+	defaultStopAcceptingConnectionsThreshold = "98"
 )
 
 var (
+	overloadMaxHeapSizeVar = env.RegisterStringVar("OVERLOAD_MAX_HEAP_SIZE", "",
+		"The default maximum heap size of the proxies, as a quantity such as 512Mi, enabling the overload manager "+
+			"of Envoy. Overridden by the sidecar.istio.io/overloadMaxHeapSize annotation of the pod.")
+	overloadShrinkHeapThresholdVar = env.RegisterStringVar("OVERLOAD_SHRINK_HEAP_THRESHOLD", defaultShrinkHeapThreshold,
+		"The default percentage of the maximum heap size at which the proxies return their free memory to the system. "+
+			"Overridden by the sidecar.istio.io/overloadShrinkHeapThreshold annotation of the pod.")
+	overloadStopAcceptingConnectionsThresholdVar = env.RegisterStringVar("OVERLOAD_STOP_ACCEPTING_CONNECTIONS_THRESHOLD",
+		defaultStopAcceptingConnectionsThreshold,
+		"The default percentage of the maximum heap size at which the proxies stop accepting new connections. "+
+			"Overridden by the sidecar.istio.io/overloadStopAcceptingConnectionsThreshold annotation of the pod.")
+
+	// statTagName matches the names of the extra stat tags, which are also Prometheus label names.
+	statTagName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
 	// These must match the json field names in model.nodeMetadata
 	metadataExchangeKeys = []string{
 		"NAME",
@@ -89,6 +121,10 @@ type Config struct {
 	SDSTokenPath        string
 	ControlPlaneAuth    bool
 	DisableReportCalls  bool
+
+	// OpenTelemetry is the tracer exporting the spans to an OpenTelemetry collector. It takes precedence
+	// over the tracer in Proxy.
+	OpenTelemetry *mesh.OpenTelemetryTracer
 }
 
 // newTemplateParams creates a new template configuration for the given configuration.
@@ -125,6 +161,10 @@ func (cfg Config) toTemplateParams() (map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sampling := cfg.OpenTelemetry.GetSampling(); sampling != nil && meta.TraceSampling == "" {
+		// Pilot sets the sampling of the connection managers from the annotation of the proxy.
+		meta.TraceSampling = strconv.FormatFloat(*sampling, 'f', -1, 64)
+	}
 	opts = append(opts, getNodeMetadataOptions(meta, rawMeta, cfg.PlatEnv)...)
 
 	// Check if nodeIP carries IPv4 or IPv6 and set up proxy accordingly
@@ -146,6 +186,13 @@ func (cfg Config) toTemplateParams() (map[string]interface{}, error) {
 	}
 	opts = append(opts, proxyOpts...)
 
+	if otel := cfg.OpenTelemetry; otel != nil {
+		opts = append(opts, option.OpenTelemetryAddress(otel.Address))
+		if len(otel.ResourceAttributes) > 0 {
+			opts = append(opts, option.OpenTelemetryResourceAttributes(otel.ResourceAttributes))
+		}
+	}
+
 	// TODO: allow reading a file with additional metadata (for example if created with
 	// 'envref'. This will allow Istio to generate the right config even if the pod info
 	// is not available (in particular in some multi-cluster cases)
@@ -191,7 +238,99 @@ func getStatsOptions(meta *model.NodeMetadata, nodeIPs []string) []option.Instan
 		option.EnvoyStatsMatcherInclusionPrefix(parseOption(meta.StatsInclusionPrefixes, requiredEnvoyStatsMatcherInclusionPrefixes)),
 		option.EnvoyStatsMatcherInclusionSuffix(parseOption(meta.StatsInclusionSuffixes, requiredEnvoyStatsMatcherInclusionSuffix)),
 		option.EnvoyStatsMatcherInclusionRegexp(parseOption(meta.StatsInclusionRegexps, "")),
+		option.EnvoyExtraStatTags(parseExtraStatTags(meta.ExtraStatTags)),
+	}
+}
+
+// parseExtraStatTags returns the names of the extra dimensions of the standard metrics, skipping the names
+// which are not valid tag names.
+func parseExtraStatTags(tags string) []string {
+	var out []string
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if !statTagName.MatchString(tag) {
+			log.Warnf("invalid stat tag name %q", tag)
+			continue
+		}
+		out = append(out, tag)
 	}
+	return out
+}
+
+// getConnectionLimitOptions sets the connection limits of the annotations of the proxy in the runtime
+// of Envoy, so that a misbehaving client can not exhaust the file descriptors of the proxy.
+func getConnectionLimitOptions(meta *model.NodeMetadata) []option.Instance {
+	limits := []struct {
+		key   string
+		value string
+	}{
+		{globalConnectionLimitKey, meta.MaxDownstreamConnections},
+		{fmt.Sprintf(listenerConnectionLimitKey, virtualInboundListenerName), meta.MaxInboundConnections},
+		{fmt.Sprintf(listenerConnectionLimitKey, virtualOutboundListenerName), meta.MaxOutboundConnections},
+	}
+
+	var runtime map[string]uint32
+	for _, limit := range limits {
+		if limit.value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(limit.value, 10, 32)
+		if err != nil || n == 0 {
+			log.Warnf("Ignoring invalid connection limit %q for %s", limit.value, limit.key)
+			continue
+		}
+		if runtime == nil {
+			runtime = make(map[string]uint32)
+		}
+		runtime[limit.key] = uint32(n)
+	}
+	return []option.Instance{option.EnvoyRuntime(runtime)}
+}
+
+// getOverloadManagerOptions enables the overload manager of Envoy when a maximum heap size is set by the
+// annotations of the proxy or the defaults of the mesh, so that a proxy running out of memory sheds load
+// instead of being OOM killed.
+func getOverloadManagerOptions(meta *model.NodeMetadata) []option.Instance {
+	maxHeapSize := meta.OverloadMaxHeapSize
+	if maxHeapSize == "" {
+		maxHeapSize = overloadMaxHeapSizeVar.Get()
+	}
+	if maxHeapSize == "" {
+		return nil
+	}
+	q, err := resource.ParseQuantity(maxHeapSize)
+	if err != nil || q.Value() <= 0 {
+		log.Warnf("Ignoring invalid maximum heap size %q of the overload manager", maxHeapSize)
+		return nil
+	}
+
+	return []option.Instance{
+		option.OverloadMaxHeapSize(q.Value()),
+		option.OverloadShrinkHeapThreshold(overloadThreshold("shrink heap",
+			meta.OverloadShrinkHeapThreshold, overloadShrinkHeapThresholdVar.Get(), defaultShrinkHeapThreshold)),
+		option.OverloadStopAcceptingConnectionsThreshold(overloadThreshold("stop accepting connections",
+			meta.OverloadStopAcceptingConnectionsThreshold, overloadStopAcceptingConnectionsThresholdVar.Get(),
+			defaultStopAcceptingConnectionsThreshold)),
+	}
+}
+
+// overloadThreshold returns the first valid percentage of the given values as a fraction of the maximum heap size.
+func overloadThreshold(action string, values ...string) float64 {
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 || f > 100 {
+			log.Warnf("Ignoring invalid %s threshold %q of the overload manager", action, value)
+			continue
+		}
+		return f / 100
+	}
+	return 1
 }
 
 func defaultPilotSAN() []string {
@@ -219,6 +358,10 @@ func getNodeMetadataOptions(meta *model.NodeMetadata, rawMeta map[string]interfa
 
 	opts = append(opts, getStatsOptions(meta, meta.InstanceIPs)...)
 
+	opts = append(opts, getConnectionLimitOptions(meta)...)
+
+	opts = append(opts, getOverloadManagerOptions(meta)...)
+
 	opts = append(opts, option.NodeMetadata(meta, rawMeta))
 	return opts
 }
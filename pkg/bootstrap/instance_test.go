@@ -38,6 +38,7 @@ import (
 	meshconfig "istio.io/api/mesh/v1alpha1"
 
 	"istio.io/istio/pkg/bootstrap/platform"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/test/env"
 )
 
@@ -69,6 +70,7 @@ var (
 // cp $TOP/out/linux_amd64/release/bootstrap/default/envoy-rev0.json pkg/bootstrap/testdata/default_golden.json
 // cp $TOP/out/linux_amd64/release/bootstrap/tracing_datadog/envoy-rev0.json pkg/bootstrap/testdata/tracing_datadog_golden.json
 // cp $TOP/out/linux_amd64/release/bootstrap/tracing_lightstep/envoy-rev0.json pkg/bootstrap/testdata/tracing_lightstep_golden.json
+// cp $TOP/out/linux_amd64/release/bootstrap/tracing_opentelemetry/envoy-rev0.json pkg/bootstrap/testdata/tracing_opentelemetry_golden.json
 // cp $TOP/out/linux_amd64/release/bootstrap/tracing_zipkin/envoy-rev0.json pkg/bootstrap/testdata/tracing_zipkin_golden.json
 func TestGolden(t *testing.T) {
 	out := env.ISTIO_OUT.Value() // defined in the makefile
@@ -76,12 +78,14 @@ func TestGolden(t *testing.T) {
 		out = "/tmp"
 	}
 
+	openTelemetrySampling := 2.5
 	cases := []struct {
 		base                       string
 		envVars                    map[string]string
 		annotations                map[string]string
 		sdsUDSPath                 string
 		sdsTokenPath               string
+		openTelemetry              *mesh.OpenTelemetryTracer
 		expectLightstepAccessToken bool
 		stats                      stats
 		checkLocality              bool
@@ -200,6 +204,57 @@ func TestGolden(t *testing.T) {
 				}
 			},
 		},
+		{
+			// The OpenTelemetry tracer takes precedence over the zipkin tracer of the proxy config
+			base: "tracing_opentelemetry",
+			openTelemetry: &mesh.OpenTelemetryTracer{
+				Address:            "otel-collector.observability:4317",
+				ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+				Sampling:           &openTelemetrySampling,
+			},
+			check: func(got *v2.Bootstrap, t *testing.T) {
+				if got.Tracing.Http.Name != "envoy.tracers.opentelemetry" {
+					t.Fatalf("got tracer %s, want envoy.tracers.opentelemetry", got.Tracing.Http.Name)
+				}
+				cfg := got.Tracing.Http.GetConfig().GetFields()
+				cluster := cfg["grpc_service"].GetStructValue().GetFields()["envoy_grpc"].GetStructValue().GetFields()["cluster_name"]
+				if cluster.GetStringValue() != "opentelemetry" {
+					t.Errorf("got collector cluster %v, want opentelemetry", cluster)
+				}
+				if got := cfg["service_name"].GetStringValue(); got != "istio-proxy" {
+					t.Errorf("got service name %q, want istio-proxy", got)
+				}
+				detectors := cfg["resource_detectors"].GetListValue().GetValues()
+				if len(detectors) != 1 {
+					t.Fatalf("got resource detectors %v, want the static config one", detectors)
+				}
+				attributes := detectors[0].GetStructValue().GetFields()["typed_config"].GetStructValue().GetFields()["attributes"]
+				if got := attributes.GetStructValue().GetFields()["deployment.environment"].GetStringValue(); got != "prod" {
+					t.Errorf("got deployment.environment resource attribute %q, want prod", got)
+				}
+
+				collector := false
+				for _, c := range got.GetStaticResources().GetClusters() {
+					if c.Name == "zipkin" {
+						t.Errorf("got the zipkin cluster with the OpenTelemetry tracer")
+					}
+					if c.Name == "opentelemetry" {
+						collector = true
+						if addr := c.GetHosts()[0].GetSocketAddress(); addr.GetAddress() != "otel-collector.observability" ||
+							addr.GetPortValue() != 4317 {
+							t.Errorf("got collector address %v, want otel-collector.observability:4317", addr)
+						}
+					}
+				}
+				if !collector {
+					t.Errorf("no opentelemetry cluster")
+				}
+
+				if got := got.Node.Metadata.GetFields()["sidecar.istio.io/traceSampling"].GetStringValue(); got != "2.5" {
+					t.Errorf("got trace sampling %q, want 2.5", got)
+				}
+			},
+		},
 		{
 			// Specify zipkin/statsd address, similar with the default config in v1 tests
 			base: "all",
@@ -236,6 +291,79 @@ func TestGolden(t *testing.T) {
 			},
 			stats: stats{regexps: "http.[0-9]*\\.[0-9]*\\.[0-9]*\\.[0-9]*_8080.downstream_rq_time"},
 		},
+		{
+			base: "extra_stat_tags",
+			envVars: map[string]string{
+				"ISTIO_META_EXTRA_STAT_TAGS": "request_user_agent,source_mesh,invalid-tag,",
+			},
+			check: func(got *v2.Bootstrap, t *testing.T) {
+				tags := map[string]string{}
+				for _, tag := range got.GetStatsConfig().GetStatsTags() {
+					tags[tag.TagName] = tag.GetRegex()
+				}
+				for _, name := range []string{"request_user_agent", "source_mesh"} {
+					if want := "(" + name + "=\\.=(.+?);\\.;)"; tags[name] != want {
+						t.Errorf("got regex %q for stat tag %s, want %q", tags[name], name, want)
+					}
+				}
+				if _, ok := tags["invalid-tag"]; ok {
+					t.Errorf("got the invalid stat tag invalid-tag")
+				}
+			},
+		},
+		{
+			base: "connection_limits",
+			annotations: map[string]string{
+				"sidecar.istio.io/maxDownstreamConnections": "10000",
+				"sidecar.istio.io/maxInboundConnections":    "5000",
+				"sidecar.istio.io/maxOutboundConnections":   "invalid",
+			},
+			check: func(got *v2.Bootstrap, t *testing.T) {
+				layers := got.GetLayeredRuntime().GetLayers()
+				if len(layers) != 2 {
+					t.Fatalf("expected the istio and admin runtime layers, got %v", layers)
+				}
+				want := map[string]float64{
+					"overload.global_downstream_max_connections":                     10000,
+					"envoy.resource_limits.listener.virtualInbound.connection_limit": 5000,
+				}
+				fields := layers[0].GetStaticLayer().GetFields()
+				if len(fields) != len(want) {
+					t.Fatalf("got runtime %v, want %v", fields, want)
+				}
+				for key, value := range want {
+					if got := fields[key].GetNumberValue(); got != value {
+						t.Errorf("got %v for runtime key %s, want %v", got, key, value)
+					}
+				}
+			},
+		},
+		{
+			base: "overload_manager",
+			annotations: map[string]string{
+				"sidecar.istio.io/overloadMaxHeapSize":                       "1Gi",
+				"sidecar.istio.io/overloadShrinkHeapThreshold":               "90",
+				"sidecar.istio.io/overloadStopAcceptingConnectionsThreshold": "invalid",
+			},
+			check: func(got *v2.Bootstrap, t *testing.T) {
+				overload := got.GetOverloadManager()
+				if len(overload.GetResourceMonitors()) != 1 {
+					t.Fatalf("expected the fixed heap resource monitor, got %v", overload.GetResourceMonitors())
+				}
+				want := map[string]float64{
+					"envoy.overload_actions.shrink_heap":                0.9,
+					"envoy.overload_actions.stop_accepting_connections": 0.98,
+				}
+				if len(overload.GetActions()) != len(want) {
+					t.Fatalf("got actions %v, want %v", overload.GetActions(), want)
+				}
+				for _, action := range overload.GetActions() {
+					if got := action.GetTriggers()[0].GetThreshold().GetValue(); got != want[action.Name] {
+						t.Errorf("got threshold %v for action %s, want %v", got, action.Name, want[action.Name])
+					}
+				}
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -268,6 +396,8 @@ func TestGolden(t *testing.T) {
 				NodeIPs:      []string{"10.3.3.3", "10.4.4.4", "10.5.5.5", "10.6.6.6", "10.4.4.4"},
 				SDSUDSPath:   c.sdsUDSPath,
 				SDSTokenPath: c.sdsTokenPath,
+
+				OpenTelemetry: c.openTelemetry,
 			}).CreateFileForEpoch(0)
 			if err != nil {
 				t.Fatal(err)
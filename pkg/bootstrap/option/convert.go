@@ -165,6 +165,12 @@ func podIPConverter(value net.IP) convertFunc {
 	}
 }
 
+func jsonConverter(value interface{}) convertFunc {
+	return func(*instance) (interface{}, error) {
+		return convertToJSON(value), nil
+	}
+}
+
 func convertToJSON(v interface{}) string {
 	if v == nil {
 		return ""
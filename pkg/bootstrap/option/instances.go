@@ -170,6 +170,14 @@ func StackDriverMaxEvents(value int64) Instance {
 	return newOption("stackdriverMaxEvents", value)
 }
 
+func OpenTelemetryAddress(value string) Instance {
+	return newOptionOrSkipIfZero("openTelemetry", value).withConvert(addressConverter(value))
+}
+
+func OpenTelemetryResourceAttributes(value map[string]string) Instance {
+	return newOptionOrSkipIfZero("openTelemetryResourceAttributes", value).withConvert(jsonConverter(value))
+}
+
 func PilotGRPCAddress(value string) Instance {
 	return newOptionOrSkipIfZero("pilot_grpc_address", value).withConvert(addressConverter(value))
 }
@@ -224,6 +232,26 @@ func EnvoyStatsMatcherInclusionRegexp(value []string) Instance {
 	return newStringArrayOptionOrSkipIfEmpty("inclusionRegexps", value)
 }
 
+func EnvoyExtraStatTags(value []string) Instance {
+	return newStringArrayOptionOrSkipIfEmpty("extraStatTags", value)
+}
+
+func EnvoyRuntime(value map[string]uint32) Instance {
+	return newOptionOrSkipIfZero("runtime_static_layer", value).withConvert(jsonConverter(value))
+}
+
+func OverloadMaxHeapSize(value int64) Instance {
+	return newOptionOrSkipIfZero("overload_max_heap_size", value)
+}
+
+func OverloadShrinkHeapThreshold(value float64) Instance {
+	return newOption("overload_shrink_heap_threshold", value)
+}
+
+func OverloadStopAcceptingConnectionsThreshold(value float64) Instance {
+	return newOption("overload_stop_accepting_connections_threshold", value)
+}
+
 func SDSUDSPath(value string) Instance {
 	return newOption("sds_uds_path", value)
 }
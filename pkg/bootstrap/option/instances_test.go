@@ -668,6 +668,54 @@ func TestOptions(t *testing.T) {
 			option:   option.EnvoyStatsMatcherInclusionRegexp([]string{"fake"}),
 			expected: []string{"fake"},
 		},
+		{
+			testName: "envoy extra stat tags nil",
+			key:      "extraStatTags",
+			option:   option.EnvoyExtraStatTags(nil),
+			expected: nil,
+		},
+		{
+			testName: "envoy extra stat tags",
+			key:      "extraStatTags",
+			option:   option.EnvoyExtraStatTags([]string{"source_mesh"}),
+			expected: []string{"source_mesh"},
+		},
+		{
+			testName: "envoy runtime nil",
+			key:      "runtime_static_layer",
+			option:   option.EnvoyRuntime(nil),
+			expected: nil,
+		},
+		{
+			testName: "envoy runtime",
+			key:      "runtime_static_layer",
+			option:   option.EnvoyRuntime(map[string]uint32{"overload.global_downstream_max_connections": 100}),
+			expected: `{"overload.global_downstream_max_connections":100}`,
+		},
+		{
+			testName: "overload max heap size zero",
+			key:      "overload_max_heap_size",
+			option:   option.OverloadMaxHeapSize(0),
+			expected: nil,
+		},
+		{
+			testName: "overload max heap size",
+			key:      "overload_max_heap_size",
+			option:   option.OverloadMaxHeapSize(1073741824),
+			expected: int64(1073741824),
+		},
+		{
+			testName: "overload shrink heap threshold",
+			key:      "overload_shrink_heap_threshold",
+			option:   option.OverloadShrinkHeapThreshold(0.95),
+			expected: 0.95,
+		},
+		{
+			testName: "overload stop accepting connections threshold",
+			key:      "overload_stop_accepting_connections_threshold",
+			option:   option.OverloadStopAcceptingConnectionsThreshold(0.98),
+			expected: 0.98,
+		},
 		{
 			testName: "sds uds path",
 			key:      "sds_uds_path",
@@ -63,6 +63,13 @@ const (
 	// IstioIngressGatewayName is the internal gateway name assigned to ingress
 	IstioIngressGatewayName = "istio-autogenerated-k8s-ingress"
 
+	// IstioNetworkGatewayName is the internal gateway name assigned to the servers generated for
+	// the gateways bridging the networks of the mesh.
+	IstioNetworkGatewayName = "istio-autogenerated-network-gateway"
+
+	// DefaultNetworkGatewayPort is the port on which network gateways accept cross-network traffic.
+	DefaultNetworkGatewayPort = 15443
+
 	// IstioIngressNamespace is the namespace where Istio ingress controller is deployed
 	IstioIngressNamespace = "istio-system"
 
@@ -72,6 +79,21 @@ const (
 	// IstioIngressLabelValue is value for IstioLabel that identifies an ingress workload.
 	IstioIngressLabelValue = "ingress"
 
+	// IstioRevisionLabel selects, on namespaces and pods, the revision of the control plane whose
+	// injector handles the pods. Injected pods are labeled with the revision that injected them.
+	IstioRevisionLabel = "istio.io/rev"
+
+	// IstioTagLabel labels the webhook configurations of revision tags with the tag. A tag is an
+	// alias of a revision, namespaces labeled with the tag being injected by the tagged revision.
+	IstioTagLabel = "istio.io/tag"
+
+	// DefaultRevision is the name of the revision of the control plane installed without revision.
+	DefaultRevision = "default"
+
+	// InjectTagPathPrefix is the path prefix of the injection requests of the webhooks of revision
+	// tags, followed by the tag.
+	InjectTagPathPrefix = "/inject/tag/"
+
 	// IstioAPIGroupDomain defines API group domain of all Istio configuration resources.
 	// Group domain suffix to the proto schema's group to generate the full resource group.
 	IstioAPIGroupDomain = ".istio.io"
@@ -89,4 +111,98 @@ const (
 
 	// IstioMeshGateway is the built in gateway for all sidecars
 	IstioMeshGateway = "mesh"
+
+	// TelemetryProviderEnvoy names the telemetry sinks built into the proxy: the file access log and
+	// the tracer configured in the proxy bootstrap.
+	TelemetryProviderEnvoy = "envoy"
+
+	// TelemetryProviderMixer names Mixer telemetry reports.
+	TelemetryProviderMixer = "mixer"
+
+	// TelemetryProviderEnvoyALS names the gRPC access log service configured in the proxy bootstrap.
+	TelemetryProviderEnvoyALS = "envoy_als"
+
+	// SidecarTraceSamplingAnnotation is the pod annotation overriding the percentage of requests
+	// traced by the sidecar.
+	SidecarTraceSamplingAnnotation = "sidecar.istio.io/traceSampling"
+
+	// SidecarNativeAnnotation is the pod annotation overriding whether the proxy is injected as a
+	// native sidecar container.
+	SidecarNativeAnnotation = "sidecar.istio.io/nativeSidecar"
+
+	// SidecarConcurrencyAnnotation is the pod annotation overriding the number of worker threads
+	// of the proxy.
+	SidecarConcurrencyAnnotation = "sidecar.istio.io/concurrency"
+
+	// SidecarDrainDurationAnnotation is the pod annotation overriding how long the proxy drains
+	// connections when it is restarted or shut down.
+	SidecarDrainDurationAnnotation = "sidecar.istio.io/drainDuration"
+
+	// SidecarParentShutdownDurationAnnotation is the pod annotation overriding how long the proxy
+	// waits before terminating its previous instance on hot restart.
+	SidecarParentShutdownDurationAnnotation = "sidecar.istio.io/parentShutdownDuration"
+
+	// SidecarMaxDownstreamConnectionsAnnotation is the pod annotation limiting the number of
+	// connections accepted by all the listeners of the proxy.
+	SidecarMaxDownstreamConnectionsAnnotation = "sidecar.istio.io/maxDownstreamConnections"
+
+	// SidecarMaxInboundConnectionsAnnotation is the pod annotation limiting the number of
+	// connections accepted by the inbound listener of the sidecar.
+	SidecarMaxInboundConnectionsAnnotation = "sidecar.istio.io/maxInboundConnections"
+
+	// SidecarMaxOutboundConnectionsAnnotation is the pod annotation limiting the number of
+	// connections accepted by the outbound listener of the sidecar.
+	SidecarMaxOutboundConnectionsAnnotation = "sidecar.istio.io/maxOutboundConnections"
+
+	// SidecarOverloadMaxHeapSizeAnnotation is the pod annotation setting the maximum heap size of
+	// the proxy, as a quantity, which enables its overload manager.
+	SidecarOverloadMaxHeapSizeAnnotation = "sidecar.istio.io/overloadMaxHeapSize"
+
+	// SidecarOverloadShrinkHeapAnnotation is the pod annotation setting the percentage of the
+	// maximum heap size at which the proxy returns its free memory to the system.
+	SidecarOverloadShrinkHeapAnnotation = "sidecar.istio.io/overloadShrinkHeapThreshold"
+
+	// SidecarOverloadStopConnectionsAnnotation is the pod annotation setting the percentage of the
+	// maximum heap size at which the proxy stops accepting new connections.
+	SidecarOverloadStopConnectionsAnnotation = "sidecar.istio.io/overloadStopAcceptingConnectionsThreshold"
+
+	// SidecarHTTPDrainTimeoutAnnotation is the pod annotation setting the time the HTTP/2 and gRPC
+	// connections of a drained listener are given to complete their requests after their first GOAWAY.
+	SidecarHTTPDrainTimeoutAnnotation = "sidecar.istio.io/httpDrainTimeout"
+
+	// SidecarTrafficExcludeInterfacesAnnotation is the pod annotation listing, comma separated,
+	// the interfaces whose inbound and outbound traffic is not redirected to the proxy.
+	SidecarTrafficExcludeInterfacesAnnotation = "traffic.sidecar.istio.io/excludeInterfaces"
+
+	// ProxyConfigAnnotation is the pod annotation holding a ProxyConfig in YAML, whose fields
+	// override the default proxy configuration of the mesh for the proxy of the pod.
+	ProxyConfigAnnotation = "proxy.istio.io/config"
+
+	// SidecarEgressOutboundTrafficPolicyAnnotation is the Sidecar annotation overriding, in the form
+	// 9080=REGISTRY_ONLY,*=ALLOW_ANY, the outbound traffic policy of its egress listeners on these
+	// ports. "*" designates the egress listener without port.
+	SidecarEgressOutboundTrafficPolicyAnnotation = "networking.istio.io/egressOutboundTrafficPolicy"
+
+	// ServiceEntryWorkloadSelectorAnnotation is the ServiceEntry annotation selecting, in the form
+	// k1=v1,k2=v2, the WorkloadEntries of its namespace whose addresses are endpoints of its hosts.
+	ServiceEntryWorkloadSelectorAnnotation = "networking.istio.io/workloadSelector"
+
+	// ServiceEntryReadinessProbeAnnotation is the ServiceEntry annotation holding, as JSON, a readiness
+	// probe that Pilot executes against each of its static endpoints. The endpoints failing it are
+	// removed from the endpoints of its services.
+	ServiceEntryReadinessProbeAnnotation = "networking.istio.io/readinessProbe"
+
+	// WorkloadGroupAnnotation is the WorkloadEntry annotation naming the WorkloadGroup of its
+	// namespace it was stamped out of.
+	WorkloadGroupAnnotation = "networking.istio.io/workloadGroup"
+
+	// WorkloadEntryHealthAnnotation records the result of the readiness probe of a WorkloadEntry,
+	// either WorkloadHealthy or WorkloadUnhealthy.
+	WorkloadEntryHealthAnnotation = "status.networking.istio.io/health"
+
+	// WorkloadHealthy is the WorkloadEntryHealthAnnotation value of a workload that passes its probe.
+	WorkloadHealthy = "Healthy"
+
+	// WorkloadUnhealthy is the WorkloadEntryHealthAnnotation value of a workload that fails its probe.
+	WorkloadUnhealthy = "Unhealthy"
 )
@@ -0,0 +1,154 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package defaults fills in the documented defaults of the networking resources, so that their validation and the
+// generation of the proxy config operate on fully specified resources rather than on defaults implied by the code.
+// The defaults are applied when the resources are read from their source, and only fill in unset fields: applying
+// them to a resource already defaulted does not change it.
+package defaults
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+const (
+	// RetryAttempts is the number of retries of the HTTP requests when a route does not set it.
+	RetryAttempts = 2
+
+	// RetryOn are the conditions the HTTP requests are retried on when a route does not set them.
+	RetryOn = "connect-failure,refused-stream,unavailable,cancelled,resource-exhausted,retriable-status-codes,503"
+
+	// MaxEjectionPercent is the maximum percentage of the hosts of a service ejected by the outlier detection.
+	MaxEjectionPercent = 10
+)
+
+var (
+	// OutlierDetectionInterval is the time between the analysis sweeps of the outlier detection.
+	OutlierDetectionInterval = 10 * time.Second
+
+	// BaseEjectionTime is the minimum ejection duration of the outlier detection.
+	BaseEjectionTime = 30 * time.Second
+)
+
+// Apply fills in the defaults of the config spec, if it is a networking resource.
+func Apply(spec proto.Message) {
+	switch s := spec.(type) {
+	case *networking.VirtualService:
+		ApplyVirtualService(s)
+	case *networking.DestinationRule:
+		ApplyDestinationRule(s)
+	case *networking.Gateway:
+		ApplyGateway(s)
+	}
+}
+
+// ApplyVirtualService fills in the defaults of a virtual service:
+//
+// - the virtual service applies to the sidecars, i.e. the mesh gateway, if it does not list any gateway.
+//
+// - the weight of the only destination of a route is 100.
+//
+// - the HTTP requests are retried twice on connection failures and 503 responses.
+func ApplyVirtualService(vs *networking.VirtualService) {
+	if len(vs.Gateways) == 0 {
+		vs.Gateways = []string{constants.IstioMeshGateway}
+	}
+	for _, http := range vs.Http {
+		if http == nil {
+			continue
+		}
+		if len(http.Route) == 1 && http.Route[0] != nil && http.Route[0].Weight == 0 {
+			http.Route[0].Weight = 100
+		}
+		// The retries only apply to the requests forwarded to destinations, not to the redirects.
+		if len(http.Route) > 0 && http.Redirect == nil {
+			if http.Retries == nil {
+				http.Retries = &networking.HTTPRetry{Attempts: RetryAttempts}
+			}
+			if http.Retries.Attempts > 0 && http.Retries.RetryOn == "" {
+				http.Retries.RetryOn = RetryOn
+			}
+		}
+	}
+	for _, tcp := range vs.Tcp {
+		if tcp != nil && len(tcp.Route) == 1 && tcp.Route[0] != nil && tcp.Route[0].Weight == 0 {
+			tcp.Route[0].Weight = 100
+		}
+	}
+	for _, tls := range vs.Tls {
+		if tls != nil && len(tls.Route) == 1 && tls.Route[0] != nil && tls.Route[0].Weight == 0 {
+			tls.Route[0].Weight = 100
+		}
+	}
+}
+
+// ApplyDestinationRule fills in the defaults of the traffic policies of a destination rule, at the top level, the
+// port level and in the subsets: the outlier detection ejects at most 10% of the hosts, for at least 30s, with
+// sweeps every 10s.
+func ApplyDestinationRule(dr *networking.DestinationRule) {
+	applyTrafficPolicy(dr.TrafficPolicy)
+	for _, subset := range dr.Subsets {
+		if subset != nil {
+			applyTrafficPolicy(subset.TrafficPolicy)
+		}
+	}
+}
+
+func applyTrafficPolicy(policy *networking.TrafficPolicy) {
+	if policy == nil {
+		return
+	}
+	applyOutlierDetection(policy.OutlierDetection)
+	for _, port := range policy.PortLevelSettings {
+		if port != nil {
+			applyOutlierDetection(port.OutlierDetection)
+		}
+	}
+}
+
+func applyOutlierDetection(outlier *networking.OutlierDetection) {
+	if outlier == nil {
+		return
+	}
+	if outlier.Interval == nil {
+		outlier.Interval = types.DurationProto(OutlierDetectionInterval)
+	}
+	if outlier.BaseEjectionTime == nil {
+		outlier.BaseEjectionTime = types.DurationProto(BaseEjectionTime)
+	}
+	if outlier.MaxEjectionPercent == 0 {
+		outlier.MaxEjectionPercent = MaxEjectionPercent
+	}
+}
+
+// ApplyGateway fills in the defaults of a gateway: the protocols of the ports of the servers are spelled in upper
+// case, as documented.
+func ApplyGateway(gw *networking.Gateway) {
+	for _, server := range gw.Servers {
+		if server == nil || server.Port == nil {
+			continue
+		}
+		if upper := strings.ToUpper(server.Port.Protocol); upper != server.Port.Protocol {
+			server.Port.Protocol = upper
+		}
+	}
+}
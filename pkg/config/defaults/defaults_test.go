@@ -0,0 +1,165 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defaults
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	networking "istio.io/api/networking/v1alpha3"
+)
+
+func TestApply(t *testing.T) {
+	cases := []struct {
+		name string
+		in   proto.Message
+		want proto.Message
+	}{
+		{
+			name: "virtual service",
+			in: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{
+					{
+						Route: []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}}},
+					},
+					{
+						Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}}},
+						Retries: &networking.HTTPRetry{Attempts: 5},
+					},
+					{
+						Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}}},
+						Retries: &networking.HTTPRetry{},
+					},
+					{
+						Redirect: &networking.HTTPRedirect{Uri: "/v2"},
+					},
+				},
+				Tcp: []*networking.TCPRoute{{
+					Route: []*networking.RouteDestination{
+						{Destination: &networking.Destination{Host: "reviews"}, Weight: 50},
+						{Destination: &networking.Destination{Host: "ratings"}, Weight: 50},
+					},
+				}},
+			},
+			want: &networking.VirtualService{
+				Hosts:    []string{"reviews"},
+				Gateways: []string{"mesh"},
+				Http: []*networking.HTTPRoute{
+					{
+						Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}, Weight: 100}},
+						Retries: &networking.HTTPRetry{Attempts: RetryAttempts, RetryOn: RetryOn},
+					},
+					{
+						Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}, Weight: 100}},
+						Retries: &networking.HTTPRetry{Attempts: 5, RetryOn: RetryOn},
+					},
+					{
+						// Retries disabled explicitly.
+						Route:   []*networking.HTTPRouteDestination{{Destination: &networking.Destination{Host: "reviews"}, Weight: 100}},
+						Retries: &networking.HTTPRetry{},
+					},
+					{
+						Redirect: &networking.HTTPRedirect{Uri: "/v2"},
+					},
+				},
+				Tcp: []*networking.TCPRoute{{
+					Route: []*networking.RouteDestination{
+						{Destination: &networking.Destination{Host: "reviews"}, Weight: 50},
+						{Destination: &networking.Destination{Host: "ratings"}, Weight: 50},
+					},
+				}},
+			},
+		},
+		{
+			name: "destination rule",
+			in: &networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					OutlierDetection: &networking.OutlierDetection{ConsecutiveErrors: 3},
+				},
+				Subsets: []*networking.Subset{{
+					Name: "v1",
+					TrafficPolicy: &networking.TrafficPolicy{
+						PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{
+							OutlierDetection: &networking.OutlierDetection{MaxEjectionPercent: 50},
+						}},
+					},
+				}},
+			},
+			want: &networking.DestinationRule{
+				Host: "reviews",
+				TrafficPolicy: &networking.TrafficPolicy{
+					OutlierDetection: &networking.OutlierDetection{
+						ConsecutiveErrors:  3,
+						Interval:           types.DurationProto(10 * time.Second),
+						BaseEjectionTime:   types.DurationProto(30 * time.Second),
+						MaxEjectionPercent: 10,
+					},
+				},
+				Subsets: []*networking.Subset{{
+					Name: "v1",
+					TrafficPolicy: &networking.TrafficPolicy{
+						PortLevelSettings: []*networking.TrafficPolicy_PortTrafficPolicy{{
+							OutlierDetection: &networking.OutlierDetection{
+								Interval:           types.DurationProto(10 * time.Second),
+								BaseEjectionTime:   types.DurationProto(30 * time.Second),
+								MaxEjectionPercent: 50,
+							},
+						}},
+					},
+				}},
+			},
+		},
+		{
+			name: "gateway",
+			in: &networking.Gateway{
+				Servers: []*networking.Server{{
+					Port:  &networking.Port{Number: 80, Name: "http", Protocol: "http"},
+					Hosts: []string{"*"},
+				}},
+			},
+			want: &networking.Gateway{
+				Servers: []*networking.Server{{
+					Port:  &networking.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+					Hosts: []string{"*"},
+				}},
+			},
+		},
+		{
+			name: "other resource",
+			in:   &networking.ServiceEntry{Hosts: []string{"example.com"}},
+			want: &networking.ServiceEntry{Hosts: []string{"example.com"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			Apply(c.in)
+			if !proto.Equal(c.in, c.want) {
+				t.Fatalf("got\n%v\nwant\n%v", proto.MarshalTextString(c.in), proto.MarshalTextString(c.want))
+			}
+
+			// Applying the defaults again does not change the resource.
+			Apply(c.in)
+			if !proto.Equal(c.in, c.want) {
+				t.Errorf("defaults not idempotent, got\n%v", proto.MarshalTextString(c.in))
+			}
+		})
+	}
+}
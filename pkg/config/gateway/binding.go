@@ -0,0 +1,75 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// AllowedNamespacesAnnotation is the annotation of a gateway restricting the namespaces whose virtual
+	// services may bind to it to a comma separated list.
+	AllowedNamespacesAnnotation = "gateway.istio.io/allowed-namespaces"
+
+	// AllowedNamespaceSelectorAnnotation is the annotation of a gateway restricting the namespaces whose
+	// virtual services may bind to it to the ones matching a label selector, e.g. "team in (web,api)".
+	AllowedNamespaceSelectorAnnotation = "gateway.istio.io/allowed-namespace-selector"
+)
+
+// BindingPolicy restricts the namespaces whose virtual services may bind to a gateway to the namespace of
+// the gateway and the ones allowed by its annotations, listed or selected by their labels.
+type BindingPolicy struct {
+	namespace  string
+	namespaces map[string]bool
+	selector   labels.Selector
+}
+
+// NewBindingPolicy returns the binding policy set by the annotations of a gateway of the namespace, or nil if
+// the gateway accepts the virtual services of all the namespaces. An invalid selector selects no namespace,
+// and is returned as an error along with the policy.
+func NewBindingPolicy(namespace string, annotations map[string]string) (*BindingPolicy, error) {
+	allowed, hasAllowed := annotations[AllowedNamespacesAnnotation]
+	selector, hasSelector := annotations[AllowedNamespaceSelectorAnnotation]
+	if !hasAllowed && !hasSelector {
+		return nil, nil
+	}
+
+	p := &BindingPolicy{namespace: namespace, namespaces: make(map[string]bool)}
+	for _, ns := range strings.Split(allowed, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			p.namespaces[ns] = true
+		}
+	}
+	if hasSelector {
+		s, err := labels.Parse(selector)
+		if err != nil {
+			p.selector = labels.Nothing()
+			return p, fmt.Errorf("invalid %s annotation %q: %v", AllowedNamespaceSelectorAnnotation, selector, err)
+		}
+		p.selector = s
+	}
+	return p, nil
+}
+
+// Allows returns whether the virtual services of the namespace, with the labels, may bind to the gateway.
+func (p *BindingPolicy) Allows(namespace string, namespaceLabels map[string]string) bool {
+	if p == nil || namespace == p.namespace || p.namespaces[namespace] {
+		return true
+	}
+	return p.selector != nil && p.selector.Matches(labels.Set(namespaceLabels))
+}
@@ -0,0 +1,56 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"testing"
+)
+
+func TestBindingPolicy(t *testing.T) {
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		namespace   string
+		labels      map[string]string
+		allowed     bool
+		wantErr     bool
+	}{
+		{"no annotation", nil, "other", nil, true, false},
+		{"own namespace", map[string]string{AllowedNamespacesAnnotation: ""}, "ingress", nil, true, false},
+		{"listed", map[string]string{AllowedNamespacesAnnotation: "web, api"}, "api", nil, true, false},
+		{"not listed", map[string]string{AllowedNamespacesAnnotation: "web, api"}, "other", nil, false, false},
+		{"selected", map[string]string{AllowedNamespaceSelectorAnnotation: "team in (web,api)"},
+			"other", map[string]string{"team": "web"}, true, false},
+		{"not selected", map[string]string{AllowedNamespaceSelectorAnnotation: "team in (web,api)"},
+			"other", map[string]string{"team": "db"}, false, false},
+		{"listed or selected", map[string]string{
+			AllowedNamespacesAnnotation:        "web",
+			AllowedNamespaceSelectorAnnotation: "team=api",
+		}, "web", nil, true, false},
+		{"invalid selector", map[string]string{AllowedNamespaceSelectorAnnotation: "team in ("},
+			"other", map[string]string{"team": "web"}, false, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewBindingPolicy("ingress", tt.annotations)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if got := p.Allows(tt.namespace, tt.labels); got != tt.allowed {
+				t.Errorf("got allowed %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/validation"
+)
+
+// dimensionName matches the names of the dimensions of the metrics, which are also Prometheus label names.
+var dimensionName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// dimensionSourceKey matches the request headers and node metadata keys which can be the source of a dimension.
+var dimensionSourceKey = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// Extensions holds the mesh config fields which are not part of the MeshConfig API yet. They are
+// decoded from the same mesh config YAML, whose fields unknown to MeshConfig are ignored.
+type Extensions struct {
+	// ServiceSettings configures the services matching their hosts.
+	ServiceSettings []*ServiceSettings `json:"serviceSettings,omitempty"`
+
+	// AdditionalRootNamespaces are the namespaces whose configs apply mesh-wide, in addition to the
+	// rootNamespace of the mesh config, e.g. a namespace owned by the security team. When the configs of
+	// several root namespaces conflict, the rootNamespace takes precedence, then these namespaces in order.
+	AdditionalRootNamespaces []string `json:"additionalRootNamespaces,omitempty"`
+
+	// DiscoverySelectors select the namespaces whose services, endpoints and pods are discovered in the
+	// Kubernetes clusters. A namespace is discovered if its labels match any of them, or if there are none.
+	// The objects of the other namespaces are neither watched nor cached. Changes require a restart.
+	DiscoverySelectors []*metav1.LabelSelector `json:"discoverySelectors,omitempty"`
+
+	// StatsFilter enables the stats filter of the proxies, which report the standard metrics from the proxies.
+	// Pilot inserts it in the HTTP filter chains, after the metadata exchange filter sharing the metadata
+	// of the workloads with their peers.
+	StatsFilter *StatsFilterSettings `json:"statsFilter,omitempty"`
+
+	// DefaultConfig holds the extensions of the defaultConfig of the mesh config, the default ProxyConfig
+	// of the proxies.
+	DefaultConfig *ProxyConfigExtensions `json:"defaultConfig,omitempty"`
+}
+
+// StatsFilterSettings customizes the dimensions of the standard metrics reported by the stats filter.
+type StatsFilterSettings struct {
+	// ExtraDimensions maps the names of the dimensions added to the standard metrics to their source.
+	// The proxies must be injected with the same dimensions, so that they are extracted as tags.
+	ExtraDimensions map[string]*DimensionSource `json:"extraDimensions,omitempty"`
+
+	// RemovedDimensions are the default dimensions dropped from the standard metrics, e.g. to reduce
+	// their cardinality.
+	RemovedDimensions []string `json:"removedDimensions,omitempty"`
+}
+
+// DimensionSource is the source of the value of a dimension. Exactly one of its fields must be set.
+type DimensionSource struct {
+	// RequestHeader is the name of the request header holding the value of the dimension.
+	RequestHeader string `json:"requestHeader,omitempty"`
+
+	// NodeMetadata is the key of the node metadata of the reporting proxy holding the value of the
+	// dimension, e.g. MESH_ID.
+	NodeMetadata string `json:"nodeMetadata,omitempty"`
+}
+
+// ServiceSettings configures the services of a set of hosts.
+type ServiceSettings struct {
+	// Settings applied to the services.
+	Settings ServiceSettingsOptions `json:"settings"`
+
+	// Hosts of the services, possibly wildcarded, e.g. *.kube-system.svc.cluster.local for all the
+	// services of the kube-system namespace.
+	Hosts []string `json:"hosts"`
+}
+
+// ServiceSettingsOptions are the settings of the services of ServiceSettings.
+type ServiceSettingsOptions struct {
+	// ClusterLocal keeps the traffic to the services within the cluster of the client. In a multicluster
+	// mesh, the proxies only receive the endpoints of these services from their own cluster.
+	ClusterLocal bool `json:"clusterLocal,omitempty"`
+}
+
+// DefaultExtensions returns the default mesh config extensions. The services of kube-system are
+// cluster-local, unless serviceSettings is set.
+func DefaultExtensions() Extensions {
+	return Extensions{
+		ServiceSettings: []*ServiceSettings{
+			{
+				Settings: ServiceSettingsOptions{ClusterLocal: true},
+				Hosts:    []string{"*.kube-system.svc.cluster.local"},
+			},
+		},
+	}
+}
+
+// ApplyExtensions returns the mesh config extensions decoded from the input mesh config YAML, with
+// the provided defaults applied to omitted fields.
+func ApplyExtensions(yml string, defaultExtensions Extensions) (*Extensions, error) {
+	if err := yaml.Unmarshal([]byte(yml), &defaultExtensions); err != nil {
+		return nil, multierror.Prefix(err, "failed to decode the mesh config extensions.")
+	}
+	if err := validateExtensions(&defaultExtensions); err != nil {
+		return nil, err
+	}
+	return &defaultExtensions, nil
+}
+
+// ApplyExtensionsDefaults returns the mesh config extensions decoded from the input mesh config YAML,
+// with defaults applied to omitted fields.
+func ApplyExtensionsDefaults(yml string) (*Extensions, error) {
+	return ApplyExtensions(yml, DefaultExtensions())
+}
+
+func validateExtensions(e *Extensions) (errs error) {
+	for i, s := range e.ServiceSettings {
+		if s == nil {
+			errs = multierror.Append(errs, fmt.Errorf("serviceSettings[%d] is empty", i))
+			continue
+		}
+		for _, h := range s.Hosts {
+			if err := validation.ValidateWildcardDomain(h); err != nil {
+				errs = multierror.Append(errs, multierror.Prefix(err, fmt.Sprintf("serviceSettings[%d]:", i)))
+			}
+		}
+	}
+	for _, ns := range e.AdditionalRootNamespaces {
+		if !labels.IsDNS1123Label(ns) {
+			errs = multierror.Append(errs, fmt.Errorf("invalid additional root namespace %q", ns))
+		}
+	}
+	for i, selector := range e.DiscoverySelectors {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			errs = multierror.Append(errs, multierror.Prefix(err, fmt.Sprintf("discoverySelectors[%d]:", i)))
+		}
+	}
+	if f := e.StatsFilter; f != nil {
+		for name, source := range f.ExtraDimensions {
+			if !dimensionName.MatchString(name) {
+				errs = multierror.Append(errs, fmt.Errorf("statsFilter: invalid dimension name %q", name))
+			}
+			switch {
+			case source == nil || (source.RequestHeader == "") == (source.NodeMetadata == ""):
+				errs = multierror.Append(errs, fmt.Errorf("statsFilter: dimension %s must set either requestHeader or nodeMetadata", name))
+			case !dimensionSourceKey.MatchString(source.RequestHeader + source.NodeMetadata):
+				errs = multierror.Append(errs, fmt.Errorf("statsFilter: dimension %s has an invalid source %q",
+					name, source.RequestHeader+source.NodeMetadata))
+			}
+		}
+		for _, name := range f.RemovedDimensions {
+			if !dimensionName.MatchString(name) {
+				errs = multierror.Append(errs, fmt.Errorf("statsFilter: invalid removed dimension name %q", name))
+			}
+		}
+	}
+	if err := ValidateProxyConfigExtensions(e.DefaultConfig); err != nil {
+		errs = multierror.Append(errs, multierror.Prefix(err, "defaultConfig:"))
+	}
+	return
+}
+
+// GetAdditionalRootNamespaces returns the additional root namespaces, or nil if the extensions are nil.
+func (e *Extensions) GetAdditionalRootNamespaces() []string {
+	if e == nil {
+		return nil
+	}
+	return e.AdditionalRootNamespaces
+}
+
+// GetDiscoverySelectors returns the discovery selectors, or nil if the extensions are nil.
+func (e *Extensions) GetDiscoverySelectors() []*metav1.LabelSelector {
+	if e == nil {
+		return nil
+	}
+	return e.DiscoverySelectors
+}
+
+// GetStatsFilter returns the settings of the stats filter, or nil if the extensions are nil or the stats
+// filter is not enabled.
+func (e *Extensions) GetStatsFilter() *StatsFilterSettings {
+	if e == nil {
+		return nil
+	}
+	return e.StatsFilter
+}
+
+// GetDefaultConfig returns the extensions of the default ProxyConfig, or nil if the extensions are nil or
+// there are none.
+func (e *Extensions) GetDefaultConfig() *ProxyConfigExtensions {
+	if e == nil {
+		return nil
+	}
+	return e.DefaultConfig
+}
+
+// ClusterLocalHosts returns the hosts of the cluster-local services. The default ones are returned
+// if the extensions are nil.
+func (e *Extensions) ClusterLocalHosts() host.Names {
+	if e == nil {
+		defaults := DefaultExtensions()
+		e = &defaults
+	}
+	out := make(host.Names, 0)
+	for _, s := range e.ServiceSettings {
+		if s == nil || !s.Settings.ClusterLocal {
+			continue
+		}
+		for _, h := range s.Hosts {
+			out = append(out, host.Name(h))
+		}
+	}
+	return out
+}
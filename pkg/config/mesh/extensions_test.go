@@ -0,0 +1,219 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestApplyExtensionsDefaults(t *testing.T) {
+	cases := []struct {
+		name         string
+		yaml         string
+		clusterLocal host.Names
+		wantErr      bool
+	}{
+		{
+			name:         "defaults",
+			yaml:         "rootNamespace: istio-system",
+			clusterLocal: host.Names{"*.kube-system.svc.cluster.local"},
+		},
+		{
+			name: "service settings",
+			yaml: `
+rootNamespace: istio-system
+serviceSettings:
+- settings:
+    clusterLocal: true
+  hosts:
+  - "*.kube-system.svc.cluster.local"
+  - db.default.svc.cluster.local
+- settings:
+    clusterLocal: false
+  hosts:
+  - reviews.default.svc.cluster.local
+`,
+			clusterLocal: host.Names{"*.kube-system.svc.cluster.local", "db.default.svc.cluster.local"},
+		},
+		{
+			name: "no cluster-local services",
+			yaml: `
+serviceSettings: []
+`,
+			clusterLocal: host.Names{},
+		},
+		{
+			name: "invalid host",
+			yaml: `
+serviceSettings:
+- settings:
+    clusterLocal: true
+  hosts:
+  - "db.*.svc.cluster.local"
+`,
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mesh.ApplyExtensionsDefaults(c.yaml)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("ApplyExtensionsDefaults() got error %v, want error %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if hosts := got.ClusterLocalHosts(); !reflect.DeepEqual(hosts, c.clusterLocal) {
+				t.Errorf("got cluster-local hosts %v, want %v", hosts, c.clusterLocal)
+			}
+		})
+	}
+}
+
+func TestApplyExtensionsAdditionalRootNamespaces(t *testing.T) {
+	got, err := mesh.ApplyExtensionsDefaults(`
+rootNamespace: istio-system
+additionalRootNamespaces:
+- security
+- platform
+`)
+	if err != nil {
+		t.Fatalf("ApplyExtensionsDefaults() failed: %v", err)
+	}
+	if want := []string{"security", "platform"}; !reflect.DeepEqual(got.GetAdditionalRootNamespaces(), want) {
+		t.Errorf("got additional root namespaces %v, want %v", got.GetAdditionalRootNamespaces(), want)
+	}
+
+	if _, err := mesh.ApplyExtensionsDefaults("additionalRootNamespaces: [security.team]"); err == nil {
+		t.Errorf("expected an error for an invalid namespace")
+	}
+}
+
+func TestApplyExtensionsDiscoverySelectors(t *testing.T) {
+	got, err := mesh.ApplyExtensionsDefaults(`
+discoverySelectors:
+- matchLabels:
+    istio-discovery: enabled
+- matchExpressions:
+  - key: env
+    operator: In
+    values: [prod]
+`)
+	if err != nil {
+		t.Fatalf("ApplyExtensionsDefaults() failed: %v", err)
+	}
+	want := []*metav1.LabelSelector{
+		{MatchLabels: map[string]string{"istio-discovery": "enabled"}},
+		{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"prod"}}}},
+	}
+	if !reflect.DeepEqual(got.GetDiscoverySelectors(), want) {
+		t.Errorf("got discovery selectors %v, want %v", got.GetDiscoverySelectors(), want)
+	}
+
+	if _, err := mesh.ApplyExtensionsDefaults(`
+discoverySelectors:
+- matchExpressions:
+  - key: env
+    operator: Unknown
+`); err == nil {
+		t.Errorf("expected an error for an invalid selector")
+	}
+}
+
+func TestApplyExtensionsStatsFilter(t *testing.T) {
+	got, err := mesh.ApplyExtensionsDefaults(`
+statsFilter:
+  extraDimensions:
+    request_user_agent:
+      requestHeader: user-agent
+    source_mesh:
+      nodeMetadata: MESH_ID
+  removedDimensions:
+  - request_protocol
+`)
+	if err != nil {
+		t.Fatalf("ApplyExtensionsDefaults() failed: %v", err)
+	}
+	want := &mesh.StatsFilterSettings{
+		ExtraDimensions: map[string]*mesh.DimensionSource{
+			"request_user_agent": {RequestHeader: "user-agent"},
+			"source_mesh":        {NodeMetadata: "MESH_ID"},
+		},
+		RemovedDimensions: []string{"request_protocol"},
+	}
+	if !reflect.DeepEqual(got.GetStatsFilter(), want) {
+		t.Errorf("got stats filter %v, want %v", got.GetStatsFilter(), want)
+	}
+
+	for _, invalid := range []string{
+		"statsFilter: {extraDimensions: {source_mesh: {}}}",
+		"statsFilter: {extraDimensions: {source_mesh: {requestHeader: x-mesh, nodeMetadata: MESH_ID}}}",
+		"statsFilter: {extraDimensions: {source_mesh: {requestHeader: 'x mesh'}}}",
+		"statsFilter: {extraDimensions: {source-mesh: {nodeMetadata: MESH_ID}}}",
+		"statsFilter: {removedDimensions: [request.protocol]}",
+	} {
+		if _, err := mesh.ApplyExtensionsDefaults(invalid); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestApplyMeshConfigIgnoresExtensions(t *testing.T) {
+	yaml := `
+rootNamespace: istio-config
+serviceSettings:
+- settings:
+    clusterLocal: true
+  hosts:
+  - db.default.svc.cluster.local
+additionalRootNamespaces:
+- security
+discoverySelectors:
+- matchLabels:
+    istio-discovery: enabled
+statsFilter:
+  removedDimensions:
+  - request_protocol
+`
+	got, err := mesh.ApplyMeshConfigDefaults(yaml)
+	if err != nil {
+		t.Fatalf("ApplyMeshConfigDefaults() failed: %v", err)
+	}
+	if got.RootNamespace != "istio-config" {
+		t.Errorf("got root namespace %q, want istio-config", got.RootNamespace)
+	}
+}
+
+func TestNilExtensions(t *testing.T) {
+	var e *mesh.Extensions
+	if got := e.GetAdditionalRootNamespaces(); got != nil {
+		t.Errorf("got additional root namespaces %v, want none", got)
+	}
+	if got := e.GetDiscoverySelectors(); got != nil {
+		t.Errorf("got discovery selectors %v, want none", got)
+	}
+	if got := e.GetStatsFilter(); got != nil {
+		t.Errorf("got stats filter %v, want none", got)
+	}
+	if got, want := e.ClusterLocalHosts(), (host.Names{"*.kube-system.svc.cluster.local"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got cluster-local hosts %v, want %v", got, want)
+	}
+}
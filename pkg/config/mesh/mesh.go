@@ -118,6 +118,20 @@ func ApplyMeshConfigDefaults(yaml string) (*meshconfig.MeshConfig, error) {
 	return ApplyMeshConfig(yaml, DefaultMeshConfig())
 }
 
+// ApplyProxyConfig returns a new ProxyConfig with the fields set in the
+// input YAML overriding the ones of defaultConfig.
+func ApplyProxyConfig(yaml string, defaultConfig meshconfig.ProxyConfig) (*meshconfig.ProxyConfig, error) {
+	if err := gogoprotomarshal.ApplyYAML(yaml, &defaultConfig); err != nil {
+		return nil, multierror.Prefix(err, "failed to convert to proto.")
+	}
+
+	if err := validation.ValidateProxyConfig(&defaultConfig); err != nil {
+		return nil, err
+	}
+
+	return &defaultConfig, nil
+}
+
 // EmptyMeshNetworks configuration with no networks
 func EmptyMeshNetworks() meshconfig.MeshNetworks {
 	return meshconfig.MeshNetworks{
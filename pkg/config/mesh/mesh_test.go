@@ -18,6 +18,9 @@ import (
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 
@@ -58,6 +61,30 @@ defaultConfig:
 	}
 }
 
+func TestApplyProxyConfig(t *testing.T) {
+	yaml := `
+concurrency: 4
+drainDuration: 10s
+discoveryAddress: istio-pilot-canary.istio-system:15010
+`
+	want := mesh.DefaultProxyConfig()
+	want.Concurrency = 4
+	want.DrainDuration = types.DurationProto(10 * time.Second)
+	want.DiscoveryAddress = "istio-pilot-canary.istio-system:15010"
+
+	got, err := mesh.ApplyProxyConfig(yaml, mesh.DefaultProxyConfig())
+	if err != nil {
+		t.Fatalf("ApplyProxyConfig() failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, &want) {
+		t.Fatalf("Wrong values:\n got %#v \nwant %#v", got, &want)
+	}
+
+	if _, err := mesh.ApplyProxyConfig("drainDuration: 1ms", mesh.DefaultProxyConfig()); err == nil {
+		t.Fatal("ApplyProxyConfig() accepted an invalid drain duration")
+	}
+}
+
 func TestApplyMeshNetworksDefaults(t *testing.T) {
 	yml := fmt.Sprintf(`
 networks:
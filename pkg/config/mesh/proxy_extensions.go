@@ -0,0 +1,135 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/pkg/config/validation"
+)
+
+// ProxyConfigExtensions holds the ProxyConfig fields which are not part of the ProxyConfig API yet. They are
+// decoded from the same ProxyConfig YAML, either the defaultConfig of the mesh config extensions or the
+// proxy.istio.io/config annotation of a pod, whose fields unknown to ProxyConfig are ignored.
+type ProxyConfigExtensions struct {
+	// Tracing configures the tracers which are not part of the ProxyConfig API yet.
+	Tracing *TracingExtensions `json:"tracing,omitempty"`
+}
+
+// TracingExtensions configures the tracers which are not part of the ProxyConfig API yet. A tracer set here
+// takes precedence over the tracer of the ProxyConfig.
+type TracingExtensions struct {
+	// OpenTelemetry exports the spans of the proxies with the OpenTelemetry protocol.
+	OpenTelemetry *OpenTelemetryTracer `json:"openTelemetry,omitempty"`
+}
+
+// OpenTelemetryTracer configures the OpenTelemetry tracer of the proxies, which exports their spans to an
+// OpenTelemetry collector with the OTLP/gRPC protocol.
+type OpenTelemetryTracer struct {
+	// Address is the host:port of the OTLP/gRPC endpoint of the collector.
+	Address string `json:"address"`
+
+	// ResourceAttributes are the attributes of the resource of the spans exported by the proxies, in addition
+	// to the service.name attribute, e.g. deployment.environment.
+	ResourceAttributes map[string]string `json:"resourceAttributes,omitempty"`
+
+	// Sampling is the percentage of the requests traced, from 0.0 to 100.0. It is the default of the
+	// sidecar.istio.io/traceSampling annotation of the proxies, falling back to the sampling of Pilot if unset.
+	Sampling *float64 `json:"sampling,omitempty"`
+}
+
+// ApplyProxyConfigExtensions returns the ProxyConfig extensions decoded from the input ProxyConfig YAML,
+// overriding the ones of defaultExtensions, which may be nil. A tracer set in the input replaces the
+// default one.
+func ApplyProxyConfigExtensions(yml string, defaultExtensions *ProxyConfigExtensions) (*ProxyConfigExtensions, error) {
+	var out ProxyConfigExtensions
+	if err := yaml.Unmarshal([]byte(yml), &out); err != nil {
+		return nil, multierror.Prefix(err, "failed to decode the proxy config extensions.")
+	}
+	if out.Tracing.GetOpenTelemetry() == nil && defaultExtensions.GetOpenTelemetry() != nil {
+		out.Tracing = defaultExtensions.Tracing
+	}
+	if err := ValidateProxyConfigExtensions(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ValidateProxyConfigExtensions checks the ProxyConfig extensions, which may be nil.
+func ValidateProxyConfigExtensions(e *ProxyConfigExtensions) error {
+	return ValidateOpenTelemetryTracer(e.GetOpenTelemetry())
+}
+
+// ValidateOpenTelemetryTracer checks the OpenTelemetry tracer, which may be nil.
+func ValidateOpenTelemetryTracer(t *OpenTelemetryTracer) (errs error) {
+	if t == nil {
+		return nil
+	}
+	if err := validation.ValidateProxyAddress(t.Address); err != nil {
+		errs = multierror.Append(errs, multierror.Prefix(err, "openTelemetry address:"))
+	}
+	for name := range t.ResourceAttributes {
+		if name == "" {
+			errs = multierror.Append(errs, fmt.Errorf("openTelemetry: empty resource attribute name"))
+		}
+	}
+	if t.Sampling != nil && (*t.Sampling < 0 || *t.Sampling > 100) {
+		errs = multierror.Append(errs, fmt.Errorf("openTelemetry: sampling %v is not in range 0..100", *t.Sampling))
+	}
+	return
+}
+
+// ParseOpenTelemetryTracer returns the OpenTelemetry tracer decoded from its JSON, or nil if it is empty.
+func ParseOpenTelemetryTracer(js string) (*OpenTelemetryTracer, error) {
+	if js == "" || js == "null" {
+		return nil, nil
+	}
+	var out OpenTelemetryTracer
+	if err := json.Unmarshal([]byte(js), &out); err != nil {
+		return nil, multierror.Prefix(err, "failed to decode the OpenTelemetry tracer.")
+	}
+	if err := ValidateOpenTelemetryTracer(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOpenTelemetry returns the OpenTelemetry tracer, or nil if the extensions are nil or it is not set.
+func (e *ProxyConfigExtensions) GetOpenTelemetry() *OpenTelemetryTracer {
+	if e == nil {
+		return nil
+	}
+	return e.Tracing.GetOpenTelemetry()
+}
+
+// GetOpenTelemetry returns the OpenTelemetry tracer, or nil if the extensions are nil or it is not set.
+func (e *TracingExtensions) GetOpenTelemetry() *OpenTelemetryTracer {
+	if e == nil {
+		return nil
+	}
+	return e.OpenTelemetry
+}
+
+// GetSampling returns the sampling percentage, or nil if the tracer is nil or the sampling is not set.
+func (t *OpenTelemetryTracer) GetSampling() *float64 {
+	if t == nil {
+		return nil
+	}
+	return t.Sampling
+}
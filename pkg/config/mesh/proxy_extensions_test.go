@@ -0,0 +1,147 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mesh_test
+
+import (
+	"reflect"
+	"testing"
+
+	"istio.io/istio/pkg/config/mesh"
+)
+
+func TestApplyExtensionsDefaultConfig(t *testing.T) {
+	got, err := mesh.ApplyExtensionsDefaults(`
+defaultConfig:
+  discoveryAddress: istio-pilot.istio-system:15010
+  tracing:
+    openTelemetry:
+      address: otel-collector.observability:4317
+      resourceAttributes:
+        deployment.environment: prod
+      sampling: 10
+`)
+	if err != nil {
+		t.Fatalf("ApplyExtensionsDefaults() failed: %v", err)
+	}
+	sampling := 10.0
+	want := &mesh.OpenTelemetryTracer{
+		Address:            "otel-collector.observability:4317",
+		ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+		Sampling:           &sampling,
+	}
+	if !reflect.DeepEqual(got.GetDefaultConfig().GetOpenTelemetry(), want) {
+		t.Errorf("got OpenTelemetry tracer %v, want %v", got.GetDefaultConfig().GetOpenTelemetry(), want)
+	}
+
+	meshConfig, err := mesh.ApplyMeshConfigDefaults(`
+defaultConfig:
+  tracing:
+    openTelemetry:
+      address: otel-collector.observability:4317
+`)
+	if err != nil {
+		t.Fatalf("ApplyMeshConfigDefaults() failed: %v", err)
+	}
+	if meshConfig.DefaultConfig.GetTracing().GetTracer() != nil {
+		t.Errorf("got tracer %v, want none", meshConfig.DefaultConfig.GetTracing().GetTracer())
+	}
+
+	if _, err := mesh.ApplyExtensionsDefaults("defaultConfig: {tracing: {openTelemetry: {address: otel-collector}}}"); err == nil {
+		t.Errorf("expected an error for an address without port")
+	}
+}
+
+func TestApplyProxyConfigExtensions(t *testing.T) {
+	defaults := &mesh.ProxyConfigExtensions{
+		Tracing: &mesh.TracingExtensions{
+			OpenTelemetry: &mesh.OpenTelemetryTracer{Address: "otel-collector.observability:4317"},
+		},
+	}
+	cases := []struct {
+		name     string
+		yaml     string
+		defaults *mesh.ProxyConfigExtensions
+		want     *mesh.OpenTelemetryTracer
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			yaml:     "concurrency: 2",
+			defaults: defaults,
+			want:     defaults.Tracing.OpenTelemetry,
+		},
+		{
+			name: "no defaults",
+			yaml: "concurrency: 2",
+		},
+		{
+			name: "override",
+			yaml: `
+tracing:
+  openTelemetry:
+    address: 10.0.0.1:4317
+    resourceAttributes:
+      team: payments
+`,
+			defaults: defaults,
+			want: &mesh.OpenTelemetryTracer{
+				Address:            "10.0.0.1:4317",
+				ResourceAttributes: map[string]string{"team": "payments"},
+			},
+		},
+		{
+			name:    "invalid sampling",
+			yaml:    "tracing: {openTelemetry: {address: '10.0.0.1:4317', sampling: 101}}",
+			wantErr: true,
+		},
+		{
+			name:    "empty resource attribute",
+			yaml:    "tracing: {openTelemetry: {address: '10.0.0.1:4317', resourceAttributes: {'': x}}}",
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := mesh.ApplyProxyConfigExtensions(c.yaml, c.defaults)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("ApplyProxyConfigExtensions() got error %v, want error %v", err, c.wantErr)
+			}
+			if c.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got.GetOpenTelemetry(), c.want) {
+				t.Errorf("got OpenTelemetry tracer %v, want %v", got.GetOpenTelemetry(), c.want)
+			}
+		})
+	}
+}
+
+func TestParseOpenTelemetryTracer(t *testing.T) {
+	got, err := mesh.ParseOpenTelemetryTracer(`{"address":"otel-collector:4317","resourceAttributes":{"team":"payments"}}`)
+	if err != nil {
+		t.Fatalf("ParseOpenTelemetryTracer() failed: %v", err)
+	}
+	want := &mesh.OpenTelemetryTracer{Address: "otel-collector:4317", ResourceAttributes: map[string]string{"team": "payments"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got OpenTelemetry tracer %v, want %v", got, want)
+	}
+
+	if got, err := mesh.ParseOpenTelemetryTracer(""); got != nil || err != nil {
+		t.Errorf("got OpenTelemetry tracer %v and error %v, want none", got, err)
+	}
+	if _, err := mesh.ParseOpenTelemetryTracer(`{"address":"otel-collector"}`); err == nil {
+		t.Errorf("expected an error for an address without port")
+	}
+}
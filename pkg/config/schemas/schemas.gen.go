@@ -61,6 +61,32 @@ var (
 		VariableName:  "SyntheticServiceEntry",
 	}
 
+	// WorkloadEntry describes workload entries
+	WorkloadEntry = schema.Instance{
+		Type:          "workload-entry",
+		Plural:        "workload-entries",
+		Group:         "networking",
+		Version:       "v1alpha3",
+		MessageName:   "istio.networking.v1alpha3.WorkloadEntry",
+		Validate:      validation.ValidateWorkloadEntry,
+		Collection:    "istio/networking/v1alpha3/workloadentries",
+		ClusterScoped: false,
+		VariableName:  "WorkloadEntry",
+	}
+
+	// WorkloadGroup describes workload groups
+	WorkloadGroup = schema.Instance{
+		Type:          "workload-group",
+		Plural:        "workload-groups",
+		Group:         "networking",
+		Version:       "v1alpha3",
+		MessageName:   "istio.networking.v1alpha3.WorkloadGroup",
+		Validate:      validation.ValidateWorkloadGroup,
+		Collection:    "istio/networking/v1alpha3/workloadgroups",
+		ClusterScoped: false,
+		VariableName:  "WorkloadGroup",
+	}
+
 	// DestinationRule describes destination rules
 	DestinationRule = schema.Instance{
 		Type:          "destination-rule",
@@ -246,12 +272,27 @@ var (
 		VariableName:  "AuthorizationPolicy",
 	}
 
+	// Telemetry describes the telemetry providers of workloads.
+	Telemetry = schema.Instance{
+		Type:          "telemetry",
+		Plural:        "telemetries",
+		Group:         "telemetry",
+		Version:       "v1alpha1",
+		MessageName:   "istio.telemetry.v1alpha1.Telemetry",
+		Validate:      validation.ValidateTelemetry,
+		Collection:    "istio/telemetry/v1alpha1/telemetries",
+		ClusterScoped: false,
+		VariableName:  "Telemetry",
+	}
+
 	// Istio lists all Istio schemas.
 	Istio = schema.Set{
 		VirtualService,
 		Gateway,
 		ServiceEntry,
 		SyntheticServiceEntry,
+		WorkloadEntry,
+		WorkloadGroup,
 		DestinationRule,
 		EnvoyFilter,
 		Sidecar,
@@ -266,5 +307,6 @@ var (
 		RbacConfig,
 		ClusterRbacConfig,
 		AuthorizationPolicy,
+		Telemetry,
 	}
 )
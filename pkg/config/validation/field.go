@@ -0,0 +1,234 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+	multierror "github.com/hashicorp/go-multierror"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/host"
+)
+
+// FieldError is a validation error of a field of a config. Its path locates the field in the spec of
+// the config, e.g. http[0].route[1].weight.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Warning is a validation result flagging a config that is accepted, but is likely not what the user
+// intended.
+type Warning struct {
+	Err error
+}
+
+func (w *Warning) Error() string {
+	return w.Err.Error()
+}
+
+// WrapWarning returns a warning for the error, or nil if the error is nil.
+func WrapWarning(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Warning{Err: err}
+}
+
+// withField prefixes the paths of the errors with the path of the field holding them.
+func withField(path string, err error) error {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *multierror.Error:
+		var out error
+		for _, inner := range e.Errors {
+			out = appendErrors(out, withField(path, inner))
+		}
+		return out
+	case *Warning:
+		return &Warning{Err: withField(path, e.Err)}
+	case *FieldError:
+		if strings.HasPrefix(e.Path, "[") {
+			return &FieldError{Path: path + e.Path, Err: e.Err}
+		}
+		return &FieldError{Path: path + "." + e.Path, Err: e.Err}
+	default:
+		return &FieldError{Path: path, Err: err}
+	}
+}
+
+// indexed returns the path of the element of a list field.
+func indexed(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// FieldErrors flattens the validation errors, returning the errors and the warnings apart. The errors
+// that are not a FieldError have an empty path.
+func FieldErrors(err error) (errs []*FieldError, warnings []*FieldError) {
+	var flatten func(err error, warning bool)
+	flatten = func(err error, warning bool) {
+		switch e := err.(type) {
+		case nil:
+		case *multierror.Error:
+			for _, inner := range e.Errors {
+				flatten(inner, warning)
+			}
+		case *Warning:
+			flatten(e.Err, true)
+		default:
+			fe, ok := e.(*FieldError)
+			if !ok {
+				fe = &FieldError{Err: e}
+			}
+			if warning {
+				warnings = append(warnings, fe)
+			} else {
+				errs = append(errs, fe)
+			}
+		}
+	}
+	flatten(err, false)
+	return
+}
+
+// SplitWarnings splits the validation errors into the errors rejecting the config and the warnings.
+func SplitWarnings(err error) (errs error, warnings error) {
+	e, w := FieldErrors(err)
+	for _, fe := range e {
+		errs = appendErrors(errs, fe)
+	}
+	for _, fe := range w {
+		warnings = appendErrors(warnings, &Warning{Err: fe})
+	}
+	return
+}
+
+// ValidateWarnings returns the warnings of a config: the settings that are valid, but are likely not
+// what the user intended. The configs are accepted regardless of their warnings, so the warnings are
+// not part of the ValidateFunc of the configs.
+func ValidateWarnings(_, _ string, msg proto.Message) (warnings error) {
+	switch c := msg.(type) {
+	case *networking.VirtualService:
+		warnings = virtualServiceWarnings(c)
+	case *networking.DestinationRule:
+		warnings = destinationRuleWarnings(c)
+	}
+	return
+}
+
+func virtualServiceWarnings(vs *networking.VirtualService) (warnings error) {
+	// The routes after a route matching all the requests are never used.
+	for i, route := range vs.Http {
+		if matchesAll(route.Match) && i < len(vs.Http)-1 {
+			warnings = appendErrors(warnings, WrapWarning(withField(indexed("http", i+1),
+				fmt.Errorf("the route is unreachable, http[%d] matches all the requests", i))))
+			break
+		}
+	}
+	for i, route := range vs.Http {
+		for j, dest := range route.Route {
+			if len(route.Route) > 1 && dest.Weight == 0 {
+				warnings = appendErrors(warnings, WrapWarning(withField(indexed(indexed("http", i)+".route", j)+".weight",
+					fmt.Errorf("the destination receives no traffic"))))
+			}
+		}
+	}
+	return
+}
+
+// matchesAll returns whether the match conditions of an HTTP route match all the requests.
+func matchesAll(matches []*networking.HTTPMatchRequest) bool {
+	if len(matches) == 0 {
+		return true
+	}
+	for _, m := range matches {
+		if m == nil {
+			continue
+		}
+		if m.Scheme != nil || m.Method != nil || m.Authority != nil || len(m.Headers) > 0 || m.Port != 0 ||
+			len(m.SourceLabels) > 0 || len(m.Gateways) > 0 || len(m.QueryParams) > 0 {
+			continue
+		}
+		if m.Uri == nil || m.Uri.GetPrefix() == "/" {
+			return true
+		}
+	}
+	return false
+}
+
+func destinationRuleWarnings(rule *networking.DestinationRule) (warnings error) {
+	for i, subset := range rule.Subsets {
+		if len(subset.Labels) == 0 {
+			warnings = appendErrors(warnings, WrapWarning(withField(indexed("subsets", i)+".labels",
+				fmt.Errorf("subset %q has no labels, it selects all the endpoints of the host", subset.Name))))
+		}
+	}
+	return
+}
+
+// ValidateSubsetReferences checks that the subsets the destinations of the virtual service refer to
+// are defined by a destination rule of their host. The subsets are given by host, the hosts being fully
+// qualified. The short hosts of the destinations are qualified with the namespace of the virtual service
+// and the domain suffix. The destination rules of a virtual service may be applied after it, so missing
+// subsets are warnings.
+func ValidateSubsetReferences(vs *networking.VirtualService, namespace, domainSuffix string,
+	subsets map[host.Name]map[string]bool) (warnings error) {
+	check := func(path string, dest *networking.Destination) {
+		if dest == nil || dest.Subset == "" {
+			return
+		}
+		h := host.Name(dest.Host)
+		if !strings.Contains(dest.Host, ".") {
+			h = host.Name(fmt.Sprintf("%s.%s.svc.%s", dest.Host, namespace, domainSuffix))
+		}
+		for ruleHost, names := range subsets {
+			if h.SubsetOf(ruleHost) && names[dest.Subset] {
+				return
+			}
+		}
+		warnings = appendErrors(warnings, WrapWarning(withField(path+".subset",
+			fmt.Errorf("subset %q of host %s is not defined by a destination rule", dest.Subset, h))))
+	}
+
+	for i, route := range vs.Http {
+		for j, dest := range route.Route {
+			check(indexed(indexed("http", i)+".route", j)+".destination", dest.Destination)
+		}
+		check(indexed("http", i)+".mirror", route.Mirror)
+	}
+	for i, route := range vs.Tls {
+		for j, dest := range route.Route {
+			check(indexed(indexed("tls", i)+".route", j)+".destination", dest.Destination)
+		}
+	}
+	for i, route := range vs.Tcp {
+		for j, dest := range route.Route {
+			check(indexed(indexed("tcp", i)+".route", j)+".destination", dest.Destination)
+		}
+	}
+	return
+}
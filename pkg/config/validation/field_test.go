@@ -0,0 +1,191 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+
+	networking "istio.io/api/networking/v1alpha3"
+
+	"istio.io/istio/pkg/config/host"
+)
+
+func paths(errs []*FieldError) []string {
+	out := make([]string, 0, len(errs))
+	for _, e := range errs {
+		out = append(out, e.Path)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestFieldPaths(t *testing.T) {
+	vs := &networking.VirtualService{
+		Hosts: []string{"reviews", "_invalid"},
+		Http: []*networking.HTTPRoute{
+			{
+				Route: []*networking.HTTPRouteDestination{
+					{Destination: &networking.Destination{Host: "reviews", Subset: "v1"}, Weight: 50},
+					{Destination: &networking.Destination{Host: "reviews", Subset: "-v2"}, Weight: 60},
+				},
+			},
+		},
+		Tcp: []*networking.TCPRoute{{}},
+	}
+	errs, warnings := FieldErrors(ValidateVirtualService("reviews", "default", vs))
+	want := []string{
+		"hosts[1]",
+		"http[0].route",
+		"http[0].route[1].destination",
+		"tcp[0].route",
+	}
+	if got := paths(errs); !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %v, want %v", got, want)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("got warnings %v, want none", warnings)
+	}
+
+	dr := &networking.DestinationRule{
+		Host: "reviews",
+		Subsets: []*networking.Subset{
+			{Name: "v1", Labels: map[string]string{"version": "v1"}},
+			{Name: "", Labels: map[string]string{"version": "v2"}},
+		},
+	}
+	errs, _ = FieldErrors(ValidateDestinationRule("reviews", "default", dr))
+	if got, want := paths(errs), []string{"subsets[1].name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got paths %v, want %v", got, want)
+	}
+}
+
+func TestSplitWarnings(t *testing.T) {
+	err := appendErrors(
+		withField("a", errors.New("error")),
+		WrapWarning(withField("b", errors.New("warning"))),
+		errors.New("unlocated error"))
+	errs, warnings := SplitWarnings(withField("spec", err))
+
+	gotErrs, gotWarnings := FieldErrors(appendErrors(errs, warnings))
+	if got, want := paths(gotErrs), []string{"spec", "spec.a"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got error paths %v, want %v", got, want)
+	}
+	if got, want := paths(gotWarnings), []string{"spec.b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got warning paths %v, want %v", got, want)
+	}
+	if errs, warnings := SplitWarnings(nil); errs != nil || warnings != nil {
+		t.Errorf("got %v, %v, want no error", errs, warnings)
+	}
+}
+
+func TestValidateWarnings(t *testing.T) {
+	cases := []struct {
+		name string
+		in   proto.Message
+		want []string
+	}{
+		{
+			name: "unreachable route",
+			in: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{
+					{Match: []*networking.HTTPMatchRequest{{Uri: &networking.StringMatch{
+						MatchType: &networking.StringMatch_Prefix{Prefix: "/"}}}}},
+					{},
+				},
+			},
+			want: []string{"http[1]"},
+		},
+		{
+			name: "reachable routes",
+			in: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{
+					{Match: []*networking.HTTPMatchRequest{{Uri: &networking.StringMatch{
+						MatchType: &networking.StringMatch_Prefix{Prefix: "/api"}}}}},
+					{},
+				},
+			},
+			want: []string{},
+		},
+		{
+			name: "destination without traffic",
+			in: &networking.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*networking.HTTPRoute{{
+					Route: []*networking.HTTPRouteDestination{
+						{Destination: &networking.Destination{Host: "reviews", Subset: "v1"}, Weight: 100},
+						{Destination: &networking.Destination{Host: "reviews", Subset: "v2"}},
+					},
+				}},
+			},
+			want: []string{"http[0].route[1].weight"},
+		},
+		{
+			name: "subset without labels",
+			in: &networking.DestinationRule{
+				Host:    "reviews",
+				Subsets: []*networking.Subset{{Name: "all"}},
+			},
+			want: []string{"subsets[0].labels"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			errs, got := FieldErrors(ValidateWarnings("", "", tt.in))
+			if len(errs) != 0 {
+				t.Errorf("got errors %v, want only warnings", errs)
+			}
+			if !reflect.DeepEqual(paths(got), tt.want) {
+				t.Errorf("got warnings %v, want %v", paths(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateSubsetReferences(t *testing.T) {
+	vs := &networking.VirtualService{
+		Hosts: []string{"reviews"},
+		Http: []*networking.HTTPRoute{{
+			Route: []*networking.HTTPRouteDestination{
+				{Destination: &networking.Destination{Host: "reviews", Subset: "v1"}, Weight: 50},
+				{Destination: &networking.Destination{Host: "reviews", Subset: "v3"}, Weight: 50},
+			},
+			Mirror: &networking.Destination{Host: "ratings.other.svc.cluster.local", Subset: "v1"},
+		}},
+		Tcp: []*networking.TCPRoute{{
+			Route: []*networking.RouteDestination{
+				{Destination: &networking.Destination{Host: "db.example.com", Subset: "primary"}},
+			},
+		}},
+	}
+	subsets := map[host.Name]map[string]bool{
+		"reviews.default.svc.cluster.local": {"v1": true, "v2": true},
+		"*.other.svc.cluster.local":         {"v1": true},
+	}
+	errs, warnings := FieldErrors(ValidateSubsetReferences(vs, "default", "cluster.local", subsets))
+	if len(errs) != 0 {
+		t.Errorf("got errors %v, want only warnings", errs)
+	}
+	want := []string{"http[0].route[1].destination.subset", "tcp[0].route[0].destination.subset"}
+	if got := paths(warnings); !reflect.DeepEqual(got, want) {
+		t.Errorf("got warnings %v, want %v", got, want)
+	}
+}
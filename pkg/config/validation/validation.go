@@ -15,6 +15,7 @@
 package validation
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -39,12 +40,15 @@ import (
 	authz "istio.io/api/security/v1beta1"
 	"istio.io/pkg/log"
 
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/gateway"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/security"
+	"istio.io/istio/pkg/config/virtualservice"
 	"istio.io/istio/pkg/config/visibility"
 	"istio.io/istio/pkg/config/xds"
 )
@@ -101,6 +105,17 @@ var scope = log.RegisterScope("validation", "CRD validation debugging", 0)
 // ValidateFunc defines a validation func for an API proto.
 type ValidateFunc func(name, namespace string, config proto.Message) error
 
+// ValidateAnnotations validates the annotations of a config changing its translation, which are not part of
+// the ValidateFunc of the configs as it only covers their spec.
+func ValidateAnnotations(config proto.Message, annotations map[string]string) error {
+	switch config.(type) {
+	case *networking.VirtualService:
+		_, err := virtualservice.ParseURIRegexRewrites(annotations)
+		return err
+	}
+	return nil
+}
+
 // ValidatePort checks that the network port is in range
 func ValidatePort(port int) error {
 	if 1 <= port && port <= 65535 {
@@ -298,18 +313,19 @@ func ValidateGateway(name, _ string, msg proto.Message) (errs error) {
 	if len(value.Servers) == 0 {
 		errs = appendErrors(errs, fmt.Errorf("gateway must have at least one server"))
 	} else {
-		for _, server := range value.Servers {
-			errs = appendErrors(errs, validateServer(server))
+		for i, server := range value.Servers {
+			errs = appendErrors(errs, withField(indexed("servers", i), validateServer(server)))
 		}
 	}
 
 	// Ensure unique port names
 	portNames := make(map[string]bool)
 
-	for _, s := range value.Servers {
+	for i, s := range value.Servers {
 		if s.Port != nil {
 			if portNames[s.Port.Name] {
-				errs = appendErrors(errs, fmt.Errorf("port names in servers must be unique: duplicate name %s", s.Port.Name))
+				errs = appendErrors(errs, withField(indexed("servers", i)+".port.name",
+					fmt.Errorf("port names in servers must be unique: duplicate name %s", s.Port.Name)))
 			}
 			portNames[s.Port.Name] = true
 		}
@@ -320,17 +336,20 @@ func ValidateGateway(name, _ string, msg proto.Message) (errs error) {
 
 func validateServer(server *networking.Server) (errs error) {
 	if len(server.Hosts) == 0 {
-		errs = appendErrors(errs, fmt.Errorf("server config must contain at least one host"))
+		errs = appendErrors(errs, withField("hosts", fmt.Errorf("server config must contain at least one host")))
 	} else {
-		for _, hostname := range server.Hosts {
-			errs = appendErrors(errs, validateNamespaceSlashWildcardHostname(hostname, true))
+		for i, hostname := range server.Hosts {
+			errs = appendErrors(errs, withField(indexed("hosts", i), validateNamespaceSlashWildcardHostname(hostname, true)))
 		}
 	}
 	portErr := validateServerPort(server.Port)
 	if portErr != nil {
-		errs = appendErrors(errs, portErr)
+		errs = appendErrors(errs, withField("port", portErr))
+	}
+	errs = appendErrors(errs, withField("tls", validateTLSOptions(server.Tls)))
+	if server.Bind != "" {
+		errs = appendErrors(errs, withField("bind", ValidateIPAddress(server.Bind)))
 	}
-	errs = appendErrors(errs, validateTLSOptions(server.Tls))
 
 	// If port is HTTPS or TLS, make sure that server has TLS options
 	if portErr == nil {
@@ -425,14 +444,14 @@ func ValidateDestinationRule(_, _ string, msg proto.Message) (errs error) {
 	}
 
 	errs = appendErrors(errs,
-		ValidateWildcardDomain(rule.Host),
-		validateTrafficPolicy(rule.TrafficPolicy))
+		withField("host", ValidateWildcardDomain(rule.Host)),
+		withField("trafficPolicy", validateTrafficPolicy(rule.TrafficPolicy)))
 
-	for _, subset := range rule.Subsets {
-		errs = appendErrors(errs, validateSubset(subset))
+	for i, subset := range rule.Subsets {
+		errs = appendErrors(errs, withField(indexed("subsets", i), validateSubset(subset)))
 	}
 
-	errs = appendErrors(errs, validateExportTo(rule.ExportTo))
+	errs = appendErrors(errs, withField("exportTo", validateExportTo(rule.ExportTo)))
 	return
 }
 
@@ -908,9 +927,9 @@ func validateTLS(settings *networking.TLSSettings) (errs error) {
 }
 
 func validateSubset(subset *networking.Subset) error {
-	return appendErrors(validateSubsetName(subset.Name),
-		labels.Instance(subset.Labels).Validate(),
-		validateTrafficPolicy(subset.TrafficPolicy))
+	return appendErrors(withField("name", validateSubsetName(subset.Name)),
+		withField("labels", labels.Instance(subset.Labels).Validate()),
+		withField("trafficPolicy", validateTrafficPolicy(subset.TrafficPolicy)))
 }
 
 func validatePortTrafficPolicies(pls []*networking.TrafficPolicy_PortTrafficPolicy) (errs error) {
@@ -1116,9 +1135,29 @@ func ValidateMeshConfig(mesh *meshconfig.MeshConfig) (errs error) {
 		errs = multierror.Append(errs, err)
 	}
 
+	if err := validateAccessLogFormat(mesh.AccessLogEncoding, mesh.AccessLogFormat); err != nil {
+		errs = multierror.Append(errs, multierror.Prefix(err, "invalid access log format:"))
+	}
+
 	return
 }
 
+// validateAccessLogFormat checks that a custom JSON access log format is an object mapping log
+// field names to envoy command operators.
+func validateAccessLogFormat(encoding meshconfig.MeshConfig_AccessLogEncoding, format string) error {
+	if encoding != meshconfig.MeshConfig_JSON || format == "" {
+		return nil
+	}
+	fields := map[string]string{}
+	if err := json.Unmarshal([]byte(format), &fields); err != nil {
+		return fmt.Errorf("JSON format must be an object of string values: %v", err)
+	}
+	if len(fields) == 0 {
+		return errors.New("JSON format must define at least one field")
+	}
+	return nil
+}
+
 // ValidateProxyConfig checks that the mesh config is well-formed
 func ValidateProxyConfig(config *meshconfig.ProxyConfig) (errs error) {
 	if config.ConfigPath == "" {
@@ -1513,6 +1552,113 @@ func ValidateAuthorizationPolicy(_, _ string, msg proto.Message) error {
 	return nil
 }
 
+// ValidateTelemetry checks that Telemetry is well-formed.
+func ValidateTelemetry(_, _ string, msg proto.Message) (errs error) {
+	in, ok := msg.(*telemetry.Telemetry)
+	if !ok {
+		return errors.New("cannot cast to Telemetry")
+	}
+
+	if in.Selector != nil {
+		for k, v := range in.Selector.MatchLabels {
+			if k == "" || v == "" {
+				errs = appendErrors(errs, fmt.Errorf("telemetry: selector has empty key or values"))
+			}
+		}
+	}
+
+	if in.Tracing != nil {
+		errs = appendErrors(errs, validateTelemetryProviders("tracing", in.Tracing.Providers, constants.TelemetryProviderEnvoy))
+		if p := in.Tracing.RandomSamplingPercentage; p != nil && (p.Value < 0 || p.Value > 100) {
+			errs = appendErrors(errs, fmt.Errorf("telemetry: tracing randomSamplingPercentage must be in range [0.0, 100.0], got %v", p.Value))
+		}
+		for _, name := range in.Tracing.RequestHeadersForTags {
+			if err := ValidateHTTPHeaderName(name); err != nil {
+				errs = appendErrors(errs, fmt.Errorf("telemetry: tracing requestHeadersForTags: %v", err))
+			}
+		}
+		for _, tag := range in.Tracing.CustomTags {
+			errs = appendErrors(errs, validateTelemetryCustomTag(tag))
+		}
+	}
+	if in.Metrics != nil {
+		errs = appendErrors(errs, validateTelemetryProviders("metrics", in.Metrics.Providers, constants.TelemetryProviderMixer))
+	}
+	if in.AccessLogging != nil {
+		errs = appendErrors(errs, validateTelemetryProviders("accessLogging", in.AccessLogging.Providers,
+			constants.TelemetryProviderEnvoy, constants.TelemetryProviderEnvoyALS))
+		errs = appendErrors(errs, validateAccessLogFilter(in.AccessLogging.Filter))
+	}
+	return
+}
+
+// envoyResponseFlags are the short names of the response flags of the Envoy access logs.
+var envoyResponseFlags = map[string]bool{
+	"LH": true, "UH": true, "UT": true, "LR": true, "UR": true, "UF": true, "UC": true, "UO": true, "NR": true,
+	"DI": true, "FI": true, "RL": true, "UAEX": true, "RLSE": true, "DC": true, "URX": true, "SI": true, "IH": true,
+	"DPE": true,
+}
+
+func validateAccessLogFilter(in *telemetry.AccessLogFilter) (errs error) {
+	if in == nil {
+		return
+	}
+	if in.MinStatusCode != 0 && (in.MinStatusCode < 100 || in.MinStatusCode > 599) {
+		errs = appendErrors(errs, fmt.Errorf("telemetry: accessLogging filter minStatusCode must be in range [100, 599], got %d",
+			in.MinStatusCode))
+	}
+	if in.MinDuration != nil {
+		if err := ValidateDurationGogo(in.MinDuration); err != nil {
+			errs = appendErrors(errs, fmt.Errorf("telemetry: accessLogging filter minDuration: %v", err))
+		}
+	}
+	for _, flag := range in.ResponseFlags {
+		if !envoyResponseFlags[flag] {
+			errs = appendErrors(errs, fmt.Errorf("telemetry: accessLogging filter has unknown response flag %q", flag))
+		}
+	}
+	return
+}
+
+func validateTelemetryCustomTag(tag *telemetry.CustomTag) (errs error) {
+	if tag == nil {
+		return fmt.Errorf("telemetry: tracing custom tag must not be empty")
+	}
+	if tag.Tag == "" {
+		errs = appendErrors(errs, fmt.Errorf("telemetry: tracing custom tag name must be set"))
+	}
+	switch {
+	case tag.Literal == "" && tag.Environment == "":
+		errs = appendErrors(errs, fmt.Errorf("telemetry: tracing custom tag %q must set either literal or environment", tag.Tag))
+	case tag.Literal != "" && tag.Environment != "":
+		errs = appendErrors(errs, fmt.Errorf("telemetry: tracing custom tag %q must not set both literal and environment", tag.Tag))
+	case tag.DefaultValue != "" && tag.Environment == "":
+		errs = appendErrors(errs, fmt.Errorf("telemetry: tracing custom tag %q defaultValue requires environment", tag.Tag))
+	}
+	return
+}
+
+func validateTelemetryProviders(section string, providers []*telemetry.ProviderRef, supported ...string) (errs error) {
+	for _, p := range providers {
+		if p.GetName() == "" {
+			errs = appendErrors(errs, fmt.Errorf("telemetry: %s provider name must be set", section))
+			continue
+		}
+		found := false
+		for _, s := range supported {
+			if p.Name == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = appendErrors(errs, fmt.Errorf("telemetry: unsupported %s provider %q, supported providers are %v",
+				section, p.Name, supported))
+		}
+	}
+	return
+}
+
 // ValidateServiceRole checks that ServiceRole is well-formed.
 func ValidateServiceRole(_, _ string, msg proto.Message) error {
 	in, ok := msg.(*rbac.ServiceRole)
@@ -1770,7 +1916,7 @@ func ValidateVirtualService(_, _ string, msg proto.Message) (errs error) {
 		appliesToMesh = true
 	}
 
-	errs = appendErrors(errs, validateGatewayNames(virtualService.Gateways))
+	errs = appendErrors(errs, withField("gateways", validateGatewayNames(virtualService.Gateways)))
 	for _, gatewayName := range virtualService.Gateways {
 		if gatewayName == constants.IstioMeshGateway {
 			appliesToMesh = true
@@ -1779,19 +1925,20 @@ func ValidateVirtualService(_, _ string, msg proto.Message) (errs error) {
 	}
 
 	if len(virtualService.Hosts) == 0 {
-		errs = appendErrors(errs, fmt.Errorf("virtual service must have at least one host"))
+		errs = appendErrors(errs, withField("hosts", fmt.Errorf("virtual service must have at least one host")))
 	}
 
 	allHostsValid := true
-	for _, virtualHost := range virtualService.Hosts {
+	for i, virtualHost := range virtualService.Hosts {
 		if err := ValidateWildcardDomain(virtualHost); err != nil {
 			ipAddr := net.ParseIP(virtualHost) // Could also be an IP
 			if ipAddr == nil {
-				errs = appendErrors(errs, err)
+				errs = appendErrors(errs, withField(indexed("hosts", i), err))
 				allHostsValid = false
 			}
 		} else if appliesToMesh && virtualHost == "*" {
-			errs = appendErrors(errs, fmt.Errorf("wildcard host * is not allowed for virtual services bound to the mesh gateway"))
+			errs = appendErrors(errs, withField(indexed("hosts", i),
+				fmt.Errorf("wildcard host * is not allowed for virtual services bound to the mesh gateway")))
 			allHostsValid = false
 		}
 	}
@@ -1805,7 +1952,8 @@ func ValidateVirtualService(_, _ string, msg proto.Message) (errs error) {
 			for j := i + 1; j < len(virtualService.Hosts); j++ {
 				hostJ := host.Name(virtualService.Hosts[j])
 				if hostI.Matches(hostJ) {
-					errs = appendErrors(errs, fmt.Errorf("duplicate hosts in virtual service: %s & %s", hostI, hostJ))
+					errs = appendErrors(errs, withField(indexed("hosts", j),
+						fmt.Errorf("duplicate hosts in virtual service: %s & %s", hostI, hostJ)))
 				}
 			}
 		}
@@ -1814,17 +1962,17 @@ func ValidateVirtualService(_, _ string, msg proto.Message) (errs error) {
 	if len(virtualService.Http) == 0 && len(virtualService.Tcp) == 0 && len(virtualService.Tls) == 0 {
 		errs = appendErrors(errs, errors.New("http, tcp or tls must be provided in virtual service"))
 	}
-	for _, httpRoute := range virtualService.Http {
-		errs = appendErrors(errs, validateHTTPRoute(httpRoute))
+	for i, httpRoute := range virtualService.Http {
+		errs = appendErrors(errs, withField(indexed("http", i), validateHTTPRoute(httpRoute)))
 	}
-	for _, tlsRoute := range virtualService.Tls {
-		errs = appendErrors(errs, validateTLSRoute(tlsRoute, virtualService))
+	for i, tlsRoute := range virtualService.Tls {
+		errs = appendErrors(errs, withField(indexed("tls", i), validateTLSRoute(tlsRoute, virtualService)))
 	}
-	for _, tcpRoute := range virtualService.Tcp {
-		errs = appendErrors(errs, validateTCPRoute(tcpRoute))
+	for i, tcpRoute := range virtualService.Tcp {
+		errs = appendErrors(errs, withField(indexed("tcp", i), validateTCPRoute(tcpRoute)))
 	}
 
-	errs = appendErrors(errs, validateExportTo(virtualService.ExportTo))
+	errs = appendErrors(errs, withField("exportTo", validateExportTo(virtualService.ExportTo)))
 	return
 }
 
@@ -1833,13 +1981,13 @@ func validateTLSRoute(tls *networking.TLSRoute, context *networking.VirtualServi
 		return nil
 	}
 	if len(tls.Match) == 0 {
-		errs = appendErrors(errs, errors.New("TLS route must have at least one match condition"))
+		errs = appendErrors(errs, withField("match", errors.New("TLS route must have at least one match condition")))
 	}
-	for _, match := range tls.Match {
-		errs = appendErrors(errs, validateTLSMatch(match, context))
+	for i, match := range tls.Match {
+		errs = appendErrors(errs, withField(indexed("match", i), validateTLSMatch(match, context)))
 	}
 	if len(tls.Route) == 0 {
-		errs = appendErrors(errs, errors.New("TLS route is required"))
+		errs = appendErrors(errs, withField("route", errors.New("TLS route is required")))
 	}
 	errs = appendErrors(errs, validateRouteDestinations(tls.Route))
 	return
@@ -1890,11 +2038,11 @@ func validateTCPRoute(tcp *networking.TCPRoute) (errs error) {
 	if tcp == nil {
 		return nil
 	}
-	for _, match := range tcp.Match {
-		errs = appendErrors(errs, validateTCPMatch(match))
+	for i, match := range tcp.Match {
+		errs = appendErrors(errs, withField(indexed("match", i), validateTCPMatch(match)))
 	}
 	if len(tcp.Route) == 0 {
-		errs = appendErrors(errs, errors.New("TCP route is required"))
+		errs = appendErrors(errs, withField("route", errors.New("TCP route is required")))
 	}
 	errs = appendErrors(errs, validateRouteDestinations(tcp.Route))
 	return
@@ -1974,39 +2122,41 @@ func validateHTTPRoute(http *networking.HTTPRoute) (errs error) {
 		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
 	}
 
-	errs = appendErrors(errs, validateCORSPolicy(http.CorsPolicy))
-	errs = appendErrors(errs, validateHTTPFaultInjection(http.Fault))
+	errs = appendErrors(errs, withField("corsPolicy", validateCORSPolicy(http.CorsPolicy)))
+	errs = appendErrors(errs, withField("fault", validateHTTPFaultInjection(http.Fault)))
 
-	for _, match := range http.Match {
+	for i, match := range http.Match {
 		if match != nil {
+			path := indexed("match", i)
 			for name, header := range match.Headers {
 				if header == nil {
-					errs = appendErrors(errs, fmt.Errorf("header match %v cannot be null", name))
+					errs = appendErrors(errs, withField(path+".headers", fmt.Errorf("header match %v cannot be null", name)))
 				}
-				errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+				errs = appendErrors(errs, withField(path+".headers", ValidateHTTPHeaderName(name)))
 			}
 
 			if match.Port != 0 {
-				errs = appendErrors(errs, ValidatePort(int(match.Port)))
+				errs = appendErrors(errs, withField(path+".port", ValidatePort(int(match.Port))))
 			}
-			errs = appendErrors(errs, labels.Instance(match.SourceLabels).Validate())
-			errs = appendErrors(errs, validateGatewayNames(match.Gateways))
+			errs = appendErrors(errs, withField(path+".sourceLabels", labels.Instance(match.SourceLabels).Validate()))
+			errs = appendErrors(errs, withField(path+".gateways", validateGatewayNames(match.Gateways)))
 		}
 	}
 
 	if http.MirrorPercent != nil {
 		if value := http.MirrorPercent.GetValue(); value > 100 {
-			errs = appendErrors(errs, fmt.Errorf("mirror_percent must have a max value of 100 (it has %d)", value))
+			errs = appendErrors(errs, withField("mirrorPercent",
+				fmt.Errorf("mirror_percent must have a max value of 100 (it has %d)", value)))
 		}
 	}
 
-	errs = appendErrors(errs, validateDestination(http.Mirror))
-	errs = appendErrors(errs, validateHTTPRedirect(http.Redirect))
-	errs = appendErrors(errs, validateHTTPRetry(http.Retries))
-	errs = appendErrors(errs, validateHTTPRewrite(http.Rewrite))
+	errs = appendErrors(errs, withField("mirror", validateDestination(http.Mirror)))
+	errs = appendErrors(errs, withField("redirect", validateHTTPRedirect(http.Redirect)))
+	errs = appendErrors(errs, withField("retries", validateHTTPRetry(http.Retries)))
+	errs = appendErrors(errs, withField("rewrite", validateHTTPRewrite(http.Rewrite)))
 	errs = appendErrors(errs, validateHTTPRouteDestinations(http.Route))
 	if http.Timeout != nil {
-		errs = appendErrors(errs, ValidateDurationGogo(http.Timeout))
+		errs = appendErrors(errs, withField("timeout", ValidateDurationGogo(http.Timeout)))
 	}
 
 	return
@@ -2040,67 +2190,73 @@ func validateGatewayNames(gatewayNames []string) (errs error) {
 
 func validateHTTPRouteDestinations(weights []*networking.HTTPRouteDestination) (errs error) {
 	var totalWeight int32
-	for _, weight := range weights {
-		if weight.Destination == nil {
-			errs = multierror.Append(errs, errors.New("destination is required"))
-		}
+	for i, weight := range weights {
+		errs = appendErrors(errs, withField(indexed("route", i), validateHTTPRouteDestination(weight)))
+		totalWeight += weight.Weight
+	}
+	if len(weights) > 1 && totalWeight != 100 {
+		errs = appendErrors(errs, withField("route", fmt.Errorf("total destination weight %v != 100", totalWeight)))
+	}
+	return
+}
 
-		// deprecated
-		for name := range weight.AppendRequestHeaders {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for name := range weight.AppendResponseHeaders {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for _, name := range weight.RemoveRequestHeaders {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for _, name := range weight.RemoveResponseHeaders {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
+func validateHTTPRouteDestination(weight *networking.HTTPRouteDestination) (errs error) {
+	if weight.Destination == nil {
+		errs = appendErrors(errs, withField("destination", errors.New("destination is required")))
+	}
 
-		// header manipulations
-		for name := range weight.Headers.GetRequest().GetAdd() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for name := range weight.Headers.GetRequest().GetSet() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for _, name := range weight.Headers.GetRequest().GetRemove() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for name := range weight.Headers.GetResponse().GetAdd() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for name := range weight.Headers.GetResponse().GetSet() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
-		for _, name := range weight.Headers.GetResponse().GetRemove() {
-			errs = appendErrors(errs, ValidateHTTPHeaderName(name))
-		}
+	// deprecated
+	for name := range weight.AppendRequestHeaders {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for name := range weight.AppendResponseHeaders {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for _, name := range weight.RemoveRequestHeaders {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for _, name := range weight.RemoveResponseHeaders {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
 
-		errs = appendErrors(errs, validateDestination(weight.Destination))
-		errs = appendErrors(errs, ValidatePercent(weight.Weight))
-		totalWeight += weight.Weight
+	// header manipulations
+	for name := range weight.Headers.GetRequest().GetAdd() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
 	}
-	if len(weights) > 1 && totalWeight != 100 {
-		errs = appendErrors(errs, fmt.Errorf("total destination weight %v != 100", totalWeight))
+	for name := range weight.Headers.GetRequest().GetSet() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for _, name := range weight.Headers.GetRequest().GetRemove() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for name := range weight.Headers.GetResponse().GetAdd() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for name := range weight.Headers.GetResponse().GetSet() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
+	}
+	for _, name := range weight.Headers.GetResponse().GetRemove() {
+		errs = appendErrors(errs, ValidateHTTPHeaderName(name))
 	}
+
+	errs = appendErrors(errs, withField("destination", validateDestination(weight.Destination)))
+	errs = appendErrors(errs, withField("weight", ValidatePercent(weight.Weight)))
 	return
 }
 
 func validateRouteDestinations(weights []*networking.RouteDestination) (errs error) {
 	var totalWeight int32
-	for _, weight := range weights {
+	for i, weight := range weights {
+		path := indexed("route", i)
 		if weight.Destination == nil {
-			errs = multierror.Append(errs, errors.New("destination is required"))
+			errs = appendErrors(errs, withField(path+".destination", errors.New("destination is required")))
 		}
-		errs = appendErrors(errs, validateDestination(weight.Destination))
-		errs = appendErrors(errs, ValidatePercent(weight.Weight))
+		errs = appendErrors(errs, withField(path+".destination", validateDestination(weight.Destination)))
+		errs = appendErrors(errs, withField(path+".weight", ValidatePercent(weight.Weight)))
 		totalWeight += weight.Weight
 	}
 	if len(weights) > 1 && totalWeight != 100 {
-		errs = appendErrors(errs, fmt.Errorf("total destination weight %v != 100", totalWeight))
+		errs = appendErrors(errs, withField("route", fmt.Errorf("total destination weight %v != 100", totalWeight)))
 	}
 	return
 }
@@ -2346,21 +2502,21 @@ func ValidateServiceEntry(_, _ string, config proto.Message) (errs error) {
 	}
 
 	if len(serviceEntry.Hosts) == 0 {
-		errs = appendErrors(errs, fmt.Errorf("service entry must have at least one host"))
+		errs = appendErrors(errs, withField("hosts", fmt.Errorf("service entry must have at least one host")))
 	}
-	for _, hostname := range serviceEntry.Hosts {
+	for i, hostname := range serviceEntry.Hosts {
 		// Full wildcard is not allowed in the service entry.
 		if hostname == "*" {
-			errs = appendErrors(errs, fmt.Errorf("invalid host %s", hostname))
+			errs = appendErrors(errs, withField(indexed("hosts", i), fmt.Errorf("invalid host %s", hostname)))
 		} else {
-			errs = appendErrors(errs, ValidateWildcardDomain(hostname))
+			errs = appendErrors(errs, withField(indexed("hosts", i), ValidateWildcardDomain(hostname)))
 		}
 	}
 
 	cidrFound := false
-	for _, address := range serviceEntry.Addresses {
+	for i, address := range serviceEntry.Addresses {
 		cidrFound = cidrFound || strings.Contains(address, "/")
-		errs = appendErrors(errs, ValidateIPSubnet(address))
+		errs = appendErrors(errs, withField(indexed("addresses", i), ValidateIPSubnet(address)))
 	}
 
 	if cidrFound {
@@ -2371,13 +2527,15 @@ func ValidateServiceEntry(_, _ string, config proto.Message) (errs error) {
 
 	servicePortNumbers := make(map[uint32]bool)
 	servicePorts := make(map[string]bool, len(serviceEntry.Ports))
-	for _, port := range serviceEntry.Ports {
+	for i, port := range serviceEntry.Ports {
 		if servicePorts[port.Name] {
-			errs = appendErrors(errs, fmt.Errorf("service entry port name %q already defined", port.Name))
+			errs = appendErrors(errs, withField(indexed("ports", i)+".name",
+				fmt.Errorf("service entry port name %q already defined", port.Name)))
 		}
 		servicePorts[port.Name] = true
 		if servicePortNumbers[port.Number] {
-			errs = appendErrors(errs, fmt.Errorf("service entry port %d already defined", port.Number))
+			errs = appendErrors(errs, withField(indexed("ports", i)+".number",
+				fmt.Errorf("service entry port %d already defined", port.Number)))
 		}
 		servicePortNumbers[port.Number] = true
 	}
@@ -2388,10 +2546,8 @@ func ValidateServiceEntry(_, _ string, config proto.Message) (errs error) {
 			errs = appendErrors(errs, fmt.Errorf("no endpoints should be provided for resolution type none"))
 		}
 	case networking.ServiceEntry_STATIC:
-		if len(serviceEntry.Endpoints) == 0 {
-			errs = appendErrors(errs,
-				fmt.Errorf("endpoints must be provided if service entry resolution mode is static"))
-		}
+		// The endpoints may be omitted, in which case they are the WorkloadEntries selected through
+		// the workload selector annotation.
 
 		unixEndpoint := false
 		for _, endpoint := range serviceEntry.Endpoints {
@@ -2419,10 +2575,19 @@ func ValidateServiceEntry(_, _ string, config proto.Message) (errs error) {
 		}
 	case networking.ServiceEntry_DNS:
 		if len(serviceEntry.Endpoints) == 0 {
+			// wildcard hosts are resolved from the Host header of the requests, hence only for HTTP ports
+			httpOnly := true
+			for _, port := range serviceEntry.Ports {
+				httpOnly = httpOnly && protocol.Parse(port.Protocol).IsHTTP()
+			}
 			for _, hostname := range serviceEntry.Hosts {
+				if httpOnly && host.Name(hostname).IsWildCarded() {
+					continue
+				}
 				if err := ValidateFQDN(hostname); err != nil {
 					errs = appendErrors(errs,
-						fmt.Errorf("hosts must be FQDN if no endpoints are provided for resolution mode DNS"))
+						fmt.Errorf("hosts must be FQDN if no endpoints are provided for resolution mode DNS, "+
+							"or wildcard if all the ports are HTTP"))
 				}
 			}
 		}
@@ -2473,14 +2638,105 @@ func ValidateServiceEntry(_, _ string, config proto.Message) (errs error) {
 		}
 	}
 
-	for _, port := range serviceEntry.Ports {
+	for i, port := range serviceEntry.Ports {
 		errs = appendErrors(errs,
-			validatePortName(port.Name),
-			validateProtocol(port.Protocol),
-			ValidatePort(int(port.Number)))
+			withField(indexed("ports", i)+".name", validatePortName(port.Name)),
+			withField(indexed("ports", i)+".protocol", validateProtocol(port.Protocol)),
+			withField(indexed("ports", i)+".number", ValidatePort(int(port.Number))))
+	}
+
+	errs = appendErrors(errs, withField("exportTo", validateExportTo(serviceEntry.ExportTo)))
+	return
+}
+
+// ValidateWorkloadEntry validates a workload entry.
+func ValidateWorkloadEntry(_, _ string, config proto.Message) (errs error) {
+	we, ok := config.(*workload.WorkloadEntry)
+	if !ok {
+		return fmt.Errorf("cannot cast to workload entry")
 	}
 
-	errs = appendErrors(errs, validateExportTo(serviceEntry.ExportTo))
+	addr := we.Address
+	if addr == "" {
+		return fmt.Errorf("address must be set")
+	}
+	if strings.HasPrefix(addr, UnixAddressPrefix) {
+		errs = appendErrors(errs, ValidateUnixAddress(strings.TrimPrefix(addr, UnixAddressPrefix)))
+		if len(we.Ports) != 0 {
+			errs = appendErrors(errs, fmt.Errorf("unix socket address %q must not have ports", addr))
+		}
+	} else if net.ParseIP(addr) == nil {
+		if err := ValidateFQDN(addr); err != nil {
+			errs = appendErrors(errs, fmt.Errorf("address %q is not a valid FQDN or an IP address", addr))
+		}
+	}
+
+	errs = appendErrors(errs, labels.Instance(we.Labels).Validate())
+	for name, port := range we.Ports {
+		errs = appendErrors(errs,
+			validatePortName(name),
+			ValidatePort(int(port)))
+	}
+	if we.Probe != nil {
+		errs = appendErrors(errs, ValidateReadinessProbe(we.Probe))
+	}
+	return
+}
+
+// ValidateWorkloadGroup validates a workload group.
+func ValidateWorkloadGroup(_, _ string, config proto.Message) (errs error) {
+	wg, ok := config.(*workload.WorkloadGroup)
+	if !ok {
+		return fmt.Errorf("cannot cast to workload group")
+	}
+
+	if wg.Metadata != nil {
+		errs = appendErrors(errs, labels.Instance(wg.Metadata.Labels).Validate())
+	}
+	if wg.Template == nil {
+		return appendErrors(errs, fmt.Errorf("template must be set"))
+	}
+	if wg.Template.Address != "" {
+		errs = appendErrors(errs, fmt.Errorf("template address must not be set, it is set from each workload"))
+	}
+	errs = appendErrors(errs, labels.Instance(wg.Template.Labels).Validate())
+	for name, port := range wg.Template.Ports {
+		errs = appendErrors(errs,
+			validatePortName(name),
+			ValidatePort(int(port)))
+	}
+	if wg.Template.Probe != nil {
+		errs = appendErrors(errs, ValidateReadinessProbe(wg.Template.Probe))
+	}
+	if wg.Probe != nil {
+		errs = appendErrors(errs, ValidateReadinessProbe(wg.Probe))
+	}
+	return
+}
+
+// ValidateReadinessProbe validates the readiness probe of a workload.
+func ValidateReadinessProbe(probe *workload.ReadinessProbe) (errs error) {
+	if probe.InitialDelaySeconds < 0 || probe.TimeoutSeconds < 0 || probe.PeriodSeconds < 0 ||
+		probe.SuccessThreshold < 0 || probe.FailureThreshold < 0 {
+		errs = appendErrors(errs, fmt.Errorf("probe: durations and thresholds must not be negative"))
+	}
+	switch m := probe.HealthCheckMethod.(type) {
+	case *workload.ReadinessProbe_HttpGet:
+		if m.HttpGet == nil {
+			return appendErrors(errs, fmt.Errorf("probe: httpGet must be set"))
+		}
+		errs = appendErrors(errs, ValidatePort(int(m.HttpGet.Port)))
+		if m.HttpGet.Scheme != "" && m.HttpGet.Scheme != "HTTP" && m.HttpGet.Scheme != "HTTPS" {
+			errs = appendErrors(errs, fmt.Errorf("probe: httpGet scheme must be HTTP or HTTPS, got %q", m.HttpGet.Scheme))
+		}
+	case *workload.ReadinessProbe_TcpSocket:
+		if m.TcpSocket == nil {
+			return appendErrors(errs, fmt.Errorf("probe: tcpSocket must be set"))
+		}
+		errs = appendErrors(errs, ValidatePort(int(m.TcpSocket.Port)))
+	default:
+		errs = appendErrors(errs, fmt.Errorf("probe: one of httpGet or tcpSocket must be set"))
+	}
 	return
 }
 
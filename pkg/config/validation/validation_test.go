@@ -33,7 +33,10 @@ import (
 	authz "istio.io/api/security/v1beta1"
 	api "istio.io/api/type/v1beta1"
 
+	workload "istio.io/istio/pkg/config/apis/networking/v1alpha3"
+	telemetry "istio.io/istio/pkg/config/apis/telemetry/v1alpha1"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/virtualservice"
 )
 
 const (
@@ -318,6 +321,37 @@ func TestValidateMeshConfig(t *testing.T) {
 	}
 }
 
+func TestValidateAccessLogFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		encoding meshconfig.MeshConfig_AccessLogEncoding
+		format   string
+		valid    bool
+	}{
+		{name: "default text", encoding: meshconfig.MeshConfig_TEXT, valid: true},
+		{name: "default json", encoding: meshconfig.MeshConfig_JSON, valid: true},
+		{name: "custom text", encoding: meshconfig.MeshConfig_TEXT, format: "%START_TIME% %RESPONSE_CODE%", valid: true},
+		{
+			name:     "custom json",
+			encoding: meshconfig.MeshConfig_JSON,
+			format:   `{"trace_id": "%REQ(X-B3-TRACEID)%", "route_name": "%ROUTE_NAME%", "upstream_host": "%UPSTREAM_HOST%"}`,
+			valid:    true,
+		},
+		{name: "malformed json", encoding: meshconfig.MeshConfig_JSON, format: `{"code": "%RESPONSE_CODE%"`},
+		{name: "non string value", encoding: meshconfig.MeshConfig_JSON, format: `{"code": 200}`},
+		{name: "not an object", encoding: meshconfig.MeshConfig_JSON, format: `["%RESPONSE_CODE%"]`},
+		{name: "no fields", encoding: meshconfig.MeshConfig_JSON, format: `{}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := validateAccessLogFormat(c.encoding, c.format); (got == nil) != c.valid {
+				t.Errorf("got valid=%v but wanted valid=%v: %v", got == nil, c.valid, got)
+			}
+		})
+	}
+}
+
 func TestValidateProxyConfig(t *testing.T) {
 	valid := &meshconfig.ProxyConfig{
 		ConfigPath:             "/etc/istio/proxy",
@@ -1188,6 +1222,20 @@ func TestValidateServer(t *testing.T) {
 				Port:  &networking.Port{Number: 7, Name: "http", Protocol: "http"},
 			},
 			""},
+		{"happy bind",
+			&networking.Server{
+				Hosts: []string{"foo.bar.com"},
+				Port:  &networking.Port{Number: 7, Name: "http", Protocol: "http"},
+				Bind:  "10.0.0.1",
+			},
+			""},
+		{"invalid bind",
+			&networking.Server{
+				Hosts: []string{"foo.bar.com"},
+				Port:  &networking.Port{Number: 7, Name: "http", Protocol: "http"},
+				Bind:  "unix:///var/run/gateway.sock",
+			},
+			"bind"},
 		{"happy ns/name",
 			&networking.Server{
 				Hosts: []string{"ns1/foo.bar.com"},
@@ -2334,6 +2382,30 @@ func TestValidateRouteDestination(t *testing.T) {
 }
 
 // TODO: add TCP test cases once it is implemented
+func TestValidateAnnotations(t *testing.T) {
+	cases := []struct {
+		name        string
+		config      proto.Message
+		annotations map[string]string
+		valid       bool
+	}{
+		{"virtual service without annotations", &networking.VirtualService{}, nil, true},
+		{"valid regex rewrite", &networking.VirtualService{},
+			map[string]string{virtualservice.URIRegexRewriteAnnotation: `{"v1": {"pattern": "^/v1"}}`}, true},
+		{"invalid regex rewrite", &networking.VirtualService{},
+			map[string]string{virtualservice.URIRegexRewriteAnnotation: `{"v1": {"pattern": "("}}`}, false},
+		{"annotation of another config", &networking.DestinationRule{},
+			map[string]string{virtualservice.URIRegexRewriteAnnotation: `{"v1": {"pattern": "("}}`}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ValidateAnnotations(c.config, c.annotations); (got == nil) != c.valid {
+				t.Errorf("got error %v, want valid %v", got, c.valid)
+			}
+		})
+	}
+}
+
 func TestValidateVirtualService(t *testing.T) {
 	testCases := []struct {
 		name  string
@@ -3368,13 +3440,24 @@ func TestValidateServiceEntries(t *testing.T) {
 		},
 			valid: false},
 
-		{name: "discovery type DNS, non-FQDN host", in: networking.ServiceEntry{
+		{name: "discovery type DNS, wildcard host with HTTP ports", in: networking.ServiceEntry{
 			Hosts: []string{"*.google.com"},
 			Ports: []*networking.Port{
 				{Number: 80, Protocol: "http", Name: "http-valid1"},
 				{Number: 8080, Protocol: "http", Name: "http-valid2"},
 			},
 
+			Resolution: networking.ServiceEntry_DNS,
+		},
+			valid: true},
+
+		{name: "discovery type DNS, wildcard host with TLS port", in: networking.ServiceEntry{
+			Hosts: []string{"*.google.com"},
+			Ports: []*networking.Port{
+				{Number: 80, Protocol: "http", Name: "http-valid1"},
+				{Number: 443, Protocol: "tls", Name: "tls-valid1"},
+			},
+
 			Resolution: networking.ServiceEntry_DNS,
 		},
 			valid: false},
@@ -3481,7 +3564,7 @@ func TestValidateServiceEntries(t *testing.T) {
 		},
 			valid: false},
 
-		{name: "discovery type static, missing endpoints", in: networking.ServiceEntry{
+		{name: "discovery type static, endpoints from workload entries", in: networking.ServiceEntry{
 			Hosts:     []string{"google.com"},
 			Addresses: []string{"172.1.2.16"},
 			Ports: []*networking.Port{
@@ -3490,7 +3573,7 @@ func TestValidateServiceEntries(t *testing.T) {
 			},
 			Resolution: networking.ServiceEntry_STATIC,
 		},
-			valid: false},
+			valid: true},
 
 		{name: "discovery type static, bad endpoint port name", in: networking.ServiceEntry{
 			Hosts:     []string{"google.com"},
@@ -3983,6 +4066,350 @@ func TestValidateAuthorizationPolicy(t *testing.T) {
 	}
 }
 
+func TestValidateTelemetry(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    proto.Message
+		valid bool
+	}{
+		{
+			name: "good",
+			in: &telemetry.Telemetry{
+				Selector: &api.WorkloadSelector{
+					MatchLabels: map[string]string{"app": "reviews"},
+				},
+				Tracing: &telemetry.Tracing{
+					Providers:                []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoy}},
+					RandomSamplingPercentage: &types.DoubleValue{Value: 100},
+					RequestHeadersForTags:    []string{"x-tenant-id"},
+					CustomTags: []*telemetry.CustomTag{
+						{Tag: "tenant", Literal: "acme"},
+						{Tag: "build", Environment: "BUILD_ID", DefaultValue: "unknown"},
+					},
+				},
+				Metrics: &telemetry.Metrics{
+					Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderMixer}},
+				},
+				AccessLogging: &telemetry.AccessLogging{
+					Disabled: &types.BoolValue{Value: true},
+				},
+			},
+			valid: true,
+		},
+		{
+			name:  "empty",
+			in:    &telemetry.Telemetry{},
+			valid: true,
+		},
+		{
+			name: "selector with empty value",
+			in: &telemetry.Telemetry{
+				Selector: &api.WorkloadSelector{
+					MatchLabels: map[string]string{"app": ""},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "empty tag header",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					RequestHeadersForTags: []string{"x-tenant-id", ""},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "custom tag without name",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					CustomTags: []*telemetry.CustomTag{{Literal: "acme"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "custom tag without value",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					CustomTags: []*telemetry.CustomTag{{Tag: "tenant"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "custom tag with literal and environment",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					CustomTags: []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme", Environment: "TENANT"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "custom tag literal with default value",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					CustomTags: []*telemetry.CustomTag{{Tag: "tenant", Literal: "acme", DefaultValue: "none"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "sampling out of range",
+			in: &telemetry.Telemetry{
+				Tracing: &telemetry.Tracing{
+					RandomSamplingPercentage: &types.DoubleValue{Value: 101},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "access log service",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Providers: []*telemetry.ProviderRef{
+						{Name: constants.TelemetryProviderEnvoy},
+						{Name: constants.TelemetryProviderEnvoyALS},
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "access log service for metrics",
+			in: &telemetry.Telemetry{
+				Metrics: &telemetry.Metrics{
+					Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderEnvoyALS}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "unsupported provider",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Providers: []*telemetry.ProviderRef{{Name: constants.TelemetryProviderMixer}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "empty provider name",
+			in: &telemetry.Telemetry{
+				Metrics: &telemetry.Metrics{
+					Providers: []*telemetry.ProviderRef{{}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "access log filter",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Filter: &telemetry.AccessLogFilter{
+						MinStatusCode: 400,
+						MinDuration:   &types.Duration{Seconds: 1},
+						ResponseFlags: []string{"UH", "URX"},
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			name: "access log filter status code out of range",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Filter: &telemetry.AccessLogFilter{MinStatusCode: 99},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "access log filter duration below 1ms",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Filter: &telemetry.AccessLogFilter{MinDuration: &types.Duration{Nanos: 1000}},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "access log filter unknown response flag",
+			in: &telemetry.Telemetry{
+				AccessLogging: &telemetry.AccessLogging{
+					Filter: &telemetry.AccessLogFilter{ResponseFlags: []string{"XX"}},
+				},
+			},
+			valid: false,
+		},
+	}
+
+	for _, c := range cases {
+		if got := ValidateTelemetry("", "", c.in); (got == nil) != c.valid {
+			t.Errorf("ValidateTelemetry(%v): got(%v) != want(%v): %v\n", c.name, got == nil, c.valid, got)
+		}
+	}
+}
+
+func TestValidateWorkloadEntry(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    proto.Message
+		valid bool
+	}{
+		{
+			name: "good",
+			in: &workload.WorkloadEntry{
+				Address:        "1.1.1.1",
+				Ports:          map[string]uint32{"http": 8080},
+				Labels:         map[string]string{"app": "details"},
+				ServiceAccount: "details",
+				Probe: &workload.ReadinessProbe{
+					PeriodSeconds: 5,
+					HealthCheckMethod: &workload.ReadinessProbe_HttpGet{
+						HttpGet: &workload.HTTPHealthCheckConfig{Path: "/healthz", Port: 8080},
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			name:  "fqdn",
+			in:    &workload.WorkloadEntry{Address: "vm.example.com"},
+			valid: true,
+		},
+		{
+			name:  "unix socket",
+			in:    &workload.WorkloadEntry{Address: "unix:///var/run/app.sock"},
+			valid: true,
+		},
+		{
+			name:  "unix socket with ports",
+			in:    &workload.WorkloadEntry{Address: "unix:///var/run/app.sock", Ports: map[string]uint32{"http": 80}},
+			valid: false,
+		},
+		{
+			name:  "missing address",
+			in:    &workload.WorkloadEntry{},
+			valid: false,
+		},
+		{
+			name:  "bad address",
+			in:    &workload.WorkloadEntry{Address: "not an address"},
+			valid: false,
+		},
+		{
+			name:  "bad port",
+			in:    &workload.WorkloadEntry{Address: "1.1.1.1", Ports: map[string]uint32{"http": 0}},
+			valid: false,
+		},
+		{
+			name:  "bad label",
+			in:    &workload.WorkloadEntry{Address: "1.1.1.1", Labels: map[string]string{"@": "x"}},
+			valid: false,
+		},
+		{
+			name: "probe without method",
+			in: &workload.WorkloadEntry{
+				Address: "1.1.1.1",
+				Probe:   &workload.ReadinessProbe{PeriodSeconds: 5},
+			},
+			valid: false,
+		},
+		{
+			name: "probe with negative threshold",
+			in: &workload.WorkloadEntry{
+				Address: "1.1.1.1",
+				Probe: &workload.ReadinessProbe{
+					FailureThreshold: -1,
+					HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+						TcpSocket: &workload.TCPHealthCheckConfig{Port: 8080},
+					},
+				},
+			},
+			valid: false,
+		},
+		{
+			name: "probe with bad scheme",
+			in: &workload.WorkloadEntry{
+				Address: "1.1.1.1",
+				Probe: &workload.ReadinessProbe{
+					HealthCheckMethod: &workload.ReadinessProbe_HttpGet{
+						HttpGet: &workload.HTTPHealthCheckConfig{Port: 8080, Scheme: "GRPC"},
+					},
+				},
+			},
+			valid: false,
+		},
+	}
+
+	for _, c := range cases {
+		if got := ValidateWorkloadEntry("", "", c.in); (got == nil) != c.valid {
+			t.Errorf("ValidateWorkloadEntry(%v): got(%v) != want(%v): %v\n", c.name, got == nil, c.valid, got)
+		}
+	}
+}
+
+func TestValidateWorkloadGroup(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    proto.Message
+		valid bool
+	}{
+		{
+			name: "good",
+			in: &workload.WorkloadGroup{
+				Metadata: &workload.ObjectMeta{
+					Labels:      map[string]string{"app": "details"},
+					Annotations: map[string]string{"owner": "team-a"},
+				},
+				Template: &workload.WorkloadEntry{
+					Ports:          map[string]uint32{"http": 8080},
+					ServiceAccount: "details",
+				},
+				Probe: &workload.ReadinessProbe{
+					HealthCheckMethod: &workload.ReadinessProbe_TcpSocket{
+						TcpSocket: &workload.TCPHealthCheckConfig{Port: 8080},
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			name:  "missing template",
+			in:    &workload.WorkloadGroup{Metadata: &workload.ObjectMeta{Labels: map[string]string{"app": "details"}}},
+			valid: false,
+		},
+		{
+			name:  "template with address",
+			in:    &workload.WorkloadGroup{Template: &workload.WorkloadEntry{Address: "1.1.1.1"}},
+			valid: false,
+		},
+		{
+			name:  "bad metadata label",
+			in:    &workload.WorkloadGroup{Metadata: &workload.ObjectMeta{Labels: map[string]string{"@": "x"}}, Template: &workload.WorkloadEntry{}},
+			valid: false,
+		},
+		{
+			name:  "bad template port",
+			in:    &workload.WorkloadGroup{Template: &workload.WorkloadEntry{Ports: map[string]uint32{"http": 0}}},
+			valid: false,
+		},
+		{
+			name:  "probe without method",
+			in:    &workload.WorkloadGroup{Template: &workload.WorkloadEntry{}, Probe: &workload.ReadinessProbe{PeriodSeconds: 5}},
+			valid: false,
+		},
+	}
+
+	for _, c := range cases {
+		if got := ValidateWorkloadGroup("", "", c.in); (got == nil) != c.valid {
+			t.Errorf("ValidateWorkloadGroup(%v): got(%v) != want(%v): %v\n", c.name, got == nil, c.valid, got)
+		}
+	}
+}
+
 func TestValidateServiceRole(t *testing.T) {
 	cases := []struct {
 		name         string
@@ -0,0 +1,56 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// URIRegexRewriteAnnotation is the annotation of a virtual service rewriting the path of the requests of its
+// HTTP routes with regexes, e.g. {"v1": {"pattern": "^/v1/(.*)$", "substitution": "/api/\\1"}}. It maps the
+// names of the HTTP routes to their regex rewrite, which replaces the URI prefix rewrite of the route.
+const URIRegexRewriteAnnotation = "networking.istio.io/uri-regex-rewrite"
+
+// URIRegexRewrite rewrites the path of a request, replacing the portions matching the RE2 pattern with the
+// substitution, which may refer to the capture groups of the pattern, e.g. \1.
+type URIRegexRewrite struct {
+	Pattern      string `json:"pattern"`
+	Substitution string `json:"substitution"`
+}
+
+// ParseURIRegexRewrites returns the regex rewrites set by the annotations of a virtual service, by HTTP route
+// name, or nil if the virtual service has no regex rewrite.
+func ParseURIRegexRewrites(annotations map[string]string) (map[string]*URIRegexRewrite, error) {
+	annotation, ok := annotations[URIRegexRewriteAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	rewrites := make(map[string]*URIRegexRewrite)
+	if err := json.Unmarshal([]byte(annotation), &rewrites); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", URIRegexRewriteAnnotation, err)
+	}
+	for name, rewrite := range rewrites {
+		if rewrite == nil || rewrite.Pattern == "" {
+			return nil, fmt.Errorf("invalid %s annotation: empty pattern for HTTP route %q", URIRegexRewriteAnnotation, name)
+		}
+		if _, err := regexp.Compile(rewrite.Pattern); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: invalid pattern %q for HTTP route %q: %v",
+				URIRegexRewriteAnnotation, rewrite.Pattern, name, err)
+		}
+	}
+	return rewrites, nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package virtualservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseURIRegexRewrites(t *testing.T) {
+	cases := []struct {
+		name       string
+		annotation string
+		want       map[string]*URIRegexRewrite
+		wantErr    bool
+	}{
+		{"rewrite", `{"v1": {"pattern": "^/v1/(.*)$", "substitution": "/api/\\1"}}`,
+			map[string]*URIRegexRewrite{"v1": {Pattern: "^/v1/(.*)$", Substitution: "/api/\\1"}}, false},
+		{"empty substitution", `{"v1": {"pattern": "^/v1"}}`,
+			map[string]*URIRegexRewrite{"v1": {Pattern: "^/v1"}}, false},
+		{"invalid json", `{"v1": `, nil, true},
+		{"null rewrite", `{"v1": null}`, nil, true},
+		{"empty pattern", `{"v1": {"substitution": "/api"}}`, nil, true},
+		{"invalid pattern", `{"v1": {"pattern": "^/v1/(.*$"}}`, nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseURIRegexRewrites(map[string]string{URIRegexRewriteAnnotation: c.annotation})
+			if (err != nil) != c.wantErr {
+				t.Fatalf("got error %v, want error %v", err, c.wantErr)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if got, err := ParseURIRegexRewrites(map[string]string{"other": "value"}); got != nil || err != nil {
+		t.Errorf("got %v %v without the annotation, want nil", got, err)
+	}
+}
@@ -31,6 +31,7 @@ import (
 	"istio.io/pkg/log"
 
 	"istio.io/istio/pkg/bootstrap"
+	"istio.io/istio/pkg/config/mesh"
 )
 
 const (
@@ -59,6 +60,11 @@ type ProxyConfig struct {
 	SDSTokenPath        string
 	ControlPlaneAuth    bool
 	DisableReportCalls  bool
+
+	// DiscoveryShard, if set, overrides the discovery address with the replica of the proxy.
+	DiscoveryShard *DiscoveryShard
+
+	OpenTelemetry *mesh.OpenTelemetryTracer
 }
 
 // NewProxy creates an instance of the proxy control commands
@@ -149,10 +155,16 @@ func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 		// there is a custom configuration. Don't write our own config - but keep watching the certs.
 		fname = e.Config.CustomConfigFile
 	} else {
+		proxyConfig := e.Config
+		if e.DiscoveryShard != nil {
+			if address := e.DiscoveryShard.Address(); address != "" {
+				proxyConfig.DiscoveryAddress = address
+			}
+		}
 		out, err := bootstrap.New(bootstrap.Config{
 			Node:                e.Node,
 			DNSRefreshRate:      e.DNSRefreshRate,
-			Proxy:               &e.Config,
+			Proxy:               &proxyConfig,
 			PilotSubjectAltName: e.PilotSubjectAltName,
 			MixerSubjectAltName: e.MixerSubjectAltName,
 			LocalEnv:            os.Environ(),
@@ -164,6 +176,8 @@ func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
 			SDSTokenPath:        e.SDSTokenPath,
 			ControlPlaneAuth:    e.ControlPlaneAuth,
 			DisableReportCalls:  e.DisableReportCalls,
+
+			OpenTelemetry: e.OpenTelemetry,
 		}).CreateFileForEpoch(epoch)
 		if err != nil {
 			log.Errora("Failed to generate bootstrap config: ", err)
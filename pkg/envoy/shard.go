@@ -0,0 +1,120 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// defaultShardRefreshPeriod is the period between two successive resolutions of the discovery replicas.
+	defaultShardRefreshPeriod = 30 * time.Second
+)
+
+// DiscoveryShard picks the discovery server replica of a proxy by consistent hashing of its node ID among the
+// addresses of a headless discovery service. Each replica then serves a stable subset of the proxies and keeps
+// its caches hot for them, and a proxy only moves when its replica leaves or a replica with a higher hash joins.
+type DiscoveryShard struct {
+	node   string
+	host   string
+	port   string
+	lookup func(host string) ([]string, error)
+
+	mutex   sync.RWMutex
+	address string
+}
+
+// NewDiscoveryShard creates the shard of the node among the replicas of the headless service host, serving on
+// the port of the discovery address, and resolves its replica.
+func NewDiscoveryShard(node, host, discoveryAddress string) (*DiscoveryShard, error) {
+	_, port, err := net.SplitHostPort(discoveryAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid discovery address %q: %v", discoveryAddress, err)
+	}
+	s := &DiscoveryShard{
+		node:   node,
+		host:   host,
+		port:   port,
+		lookup: net.LookupHost,
+	}
+	s.resolve()
+	return s, nil
+}
+
+// Address returns the address of the replica of the node, or an empty string if none was resolved yet.
+func (s *DiscoveryShard) Address() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.address
+}
+
+// Run resolves the replicas every refresh period and calls notifyFn when the replica of the node changes.
+// The function does not return until the context is canceled.
+func (s *DiscoveryShard) Run(ctx context.Context, refreshPeriod time.Duration, notifyFn func()) {
+	ticker := time.NewTicker(refreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if s.resolve() {
+				notifyFn()
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// resolve picks the replica of the node among the current addresses of the host, and returns whether it
+// changed. The previous replica is kept if the host cannot be resolved.
+func (s *DiscoveryShard) resolve() bool {
+	addresses, err := s.lookup(s.host)
+	if err != nil || len(addresses) == 0 {
+		log.Warnf("failed to resolve the discovery replicas of %s: %v", s.host, err)
+		return false
+	}
+	address := net.JoinHostPort(pickReplica(s.node, addresses), s.port)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if address == s.address {
+		return false
+	}
+	log.Infof("discovery replica of %s changed from %q to %q", s.node, s.address, address)
+	s.address = address
+	return true
+}
+
+// pickReplica returns the address with the highest rendezvous hash with the node, so that removing an address
+// only moves the nodes of this address, and adding one only moves the nodes it wins.
+func pickReplica(node string, addresses []string) string {
+	var picked string
+	var max uint64
+	for _, address := range addresses {
+		h := sha256.Sum256([]byte(node + "/" + address))
+		if sum := binary.BigEndian.Uint64(h[:8]); picked == "" || sum > max || (sum == max && address < picked) {
+			picked, max = address, sum
+		}
+	}
+	return picked
+}
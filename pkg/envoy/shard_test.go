@@ -0,0 +1,119 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envoy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPickReplica(t *testing.T) {
+	replicas := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"}
+	picked := make(map[string]string)
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		node := fmt.Sprintf("sidecar~10.1.%d.%d~pod-%d.default~default.svc.cluster.local", i/256, i%256, i)
+		picked[node] = pickReplica(node, replicas)
+		counts[picked[node]]++
+
+		// the order of the addresses does not matter
+		if reversed := pickReplica(node, []string{replicas[3], replicas[2], replicas[1], replicas[0]}); reversed != picked[node] {
+			t.Fatalf("%s: got replica %s for the reversed addresses, want %s", node, reversed, picked[node])
+		}
+	}
+	for _, r := range replicas {
+		if counts[r] < 150 {
+			t.Errorf("got %d of 1000 nodes on replica %s, want a balanced distribution: %v", counts[r], r, counts)
+		}
+	}
+
+	// removing a replica only moves its nodes
+	for node, replica := range picked {
+		got := pickReplica(node, replicas[1:])
+		if replica != replicas[0] && got != replica {
+			t.Errorf("%s: moved from %s to %s after removing %s", node, replica, got, replicas[0])
+		}
+	}
+
+	// adding a replica only moves nodes to it
+	for node, replica := range picked {
+		got := pickReplica(node, append(replicas, "10.0.0.5"))
+		if got != replica && got != "10.0.0.5" {
+			t.Errorf("%s: moved from %s to %s after adding a replica", node, replica, got)
+		}
+	}
+}
+
+func TestDiscoveryShard(t *testing.T) {
+	replicas := []string{"10.0.0.1", "10.0.0.2"}
+	var lookupErr error
+	shard := &DiscoveryShard{
+		node: "sidecar~10.1.0.1~pod.default~default.svc.cluster.local",
+		host: "istio-pilot-headless.istio-system",
+		port: "15010",
+		lookup: func(string) ([]string, error) {
+			return replicas, lookupErr
+		},
+	}
+	if !shard.resolve() {
+		t.Fatal("got no replica on the first resolution")
+	}
+	first := shard.Address()
+	if first != "10.0.0.1:15010" && first != "10.0.0.2:15010" {
+		t.Fatalf("got replica %q", first)
+	}
+	if shard.resolve() {
+		t.Error("got a change of replica without change of the replicas")
+	}
+
+	// the replica is kept when the lookup fails
+	lookupErr = fmt.Errorf("no such host")
+	if shard.resolve() || shard.Address() != first {
+		t.Errorf("got replica %q after a failed lookup, want %q", shard.Address(), first)
+	}
+	lookupErr = nil
+
+	// the proxy moves when its replica leaves
+	if first == "10.0.0.1:15010" {
+		replicas = []string{"10.0.0.2"}
+	} else {
+		replicas = []string{"10.0.0.1"}
+	}
+	notified := make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go shard.Run(ctx, 10*time.Millisecond, func() {
+		select {
+		case notified <- struct{}{}:
+		default:
+		}
+	})
+	select {
+	case <-notified:
+	case <-time.After(5 * time.Second):
+		t.Fatal("not notified of the change of replica")
+	}
+	if got, want := shard.Address(), replicas[0]+":15010"; got != want {
+		t.Errorf("got replica %q, want %q", got, want)
+	}
+}
+
+func TestNewDiscoveryShardInvalidAddress(t *testing.T) {
+	if _, err := NewDiscoveryShard("node", "istio-pilot-headless", "istio-pilot"); err == nil {
+		t.Error("expected an error for a discovery address without port")
+	}
+}
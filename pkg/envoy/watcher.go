@@ -41,13 +41,21 @@ type Watcher interface {
 
 type watcher struct {
 	certs   []string
+	shard   *DiscoveryShard
 	updates func(interface{})
 }
 
 // NewWatcher creates a new watcher instance from a proxy agent and a set of monitored certificate file paths
 func NewWatcher(certs []string, updates func(interface{})) Watcher {
+	return NewShardedWatcher(certs, nil, updates)
+}
+
+// NewShardedWatcher creates a new watcher instance which also reloads the proxy when its discovery replica
+// changes, if the shard is not nil.
+func NewShardedWatcher(certs []string, shard *DiscoveryShard, updates func(interface{})) Watcher {
 	return &watcher{
 		certs:   certs,
+		shard:   shard,
 		updates: updates,
 	}
 }
@@ -59,6 +67,11 @@ func (w *watcher) Run(ctx context.Context) {
 	// monitor certificates
 	go watchCerts(ctx, w.certs, watchFileEvents, defaultMinDelay, w.SendConfig)
 
+	// monitor the discovery replica
+	if w.shard != nil {
+		go w.shard.Run(ctx, defaultShardRefreshPeriod, w.SendConfig)
+	}
+
 	<-ctx.Done()
 	log.Info("Watcher has successfully terminated")
 }
@@ -66,6 +79,11 @@ func (w *watcher) Run(ctx context.Context) {
 func (w *watcher) SendConfig() {
 	h := sha256.New()
 	generateCertHash(h, w.certs)
+	if w.shard != nil {
+		if _, err := h.Write([]byte(w.shard.Address())); err != nil {
+			log.Warna(err)
+		}
+	}
 	w.updates(h.Sum(nil))
 }
 
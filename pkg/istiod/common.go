@@ -15,6 +15,7 @@
 package istiod
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -22,6 +23,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 
 	"istio.io/pkg/log"
 
@@ -35,6 +37,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	envoyv2 "istio.io/istio/pilot/pkg/proxy/envoy/v2"
 	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
+	"istio.io/istio/pkg/config/mesh"
 	istiokeepalive "istio.io/istio/pkg/keepalive"
 	"istio.io/pkg/ctrlz"
 	"istio.io/pkg/filewatcher"
@@ -53,6 +56,9 @@ type Server struct {
 	// Mesh config - loaded and watched. Updated by watcher.
 	Mesh *meshconfig.MeshConfig
 
+	// Mesh config fields not part of the MeshConfig API yet, loaded and watched with it.
+	MeshExtensions *mesh.Extensions
+
 	MeshNetworks *meshconfig.MeshNetworks
 
 	ConfigStores []model.ConfigStoreCache
@@ -87,6 +93,11 @@ type Server struct {
 	// for testing.
 	basePort           int32
 	secureGrpcListener net.Listener
+
+	// servingCert is the DNS key and certificate served by the secure gRPC and HTTPS servers,
+	// reloaded from DNSCertDir each time the files are rotated.
+	servingCert      *tls.Certificate
+	servingCertMutex sync.RWMutex
 }
 
 var (
@@ -65,8 +65,9 @@ func InitK8S(is *istiod.Server, clientset kubernetes.Interface, config *rest.Con
 		kubeClient:  clientset,
 		args:        args,
 		ControllerOptions: controller2.Options{
-			DomainSuffix: args.DomainSuffix,
-			TrustDomain:  args.MeshConfig.TrustDomain,
+			DomainSuffix:       args.DomainSuffix,
+			TrustDomain:        args.MeshConfig.TrustDomain,
+			DiscoverySelectors: is.MeshExtensions.GetDiscoverySelectors(),
 		},
 	}
 
@@ -120,6 +121,7 @@ func (s *Controllers) initClusterRegistries(args *istiod.PilotArgs) (err error)
 		s.ControllerOptions.WatchedNamespace,
 		args.DomainSuffix,
 		s.ControllerOptions.ResyncPeriod,
+		s.ControllerOptions.DiscoverySelectors,
 		s.IstioServer.ServiceController,
 		s.IstioServer.EnvoyXdsServer,
 		s.IstioServer.MeshNetworks)
@@ -0,0 +1,294 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"k8s.io/api/admissionregistration/v1beta1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	admissionregistration "k8s.io/client-go/kubernetes/typed/admissionregistration/v1beta1"
+	"k8s.io/client-go/tools/cache"
+
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/pkg/filewatcher"
+	"istio.io/pkg/log"
+)
+
+const (
+	// The interval between two reconciliations after a failed one.
+	webhookRetryInterval = 5 * time.Second
+	// The delay to reconcile after a change of the certificate files.
+	webhookFileDebounce = 100 * time.Millisecond
+)
+
+// WebhookReconcilerArgs configures a WebhookReconciler.
+type WebhookReconcilerArgs struct {
+	// CACertFile is the root certificate that the API server uses to verify the webhooks.
+	CACertFile string
+	// CertFile is the certificate chain served by the webhooks.
+	CertFile string
+	// MutatingWebhookConfigName is the name of the injection webhook configuration. Empty to skip it.
+	MutatingWebhookConfigName string
+	// ValidatingWebhookConfigName is the name of the validation webhook configuration. Empty to skip it.
+	ValidatingWebhookConfigName string
+	// ResyncPeriod is the interval at which the configurations are reconciled even when nothing changed.
+	ResyncPeriod time.Duration
+}
+
+// WebhookReconciler continuously reconciles the caBundle and the failurePolicy of the webhooks in the
+// injection and validation webhook configurations with the current root certificate.
+//
+// The caBundle of each webhook is set to the content of CACertFile. The failurePolicy is Fail when
+// the certificate served by the webhooks is trusted by that root certificate, and Ignore otherwise: a
+// webhook the API server cannot reach over TLS would reject every admission, e.g. no pod would be
+// scheduled, until the certificates are fixed.
+type WebhookReconciler struct {
+	client  admissionregistration.AdmissionregistrationV1beta1Interface
+	args    WebhookReconcilerArgs
+	watcher filewatcher.FileWatcher
+	queue   chan struct{}
+}
+
+// NewWebhookReconciler returns a pointer to a newly constructed WebhookReconciler instance.
+func NewWebhookReconciler(client admissionregistration.AdmissionregistrationV1beta1Interface,
+	args WebhookReconcilerArgs) *WebhookReconciler {
+	return &WebhookReconciler{
+		client:  client,
+		args:    args,
+		watcher: filewatcher.NewWatcher(),
+		queue:   make(chan struct{}, 1),
+	}
+}
+
+// Run reconciles the webhook configurations each time they, the root certificate or the served
+// certificate change, until stop is closed.
+func (r *WebhookReconciler) Run(stop <-chan struct{}) {
+	defer r.watcher.Close() // nolint: errcheck
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { r.enqueue() },
+		UpdateFunc: func(interface{}, interface{}) { r.enqueue() },
+		DeleteFunc: func(interface{}) { r.enqueue() },
+	}
+	if name := r.args.MutatingWebhookConfigName; name != "" {
+		client := r.client.MutatingWebhookConfigurations()
+		_, informer := cache.NewInformer(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+				return client.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+				return client.Watch(options)
+			},
+		}, &v1beta1.MutatingWebhookConfiguration{}, r.args.ResyncPeriod, handler)
+		go informer.Run(stop)
+	}
+	if name := r.args.ValidatingWebhookConfigName; name != "" {
+		client := r.client.ValidatingWebhookConfigurations()
+		_, informer := cache.NewInformer(&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+				return client.List(options)
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+				return client.Watch(options)
+			},
+		}, &v1beta1.ValidatingWebhookConfiguration{}, r.args.ResyncPeriod, handler)
+		go informer.Run(stop)
+	}
+
+	fileChanged := make(chan struct{}, 1)
+	for _, file := range []string{r.args.CACertFile, r.args.CertFile} {
+		if err := r.watcher.Add(file); err != nil {
+			log.Warnf("could not watch %s, webhook configurations will only be reconciled on resync: %v", file, err)
+			continue
+		}
+		events := r.watcher.Events(file)
+		go func() {
+			for {
+				select {
+				case <-stop:
+					return
+				case <-events:
+					select {
+					case fileChanged <- struct{}{}:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	r.enqueue()
+	var retryC, debounceC <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case <-fileChanged:
+			// Use a timer to debounce the writes of the certificates
+			if debounceC == nil {
+				debounceC = time.After(webhookFileDebounce)
+			}
+			continue
+		case <-debounceC:
+			debounceC = nil
+		case <-retryC:
+		case <-r.queue:
+		}
+
+		if err := r.reconcile(); err != nil {
+			log.Errorf("webhook configurations reconciliation failed, retrying in %v: %v", webhookRetryInterval, err)
+			retryC = time.After(webhookRetryInterval)
+		} else {
+			retryC = nil
+		}
+	}
+}
+
+func (r *WebhookReconciler) enqueue() {
+	select {
+	case r.queue <- struct{}{}:
+	default:
+		// A reconciliation is already pending.
+	}
+}
+
+// reconcile sets the desired caBundle and failurePolicy on the webhook configurations.
+func (r *WebhookReconciler) reconcile() error {
+	caBundle, err := ioutil.ReadFile(r.args.CACertFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the root certificate %s: %v", r.args.CACertFile, err)
+	}
+	failurePolicy := v1beta1.Fail
+	if err := r.verifyServedCert(caBundle); err != nil {
+		log.Warnf("the webhook certificate %s is not trusted by the root certificate %s, "+
+			"failures of the webhooks are ignored: %v", r.args.CertFile, r.args.CACertFile, err)
+		failurePolicy = v1beta1.Ignore
+	}
+
+	var errs *multierror.Error
+	if r.args.MutatingWebhookConfigName != "" {
+		errs = multierror.Append(errs, r.reconcileMutatingWebhookConfig(caBundle, failurePolicy))
+	}
+	if r.args.ValidatingWebhookConfigName != "" {
+		errs = multierror.Append(errs, r.reconcileValidatingWebhookConfig(caBundle, failurePolicy))
+	}
+	return errs.ErrorOrNil()
+}
+
+func (r *WebhookReconciler) reconcileMutatingWebhookConfig(caBundle []byte, failurePolicy v1beta1.FailurePolicyType) error {
+	client := r.client.MutatingWebhookConfigurations()
+	current, err := client.Get(r.args.MutatingWebhookConfigName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Debugf("mutatingwebhookconfiguration %s not found, skipping", r.args.MutatingWebhookConfigName)
+			return nil
+		}
+		return err
+	}
+	updated := current.DeepCopy()
+	changed := false
+	for i := range updated.Webhooks {
+		changed = reconcileWebhook(&updated.Webhooks[i].ClientConfig, &updated.Webhooks[i].FailurePolicy,
+			caBundle, failurePolicy) || changed
+	}
+	if !changed {
+		return nil
+	}
+	if _, err := client.Update(updated); err != nil {
+		return err
+	}
+	log.Infof("mutatingwebhookconfiguration %s updated, failurePolicy %s", updated.Name, failurePolicy)
+	return nil
+}
+
+func (r *WebhookReconciler) reconcileValidatingWebhookConfig(caBundle []byte, failurePolicy v1beta1.FailurePolicyType) error {
+	client := r.client.ValidatingWebhookConfigurations()
+	current, err := client.Get(r.args.ValidatingWebhookConfigName, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			log.Debugf("validatingwebhookconfiguration %s not found, skipping", r.args.ValidatingWebhookConfigName)
+			return nil
+		}
+		return err
+	}
+	updated := current.DeepCopy()
+	changed := false
+	for i := range updated.Webhooks {
+		changed = reconcileWebhook(&updated.Webhooks[i].ClientConfig, &updated.Webhooks[i].FailurePolicy,
+			caBundle, failurePolicy) || changed
+	}
+	if !changed {
+		return nil
+	}
+	if _, err := client.Update(updated); err != nil {
+		return err
+	}
+	log.Infof("validatingwebhookconfiguration %s updated, failurePolicy %s", updated.Name, failurePolicy)
+	return nil
+}
+
+// reconcileWebhook sets the caBundle and the failurePolicy of a webhook, and returns whether they changed.
+func reconcileWebhook(clientConfig *v1beta1.WebhookClientConfig, policy **v1beta1.FailurePolicyType,
+	caBundle []byte, failurePolicy v1beta1.FailurePolicyType) bool {
+	changed := false
+	if !bytes.Equal(clientConfig.CABundle, caBundle) {
+		clientConfig.CABundle = caBundle
+		changed = true
+	}
+	if *policy == nil || **policy != failurePolicy {
+		p := failurePolicy
+		*policy = &p
+		changed = true
+	}
+	return changed
+}
+
+// verifyServedCert verifies that the certificate served by the webhooks is trusted by the caBundle.
+func (r *WebhookReconciler) verifyServedCert(caBundle []byte) error {
+	certChain, err := ioutil.ReadFile(r.args.CertFile)
+	if err != nil {
+		return err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("failed to parse the root certificate")
+	}
+	intermediates := x509.NewCertPool()
+	intermediates.AppendCertsFromPEM(certChain)
+	cert, err := util.ParsePemEncodedCertificate(certChain)
+	if err != nil {
+		return err
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
@@ -0,0 +1,262 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	testInjectionConfig  = "istio-sidecar-injector"
+	testValidationConfig = "istio-galley"
+)
+
+// genTestCA returns a self-signed root certificate, and a certificate signed by it.
+func genTestCA(t *testing.T) (rootCert, cert []byte) {
+	t.Helper()
+	rootCert, rootKey, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         "k8s-ca",
+		TTL:          time.Hour,
+		NotBefore:    time.Now(),
+		IsCA:         true,
+		IsSelfSigned: true,
+		RSAKeySize:   1024,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate the root certificate: %v", err)
+	}
+	signerCert, err := util.ParsePemEncodedCertificate(rootCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signerKey, err := util.ParsePemEncodedKey(rootKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _, err = util.GenCertKeyFromOptions(util.CertOptions{
+		Host:       "istiod.istio-system.svc",
+		TTL:        time.Hour,
+		NotBefore:  time.Now(),
+		SignerCert: signerCert,
+		SignerPriv: signerKey,
+		IsServer:   true,
+		RSAKeySize: 1024,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate the certificate: %v", err)
+	}
+	return rootCert, cert
+}
+
+func writeTestFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func newTestWebhookConfigs(caBundle []byte, policy v1beta1.FailurePolicyType) (*v1beta1.MutatingWebhookConfiguration,
+	*v1beta1.ValidatingWebhookConfiguration) {
+	return &v1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: testInjectionConfig},
+		Webhooks: []v1beta1.MutatingWebhook{{
+			Name:          "sidecar-injector.istio.io",
+			ClientConfig:  v1beta1.WebhookClientConfig{CABundle: caBundle},
+			FailurePolicy: &policy,
+		}},
+	}, &v1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: testValidationConfig},
+		Webhooks: []v1beta1.ValidatingWebhook{{
+			Name:         "pilot.validation.istio.io",
+			ClientConfig: v1beta1.WebhookClientConfig{CABundle: caBundle},
+		}, {
+			Name:          "mixer.validation.istio.io",
+			ClientConfig:  v1beta1.WebhookClientConfig{CABundle: caBundle},
+			FailurePolicy: &policy,
+		}},
+	}
+}
+
+func checkWebhookConfigs(t *testing.T, client *fake.Clientset, caBundle []byte, policy v1beta1.FailurePolicyType) {
+	t.Helper()
+	mutating, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(testInjectionConfig, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range mutating.Webhooks {
+		if !bytes.Equal(w.ClientConfig.CABundle, caBundle) {
+			t.Errorf("webhook %s: unexpected caBundle", w.Name)
+		}
+		if w.FailurePolicy == nil || *w.FailurePolicy != policy {
+			t.Errorf("webhook %s: expect failurePolicy %s, got %v", w.Name, policy, w.FailurePolicy)
+		}
+	}
+	validating, err := client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(testValidationConfig, metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range validating.Webhooks {
+		if !bytes.Equal(w.ClientConfig.CABundle, caBundle) {
+			t.Errorf("webhook %s: unexpected caBundle", w.Name)
+		}
+		if w.FailurePolicy == nil || *w.FailurePolicy != policy {
+			t.Errorf("webhook %s: expect failurePolicy %s, got %v", w.Name, policy, w.FailurePolicy)
+		}
+	}
+}
+
+func TestWebhookReconcilerReconcile(t *testing.T) {
+	rootCert, cert := genTestCA(t)
+	otherRootCert, _ := genTestCA(t)
+
+	testCases := []struct {
+		name           string
+		rootCert       []byte
+		expectedPolicy v1beta1.FailurePolicyType
+	}{
+		{
+			name:           "certificate trusted by the root certificate",
+			rootCert:       rootCert,
+			expectedPolicy: v1beta1.Fail,
+		},
+		{
+			name:           "certificate not trusted by the root certificate",
+			rootCert:       otherRootCert,
+			expectedPolicy: v1beta1.Ignore,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "webhooks")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			mutating, validating := newTestWebhookConfigs([]byte("stale"), v1beta1.Ignore)
+			client := fake.NewSimpleClientset(mutating, validating)
+			r := NewWebhookReconciler(client.AdmissionregistrationV1beta1(), WebhookReconcilerArgs{
+				CACertFile:                  writeTestFile(t, dir, "ca.crt", tc.rootCert),
+				CertFile:                    writeTestFile(t, dir, "cert-chain.pem", cert),
+				MutatingWebhookConfigName:   testInjectionConfig,
+				ValidatingWebhookConfigName: testValidationConfig,
+			})
+			if err := r.reconcile(); err != nil {
+				t.Fatalf("reconcile failed: %v", err)
+			}
+			checkWebhookConfigs(t, client, tc.rootCert, tc.expectedPolicy)
+
+			// A second reconciliation has nothing to update.
+			client.ClearActions()
+			if err := r.reconcile(); err != nil {
+				t.Fatalf("reconcile failed: %v", err)
+			}
+			for _, action := range client.Actions() {
+				if action.GetVerb() != "get" {
+					t.Errorf("unexpected %s of %s", action.GetVerb(), action.GetResource().Resource)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookReconcilerMissingConfig(t *testing.T) {
+	rootCert, cert := genTestCA(t)
+	dir, err := ioutil.TempDir("", "webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewWebhookReconciler(fake.NewSimpleClientset().AdmissionregistrationV1beta1(), WebhookReconcilerArgs{
+		CACertFile:                  writeTestFile(t, dir, "ca.crt", rootCert),
+		CertFile:                    writeTestFile(t, dir, "cert-chain.pem", cert),
+		MutatingWebhookConfigName:   testInjectionConfig,
+		ValidatingWebhookConfigName: testValidationConfig,
+	})
+	if err := r.reconcile(); err != nil {
+		t.Errorf("reconcile of missing configurations failed: %v", err)
+	}
+}
+
+func TestWebhookReconcilerRun(t *testing.T) {
+	rootCert, cert := genTestCA(t)
+	dir, err := ioutil.TempDir("", "webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mutating, validating := newTestWebhookConfigs(rootCert, v1beta1.Fail)
+	client := fake.NewSimpleClientset(mutating, validating)
+	r := NewWebhookReconciler(client.AdmissionregistrationV1beta1(), WebhookReconcilerArgs{
+		CACertFile:                  writeTestFile(t, dir, "ca.crt", rootCert),
+		CertFile:                    writeTestFile(t, dir, "cert-chain.pem", cert),
+		MutatingWebhookConfigName:   testInjectionConfig,
+		ValidatingWebhookConfigName: testValidationConfig,
+		ResyncPeriod:                time.Minute,
+	})
+	stop := make(chan struct{})
+	defer close(stop)
+	go r.Run(stop)
+
+	// The root certificate is rotated: the webhooks are updated to the new root certificate.
+	newRootCert, newCert := genTestCA(t)
+	writeTestFile(t, dir, "cert-chain.pem", newCert)
+	writeTestFile(t, dir, "ca.crt", newRootCert)
+	waitForWebhookConfigs(t, client, newRootCert, v1beta1.Fail)
+
+	// The caBundle is overwritten, e.g. by a re-apply of the charts: it is restored.
+	mutating, _ = newTestWebhookConfigs([]byte("stale"), v1beta1.Ignore)
+	if _, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Update(mutating); err != nil {
+		t.Fatal(err)
+	}
+	waitForWebhookConfigs(t, client, newRootCert, v1beta1.Fail)
+}
+
+func waitForWebhookConfigs(t *testing.T, client *fake.Clientset, caBundle []byte, policy v1beta1.FailurePolicyType) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		mutating, err := client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Get(testInjectionConfig, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		validating, err := client.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Get(testValidationConfig, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Equal(mutating.Webhooks[0].ClientConfig.CABundle, caBundle) &&
+			*mutating.Webhooks[0].FailurePolicy == policy &&
+			bytes.Equal(validating.Webhooks[0].ClientConfig.CABundle, caBundle) {
+			checkWebhookConfigs(t, client, caBundle, policy)
+			return
+		}
+	}
+	t.Fatal("timed out waiting for the webhook configurations to be reconciled")
+}
@@ -81,6 +81,7 @@ var (
 		plugin.Authz,
 		plugin.Health,
 		plugin.Mixer,
+		plugin.Stats,
 	}
 )
 
@@ -210,6 +211,7 @@ func (s *Server) InitDiscovery() error {
 
 	s.Environment = &model.Environment{
 		Mesh:             s.Mesh,
+		MeshExtensions:   s.MeshExtensions,
 		MeshNetworks:     s.MeshNetworks,
 		IstioConfigStore: s.IstioConfigStore,
 		ServiceDiscovery: s.ServiceController,
@@ -336,6 +338,7 @@ func defaultMeshConfig() *meshconfig.MeshConfig {
 // TODO: merge with user-specified mesh config.
 func (s *Server) WatchMeshConfig(args string) error {
 	var meshConfig *meshconfig.MeshConfig
+	var meshExtensions *mesh.Extensions
 	var err error
 
 	// Mesh config is required - this is the primary source of config.
@@ -344,6 +347,11 @@ func (s *Server) WatchMeshConfig(args string) error {
 		log.Infof("No local mesh config found, using defaults")
 		meshConfig = defaultMeshConfig()
 	}
+	meshExtensions, err = cmd.ReadMeshExtensions(args)
+	if err != nil {
+		defaultExtensions := mesh.DefaultExtensions()
+		meshExtensions = &defaultExtensions
+	}
 
 	// Watch the config file for changes and reload if it got modified
 	s.addFileWatcher(args, func() {
@@ -353,25 +361,34 @@ func (s *Server) WatchMeshConfig(args string) error {
 			log.Warnf("failed to read mesh configuration, using default: %v", err)
 			return
 		}
-		if !reflect.DeepEqual(meshConfig, s.Mesh) {
-			log.Infof("mesh configuration updated to: %s", spew.Sdump(meshConfig))
+		meshExtensions, err = cmd.ReadMeshExtensions(args)
+		if err != nil {
+			log.Warnf("failed to read mesh configuration extensions, using default: %v", err)
+			return
+		}
+		if !reflect.DeepEqual(meshConfig, s.Mesh) || !reflect.DeepEqual(meshExtensions, s.MeshExtensions) {
+			log.Infof("mesh configuration updated to: %s %s", spew.Sdump(meshConfig), spew.Sdump(meshExtensions))
 			if !reflect.DeepEqual(meshConfig.ConfigSources, s.Mesh.ConfigSources) {
 				log.Infof("mesh configuration sources have changed")
 				//TODO Need to re-create or reload initConfigController()
 			}
 			s.Mesh = meshConfig
+			s.MeshExtensions = meshExtensions
 			s.Args.MeshConfig = meshConfig
 			if s.EnvoyXdsServer != nil {
 				s.EnvoyXdsServer.Env.Mesh = meshConfig
+				s.EnvoyXdsServer.Env.MeshExtensions = meshExtensions
 				s.EnvoyXdsServer.ConfigUpdate(&model.PushRequest{Full: true})
 			}
 		}
 	})
 
 	log.Infof("mesh configuration %s", spew.Sdump(meshConfig))
+	log.Infof("mesh configuration extensions %s", spew.Sdump(meshExtensions))
 	log.Infof("version %s", version.Info.String())
 
 	s.Mesh = meshConfig
+	s.MeshExtensions = meshExtensions
 	s.Args.MeshConfig = meshConfig
 	return nil
 }
@@ -574,6 +591,10 @@ func (s *Server) initDiscoveryService(args *PilotArgs, onXDSStart func(model.XDS
 		onXDSStart(s.EnvoyXdsServer)
 	}
 
+	// Istiod is not ready before the caches of all the registries and config stores have synced.
+	s.EnvoyXdsServer.AddReadinessCheck("service registries", s.ServiceController.HasSynced)
+	s.EnvoyXdsServer.AddReadinessCheck("config stores", s.ConfigController.HasSynced)
+
 	s.mux = http.NewServeMux()
 	s.EnvoyXdsServer.InitDebug(s.mux, s.ServiceController, args.DiscoveryOptions.EnableProfiling)
 
@@ -624,26 +645,21 @@ func (s *Server) initSecureGrpcServer(options *istiokeepalive.Options) error {
 	key := path.Join(certDir, constants.KeyFilename)
 	cert := path.Join(certDir, constants.CertChainFilename)
 
-	tlsCreds, err := credentials.NewServerTLSFromFile(cert, key)
 	// certs not ready yet.
-	if err != nil {
-		return err
-	}
-
-	// TODO: parse the file to determine expiration date. Restart listener before expiration
-	certificate, err := tls.LoadX509KeyPair(cert, key)
-	if err != nil {
+	if err := s.initServingCertificate(cert, key); err != nil {
 		return err
 	}
 
 	opts := s.grpcServerOptions(options)
-	opts = append(opts, grpc.Creds(tlsCreds))
+	opts = append(opts, grpc.Creds(credentials.NewTLS(&tls.Config{
+		GetCertificate: s.getServingCertificate,
+	})))
 	s.SecureGRPCServer = grpc.NewServer(opts...)
 	s.EnvoyXdsServer.Register(s.SecureGRPCServer)
 
 	s.SecureHTTPServer = &http.Server{
 		TLSConfig: &tls.Config{
-			Certificates: []tls.Certificate{certificate},
+			GetCertificate: s.getServingCertificate,
 			VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 				// For now accept any certs - pilot is not authenticating the caller, TLS used for
 				// privacy
@@ -665,6 +681,43 @@ func (s *Server) initSecureGrpcServer(options *istiokeepalive.Options) error {
 	return nil
 }
 
+// initServingCertificate loads the DNS key and certificate, and reloads them whenever the files
+// are rotated so that the secure servers pick up renewed certificates without a restart.
+func (s *Server) initServingCertificate(cert, key string) error {
+	if err := s.loadServingCertificate(cert, key); err != nil {
+		return err
+	}
+	reload := func() {
+		// The key and the certificate are not written atomically: a mismatched pair is
+		// ignored, and the event for the other file will load the complete pair.
+		if err := s.loadServingCertificate(cert, key); err != nil {
+			log.Warnf("failed to reload the serving certificate %s: %v", cert, err)
+			return
+		}
+		log.Infof("reloaded the serving certificate %s", cert)
+	}
+	s.addFileWatcher(cert, reload)
+	s.addFileWatcher(key, reload)
+	return nil
+}
+
+func (s *Server) loadServingCertificate(cert, key string) error {
+	certificate, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return err
+	}
+	s.servingCertMutex.Lock()
+	s.servingCert = &certificate
+	s.servingCertMutex.Unlock()
+	return nil
+}
+
+func (s *Server) getServingCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.servingCertMutex.RLock()
+	defer s.servingCertMutex.RUnlock()
+	return s.servingCert, nil
+}
+
 func (s *Server) initGrpcServer(options *istiokeepalive.Options) {
 	grpcOptions := s.grpcServerOptions(options)
 	s.GrpcServer = grpc.NewServer(grpcOptions...)
@@ -707,8 +760,9 @@ func (s *Server) initEventHandlers() error {
 		// (especially mixerclient HTTP and quota)
 		configHandler := func(c model.Config, _ model.Event) {
 			pushReq := &model.PushRequest{
-				Full:               true,
-				ConfigTypesUpdated: map[string]struct{}{c.Type: {}},
+				Full:                    true,
+				ConfigTypesUpdated:      map[string]struct{}{c.Type: {}},
+				ConfigNamespacesUpdated: map[string]struct{}{c.Namespace: {}},
 			}
 			s.EnvoyXdsServer.ConfigUpdate(pushReq)
 		}
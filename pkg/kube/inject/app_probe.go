@@ -36,6 +36,10 @@ const (
 	// We reuse it for taking over application's readiness probing as well.
 	// TODO: replace the hardcoded statusPort elsewhere by this variable as much as possible.
 	StatusPortCmdFlagName = "statusPort"
+
+	// AppProbersAnnotation records the original probes of the application containers when they
+	// are rewritten, keyed by the URL of the agent that replaced them.
+	AppProbersAnnotation = "sidecar.istio.io/appProbers"
 )
 
 var (
@@ -104,41 +108,59 @@ func extractStatusPort(sidecar *corev1.Container) int {
 	return -1
 }
 
-// convertAppProber returns a overwritten `HTTPGetAction` for pilot agent to take over.
-func convertAppProber(probe *corev1.Probe, newURL string, statusPort int) *corev1.HTTPGetAction {
-	if probe == nil || probe.HTTPGet == nil {
+// convertAppProber returns the probe rewritten for pilot agent to take over, or nil if it is
+// neither an HTTP nor a TCP probe. TCP probes are turned into HTTP probes of the agent.
+func convertAppProber(probe *corev1.Probe, newURL string, statusPort int) *corev1.Probe {
+	if probe == nil || (probe.HTTPGet == nil && probe.TCPSocket == nil) {
 		return nil
 	}
-	c := probe.HTTPGet.DeepCopy()
+	p := probe.DeepCopy()
+	if p.TCPSocket != nil {
+		p.TCPSocket = nil
+		p.HTTPGet = &corev1.HTTPGetAction{}
+	}
 	// Change the application container prober config.
-	c.Port = intstr.FromInt(statusPort)
-	c.Path = newURL
+	p.HTTPGet.Port = intstr.FromInt(statusPort)
+	p.HTTPGet.Path = newURL
 	// For HTTPS prober, we change to HTTP,
 	// and pilot agent uses https to request application prober endpoint.
 	// Kubelet -> HTTP -> Pilot Agent -> HTTPS -> Application
-	if c.Scheme == corev1.URISchemeHTTPS {
-		c.Scheme = corev1.URISchemeHTTP
+	if p.HTTPGet.Scheme == corev1.URISchemeHTTPS {
+		p.HTTPGet.Scheme = corev1.URISchemeHTTP
 	}
-	return c
+	return p
 }
 
 // DumpAppProbers returns a json encoded string as `status.KubeAppProbers`.
 // Also update the probers so that all usages of named port will be resolved to integer.
 func DumpAppProbers(podspec *corev1.PodSpec) string {
 	out := status.KubeAppProbers{}
-	updateNamedPort := func(p *corev1.Probe, portMap map[string]int32) *corev1.HTTPGetAction {
-		if p == nil || p.HTTPGet == nil {
-			return nil
-		}
-		h := p.HTTPGet
-		if h.Port.Type == intstr.String {
-			port, exists := portMap[h.Port.StrVal]
+	resolveNamedPort := func(port *intstr.IntOrString, portMap map[string]int32) bool {
+		if port.Type == intstr.String {
+			p, exists := portMap[port.StrVal]
 			if !exists {
+				return false
+			}
+			*port = intstr.FromInt(int(p))
+		}
+		return true
+	}
+	updateNamedPort := func(p *corev1.Probe, portMap map[string]int32) *status.Prober {
+		switch {
+		case p == nil:
+			return nil
+		case p.HTTPGet != nil:
+			if !resolveNamedPort(&p.HTTPGet.Port, portMap) {
+				return nil
+			}
+			return &status.Prober{HTTPGet: p.HTTPGet}
+		case p.TCPSocket != nil:
+			if !resolveNamedPort(&p.TCPSocket.Port, portMap) {
 				return nil
 			}
-			h.Port = intstr.FromInt(int(port))
+			return &status.Prober{TCPSocket: p.TCPSocket}
 		}
-		return h
+		return nil
 	}
 	for _, c := range podspec.Containers {
 		if c.Name == ProxyContainerName {
@@ -166,22 +188,24 @@ func DumpAppProbers(podspec *corev1.PodSpec) string {
 	return string(b)
 }
 
-// rewriteAppHTTPProbes modifies the app probers in place for kube-inject.
-func rewriteAppHTTPProbe(annotations map[string]string, podSpec *corev1.PodSpec, spec *SidecarInjectionSpec) {
+// rewriteAppHTTPProbes modifies the app probers in place for kube-inject. It returns the original
+// probers, to be recorded in the AppProbersAnnotation, or an empty string if they are not rewritten.
+func rewriteAppHTTPProbe(annotations map[string]string, podSpec *corev1.PodSpec, spec *SidecarInjectionSpec) string {
 	if !ShouldRewriteAppHTTPProbers(annotations, spec) {
-		return
+		return ""
 	}
 	sidecar := FindSidecar(podSpec.Containers)
 	if sidecar == nil {
-		return
+		return ""
 	}
 
 	statusPort := extractStatusPort(sidecar)
 	// Pilot agent statusPort is not defined, skip changing application http probe.
 	if statusPort == -1 {
-		return
+		return ""
 	}
-	if prober := DumpAppProbers(podSpec); prober != "" {
+	prober := DumpAppProbers(podSpec)
+	if prober != "" {
 		// We don't have to escape json encoding here when using golang libraries.
 		sidecar.Env = append(sidecar.Env, corev1.EnvVar{Name: status.KubeAppProberEnvName, Value: prober})
 	}
@@ -192,13 +216,14 @@ func rewriteAppHTTPProbe(annotations map[string]string, podSpec *corev1.PodSpec,
 			continue
 		}
 		readyz, livez := status.FormatProberURL(c.Name)
-		if hg := convertAppProber(c.ReadinessProbe, readyz, statusPort); hg != nil {
-			*c.ReadinessProbe.HTTPGet = *hg
+		if p := convertAppProber(c.ReadinessProbe, readyz, statusPort); p != nil {
+			*c.ReadinessProbe = *p
 		}
-		if hg := convertAppProber(c.LivenessProbe, livez, statusPort); hg != nil {
-			*c.LivenessProbe.HTTPGet = *hg
+		if p := convertAppProber(c.LivenessProbe, livez, statusPort); p != nil {
+			*c.LivenessProbe = *p
 		}
 	}
+	return prober
 }
 
 // createProbeRewritePatch generates the patch for webhook.
@@ -207,7 +232,7 @@ func createProbeRewritePatch(annotations map[string]string, podSpec *corev1.PodS
 		return []rfc6902PatchOperation{}
 	}
 	patch := []rfc6902PatchOperation{}
-	sidecar := FindSidecar(spec.Containers)
+	sidecar := findInjectedSidecar(spec)
 	if sidecar == nil {
 		return nil
 	}
@@ -229,14 +254,14 @@ func createProbeRewritePatch(annotations map[string]string, podSpec *corev1.PodS
 		if after := convertAppProber(c.ReadinessProbe, readyz, statusPort); after != nil {
 			patch = append(patch, rfc6902PatchOperation{
 				Op:    "replace",
-				Path:  fmt.Sprintf("/spec/containers/%v/readinessProbe/httpGet", i),
+				Path:  fmt.Sprintf("/spec/containers/%v/readinessProbe", i),
 				Value: *after,
 			})
 		}
 		if after := convertAppProber(c.LivenessProbe, livez, statusPort); after != nil {
 			patch = append(patch, rfc6902PatchOperation{
 				Op:    "replace",
-				Path:  fmt.Sprintf("/spec/containers/%v/livenessProbe/httpGet", i),
+				Path:  fmt.Sprintf("/spec/containers/%v/livenessProbe", i),
 				Value: *after,
 			})
 		}
@@ -20,6 +20,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -29,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/ghodss/yaml"
 	"github.com/gogo/protobuf/jsonpb"
@@ -39,15 +41,19 @@ import (
 	"istio.io/api/annotation"
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/pkg/log"
 
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/batch/v2alpha1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	yamlDecoder "k8s.io/apimachinery/pkg/util/yaml"
 )
 
@@ -84,7 +90,23 @@ var (
 		annotation.SidecarTrafficIncludeInboundPorts.Name:         ValidateIncludeInboundPorts,
 		annotation.SidecarTrafficExcludeInboundPorts.Name:         ValidateExcludeInboundPorts,
 		annotation.SidecarTrafficExcludeOutboundPorts.Name:        ValidateExcludeOutboundPorts,
-		annotation.SidecarTrafficKubevirtInterfaces.Name:          alwaysValidFunc,
+		annotation.SidecarTrafficKubevirtInterfaces.Name:          ValidateKubevirtInterfaces,
+		constants.SidecarTrafficExcludeInterfacesAnnotation:       ValidateExcludeInterfaces,
+		constants.SidecarTraceSamplingAnnotation:                  validatePercentage,
+		InjectTemplatesAnnotation:                                 alwaysValidFunc,
+		AppProbersAnnotation:                                      alwaysValidFunc,
+		constants.SidecarNativeAnnotation:                         validateBool,
+		constants.SidecarConcurrencyAnnotation:                    validateUInt32,
+		constants.SidecarDrainDurationAnnotation:                  validateDuration,
+		constants.SidecarParentShutdownDurationAnnotation:         validateDuration,
+		constants.ProxyConfigAnnotation:                           validateProxyConfig,
+		constants.SidecarMaxDownstreamConnectionsAnnotation:       validatePositiveUInt32,
+		constants.SidecarMaxInboundConnectionsAnnotation:          validatePositiveUInt32,
+		constants.SidecarMaxOutboundConnectionsAnnotation:         validatePositiveUInt32,
+		constants.SidecarOverloadMaxHeapSizeAnnotation:            validatePositiveQuantity,
+		constants.SidecarOverloadShrinkHeapAnnotation:             validatePercentage,
+		constants.SidecarOverloadStopConnectionsAnnotation:        validatePercentage,
+		constants.SidecarHTTPDrainTimeoutAnnotation:               validateDuration,
 	}
 )
 
@@ -138,6 +160,28 @@ const (
 const (
 	// ProxyContainerName is used by e2e integration tests for fetching logs
 	ProxyContainerName = "istio-proxy"
+
+	// InjectTemplatesAnnotation selects the comma separated list of named injection
+	// templates that are rendered, in order, for a pod.
+	InjectTemplatesAnnotation = "inject.istio.io/templates"
+
+	// SidecarTemplateName is the name under which the default injection template can be selected.
+	SidecarTemplateName = "sidecar"
+
+	// GatewayInjectionLabel set to "true" turns a pod into a gateway: it is injected, with the gateway
+	// template unless it selects other templates, and its own istio-proxy container is completed by the
+	// injected one rather than duplicated.
+	GatewayInjectionLabel = "inject.istio.io/gateway"
+
+	// GatewayTemplateName is the name of the injection template of the gateways.
+	GatewayTemplateName = "gateway"
+
+	// GRPCTemplateName is the name of the injection template of the proxyless gRPC applications,
+	// which configures their xDS client rather than injecting a proxy.
+	GRPCTemplateName = "grpc"
+
+	// AutoImage is the image of a pod container whose image is set by the injection template.
+	AutoImage = "auto"
 )
 
 // SidecarInjectionSpec collects all container types and volumes for
@@ -152,6 +196,9 @@ type SidecarInjectionSpec struct {
 	Volumes             []corev1.Volume               `yaml:"volumes"`
 	DNSConfig           *corev1.PodDNSConfig          `yaml:"dnsConfig"`
 	ImagePullSecrets    []corev1.LocalObjectReference `yaml:"imagePullSecrets"`
+	// NativeSidecar indicates that the proxy container was moved to the init containers, to be
+	// injected as a native sidecar.
+	NativeSidecar bool `yaml:"-"`
 }
 
 // SidecarTemplateData is the data object to which the templated
@@ -164,6 +211,9 @@ type SidecarTemplateData struct {
 	ProxyConfig    *meshconfig.ProxyConfig
 	MeshConfig     *meshconfig.MeshConfig
 	Values         map[string]interface{}
+
+	// ProxyConfigExtensions holds the ProxyConfig fields which are not part of the ProxyConfig API yet.
+	ProxyConfigExtensions *mesh.ProxyConfigExtensions
 }
 
 // Params describes configurable parameters for injecting istio proxy
@@ -264,6 +314,11 @@ type Config struct {
 	// expansion over the `SidecarTemplateData`.
 	Template string `json:"template"`
 
+	// Templates are additional named injection templates. Pods select them with the
+	// inject.istio.io/templates annotation; the results of all the selected templates are
+	// merged, so a template can add to or override the containers of the ones before it.
+	Templates map[string]string `json:"templates"`
+
 	// NeverInjectSelector: Refuses the injection on pods whose labels match this selector.
 	// It's an array of label selectors, that will be OR'ed, meaning we will iterate
 	// over it and stop at the first match
@@ -280,6 +335,35 @@ type Config struct {
 	InjectedAnnotations map[string]string `json:"injectedAnnotations"`
 }
 
+// selectTemplates returns the templates selected by the inject.istio.io/templates annotation of
+// the pod, or the default template if there is none: the gateway template for a gateway, the
+// sidecar one otherwise.
+func (c *Config) selectTemplates(metadata *metav1.ObjectMeta) ([]string, error) {
+	names, f := metadata.Annotations[InjectTemplatesAnnotation]
+	if !f || strings.TrimSpace(names) == "" {
+		if !isGateway(metadata) {
+			return []string{c.Template}, nil
+		}
+		names = GatewayTemplateName
+	}
+	templates := make([]string, 0)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		tmpl, f := c.Templates[name]
+		if !f && name == SidecarTemplateName {
+			tmpl, f = c.Template, true
+		}
+		if !f {
+			return nil, fmt.Errorf("unknown injection template %q", name)
+		}
+		templates = append(templates, tmpl)
+	}
+	return templates, nil
+}
+
 func validateCIDRList(cidrs string) error {
 	if len(cidrs) > 0 {
 		for _, cidr := range strings.Split(cidrs, ",") {
@@ -373,6 +457,29 @@ func ValidateExcludeOutboundPorts(ports string) error {
 	return validatePortList("excludeOutboundPorts", ports)
 }
 
+// ValidateKubevirtInterfaces validates the kubevirtInterfaces parameter
+func ValidateKubevirtInterfaces(interfaces string) error {
+	return validateInterfaceList("kubevirtInterfaces", interfaces)
+}
+
+// ValidateExcludeInterfaces validates the excludeInterfaces parameter
+func ValidateExcludeInterfaces(interfaces string) error {
+	return validateInterfaceList("excludeInterfaces", interfaces)
+}
+
+// validateInterfaceList validates a comma separated list of interface names, of at most 15 characters
+// without slash, colon or whitespace as required by the kernel.
+func validateInterfaceList(parameterName, interfaces string) error {
+	if len(interfaces) > 0 {
+		for _, name := range strings.Split(interfaces, ",") {
+			if name == "" || len(name) > 15 || strings.ContainsAny(name, "/: \t\n") {
+				return fmt.Errorf("%s invalid: invalid interface name '%s'", parameterName, name)
+			}
+		}
+	}
+	return nil
+}
+
 // validateStatusPort validates the statusPort parameter
 func validateStatusPort(port string) error {
 	if _, e := parsePort(port); e != nil {
@@ -381,12 +488,59 @@ func validateStatusPort(port string) error {
 	return nil
 }
 
+// validateBool validates that the given annotation value is a boolean.
+func validateBool(value string) error {
+	_, err := strconv.ParseBool(value)
+	return err
+}
+
+// validateDuration validates that the given annotation value is a duration.
+func validateDuration(value string) error {
+	_, err := time.ParseDuration(value)
+	return err
+}
+
 // validateUInt32 validates that the given annotation value is a positive integer.
 func validateUInt32(value string) error {
 	_, err := strconv.ParseUint(value, 10, 32)
 	return err
 }
 
+// validatePositiveUInt32 validates that the given annotation value is a strictly positive integer.
+func validatePositiveUInt32(value string) error {
+	n, err := strconv.ParseUint(value, 10, 32)
+	if err == nil && n == 0 {
+		return errors.New("must be greater than 0")
+	}
+	return err
+}
+
+// validatePositiveQuantity validates that the given annotation value is a strictly positive quantity.
+func validatePositiveQuantity(value string) error {
+	q, err := resource.ParseQuantity(value)
+	if err == nil && q.Sign() <= 0 {
+		return errors.New("must be greater than 0")
+	}
+	return err
+}
+
+// validateProxyConfig validates that the given annotation value is a valid ProxyConfig in YAML.
+func validateProxyConfig(value string) error {
+	_, err := mesh.ApplyProxyConfig(value, mesh.DefaultProxyConfig())
+	return err
+}
+
+func validatePercentage(value string) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	if f < 0.0 || f > 100.0 {
+		return fmt.Errorf("percentage %v is not in range 0.0 - 100.0", f)
+	}
+	return nil
+}
+
 func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, metadata *metav1.ObjectMeta) bool { // nolint: lll
 	// Skip injection when host networking is enabled. The problem is
 	// that the iptable changes are assumed to be within the pod when,
@@ -436,6 +590,12 @@ func injectRequired(ignored []string, config *Config, podSpec *corev1.PodSpec, m
 		}
 	}
 
+	// Gateways are always injected, unless explicitly disabled
+	if useDefault && isGateway(metadata) {
+		inject = true
+		useDefault = false
+	}
+
 	// If there's no annotation nor a NeverInjectSelector, check the AlwaysInject one
 	if useDefault {
 		for _, alwaysSelector := range config.AlwaysInjectSelector {
@@ -522,6 +682,15 @@ func flippedContains(needle, haystack string) bool {
 func InjectionData(sidecarTemplate, valuesConfig, version string, typeMetadata *metav1.TypeMeta, deploymentMetadata *metav1.ObjectMeta, spec *corev1.PodSpec,
 	metadata *metav1.ObjectMeta, proxyConfig *meshconfig.ProxyConfig, meshConfig *meshconfig.MeshConfig) (
 	*SidecarInjectionSpec, string, error) {
+	return injectionData([]string{sidecarTemplate}, valuesConfig, version, typeMetadata, deploymentMetadata, spec,
+		metadata, proxyConfig, meshConfig, nil)
+}
+
+// injectionData renders each of the templates with valuesConfig and merges the results. The proxyConfigExtensions
+// are the defaults of the ProxyConfig extensions, which may be nil.
+func injectionData(templates []string, valuesConfig, version string, typeMetadata *metav1.TypeMeta, deploymentMetadata *metav1.ObjectMeta,
+	spec *corev1.PodSpec, metadata *metav1.ObjectMeta, proxyConfig *meshconfig.ProxyConfig, meshConfig *meshconfig.MeshConfig,
+	proxyConfigExtensions *mesh.ProxyConfigExtensions) (*SidecarInjectionSpec, string, error) {
 
 	// If DNSPolicy is not ClusterFirst, the Envoy sidecar may not able to connect to Istio Pilot.
 	if spec.DNSPolicy != "" && spec.DNSPolicy != corev1.DNSClusterFirst {
@@ -535,6 +704,19 @@ func InjectionData(sidecarTemplate, valuesConfig, version string, typeMetadata *
 		return nil, "", err
 	}
 
+	if pc, ok := metadata.GetAnnotations()[constants.ProxyConfigAnnotation]; ok && proxyConfig != nil {
+		merged, err := mesh.ApplyProxyConfig(pc, *proxyConfig)
+		if err != nil {
+			return nil, "", multierror.Prefix(err, "could not apply the proxy configuration of the pod:")
+		}
+		proxyConfig = merged
+		extensions, err := mesh.ApplyProxyConfigExtensions(pc, proxyConfigExtensions)
+		if err != nil {
+			return nil, "", multierror.Prefix(err, "could not apply the proxy configuration of the pod:")
+		}
+		proxyConfigExtensions = extensions
+	}
+
 	values := map[string]interface{}{}
 	if err := yaml.Unmarshal([]byte(valuesConfig), &values); err != nil {
 		log.Infof("Failed to parse values config: %v [%v]\n", err, valuesConfig)
@@ -549,6 +731,8 @@ func InjectionData(sidecarTemplate, valuesConfig, version string, typeMetadata *
 		ProxyConfig:    proxyConfig,
 		MeshConfig:     meshConfig,
 		Values:         values,
+
+		ProxyConfigExtensions: proxyConfigExtensions,
 	}
 
 	funcMap := template.FuncMap{
@@ -582,22 +766,37 @@ func InjectionData(sidecarTemplate, valuesConfig, version string, typeMetadata *
 		return bbuf.String()
 	}
 
-	bbuf, err := parseTemplate(sidecarTemplate, funcMap, data)
-	if err != nil {
-		return nil, "", err
-	}
-
 	var sic SidecarInjectionSpec
-	if err := yaml.Unmarshal(bbuf.Bytes(), &sic); err != nil {
-		// This usually means an invalid injector template; we can't check
-		// the template itself because it is merely a string.
-		log.Warnf("Failed to unmarshal template %v %s", err, bbuf.String())
-		return nil, "", multierror.Prefix(err, "failed parsing generated injected YAML (check Istio sidecar injector configuration):")
+	for _, sidecarTemplate := range templates {
+		bbuf, err := parseTemplate(sidecarTemplate, funcMap, data)
+		if err != nil {
+			return nil, "", err
+		}
+
+		var rendered SidecarInjectionSpec
+		if err := yaml.Unmarshal(bbuf.Bytes(), &rendered); err != nil {
+			// This usually means an invalid injector template; we can't check
+			// the template itself because it is merely a string.
+			log.Warnf("Failed to unmarshal template %v %s", err, bbuf.String())
+			return nil, "", multierror.Prefix(err, "failed parsing generated injected YAML (check Istio sidecar injector configuration):")
+		}
+		if err := mergeInjectionSpec(&sic, &rendered); err != nil {
+			return nil, "", multierror.Prefix(err, "failed merging injection templates:")
+		}
 	}
 
 	// set sidecar --concurrency
 	applyConcurrency(sic.Containers)
 
+	statusAnnotationValue, err := injectionStatusValue(&sic, version)
+	if err != nil {
+		return nil, "", err
+	}
+	return &sic, statusAnnotationValue, nil
+}
+
+// injectionStatusValue returns the value of the status annotation recording what sic injects.
+func injectionStatusValue(sic *SidecarInjectionSpec, version string) (string, error) {
 	status := &SidecarInjectionStatus{Version: version}
 	for _, c := range sic.InitContainers {
 		status.InitContainers = append(status.InitContainers, c.Name)
@@ -613,9 +812,132 @@ func InjectionData(sidecarTemplate, valuesConfig, version string, typeMetadata *
 	}
 	statusAnnotationValue, err := json.Marshal(status)
 	if err != nil {
-		return nil, "", fmt.Errorf("error encoded injection status: %v", err)
+		return "", fmt.Errorf("error encoded injection status: %v", err)
+	}
+	return string(statusAnnotationValue), nil
+}
+
+// mergeInjectionSpec merges the rendering of a template into the ones of the templates before it.
+// Containers with the same name are strategically merged, volumes with the same name are replaced
+// and everything else is appended.
+func mergeInjectionSpec(dst, src *SidecarInjectionSpec) error {
+	var err error
+	if dst.InitContainers, err = mergeContainers(dst.InitContainers, src.InitContainers); err != nil {
+		return err
+	}
+	if dst.Containers, err = mergeContainers(dst.Containers, src.Containers); err != nil {
+		return err
+	}
+	for _, v := range src.Volumes {
+		replaced := false
+		for i := range dst.Volumes {
+			if dst.Volumes[i].Name == v.Name {
+				dst.Volumes[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			dst.Volumes = append(dst.Volumes, v)
+		}
+	}
+	for _, s := range src.ImagePullSecrets {
+		found := false
+		for _, d := range dst.ImagePullSecrets {
+			if d.Name == s.Name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dst.ImagePullSecrets = append(dst.ImagePullSecrets, s)
+		}
 	}
-	return &sic, string(statusAnnotationValue), nil
+	if src.DNSConfig != nil {
+		dst.DNSConfig = src.DNSConfig
+	}
+	dst.RewriteAppHTTPProbe = dst.RewriteAppHTTPProbe || src.RewriteAppHTTPProbe
+	for k, v := range src.PodRedirectAnnot {
+		if dst.PodRedirectAnnot == nil {
+			dst.PodRedirectAnnot = map[string]string{}
+		}
+		dst.PodRedirectAnnot[k] = v
+	}
+	return nil
+}
+
+func mergeContainers(dst, src []corev1.Container) ([]corev1.Container, error) {
+	for _, c := range src {
+		merged := false
+		for i := range dst {
+			if dst[i].Name != c.Name {
+				continue
+			}
+			original, err := json.Marshal(dst[i])
+			if err != nil {
+				return nil, err
+			}
+			patch, err := json.Marshal(c)
+			if err != nil {
+				return nil, err
+			}
+			out, err := strategicpatch.StrategicMergePatch(original, patch, corev1.Container{})
+			if err != nil {
+				return nil, fmt.Errorf("failed merging container %s: %v", c.Name, err)
+			}
+			var container corev1.Container
+			if err := json.Unmarshal(out, &container); err != nil {
+				return nil, err
+			}
+			dst[i] = container
+			merged = true
+			break
+		}
+		if !merged {
+			dst = append(dst, c)
+		}
+	}
+	return dst, nil
+}
+
+// isGateway returns true if the pod is labeled to be injected as a gateway.
+func isGateway(metadata *metav1.ObjectMeta) bool {
+	return strings.ToLower(metadata.Labels[GatewayInjectionLabel]) == "true"
+}
+
+// completePodContainers merges the containers of the pod into the injected containers of the same
+// name, e.g. the istio-proxy container of a gateway deployment. The fields of the pod container take
+// precedence over the ones of the templates, but for an auto image. The containers previously
+// injected are ignored as they are replaced anyway. It returns the names of the merged containers,
+// which replace the ones of the pod.
+func completePodContainers(pod []corev1.Container, previouslyInjected []string, sic *SidecarInjectionSpec) ([]string, error) {
+	injected := make(map[string]bool, len(previouslyInjected))
+	for _, name := range previouslyInjected {
+		injected[name] = true
+	}
+
+	var merged []string
+	for _, c := range pod {
+		if injected[c.Name] {
+			continue
+		}
+		for i := range sic.Containers {
+			if sic.Containers[i].Name != c.Name {
+				continue
+			}
+			if c.Image == AutoImage {
+				c.Image = ""
+			}
+			containers, err := mergeContainers([]corev1.Container{sic.Containers[i]}, []corev1.Container{c})
+			if err != nil {
+				return nil, err
+			}
+			sic.Containers[i] = containers[0]
+			merged = append(merged, c.Name)
+			break
+		}
+	}
+	return merged, nil
 }
 
 func parseTemplate(tmplStr string, funcMap map[string]interface{}, data SidecarTemplateData) (bytes.Buffer, error) {
@@ -820,7 +1142,7 @@ func IntoObject(sidecarTemplate string, valuesConfig string, meshconfig *meshcon
 
 	// Modify application containers' HTTP probe after appending injected containers.
 	// Because we need to extract istio-proxy's statusPort.
-	rewriteAppHTTPProbe(metadata.Annotations, podSpec, spec)
+	appProbers := rewriteAppHTTPProbe(metadata.Annotations, podSpec, spec)
 
 	// due to bug https://github.com/kubernetes/kubernetes/issues/57923,
 	// k8s sa jwt token volume mount file is only accessible to root user, not istio-proxy(the user that istio proxy runs as).
@@ -840,6 +1162,10 @@ func IntoObject(sidecarTemplate string, valuesConfig string, meshconfig *meshcon
 		rewriteCniPodSPec(metadata.Annotations, spec)
 	}
 
+	if appProbers != "" {
+		metadata.Annotations[AppProbersAnnotation] = appProbers
+	}
+
 	metadata.Annotations[annotation.SidecarStatus.Name] = status
 	if status != "" && metadata.Labels[model.TLSModeLabelName] == "" {
 		if metadata.Labels == nil {
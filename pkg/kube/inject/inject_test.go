@@ -23,14 +23,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/gogo/protobuf/types"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/strvals"
 
 	meshapi "istio.io/api/mesh/v1alpha1"
 
 	"istio.io/istio/pilot/test/util"
 	"istio.io/istio/pkg/config/mesh"
 
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -149,6 +154,26 @@ func TestIntoResourceFile(t *testing.T) {
 			readinessPeriodSeconds:       DefaultReadinessPeriodSeconds,
 			readinessFailureThreshold:    DefaultReadinessFailureThreshold,
 		},
+		{
+			in:                           "hello-proxy-settings.yaml",
+			want:                         "hello-proxy-settings.yaml.injected",
+			includeIPRanges:              DefaultIncludeIPRanges,
+			includeInboundPorts:          DefaultIncludeInboundPorts,
+			statusPort:                   DefaultStatusPort,
+			readinessInitialDelaySeconds: DefaultReadinessInitialDelaySeconds,
+			readinessPeriodSeconds:       DefaultReadinessPeriodSeconds,
+			readinessFailureThreshold:    DefaultReadinessFailureThreshold,
+		},
+		{
+			in:                           "hello-proxy-config.yaml",
+			want:                         "hello-proxy-config.yaml.injected",
+			includeIPRanges:              DefaultIncludeIPRanges,
+			includeInboundPorts:          DefaultIncludeInboundPorts,
+			statusPort:                   DefaultStatusPort,
+			readinessInitialDelaySeconds: DefaultReadinessInitialDelaySeconds,
+			readinessPeriodSeconds:       DefaultReadinessPeriodSeconds,
+			readinessFailureThreshold:    DefaultReadinessFailureThreshold,
+		},
 		{
 			in:     "hello.yaml",
 			want:   "hello-tproxy.yaml.injected",
@@ -660,6 +685,11 @@ func TestRewriteAppProbe(t *testing.T) {
 			rewriteAppHTTPProbe: true,
 			want:                "ready_live.yaml.injected",
 		},
+		{
+			in:                  "tcp_probe.yaml",
+			rewriteAppHTTPProbe: true,
+			want:                "tcp_probe.yaml.injected",
+		},
 		// TODO(incfly): add more test case covering different -statusPort=123, --statusPort=123
 		// No statusport, --statusPort 123.
 	}
@@ -780,6 +810,14 @@ func TestInvalidAnnotations(t *testing.T) {
 			annotation: "excludeoutboundports",
 			in:         "traffic-annotations-bad-excludeoutboundports.yaml",
 		},
+		{
+			annotation: "excludeinterfaces",
+			in:         "traffic-annotations-bad-excludeinterfaces.yaml",
+		},
+		{
+			annotation: "proxy.istio.io/config",
+			in:         "proxy-config-annotation-bad.yaml",
+		},
 	}
 
 	for _, c := range cases {
@@ -883,6 +921,116 @@ func TestSkipUDPPorts(t *testing.T) {
 	}
 }
 
+func TestStatsFilterExtraStatTags(t *testing.T) {
+	cases := []struct {
+		name   string
+		values string
+		want   string
+	}{
+		{
+			name:   "disabled",
+			values: "global.statsFilter.enabled=false,global.statsFilter.extraDimensions.source_mesh.nodeMetadata=MESH_ID",
+		},
+		{
+			name:   "no extra dimensions",
+			values: "global.statsFilter.enabled=true,global.statsFilter.removedDimensions={request_protocol}",
+		},
+		{
+			name: "extra dimensions",
+			values: "global.statsFilter.enabled=true,global.statsFilter.extraDimensions.source_mesh.nodeMetadata=MESH_ID," +
+				"global.statsFilter.extraDimensions.request_user_agent.requestHeader=user-agent",
+			want: "request_user_agent,source_mesh,",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := newTestParams()
+			valMap := chartutil.FromYaml(getValues(params, t))
+			if err := strvals.ParseInto(c.values, valMap); err != nil {
+				t.Fatal(err)
+			}
+			in, err := os.Open("testdata/inject/hello.yaml")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = in.Close() }()
+			var out bytes.Buffer
+			if err := IntoResourceFile(loadSidecarTemplate(t), chartutil.ToYaml(valMap), params.Mesh, in, &out); err != nil {
+				t.Fatalf("IntoResourceFile() failed: %v", err)
+			}
+
+			var deployment appsv1.Deployment
+			if err := yaml.Unmarshal(out.Bytes(), &deployment); err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			for _, container := range deployment.Spec.Template.Spec.Containers {
+				for _, env := range container.Env {
+					if env.Name == "ISTIO_META_EXTRA_STAT_TAGS" {
+						got = env.Value
+					}
+				}
+			}
+			if got != c.want {
+				t.Errorf("got extra stat tags %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestOpenTelemetryTracer(t *testing.T) {
+	defaults := &mesh.ProxyConfigExtensions{
+		Tracing: &mesh.TracingExtensions{
+			OpenTelemetry: &mesh.OpenTelemetryTracer{
+				Address:            "otel-collector.observability:4317",
+				ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+			},
+		},
+	}
+	cases := []struct {
+		name        string
+		annotations map[string]string
+		defaults    *mesh.ProxyConfigExtensions
+		want        string
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:     "mesh default",
+			defaults: defaults,
+			want:     `{"address":"otel-collector.observability:4317","resourceAttributes":{"deployment.environment":"prod"}}`,
+		},
+		{
+			name:        "annotation",
+			annotations: map[string]string{"proxy.istio.io/config": "tracing: {openTelemetry: {address: 'otel-collector:4317', sampling: 5}}"},
+			defaults:    defaults,
+			want:        `{"address":"otel-collector:4317","sampling":5}`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := newTestParams()
+			meta := &metav1.ObjectMeta{Name: "hello", Namespace: "default", Annotations: c.annotations}
+			spec, _, err := injectionData([]string{loadSidecarTemplate(t)}, getValues(params, t), "", &metav1.TypeMeta{}, meta,
+				&corev1.PodSpec{}, meta, params.Mesh.DefaultConfig, params.Mesh, c.defaults)
+			if err != nil {
+				t.Fatalf("injectionData() failed: %v", err)
+			}
+			var got string
+			args := spec.Containers[0].Args
+			for i := range args {
+				if args[i] == "--openTelemetryTracer" && i+1 < len(args) {
+					got = args[i+1]
+				}
+			}
+			if got != c.want {
+				t.Errorf("got OpenTelemetry tracer %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
 func newTestParams() *Params {
 	m := mesh.DefaultMeshConfig()
 	return &Params{
@@ -0,0 +1,91 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file is focused on the proxy settings a namespace sets for the pods injected in it.
+package inject
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"istio.io/api/annotation"
+
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/pkg/log"
+)
+
+// namespaceResyncPeriod is the resync period of the namespace informer of the webhook.
+const namespaceResyncPeriod = 30 * time.Minute
+
+// namespaceDefaultAnnotations are the pod annotations a namespace can set a default for, by
+// carrying the annotation itself. The annotations of the pod take precedence.
+var namespaceDefaultAnnotations = []string{
+	annotation.SidecarProxyCPU.Name,
+	annotation.SidecarProxyMemory.Name,
+	annotation.SidecarLogLevel.Name,
+	annotation.SidecarComponentLogLevel.Name,
+	annotation.SidecarInterceptionMode.Name,
+	constants.SidecarConcurrencyAnnotation,
+	constants.SidecarDrainDurationAnnotation,
+	constants.SidecarParentShutdownDurationAnnotation,
+}
+
+// newNamespaceInformer returns an informer of the namespaces, whose annotations set the defaults
+// of the proxies injected in them.
+func newNamespaceInformer(client kubernetes.Interface) (cache.SharedIndexInformer, corelisters.NamespaceLister) {
+	namespaces := informers.NewSharedInformerFactory(client, namespaceResyncPeriod).Core().V1().Namespaces()
+	return namespaces.Informer(), namespaces.Lister()
+}
+
+// withNamespaceDefaults returns a copy of the pod metadata, with the defaults set by its namespace
+// added to the annotations the pod does not set.
+func withNamespaceDefaults(namespaces corelisters.NamespaceLister, metadata *metav1.ObjectMeta) *metav1.ObjectMeta {
+	if namespaces == nil {
+		return metadata
+	}
+	ns, err := namespaces.Get(metadata.Namespace)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Warnf("failed to get namespace %s, its proxy defaults are not applied: %v", metadata.Namespace, err)
+		}
+		return metadata
+	}
+	var out *metav1.ObjectMeta
+	for _, name := range namespaceDefaultAnnotations {
+		value, ok := ns.Annotations[name]
+		if !ok {
+			continue
+		}
+		if _, ok := metadata.Annotations[name]; ok {
+			continue
+		}
+		if out == nil {
+			out = metadata.DeepCopy()
+			if out.Annotations == nil {
+				out.Annotations = map[string]string{}
+			}
+		}
+		out.Annotations[name] = value
+	}
+	if out == nil {
+		return metadata
+	}
+	return out
+}
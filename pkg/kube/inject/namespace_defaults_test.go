@@ -0,0 +1,100 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"istio.io/api/annotation"
+
+	"istio.io/istio/pkg/config/constants"
+)
+
+func TestWithNamespaceDefaults(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	_ = indexer.Add(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "tenant",
+			Annotations: map[string]string{
+				annotation.SidecarProxyCPU.Name:         "500m",
+				annotation.SidecarLogLevel.Name:         "debug",
+				annotation.SidecarInterceptionMode.Name: "NONE",
+				constants.SidecarConcurrencyAnnotation:  "4",
+				// not a proxy default
+				annotation.SidecarInject.Name: "false",
+			},
+		},
+	})
+	_ = indexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "plain"}})
+	namespaces := corelisters.NewNamespaceLister(indexer)
+
+	cases := []struct {
+		name        string
+		namespace   string
+		annotations map[string]string
+		want        map[string]string
+	}{
+		{
+			name:      "defaults",
+			namespace: "tenant",
+			want: map[string]string{
+				annotation.SidecarProxyCPU.Name:         "500m",
+				annotation.SidecarLogLevel.Name:         "debug",
+				annotation.SidecarInterceptionMode.Name: "NONE",
+				constants.SidecarConcurrencyAnnotation:  "4",
+			},
+		},
+		{
+			name:        "pod overrides",
+			namespace:   "tenant",
+			annotations: map[string]string{annotation.SidecarProxyCPU.Name: "2", "app": "reviews"},
+			want: map[string]string{
+				annotation.SidecarProxyCPU.Name:         "2",
+				annotation.SidecarLogLevel.Name:         "debug",
+				annotation.SidecarInterceptionMode.Name: "NONE",
+				constants.SidecarConcurrencyAnnotation:  "4",
+				"app":                                   "reviews",
+			},
+		},
+		{
+			name:        "no defaults",
+			namespace:   "plain",
+			annotations: map[string]string{"app": "reviews"},
+			want:        map[string]string{"app": "reviews"},
+		},
+		{
+			name:      "unknown namespace",
+			namespace: "missing",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			metadata := &metav1.ObjectMeta{Name: "app", Namespace: c.namespace, Annotations: c.annotations}
+			got := withNamespaceDefaults(namespaces, metadata)
+			if !reflect.DeepEqual(got.Annotations, c.want) {
+				t.Errorf("got annotations %v, want %v", got.Annotations, c.want)
+			}
+			if !reflect.DeepEqual(metadata.Annotations, c.annotations) {
+				t.Errorf("expected the pod metadata to be unchanged, got %v", metadata.Annotations)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file is focused on injecting the proxy as a native sidecar container, a restartable init
+// container which starts before the application containers and terminates after them.
+package inject
+
+import (
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/pkg/log"
+)
+
+const (
+	// restartPolicyAlways is the restart policy which makes an init container a native sidecar.
+	restartPolicyAlways = "Always"
+
+	// nativeSidecarMinMinorVersion is the first Kubernetes 1.x release enabling native sidecars by default.
+	nativeSidecarMinMinorVersion = 29
+)
+
+// nativeSidecarContainer adds the restartPolicy field, which the vendored Kubernetes API predates,
+// to an init container.
+type nativeSidecarContainer struct {
+	corev1.Container
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+}
+
+// NativeSidecarsSupported returns whether the API server runs a Kubernetes version with native
+// sidecar containers enabled by default.
+func NativeSidecarsSupported(client kubernetes.Interface) bool {
+	v, err := client.Discovery().ServerVersion()
+	if err != nil {
+		log.Warnf("failed to get the Kubernetes version, native sidecars are disabled: %v", err)
+		return false
+	}
+	major, err := strconv.Atoi(v.Major)
+	if err != nil {
+		return false
+	}
+	// managed clusters report minor versions like "29+"
+	minor, err := strconv.Atoi(strings.TrimSuffix(v.Minor, "+"))
+	if err != nil {
+		return false
+	}
+	return major > 1 || (major == 1 && minor >= nativeSidecarMinMinorVersion)
+}
+
+// useNativeSidecar returns whether the proxy of the pod is injected as a native sidecar, enabled
+// is the default of the webhook.
+func useNativeSidecar(annotations map[string]string, enabled bool) bool {
+	if value, ok := annotations[constants.SidecarNativeAnnotation]; ok {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return enabled
+}
+
+// moveSidecarToInitContainers moves the proxy container after the injected init containers, so
+// that it starts once traffic is redirected and before the application containers. Returns false
+// if the template has no proxy container.
+func moveSidecarToInitContainers(sic *SidecarInjectionSpec) bool {
+	for i, c := range sic.Containers {
+		if c.Name != ProxyContainerName {
+			continue
+		}
+		sic.Containers = append(sic.Containers[:i:i], sic.Containers[i+1:]...)
+		sic.InitContainers = append(sic.InitContainers, c)
+		sic.NativeSidecar = true
+		return true
+	}
+	return false
+}
+
+// findInjectedSidecar returns the proxy container of the injection, wherever it is injected.
+func findInjectedSidecar(sic *SidecarInjectionSpec) *corev1.Container {
+	if sic.NativeSidecar {
+		return FindSidecar(sic.InitContainers)
+	}
+	return FindSidecar(sic.Containers)
+}
@@ -0,0 +1,45 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inject
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNativeSidecarsSupported(t *testing.T) {
+	cases := []struct {
+		major, minor string
+		want         bool
+	}{
+		{major: "1", minor: "16", want: false},
+		{major: "1", minor: "28+", want: false},
+		{major: "1", minor: "29", want: true},
+		{major: "1", minor: "30+", want: true},
+		{major: "", minor: "", want: false},
+	}
+	for _, c := range cases {
+		t.Run(c.major+"."+c.minor, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			client.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: c.major, Minor: c.minor}
+			if got := NativeSidecarsSupported(client); got != c.want {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
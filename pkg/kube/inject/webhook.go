@@ -35,6 +35,8 @@ import (
 	"istio.io/istio/pilot/cmd"
 	"istio.io/istio/pilot/cmd/pilot-agent/status"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/pkg/log"
 
 	"k8s.io/api/admission/v1beta1"
@@ -42,6 +44,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 var (
@@ -65,6 +70,7 @@ type Webhook struct {
 	sidecarConfig          *Config
 	sidecarTemplateVersion string
 	meshConfig             *meshconfig.MeshConfig
+	meshExtensions         *mesh.Extensions
 	valuesConfig           string
 
 	healthCheckInterval time.Duration
@@ -79,27 +85,37 @@ type Webhook struct {
 	keyFile    string
 	cert       *tls.Certificate
 	mon        *monitor
+	revision   string
+
+	nativeSidecars bool
+
+	namespaceInformer cache.SharedIndexInformer
+	namespaces        corelisters.NamespaceLister
 }
 
-func loadConfig(injectFile, meshFile, valuesFile string) (*Config, *meshconfig.MeshConfig, string, error) {
+func loadConfig(injectFile, meshFile, valuesFile string) (*Config, *meshconfig.MeshConfig, *mesh.Extensions, string, error) {
 	data, err := ioutil.ReadFile(injectFile)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
 	}
 	var c Config
 	if err := yaml.Unmarshal(data, &c); err != nil {
 		log.Warnf("Failed to parse injectFile %s", string(data))
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
 	}
 
 	valuesConfig, err := ioutil.ReadFile(valuesFile)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
 	}
 
 	meshConfig, err := cmd.ReadMeshConfig(meshFile)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, "", err
+	}
+	meshExtensions, err := cmd.ReadMeshExtensions(meshFile)
+	if err != nil {
+		return nil, nil, nil, "", err
 	}
 
 	log.Infof("New configuration: sha256sum %x", sha256.Sum256(data))
@@ -108,7 +124,7 @@ func loadConfig(injectFile, meshFile, valuesFile string) (*Config, *meshconfig.M
 	log.Infof("NeverInjectSelector: %v", c.NeverInjectSelector)
 	log.Infof("Template: |\n  %v", strings.Replace(c.Template, "\n", "\n  ", -1))
 
-	return &c, meshConfig, string(valuesConfig), nil
+	return &c, meshConfig, meshExtensions, string(valuesConfig), nil
 }
 
 // WebhookParameters configures parameters for the sidecar injection
@@ -142,11 +158,25 @@ type WebhookParameters struct {
 	// HealthCheckFile specifies the path to the health check file
 	// that is periodically updated.
 	HealthCheckFile string
+
+	// Revision is the revision of the control plane the webhook injects for. Pods
+	// labeled with another revision are skipped, and injected pods are labeled with it.
+	// The requests of the webhooks of the tags of the revision carry the tag in their path,
+	// the pods labeled with the tag are injected as well.
+	Revision string
+
+	// NativeSidecars injects the proxy as a native sidecar container, unless a pod opts out
+	// with the sidecar.istio.io/nativeSidecar annotation. It requires a cluster supporting them.
+	NativeSidecars bool
+
+	// Client, if set, is used to watch the namespaces, whose annotations set the defaults of the
+	// proxies injected in them.
+	Client kubernetes.Interface
 }
 
 // NewWebhook creates a new instance of a mutating webhook for automatic sidecar injection.
 func NewWebhook(p WebhookParameters) (*Webhook, error) {
-	sidecarConfig, meshConfig, valuesConfig, err := loadConfig(p.ConfigFile, p.MeshFile, p.ValuesFile)
+	sidecarConfig, meshConfig, meshExtensions, valuesConfig, err := loadConfig(p.ConfigFile, p.MeshFile, p.ValuesFile)
 	if err != nil {
 		return nil, err
 	}
@@ -175,6 +205,7 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 		sidecarConfig:          sidecarConfig,
 		sidecarTemplateVersion: sidecarTemplateVersionHash(sidecarConfig.Template),
 		meshConfig:             meshConfig,
+		meshExtensions:         meshExtensions,
 		configFile:             p.ConfigFile,
 		valuesFile:             p.ValuesFile,
 		valuesConfig:           valuesConfig,
@@ -185,11 +216,17 @@ func NewWebhook(p WebhookParameters) (*Webhook, error) {
 		certFile:               p.CertFile,
 		keyFile:                p.KeyFile,
 		cert:                   &pair,
+		revision:               p.Revision,
+		nativeSidecars:         p.NativeSidecars,
+	}
+	if p.Client != nil {
+		wh.namespaceInformer, wh.namespaces = newNamespaceInformer(p.Client)
 	}
 	// mtls disabled because apiserver webhook cert usage is still TBD.
 	wh.server.TLSConfig = &tls.Config{GetCertificate: wh.getCert}
 	h := http.NewServeMux()
 	h.HandleFunc("/inject", wh.serveInject)
+	h.HandleFunc(constants.InjectTagPathPrefix, wh.serveInject)
 
 	mon, err := startMonitor(h, p.MonitoringPort)
 
@@ -214,6 +251,10 @@ func (wh *Webhook) Run(stop <-chan struct{}) {
 	defer wh.server.Close()
 	defer wh.mon.monitoringServer.Close()
 
+	if wh.namespaceInformer != nil {
+		go wh.namespaceInformer.Run(stop)
+	}
+
 	var healthC <-chan time.Time
 	if wh.healthCheckInterval != 0 && wh.healthCheckFile != "" {
 		t := time.NewTicker(wh.healthCheckInterval)
@@ -226,7 +267,7 @@ func (wh *Webhook) Run(stop <-chan struct{}) {
 		select {
 		case <-timerC:
 			timerC = nil
-			sidecarConfig, meshConfig, valuesConfig, err := loadConfig(wh.configFile, wh.meshFile, wh.valuesFile)
+			sidecarConfig, meshConfig, meshExtensions, valuesConfig, err := loadConfig(wh.configFile, wh.meshFile, wh.valuesFile)
 			if err != nil {
 				log.Errorf("update error: %v", err)
 				break
@@ -243,6 +284,7 @@ func (wh *Webhook) Run(stop <-chan struct{}) {
 			wh.valuesConfig = valuesConfig
 			wh.sidecarTemplateVersion = version
 			wh.meshConfig = meshConfig
+			wh.meshExtensions = meshExtensions
 			wh.cert = &pair
 			wh.mu.Unlock()
 		case event := <-wh.watcher.Event:
@@ -329,7 +371,9 @@ func removeImagePullSecrets(imagePullSecrets []corev1.LocalObjectReference, remo
 	return patch
 }
 
-func addContainer(target, added []corev1.Container, basePath string) (patch []rfc6902PatchOperation) {
+// addContainer adds the containers to the target. With nativeSidecar, the proxy container among them
+// is added as a native sidecar.
+func addContainer(target, added []corev1.Container, basePath string, nativeSidecar bool) (patch []rfc6902PatchOperation) {
 	saJwtSecretMountName := ""
 	var saJwtSecretMount corev1.VolumeMount
 	// find service account secret volume mount(/var/run/secrets/kubernetes.io/serviceaccount,
@@ -352,10 +396,13 @@ func addContainer(target, added []corev1.Container, basePath string) (patch []rf
 			add.VolumeMounts = append(add.VolumeMounts, saJwtSecretMount)
 		}
 		value = add
+		if nativeSidecar && add.Name == ProxyContainerName {
+			value = nativeSidecarContainer{Container: add, RestartPolicy: restartPolicyAlways}
+		}
 		path := basePath
 		if first {
 			first = false
-			value = []corev1.Container{add}
+			value = []interface{}{value}
 		} else {
 			path += "/-"
 		}
@@ -494,7 +541,8 @@ func updateAnnotation(target map[string]string, added map[string]string) (patch
 	return patch
 }
 
-func createPatch(pod *corev1.Pod, prevStatus *SidecarInjectionStatus, annotations map[string]string, sic *SidecarInjectionSpec) ([]byte, error) {
+func createPatch(pod *corev1.Pod, prevStatus *SidecarInjectionStatus, revision string, annotations map[string]string,
+	sic *SidecarInjectionSpec) ([]byte, error) {
 	var patch []rfc6902PatchOperation
 
 	// Remove any containers previously injected by kube-inject using
@@ -509,7 +557,7 @@ func createPatch(pod *corev1.Pod, prevStatus *SidecarInjectionStatus, annotation
 		if !rewrite {
 			return
 		}
-		sidecar := FindSidecar(sic.Containers)
+		sidecar := findInjectedSidecar(sic)
 		if sidecar == nil {
 			log.Errorf("sidecar not found in the template, skip addAppProberCmd")
 			return
@@ -517,12 +565,13 @@ func createPatch(pod *corev1.Pod, prevStatus *SidecarInjectionStatus, annotation
 		// We don't have to escape json encoding here when using golang libraries.
 		if prober := DumpAppProbers(&pod.Spec); prober != "" {
 			sidecar.Env = append(sidecar.Env, corev1.EnvVar{Name: status.KubeAppProberEnvName, Value: prober})
+			annotations[AppProbersAnnotation] = prober
 		}
 	}
 	addAppProberCmd()
 
-	patch = append(patch, addContainer(pod.Spec.InitContainers, sic.InitContainers, "/spec/initContainers")...)
-	patch = append(patch, addContainer(pod.Spec.Containers, sic.Containers, "/spec/containers")...)
+	patch = append(patch, addContainer(pod.Spec.InitContainers, sic.InitContainers, "/spec/initContainers", sic.NativeSidecar)...)
+	patch = append(patch, addContainer(pod.Spec.Containers, sic.Containers, "/spec/containers", false)...)
 	patch = append(patch, addVolume(pod.Spec.Volumes, sic.Volumes, "/spec/volumes")...)
 	patch = append(patch, addImagePullSecrets(pod.Spec.ImagePullSecrets, sic.ImagePullSecrets, "/spec/imagePullSecrets")...)
 
@@ -536,7 +585,11 @@ func createPatch(pod *corev1.Pod, prevStatus *SidecarInjectionStatus, annotation
 
 	patch = append(patch, updateAnnotation(pod.Annotations, annotations)...)
 
-	patch = append(patch, addLabels(pod.Labels, map[string]string{model.TLSModeLabelName: model.IstioMutualTLSModeLabel})...)
+	labels := map[string]string{model.TLSModeLabelName: model.IstioMutualTLSModeLabel}
+	if revision != "" {
+		labels[constants.IstioRevisionLabel] = revision
+	}
+	patch = append(patch, addLabels(pod.Labels, labels)...)
 
 	if rewrite {
 		patch = append(patch, createProbeRewritePatch(pod.Annotations, &pod.Spec, sic)...)
@@ -588,7 +641,9 @@ func toAdmissionResponse(err error) *v1beta1.AdmissionResponse {
 	return &v1beta1.AdmissionResponse{Result: &metav1.Status{Message: err.Error()}}
 }
 
-func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionResponse {
+// inject injects the pod of the review. Tag is the revision tag the request was made for, if
+// made by the webhook of a tag.
+func (wh *Webhook) inject(ar *v1beta1.AdmissionReview, tag string) *v1beta1.AdmissionResponse {
 	req := ar.Request
 	var pod corev1.Pod
 	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
@@ -616,6 +671,15 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 		}
 	}
 
+	// the pod may be pinned to the injector of another control plane revision
+	if rev := pod.Labels[constants.IstioRevisionLabel]; rev != "" && rev != wh.revision && rev != tag {
+		log.Infof("Skipping %s/%s due to revision %q", pod.ObjectMeta.Namespace, podName, rev)
+		totalSkippedInjections.Increment()
+		return &v1beta1.AdmissionResponse{
+			Allowed: true,
+		}
+	}
+
 	// due to bug https://github.com/kubernetes/kubernetes/issues/57923,
 	// k8s sa jwt token volume mount file is only accessible to root user, not istio-proxy(the user that istio proxy runs as).
 	// workaround by https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-the-security-context-for-a-pod
@@ -667,12 +731,27 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 		deployMeta.Name = pod.Name
 	}
 
-	spec, iStatus, err := InjectionData(wh.sidecarConfig.Template, wh.valuesConfig, wh.sidecarTemplateVersion, typeMetadata, deployMeta, &pod.Spec, &pod.ObjectMeta, wh.meshConfig.DefaultConfig, wh.meshConfig) // nolint: lll
+	templates, err := wh.sidecarConfig.selectTemplates(&pod.ObjectMeta)
+	if err != nil {
+		handleError(fmt.Sprintf("Injection templates: err=%v", err))
+		return toAdmissionResponse(err)
+	}
+
+	metadata := withNamespaceDefaults(wh.namespaces, &pod.ObjectMeta)
+	spec, iStatus, err := injectionData(templates, wh.valuesConfig, wh.sidecarTemplateVersion, typeMetadata, deployMeta, &pod.Spec, metadata, wh.meshConfig.DefaultConfig, wh.meshConfig, wh.meshExtensions.GetDefaultConfig()) // nolint: lll
 	if err != nil {
 		handleError(fmt.Sprintf("Injection data: err=%v spec=%v\n", err, iStatus))
 		return toAdmissionResponse(err)
 	}
 
+	if useNativeSidecar(pod.Annotations, wh.nativeSidecars) && moveSidecarToInitContainers(spec) {
+		// the status records the proxy as an init container, for it to be removed on reinjection
+		if iStatus, err = injectionStatusValue(spec, wh.sidecarTemplateVersion); err != nil {
+			handleError(fmt.Sprintf("Injection status: err=%v", err))
+			return toAdmissionResponse(err)
+		}
+	}
+
 	annotations := map[string]string{annotation.SidecarStatus.Name: iStatus}
 
 	// Add all additional injected annotations
@@ -680,7 +759,25 @@ func (wh *Webhook) inject(ar *v1beta1.AdmissionReview) *v1beta1.AdmissionRespons
 		annotations[k] = v
 	}
 
-	patchBytes, err := createPatch(&pod, injectionStatus(&pod), annotations, spec)
+	prevStatus := injectionStatus(&pod)
+	var injected []string
+	if _, ok := pod.Annotations[annotation.SidecarStatus.Name]; ok {
+		// without status, the legacy container names would hide the istio-proxy container of a gateway
+		injected = prevStatus.Containers
+	}
+	completed, err := completePodContainers(pod.Spec.Containers, injected, spec)
+	if err != nil {
+		handleError(fmt.Sprintf("Completing the pod containers: err=%v", err))
+		return toAdmissionResponse(err)
+	}
+	if len(completed) > 0 {
+		// the pod containers completed by the injected ones are replaced by them
+		replaced := *prevStatus
+		replaced.Containers = append(append([]string{}, prevStatus.Containers...), completed...)
+		prevStatus = &replaced
+	}
+
+	patchBytes, err := createPatch(&pod, prevStatus, wh.revision, annotations, spec)
 	if err != nil {
 		handleError(fmt.Sprintf("AdmissionResponse: err=%v spec=%v\n", err, spec))
 		return toAdmissionResponse(err)
@@ -728,7 +825,7 @@ func (wh *Webhook) serveInject(w http.ResponseWriter, r *http.Request) {
 		handleError(fmt.Sprintf("Could not decode body: %v", err))
 		reviewResponse = toAdmissionResponse(err)
 	} else {
-		reviewResponse = wh.inject(&ar)
+		reviewResponse = wh.inject(&ar, requestTag(r))
 	}
 
 	response := v1beta1.AdmissionReview{}
@@ -750,6 +847,14 @@ func (wh *Webhook) serveInject(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// requestTag returns the revision tag of an injection request made by the webhook of a tag.
+func requestTag(r *http.Request) string {
+	if !strings.HasPrefix(r.URL.Path, constants.InjectTagPathPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(r.URL.Path, constants.InjectTagPathPrefix)
+}
+
 func handleError(message string) {
 	log.Errorf(message)
 	totalFailedInjections.Increment()
@@ -24,6 +24,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -37,6 +38,7 @@ import (
 	"istio.io/api/annotation"
 
 	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/mcp/testing/testcerts"
 
@@ -509,6 +511,44 @@ func TestInjectRequired(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			config: &Config{
+				Policy: InjectionPolicyDisabled,
+			},
+			podSpec: podSpec,
+			meta: &metav1.ObjectMeta{
+				Name:      "policy-disabled-gateway",
+				Namespace: "test-namespace",
+				Labels:    map[string]string{GatewayInjectionLabel: "true"},
+			},
+			want: true,
+		},
+		{
+			config: &Config{
+				Policy: InjectionPolicyEnabled,
+			},
+			podSpec: podSpec,
+			meta: &metav1.ObjectMeta{
+				Name:        "policy-enabled-annotation-false-gateway",
+				Namespace:   "test-namespace",
+				Annotations: map[string]string{annotation.SidecarInject.Name: "false"},
+				Labels:      map[string]string{GatewayInjectionLabel: "true"},
+			},
+			want: false,
+		},
+		{
+			config: &Config{
+				Policy:              InjectionPolicyEnabled,
+				NeverInjectSelector: []metav1.LabelSelector{*parseToLabelSelector(t, "foo")},
+			},
+			podSpec: podSpec,
+			meta: &metav1.ObjectMeta{
+				Name:      "policy-enabled-never-inject-gateway",
+				Namespace: "test-namespace",
+				Labels:    map[string]string{"foo": "", GatewayInjectionLabel: "true"},
+			},
+			want: false,
+		},
 	}
 
 	for _, c := range cases {
@@ -657,7 +697,7 @@ func TestWebhookInject(t *testing.T) {
 						Raw: podJSON,
 					},
 				},
-			})
+			}, "")
 			var prettyPatch bytes.Buffer
 			if err := json.Indent(&prettyPatch, got.Patch, "", "  "); err != nil {
 				t.Fatalf(err.Error())
@@ -667,6 +707,496 @@ func TestWebhookInject(t *testing.T) {
 	}
 }
 
+func TestWebhookInjectRevision(t *testing.T) {
+	wh, cleanup := createTestWebhookFromFile("testdata/webhook/TestWebhookInject_template.yaml", t)
+	defer cleanup()
+	wh.revision = "1-6-canary"
+
+	podYAML := util.ReadFile("testdata/webhook/TestWebhookInject.yaml", t)
+	podJSON, err := yaml.YAMLToJSON(podYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pod corev1.Pod
+	if err := json.Unmarshal(podJSON, &pod); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		revision  string
+		tag       string
+		wantPatch bool
+	}{
+		{name: "unlabeled pod", revision: "", wantPatch: true},
+		{name: "pod of the same revision", revision: "1-6-canary", wantPatch: true},
+		{name: "pod of another revision", revision: "1-5", wantPatch: false},
+		{name: "pod of a tag of the revision", revision: "canary", tag: "canary", wantPatch: true},
+		{name: "pod of a tag of another revision", revision: "stable", tag: "canary", wantPatch: false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := pod.DeepCopy()
+			if c.revision != "" {
+				if p.Labels == nil {
+					p.Labels = map[string]string{}
+				}
+				p.Labels[constants.IstioRevisionLabel] = c.revision
+			}
+			raw, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := wh.inject(&v1beta1.AdmissionReview{
+				Request: &v1beta1.AdmissionRequest{
+					Object: runtime.RawExtension{Raw: raw},
+				},
+			}, c.tag)
+			if !got.Allowed {
+				t.Fatalf("expected the pod to be allowed: %v", got.Result)
+			}
+			if (got.Patch != nil) != c.wantPatch {
+				t.Fatalf("got patch %s, want patch %v", string(got.Patch), c.wantPatch)
+			}
+			if c.wantPatch && c.revision == "" {
+				patch, err := jsonpatch.DecodePatch(got.Patch)
+				if err != nil {
+					t.Fatal(err)
+				}
+				patched, err := patch.Apply(raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var injected corev1.Pod
+				if err := json.Unmarshal(patched, &injected); err != nil {
+					t.Fatal(err)
+				}
+				if rev := injected.Labels[constants.IstioRevisionLabel]; rev != "1-6-canary" {
+					t.Errorf("expected the pod to be labeled with the revision, got %q", rev)
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookInjectNativeSidecar(t *testing.T) {
+	wh, cleanup := createTestWebhookFromFile("testdata/webhook/TestWebhookInject_template.yaml", t)
+	defer cleanup()
+
+	podYAML := util.ReadFile("testdata/webhook/TestWebhookInject.yaml", t)
+	podJSON, err := yaml.YAMLToJSON(podYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pod corev1.Pod
+	if err := json.Unmarshal(podJSON, &pod); err != nil {
+		t.Fatal(err)
+	}
+
+	inject := func(t *testing.T, raw []byte) []byte {
+		t.Helper()
+		got := wh.inject(&v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Object: runtime.RawExtension{Raw: raw},
+			},
+		}, "")
+		if !got.Allowed || got.Patch == nil {
+			t.Fatalf("expected the pod to be injected: %v", got.Result)
+		}
+		patch, err := jsonpatch.DecodePatch(got.Patch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		patched, err := patch.Apply(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return patched
+	}
+
+	cases := []struct {
+		name       string
+		enabled    bool
+		annotation string
+		wantNative bool
+	}{
+		{name: "enabled", enabled: true, wantNative: true},
+		{name: "disabled", enabled: false, wantNative: false},
+		{name: "opted out", enabled: true, annotation: "false", wantNative: false},
+		{name: "opted in", enabled: false, annotation: "true", wantNative: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			wh.nativeSidecars = c.enabled
+			p := pod.DeepCopy()
+			if c.annotation != "" {
+				p.Annotations = map[string]string{constants.SidecarNativeAnnotation: c.annotation}
+			}
+			raw, err := json.Marshal(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patched := inject(t, raw)
+			// injecting the pod again replaces the proxy rather than adding another one
+			patched = inject(t, patched)
+
+			var injected struct {
+				Spec struct {
+					InitContainers []struct {
+						Name          string `json:"name"`
+						RestartPolicy string `json:"restartPolicy"`
+					} `json:"initContainers"`
+					Containers []corev1.Container `json:"containers"`
+				} `json:"spec"`
+			}
+			if err := json.Unmarshal(patched, &injected); err != nil {
+				t.Fatal(err)
+			}
+			var native []string
+			for _, c := range injected.Spec.InitContainers {
+				if c.Name == ProxyContainerName {
+					native = append(native, c.RestartPolicy)
+				}
+			}
+			sidecar := FindSidecar(injected.Spec.Containers)
+			if c.wantNative {
+				if len(native) != 1 || native[0] != "Always" || sidecar != nil {
+					t.Errorf("expected a single native sidecar, got init containers %v and sidecar %v",
+						injected.Spec.InitContainers, sidecar != nil)
+				}
+			} else if len(native) != 0 || sidecar == nil {
+				t.Errorf("expected the proxy in the containers, got init containers %v", injected.Spec.InitContainers)
+			}
+		})
+	}
+}
+
+func TestWebhookInjectTemplates(t *testing.T) {
+	wh, cleanup := createTestWebhookFromFile("testdata/webhook/TestWebhookInject_template.yaml", t)
+	defer cleanup()
+	wh.sidecarConfig.Templates = map[string]string{
+		"gateway": `containers:
+- name: istio-proxy
+  image: example.com/proxyv2:latest
+  args:
+  - router
+`,
+		"gpu": `containers:
+- name: istio-proxy
+  resources:
+    limits:
+      memory: 2Gi
+volumes:
+- name: istio-envoy
+  emptyDir: {}
+- name: gpu-cache
+  emptyDir: {}
+`,
+	}
+
+	cases := []struct {
+		name           string
+		templates      string
+		gateway        bool
+		wantErr        bool
+		wantImage      string
+		wantArgs       []string
+		wantContainers int
+		wantVolumes    []string
+	}{
+		{
+			name:           "default",
+			wantImage:      "example.com/proxy:latest",
+			wantContainers: 1,
+			wantVolumes:    []string{"istio-envoy", "istio-certs"},
+		},
+		{
+			name:           "replaced",
+			templates:      "gateway",
+			wantImage:      "example.com/proxyv2:latest",
+			wantArgs:       []string{"router"},
+			wantContainers: 1,
+		},
+		{
+			name:           "merged",
+			templates:      "sidecar, gpu",
+			wantImage:      "example.com/proxy:latest",
+			wantContainers: 1,
+			wantVolumes:    []string{"istio-envoy", "istio-certs", "gpu-cache"},
+		},
+		{
+			name:      "unknown",
+			templates: "sidecar,debug",
+			wantErr:   true,
+		},
+		{
+			name:           "gateway",
+			gateway:        true,
+			wantImage:      "example.com/proxyv2:latest",
+			wantArgs:       []string{"router"},
+			wantContainers: 1,
+		},
+		{
+			name:           "gateway selecting templates",
+			templates:      "sidecar",
+			gateway:        true,
+			wantImage:      "example.com/proxy:latest",
+			wantContainers: 1,
+			wantVolumes:    []string{"istio-envoy", "istio-certs"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			meta := &metav1.ObjectMeta{Name: "app", Namespace: "default"}
+			if c.templates != "" {
+				meta.Annotations = map[string]string{InjectTemplatesAnnotation: c.templates}
+			}
+			if c.gateway {
+				meta.Labels = map[string]string{GatewayInjectionLabel: "true"}
+			}
+			templates, err := wh.sidecarConfig.selectTemplates(meta)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error selecting %q", c.templates)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			spec, _, err := injectionData(templates, wh.valuesConfig, wh.sidecarTemplateVersion, &metav1.TypeMeta{}, meta,
+				&corev1.PodSpec{}, meta, wh.meshConfig.DefaultConfig, wh.meshConfig, wh.meshExtensions.GetDefaultConfig())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(spec.Containers) != c.wantContainers {
+				t.Fatalf("got %d containers, want %d", len(spec.Containers), c.wantContainers)
+			}
+			proxy := spec.Containers[0]
+			if proxy.Image != c.wantImage {
+				t.Errorf("got image %q, want %q", proxy.Image, c.wantImage)
+			}
+			if strings.Join(proxy.Args, ",") != strings.Join(c.wantArgs, ",") {
+				t.Errorf("got args %v, want %v", proxy.Args, c.wantArgs)
+			}
+			var volumes []string
+			for _, v := range spec.Volumes {
+				volumes = append(volumes, v.Name)
+			}
+			if strings.Join(volumes, ",") != strings.Join(c.wantVolumes, ",") {
+				t.Errorf("got volumes %v, want %v", volumes, c.wantVolumes)
+			}
+			if c.name == "merged" {
+				if proxy.Resources.Limits.Memory().String() != "2Gi" {
+					t.Errorf("expected the memory limit to be merged into the proxy, got %v", proxy.Resources.Limits)
+				}
+				if spec.Volumes[0].EmptyDir == nil || spec.Volumes[0].EmptyDir.Medium != "" {
+					t.Errorf("expected the istio-envoy volume to be replaced, got %v", spec.Volumes[0])
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookInjectGateway(t *testing.T) {
+	wh, cleanup := createTestWebhookFromFile("testdata/webhook/TestWebhookInject_template.yaml", t)
+	defer cleanup()
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "custom-gateway",
+			Namespace: "default",
+			Labels:    map[string]string{GatewayInjectionLabel: "true"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  ProxyContainerName,
+				Image: AutoImage,
+				Ports: []corev1.ContainerPort{{Name: "https", ContainerPort: 8443}},
+			}},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	if got := wh.inject(review, ""); got.Allowed {
+		t.Fatal("expected the gateway to be rejected without a gateway template")
+	}
+
+	wh.sidecarConfig.Templates = map[string]string{
+		GatewayTemplateName: `containers:
+- name: istio-proxy
+  image: example.com/proxyv2:latest
+  args:
+  - proxy
+  - router
+`,
+	}
+	got := wh.inject(review, "")
+	if !got.Allowed || got.Patch == nil {
+		t.Fatalf("expected the gateway to be injected: %v", got.Result)
+	}
+	patch, err := jsonpatch.DecodePatch(got.Patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, err := patch.Apply(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var injected corev1.Pod
+	if err := json.Unmarshal(patched, &injected); err != nil {
+		t.Fatal(err)
+	}
+	if len(injected.Spec.Containers) != 1 {
+		t.Fatalf("expected the istio-proxy container to be completed, got %d containers", len(injected.Spec.Containers))
+	}
+	proxy := injected.Spec.Containers[0]
+	if proxy.Image != "example.com/proxyv2:latest" || strings.Join(proxy.Args, ",") != "proxy,router" {
+		t.Errorf("expected the proxy to come from the gateway template, got %v %v", proxy.Image, proxy.Args)
+	}
+	if len(proxy.Ports) != 1 || proxy.Ports[0].ContainerPort != 8443 {
+		t.Errorf("expected the ports of the gateway to be kept, got %v", proxy.Ports)
+	}
+}
+
+func TestWebhookInjectGRPC(t *testing.T) {
+	wh, cleanup := createTestWebhookFromFile("testdata/webhook/TestWebhookInject_template.yaml", t)
+	defer cleanup()
+	wh.sidecarConfig.Templates = map[string]string{
+		GRPCTemplateName: string(util.ReadFile("../../../install/kubernetes/helm/istio/files/grpc-injection-template.yaml", t)),
+	}
+
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "grpc-app",
+			Namespace:   "default",
+			Annotations: map[string]string{InjectTemplatesAnnotation: GRPCTemplateName},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name:  "app",
+				Image: "example.com/app:latest",
+				Env:   []corev1.EnvVar{{Name: "GREETING", Value: "hello"}},
+			}},
+		},
+	}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	}
+
+	got := wh.inject(review, "")
+	if !got.Allowed || got.Patch == nil {
+		t.Fatalf("expected the gRPC application to be injected: %v", got.Result)
+	}
+	patch, err := jsonpatch.DecodePatch(got.Patch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patched, err := patch.Apply(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var injected corev1.Pod
+	if err := json.Unmarshal(patched, &injected); err != nil {
+		t.Fatal(err)
+	}
+	if len(injected.Spec.Containers) != 1 {
+		t.Fatalf("expected no proxy to be injected, got %d containers", len(injected.Spec.Containers))
+	}
+	app := injected.Spec.Containers[0]
+	if app.Name != "app" || app.Image != "example.com/app:latest" {
+		t.Errorf("expected the application container to be kept, got %v %v", app.Name, app.Image)
+	}
+	env := map[string]string{}
+	for _, e := range app.Env {
+		env[e.Name] = e.Value
+	}
+	if env["GREETING"] != "hello" {
+		t.Errorf("expected the environment of the application to be kept, got %v", app.Env)
+	}
+	bootstrap := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(env["GRPC_XDS_BOOTSTRAP_CONFIG"]), &bootstrap); err != nil {
+		t.Fatalf("invalid xDS bootstrap %q: %v", env["GRPC_XDS_BOOTSTRAP_CONFIG"], err)
+	}
+	if !strings.Contains(env["GRPC_XDS_BOOTSTRAP_CONFIG"], `"GENERATOR": "grpc"`) {
+		t.Errorf("expected the xDS bootstrap to select the gRPC generator, got %v", env["GRPC_XDS_BOOTSTRAP_CONFIG"])
+	}
+	if len(app.VolumeMounts) != 1 || app.VolumeMounts[0].Name != "istio-certs" {
+		t.Errorf("expected the certificates to be mounted, got %v", app.VolumeMounts)
+	}
+	if len(injected.Spec.Volumes) != 1 || injected.Spec.Volumes[0].Name != "istio-certs" {
+		t.Errorf("expected the certificates volume to be added, got %v", injected.Spec.Volumes)
+	}
+}
+
+func TestCompletePodContainers(t *testing.T) {
+	sic := &SidecarInjectionSpec{
+		Containers: []corev1.Container{{
+			Name:  ProxyContainerName,
+			Image: "example.com/proxyv2:latest",
+			Args:  []string{"proxy", "router"},
+			Env:   []corev1.EnvVar{{Name: "POD_NAME", Value: "template"}},
+		}},
+	}
+	pod := []corev1.Container{
+		{Name: "app", Image: "app:latest"},
+		{
+			Name:  ProxyContainerName,
+			Image: AutoImage,
+			Env:   []corev1.EnvVar{{Name: "ISTIO_META_ROUTER_MODE", Value: "sni-dnat"}},
+			Ports: []corev1.ContainerPort{{Name: "https", ContainerPort: 8443}},
+		},
+	}
+
+	merged, err := completePodContainers(pod, nil, sic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(merged, []string{ProxyContainerName}) {
+		t.Fatalf("got merged containers %v, want [%s]", merged, ProxyContainerName)
+	}
+	proxy := sic.Containers[0]
+	if proxy.Image != "example.com/proxyv2:latest" {
+		t.Errorf("got image %q, want the template one", proxy.Image)
+	}
+	if strings.Join(proxy.Args, ",") != "proxy,router" {
+		t.Errorf("got args %v, want the template ones", proxy.Args)
+	}
+	env := map[string]string{}
+	for _, e := range proxy.Env {
+		env[e.Name] = e.Value
+	}
+	if env["POD_NAME"] != "template" || env["ISTIO_META_ROUTER_MODE"] != "sni-dnat" {
+		t.Errorf("expected the env of the pod and the template to be merged, got %v", proxy.Env)
+	}
+	if len(proxy.Ports) != 1 || proxy.Ports[0].ContainerPort != 8443 {
+		t.Errorf("expected the ports of the pod to be kept, got %v", proxy.Ports)
+	}
+
+	// a previously injected container is replaced, not completed
+	merged, err = completePodContainers(pod, []string{ProxyContainerName}, sic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 0 {
+		t.Errorf("got merged containers %v, want none", merged)
+	}
+}
+
 // TestHelmInject tests the webhook injector with the installation configmap.yaml. It runs through many of the
 // same tests as TestIntoResourceFile in order to verify that the webhook performs the same way as the manual injector.
 func TestHelmInject(t *testing.T) {
@@ -799,7 +1329,7 @@ func TestHelmInject(t *testing.T) {
 								Raw: templateJSON,
 							},
 						},
-					})
+					}, "")
 
 					// Apply the generated patch to the template.
 					patch := prettyJSON(got.Patch, t)
@@ -15,6 +15,7 @@
 package secretcontroller
 
 import (
+	"crypto/sha256"
 	"fmt"
 	"time"
 
@@ -68,7 +69,8 @@ type Controller struct {
 
 // RemoteCluster defines cluster structZZ
 type RemoteCluster struct {
-	secretName string
+	secretName    string
+	kubeConfigSha [sha256.Size]byte
 }
 
 // ClusterStore is a collection of clusters
@@ -127,6 +129,16 @@ func NewController(
 				queue.Add(key)
 			}
 		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if oldObj.(*corev1.Secret).ResourceVersion == newObj.(*corev1.Secret).ResourceVersion {
+				return
+			}
+			key, err := cache.MetaNamespaceKeyFunc(newObj)
+			log.Infof("Processing update: %s", key)
+			if err == nil {
+				queue.Add(key)
+			}
+		},
 		DeleteFunc: func(obj interface{}) {
 			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
 			log.Infof("Processing delete: %s", key)
@@ -216,6 +228,22 @@ func (c *Controller) processItem(secretName string) error {
 }
 
 func (c *Controller) addMemberCluster(secretName string, s *corev1.Secret) {
+	// remove the clusters that were dropped from an updated secret or whose kubeconfig changed,
+	// they are added back below with the new kubeconfig.
+	for clusterID, cluster := range c.cs.remoteClusters {
+		if cluster.secretName != secretName {
+			continue
+		}
+		if kubeConfig, ok := s.Data[clusterID]; ok && cluster.kubeConfigSha == sha256.Sum256(kubeConfig) {
+			continue
+		}
+		log.Infof("Deleting cluster member: %s", clusterID)
+		if err := c.removeCallback(clusterID); err != nil {
+			log.Errorf("error during cluster delete: %s %v", clusterID, err)
+		}
+		delete(c.cs.remoteClusters, clusterID)
+	}
+
 	for clusterID, kubeConfig := range s.Data {
 		// clusterID must be unique even across multiple secrets
 		if _, ok := c.cs.remoteClusters[clusterID]; !ok {
@@ -239,8 +267,10 @@ func (c *Controller) addMemberCluster(secretName string, s *corev1.Secret) {
 			}
 
 			log.Infof("Adding new cluster member: %s", clusterID)
-			c.cs.remoteClusters[clusterID] = &RemoteCluster{}
-			c.cs.remoteClusters[clusterID].secretName = secretName
+			c.cs.remoteClusters[clusterID] = &RemoteCluster{
+				secretName:    secretName,
+				kubeConfigSha: sha256.Sum256(kubeConfig),
+			}
 			client, err := CreateInterfaceFromClusterConfig(clientConfig)
 			if err != nil {
 				log.Errorf("error during create of kubernetes client interface for cluster: %s %v", clusterID, err)
@@ -15,6 +15,9 @@
 package secretcontroller
 
 import (
+	"reflect"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -138,3 +141,68 @@ func Test_SecretController(t *testing.T) {
 		t.Fatalf("Test failed on delete secret, create callback function called")
 	}
 }
+
+func Test_SecretControllerUpdate(t *testing.T) {
+	LoadKubeConfig = mockLoadKubeConfig
+	ValidateClientConfig = mockValidateClientConfig
+	CreateInterfaceFromClusterConfig = mockCreateInterfaceFromClusterConfig
+
+	var mu sync.Mutex
+	var added, removed []string
+	addCallback := func(_ kubernetes.Interface, clusterID string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		added = append(added, clusterID)
+		return nil
+	}
+	removeCallback := func(clusterID string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		removed = append(removed, clusterID)
+		return nil
+	}
+	expect := func(name string, wantAdded, wantRemoved []string) {
+		t.Helper()
+		pkgtest.NewEventualOpts(10*time.Millisecond, 5*time.Second).Eventually(t, name, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			sort.Strings(added)
+			sort.Strings(removed)
+			return reflect.DeepEqual(added, wantAdded) && reflect.DeepEqual(removed, wantRemoved)
+		})
+	}
+
+	clientset := fake.NewSimpleClientset()
+	if err := StartSecretController(clientset, addCallback, removeCallback, secretNamespace); err != nil {
+		t.Fatalf("Could not start secret controller: %v", err)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            secretName,
+			Namespace:       secretNamespace,
+			Labels:          map[string]string{MultiClusterSecretLabel: "true"},
+			ResourceVersion: "1",
+		},
+		Data: map[string][]byte{
+			"cluster1": []byte("kubeconfig1"),
+			"cluster2": []byte("kubeconfig2"),
+		},
+	}
+	if _, err := clientset.CoreV1().Secrets(secretNamespace).Create(secret); err != nil {
+		t.Fatalf("Unexpected error on secret create: %v", err)
+	}
+	expect("clusters added", []string{"cluster1", "cluster2"}, nil)
+
+	// drop cluster1 and rotate the kubeconfig of cluster2
+	secret = secret.DeepCopy()
+	secret.ResourceVersion = "2"
+	secret.Data = map[string][]byte{
+		"cluster2": []byte("rotated"),
+		"cluster3": []byte("kubeconfig3"),
+	}
+	if _, err := clientset.CoreV1().Secrets(secretNamespace).Update(secret); err != nil {
+		t.Fatalf("Unexpected error on secret update: %v", err)
+	}
+	expect("clusters updated", []string{"cluster1", "cluster2", "cluster2", "cluster3"}, []string{"cluster1", "cluster2"})
+}
@@ -0,0 +1,108 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"context"
+	"sort"
+
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"google.golang.org/grpc"
+
+	mcp "istio.io/api/mcp/v1alpha1"
+)
+
+// client is an MCP source client receiving the resources over the xDS transport.
+type client struct {
+	ads ads.AggregatedDiscoveryServiceClient
+}
+
+var _ mcp.ResourceSourceClient = &client{}
+
+// NewResourceSourceClient returns an MCP source client receiving the resources over the xDS transport of the
+// connection, to be used by an MCP sink, e.g. a sink.Client.
+func NewResourceSourceClient(conn *grpc.ClientConn) mcp.ResourceSourceClient {
+	return &client{ads: ads.NewAggregatedDiscoveryServiceClient(conn)}
+}
+
+// EstablishResourceStream implements mcp.ResourceSourceClient.
+func (c *client) EstablishResourceStream(ctx context.Context, opts ...grpc.CallOption) (
+	mcp.ResourceSource_EstablishResourceStreamClient, error) {
+	stream, err := c.ads.DeltaAggregatedResources(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &clientStream{
+		ClientStream: stream,
+		stream:       stream,
+		incremental:  make(map[string]bool),
+		resources:    make(map[string]map[string]mcp.Resource),
+	}, nil
+}
+
+// clientStream adapts an xDS stream to the MCP sink, which sends and receives in the same go routine.
+type clientStream struct {
+	grpc.ClientStream
+	stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesClient
+
+	// Whether the sink receives the changes of a collection rather than its full state.
+	incremental map[string]bool
+	// The full state of the collections the sink does not receive incrementally.
+	resources map[string]map[string]mcp.Resource
+}
+
+var _ mcp.ResourceSource_EstablishResourceStreamClient = &clientStream{}
+
+func (c *clientStream) Send(req *mcp.RequestResources) error {
+	c.incremental[req.Collection] = req.Incremental
+	return c.stream.Send(toDeltaRequest(req))
+}
+
+func (c *clientStream) Recv() (*mcp.Resources, error) {
+	res, err := c.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	out, err := fromDeltaResponse(res)
+	if err != nil {
+		return nil, err
+	}
+	if c.incremental[out.Collection] {
+		return out, nil
+	}
+
+	resources := c.resources[out.Collection]
+	if resources == nil {
+		resources = make(map[string]mcp.Resource)
+		c.resources[out.Collection] = resources
+	}
+	for _, name := range out.RemovedResources {
+		delete(resources, name)
+	}
+	for _, r := range out.Resources {
+		resources[r.Metadata.GetName()] = r
+	}
+
+	out.Resources = make([]mcp.Resource, 0, len(resources))
+	for _, r := range resources {
+		out.Resources = append(out.Resources, r)
+	}
+	sort.Slice(out.Resources, func(i, j int) bool {
+		return out.Resources[i].Metadata.GetName() < out.Resources[j].Metadata.GetName()
+	})
+	out.RemovedResources = nil
+	out.Incremental = false
+	return out, nil
+}
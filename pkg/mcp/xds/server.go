@@ -0,0 +1,124 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"sync"
+
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mcp "istio.io/api/mcp/v1alpha1"
+)
+
+// Server serves the resources of an MCP source over the xDS transport.
+type Server struct {
+	source mcp.ResourceSourceServer
+}
+
+var _ ads.AggregatedDiscoveryServiceServer = &Server{}
+
+// NewServer returns a new xDS server of the resources of the MCP source, e.g. a source.Server.
+func NewServer(source mcp.ResourceSourceServer) *Server {
+	return &Server{source: source}
+}
+
+// StreamAggregatedResources implements ads.AggregatedDiscoveryServiceServer. The state of the world variant of
+// the transport cannot remove resources, the resources are only served incrementally.
+func (s *Server) StreamAggregatedResources(ads.AggregatedDiscoveryService_StreamAggregatedResourcesServer) error {
+	return status.Error(codes.Unimplemented, "the MCP resources are only served by DeltaAggregatedResources")
+}
+
+// DeltaAggregatedResources implements ads.AggregatedDiscoveryServiceServer.
+func (s *Server) DeltaAggregatedResources(stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	return s.source.EstablishResourceStream(&serverStream{
+		ServerStream: stream,
+		stream:       stream,
+		sent:         make(map[string]map[string]bool),
+	})
+}
+
+// serverStream adapts an xDS stream to the MCP source.
+type serverStream struct {
+	grpc.ServerStream
+	stream ads.AggregatedDiscoveryService_DeltaAggregatedResourcesServer
+
+	// The source receives and sends in different go routines.
+	mu sync.Mutex
+	// The names of the resources of each collection the sink has, to remove the missing ones from the full-state
+	// responses.
+	sent map[string]map[string]bool
+}
+
+var _ mcp.ResourceSource_EstablishResourceStreamServer = &serverStream{}
+
+func (s *serverStream) Recv() (*mcp.RequestResources, error) {
+	req, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if _, ok := s.sent[req.TypeUrl]; !ok {
+		// The sink keeps the resources it had when reconnecting.
+		names := make(map[string]bool, len(req.InitialResourceVersions))
+		for name := range req.InitialResourceVersions {
+			names[name] = true
+		}
+		s.sent[req.TypeUrl] = names
+	}
+	s.mu.Unlock()
+
+	return fromDeltaRequest(req), nil
+}
+
+func (s *serverStream) Send(res *mcp.Resources) error {
+	s.mu.Lock()
+	names := s.sent[res.Collection]
+	if names == nil {
+		names = make(map[string]bool)
+		s.sent[res.Collection] = names
+	}
+	removed := res.RemovedResources
+	if res.Incremental {
+		for _, name := range removed {
+			delete(names, name)
+		}
+		for i := range res.Resources {
+			names[res.Resources[i].Metadata.GetName()] = true
+		}
+	} else {
+		current := make(map[string]bool, len(res.Resources))
+		for i := range res.Resources {
+			current[res.Resources[i].Metadata.GetName()] = true
+		}
+		removed = nil
+		for _, name := range sortedNames(names) {
+			if !current[name] {
+				removed = append(removed, name)
+			}
+		}
+		s.sent[res.Collection] = current
+	}
+	s.mu.Unlock()
+
+	out, err := toDeltaResponse(res, removed)
+	if err != nil {
+		return err
+	}
+	return s.stream.Send(out)
+}
@@ -0,0 +1,162 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package xds carries the MCP message exchange over the incremental variant of the xDS transport, the
+// DeltaAggregatedResources stream of the aggregated discovery service. It lets a config management service
+// speaking xDS feed the MCP sinks, e.g. Pilot, without an MCP server.
+//
+// The collections are the type URLs of the xDS requests, and each xDS resource wraps an MCP resource, keeping
+// its metadata. The xDS transport is always incremental: a full-state MCP response is sent as the changes
+// since the previous response, and turned back into a full state for the collections a sink does not receive
+// incrementally.
+package xds
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/any"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	core "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+
+	mcp "istio.io/api/mcp/v1alpha1"
+	rpc "istio.io/gogo-genproto/googleapis/google/rpc"
+)
+
+// ResourceTypeURL is the type URL of the xDS resources, which are MCP resources.
+var ResourceTypeURL = "type.googleapis.com/" + proto.MessageName(&mcp.Resource{})
+
+func toDeltaRequest(req *mcp.RequestResources) *xdsapi.DeltaDiscoveryRequest {
+	out := &xdsapi.DeltaDiscoveryRequest{
+		TypeUrl:                 req.Collection,
+		InitialResourceVersions: req.InitialResourceVersions,
+		ResponseNonce:           req.ResponseNonce,
+	}
+	if req.SinkNode != nil {
+		out.Node = &core.Node{
+			Id:       req.SinkNode.Id,
+			Metadata: toStruct(req.SinkNode.Annotations),
+		}
+	}
+	if req.ErrorDetail != nil {
+		out.ErrorDetail = &rpcstatus.Status{
+			Code:    req.ErrorDetail.Code,
+			Message: req.ErrorDetail.Message,
+		}
+	}
+	return out
+}
+
+func fromDeltaRequest(req *xdsapi.DeltaDiscoveryRequest) *mcp.RequestResources {
+	out := &mcp.RequestResources{
+		Collection:              req.TypeUrl,
+		InitialResourceVersions: req.InitialResourceVersions,
+		ResponseNonce:           req.ResponseNonce,
+		// The sources only send the changes to the sinks requesting them.
+		Incremental: true,
+	}
+	if req.Node != nil {
+		out.SinkNode = &mcp.SinkNode{
+			Id:          req.Node.Id,
+			Annotations: fromStruct(req.Node.Metadata),
+		}
+	}
+	if req.ErrorDetail != nil {
+		out.ErrorDetail = &rpc.Status{
+			Code:    req.ErrorDetail.Code,
+			Message: req.ErrorDetail.Message,
+		}
+	}
+	return out
+}
+
+func toDeltaResponse(res *mcp.Resources, removed []string) (*xdsapi.DeltaDiscoveryResponse, error) {
+	out := &xdsapi.DeltaDiscoveryResponse{
+		SystemVersionInfo: res.SystemVersionInfo,
+		TypeUrl:           res.Collection,
+		Resources:         make([]*xdsapi.Resource, 0, len(res.Resources)),
+		RemovedResources:  removed,
+		Nonce:             res.Nonce,
+	}
+	for i := range res.Resources {
+		r := &res.Resources[i]
+		value, err := proto.Marshal(r)
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal resource %s of %s: %v", r.Metadata.GetName(), res.Collection, err)
+		}
+		out.Resources = append(out.Resources, &xdsapi.Resource{
+			Name:     r.Metadata.GetName(),
+			Version:  r.Metadata.GetVersion(),
+			Resource: &any.Any{TypeUrl: ResourceTypeURL, Value: value},
+		})
+	}
+	return out, nil
+}
+
+func fromDeltaResponse(res *xdsapi.DeltaDiscoveryResponse) (*mcp.Resources, error) {
+	out := &mcp.Resources{
+		SystemVersionInfo: res.SystemVersionInfo,
+		Collection:        res.TypeUrl,
+		Resources:         make([]mcp.Resource, len(res.Resources)),
+		RemovedResources:  res.RemovedResources,
+		Nonce:             res.Nonce,
+		Incremental:       true,
+	}
+	for i, r := range res.Resources {
+		if r.Resource.GetTypeUrl() != ResourceTypeURL {
+			return nil, fmt.Errorf("resource %s of %s has type %q, want %q", r.Name, res.TypeUrl, r.Resource.GetTypeUrl(), ResourceTypeURL)
+		}
+		if err := proto.Unmarshal(r.Resource.Value, &out.Resources[i]); err != nil {
+			return nil, fmt.Errorf("cannot unmarshal resource %s of %s: %v", r.Name, res.TypeUrl, err)
+		}
+	}
+	return out, nil
+}
+
+func toStruct(m map[string]string) *structpb.Struct {
+	if len(m) == 0 {
+		return nil
+	}
+	out := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(m))}
+	for k, v := range m {
+		out.Fields[k] = &structpb.Value{Kind: &structpb.Value_StringValue{StringValue: v}}
+	}
+	return out
+}
+
+func fromStruct(s *structpb.Struct) map[string]string {
+	if len(s.GetFields()) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(s.Fields))
+	for k, v := range s.Fields {
+		if sv, ok := v.Kind.(*structpb.Value_StringValue); ok {
+			out[k] = sv.StringValue
+		}
+	}
+	return out
+}
+
+func sortedNames(names map[string]bool) []string {
+	out := make([]string, 0, len(names))
+	for name := range names {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
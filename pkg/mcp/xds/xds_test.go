@@ -0,0 +1,158 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	ads "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"github.com/gogo/protobuf/types"
+	"google.golang.org/grpc"
+
+	"istio.io/istio/pkg/mcp/rate"
+	"istio.io/istio/pkg/mcp/server"
+	"istio.io/istio/pkg/mcp/sink"
+	"istio.io/istio/pkg/mcp/snapshot"
+	"istio.io/istio/pkg/mcp/source"
+	"istio.io/istio/pkg/mcp/testing/groups"
+	"istio.io/istio/pkg/mcp/testing/monitoring"
+	"istio.io/istio/pkg/mcp/xds"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+const (
+	full        = "full"
+	incremental = "incremental"
+)
+
+// updater records the last change of each collection.
+type updater struct {
+	mu      sync.Mutex
+	changes map[string]*sink.Change
+}
+
+func (u *updater) Apply(c *sink.Change) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.changes[c.Collection] = c
+	return nil
+}
+
+func (u *updater) check(collection string, incremental bool, names, removed []string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	c := u.changes[collection]
+	if c == nil {
+		return fmt.Errorf("no change of %s", collection)
+	}
+	var got []string
+	for _, o := range c.Objects {
+		got = append(got, o.Metadata.Name)
+	}
+	if c.Incremental != incremental || !reflect.DeepEqual(got, names) || !reflect.DeepEqual(c.Removed, removed) {
+		return fmt.Errorf("got change of %s incremental=%v objects=%v removed=%v, want incremental=%v objects=%v removed=%v",
+			collection, c.Incremental, got, c.Removed, incremental, names, removed)
+	}
+	return nil
+}
+
+func TestSinkOverXDS(t *testing.T) {
+	cache := snapshot.New(groups.DefaultIndexFn)
+	src := source.NewServer(&source.Options{
+		Watcher: cache,
+		CollectionsOptions: []source.CollectionOptions{
+			{Name: full},
+			{Name: incremental, Incremental: true},
+		},
+		Reporter:        monitoring.NewInMemoryStatsContext(),
+		ConnRateLimiter: rate.NewRateLimiter(time.Second, 100),
+	}, &source.ServerOptions{
+		AuthChecker: server.NewAllowAllChecker(),
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gs := grpc.NewServer()
+	ads.RegisterAggregatedDiscoveryServiceServer(gs, xds.NewServer(src))
+	go func() { _ = gs.Serve(l) }()
+	defer gs.Stop()
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	u := &updater{changes: make(map[string]*sink.Change)}
+	c := sink.NewClient(xds.NewResourceSourceClient(conn), &sink.Options{
+		CollectionOptions: []sink.CollectionOptions{
+			{Name: full},
+			{Name: incremental, Incremental: true},
+		},
+		Updater:  u,
+		ID:       "sink",
+		Reporter: monitoring.NewInMemoryStatsContext(),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.Run(ctx)
+
+	b := snapshot.NewInMemoryBuilder()
+	setEntry(t, b, full, "ns/a", "v1")
+	setEntry(t, b, full, "ns/b", "v1")
+	setEntry(t, b, incremental, "ns/x", "v1")
+	b.SetVersion(full, "1")
+	b.SetVersion(incremental, "1")
+	cache.SetSnapshot(groups.Default, b.Build())
+
+	retry.UntilSuccessOrFail(t, func() error {
+		if err := u.check(full, false, []string{"ns/a", "ns/b"}, nil); err != nil {
+			return err
+		}
+		return u.check(incremental, true, []string{"ns/x"}, nil)
+	}, retry.Delay(10*time.Millisecond))
+
+	// The full state of the first collection is rebuilt from the changes sent over xDS, the second collection
+	// is updated incrementally.
+	b = snapshot.NewInMemoryBuilder()
+	setEntry(t, b, full, "ns/b", "v2")
+	setEntry(t, b, full, "ns/c", "v1")
+	setEntry(t, b, incremental, "ns/y", "v1")
+	b.SetVersion(full, "2")
+	b.SetVersion(incremental, "2")
+	cache.SetSnapshot(groups.Default, b.Build())
+
+	retry.UntilSuccessOrFail(t, func() error {
+		if err := u.check(full, false, []string{"ns/b", "ns/c"}, nil); err != nil {
+			return err
+		}
+		return u.check(incremental, true, []string{"ns/y"}, []string{"ns/x"})
+	}, retry.Delay(10*time.Millisecond))
+}
+
+func setEntry(t *testing.T, b *snapshot.InMemoryBuilder, collection, name, version string) {
+	t.Helper()
+	if err := b.SetEntry(collection, name, version, time.Time{}, nil, nil, &types.StringValue{Value: name}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -34,8 +34,19 @@ var (
 	statusCodeFieldRegex     = regexp.MustCompile(string(response.StatusCodeField) + "=(.*)")
 	hostFieldRegex           = regexp.MustCompile(string(response.HostField) + "=(.*)")
 	hostnameFieldRegex       = regexp.MustCompile(string(response.HostnameField) + "=(.*)")
+	// The fields below are matched at the beginning of a word, so that they don't match the headers
+	// ending with the same name, e.g. X-Forwarded-Proto.
+	protocolFieldRegex            = fieldRegex(response.ProtocolField)
+	alpnFieldRegex                = fieldRegex(response.AlpnField)
+	sourceIdentityFieldRegex      = fieldRegex(response.SourceIdentityField)
+	destinationIdentityFieldRegex = fieldRegex(response.DestinationIdentityField)
+	peerCertSANFieldRegex         = fieldRegex(response.PeerCertSANField)
 )
 
+func fieldRegex(field response.Field) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|\s)` + string(field) + "=(.*)")
+}
+
 // ParsedResponse represents a response to a single echo request.
 type ParsedResponse struct {
 	// Body is the body of the response
@@ -52,6 +63,18 @@ type ParsedResponse struct {
 	Host string
 	// Hostname is the host that responded to the request
 	Hostname string
+	// Protocol is the protocol of the request received by the server, e.g. HTTP/1.1, HTTP/2.0, GRPC
+	// or TCP
+	Protocol string
+	// Alpn is the application protocol negotiated with the server, when the server terminates TLS
+	Alpn string
+	// SourceIdentity is the identity of the client, when the sidecar of the server terminates mTLS
+	SourceIdentity string
+	// DestinationIdentity is the identity of the server, when its sidecar terminates mTLS
+	DestinationIdentity string
+	// PeerCertSANs are the subject alternative names of the client certificate, when the server
+	// terminates TLS
+	PeerCertSANs []string
 }
 
 // IsOK indicates whether or not the code indicates a successful request.
@@ -145,6 +168,60 @@ func (r ParsedResponses) CheckPortOrFail(t test.Failer, expected int) ParsedResp
 	return r
 }
 
+func (r ParsedResponses) CheckProtocol(expected string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.Protocol != expected {
+			return fmt.Errorf("response[%d] Protocol: expected %s, received %s", i, expected, response.Protocol)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckProtocolOrFail(t test.Failer, expected string) ParsedResponses {
+	t.Helper()
+	if err := r.CheckProtocol(expected); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// CheckMTLS checks that the sidecar of the server terminated mTLS, with the given client identity.
+func (r ParsedResponses) CheckMTLS(sourceIdentity string) error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.SourceIdentity != sourceIdentity {
+			return fmt.Errorf("response[%d] SourceIdentity: expected %q, received %q",
+				i, sourceIdentity, response.SourceIdentity)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckMTLSOrFail(t test.Failer, sourceIdentity string) ParsedResponses {
+	t.Helper()
+	if err := r.CheckMTLS(sourceIdentity); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// CheckPlaintext checks that the request reached the server without being sent over mTLS.
+func (r ParsedResponses) CheckPlaintext() error {
+	return r.Check(func(i int, response *ParsedResponse) error {
+		if response.SourceIdentity != "" {
+			return fmt.Errorf("response[%d] SourceIdentity: expected none, received %q", i, response.SourceIdentity)
+		}
+		return nil
+	})
+}
+
+func (r ParsedResponses) CheckPlaintextOrFail(t test.Failer) ParsedResponses {
+	t.Helper()
+	if err := r.CheckPlaintext(); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
 // Count occurrences of the given text within the bodies of all responses.
 func (r ParsedResponses) Count(text string) int {
 	count := 0
@@ -197,5 +274,29 @@ func parseResponse(output string) *ParsedResponse {
 		out.Hostname = match[1]
 	}
 
+	match = protocolFieldRegex.FindStringSubmatch(output)
+	if match != nil {
+		out.Protocol = match[1]
+	}
+
+	match = alpnFieldRegex.FindStringSubmatch(output)
+	if match != nil {
+		out.Alpn = match[1]
+	}
+
+	match = sourceIdentityFieldRegex.FindStringSubmatch(output)
+	if match != nil {
+		out.SourceIdentity = match[1]
+	}
+
+	match = destinationIdentityFieldRegex.FindStringSubmatch(output)
+	if match != nil {
+		out.DestinationIdentity = match[1]
+	}
+
+	for _, match := range peerCertSANFieldRegex.FindAllStringSubmatch(output, -1) {
+		out.PeerCertSANs = append(out.PeerCertSANs, match[1])
+	}
+
 	return &out
 }
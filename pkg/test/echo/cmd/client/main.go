@@ -42,6 +42,7 @@ var (
 	headerVal string
 	headers   string
 	msg       string
+	http2     bool
 
 	caFile string
 
@@ -117,7 +118,9 @@ func init() {
 		"A list of http headers (use Host for authority) - name:value[,name:value]*")
 	rootCmd.PersistentFlags().StringVar(&caFile, "ca", "/cert.crt", "CA root cert file")
 	rootCmd.PersistentFlags().StringVar(&msg, "msg", "HelloWorld",
-		"message to send (for websockets)")
+		"message to send (for websockets and TCP)")
+	rootCmd.PersistentFlags().BoolVar(&http2, "http2", false,
+		"send HTTP requests with HTTP/2 (h2c for http URLs)")
 
 	loggingOptions.AttachCobraFlags(rootCmd)
 
@@ -131,6 +134,7 @@ func getRequest() (*proto.ForwardEchoRequest, error) {
 		Count:         int32(count),
 		Qps:           int32(qps),
 		Message:       msg,
+		Http2:         http2,
 	}
 
 	// Old http add header - deprecated
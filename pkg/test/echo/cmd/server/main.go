@@ -22,9 +22,9 @@ import (
 
 	"github.com/spf13/cobra"
 
-	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/cmd"
 	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/server"
 	"istio.io/pkg/log"
 )
@@ -32,6 +32,8 @@ import (
 var (
 	httpPorts []int
 	grpcPorts []int
+	tcpPorts  []int
+	tlsPorts  []int
 	uds       string
 	version   string
 	crt       string
@@ -46,23 +48,34 @@ var (
 		Long:              `Echo application for testing Istio E2E`,
 		PersistentPreRunE: configureLogging,
 		Run: func(cmd *cobra.Command, args []string) {
-			ports := make(model.PortList, len(httpPorts)+len(grpcPorts))
-			portIndex := 0
+			ports := make(common.PortList, 0, len(httpPorts)+len(grpcPorts)+len(tcpPorts))
 			for i, p := range httpPorts {
-				ports[portIndex] = &model.Port{
+				ports = append(ports, &common.Port{
 					Name:     "http-" + strconv.Itoa(i),
 					Protocol: protocol.HTTP,
 					Port:     p,
-				}
-				portIndex++
+				})
 			}
 			for i, p := range grpcPorts {
-				ports[portIndex] = &model.Port{
+				ports = append(ports, &common.Port{
 					Name:     "grpc-" + strconv.Itoa(i),
 					Protocol: protocol.GRPC,
 					Port:     p,
+				})
+			}
+			for i, p := range tcpPorts {
+				ports = append(ports, &common.Port{
+					Name:     "tcp-" + strconv.Itoa(i),
+					Protocol: protocol.TCP,
+					Port:     p,
+				})
+			}
+			for _, p := range ports {
+				for _, tlsPort := range tlsPorts {
+					if p.Port == tlsPort {
+						p.TLS = true
+					}
 				}
-				portIndex++
 			}
 
 			s := server.New(server.Config{
@@ -99,10 +112,13 @@ func configureLogging(_ *cobra.Command, _ []string) error {
 func init() {
 	rootCmd.PersistentFlags().IntSliceVar(&httpPorts, "port", []int{8080}, "HTTP/1.1 ports")
 	rootCmd.PersistentFlags().IntSliceVar(&grpcPorts, "grpc", []int{7070}, "GRPC ports")
+	rootCmd.PersistentFlags().IntSliceVar(&tcpPorts, "tcp", []int{}, "TCP ports, also serving the HTTP requests")
+	rootCmd.PersistentFlags().IntSliceVar(&tlsPorts, "tls", []int{},
+		"HTTP and GRPC ports terminating TLS with the --crt and --key certificate")
 	rootCmd.PersistentFlags().StringVar(&uds, "uds", "", "HTTP server on unix domain socket")
 	rootCmd.PersistentFlags().StringVar(&version, "version", "", "Version string")
-	rootCmd.PersistentFlags().StringVar(&crt, "crt", "", "gRPC TLS server-side certificate")
-	rootCmd.PersistentFlags().StringVar(&key, "key", "", "gRPC TLS server-side key")
+	rootCmd.PersistentFlags().StringVar(&crt, "crt", "", "TLS server-side certificate")
+	rootCmd.PersistentFlags().StringVar(&key, "key", "", "TLS server-side key")
 
 	loggingOptions.AttachCobraFlags(rootCmd)
 
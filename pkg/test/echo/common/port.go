@@ -0,0 +1,38 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package common
+
+import (
+	"istio.io/istio/pkg/config/protocol"
+)
+
+// Port represents a network port where the Echo server listens.
+type Port struct {
+	// Name of the port.
+	Name string
+
+	// Protocol served on the port.
+	Protocol protocol.Instance
+
+	// Port number. If 0, a free port is picked and stored here when the server starts.
+	Port int
+
+	// TLS indicates that the server terminates TLS on the port, with its certificate. Only
+	// supported for the HTTP and gRPC ports.
+	TLS bool
+}
+
+// PortList is a set of ports
+type PortList []*Port
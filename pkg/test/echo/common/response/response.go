@@ -35,4 +35,19 @@ const (
 	StatusCodeField     Field = "StatusCode"
 	HostField           Field = "Host"
 	HostnameField       Field = "Hostname"
+
+	// ProtocolField is the protocol of the request received by the server, e.g. HTTP/1.1, HTTP/2.0,
+	// GRPC or TCP.
+	ProtocolField Field = "Proto"
+	// AlpnField is the application protocol negotiated by the TLS handshake, when the server
+	// terminates TLS.
+	AlpnField Field = "Alpn"
+	// SourceIdentityField is the identity of the client, as forwarded by the sidecar terminating mTLS.
+	SourceIdentityField Field = "SourceIdentity"
+	// DestinationIdentityField is the identity of the server, as forwarded by the sidecar
+	// terminating mTLS.
+	DestinationIdentityField Field = "DestinationIdentity"
+	// PeerCertSANField is a subject alternative name of the certificate of the client, when the server
+	// terminates TLS. It appears once per name.
+	PeerCertSANField Field = "PeerCertSAN"
 )
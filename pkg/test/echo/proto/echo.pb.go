@@ -155,6 +155,7 @@ type ForwardEchoRequest struct {
 	Url                  string    `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
 	Headers              []*Header `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty"`
 	Message              string    `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
+	Http2                bool      `protobuf:"varint,7,opt,name=http2,proto3" json:"http2,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
 	XXX_unrecognized     []byte    `json:"-"`
 	XXX_sizecache        int32     `json:"-"`
@@ -227,6 +228,13 @@ func (m *ForwardEchoRequest) GetMessage() string {
 	return ""
 }
 
+func (m *ForwardEchoRequest) GetHttp2() bool {
+	if m != nil {
+		return m.Http2
+	}
+	return false
+}
+
 type ForwardEchoResponse struct {
 	Output               []string `protobuf:"bytes,1,rep,name=output,proto3" json:"output,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -277,26 +285,27 @@ func init() {
 func init() { proto.RegisterFile("echo.proto", fileDescriptor_08134aea513e0001) }
 
 var fileDescriptor_08134aea513e0001 = []byte{
-	// 301 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x50, 0xcb, 0x4a, 0xf3, 0x40,
-	0x18, 0x25, 0x7f, 0x3a, 0x29, 0xfd, 0xfa, 0x57, 0x65, 0x5a, 0x64, 0xec, 0xaa, 0x04, 0xa4, 0xd9,
-	0x58, 0xa5, 0xbe, 0x82, 0x8a, 0x1b, 0x37, 0xa3, 0x7b, 0x89, 0xe9, 0x87, 0x09, 0x26, 0x9d, 0x74,
-	0x2e, 0x15, 0xdf, 0xc0, 0x47, 0xf2, 0xf1, 0x64, 0x2e, 0x85, 0x04, 0xc5, 0x55, 0xe6, 0x5c, 0x72,
-	0xe6, 0xcc, 0x01, 0xc0, 0xa2, 0x14, 0xab, 0x56, 0x0a, 0x2d, 0x28, 0x71, 0x9f, 0x74, 0x09, 0xe3,
-	0xdb, 0xa2, 0x14, 0x1c, 0x77, 0x06, 0x95, 0xa6, 0x0c, 0x86, 0x0d, 0x2a, 0x95, 0xbf, 0x22, 0x8b,
-	0x16, 0x51, 0x36, 0xe2, 0x07, 0x98, 0x66, 0xf0, 0xdf, 0x1b, 0x55, 0x2b, 0xb6, 0x0a, 0xff, 0x70,
-	0x5e, 0x41, 0x72, 0x8f, 0xf9, 0x06, 0x25, 0x3d, 0x81, 0xf8, 0x0d, 0x3f, 0x82, 0x6e, 0x8f, 0x74,
-	0x06, 0x64, 0x9f, 0xd7, 0x06, 0xd9, 0x3f, 0xc7, 0x79, 0x90, 0x7e, 0x45, 0x40, 0xef, 0x84, 0x7c,
-	0xcf, 0xe5, 0xa6, 0x5b, 0x66, 0x06, 0xa4, 0x10, 0x66, 0xab, 0x5d, 0x00, 0xe1, 0x1e, 0xd8, 0xd0,
-	0x5d, 0xab, 0x5c, 0x00, 0xe1, 0xf6, 0x48, 0xcf, 0xe1, 0x48, 0x57, 0x0d, 0x0a, 0xa3, 0x9f, 0x9b,
-	0xaa, 0x90, 0x42, 0xb1, 0x78, 0x11, 0x65, 0x31, 0x9f, 0x04, 0xf6, 0xc1, 0x91, 0xf6, 0x47, 0x23,
-	0x6b, 0x36, 0xf0, 0x6d, 0x8c, 0xac, 0xe9, 0x12, 0x86, 0xa5, 0x6b, 0xaa, 0x18, 0x59, 0xc4, 0xd9,
-	0x78, 0x3d, 0xf1, 0xe3, 0xac, 0x7c, 0x7f, 0x7e, 0x50, 0xbb, 0x8f, 0x4d, 0xfa, 0x8f, 0xbd, 0x80,
-	0x69, 0xaf, 0x79, 0x58, 0xe7, 0x14, 0x12, 0x61, 0x74, 0x6b, 0x6c, 0xf7, 0x38, 0x1b, 0xf1, 0x80,
-	0xd6, 0x9f, 0x11, 0x1c, 0x5b, 0xe3, 0x13, 0x2a, 0xfd, 0x88, 0x72, 0x5f, 0x15, 0x48, 0x2f, 0x61,
-	0x60, 0x29, 0x4a, 0xc3, 0xe5, 0x9d, 0x09, 0xe6, 0xd3, 0x1e, 0x17, 0xc2, 0x6f, 0x60, 0xdc, 0xb9,
-	0x93, 0x9e, 0x05, 0xcf, 0xcf, 0x05, 0xe7, 0xf3, 0xdf, 0x24, 0x9f, 0xf2, 0x92, 0x38, 0xe9, 0xfa,
-	0x3b, 0x00, 0x00, 0xff, 0xff, 0x11, 0x07, 0xc8, 0x61, 0x15, 0x02, 0x00, 0x00,
+	// 311 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x50, 0x4d, 0x4f, 0xc2, 0x40,
+	0x14, 0x4c, 0x2d, 0x5b, 0xe4, 0x21, 0x6a, 0x16, 0x62, 0x56, 0x4e, 0x4d, 0x13, 0xc3, 0x5e, 0x44,
+	0x83, 0x7f, 0x41, 0x8d, 0x17, 0x2f, 0xab, 0x77, 0x53, 0xcb, 0x8b, 0x25, 0x02, 0x5b, 0xf6, 0x03,
+	0xe3, 0x3f, 0xf0, 0xd7, 0xf9, 0x9b, 0xcc, 0x7e, 0x90, 0x94, 0x68, 0x3c, 0xf5, 0xcd, 0xbc, 0xd7,
+	0xd9, 0x99, 0x01, 0xc0, 0xaa, 0x96, 0xd3, 0x46, 0x49, 0x23, 0x29, 0xf1, 0x9f, 0x62, 0x02, 0xfd,
+	0xbb, 0xaa, 0x96, 0x02, 0x37, 0x16, 0xb5, 0xa1, 0x0c, 0xba, 0x2b, 0xd4, 0xba, 0x7c, 0x43, 0x96,
+	0xe4, 0x09, 0xef, 0x89, 0x1d, 0x2c, 0x38, 0x1c, 0x85, 0x43, 0xdd, 0xc8, 0xb5, 0xc6, 0x7f, 0x2e,
+	0xaf, 0x21, 0x7b, 0xc0, 0x72, 0x8e, 0x8a, 0x9e, 0x42, 0xfa, 0x8e, 0x9f, 0x71, 0xef, 0x46, 0x3a,
+	0x02, 0xb2, 0x2d, 0x97, 0x16, 0xd9, 0x81, 0xe7, 0x02, 0x28, 0xbe, 0x13, 0xa0, 0xf7, 0x52, 0x7d,
+	0x94, 0x6a, 0xde, 0x36, 0x33, 0x02, 0x52, 0x49, 0xbb, 0x36, 0x5e, 0x80, 0x88, 0x00, 0x9c, 0xe8,
+	0xa6, 0xd1, 0x5e, 0x80, 0x08, 0x37, 0xd2, 0x0b, 0x38, 0x36, 0x8b, 0x15, 0x4a, 0x6b, 0x5e, 0x56,
+	0x8b, 0x4a, 0x49, 0xcd, 0xd2, 0x3c, 0xe1, 0xa9, 0x18, 0x44, 0xf6, 0xd1, 0x93, 0xee, 0x47, 0xab,
+	0x96, 0xac, 0x13, 0xdc, 0x58, 0xb5, 0xa4, 0x13, 0xe8, 0xd6, 0xde, 0xa9, 0x66, 0x24, 0x4f, 0x79,
+	0x7f, 0x36, 0x08, 0xe5, 0x4c, 0x83, 0x7f, 0xb1, 0xdb, 0xb6, 0xc3, 0x66, 0x7b, 0x61, 0x9d, 0xc7,
+	0xda, 0x98, 0x66, 0xc6, 0xba, 0x79, 0xc2, 0x0f, 0x45, 0x00, 0xc5, 0x25, 0x0c, 0xf7, 0xf2, 0xc4,
+	0xce, 0xce, 0x20, 0x93, 0xd6, 0x34, 0xd6, 0x25, 0x4a, 0x79, 0x4f, 0x44, 0x34, 0xfb, 0x4a, 0xe0,
+	0xc4, 0x1d, 0x3e, 0xa3, 0x36, 0x4f, 0xa8, 0xb6, 0x8b, 0x0a, 0xe9, 0x15, 0x74, 0x1c, 0x45, 0x69,
+	0xb4, 0xd4, 0x2a, 0x66, 0x3c, 0xdc, 0xe3, 0xa2, 0xf8, 0x2d, 0xf4, 0x5b, 0x6f, 0xd2, 0xf3, 0x78,
+	0xf3, 0xbb, 0xd7, 0xf1, 0xf8, 0xaf, 0x55, 0x50, 0x79, 0xcd, 0xfc, 0xea, 0xe6, 0x67, 0x00, 0x1d,
+	0x97, 0xd3, 0x05, 0x2b, 0x02, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
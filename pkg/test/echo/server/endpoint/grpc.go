@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 
 	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/common/response"
@@ -57,7 +58,7 @@ func (s *grpcInstance) Start(onReady OnReadyFunc) error {
 	s.Port.Port = p
 	fmt.Printf("Listening GRPC on %v\n", p)
 
-	if s.TLSCert != "" && s.TLSKey != "" {
+	if s.Port.TLS {
 		// Create the TLS credentials
 		creds, errCreds := credentials.NewServerTLSFromFile(s.TLSCert, s.TLSKey)
 		if errCreds != nil {
@@ -148,6 +149,15 @@ func (h *grpcHandler) Echo(ctx context.Context, req *proto.EchoRequest) (*proto.
 	writeField(&body, response.StatusCodeField, response.StatusCodeOK)
 	writeField(&body, response.ServiceVersionField, h.Version)
 	writeField(&body, response.ServicePortField, strconv.Itoa(portNumber))
+	writeField(&body, response.ProtocolField, "GRPC")
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			writeTLSFields(&body, &info.State)
+		}
+	}
+	if xfcc := md.Get("x-forwarded-client-cert"); len(xfcc) > 0 {
+		writeIdentityFields(&body, xfcc[0])
+	}
 	writeField(&body, response.Field("Echo"), req.GetMessage())
 
 	if hostname, err := os.Hostname(); err == nil {
@@ -17,6 +17,7 @@ package endpoint
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"math/rand"
 	"net"
@@ -27,6 +28,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/common/response"
@@ -63,9 +66,20 @@ func newHTTP(config Config) Instance {
 
 func (s *httpInstance) Start(onReady OnReadyFunc) error {
 	s.server = &http.Server{
-		Handler: &httpHandler{
-			Config: s.Config,
-		},
+		Handler: newHTTPHandler(s.Config),
+	}
+
+	if s.isTLS() {
+		cert, err := tls.LoadX509KeyPair(s.TLSCert, s.TLSKey)
+		if err != nil {
+			return fmt.Errorf("could not load TLS keys: %v", err)
+		}
+		s.server.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			NextProtos:   []string{http2.NextProtoTLS, "http/1.1"},
+			// Request the client certificate, to report its SANs in the response.
+			ClientAuth: tls.RequestClientCert,
+		}
 	}
 
 	var listener net.Listener
@@ -87,14 +101,21 @@ func (s *httpInstance) Start(onReady OnReadyFunc) error {
 
 	if s.isUDS() {
 		fmt.Printf("Listening HTTP/1.1 on %v\n", s.UDSServer)
+	} else if s.isTLS() {
+		s.server.Addr = fmt.Sprintf(":%d", port)
+		fmt.Printf("Listening HTTPS/1.1 and HTTPS/2 on %v\n", port)
 	} else {
 		s.server.Addr = fmt.Sprintf(":%d", port)
-		fmt.Printf("Listening HTTP/1.1 on %v\n", port)
+		fmt.Printf("Listening HTTP/1.1 and HTTP/2 (h2c) on %v\n", port)
 	}
 
 	// Start serving HTTP traffic.
 	go func() {
-		_ = s.server.Serve(listener)
+		if s.isTLS() {
+			_ = s.server.ServeTLS(listener, "", "")
+		} else {
+			_ = s.server.Serve(listener)
+		}
 	}()
 
 	// Notify the WaitGroup once the port has transitioned to ready.
@@ -107,6 +128,10 @@ func (s *httpInstance) isUDS() bool {
 	return s.UDSServer != ""
 }
 
+func (s *httpInstance) isTLS() bool {
+	return s.Port != nil && s.Port.TLS
+}
+
 func (s *httpInstance) awaitReady(onReady OnReadyFunc, port int) {
 	defer onReady()
 
@@ -119,15 +144,23 @@ func (s *httpInstance) awaitReady(onReady OnReadyFunc, port int) {
 				return net.Dial("unix", s.UDSServer)
 			},
 		}
+	} else if s.isTLS() {
+		url = fmt.Sprintf("https://127.0.0.1:%d", port)
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
 	} else {
 		url = fmt.Sprintf("http://127.0.0.1:%d", port)
 	}
 
 	err := retry.UntilSuccess(func() error {
-		resp, err := http.Get(url)
+		resp, err := client.Get(url)
 		if err != nil {
 			return err
 		}
+		_ = resp.Body.Close()
 
 		// The handler applies server readiness when handling HTTP requests. Since the
 		// server won't become ready until all endpoints (including this one) report
@@ -158,6 +191,12 @@ type httpHandler struct {
 	Config
 }
 
+// newHTTPHandler returns the handler of the HTTP requests of the endpoint, which serves HTTP/1.1 and,
+// over TLS or with prior knowledge (h2c), HTTP/2.
+func newHTTPHandler(cfg Config) http.Handler {
+	return h2c.NewHandler(&httpHandler{Config: cfg}, &http2.Server{})
+}
+
 // Imagine a pie of different flavors.
 // The flavors are the HTTP response codes.
 // The chance of a particular flavor is ( slices / sum of slices ).
@@ -263,9 +302,11 @@ func (h *httpHandler) addResponsePayload(r *http.Request, body *bytes.Buffer) {
 
 	writeField(body, response.Field("Method"), r.Method)
 	writeField(body, response.Field("URL"), r.URL.String())
-	writeField(body, response.Field("Proto"), r.Proto)
+	writeField(body, response.ProtocolField, r.Proto)
 	writeField(body, response.Field("RemoteAddr"), r.RemoteAddr)
 	writeField(body, response.Field("Method"), r.Method)
+	writeTLSFields(body, r.TLS)
+	writeIdentityFields(body, r.Header.Get("X-Forwarded-Client-Cert"))
 
 	for name, values := range r.Header {
 		for _, value := range values {
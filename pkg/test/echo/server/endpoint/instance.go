@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"io"
 
-	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/test/echo/common"
 )
@@ -37,7 +36,7 @@ type Config struct {
 	TLSKey        string
 	UDSServer     string
 	Dialer        common.Dialer
-	Port          *model.Port
+	Port          *common.Port
 }
 
 // Instance of an endpoint that serves the Echo application on a single port/protocol.
@@ -50,8 +49,10 @@ type Instance interface {
 func New(cfg Config) (Instance, error) {
 	if cfg.Port != nil {
 		switch cfg.Port.Protocol {
-		case protocol.TCP, protocol.HTTP, protocol.HTTPS:
+		case protocol.HTTP, protocol.HTTPS:
 			return newHTTP(cfg), nil
+		case protocol.TCP:
+			return newTCP(cfg), nil
 		case protocol.HTTP2, protocol.GRPC:
 			return newGRPC(cfg), nil
 		default:
@@ -0,0 +1,228 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package endpoint
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"istio.io/istio/pkg/test/echo/common/response"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/pkg/log"
+)
+
+// httpPrefixes are the beginnings of the requests of HTTP/1.x and of the connection preface of
+// HTTP/2 with prior knowledge (h2c).
+var httpPrefixes = []string{
+	"GET ", "HEAD ", "POST ", "PUT ", "DELETE ", "OPTIONS ", "PATCH ", "CONNECT ", "TRACE ",
+	"PRI * HTTP/2.0",
+}
+
+var _ Instance = &tcpInstance{}
+
+// tcpInstance echoes the data received on a TCP port. The HTTP requests received on the port are
+// served as on an HTTP port, so that the tests can call the port with either protocol and check
+// which one the proxies selected.
+type tcpInstance struct {
+	Config
+	listener     net.Listener
+	httpListener *connListener
+	httpServer   *http.Server
+}
+
+func newTCP(config Config) Instance {
+	return &tcpInstance{
+		Config: config,
+	}
+}
+
+func (s *tcpInstance) Start(onReady OnReadyFunc) error {
+	// Listen on the given port and update the port if it changed from what was passed in.
+	listener, port, err := listenOnPort(s.Port.Port)
+	if err != nil {
+		return err
+	}
+	// Store the actual listening port back to the argument.
+	s.Port.Port = port
+	s.listener = listener
+	fmt.Printf("Listening TCP on %v\n", port)
+
+	s.httpListener = newConnListener(listener.Addr())
+	s.httpServer = &http.Server{
+		Handler: newHTTPHandler(s.Config),
+	}
+	go func() {
+		_ = s.httpServer.Serve(s.httpListener)
+	}()
+
+	// Start serving TCP traffic.
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	// Notify the WaitGroup once the port has transitioned to ready.
+	go s.awaitReady(onReady, listener)
+
+	return nil
+}
+
+// handle echoes the first chunk of data received on the connection, or hands the connection to the
+// HTTP server if the data is the beginning of an HTTP request.
+func (s *tcpInstance) handle(conn net.Conn) {
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		if err != io.EOF {
+			log.Warnf("TCP read failed: %v", err)
+		}
+		_ = conn.Close()
+		return
+	}
+	data := buf[:n]
+
+	if isHTTP(data) {
+		s.httpListener.serve(&prefixedConn{Conn: conn, prefix: data})
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	log.Infof("TCP Request:\n  RemoteAddr: %s\n  Data: %q", conn.RemoteAddr(), data)
+
+	body := bytes.Buffer{}
+	writeField(&body, response.StatusCodeField, response.StatusCodeOK)
+	writeField(&body, response.ServiceVersionField, s.Version)
+	writeField(&body, response.ServicePortField, strconv.Itoa(s.Port.Port))
+	writeField(&body, response.ProtocolField, "TCP")
+	writeField(&body, response.Field("RemoteAddr"), conn.RemoteAddr().String())
+	if hostname, err := os.Hostname(); err == nil {
+		writeField(&body, response.HostnameField, hostname)
+	}
+	writeField(&body, response.Field("Echo"), strings.TrimRight(string(data), "\r\n"))
+
+	if _, err := conn.Write(body.Bytes()); err != nil {
+		log.Warnf("TCP write failed: %v", err)
+	}
+}
+
+func isHTTP(data []byte) bool {
+	for _, prefix := range httpPrefixes {
+		if bytes.HasPrefix(data, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *tcpInstance) awaitReady(onReady OnReadyFunc, listener net.Listener) {
+	defer onReady()
+
+	address := listener.Addr().String()
+	err := retry.UntilSuccess(func() error {
+		conn, err := net.DialTimeout("tcp", address, readyInterval)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}, retry.Timeout(readyTimeout), retry.Delay(readyInterval))
+	if err != nil {
+		log.Errorf("readiness failed for TCP endpoint %s: %v", address, err)
+	} else {
+		log.Infof("ready for TCP endpoint %s", address)
+	}
+}
+
+func (s *tcpInstance) Close() error {
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	if s.httpServer != nil {
+		return s.httpServer.Close()
+	}
+	return nil
+}
+
+// prefixedConn is a connection whose first bytes were already read.
+type prefixedConn struct {
+	net.Conn
+	prefix []byte
+}
+
+func (c *prefixedConn) Read(b []byte) (int, error) {
+	if len(c.prefix) > 0 {
+		n := copy(b, c.prefix)
+		c.prefix = c.prefix[n:]
+		return n, nil
+	}
+	return c.Conn.Read(b)
+}
+
+// connListener is a net.Listener accepting the connections handed to it.
+type connListener struct {
+	addr      net.Addr
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newConnListener(addr net.Addr) *connListener {
+	return &connListener{
+		addr:   addr,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+}
+
+// serve hands the connection to the server accepting the connections of the listener.
+func (l *connListener) serve(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		_ = conn.Close()
+	}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, errors.New("listener closed")
+	}
+}
+
+func (l *connListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
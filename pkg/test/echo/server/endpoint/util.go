@@ -16,9 +16,11 @@ package endpoint
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"os"
+	"strings"
 
 	"istio.io/istio/pkg/test/echo/common/response"
 )
@@ -47,3 +49,67 @@ func listenOnUDS(uds string) (net.Listener, error) {
 func writeField(out *bytes.Buffer, field response.Field, value string) {
 	_, _ = out.WriteString(string(field) + "=" + value + "\n")
 }
+
+// writeIdentityFields writes the identities of the client and of the server that the sidecar of the
+// server forwards in the x-forwarded-client-cert header when it terminates mTLS.
+func writeIdentityFields(out *bytes.Buffer, xfcc string) {
+	if xfcc == "" {
+		return
+	}
+	// Each proxy terminating mTLS appends an element: the last one is set by the sidecar of the server.
+	elements := splitUnquoted(xfcc, ',')
+	for _, pair := range splitUnquoted(elements[len(elements)-1], ';') {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		value := strings.Trim(kv[1], `"`)
+		switch strings.ToLower(strings.TrimSpace(kv[0])) {
+		case "uri":
+			writeField(out, response.SourceIdentityField, value)
+		case "by":
+			writeField(out, response.DestinationIdentityField, value)
+		}
+	}
+}
+
+// writeTLSFields writes the negotiated protocol and the subject alternative names of the client
+// certificate of a connection on which the server terminates TLS.
+func writeTLSFields(out *bytes.Buffer, state *tls.ConnectionState) {
+	if state == nil {
+		return
+	}
+	writeField(out, response.AlpnField, state.NegotiatedProtocol)
+	if len(state.PeerCertificates) == 0 {
+		return
+	}
+	cert := state.PeerCertificates[0]
+	for _, uri := range cert.URIs {
+		writeField(out, response.PeerCertSANField, uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		writeField(out, response.PeerCertSANField, name)
+	}
+	for _, ip := range cert.IPAddresses {
+		writeField(out, response.PeerCertSANField, ip.String())
+	}
+}
+
+// splitUnquoted splits s around the separators which are not within double quotes.
+func splitUnquoted(s string, sep rune) []string {
+	var out []string
+	quoted := false
+	start := 0
+	for i, c := range s {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
@@ -39,8 +39,9 @@ func (c *httpProtocol) setHost(r *http.Request, host string) {
 	if r.URL.Scheme == "https" {
 		// Set SNI value to be same as the request Host
 		// For use with SNI routing tests
-		httpTransport := c.client.Transport.(*http.Transport)
-		httpTransport.TLSClientConfig.ServerName = host
+		if httpTransport, ok := c.client.Transport.(*http.Transport); ok {
+			httpTransport.TLSClientConfig.ServerName = host
+		}
 	}
 }
 
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"golang.org/x/net/http2"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -73,15 +74,30 @@ func newProtocol(cfg Config) (protocol, error) {
 
 	switch scheme.Instance(u.Scheme) {
 	case scheme.HTTP, scheme.HTTPS:
+		var transport http.RoundTripper = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+			DialContext: httpDialContext,
+			// Over TLS, HTTP/2 is negotiated with ALPN.
+			ForceAttemptHTTP2: cfg.Request.Http2,
+		}
+		if cfg.Request.Http2 && scheme.Instance(u.Scheme) == scheme.HTTP {
+			// Without TLS, HTTP/2 is sent with prior knowledge (h2c).
+			transport = &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+					if httpDialContext != nil {
+						return httpDialContext(context.Background(), network, addr)
+					}
+					return net.Dial(network, addr)
+				},
+			}
+		}
 		return &httpProtocol{
 			client: &http.Client{
-				Transport: &http.Transport{
-					TLSClientConfig: &tls.Config{
-						InsecureSkipVerify: true,
-					},
-					DialContext: httpDialContext,
-				},
-				Timeout: timeout,
+				Transport: transport,
+				Timeout:   timeout,
 			},
 			do: cfg.Dialer.HTTP,
 		}, nil
@@ -117,6 +133,11 @@ func newProtocol(cfg Config) (protocol, error) {
 			conn:   grpcConn,
 			client: proto.NewEchoTestServiceClient(grpcConn),
 		}, nil
+	case scheme.TCP:
+		return &tcpProtocol{
+			address: u.Host,
+			dial:    cfg.Dialer.TCP,
+		}, nil
 	case scheme.WebSocket, scheme.WebSocketS:
 		dialer := &websocket.Dialer{
 			TLSClientConfig: &tls.Config{
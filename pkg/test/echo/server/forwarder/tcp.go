@@ -0,0 +1,71 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forwarder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"istio.io/istio/pkg/test/echo/common"
+)
+
+var _ protocol = &tcpProtocol{}
+
+type tcpProtocol struct {
+	address string
+	dial    common.TCPDialFunc
+}
+
+func (c *tcpProtocol) makeRequest(ctx context.Context, req *request) (string, error) {
+	// Set the per-request timeout.
+	ctx, cancel := context.WithTimeout(ctx, req.Timeout)
+	defer cancel()
+
+	conn, err := c.dial(net.Dialer{}, ctx, c.address)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	var outBuffer bytes.Buffer
+	outBuffer.WriteString(fmt.Sprintf("[%d] Url=%s\n", req.RequestID, req.URL))
+
+	// The server echoes the message and closes the connection.
+	if _, err := conn.Write([]byte(req.Message + "\n")); err != nil {
+		return outBuffer.String(), err
+	}
+	data, err := ioutil.ReadAll(conn)
+	if err != nil {
+		return outBuffer.String(), err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			outBuffer.WriteString(fmt.Sprintf("[%d body] %s\n", req.RequestID, line))
+		}
+	}
+	return outBuffer.String(), nil
+}
+
+func (c *tcpProtocol) Close() error {
+	return nil
+}
@@ -22,7 +22,6 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 
-	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/test/echo/common"
 	"istio.io/istio/pkg/test/echo/server/endpoint"
@@ -31,7 +30,7 @@ import (
 
 // Config for an echo server Instance.
 type Config struct {
-	Ports     model.PortList
+	Ports     common.PortList
 	TLSCert   string
 	TLSKey    string
 	Version   string
@@ -100,7 +99,7 @@ func (s *Instance) Close() (err error) {
 	return
 }
 
-func (s *Instance) newEndpoint(port *model.Port, udsServer string) (endpoint.Instance, error) {
+func (s *Instance) newEndpoint(port *common.Port, udsServer string) (endpoint.Instance, error) {
 	return endpoint.New(endpoint.Config{
 		Port:          port,
 		UDSServer:     udsServer,
@@ -145,6 +144,9 @@ func (s *Instance) validate() error {
 	for _, port := range s.Ports {
 		switch port.Protocol {
 		case protocol.TCP:
+			if port.TLS {
+				return fmt.Errorf("TLS is not supported on the TCP port %s", port.Name)
+			}
 		case protocol.HTTP:
 		case protocol.HTTPS:
 		case protocol.HTTP2:
@@ -152,6 +154,9 @@ func (s *Instance) validate() error {
 		default:
 			return fmt.Errorf("protocol %v not currently supported", port.Protocol)
 		}
+		if port.TLS && (s.TLSCert == "" || s.TLSKey == "") {
+			return fmt.Errorf("the TLS port %s requires a TLS certificate and key", port.Name)
+		}
 	}
 	return nil
 }
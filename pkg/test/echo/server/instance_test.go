@@ -0,0 +1,122 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package server_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/test/echo/client"
+	"istio.io/istio/pkg/test/echo/common"
+	"istio.io/istio/pkg/test/echo/proto"
+	"istio.io/istio/pkg/test/echo/server"
+	"istio.io/istio/pkg/test/env"
+)
+
+func TestProtocols(t *testing.T) {
+	httpPort := &common.Port{Name: "http", Protocol: protocol.HTTP}
+	httpsPort := &common.Port{Name: "https", Protocol: protocol.HTTPS, TLS: true}
+	tcpPort := &common.Port{Name: "tcp", Protocol: protocol.TCP}
+	grpcPort := &common.Port{Name: "grpc", Protocol: protocol.GRPC}
+
+	s := server.New(server.Config{
+		Ports:   common.PortList{httpPort, httpsPort, tcpPort, grpcPort},
+		TLSCert: env.IstioSrc + "/tests/testdata/certs/cert.crt",
+		TLSKey:  env.IstioSrc + "/tests/testdata/certs/cert.key",
+		Version: "v1",
+	})
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+
+	c, err := client.New(fmt.Sprintf("127.0.0.1:%d", grpcPort.Port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = c.Close() }()
+
+	cases := []struct {
+		name     string
+		url      string
+		http2    bool
+		protocol string
+		alpn     string
+	}{
+		{
+			name:     "http",
+			url:      fmt.Sprintf("http://127.0.0.1:%d", httpPort.Port),
+			protocol: "HTTP/1.1",
+		},
+		{
+			name:     "h2c",
+			url:      fmt.Sprintf("http://127.0.0.1:%d", httpPort.Port),
+			http2:    true,
+			protocol: "HTTP/2.0",
+		},
+		{
+			name:     "https",
+			url:      fmt.Sprintf("https://127.0.0.1:%d", httpsPort.Port),
+			protocol: "HTTP/1.1",
+		},
+		{
+			name:     "https with http2",
+			url:      fmt.Sprintf("https://127.0.0.1:%d", httpsPort.Port),
+			http2:    true,
+			protocol: "HTTP/2.0",
+			alpn:     "h2",
+		},
+		{
+			name:     "tcp",
+			url:      fmt.Sprintf("tcp://127.0.0.1:%d", tcpPort.Port),
+			protocol: "TCP",
+		},
+		{
+			name:     "http on tcp port",
+			url:      fmt.Sprintf("http://127.0.0.1:%d", tcpPort.Port),
+			protocol: "HTTP/1.1",
+		},
+		{
+			name:     "h2c on tcp port",
+			url:      fmt.Sprintf("http://127.0.0.1:%d", tcpPort.Port),
+			http2:    true,
+			protocol: "HTTP/2.0",
+		},
+		{
+			name:     "grpc",
+			url:      fmt.Sprintf("grpc://127.0.0.1:%d", grpcPort.Port),
+			protocol: "GRPC",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := c.ForwardEcho(context.Background(), &proto.ForwardEchoRequest{
+				Url:     tc.url,
+				Count:   1,
+				Message: "hello",
+				Http2:   tc.http2,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.CheckOKOrFail(t).CheckProtocolOrFail(t, tc.protocol)
+			if resp[0].Alpn != tc.alpn {
+				t.Errorf("got ALPN %q, want %q", resp[0].Alpn, tc.alpn)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package chaos
+
+import (
+	"time"
+
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/components/environment"
+	"istio.io/istio/pkg/test/framework/components/istio"
+	"istio.io/istio/pkg/test/framework/resource"
+)
+
+// Instance injects faults in the control plane and in the network of the clusters, so that the tests can
+// check that the data plane keeps serving during control plane outages. The faults still in place are
+// removed when the instance is closed.
+type Instance interface {
+	resource.Resource
+
+	// RestartControlPlane kills the pods of the control plane running in the cluster, and waits for their
+	// replacements to be ready.
+	RestartControlPlane(cluster resource.Cluster) error
+	RestartControlPlaneOrFail(t test.Failer, cluster resource.Cluster)
+
+	// StopControlPlane scales down the control plane running in the cluster, until StartControlPlane
+	// scales it back up and waits for it to be ready.
+	StopControlPlane(cluster resource.Cluster) error
+	StopControlPlaneOrFail(t test.Failer, cluster resource.Cluster)
+	StartControlPlane(cluster resource.Cluster) error
+	StartControlPlaneOrFail(t test.Failer, cluster resource.Cluster)
+
+	// AddAPIServerLatency delays the traffic from the control plane running in the cluster to the API
+	// server, until RemoveAPIServerLatency.
+	AddAPIServerLatency(cluster resource.Cluster, latency time.Duration) error
+	AddAPIServerLatencyOrFail(t test.Failer, cluster resource.Cluster, latency time.Duration)
+	RemoveAPIServerLatency(cluster resource.Cluster) error
+	RemoveAPIServerLatencyOrFail(t test.Failer, cluster resource.Cluster)
+
+	// Partition drops the traffic between the nodes and pods of the two clusters, until Heal.
+	Partition(a, b resource.Cluster) error
+	PartitionOrFail(t test.Failer, a, b resource.Cluster)
+	Heal(a, b resource.Cluster) error
+	HealOrFail(t test.Failer, a, b resource.Cluster)
+}
+
+// Config of the chaos component.
+type Config struct {
+	// Istio instance whose control plane the faults are injected in. Defaults to the configuration of
+	// the Istio deployment of the environment.
+	Istio istio.Instance
+
+	// Image of the agents injecting the faults on the nodes of the clusters. It must provide tc,
+	// iptables, nsenter and pgrep. Defaults to DefaultAgentImage.
+	Image string
+}
+
+// DefaultAgentImage is the default image of the agents injecting the faults.
+const DefaultAgentImage = "nicolaka/netshoot:latest"
+
+// New returns a new chaos instance.
+func New(ctx resource.Context, cfg Config) (i Instance, err error) {
+	err = resource.UnsupportedEnvironment(ctx.Environment())
+	ctx.Environment().Case(environment.Kube, func() {
+		i, err = newKube(ctx, cfg)
+	})
+	return
+}
+
+// NewOrFail returns a new chaos instance, or fails the test.
+func NewOrFail(t test.Failer, ctx resource.Context, cfg Config) Instance {
+	t.Helper()
+	i, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("chaos.NewOrFail: %v", err)
+	}
+	return i
+}
@@ -0,0 +1,513 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package chaos
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	kubeApiCore "k8s.io/api/core/v1"
+
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/components/environment/kube"
+	"istio.io/istio/pkg/test/framework/components/istio"
+	"istio.io/istio/pkg/test/framework/components/namespace"
+	"istio.io/istio/pkg/test/framework/resource"
+	kubeUtil "istio.io/istio/pkg/test/kube"
+	"istio.io/istio/pkg/test/scopes"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/pkg/test/util/tmpl"
+)
+
+const (
+	// agentYAML deploys a privileged agent on every node, sharing the network and the processes of the
+	// node, to inject the faults with tc and iptables.
+	agentYAML = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: istio-chaos-agent
+spec:
+  selector:
+    matchLabels:
+      app: istio-chaos-agent
+  template:
+    metadata:
+      labels:
+        app: istio-chaos-agent
+      annotations:
+        sidecar.istio.io/inject: "false"
+    spec:
+      hostNetwork: true
+      hostPID: true
+      tolerations:
+      - operator: Exists
+      containers:
+      - name: agent
+        image: {{ .Image }}
+        command: ["sleep", "infinity"]
+        securityContext:
+          privileged: true
+`
+
+	agentSelector   = "app=istio-chaos-agent"
+	agentContainer  = "agent"
+	pilotSelector   = "istio=pilot"
+	pilotDeployment = "istio-pilot"
+	pilotProcess    = "pilot-discovery"
+
+	// ruleComment marks the iptables rules of the partitions.
+	ruleComment = "istio-test-chaos"
+
+	controlPlaneTimeout = 5 * time.Minute
+)
+
+var (
+	_ Instance  = &kubeComponent{}
+	_ io.Closer = &kubeComponent{}
+)
+
+type kubeComponent struct {
+	id              resource.ID
+	env             *kube.Environment
+	ns              namespace.Instance
+	systemNamespace string
+
+	mu sync.Mutex
+	// The number of replicas of the stopped control planes, the control planes whose traffic to the
+	// API server is delayed, and the partitioned clusters.
+	stopped    map[resource.ClusterIndex]int
+	latencies  map[resource.ClusterIndex]bool
+	partitions map[[2]resource.ClusterIndex]bool
+}
+
+func newKube(ctx resource.Context, cfg Config) (Instance, error) {
+	var istioCfg istio.Config
+	if cfg.Istio != nil {
+		istioCfg = cfg.Istio.Settings()
+	} else {
+		var err error
+		if istioCfg, err = istio.DefaultConfig(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Image == "" {
+		cfg.Image = DefaultAgentImage
+	}
+
+	// The namespace is created before the component is tracked, so that it is deleted after the faults
+	// are removed by the agents.
+	ns, err := namespace.New(ctx, namespace.Config{
+		Prefix: "istio-chaos",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c := &kubeComponent{
+		env:             ctx.Environment().(*kube.Environment),
+		ns:              ns,
+		systemNamespace: istioCfg.SystemNamespace,
+		stopped:         make(map[resource.ClusterIndex]int),
+		latencies:       make(map[resource.ClusterIndex]bool),
+		partitions:      make(map[[2]resource.ClusterIndex]bool),
+	}
+	c.id = ctx.TrackResource(c)
+
+	agent, err := tmpl.Evaluate(agentYAML, map[string]string{"Image": cfg.Image})
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range c.env.KubeClusters {
+		if _, err := cluster.ApplyContents(ns.Name(), agent); err != nil {
+			return nil, fmt.Errorf("failed to deploy the chaos agents in %s: %v", cluster, err)
+		}
+		if _, err := cluster.WaitUntilPodsAreReady(cluster.NewPodFetch(ns.Name(), agentSelector)); err != nil {
+			return nil, fmt.Errorf("failed waiting for the chaos agents of %s: %v", cluster, err)
+		}
+	}
+	return c, nil
+}
+
+func (c *kubeComponent) ID() resource.ID {
+	return c.id
+}
+
+// controlPlaneCluster returns the cluster, failing if no control plane runs in it.
+func (c *kubeComponent) controlPlaneCluster(cluster resource.Cluster) (kube.Cluster, error) {
+	kc := c.env.GetCluster(cluster)
+	if !c.env.IsControlPlaneCluster(kc) {
+		return kc, fmt.Errorf("no control plane runs in %s", kc)
+	}
+	return kc, nil
+}
+
+func (c *kubeComponent) RestartControlPlane(cluster resource.Cluster) error {
+	kc, err := c.controlPlaneCluster(cluster)
+	if err != nil {
+		return err
+	}
+	pods, err := kc.GetPods(c.systemNamespace, pilotSelector)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no control plane pod in %s", kc)
+	}
+
+	scopes.CI.Infof("Restarting the control plane of %s", kc)
+	killed := make(map[string]bool, len(pods))
+	for _, pod := range pods {
+		if err := kc.DeletePod(c.systemNamespace, pod.Name); err != nil {
+			return fmt.Errorf("failed to kill control plane pod %s of %s: %v", pod.Name, kc, err)
+		}
+		killed[pod.Name] = true
+	}
+
+	// Wait for as many new pods to be ready.
+	replacements := func() ([]kubeApiCore.Pod, error) {
+		current, err := kc.GetPods(c.systemNamespace, pilotSelector)
+		if err != nil {
+			return nil, err
+		}
+		var out []kubeApiCore.Pod
+		for _, pod := range current {
+			if !killed[pod.Name] {
+				out = append(out, pod)
+			}
+		}
+		if len(out) < len(pods) {
+			return nil, fmt.Errorf("%d/%d control plane pods replaced in %s", len(out), len(pods), kc)
+		}
+		return out, nil
+	}
+	_, err = kc.WaitUntilPodsAreReady(replacements, retry.Timeout(controlPlaneTimeout))
+	return err
+}
+
+func (c *kubeComponent) StopControlPlane(cluster resource.Cluster) error {
+	kc, err := c.controlPlaneCluster(cluster)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.stopped[kc.Index()]; ok {
+		return fmt.Errorf("the control plane of %s is already stopped", kc)
+	}
+
+	pods, err := kc.GetPods(c.systemNamespace, pilotSelector)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no control plane pod in %s", kc)
+	}
+
+	scopes.CI.Infof("Stopping the control plane of %s", kc)
+	if err := kc.ScaleDeployment(c.systemNamespace, pilotDeployment, 0); err != nil {
+		return err
+	}
+	c.stopped[kc.Index()] = len(pods)
+	return kc.WaitUntilPodsAreDeleted(kc.NewPodFetch(c.systemNamespace, pilotSelector), retry.Timeout(controlPlaneTimeout))
+}
+
+func (c *kubeComponent) StartControlPlane(cluster resource.Cluster) error {
+	kc, err := c.controlPlaneCluster(cluster)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.startControlPlane(kc)
+}
+
+func (c *kubeComponent) startControlPlane(kc kube.Cluster) error {
+	replicas, ok := c.stopped[kc.Index()]
+	if !ok {
+		return fmt.Errorf("the control plane of %s is not stopped", kc)
+	}
+
+	scopes.CI.Infof("Starting the control plane of %s", kc)
+	if err := kc.ScaleDeployment(c.systemNamespace, pilotDeployment, replicas); err != nil {
+		return err
+	}
+	delete(c.stopped, kc.Index())
+	_, err := kc.WaitUntilPodsAreReady(kc.NewPodFetch(c.systemNamespace, pilotSelector), retry.Timeout(controlPlaneTimeout))
+	return err
+}
+
+func (c *kubeComponent) AddAPIServerLatency(cluster resource.Cluster, latency time.Duration) error {
+	kc, err := c.controlPlaneCluster(cluster)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latencies[kc.Index()] {
+		return fmt.Errorf("the traffic of the control plane of %s to the API server is already delayed", kc)
+	}
+
+	svc, err := kc.GetService("default", "kubernetes")
+	if err != nil {
+		return fmt.Errorf("failed to get the address of the API server of %s: %v", kc, err)
+	}
+
+	scopes.CI.Infof("Delaying the traffic of the control plane of %s to the API server by %v", kc, latency)
+	// Only the traffic to the API server is delayed, in the network namespace of the control plane.
+	c.latencies[kc.Index()] = true
+	return c.controlPlaneExec(kc,
+		"tc qdisc add dev eth0 root handle 1: prio",
+		fmt.Sprintf("tc qdisc add dev eth0 parent 1:3 handle 30: netem delay %dms", latency.Milliseconds()),
+		fmt.Sprintf("tc filter add dev eth0 protocol ip parent 1:0 prio 3 u32 match ip dst %s/32 flowid 1:3",
+			svc.Spec.ClusterIP))
+}
+
+func (c *kubeComponent) RemoveAPIServerLatency(cluster resource.Cluster) error {
+	kc, err := c.controlPlaneCluster(cluster)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.removeAPIServerLatency(kc)
+}
+
+func (c *kubeComponent) removeAPIServerLatency(kc kube.Cluster) error {
+	if !c.latencies[kc.Index()] {
+		return fmt.Errorf("the traffic of the control plane of %s to the API server is not delayed", kc)
+	}
+
+	scopes.CI.Infof("Removing the delay of the traffic of the control plane of %s to the API server", kc)
+	delete(c.latencies, kc.Index())
+	return c.controlPlaneExec(kc, "tc qdisc del dev eth0 root")
+}
+
+// controlPlaneExec runs the commands in the network namespace of the control plane processes of the
+// cluster, with the agents of their nodes.
+func (c *kubeComponent) controlPlaneExec(kc kube.Cluster, commands ...string) error {
+	pods, err := kc.GetPods(c.systemNamespace, pilotSelector)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return fmt.Errorf("no control plane pod in %s", kc)
+	}
+
+	nodes := make(map[string]bool)
+	for _, pod := range pods {
+		nodes[pod.Spec.NodeName] = true
+	}
+	for node := range nodes {
+		agent, err := c.agent(kc, node)
+		if err != nil {
+			return err
+		}
+		out, err := kc.Exec(c.ns.Name(), agent, agentContainer, "pgrep -f "+pilotProcess)
+		if err != nil {
+			return fmt.Errorf("failed to find the control plane processes on node %s of %s: %v", node, kc, err)
+		}
+		for _, pid := range strings.Fields(out) {
+			for _, command := range commands {
+				if out, err := kc.Exec(c.ns.Name(), agent, agentContainer,
+					fmt.Sprintf("nsenter -t %s -n %s", pid, command)); err != nil {
+					return fmt.Errorf("failed to run %q for the control plane on node %s of %s: %v: %s",
+						command, node, kc, err, out)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *kubeComponent) Partition(a, b resource.Cluster) error {
+	ka, kb := c.env.GetCluster(a), c.env.GetCluster(b)
+	if ka.Index() == kb.Index() {
+		return fmt.Errorf("cannot partition %s from itself", ka)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := partitionKey(ka, kb)
+	if c.partitions[key] {
+		return fmt.Errorf("%s and %s are already partitioned", ka, kb)
+	}
+
+	scopes.CI.Infof("Partitioning %s and %s", ka, kb)
+	c.partitions[key] = true
+	return c.partitionRules("-I", ka, kb)
+}
+
+func (c *kubeComponent) Heal(a, b resource.Cluster) error {
+	ka, kb := c.env.GetCluster(a), c.env.GetCluster(b)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.heal(ka, kb)
+}
+
+func (c *kubeComponent) heal(ka, kb kube.Cluster) error {
+	key := partitionKey(ka, kb)
+	if !c.partitions[key] {
+		return fmt.Errorf("%s and %s are not partitioned", ka, kb)
+	}
+
+	scopes.CI.Infof("Healing the partition of %s and %s", ka, kb)
+	delete(c.partitions, key)
+	return c.partitionRules("-D", ka, kb)
+}
+
+func partitionKey(a, b kube.Cluster) [2]resource.ClusterIndex {
+	if a.Index() > b.Index() {
+		a, b = b, a
+	}
+	return [2]resource.ClusterIndex{a.Index(), b.Index()}
+}
+
+// partitionRules inserts (-I) or deletes (-D) the iptables rules dropping the traffic between the nodes
+// and pods of the clusters, on all their nodes.
+func (c *kubeComponent) partitionRules(op string, a, b kube.Cluster) error {
+	var err error
+	for _, pair := range [][2]kube.Cluster{{a, b}, {b, a}} {
+		local, remote := pair[0], pair[1]
+		addresses, e := clusterAddresses(remote)
+		if e != nil {
+			return e
+		}
+		agents, e := local.GetPods(c.ns.Name(), agentSelector)
+		if e != nil {
+			return e
+		}
+		for _, agent := range agents {
+			for _, address := range addresses {
+				for _, rule := range []string{
+					"INPUT -s " + address,
+					"OUTPUT -d " + address,
+					"FORWARD -s " + address,
+					"FORWARD -d " + address,
+				} {
+					command := fmt.Sprintf("iptables %s %s -m comment --comment %s -j DROP", op, rule, ruleComment)
+					if out, e := local.Exec(c.ns.Name(), agent.Name, agentContainer, command); e != nil {
+						err = multierror.Append(err, fmt.Errorf("failed to run %q on node %s of %s: %v: %s",
+							command, agent.Spec.NodeName, local, e, out))
+					}
+				}
+			}
+		}
+	}
+	return err
+}
+
+// clusterAddresses returns the addresses of the nodes of the cluster, and the ranges of the addresses
+// of its pods.
+func clusterAddresses(kc kube.Cluster) ([]string, error) {
+	nodes, err := kc.GetNodes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the nodes of %s: %v", kc, err)
+	}
+	var out []string
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			if address.Type == kubeApiCore.NodeInternalIP {
+				out = append(out, address.Address+"/32")
+			}
+		}
+		if node.Spec.PodCIDR != "" {
+			out = append(out, node.Spec.PodCIDR)
+		}
+	}
+	return out, nil
+}
+
+// agent returns the name of the agent pod running on the node.
+func (c *kubeComponent) agent(kc kube.Cluster, node string) (string, error) {
+	agents, err := kc.GetPods(c.ns.Name(), agentSelector)
+	if err != nil {
+		return "", err
+	}
+	for _, agent := range agents {
+		if agent.Spec.NodeName == node && kubeUtil.CheckPodReady(&agent) == nil {
+			return agent.Name, nil
+		}
+	}
+	return "", fmt.Errorf("no chaos agent ready on node %s of %s", node, kc)
+}
+
+// Close implements io.Closer, removing the faults still in place.
+func (c *kubeComponent) Close() (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.partitions {
+		err = multierror.Append(err, c.heal(c.env.KubeClusters[key[0]], c.env.KubeClusters[key[1]])).ErrorOrNil()
+	}
+	for index := range c.latencies {
+		err = multierror.Append(err, c.removeAPIServerLatency(c.env.KubeClusters[index])).ErrorOrNil()
+	}
+	for index := range c.stopped {
+		err = multierror.Append(err, c.startControlPlane(c.env.KubeClusters[index])).ErrorOrNil()
+	}
+	return
+}
+
+func (c *kubeComponent) RestartControlPlaneOrFail(t test.Failer, cluster resource.Cluster) {
+	t.Helper()
+	if err := c.RestartControlPlane(cluster); err != nil {
+		t.Fatalf("chaos.RestartControlPlaneOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) StopControlPlaneOrFail(t test.Failer, cluster resource.Cluster) {
+	t.Helper()
+	if err := c.StopControlPlane(cluster); err != nil {
+		t.Fatalf("chaos.StopControlPlaneOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) StartControlPlaneOrFail(t test.Failer, cluster resource.Cluster) {
+	t.Helper()
+	if err := c.StartControlPlane(cluster); err != nil {
+		t.Fatalf("chaos.StartControlPlaneOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) AddAPIServerLatencyOrFail(t test.Failer, cluster resource.Cluster, latency time.Duration) {
+	t.Helper()
+	if err := c.AddAPIServerLatency(cluster, latency); err != nil {
+		t.Fatalf("chaos.AddAPIServerLatencyOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) RemoveAPIServerLatencyOrFail(t test.Failer, cluster resource.Cluster) {
+	t.Helper()
+	if err := c.RemoveAPIServerLatency(cluster); err != nil {
+		t.Fatalf("chaos.RemoveAPIServerLatencyOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) PartitionOrFail(t test.Failer, a, b resource.Cluster) {
+	t.Helper()
+	if err := c.Partition(a, b); err != nil {
+		t.Fatalf("chaos.PartitionOrFail: %v", err)
+	}
+}
+
+func (c *kubeComponent) HealOrFail(t test.Failer, a, b resource.Cluster) {
+	t.Helper()
+	if err := c.Heal(a, b); err != nil {
+		t.Fatalf("chaos.HealOrFail: %v", err)
+	}
+}
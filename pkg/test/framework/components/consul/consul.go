@@ -0,0 +1,91 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package consul
+
+import (
+	"fmt"
+
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/components/environment"
+	"istio.io/istio/pkg/test/framework/resource"
+)
+
+// Instance of a mock Consul server, serving the catalog of the services registered in it. Pilot uses it
+// as a registry next to the other registries of its aggregate registry when it is given the instance.
+type Instance interface {
+	resource.Resource
+
+	// Address returns the URL of the Consul HTTP API.
+	Address() string
+
+	// Register adds the service to the catalog, replacing the service of the same name.
+	Register(svc Service) error
+	RegisterOrFail(t test.Failer, svc Service)
+
+	// Deregister removes the service of the given name from the catalog.
+	Deregister(name string) error
+	DeregisterOrFail(t test.Failer, name string)
+}
+
+// Service registered in Consul.
+type Service struct {
+	// Name of the service. Pilot serves it with the <name>.service.consul hostname.
+	Name string
+
+	// Endpoints of the service.
+	Endpoints []Endpoint
+}
+
+// Endpoint of a service registered in Consul.
+type Endpoint struct {
+	Address  string
+	Port     int
+	Protocol protocol.Instance
+
+	// Labels of the endpoint, registered as tags of the form key|value.
+	Labels map[string]string
+
+	// External marks the service as external to the mesh.
+	External bool
+}
+
+// Hostname returns the hostname of the service in the mesh.
+func (s Service) Hostname() string {
+	return fmt.Sprintf("%s.service.consul", s.Name)
+}
+
+// Config for the Consul component.
+type Config struct {
+}
+
+// New returns a new instance of the mock Consul server.
+func New(ctx resource.Context, cfg Config) (i Instance, err error) {
+	err = resource.UnsupportedEnvironment(ctx.Environment())
+	ctx.Environment().Case(environment.Native, func() {
+		i, err = newNative(ctx, cfg)
+	})
+	return
+}
+
+// NewOrFail returns a new instance of the mock Consul server, or fails the test.
+func NewOrFail(t test.Failer, ctx resource.Context, cfg Config) Instance {
+	t.Helper()
+	i, err := New(ctx, cfg)
+	if err != nil {
+		t.Fatalf("consul.NewOrFail: %v", err)
+	}
+	return i
+}
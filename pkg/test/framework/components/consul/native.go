@@ -0,0 +1,195 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package consul
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/resource"
+	"istio.io/istio/pkg/test/scopes"
+)
+
+const (
+	servicesPath = "/v1/catalog/services"
+	servicePath  = "/v1/catalog/service/"
+)
+
+var (
+	_ Instance  = &nativeComponent{}
+	_ io.Closer = &nativeComponent{}
+)
+
+type nativeComponent struct {
+	id resource.ID
+	*server
+}
+
+func newNative(ctx resource.Context, _ Config) (Instance, error) {
+	c := &nativeComponent{
+		server: newServer(),
+	}
+	c.id = ctx.TrackResource(c)
+	scopes.Framework.Infof("Mock Consul server listening on %s", c.Address())
+	return c, nil
+}
+
+func (c *nativeComponent) ID() resource.ID {
+	return c.id
+}
+
+func (c *nativeComponent) RegisterOrFail(t test.Failer, svc Service) {
+	t.Helper()
+	if err := c.Register(svc); err != nil {
+		t.Fatalf("consul.RegisterOrFail: %v", err)
+	}
+}
+
+func (c *nativeComponent) DeregisterOrFail(t test.Failer, name string) {
+	t.Helper()
+	if err := c.Deregister(name); err != nil {
+		t.Fatalf("consul.DeregisterOrFail: %v", err)
+	}
+}
+
+// server serves the read-only part of the catalog API of Consul that Pilot uses. Every change of the
+// catalog increments the index returned to the clients, which is how Pilot detects the changes.
+type server struct {
+	http *httptest.Server
+
+	mu       sync.Mutex
+	index    uint64
+	services map[string][]*api.CatalogService
+}
+
+func newServer() *server {
+	s := &server{
+		index:    1,
+		services: make(map[string][]*api.CatalogService),
+	}
+	s.http = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+func (s *server) Address() string {
+	return s.http.URL
+}
+
+func (s *server) Register(svc Service) error {
+	if svc.Name == "" {
+		return errors.New("the service has no name")
+	}
+	if strings.Contains(svc.Name, "/") {
+		return fmt.Errorf("invalid service name %q", svc.Name)
+	}
+
+	endpoints := make([]*api.CatalogService, 0, len(svc.Endpoints))
+	for i, ep := range svc.Endpoints {
+		tags := make([]string, 0, len(ep.Labels))
+		for k, v := range ep.Labels {
+			tags = append(tags, k+"|"+v)
+		}
+		sort.Strings(tags)
+		meta := map[string]string{}
+		if ep.Protocol != "" {
+			meta["protocol"] = strings.ToLower(string(ep.Protocol))
+		}
+		if ep.External {
+			meta["external"] = "true"
+		}
+		endpoints = append(endpoints, &api.CatalogService{
+			ID:             fmt.Sprintf("%s-node-%d", svc.Name, i),
+			Node:           fmt.Sprintf("%s-node-%d", svc.Name, i),
+			Address:        ep.Address,
+			ServiceID:      fmt.Sprintf("%s-%d", svc.Name, i),
+			ServiceName:    svc.Name,
+			ServiceTags:    tags,
+			ServiceAddress: ep.Address,
+			ServicePort:    ep.Port,
+			ServiceMeta:    meta,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.services[svc.Name] = endpoints
+	s.index++
+	return nil
+}
+
+func (s *server) Deregister(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.services[name]; !ok {
+		return fmt.Errorf("service %q is not registered", name)
+	}
+	delete(s.services, name)
+	s.index++
+	return nil
+}
+
+func (s *server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	var body interface{}
+	switch {
+	case r.URL.Path == servicesPath:
+		services := make(map[string][]string, len(s.services))
+		for name, endpoints := range s.services {
+			var tags []string
+			for _, ep := range endpoints {
+				tags = append(tags, ep.ServiceTags...)
+			}
+			services[name] = tags
+		}
+		body = services
+	case strings.HasPrefix(r.URL.Path, servicePath):
+		endpoints := s.services[strings.TrimPrefix(r.URL.Path, servicePath)]
+		if endpoints == nil {
+			endpoints = []*api.CatalogService{}
+		}
+		body = endpoints
+	default:
+		s.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	data, err := json.Marshal(body)
+	index := s.index
+	s.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func (s *server) Close() error {
+	s.http.Close()
+	return nil
+}
@@ -0,0 +1,84 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package consul
+
+import (
+	"testing"
+
+	"istio.io/istio/pilot/pkg/serviceregistry/consul"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/protocol"
+)
+
+func TestServer(t *testing.T) {
+	s := newServer()
+	defer func() { _ = s.Close() }()
+
+	svc := Service{
+		Name: "reviews",
+		Endpoints: []Endpoint{
+			{
+				Address:  "10.0.0.1",
+				Port:     9080,
+				Protocol: protocol.HTTP,
+				Labels:   map[string]string{"version": "v1"},
+			},
+			{
+				Address:  "10.0.0.2",
+				Port:     9080,
+				Protocol: protocol.HTTP,
+				Labels:   map[string]string{"version": "v2"},
+			},
+		},
+	}
+	if err := s.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the catalog as Pilot does.
+	c, err := consul.NewController(s.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetService(host.Name(svc.Hostname()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatalf("service %s not found", svc.Hostname())
+	}
+	if len(got.Ports) != 1 || got.Ports[0].Port != 9080 || got.Ports[0].Protocol != protocol.HTTP {
+		t.Fatalf("got ports %v, want 9080/HTTP", got.Ports)
+	}
+
+	instances, err := c.InstancesByPort(got, 9080, labels.Collection{{"version": "v2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(instances) != 1 || instances[0].Endpoint.Address != "10.0.0.2" {
+		t.Fatalf("got instances %v, want the v2 endpoint", instances)
+	}
+
+	if err := s.Deregister(svc.Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Deregister(svc.Name); err == nil {
+		t.Fatal("expected an error deregistering an unknown service")
+	}
+	if err := s.Register(Service{}); err == nil {
+		t.Fatal("expected an error registering a service without name")
+	}
+}
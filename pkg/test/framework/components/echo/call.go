@@ -53,6 +53,10 @@ type CallOptions struct {
 	// Timeout used for each individual request. Must be > 0, otherwise 30 seconds is used.
 	Timeout time.Duration
 
-	// Message to be sent if this is a GRPC request
+	// Message to be sent if this is a GRPC or TCP request
 	Message string
+
+	// HTTP2 sends the HTTP requests with HTTP/2: with prior knowledge (h2c) for the http scheme and
+	// negotiated with ALPN for the https scheme.
+	HTTP2 bool
 }
@@ -54,7 +54,12 @@ func CallEcho(c *client.Instance, opts *echo.CallOptions, outboundPortSelector O
 
 	// Forward a request from 'this' service to the destination service.
 	targetHost := net.JoinHostPort(opts.Host, strconv.Itoa(port))
-	targetURL := fmt.Sprintf("%s://%s%s", string(opts.Scheme), targetHost, opts.Path)
+	targetAddress := targetHost
+	if cfg := opts.Target.Config(); cfg.DeployAsVM && opts.Host == cfg.FQDN() {
+		// The services deployed on simulated VMs have no DNS record: connect to their VIP.
+		targetAddress = net.JoinHostPort(opts.Target.Address(), strconv.Itoa(port))
+	}
+	targetURL := fmt.Sprintf("%s://%s%s", string(opts.Scheme), targetAddress, opts.Path)
 	protoHeaders := []*proto.Header{
 		{
 			Key:   "Host",
@@ -73,6 +78,7 @@ func CallEcho(c *client.Instance, opts *echo.CallOptions, outboundPortSelector O
 		Headers:       protoHeaders,
 		TimeoutMicros: common.DurationToMicros(opts.Timeout),
 		Message:       opts.Message,
+		Http2:         opts.HTTP2,
 	}
 
 	resp, err := c.ForwardEcho(context.Background(), req)
@@ -21,6 +21,7 @@ import (
 	"istio.io/istio/pkg/test/framework/components/galley"
 	"istio.io/istio/pkg/test/framework/components/namespace"
 	"istio.io/istio/pkg/test/framework/components/pilot"
+	"istio.io/istio/pkg/test/framework/resource"
 )
 
 // Config defines the options for creating an Echo component.
@@ -44,6 +45,10 @@ type Config struct {
 	// Version indicates the version path for calls to the Echo application.
 	Version string
 
+	// Cluster (k8s only) is the cluster the app is deployed in. If not provided, the app is
+	// deployed in the primary cluster.
+	Cluster resource.Cluster
+
 	// Locality (k8s only) indicates the locality of the deployed app.
 	Locality string
 
@@ -68,10 +73,19 @@ type Config struct {
 	// ReadinessTimeout specifies the timeout that we wait the application to
 	// become ready.
 	ReadinessTimeout time.Duration
+
+	// DeployAsVM (k8s only) deploys the instance as a simulated mesh expansion VM: a pod without
+	// sidecar injection, running the Istio agent as the packages do on a VM, and registered in the
+	// mesh with a WorkloadEntry. The service has no DNS record: the calls connect to its VIP. Not
+	// supported for headless services.
+	DeployAsVM bool
 }
 
 // String implements the Configuration interface (which implements fmt.Stringer)
 func (c Config) String() string {
+	if c.Cluster != nil {
+		return fmt.Sprint("{service: ", c.Service, ", version: ", c.Version, ", cluster: ", c.Cluster, "}")
+	}
 	return fmt.Sprint("{service: ", c.Service, ", version: ", c.Version, "}")
 }
 
@@ -0,0 +1,276 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package distribution
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/echo/client"
+	"istio.io/istio/pkg/test/echo/common/response"
+	"istio.io/istio/pkg/test/framework/components/echo"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+const (
+	// DefaultCount is the number of requests sent by a Checker, unless specified in its call options.
+	DefaultCount = 100
+	// DefaultConcurrency is the number of concurrent calls sending the requests of a Checker.
+	DefaultConcurrency = 10
+)
+
+// GroupFunc returns the group of a response, e.g. the service or the version of the workload that
+// served it. Responses in the empty group are not counted.
+type GroupFunc func(*client.ParsedResponse) string
+
+// ByVersion groups the responses by the version of the workload that served them.
+func ByVersion(r *client.ParsedResponse) string {
+	return r.Version
+}
+
+// ByService groups the responses by the given services, from the hostname of the pod that served
+// them. The responses of other services are not counted.
+func ByService(services ...string) GroupFunc {
+	// Match the longest service first, so that "b-v2" is not counted as "b".
+	sorted := append([]string{}, services...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	return func(r *client.ParsedResponse) string {
+		for _, s := range sorted {
+			if strings.HasPrefix(r.Hostname, s+"-") {
+				return s
+			}
+		}
+		return ""
+	}
+}
+
+// Distribution of the responses to a batch of requests.
+type Distribution struct {
+	// Total is the number of responses.
+	Total int
+	// Groups is the number of responses in each group.
+	Groups map[string]int
+	// Codes is the number of responses with each status code.
+	Codes map[string]int
+}
+
+// New computes the distribution of the given responses.
+func New(responses client.ParsedResponses, groupBy GroupFunc) Distribution {
+	d := Distribution{
+		Groups: make(map[string]int),
+		Codes:  make(map[string]int),
+	}
+	for _, r := range responses {
+		d.Total++
+		d.Codes[r.Code]++
+		if groupBy == nil {
+			continue
+		}
+		if g := groupBy(r); g != "" {
+			d.Groups[g]++
+		}
+	}
+	return d
+}
+
+func (d Distribution) grouped() int {
+	n := 0
+	for _, count := range d.Groups {
+		n += count
+	}
+	return n
+}
+
+// Percent returns the percentage of the grouped responses that are in the group.
+func (d Distribution) Percent(group string) float64 {
+	total := d.grouped()
+	if total == 0 {
+		return 0
+	}
+	return float64(d.Groups[group]) * 100 / float64(total)
+}
+
+// CheckWeights checks that the grouped responses are distributed according to the expected weights,
+// in percent, within the tolerance in percentage points.
+func (d Distribution) CheckWeights(expected map[string]int32, tolerance float64) error {
+	if d.grouped() == 0 {
+		return fmt.Errorf("no response in the expected groups, got %v", d)
+	}
+	var err error
+	for group, weight := range expected {
+		got := d.Percent(group)
+		if math.Abs(float64(weight)-got) > tolerance {
+			err = multierror.Append(err, fmt.Errorf("unexpected traffic weight for %s: expected %d%%, got %g%% (tolerance: %g%%)",
+				group, weight, got, tolerance))
+		}
+	}
+	for group := range d.Groups {
+		if _, ok := expected[group]; !ok {
+			err = multierror.Append(err, fmt.Errorf("unexpected traffic to %s: %g%%", group, d.Percent(group)))
+		}
+	}
+	return err
+}
+
+// CheckPreferred checks that at least the given percentage of all the responses is in the group, e.g.
+// that the traffic stays in the locality of the client.
+func (d Distribution) CheckPreferred(group string, percent float64) error {
+	if d.Total == 0 {
+		return fmt.Errorf("no response")
+	}
+	got := float64(d.Groups[group]) * 100 / float64(d.Total)
+	if got < percent {
+		return fmt.Errorf("expected at least %g%% of the traffic to %s, got %g%% (%v)", percent, group, got, d)
+	}
+	return nil
+}
+
+// CheckSuccessRate checks that at least the given percentage of the responses is successful, e.g.
+// that retries hide the failures of the workloads.
+func (d Distribution) CheckSuccessRate(percent float64) error {
+	if d.Total == 0 {
+		return fmt.Errorf("no response")
+	}
+	got := float64(d.Codes[response.StatusCodeOK]) * 100 / float64(d.Total)
+	if got < percent {
+		return fmt.Errorf("expected a success rate of at least %g%%, got %g%% (codes: %v)", percent, got, d.Codes)
+	}
+	return nil
+}
+
+func (d Distribution) String() string {
+	return fmt.Sprintf("total: %d, groups: %v, codes: %v", d.Total, d.Groups, d.Codes)
+}
+
+// Checker sends batches of requests from an echo instance and checks how the responses are
+// distributed, until the check succeeds or the retries time out.
+type Checker struct {
+	From    echo.Instance
+	Options echo.CallOptions
+	// GroupBy groups the responses of the checks of the distribution among the groups.
+	GroupBy GroupFunc
+	// Concurrency is the number of concurrent calls sending the requests. Defaults to
+	// DefaultConcurrency.
+	Concurrency int
+	// RetryOptions of the checks. By default the checks are retried every second for a minute.
+	RetryOptions []retry.Option
+}
+
+// Send sends a batch of requests and returns the distribution of their responses.
+func (c *Checker) Send() (Distribution, error) {
+	count := c.Options.Count
+	if count <= 0 {
+		count = DefaultCount
+	}
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if concurrency > count {
+		concurrency = count
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		responses client.ParsedResponses
+		errs      error
+	)
+	for i := 0; i < concurrency; i++ {
+		opts := c.Options
+		opts.Count = count / concurrency
+		if i < count%concurrency {
+			opts.Count++
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := c.From.Call(opts)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = multierror.Append(errs, err)
+				return
+			}
+			responses = append(responses, resp...)
+		}()
+	}
+	wg.Wait()
+
+	if errs != nil {
+		return Distribution{}, errs
+	}
+	return New(responses, c.GroupBy), nil
+}
+
+// Check sends batches of requests until the distribution of their responses passes the check.
+func (c *Checker) Check(check func(Distribution) error) error {
+	opts := c.RetryOptions
+	if len(opts) == 0 {
+		opts = []retry.Option{retry.Delay(time.Second), retry.Timeout(time.Minute)}
+	}
+	return retry.UntilSuccess(func() error {
+		d, err := c.Send()
+		if err != nil {
+			return err
+		}
+		if err := check(d); err != nil {
+			return fmt.Errorf("%s to %s: %v", c.From.Config().Service, c.target(), err)
+		}
+		return nil
+	}, opts...)
+}
+
+// CheckWeightsOrFail checks that the responses are distributed among the groups according to the
+// expected weights, in percent, within the tolerance in percentage points.
+func (c *Checker) CheckWeightsOrFail(t test.Failer, expected map[string]int32, tolerance float64) {
+	t.Helper()
+	if err := c.Check(func(d Distribution) error { return d.CheckWeights(expected, tolerance) }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckPreferredOrFail checks that at least the given percentage of the responses is in the group.
+func (c *Checker) CheckPreferredOrFail(t test.Failer, group string, percent float64) {
+	t.Helper()
+	if err := c.Check(func(d Distribution) error { return d.CheckPreferred(group, percent) }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// CheckSuccessRateOrFail checks that at least the given percentage of the responses is successful.
+func (c *Checker) CheckSuccessRateOrFail(t test.Failer, percent float64) {
+	t.Helper()
+	if err := c.Check(func(d Distribution) error { return d.CheckSuccessRate(percent) }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *Checker) target() string {
+	if c.Options.Host != "" {
+		return c.Options.Host
+	}
+	if c.Options.Target != nil {
+		return c.Options.Target.Config().Service
+	}
+	return ""
+}
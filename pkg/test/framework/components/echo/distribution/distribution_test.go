@@ -0,0 +1,95 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package distribution
+
+import (
+	"testing"
+
+	"istio.io/istio/pkg/test/echo/client"
+)
+
+func responses(hostnames map[string]int, code string) client.ParsedResponses {
+	var out client.ParsedResponses
+	for hostname, count := range hostnames {
+		for i := 0; i < count; i++ {
+			out = append(out, &client.ParsedResponse{Hostname: hostname, Code: code})
+		}
+	}
+	return out
+}
+
+func TestByService(t *testing.T) {
+	groupBy := ByService("b", "b-v2")
+	cases := map[string]string{
+		"b-v1-7d4f9c-x2x9z":    "b",
+		"b-v2-v1-5b6c7-abcde":  "b-v2",
+		"c-v1-7d4f9c-x2x9z":    "",
+		"bb-v1-7d4f9c-x2x9z":   "",
+		"b-v2-5b6c7d8f9-x2x9z": "b-v2",
+	}
+	for hostname, want := range cases {
+		if got := groupBy(&client.ParsedResponse{Hostname: hostname}); got != want {
+			t.Errorf("%s: got group %q, want %q", hostname, got, want)
+		}
+	}
+}
+
+func TestCheckWeights(t *testing.T) {
+	d := New(responses(map[string]int{"b-1": 22, "c-1": 78, "other-1": 5}, "200"), ByService("b", "c"))
+
+	cases := []struct {
+		name      string
+		expected  map[string]int32
+		tolerance float64
+		wantErr   bool
+	}{
+		{"within tolerance", map[string]int32{"b": 20, "c": 80}, 5, false},
+		{"outside tolerance", map[string]int32{"b": 20, "c": 80}, 1, true},
+		{"unexpected group", map[string]int32{"b": 100}, 5, true},
+		{"missing group", map[string]int32{"b": 20, "c": 70, "d": 10}, 5, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := d.CheckWeights(tc.expected, tc.tolerance)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Errorf("got error %v, want error %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckPreferred(t *testing.T) {
+	d := New(responses(map[string]int{"b-1": 95, "c-1": 5}, "200"), ByService("b", "c"))
+	if err := d.CheckPreferred("b", 90); err != nil {
+		t.Error(err)
+	}
+	if err := d.CheckPreferred("b", 100); err == nil {
+		t.Error("expected the check of all the traffic to fail")
+	}
+	if err := New(nil, ByVersion).CheckPreferred("b", 0); err == nil {
+		t.Error("expected the check without response to fail")
+	}
+}
+
+func TestCheckSuccessRate(t *testing.T) {
+	resp := append(responses(map[string]int{"b-1": 98}, "200"), responses(map[string]int{"b-1": 2}, "503")...)
+	d := New(resp, nil)
+	if err := d.CheckSuccessRate(95); err != nil {
+		t.Error(err)
+	}
+	if err := d.CheckSuccessRate(99); err == nil {
+		t.Error("expected the check to fail")
+	}
+}
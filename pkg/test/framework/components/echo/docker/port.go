@@ -73,13 +73,24 @@ func newPortMap(portMgr reserveport.PortManager, cfg echo.Config) (*portMap, err
 
 func (m *portMap) toEchoArgs() []string {
 	echoArgs := make([]string, 0)
+	hasTLS := false
 	for _, port := range m.ports {
 		portNumber := port.containerPort.ServicePort
-		if port.containerPort.Protocol.IsGRPC() {
+		switch {
+		case port.containerPort.Protocol.IsGRPC():
 			echoArgs = append(echoArgs, "--grpc", strconv.Itoa(portNumber))
-		} else {
+		case port.containerPort.Protocol == protocol.TCP:
+			echoArgs = append(echoArgs, "--tcp", strconv.Itoa(portNumber))
+		default:
 			echoArgs = append(echoArgs, "--port", strconv.Itoa(portNumber))
 		}
+		if port.containerPort.TLS {
+			hasTLS = true
+			echoArgs = append(echoArgs, "--tls", strconv.Itoa(portNumber))
+		}
+	}
+	if hasTLS {
+		echoArgs = append(echoArgs, "--crt", "/cert.crt", "--key", "/cert.key")
 	}
 	return echoArgs
 }
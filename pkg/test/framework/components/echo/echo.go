@@ -95,6 +95,10 @@ type Port struct {
 	// InstancePort number where this instance is listening for connections.
 	// This need not be the same as the ServicePort where the service is accessed.
 	InstancePort int
+
+	// TLS indicates that the application terminates TLS on the port, rather than only its
+	// sidecar. Not supported for TCP ports.
+	TLS bool
 }
 
 // Workload provides an interface for a single deployed echo server.
@@ -0,0 +1,56 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package echo
+
+import (
+	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/resource"
+)
+
+// Instances of a service, or of several services, deployed in the clusters of a multicluster
+// environment.
+type Instances []Instance
+
+// InCluster returns the instances deployed in the given cluster. The instances without cluster are
+// deployed in the primary cluster, of index 0.
+func (i Instances) InCluster(c resource.Cluster) Instances {
+	var out Instances
+	for _, inst := range i {
+		if clusterIndex(inst.Config().Cluster) == clusterIndex(c) {
+			out = append(out, inst)
+		}
+	}
+	return out
+}
+
+// GetOrFail returns the instance of the service deployed in the given cluster, and fails t if there
+// is none.
+func (i Instances) GetOrFail(t test.Failer, service string, c resource.Cluster) Instance {
+	t.Helper()
+	for _, inst := range i.InCluster(c) {
+		if inst.Config().Service == service {
+			return inst
+		}
+	}
+	t.Fatalf("no instance of service %s in cluster %v", service, c)
+	return nil
+}
+
+func clusterIndex(c resource.Cluster) resource.ClusterIndex {
+	if c == nil {
+		return 0
+	}
+	return c.Index()
+}
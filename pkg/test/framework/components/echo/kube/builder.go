@@ -103,14 +103,24 @@ func (b *builder) initializeInstances(instances []echo.Instance) error {
 		serviceName := inst.Config().Service
 		serviceNamespace := inst.Config().Namespace.Name()
 		timeout := inst.Config().ReadinessTimeout
+		cluster := env.GetCluster(inst.Config().Cluster)
+		deployAsVM := inst.Config().DeployAsVM
+		vmInstance := inst.(*instance)
 
 		// Run the waits in parallel.
 		go func() {
 			defer wg.Done()
 
-			// Wait until all the endpoints are ready for this service
-			_, endpoints, err := env.WaitUntilServiceEndpointsAreReady(
-				serviceNamespace, serviceName, retry.Timeout(timeout))
+			var endpoints *kubeCore.Endpoints
+			var err error
+			if deployAsVM {
+				// The VMs have no Kubernetes service: register them once their pods are ready.
+				endpoints, err = vmInstance.registerVMs(timeout)
+			} else {
+				// Wait until all the endpoints are ready for this service
+				_, endpoints, err = cluster.WaitUntilServiceEndpointsAreReady(
+					serviceNamespace, serviceName, retry.Timeout(timeout))
+			}
 			if err != nil {
 				aggregateErrMux.Lock()
 				aggregateErr = multierror.Append(aggregateErr, err)
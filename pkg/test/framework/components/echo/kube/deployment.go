@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"text/template"
 
+	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/test/framework/components/echo"
 	"istio.io/istio/pkg/test/framework/core/image"
 	"istio.io/istio/pkg/test/scopes"
@@ -101,6 +102,8 @@ spec:
 {{- range $i, $p := .ContainerPorts }}
 {{- if eq .Protocol "GRPC" }}
           - --grpc
+{{- else if eq .Protocol "TCP" }}
+          - --tcp
 {{- else }}
           - --port
 {{- end }}
@@ -110,6 +113,16 @@ spec:
           - "8081"
           - --version
           - "{{ .Version }}"
+{{- range $i, $p := .TLSPorts }}
+          - --tls
+          - "{{ $p }}"
+{{- end }}
+{{- if .TLSPorts }}
+          - --crt
+          - /cert.crt
+          - --key
+          - /cert.key
+{{- end }}
         ports:
 {{- range $i, $p := .ContainerPorts }}
         - containerPort: {{ $p.Port }} 
@@ -163,13 +176,25 @@ func init() {
 	}
 }
 
-func generateYAML(cfg echo.Config) (string, error) {
+// generateYAML generates the deployment of the instance. The simulated VMs connect to the control
+// plane of the given system namespace.
+func generateYAML(cfg echo.Config, systemNamespace string) (string, error) {
 	// Create the parameters for the YAML template.
 	settings, err := image.SettingsFromCommandLine()
 	if err != nil {
 		return "", err
 	}
 
+	var tlsPorts []int
+	for _, p := range cfg.Ports {
+		if p.TLS {
+			if p.Protocol == protocol.TCP {
+				return "", fmt.Errorf("port %s: TLS is not supported for TCP ports", p.Name)
+			}
+			tlsPorts = append(tlsPorts, p.InstancePort)
+		}
+	}
+
 	// Separate the annotations.
 	serviceAnnotations := make(map[string]string)
 	workloadAnnotations := make(map[string]string)
@@ -195,11 +220,16 @@ func generateYAML(cfg echo.Config) (string, error) {
 		"ServiceAccount":      cfg.ServiceAccount,
 		"Ports":               cfg.Ports,
 		"ContainerPorts":      getContainerPorts(cfg.Ports),
+		"TLSPorts":            tlsPorts,
 		"ServiceAnnotations":  serviceAnnotations,
 		"WorkloadAnnotations": workloadAnnotations,
 		"IncludeInboundPorts": cfg.IncludeInboundPorts,
+		"SystemNamespace":     systemNamespace,
 	}
 
 	// Generate the YAML content.
+	if cfg.DeployAsVM {
+		return tmpl.Execute(vmDeploymentTemplate, params)
+	}
 	return tmpl.Execute(deploymentTemplate, params)
 }
@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pkg/test/framework/components/echo"
 	"istio.io/istio/pkg/test/framework/components/echo/common"
 	kubeEnv "istio.io/istio/pkg/test/framework/components/environment/kube"
+	"istio.io/istio/pkg/test/framework/components/namespace"
 	"istio.io/istio/pkg/test/framework/resource"
 
 	kubeCore "k8s.io/api/core/v1"
@@ -51,7 +52,7 @@ type instance struct {
 	id        resource.ID
 	cfg       echo.Config
 	clusterIP string
-	env       *kubeEnv.Environment
+	cluster   kubeEnv.Cluster
 	workloads []*workload
 	grpcPort  uint16
 	ctx       resource.Context
@@ -72,10 +73,11 @@ func newInstance(ctx resource.Context, cfg echo.Config) (out *instance, err erro
 	}
 
 	env := ctx.Environment().(*kubeEnv.Environment)
+	cluster := env.GetCluster(cfg.Cluster)
 	c := &instance{
-		env: env,
-		cfg: cfg,
-		ctx: ctx,
+		cluster: cluster,
+		cfg:     cfg,
+		ctx:     ctx,
 	}
 	c.id = ctx.TrackResource(c)
 
@@ -86,19 +88,37 @@ func newInstance(ctx resource.Context, cfg echo.Config) (out *instance, err erro
 	}
 	c.grpcPort = uint16(grpcPort.InstancePort)
 
+	var systemNamespace string
+	if cfg.DeployAsVM {
+		if cfg.Headless {
+			return nil, errors.New("the services deployed on VMs can't be headless")
+		}
+		ns, err := namespace.ClaimSystemNamespace(ctx)
+		if err != nil {
+			return nil, err
+		}
+		systemNamespace = ns.Name()
+	}
+
 	// Generate the deployment YAML.
-	generatedYAML, err := generateYAML(cfg)
+	generatedYAML, err := generateYAML(cfg, systemNamespace)
 	if err != nil {
 		return nil, err
 	}
 
 	// Deploy the YAML.
-	if err = env.ApplyContents(cfg.Namespace.Name(), generatedYAML); err != nil {
+	if _, err = cluster.ApplyContents(cfg.Namespace.Name(), generatedYAML); err != nil {
 		return nil, err
 	}
 
+	if cfg.DeployAsVM {
+		// The VMs are registered once their pods are ready.
+		c.clusterIP = vmAddress(cfg)
+		return c, nil
+	}
+
 	// Now retrieve the service information to find the ClusterIP
-	s, err := env.GetService(cfg.Namespace.Name(), cfg.Service)
+	s, err := cluster.GetService(cfg.Namespace.Name(), cfg.Service)
 	if err != nil {
 		return nil, err
 	}
@@ -223,7 +243,7 @@ func (c *instance) initialize(endpoints *kubeCore.Endpoints) error {
 	workloads := make([]*workload, 0)
 	for _, subset := range endpoints.Subsets {
 		for _, addr := range subset.Addresses {
-			workload, err := newWorkload(addr, c.cfg.Annotations, c.grpcPort, c.env.Accessor, c.ctx)
+			workload, err := newWorkload(addr, c.cfg.Annotations, c.grpcPort, c.cluster.Accessor, c.ctx)
 			if err != nil {
 				return err
 			}
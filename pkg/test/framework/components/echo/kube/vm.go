@@ -0,0 +1,270 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+	"hash/fnv"
+	"text/template"
+	"time"
+
+	kubeCore "k8s.io/api/core/v1"
+
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/test/framework/components/echo"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/pkg/test/util/tmpl"
+)
+
+const (
+	// vmDeploymentYAML deploys a simulated VM: a pod without sidecar injection, whose istio-proxy
+	// container runs the Istio agent as the packages do on a mesh expansion VM. The agent
+	// bootstraps with the credentials a VM is given: the certificates of the service account if
+	// Citadel creates them, otherwise a token of the service account for SDS.
+	vmDeploymentYAML = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: {{ .Service }}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .Service }}-{{ .Version }}
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{ .Service }}
+      version: {{ .Version }}
+  template:
+    metadata:
+      labels:
+        app: {{ .Service }}
+        version: {{ .Version }}
+      annotations:
+        sidecar.istio.io/inject: "false"
+    spec:
+      serviceAccountName: {{ .Service }}
+      containers:
+      - name: app
+        image: {{ .Hub }}/app:{{ .Tag }}
+        imagePullPolicy: {{ .PullPolicy }}
+        args:
+{{- range $i, $p := .ContainerPorts }}
+{{- if eq .Protocol "GRPC" }}
+          - --grpc
+{{- else if eq .Protocol "TCP" }}
+          - --tcp
+{{- else }}
+          - --port
+{{- end }}
+          - "{{ $p.Port }}"
+{{- end }}
+          - --version
+          - "{{ .Version }}"
+{{- range $i, $p := .TLSPorts }}
+          - --tls
+          - "{{ $p }}"
+{{- end }}
+{{- if .TLSPorts }}
+          - --crt
+          - /cert.crt
+          - --key
+          - /cert.key
+{{- end }}
+        ports:
+{{- range $i, $p := .ContainerPorts }}
+        - containerPort: {{ $p.Port }}
+{{- end }}
+      - name: istio-proxy
+        image: {{ .Hub }}/app_sidecar:{{ .Tag }}
+        imagePullPolicy: {{ .PullPolicy }}
+        command:
+        - /bin/bash
+        - -c
+        - cp /var/run/secrets/istio/* /etc/certs/ 2>/dev/null; /usr/local/bin/postinst.sh && exec /usr/local/bin/istio-start.sh
+        securityContext:
+          capabilities:
+            add:
+            - NET_ADMIN
+        env:
+        - name: ISTIO_SERVICE
+          value: {{ .Service }}
+        - name: ISTIO_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: ISTIO_META_CONFIG_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: ISTIO_SYSTEM_NAMESPACE
+          value: {{ .SystemNamespace }}
+        - name: PILOT_ADDRESS
+          value: istio-pilot.{{ .SystemNamespace }}:15010
+        - name: ISTIO_CP_AUTH
+          value: NONE
+        - name: ISTIO_SERVICE_CIDR
+          value: "*"
+        - name: ISTIO_INBOUND_PORTS
+          value: "*"
+        - name: ISTIO_AGENT_FLAGS
+          value: "--statusPort 15020"
+        - name: ISTIO_META_INTERCEPTION_MODE
+          value: REDIRECT
+        - name: ISTIO_METAJSON_LABELS
+          value: '{"app":"{{ .Service }}","version":"{{ .Version }}"}'
+        volumeMounts:
+        - mountPath: /var/run/secrets/istio
+          name: istio-certs
+          readOnly: true
+        - mountPath: /var/run/secrets/tokens
+          name: istio-token
+      volumes:
+      - name: istio-certs
+        secret:
+          secretName: istio.{{ .Service }}
+          optional: true
+      - name: istio-token
+        projected:
+          sources:
+          - serviceAccountToken:
+              path: istio-token
+              expirationSeconds: 43200
+              audience: istio-ca
+`
+
+	// vmRegistrationYAML registers the simulated VMs of a service in the mesh, as their operator
+	// would: with a WorkloadEntry per VM, selected by a ServiceEntry of the service.
+	vmRegistrationYAML = `
+{{- range $i, $address := .Addresses }}
+apiVersion: networking.istio.io/v1alpha3
+kind: WorkloadEntry
+metadata:
+  name: {{ $.Service }}-{{ $.Version }}-{{ $i }}
+spec:
+  address: {{ $address }}
+  labels:
+    app: {{ $.Service }}
+    version: {{ $.Version }}
+    security.istio.io/tlsMode: istio
+  serviceAccount: {{ $.Service }}
+{{- if $.Locality }}
+  locality: {{ $.Locality }}
+{{- end }}
+---
+{{- end }}
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: {{ .Service }}
+  annotations:
+    {{ .WorkloadSelectorAnnotation }}: app={{ .Service }}
+spec:
+  hosts:
+  - {{ .FQDN }}
+  addresses:
+  - {{ .VIP }}
+  location: MESH_INTERNAL
+  resolution: STATIC
+  ports:
+{{- range $i, $p := .Ports }}
+  - name: {{ $p.Name }}
+    number: {{ $p.ServicePort }}
+    protocol: {{ $p.Protocol }}
+{{- end }}
+`
+)
+
+var (
+	vmDeploymentTemplate   *template.Template
+	vmRegistrationTemplate *template.Template
+)
+
+func init() {
+	vmDeploymentTemplate = template.New("echo_vm_deployment")
+	if _, err := vmDeploymentTemplate.Parse(vmDeploymentYAML); err != nil {
+		panic(fmt.Sprintf("unable to parse echo VM deployment template: %v", err))
+	}
+	vmRegistrationTemplate = template.New("echo_vm_registration")
+	if _, err := vmRegistrationTemplate.Parse(vmRegistrationYAML); err != nil {
+		panic(fmt.Sprintf("unable to parse echo VM registration template: %v", err))
+	}
+}
+
+// vmAddress returns the VIP of a service deployed on simulated VMs. The service has no DNS
+// record, so the callers connect to its VIP, which is picked in the reserved 240.240.0.0/16 range
+// from the FQDN of the service.
+func vmAddress(cfg echo.Config) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(cfg.FQDN()))
+	sum := h.Sum32()
+	return fmt.Sprintf("240.240.%d.%d", (sum>>8)&0xff, sum&0xff)
+}
+
+func generateVMRegistrationYAML(cfg echo.Config, pods []kubeCore.Pod) (string, error) {
+	addresses := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		addresses = append(addresses, pod.Status.PodIP)
+	}
+	return tmpl.Execute(vmRegistrationTemplate, map[string]interface{}{
+		"Service":                    cfg.Service,
+		"Version":                    cfg.Version,
+		"Locality":                   cfg.Locality,
+		"FQDN":                       cfg.FQDN(),
+		"VIP":                        vmAddress(cfg),
+		"Ports":                      cfg.Ports,
+		"Addresses":                  addresses,
+		"WorkloadSelectorAnnotation": constants.ServiceEntryWorkloadSelectorAnnotation,
+	})
+}
+
+// registerVMs waits for the pods simulating the VMs of the instance, and registers them in the
+// mesh. It returns the endpoints of the pods, as the Kubernetes service of an instance would have.
+func (c *instance) registerVMs(timeout time.Duration) (*kubeCore.Endpoints, error) {
+	fetch := c.cluster.NewPodFetch(c.cfg.Namespace.Name(), "app="+c.cfg.Service, "version="+c.cfg.Version)
+	pods, err := c.cluster.WaitUntilPodsAreReady(fetch, retry.Timeout(timeout))
+	if err != nil {
+		return nil, err
+	}
+
+	registrationYAML, err := generateVMRegistrationYAML(c.cfg, pods)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.cluster.ApplyContents(c.cfg.Namespace.Name(), registrationYAML); err != nil {
+		return nil, err
+	}
+
+	endpoints := &kubeCore.Endpoints{
+		Subsets: []kubeCore.EndpointSubset{{}},
+	}
+	for _, pod := range pods {
+		endpoints.Subsets[0].Addresses = append(endpoints.Subsets[0].Addresses, kubeCore.EndpointAddress{
+			IP: pod.Status.PodIP,
+			TargetRef: &kubeCore.ObjectReference{
+				Kind:      "Pod",
+				Namespace: pod.Namespace,
+				Name:      pod.Name,
+			},
+		})
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,58 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+
+	"istio.io/istio/pkg/test/framework/resource"
+	"istio.io/istio/pkg/test/kube"
+)
+
+// Cluster is a Kubernetes cluster of the environment.
+type Cluster struct {
+	*kube.Accessor
+
+	filename       string
+	remoteFilename string
+	index          resource.ClusterIndex
+}
+
+var _ resource.Cluster = Cluster{}
+
+// Index implements resource.Cluster
+func (c Cluster) Index() resource.ClusterIndex {
+	return c.index
+}
+
+// Filename returns the path to the kube config file of the cluster.
+func (c Cluster) Filename() string {
+	return c.filename
+}
+
+// RemoteFilename returns the path to the kube config file the control planes of the other
+// clusters access the cluster with. The API server address of the clusters created by kind is
+// only reachable from the other clusters on their network.
+func (c Cluster) RemoteFilename() string {
+	if c.remoteFilename != "" {
+		return c.remoteFilename
+	}
+	return c.filename
+}
+
+// String implements fmt.Stringer
+func (c Cluster) String() string {
+	return fmt.Sprintf("cluster-%d", c.index)
+}
@@ -15,8 +15,10 @@
 package kube
 
 import (
+	"errors"
 	"flag"
 	"os"
+	"strings"
 
 	"github.com/mitchellh/go-homedir"
 
@@ -25,9 +27,17 @@ import (
 
 var (
 	// Settings we will collect from the command-line.
-	settingsFromCommandLine = &Settings{
-		KubeConfig: env.ISTIO_TEST_KUBE_CONFIG.Value(),
-	}
+	settingsFromCommandLine = &Settings{}
+
+	// kubeConfigs is the comma-separated list of the kube config files of the clusters.
+	kubeConfigs = env.ISTIO_TEST_KUBE_CONFIG.Value()
+
+	// controlPlaneTopology maps the clusters to the cluster running their control plane, in the
+	// form 1:0,2:0.
+	controlPlaneTopology string
+
+	// topologyFile is the path to the file describing the clusters, instead of the flags.
+	topologyFile string
 )
 
 // newSettingsFromCommandline returns Settings obtained from command-line flags. flag.Parse must be called before calling this function.
@@ -38,10 +48,35 @@ func newSettingsFromCommandline() (*Settings, error) {
 
 	s := settingsFromCommandLine.clone()
 
-	if s.KubeConfig != "" {
-		if err := normalizeFile(&s.KubeConfig); err != nil {
+	if topologyFile != "" {
+		if kubeConfigs != "" || s.KindClusters > 0 || controlPlaneTopology != "" {
+			return nil, errors.New("the topology file and the flags describing the clusters are mutually exclusive")
+		}
+		if err := normalizeFile(&topologyFile); err != nil {
+			return nil, err
+		}
+		if err := readTopologyFile(topologyFile, s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	for _, kc := range strings.Split(kubeConfigs, ",") {
+		if kc = strings.TrimSpace(kc); kc == "" {
+			continue
+		}
+		if err := normalizeFile(&kc); err != nil {
 			return nil, err
 		}
+		s.KubeConfig = append(s.KubeConfig, kc)
+	}
+	if len(s.KubeConfig) > 0 && s.KindClusters > 0 {
+		return nil, errors.New("kube config files and kind clusters are mutually exclusive")
+	}
+
+	var err error
+	if s.ControlPlaneTopology, err = parseControlPlaneTopology(controlPlaneTopology, s.clusterCount()); err != nil {
+		return nil, err
 	}
 
 	return s, nil
@@ -67,8 +102,17 @@ func checkFileExists(path string) error {
 
 // init registers the command-line flags that we can exposed for "go test".
 func init() {
-	flag.StringVar(&settingsFromCommandLine.KubeConfig, "istio.test.kube.config", settingsFromCommandLine.KubeConfig,
-		"The path to the kube config file for cluster environments")
+	flag.StringVar(&kubeConfigs, "istio.test.kube.config", kubeConfigs,
+		"A comma-separated list of paths to the kube config files of the clusters for cluster environments, "+
+			"the first one being the primary cluster")
+	flag.IntVar(&settingsFromCommandLine.KindClusters, "istio.test.kube.kind.clusters", settingsFromCommandLine.KindClusters,
+		"The number of kind clusters created for the tests when no kube config file is provided")
+	flag.StringVar(&controlPlaneTopology, "istio.test.kube.controlPlaneTopology", controlPlaneTopology,
+		"Maps clusters to the cluster running their control plane, in the form 1:0,2:0, by cluster index. "+
+			"Clusters not listed run their own control plane")
+	flag.StringVar(&topologyFile, "istio.test.kube.topology", topologyFile,
+		"The path to a YAML file describing the clusters: their kube config file, network, control plane "+
+			"and registries. Exclusive with the other flags describing the clusters")
 	flag.BoolVar(&settingsFromCommandLine.Minikube, "istio.test.kube.minikube", settingsFromCommandLine.Minikube,
 		"Indicates that the target environment is Minikube. Used by Ingress component to obtain the right IP address..")
 }
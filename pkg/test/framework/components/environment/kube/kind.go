@@ -0,0 +1,76 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package kube
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/hashicorp/go-multierror"
+
+	"istio.io/istio/pkg/test/scopes"
+	"istio.io/istio/pkg/test/shell"
+)
+
+// kindCluster is a cluster created by kind for the tests.
+type kindCluster struct {
+	name             string
+	kubeConfig       string
+	remoteKubeConfig string
+}
+
+// createKindClusters creates count kind clusters, writing their kube config files to workDir.
+func createKindClusters(count int, workDir string) ([]kindCluster, error) {
+	out := make([]kindCluster, 0, count)
+	for i := 0; i < count; i++ {
+		c := kindCluster{
+			name:             fmt.Sprintf("istio-test-%d", i),
+			kubeConfig:       path.Join(workDir, fmt.Sprintf("kind-%d.kubeconfig", i)),
+			remoteKubeConfig: path.Join(workDir, fmt.Sprintf("kind-%d-internal.kubeconfig", i)),
+		}
+		scopes.CI.Infof("Creating kind cluster %s", c.name)
+		if output, err := shell.ExecuteArgs(nil, true, "kind", "create", "cluster", "--name", c.name,
+			"--kubeconfig", c.kubeConfig, "--wait", "120s"); err != nil {
+			_ = deleteKindClusters(out)
+			return nil, fmt.Errorf("failed to create kind cluster %s: %v: %s", c.name, err, output)
+		}
+		out = append(out, c)
+
+		internal, err := shell.ExecuteArgs(nil, false, "kind", "get", "kubeconfig", "--internal", "--name", c.name)
+		if err != nil {
+			_ = deleteKindClusters(out)
+			return nil, fmt.Errorf("failed to get the internal kube config of kind cluster %s: %v", c.name, err)
+		}
+		if err := ioutil.WriteFile(c.remoteKubeConfig, []byte(internal), os.ModePerm); err != nil {
+			_ = deleteKindClusters(out)
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// deleteKindClusters deletes the given kind clusters.
+func deleteKindClusters(clusters []kindCluster) error {
+	var err error
+	for _, c := range clusters {
+		scopes.CI.Infof("Deleting kind cluster %s", c.name)
+		if output, e := shell.ExecuteArgs(nil, true, "kind", "delete", "cluster", "--name", c.name); e != nil {
+			err = multierror.Append(err, fmt.Errorf("failed to delete kind cluster %s: %v: %s", c.name, e, output))
+		}
+	}
+	return err
+}
@@ -15,6 +15,9 @@
 package kube
 
 import (
+	"fmt"
+	"io"
+
 	"istio.io/istio/pkg/test/deployment"
 	"istio.io/istio/pkg/test/framework/components/environment"
 	"istio.io/istio/pkg/test/framework/components/environment/api"
@@ -29,11 +32,18 @@ type Environment struct {
 	id resource.ID
 
 	ctx api.Context
+	// Accessor of the primary cluster.
 	*kube.Accessor
-	s *Settings
+	// KubeClusters are the clusters of the environment, the first one being the primary cluster.
+	KubeClusters []Cluster
+	s            *Settings
+
+	// kindClusters are the clusters created by the environment, deleted when it is closed.
+	kindClusters []kindCluster
 }
 
 var _ resource.Environment = &Environment{}
+var _ io.Closer = &Environment{}
 
 // New returns a new Kubernetes environment
 func New(ctx api.Context) (resource.Environment, error) {
@@ -55,8 +65,35 @@ func New(ctx api.Context) (resource.Environment, error) {
 	}
 	e.id = ctx.TrackResource(e)
 
-	if e.Accessor, err = kube.NewAccessor(s.KubeConfig, workDir); err != nil {
-		return nil, err
+	if len(s.KubeConfig) == 0 && s.KindClusters > 0 {
+		if e.kindClusters, err = createKindClusters(s.KindClusters, workDir); err != nil {
+			return nil, err
+		}
+		for _, c := range e.kindClusters {
+			s.KubeConfig = append(s.KubeConfig, c.kubeConfig)
+		}
+	}
+	if len(s.KubeConfig) == 0 {
+		// the accessor falls back to the in-cluster configuration
+		s.KubeConfig = []string{""}
+	}
+
+	for i, kc := range s.KubeConfig {
+		a, err := kube.NewAccessor(kc, workDir)
+		if err != nil {
+			return nil, err
+		}
+		c := Cluster{Accessor: a, filename: kc, index: resource.ClusterIndex(i)}
+		if i < len(e.kindClusters) {
+			c.remoteFilename = e.kindClusters[i].remoteKubeConfig
+		}
+		e.KubeClusters = append(e.KubeClusters, c)
+	}
+	e.Accessor = e.KubeClusters[0].Accessor
+	if s.ControlPlaneTopology == nil {
+		if s.ControlPlaneTopology, err = parseControlPlaneTopology("", len(e.KubeClusters)); err != nil {
+			return nil, err
+		}
 	}
 
 	return e, nil
@@ -83,6 +120,58 @@ func (e *Environment) Settings() *Settings {
 	return e.s.clone()
 }
 
+// IsMulticluster returns whether the environment has more than one cluster.
+func (e *Environment) IsMulticluster() bool {
+	return len(e.KubeClusters) > 1
+}
+
+// GetCluster returns the cluster of the environment with the index of the given cluster, the
+// primary cluster if nil.
+func (e *Environment) GetCluster(c resource.Cluster) Cluster {
+	if c == nil {
+		return e.KubeClusters[0]
+	}
+	return e.KubeClusters[c.Index()]
+}
+
+// ControlPlaneCluster returns the cluster running the control plane of the given cluster, the
+// primary cluster if nil.
+func (e *Environment) ControlPlaneCluster(c resource.Cluster) Cluster {
+	cluster := e.GetCluster(c)
+	if controlPlane, ok := e.s.ControlPlaneTopology[cluster.Index()]; ok {
+		return e.KubeClusters[controlPlane]
+	}
+	return cluster
+}
+
+// IsControlPlaneCluster returns whether the given cluster runs a control plane.
+func (e *Environment) IsControlPlaneCluster(c resource.Cluster) bool {
+	return e.ControlPlaneCluster(c).Index() == e.GetCluster(c).Index()
+}
+
+// ClusterNetwork returns the network of the pods of the given cluster, the primary cluster if nil,
+// or "" if the clusters have no network.
+func (e *Environment) ClusterNetwork(c resource.Cluster) string {
+	return e.s.Networks[e.GetCluster(c).Index()]
+}
+
+// ControlPlaneRegistries returns the service registries of the control plane running in the given
+// cluster, the primary cluster if nil, or nil for the default registries.
+func (e *Environment) ControlPlaneRegistries(c resource.Cluster) []string {
+	return e.s.Registries[e.GetCluster(c).Index()]
+}
+
+// Close implements io.Closer, deleting the clusters created by the environment.
+func (e *Environment) Close() error {
+	if len(e.kindClusters) == 0 || e.ctx.Settings().NoCleanup {
+		return nil
+	}
+	if err := deleteKindClusters(e.kindClusters); err != nil {
+		return fmt.Errorf("failed to delete the clusters of the environment: %v", err)
+	}
+	return nil
+}
+
 // ApplyContents applies the given yaml contents to the namespace.
 func (e *Environment) ApplyContents(namespace, yml string) error {
 	_, err := e.Accessor.ApplyContents(namespace, yml)
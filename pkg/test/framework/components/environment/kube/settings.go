@@ -16,12 +16,34 @@ package kube
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"istio.io/istio/pkg/test/framework/resource"
 )
 
 // Settings provide kube-specific Settings from flags.
 type Settings struct {
-	// Path to kube config file. Required if the environment is kubernetes.
-	KubeConfig string
+	// Paths to the kube config files of the clusters, the first one being the primary cluster.
+	// Required if the environment is kubernetes, unless KindClusters is set.
+	KubeConfig []string
+
+	// KindClusters is the number of kind clusters created for the tests, and deleted after them,
+	// when no kube config file is provided.
+	KindClusters int
+
+	// ControlPlaneTopology maps the index of each cluster to the index of the cluster running its
+	// control plane. Clusters running their own control plane map to themselves.
+	ControlPlaneTopology map[resource.ClusterIndex]resource.ClusterIndex
+
+	// Networks maps the index of each cluster to the network of its pods. The clusters not listed
+	// have no network.
+	Networks map[resource.ClusterIndex]string
+
+	// Registries maps the index of each cluster running a control plane to the service registries
+	// of the control plane. The control planes not listed use the default registries.
+	Registries map[resource.ClusterIndex][]string
 
 	// Indicates that the Ingress Gateway is not available. This typically happens in Minikube. The Ingress
 	// component will fall back to node-port in this case.
@@ -30,15 +52,99 @@ type Settings struct {
 
 func (s *Settings) clone() *Settings {
 	c := *s
+	c.KubeConfig = append([]string(nil), s.KubeConfig...)
+	if s.ControlPlaneTopology != nil {
+		c.ControlPlaneTopology = make(map[resource.ClusterIndex]resource.ClusterIndex, len(s.ControlPlaneTopology))
+		for k, v := range s.ControlPlaneTopology {
+			c.ControlPlaneTopology[k] = v
+		}
+	}
+	if s.Networks != nil {
+		c.Networks = make(map[resource.ClusterIndex]string, len(s.Networks))
+		for k, v := range s.Networks {
+			c.Networks[k] = v
+		}
+	}
+	if s.Registries != nil {
+		c.Registries = make(map[resource.ClusterIndex][]string, len(s.Registries))
+		for k, v := range s.Registries {
+			c.Registries[k] = append([]string(nil), v...)
+		}
+	}
 	return &c
 }
 
+// clusterCount returns the number of clusters of the environment.
+func (s *Settings) clusterCount() int {
+	if len(s.KubeConfig) > 0 {
+		return len(s.KubeConfig)
+	}
+	return s.KindClusters
+}
+
+// parseControlPlaneTopology parses a topology of the form 1:0,2:0, mapping clusters to the
+// cluster running their control plane, for clusterCount clusters. The clusters not listed run
+// their own control plane.
+func parseControlPlaneTopology(topology string, clusterCount int) (map[resource.ClusterIndex]resource.ClusterIndex, error) {
+	out := make(map[resource.ClusterIndex]resource.ClusterIndex, clusterCount)
+	for i := 0; i < clusterCount; i++ {
+		out[resource.ClusterIndex(i)] = resource.ClusterIndex(i)
+	}
+	if topology == "" {
+		return out, nil
+	}
+
+	parse := func(v string) (resource.ClusterIndex, error) {
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || i < 0 || i >= clusterCount {
+			return 0, fmt.Errorf("invalid cluster index %q in control plane topology %q", v, topology)
+		}
+		return resource.ClusterIndex(i), nil
+	}
+	for _, entry := range strings.Split(topology, ",") {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid control plane topology entry %q, expected cluster:controlPlaneCluster", entry)
+		}
+		cluster, err := parse(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		controlPlane, err := parse(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		out[cluster] = controlPlane
+	}
+
+	for cluster, controlPlane := range out {
+		if out[controlPlane] != controlPlane {
+			return nil, fmt.Errorf("the control plane of cluster %d is in cluster %d, which has no control plane",
+				cluster, controlPlane)
+		}
+	}
+	return out, nil
+}
+
 // String implements fmt.Stringer
 func (s *Settings) String() string {
 	result := ""
 
-	result += fmt.Sprintf("KubeConfig:      %s\n", s.KubeConfig)
-	result += fmt.Sprintf("MiniKubeIngress: %v\n", s.Minikube)
+	result += fmt.Sprintf("KubeConfig:           %s\n", strings.Join(s.KubeConfig, ","))
+	result += fmt.Sprintf("KindClusters:         %d\n", s.KindClusters)
+	result += fmt.Sprintf("ControlPlaneTopology: %s\n", s.topologyString())
+	result += fmt.Sprintf("Networks:             %v\n", s.Networks)
+	result += fmt.Sprintf("Registries:           %v\n", s.Registries)
+	result += fmt.Sprintf("MiniKubeIngress:      %v\n", s.Minikube)
 
 	return result
 }
+
+func (s *Settings) topologyString() string {
+	entries := make([]string, 0, len(s.ControlPlaneTopology))
+	for cluster, controlPlane := range s.ControlPlaneTopology {
+		entries = append(entries, fmt.Sprintf("%d:%d", cluster, controlPlane))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
@@ -0,0 +1,232 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package kube
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"istio.io/istio/pkg/test/framework/resource"
+)
+
+func TestParseControlPlaneTopology(t *testing.T) {
+	cases := []struct {
+		name     string
+		topology string
+		clusters int
+		want     map[resource.ClusterIndex]resource.ClusterIndex
+		wantErr  string
+	}{
+		{
+			name:     "single cluster",
+			clusters: 1,
+			want:     map[resource.ClusterIndex]resource.ClusterIndex{0: 0},
+		},
+		{
+			name:     "control plane per cluster",
+			clusters: 2,
+			want:     map[resource.ClusterIndex]resource.ClusterIndex{0: 0, 1: 1},
+		},
+		{
+			name:     "shared control plane",
+			topology: "1:0, 2:0",
+			clusters: 3,
+			want:     map[resource.ClusterIndex]resource.ClusterIndex{0: 0, 1: 0, 2: 0},
+		},
+		{
+			name:     "control plane out of the primary cluster",
+			topology: "0:1",
+			clusters: 2,
+			want:     map[resource.ClusterIndex]resource.ClusterIndex{0: 1, 1: 1},
+		},
+		{
+			name:     "unknown cluster",
+			topology: "2:0",
+			clusters: 2,
+			wantErr:  `invalid cluster index "2"`,
+		},
+		{
+			name:     "malformed entry",
+			topology: "1",
+			clusters: 2,
+			wantErr:  `invalid control plane topology entry "1"`,
+		},
+		{
+			name:     "control plane in a cluster without control plane",
+			topology: "1:0,2:1",
+			clusters: 3,
+			wantErr:  "which has no control plane",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := parseControlPlaneTopology(c.topology, c.clusters)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestTopology(t *testing.T) {
+	kubeConfig, err := ioutil.TempFile("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(kubeConfig.Name()) }()
+
+	cases := []struct {
+		name     string
+		topology string
+		want     *Settings
+		wantErr  string
+	}{
+		{
+			name: "single kind cluster",
+			topology: `
+clusters:
+- name: primary`,
+			want: &Settings{
+				KindClusters:         1,
+				ControlPlaneTopology: map[resource.ClusterIndex]resource.ClusterIndex{0: 0},
+				Networks:             map[resource.ClusterIndex]string{},
+				Registries:           map[resource.ClusterIndex][]string{},
+			},
+		},
+		{
+			name: "multi-network kind clusters",
+			topology: `
+clusters:
+- name: east
+  network: network-1
+- name: west
+  network: network-2
+  registries: [Kubernetes, MCP]`,
+			want: &Settings{
+				KindClusters:         2,
+				ControlPlaneTopology: map[resource.ClusterIndex]resource.ClusterIndex{0: 0, 1: 1},
+				Networks:             map[resource.ClusterIndex]string{0: "network-1", 1: "network-2"},
+				Registries:           map[resource.ClusterIndex][]string{1: {"Kubernetes", "MCP"}},
+			},
+		},
+		{
+			name: "shared control plane",
+			topology: `
+clusters:
+- name: primary
+  kubeconfig: ` + kubeConfig.Name() + `
+- name: remote
+  kubeconfig: ` + kubeConfig.Name() + `
+  controlPlane: primary`,
+			want: &Settings{
+				KubeConfig:           []string{kubeConfig.Name(), kubeConfig.Name()},
+				ControlPlaneTopology: map[resource.ClusterIndex]resource.ClusterIndex{0: 0, 1: 0},
+				Networks:             map[resource.ClusterIndex]string{},
+				Registries:           map[resource.ClusterIndex][]string{},
+			},
+		},
+		{
+			name:     "no cluster",
+			topology: `clusters: []`,
+			wantErr:  "no cluster",
+		},
+		{
+			name: "unknown field",
+			topology: `
+clusters:
+- name: primary
+  zone: us-east-1`,
+			wantErr: "failed to parse",
+		},
+		{
+			name: "some clusters with a kube config file",
+			topology: `
+clusters:
+- name: primary
+  kubeconfig: ` + kubeConfig.Name() + `
+- name: remote`,
+			wantErr: "either all the clusters or none of them",
+		},
+		{
+			name: "unknown control plane",
+			topology: `
+clusters:
+- name: primary
+- name: remote
+  controlPlane: other`,
+			wantErr: `unknown control plane cluster "other"`,
+		},
+		{
+			name: "registries without control plane",
+			topology: `
+clusters:
+- name: primary
+- name: remote
+  controlPlane: primary
+  registries: [Kubernetes]`,
+			wantErr: `cluster "remote" has registries but no control plane`,
+		},
+		{
+			name: "control plane in another network",
+			topology: `
+clusters:
+- name: primary
+  network: network-1
+- name: remote
+  network: network-2
+  controlPlane: primary`,
+			wantErr: `not in the network of its control plane`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			f, err := ioutil.TempFile("", "topology")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = os.Remove(f.Name()) }()
+			if err := ioutil.WriteFile(f.Name(), []byte(c.topology), os.ModePerm); err != nil {
+				t.Fatal(err)
+			}
+
+			got := &Settings{KindClusters: 3}
+			err = readTopologyFile(f.Name(), got)
+			if c.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+					t.Fatalf("got error %v, want %q", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
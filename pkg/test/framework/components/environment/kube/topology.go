@@ -0,0 +1,155 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package kube
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+
+	"istio.io/istio/pkg/test/framework/resource"
+)
+
+// topology describes the clusters of the environment. It is an alternative to the flags
+// describing them, so that the same tests can run against various topologies, e.g.:
+//
+//	clusters:
+//	- name: primary
+//	  kubeconfig: ~/.kube/primary
+//	  network: network-1
+//	- name: remote
+//	  kubeconfig: ~/.kube/remote
+//	  network: network-1
+//	  controlPlane: primary
+//
+// The clusters are created with kind when no cluster has a kube config file.
+type topology struct {
+	Clusters []clusterTopology `json:"clusters"`
+}
+
+type clusterTopology struct {
+	// Name of the cluster, referenced by the other clusters of the file.
+	Name string `json:"name"`
+
+	// Kubeconfig is the path to the kube config file of the cluster.
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+
+	// Network of the pods of the cluster.
+	Network string `json:"network,omitempty"`
+
+	// ControlPlane is the name of the cluster running the control plane of the cluster. Defaults
+	// to the cluster itself.
+	ControlPlane string `json:"controlPlane,omitempty"`
+
+	// Registries are the service registries of the control plane of the cluster, e.g. Kubernetes
+	// and MCP. Only valid for the clusters running a control plane.
+	Registries []string `json:"registries,omitempty"`
+}
+
+// readTopologyFile reads the topology file into the settings.
+func readTopologyFile(filename string, s *Settings) error {
+	by, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read the topology file: %v", err)
+	}
+	t := &topology{}
+	if err := yaml.UnmarshalStrict(by, t); err != nil {
+		return fmt.Errorf("failed to parse the topology file %s: %v", filename, err)
+	}
+	if err := t.apply(s); err != nil {
+		return fmt.Errorf("invalid topology file %s: %v", filename, err)
+	}
+	return nil
+}
+
+// apply sets the clusters of the settings from the topology.
+func (t *topology) apply(s *Settings) error {
+	if len(t.Clusters) == 0 {
+		return errors.New("no cluster")
+	}
+
+	indexes := make(map[string]resource.ClusterIndex, len(t.Clusters))
+	withKubeconfig := 0
+	for i, c := range t.Clusters {
+		if c.Name == "" {
+			return fmt.Errorf("cluster %d has no name", i)
+		}
+		if _, ok := indexes[c.Name]; ok {
+			return fmt.Errorf("duplicate cluster %q", c.Name)
+		}
+		indexes[c.Name] = resource.ClusterIndex(i)
+		if c.Kubeconfig != "" {
+			withKubeconfig++
+		}
+	}
+	if withKubeconfig != 0 && withKubeconfig != len(t.Clusters) {
+		return errors.New("either all the clusters or none of them must have a kube config file")
+	}
+
+	s.KubeConfig = nil
+	s.KindClusters = 0
+	if withKubeconfig == 0 {
+		s.KindClusters = len(t.Clusters)
+	}
+	s.ControlPlaneTopology = make(map[resource.ClusterIndex]resource.ClusterIndex, len(t.Clusters))
+	s.Networks = make(map[resource.ClusterIndex]string, len(t.Clusters))
+	s.Registries = make(map[resource.ClusterIndex][]string)
+	for i, c := range t.Clusters {
+		index := resource.ClusterIndex(i)
+		if c.Kubeconfig != "" {
+			kc := c.Kubeconfig
+			if err := normalizeFile(&kc); err != nil {
+				return fmt.Errorf("kube config file of cluster %q: %v", c.Name, err)
+			}
+			s.KubeConfig = append(s.KubeConfig, kc)
+		}
+		s.ControlPlaneTopology[index] = index
+		if c.ControlPlane != "" {
+			controlPlane, ok := indexes[c.ControlPlane]
+			if !ok {
+				return fmt.Errorf("unknown control plane cluster %q of cluster %q", c.ControlPlane, c.Name)
+			}
+			s.ControlPlaneTopology[index] = controlPlane
+		}
+		if c.Network != "" {
+			s.Networks[index] = c.Network
+		}
+		if len(c.Registries) > 0 {
+			s.Registries[index] = c.Registries
+		}
+	}
+
+	for i, c := range t.Clusters {
+		index := resource.ClusterIndex(i)
+		controlPlane := s.ControlPlaneTopology[index]
+		if controlPlane == index {
+			continue
+		}
+		if s.ControlPlaneTopology[controlPlane] != controlPlane {
+			return fmt.Errorf("the control plane of cluster %q is in cluster %q, which has no control plane",
+				c.Name, c.ControlPlane)
+		}
+		if len(c.Registries) > 0 {
+			return fmt.Errorf("cluster %q has registries but no control plane", c.Name)
+		}
+		// The remote clusters reach their control plane at its pod address.
+		if s.Networks[index] != s.Networks[controlPlane] {
+			return fmt.Errorf("cluster %q is not in the network of its control plane cluster %q", c.Name, c.ControlPlane)
+		}
+	}
+	return nil
+}
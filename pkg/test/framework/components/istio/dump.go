@@ -0,0 +1,146 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package istio
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	kubeApiCore "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"istio.io/istio/pkg/test/framework/components/environment/kube"
+	"istio.io/istio/pkg/test/framework/resource"
+	"istio.io/istio/pkg/test/scopes"
+)
+
+var _ resource.StateDumper = &kubeComponent{}
+
+// DumpState dumps the logs of the control plane, the configuration and stats of the proxies and the Istio
+// configuration of each cluster into dir.
+func (i *kubeComponent) DumpState(dir string) {
+	for _, cluster := range i.environment.KubeClusters {
+		cd := dir
+		if i.environment.IsMulticluster() {
+			cd = path.Join(dir, cluster.String())
+		}
+		proxiesDir := path.Join(cd, "proxies")
+		configDir := path.Join(cd, "config")
+		if err := mkdirs(cd, proxiesDir, configDir); err != nil {
+			scopes.CI.Errorf("Unable to create directory for dumping the state of %s: %v", cluster, err)
+			continue
+		}
+
+		dumpCluster(cd, i.settings.SystemNamespace, cluster)
+		dumpProxies(proxiesDir, cluster)
+		dumpIstioConfig(configDir, cluster)
+	}
+}
+
+// dumpProxies dumps the configuration and the stats of the running proxies of the cluster.
+func dumpProxies(d string, cluster kube.Cluster) {
+	pods, err := cluster.GetPods("")
+	if err != nil {
+		scopes.CI.Errorf("Unable to get pods from %s: %v", cluster, err)
+		return
+	}
+
+	for _, pod := range pods {
+		if pod.Status.Phase != kubeApiCore.PodRunning || !hasContainer(pod.Spec.Containers, "istio-proxy") {
+			continue
+		}
+		for _, request := range []struct {
+			path string
+			file string
+		}{
+			{"config_dump", "config_dump.json"},
+			{"stats", "stats.txt"},
+		} {
+			out, err := cluster.Exec(pod.Namespace, pod.Name, "istio-proxy", "pilot-agent request GET "+request.path)
+			if err != nil {
+				scopes.CI.Errorf("Unable to get %s of proxy %s/%s: %v", request.path, pod.Namespace, pod.Name, err)
+				continue
+			}
+			fname := path.Join(d, fmt.Sprintf("%s_%s_%s", pod.Namespace, pod.Name, request.file))
+			if err = ioutil.WriteFile(fname, []byte(out), os.ModePerm); err != nil {
+				scopes.CI.Errorf("Unable to write %s of proxy %s/%s: %v", request.path, pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+}
+
+// dumpIstioConfig dumps the resources of the Istio CRDs of the cluster, a file per CRD.
+func dumpIstioConfig(d string, cluster kube.Cluster) {
+	crds, err := cluster.GetCustomResourceDefinitions()
+	if err != nil {
+		scopes.CI.Errorf("Unable to get CRDs from %s: %v", cluster, err)
+		return
+	}
+
+	for _, crd := range crds {
+		if !strings.HasSuffix(crd.Spec.Group, "istio.io") {
+			continue
+		}
+		gvr := schema.GroupVersionResource{
+			Group:    crd.Spec.Group,
+			Version:  crd.Spec.Version,
+			Resource: crd.Spec.Names.Plural,
+		}
+		list, err := cluster.ListUnstructured(gvr, "")
+		if err != nil {
+			scopes.CI.Errorf("Unable to list %s from %s: %v", crd.Name, cluster, err)
+			continue
+		}
+		if len(list.Items) == 0 {
+			continue
+		}
+
+		var out []string
+		for _, item := range list.Items {
+			by, err := yaml.Marshal(item.Object)
+			if err != nil {
+				scopes.CI.Errorf("Unable to marshal %s %s/%s: %v", crd.Name, item.GetNamespace(), item.GetName(), err)
+				continue
+			}
+			out = append(out, string(by))
+		}
+		fname := path.Join(d, crd.Name+".yaml")
+		if err = ioutil.WriteFile(fname, []byte(strings.Join(out, "---\n")), os.ModePerm); err != nil {
+			scopes.CI.Errorf("Unable to write %s: %v", crd.Name, err)
+		}
+	}
+}
+
+func hasContainer(containers []kubeApiCore.Container, name string) bool {
+	for _, c := range containers {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func mkdirs(dirs ...string) error {
+	for _, d := range dirs {
+		if err := os.MkdirAll(d, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -72,22 +72,64 @@ func deploy(ctx resource.Context, env *kube.Environment, cfg Config) (Instance,
 		return nil, err
 	}
 
+	// Deploy the control planes first, the clusters without control plane connect to them.
+	for _, cluster := range env.KubeClusters {
+		if env.IsControlPlaneCluster(cluster) {
+			if err := i.deployCluster(clusterWorkDir(workDir, cluster), cluster, clusterConfig(env, cluster, cfg)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, cluster := range env.KubeClusters {
+		if env.IsControlPlaneCluster(cluster) {
+			continue
+		}
+		remoteCfg, err := remoteConfig(env.ControlPlaneCluster(cluster), cfg)
+		if err != nil {
+			i.Dump()
+			return nil, err
+		}
+		if err := i.deployCluster(clusterWorkDir(workDir, cluster), cluster, clusterConfig(env, cluster, remoteCfg)); err != nil {
+			return nil, err
+		}
+	}
+
+	if env.IsMulticluster() {
+		if err := configureDiscovery(env, cfg); err != nil {
+			i.Dump()
+			return nil, err
+		}
+	}
+
+	return i, nil
+}
+
+// clusterWorkDir returns the work dir of the deployment of Istio in the cluster.
+func clusterWorkDir(workDir string, cluster kube.Cluster) string {
+	if cluster.Index() == 0 {
+		return workDir
+	}
+	return path.Join(workDir, cluster.String())
+}
+
+// deployCluster deploys Istio in the cluster with the configuration.
+func (i *kubeComponent) deployCluster(workDir string, cluster kube.Cluster, cfg Config) error {
 	// Create helm working dir
 	helmWorkDir := path.Join(workDir, "helm")
 	if err := os.MkdirAll(helmWorkDir, os.ModePerm); err != nil {
-		return nil, err
+		return err
 	}
 
 	// First, generate CRDs.
 	crdYaml, err := generateCRDYaml(cfg.CrdsFilesDir)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Generate rendered yaml file for Istio, including namespace.
 	istioYaml, err := generateIstioYaml(helmWorkDir, cfg)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// split installation & configuration into two distinct steps, so that we can submit configuration before waiting
@@ -97,59 +139,62 @@ func deploy(ctx resource.Context, env *kube.Environment, cfg Config) (Instance,
 	// Write out as files for deployment and debugging purposes.
 	crdFile := path.Join(workDir, "crd.yaml")
 	if err = ioutil.WriteFile(crdFile, []byte(crdYaml), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to write %q: %v", crdFile, err)
+		return fmt.Errorf("unable to write %q: %v", crdFile, err)
 	}
 	istioFile := path.Join(workDir, "istio.yaml")
 	if err = ioutil.WriteFile(istioFile, []byte(istioYaml), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to write %q: %v", istioFile, err)
+		return fmt.Errorf("unable to write %q: %v", istioFile, err)
 	}
 	istioInstallFile := path.Join(workDir, "istio-install-only.yaml")
 	if err = ioutil.WriteFile(istioInstallFile, []byte(installYaml), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to write %q: %v", istioInstallFile, err)
+		return fmt.Errorf("unable to write %q: %v", istioInstallFile, err)
 	}
 	istioConfigFile := path.Join(workDir, "istio-config-only.yaml")
 	if err = ioutil.WriteFile(istioConfigFile, []byte(configureYaml), os.ModePerm); err != nil {
-		return nil, fmt.Errorf("unable to write %q: %v", istioConfigFile, err)
+		return fmt.Errorf("unable to write %q: %v", istioConfigFile, err)
 	}
-	scopes.CI.Infof("Wrote out istio deployment files at: %s", workDir)
+	scopes.CI.Infof("Wrote out istio deployment files of %s at: %s", cluster, workDir)
 
 	// Apply CRDs first.
-	if err = env.Accessor.Apply("", crdFile); err != nil {
-		return nil, err
+	if err = cluster.Apply("", crdFile); err != nil {
+		return err
 	}
 
 	// Deploy Istio.
-	i.deployment = deployment.NewYamlDeployment(cfg.SystemNamespace, istioInstallFile)
-	if err = i.deployment.Deploy(env.Accessor, true, retry.Timeout(cfg.DeployTimeout)); err != nil {
+	d := deployment.NewYamlDeployment(cfg.SystemNamespace, istioInstallFile)
+	if err = d.Deploy(cluster.Accessor, true, retry.Timeout(cfg.DeployTimeout)); err != nil {
 		i.Dump()
-		return nil, err
+		return err
+	}
+	if cluster.Index() == 0 {
+		i.deployment = d
 	}
 
 	if !cfg.SkipWaitForValidationWebhook {
 
 		// Wait for Galley & the validation webhook to come online before applying Istio configurations.
-		if _, _, err = env.WaitUntilServiceEndpointsAreReady(cfg.SystemNamespace, "istio-galley"); err != nil {
-			err = fmt.Errorf("error waiting %s/istio-galley service endpoints: %v", cfg.SystemNamespace, err)
+		if _, _, err = cluster.WaitUntilServiceEndpointsAreReady(cfg.SystemNamespace, "istio-galley"); err != nil {
+			err = fmt.Errorf("error waiting %s/istio-galley service endpoints of %s: %v", cfg.SystemNamespace, cluster, err)
 			scopes.CI.Info(err.Error())
 			i.Dump()
-			return nil, err
+			return err
 		}
 
 		// Wait for webhook to come online. The only reliable way to do that is to see if we can submit invalid config.
-		err = waitForValidationWebhook(env.Accessor)
+		err = waitForValidationWebhook(cluster.Accessor)
 		if err != nil {
 			i.Dump()
-			return nil, err
+			return err
 		}
 	}
 
 	// Then, apply Istio configuration.
-	if err = env.Accessor.Apply("", istioConfigFile); err != nil {
+	if err = cluster.Apply("", istioConfigFile); err != nil {
 		i.Dump()
-		return nil, err
+		return err
 	}
 
-	return i, nil
+	return nil
 }
 
 // ID implements resource.Instance
@@ -165,45 +210,49 @@ func (i *kubeComponent) Close() error {
 	var err *multierror.Error
 
 	if i.settings.DeployIstio {
-		// TODO: There is a problem with  orderly cleanup. Re-enable this once it is fixed. Delete the system namespace
-		// instead
-		// return i.deployment.Delete(i.environment.Accessor, true, retry.Timeout(s.DeployTimeout))
 		if i.ctx.Settings().FailOnDeprecation {
 			err = multierror.Append(err, i.checkDeprecation())
 		}
-		err2 := i.environment.Accessor.DeleteNamespace(i.settings.SystemNamespace)
-		err = multierror.Append(err, err2)
-		if err2 == nil {
-			err = multierror.Append(err, i.environment.Accessor.WaitForNamespaceDeletion(i.settings.SystemNamespace))
+		for _, cluster := range i.environment.KubeClusters {
+			// TODO: There is a problem with  orderly cleanup. Re-enable this once it is fixed. Delete the system namespace
+			// instead
+			// return i.deployment.Delete(i.environment.Accessor, true, retry.Timeout(s.DeployTimeout))
+			err2 := cluster.DeleteNamespace(i.settings.SystemNamespace)
+			err = multierror.Append(err, err2)
+			if err2 == nil {
+				err = multierror.Append(err, cluster.WaitForNamespaceDeletion(i.settings.SystemNamespace))
+			}
+			// Note: when cleaning up an Istio deployment, ValidatingWebhookConfiguration
+			// and MutatingWebhookConfiguration must be cleaned up. Otherwise, next
+			// Istio deployment in the cluster will be impacted, causing flaky test results.
+			// Clean up ValidatingWebhookConfiguration, if any
+			_ = cluster.DeleteValidatingWebhook(DefaultValidatingWebhookConfigurationName)
+			// Clean up MutatingWebhookConfiguration, if any
+			_ = cluster.DeleteMutatingWebhook(DefaultMutatingWebhookConfigurationName)
 		}
-		// Note: when cleaning up an Istio deployment, ValidatingWebhookConfiguration
-		// and MutatingWebhookConfiguration must be cleaned up. Otherwise, next
-		// Istio deployment in the cluster will be impacted, causing flaky test results.
-		// Clean up ValidatingWebhookConfiguration, if any
-		_ = i.environment.DeleteValidatingWebhook(DefaultValidatingWebhookConfigurationName)
-		// Clean up MutatingWebhookConfiguration, if any
-		_ = i.environment.DeleteMutatingWebhook(DefaultMutatingWebhookConfigurationName)
 	}
 
 	return err.ErrorOrNil()
 }
 
 func (i *kubeComponent) checkDeprecation() error {
-	pods, err := i.environment.GetPods(i.settings.SystemNamespace)
-	if err != nil {
-		return fmt.Errorf("could not get pods to inspect for deprecation messages: %v", err)
-	}
-
 	var result error
-	for _, pod := range pods {
-		for _, c := range pod.Spec.Containers {
-			if c.Name == "istio-proxy" {
-				info := fmt.Sprintf("pod: %s/%s", i.settings.SystemNamespace, pod.Name)
-				logs, err := i.environment.Logs(i.settings.SystemNamespace, pod.Name, "istio-proxy", false)
-				if err != nil {
-					result = multierror.Append(result, fmt.Errorf("could not get proxy logs (%s) to inspect for deprecation messages: %v", info, err))
-				} else {
-					result = multierror.Append(result, errors.FindDeprecatedMessagesInEnvoyLog(logs, info))
+	for _, cluster := range i.environment.KubeClusters {
+		pods, err := cluster.GetPods(i.settings.SystemNamespace)
+		if err != nil {
+			return fmt.Errorf("could not get pods of %s to inspect for deprecation messages: %v", cluster, err)
+		}
+
+		for _, pod := range pods {
+			for _, c := range pod.Spec.Containers {
+				if c.Name == "istio-proxy" {
+					info := fmt.Sprintf("pod: %s/%s", i.settings.SystemNamespace, pod.Name)
+					logs, err := cluster.Logs(i.settings.SystemNamespace, pod.Name, "istio-proxy", false)
+					if err != nil {
+						result = multierror.Append(result, fmt.Errorf("could not get proxy logs (%s) to inspect for deprecation messages: %v", info, err))
+					} else {
+						result = multierror.Append(result, errors.FindDeprecatedMessagesInEnvoyLog(logs, info))
+					}
 				}
 			}
 		}
@@ -221,18 +270,32 @@ func (i *kubeComponent) Dump() {
 		return
 	}
 
-	deployment.DumpPodState(d, i.settings.SystemNamespace, i.environment.Accessor)
-	deployment.DumpPodEvents(d, i.settings.SystemNamespace, i.environment.Accessor)
+	for _, cluster := range i.environment.KubeClusters {
+		cd := d
+		if i.environment.IsMulticluster() {
+			cd = path.Join(d, cluster.String())
+			if err := os.MkdirAll(cd, os.ModePerm); err != nil {
+				scopes.CI.Errorf("Unable to create directory for dumping Istio contents of %s: %v", cluster, err)
+				continue
+			}
+		}
+		dumpCluster(cd, i.settings.SystemNamespace, cluster)
+	}
+}
+
+func dumpCluster(d, systemNamespace string, cluster kube.Cluster) {
+	deployment.DumpPodState(d, systemNamespace, cluster.Accessor)
+	deployment.DumpPodEvents(d, systemNamespace, cluster.Accessor)
 
-	pods, err := i.environment.Accessor.GetPods(i.settings.SystemNamespace)
+	pods, err := cluster.GetPods(systemNamespace)
 	if err != nil {
-		scopes.CI.Errorf("Unable to get pods from the system namespace: %v", err)
+		scopes.CI.Errorf("Unable to get pods from the system namespace of %s: %v", cluster, err)
 		return
 	}
 
 	for _, pod := range pods {
 		for _, container := range pod.Spec.Containers {
-			l, err := i.environment.Logs(pod.Namespace, pod.Name, container.Name, false /* previousLog */)
+			l, err := cluster.Logs(pod.Namespace, pod.Name, container.Name, false /* previousLog */)
 			if err != nil {
 				scopes.CI.Errorf("Unable to get logs for pod/container: %s/%s/%s", pod.Namespace, pod.Name, container.Name)
 				continue
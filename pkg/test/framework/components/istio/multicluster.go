@@ -0,0 +1,145 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package istio
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"istio.io/istio/istioctl/pkg/multicluster"
+	"istio.io/istio/pkg/test/framework/components/environment/kube"
+	"istio.io/istio/pkg/test/scopes"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+const (
+	// RemoteValuesFile is the values file of the clusters whose control plane runs in another cluster.
+	RemoteValuesFile = "values-istio-remote.yaml"
+
+	// remoteSecretServiceAccount is the service account whose credentials the control planes access
+	// the other clusters with.
+	remoteSecretServiceAccount = "istio-multi"
+)
+
+// clusterConfig returns the configuration of Istio in the cluster, with the network of its pods and
+// the registries of its control plane.
+func clusterConfig(env *kube.Environment, cluster kube.Cluster, cfg Config) Config {
+	network := env.ClusterNetwork(cluster)
+	registries := env.ControlPlaneRegistries(cluster)
+	if network == "" && len(registries) == 0 {
+		return cfg
+	}
+
+	out := cfg
+	out.Values = make(map[string]string, len(cfg.Values)+2)
+	for k, v := range cfg.Values {
+		out.Values[k] = v
+	}
+	if network != "" {
+		out.Values["global.network"] = network
+	}
+	if len(registries) > 0 {
+		out.Values["pilot.registries"] = "{" + strings.Join(registries, ",") + "}"
+	}
+	return out
+}
+
+// remoteConfig returns the configuration of Istio in a cluster whose control plane runs in the
+// given cluster.
+func remoteConfig(controlPlane kube.Cluster, cfg Config) (Config, error) {
+	pods, err := controlPlane.WaitUntilPodsAreReady(controlPlane.NewSinglePodFetch(cfg.SystemNamespace, "istio=pilot"),
+		retry.Timeout(cfg.DeployTimeout))
+	if err != nil {
+		return Config{}, fmt.Errorf("error waiting for the pilot of %s: %v", controlPlane, err)
+	}
+
+	remote := cfg
+	remote.ValuesFile = RemoteValuesFile
+	// the remote clusters have no galley
+	remote.SkipWaitForValidationWebhook = true
+	remote.Values = make(map[string]string, len(cfg.Values)+1)
+	for k, v := range cfg.Values {
+		remote.Values[k] = v
+	}
+	// the pods of the clusters are assumed to share a flat network
+	remote.Values["global.remotePilotAddress"] = pods[0].Status.PodIP
+	return remote, nil
+}
+
+// configureDiscovery creates, in the control plane clusters, the secrets giving access to the
+// other clusters, so that their control plane discovers the services of all the clusters.
+func configureDiscovery(env *kube.Environment, cfg Config) error {
+	secrets := make(map[int]string, len(env.KubeClusters))
+	for _, cluster := range env.KubeClusters {
+		secret, err := createRemoteSecret(cluster, cfg.SystemNamespace)
+		if err != nil {
+			return fmt.Errorf("failed to create the remote secret of %s: %v", cluster, err)
+		}
+		secrets[int(cluster.Index())] = secret
+	}
+
+	for _, controlPlane := range env.KubeClusters {
+		if !env.IsControlPlaneCluster(controlPlane) {
+			continue
+		}
+		for _, cluster := range env.KubeClusters {
+			if cluster.Index() == controlPlane.Index() {
+				continue
+			}
+			scopes.CI.Infof("Configuring the control plane of %s to discover %s", controlPlane, cluster)
+			if _, err := controlPlane.ApplyContents(cfg.SystemNamespace, secrets[int(cluster.Index())]); err != nil {
+				return fmt.Errorf("failed to apply the remote secret of %s to %s: %v", cluster, controlPlane, err)
+			}
+		}
+	}
+	return nil
+}
+
+// createRemoteSecret returns the secret giving access to the cluster, in YAML.
+func createRemoteSecret(cluster kube.Cluster, systemNamespace string) (string, error) {
+	localEnv, err := multicluster.NewEnvironment(cluster.Filename(), "", ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return "", err
+	}
+	remoteEnv, err := multicluster.NewEnvironment(cluster.RemoteFilename(), "", ioutil.Discard, ioutil.Discard)
+	if err != nil {
+		return "", err
+	}
+
+	opts := multicluster.RemoteSecretOptions{
+		KubeOptions: multicluster.KubeOptions{
+			Kubeconfig: cluster.Filename(),
+			Namespace:  systemNamespace,
+		},
+		ServiceAccountName: remoteSecretServiceAccount,
+		AuthType:           multicluster.RemoteSecretAuthTypeBearerToken,
+	}
+	return multicluster.CreateRemoteSecret(opts, remoteSecretEnv{KubeEnvironment: localEnv, config: remoteEnv.GetConfig()})
+}
+
+// remoteSecretEnv reads the credentials of a cluster with the kube config file of the tests, and
+// writes in the secret the API server address of the kube config file the other clusters access it
+// with.
+type remoteSecretEnv struct {
+	*multicluster.KubeEnvironment
+	config *api.Config
+}
+
+func (e remoteSecretEnv) GetConfig() *api.Config {
+	return e.config
+}
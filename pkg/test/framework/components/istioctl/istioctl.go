@@ -33,7 +33,8 @@ type Instance interface {
 
 // Structured config for the istioctl component
 type Config struct {
-	// currently nothing, we might add stuff like OS env settings later
+	// Cluster (k8s only) is the cluster istioctl is invoked against, the primary cluster if nil.
+	Cluster resource.Cluster
 }
 
 // New returns a new instance of "istioctl".
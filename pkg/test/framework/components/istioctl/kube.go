@@ -52,7 +52,7 @@ func (c *kubeComponent) ID() resource.ID {
 func (c *kubeComponent) Invoke(args []string) (string, error) {
 	var envArgs = []string{
 		"--kubeconfig",
-		c.env.Settings().KubeConfig,
+		c.env.GetCluster(c.config.Cluster).Filename(),
 	}
 	var out bytes.Buffer
 	rootCmd := cmd.GetRootCmd(append(envArgs, args...))
@@ -21,6 +21,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hashicorp/go-multierror"
+
 	"istio.io/istio/pkg/test/framework/components/environment/kube"
 	"istio.io/istio/pkg/test/framework/components/istio"
 	"istio.io/istio/pkg/test/framework/resource"
@@ -34,11 +36,12 @@ var (
 	mu    sync.Mutex
 )
 
-// kubeNamespace represents a Kubernetes namespace. It is tracked as a resource.
+// kubeNamespace represents a Kubernetes namespace, created in all the clusters of the environment.
+// It is tracked as a resource.
 type kubeNamespace struct {
-	id   resource.ID
-	name string
-	a    *k.Accessor
+	id        resource.ID
+	name      string
+	accessors []*k.Accessor
 }
 
 var _ Instance = &kubeNamespace{}
@@ -59,7 +62,11 @@ func (n *kubeNamespace) Close() (err error) {
 		scopes.Framework.Debugf("%s deleting namespace", n.id)
 		ns := n.name
 		n.name = ""
-		err = n.a.DeleteNamespace(ns)
+		for _, a := range n.accessors {
+			if e := a.DeleteNamespace(ns); e != nil {
+				err = multierror.Append(err, e)
+			}
+		}
 	}
 
 	scopes.Framework.Debugf("%s close complete (err:%v)", n.id, err)
@@ -73,16 +80,17 @@ func claimKube(ctx resource.Context, name string) (Instance, error) {
 		return nil, err
 	}
 
-	if !env.Accessor.NamespaceExists(name) {
-		nsConfig := Config{
-			Inject:                  true,
-			CustomInjectorNamespace: cfg.CustomSidecarInjectorNamespace,
-		}
-		nsLabels := createNamespaceLabels(&nsConfig)
-		if err := env.CreateNamespaceWithLabels(name, "istio-test", nsLabels); err != nil {
-			return nil, err
+	nsConfig := Config{
+		Inject:                  true,
+		CustomInjectorNamespace: cfg.CustomSidecarInjectorNamespace,
+	}
+	nsLabels := createNamespaceLabels(&nsConfig)
+	for _, c := range env.KubeClusters {
+		if !c.NamespaceExists(name) {
+			if err := c.CreateNamespaceWithLabels(name, "istio-test", nsLabels); err != nil {
+				return nil, err
+			}
 		}
-
 	}
 	return &kubeNamespace{name: name}, nil
 }
@@ -99,11 +107,15 @@ func newKube(ctx resource.Context, nsConfig *Config) (Instance, error) {
 	ns := fmt.Sprintf("%s-%d-%d", nsConfig.Prefix, nsid, r)
 
 	nsLabels := createNamespaceLabels(nsConfig)
-	if err := env.CreateNamespaceWithLabels(ns, "istio-test", nsLabels); err != nil {
-		return nil, err
+	n := &kubeNamespace{name: ns}
+	for _, c := range env.KubeClusters {
+		if err := c.CreateNamespaceWithLabels(ns, "istio-test", nsLabels); err != nil {
+			_ = n.Close()
+			return nil, err
+		}
+		n.accessors = append(n.accessors, c.Accessor)
 	}
 
-	n := &kubeNamespace{name: ns, a: env.Accessor}
 	id := ctx.TrackResource(n)
 	n.id = id
 
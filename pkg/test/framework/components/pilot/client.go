@@ -22,10 +22,12 @@ import (
 	"time"
 
 	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	xdscore "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
 	adsapi "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
 	"github.com/hashicorp/go-multierror"
-
+	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"istio.io/istio/pkg/test"
 )
@@ -34,7 +36,15 @@ type client struct {
 	discoveryAddr *net.TCPAddr
 	conn          *grpc.ClientConn
 	stream        adsapi.AggregatedDiscoveryService_StreamAggregatedResourcesClient
-	lastRequest   *xdsapi.DiscoveryRequest
+	deltaStream   adsapi.AggregatedDiscoveryService_DeltaAggregatedResourcesClient
+
+	mu sync.Mutex
+	// The last request sent for each type, and the last version ACKed for each type.
+	requests map[string]*xdsapi.DiscoveryRequest
+	versions map[string]string
+	// The node of the last delta request.
+	deltaNode  *xdscore.Node
+	nackPolicy NackPolicy
 
 	wg sync.WaitGroup
 }
@@ -55,12 +65,20 @@ func newClient(discoveryAddr *net.TCPAddr) (*client, error) {
 		conn:          conn,
 		stream:        stream,
 		discoveryAddr: discoveryAddr,
+		requests:      make(map[string]*xdsapi.DiscoveryRequest),
+		versions:      make(map[string]string),
 	}, nil
 }
 
+func (c *client) send(req *xdsapi.DiscoveryRequest) error {
+	c.mu.Lock()
+	c.requests[req.TypeUrl] = req
+	c.mu.Unlock()
+	return c.stream.Send(req)
+}
+
 func (c *client) CallDiscovery(req *xdsapi.DiscoveryRequest) (*xdsapi.DiscoveryResponse, error) {
-	c.lastRequest = req
-	err := c.stream.Send(req)
+	err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -77,12 +95,7 @@ func (c *client) CallDiscoveryOrFail(t test.Failer, req *xdsapi.DiscoveryRequest
 }
 
 func (c *client) StartDiscovery(req *xdsapi.DiscoveryRequest) error {
-	c.lastRequest = req
-	err := c.stream.Send(req)
-	if err != nil {
-		return err
-	}
-	return nil
+	return c.send(req)
 }
 
 func (c *client) StartDiscoveryOrFail(t test.Failer, req *xdsapi.DiscoveryRequest) {
@@ -94,6 +107,225 @@ func (c *client) StartDiscoveryOrFail(t test.Failer, req *xdsapi.DiscoveryReques
 
 func (c *client) WatchDiscovery(timeout time.Duration,
 	accept func(*xdsapi.DiscoveryResponse) (bool, error)) error {
+	return c.watch(timeout, func() (bool, error) {
+		result, err := c.stream.Recv()
+		if err != nil {
+			return false, err
+		}
+		// ACK all responses so that when an update arrives we can receive it, unless the
+		// NACK policy rejects them.
+		if err := c.respond(result, c.nack(TypeURL(result.TypeUrl))); err != nil {
+			return false, err
+		}
+		return accept(result)
+	})
+}
+
+func (c *client) WatchDiscoveryOrFail(t test.Failer, timeout time.Duration,
+	accept func(*xdsapi.DiscoveryResponse) (bool, error)) {
+
+	t.Helper()
+	if err := c.WatchDiscovery(timeout, accept); err != nil {
+		t.Fatalf("no resource accepted: %v", err)
+	}
+}
+
+func (c *client) WatchDiscoveryTypes(timeout time.Duration,
+	accept map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error)) error {
+	pending := make(map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error), len(accept))
+	for typeURL, fn := range accept {
+		pending[typeURL] = fn
+	}
+	return c.WatchDiscovery(timeout, func(resp *xdsapi.DiscoveryResponse) (bool, error) {
+		fn, ok := pending[TypeURL(resp.TypeUrl)]
+		if !ok {
+			return false, nil
+		}
+		accepted, err := fn(resp)
+		if err != nil {
+			return false, err
+		}
+		if accepted {
+			delete(pending, TypeURL(resp.TypeUrl))
+		}
+		return len(pending) == 0, nil
+	})
+}
+
+func (c *client) WatchDiscoveryTypesOrFail(t test.Failer, timeout time.Duration,
+	accept map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error)) {
+
+	t.Helper()
+	if err := c.WatchDiscoveryTypes(timeout, accept); err != nil {
+		t.Fatalf("no resource accepted for all types: %v", err)
+	}
+}
+
+func (c *client) AckDiscovery(resp *xdsapi.DiscoveryResponse) error {
+	return c.respond(resp, nil)
+}
+
+func (c *client) NackDiscovery(resp *xdsapi.DiscoveryResponse, detail error) error {
+	if detail == nil {
+		return errors.New("a NACK requires an error detail")
+	}
+	return c.respond(resp, detail)
+}
+
+// respond ACKs the response, or NACKs it if detail is not nil. A NACK keeps the version of the last
+// response ACKed for the type, as Envoy does.
+func (c *client) respond(resp *xdsapi.DiscoveryResponse, detail error) error {
+	c.mu.Lock()
+	req := &xdsapi.DiscoveryRequest{
+		TypeUrl:       resp.TypeUrl,
+		ResponseNonce: resp.Nonce,
+		VersionInfo:   resp.VersionInfo,
+	}
+	if last, ok := c.requests[resp.TypeUrl]; ok {
+		req.Node = last.Node
+		req.ResourceNames = last.ResourceNames
+	}
+	if detail != nil {
+		req.VersionInfo = c.versions[resp.TypeUrl]
+		req.ErrorDetail = errorDetail(detail)
+	} else {
+		c.versions[resp.TypeUrl] = resp.VersionInfo
+	}
+	c.mu.Unlock()
+
+	return c.stream.Send(req)
+}
+
+func (c *client) openDelta() (adsapi.AggregatedDiscoveryService_DeltaAggregatedResourcesClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.deltaStream == nil {
+		adsClient := adsapi.NewAggregatedDiscoveryServiceClient(c.conn)
+		stream, err := adsClient.DeltaAggregatedResources(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		c.deltaStream = stream
+	}
+	return c.deltaStream, nil
+}
+
+func (c *client) sendDelta(req *xdsapi.DeltaDiscoveryRequest) (adsapi.AggregatedDiscoveryService_DeltaAggregatedResourcesClient, error) {
+	stream, err := c.openDelta()
+	if err != nil {
+		return nil, err
+	}
+	if req.Node != nil {
+		c.mu.Lock()
+		c.deltaNode = req.Node
+		c.mu.Unlock()
+	}
+	return stream, stream.Send(req)
+}
+
+func (c *client) CallDeltaDiscovery(req *xdsapi.DeltaDiscoveryRequest) (*xdsapi.DeltaDiscoveryResponse, error) {
+	stream, err := c.sendDelta(req)
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
+
+func (c *client) CallDeltaDiscoveryOrFail(t test.Failer, req *xdsapi.DeltaDiscoveryRequest) *xdsapi.DeltaDiscoveryResponse {
+	t.Helper()
+	resp, err := c.CallDeltaDiscovery(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func (c *client) StartDeltaDiscovery(req *xdsapi.DeltaDiscoveryRequest) error {
+	_, err := c.sendDelta(req)
+	return err
+}
+
+func (c *client) StartDeltaDiscoveryOrFail(t test.Failer, req *xdsapi.DeltaDiscoveryRequest) {
+	t.Helper()
+	if err := c.StartDeltaDiscovery(req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *client) WatchDeltaDiscovery(timeout time.Duration,
+	accept func(*xdsapi.DeltaDiscoveryResponse) (bool, error)) error {
+	stream, err := c.openDelta()
+	if err != nil {
+		return err
+	}
+	return c.watch(timeout, func() (bool, error) {
+		result, err := stream.Recv()
+		if err != nil {
+			return false, err
+		}
+		if err := c.respondDelta(result, c.nack(TypeURL(result.TypeUrl))); err != nil {
+			return false, err
+		}
+		return accept(result)
+	})
+}
+
+func (c *client) WatchDeltaDiscoveryOrFail(t test.Failer, timeout time.Duration,
+	accept func(*xdsapi.DeltaDiscoveryResponse) (bool, error)) {
+
+	t.Helper()
+	if err := c.WatchDeltaDiscovery(timeout, accept); err != nil {
+		t.Fatalf("no delta resource accepted: %v", err)
+	}
+}
+
+func (c *client) AckDeltaDiscovery(resp *xdsapi.DeltaDiscoveryResponse) error {
+	return c.respondDelta(resp, nil)
+}
+
+func (c *client) NackDeltaDiscovery(resp *xdsapi.DeltaDiscoveryResponse, detail error) error {
+	if detail == nil {
+		return errors.New("a NACK requires an error detail")
+	}
+	return c.respondDelta(resp, detail)
+}
+
+func (c *client) respondDelta(resp *xdsapi.DeltaDiscoveryResponse, detail error) error {
+	stream, err := c.openDelta()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	req := &xdsapi.DeltaDiscoveryRequest{
+		Node:          c.deltaNode,
+		TypeUrl:       resp.TypeUrl,
+		ResponseNonce: resp.Nonce,
+	}
+	c.mu.Unlock()
+	if detail != nil {
+		req.ErrorDetail = errorDetail(detail)
+	}
+	return stream.Send(req)
+}
+
+func (c *client) SetNackPolicy(policy NackPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nackPolicy = policy
+}
+
+func (c *client) nack(typeURL TypeURL) error {
+	c.mu.Lock()
+	policy := c.nackPolicy
+	c.mu.Unlock()
+	if policy == nil {
+		return nil
+	}
+	return policy(typeURL)
+}
+
+// watch calls recv until it accepts a response or fails, or until the timeout expires.
+func (c *client) watch(timeout time.Duration, recv func() (bool, error)) error {
 	c1 := make(chan error, 1)
 
 	c.wg.Add(1)
@@ -101,23 +333,7 @@ func (c *client) WatchDiscovery(timeout time.Duration,
 		defer c.wg.Done()
 
 		for {
-			result, err := c.stream.Recv()
-			if err != nil {
-				c1 <- err
-				break
-			}
-			// ACK all responses so that when an update arrives we can receive it
-			err = c.stream.Send(&xdsapi.DiscoveryRequest{
-				Node:          c.lastRequest.Node,
-				ResponseNonce: result.Nonce,
-				VersionInfo:   result.VersionInfo,
-				TypeUrl:       c.lastRequest.TypeUrl,
-			})
-			if err != nil {
-				c1 <- err
-				break
-			}
-			accepted, err := accept(result)
+			accepted, err := recv()
 			if err != nil {
 				c1 <- err
 				break
@@ -136,19 +352,16 @@ func (c *client) WatchDiscovery(timeout time.Duration,
 	}
 }
 
-func (c *client) WatchDiscoveryOrFail(t test.Failer, timeout time.Duration,
-	accept func(*xdsapi.DiscoveryResponse) (bool, error)) {
-
-	t.Helper()
-	if err := c.WatchDiscovery(timeout, accept); err != nil {
-		t.Fatalf("no resource accepted: %v", err)
-	}
-}
-
 func (c *client) Close() (err error) {
 	if c.stream != nil {
 		err = multierror.Append(err, c.stream.CloseSend()).ErrorOrNil()
 	}
+	c.mu.Lock()
+	deltaStream := c.deltaStream
+	c.mu.Unlock()
+	if deltaStream != nil {
+		err = multierror.Append(err, deltaStream.CloseSend()).ErrorOrNil()
+	}
 	if c.conn != nil {
 		err = multierror.Append(err, c.conn.Close()).ErrorOrNil()
 	}
@@ -157,3 +370,10 @@ func (c *client) Close() (err error) {
 
 	return
 }
+
+func errorDetail(err error) *status.Status {
+	return &status.Status{
+		Code:    int32(codes.InvalidArgument),
+		Message: err.Error(),
+	}
+}
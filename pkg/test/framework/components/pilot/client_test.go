@@ -0,0 +1,194 @@
+//  Copyright 2019 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package pilot
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	adsapi "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v2"
+	"google.golang.org/grpc"
+)
+
+// fakeADS answers the initial requests of each type with a response, and records the requests
+// answering its responses.
+type fakeADS struct {
+	acks      chan *xdsapi.DiscoveryRequest
+	deltaAcks chan *xdsapi.DeltaDiscoveryRequest
+}
+
+func (f *fakeADS) StreamAggregatedResources(stream adsapi.AggregatedDiscoveryService_StreamAggregatedResourcesServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		if req.ResponseNonce != "" {
+			f.acks <- req
+			continue
+		}
+		if err := stream.Send(&xdsapi.DiscoveryResponse{
+			TypeUrl:     req.TypeUrl,
+			VersionInfo: "1",
+			Nonce:       "nonce-" + req.TypeUrl,
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *fakeADS) DeltaAggregatedResources(stream adsapi.AggregatedDiscoveryService_DeltaAggregatedResourcesServer) error {
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		if req.ResponseNonce != "" {
+			f.deltaAcks <- req
+			continue
+		}
+		resp := &xdsapi.DeltaDiscoveryResponse{
+			TypeUrl: req.TypeUrl,
+			Nonce:   "nonce-" + req.TypeUrl,
+		}
+		for _, name := range req.ResourceNamesSubscribe {
+			resp.Resources = append(resp.Resources, &xdsapi.Resource{Name: name, Version: "1"})
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func newFakeADS(t *testing.T) (*fakeADS, *client, func()) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ads := &fakeADS{
+		acks:      make(chan *xdsapi.DiscoveryRequest, 10),
+		deltaAcks: make(chan *xdsapi.DeltaDiscoveryRequest, 10),
+	}
+	server := grpc.NewServer()
+	adsapi.RegisterAggregatedDiscoveryServiceServer(server, ads)
+	go func() { _ = server.Serve(listener) }()
+
+	c, err := newClient(listener.Addr().(*net.TCPAddr))
+	if err != nil {
+		server.Stop()
+		t.Fatal(err)
+	}
+	return ads, c, func() {
+		_ = c.Close()
+		server.Stop()
+	}
+}
+
+func expectAck(t *testing.T, acks chan *xdsapi.DiscoveryRequest) *xdsapi.DiscoveryRequest {
+	t.Helper()
+	select {
+	case ack := <-acks:
+		return ack
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the answer to a response")
+		return nil
+	}
+}
+
+func TestWatchDiscoveryTypes(t *testing.T) {
+	ads, c, stop := newFakeADS(t)
+	defer stop()
+	c.SetNackPolicy(func(typeURL TypeURL) error {
+		if typeURL == Listener {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	c.StartDiscoveryOrFail(t, NewDiscoveryRequest("node", Cluster))
+	c.StartDiscoveryOrFail(t, NewDiscoveryRequest("node", Listener))
+	acceptAny := func(*xdsapi.DiscoveryResponse) (bool, error) { return true, nil }
+	c.WatchDiscoveryTypesOrFail(t, 5*time.Second, map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error){
+		Cluster:  acceptAny,
+		Listener: acceptAny,
+	})
+
+	answers := map[string]*xdsapi.DiscoveryRequest{}
+	for i := 0; i < 2; i++ {
+		ack := expectAck(t, ads.acks)
+		answers[ack.TypeUrl] = ack
+	}
+	if ack := answers[string(Cluster)]; ack == nil || ack.ErrorDetail != nil || ack.VersionInfo != "1" || ack.Node.GetId() != "node" {
+		t.Errorf("expected an ACK of the clusters, got %v", ack)
+	}
+	if nack := answers[string(Listener)]; nack == nil || nack.ErrorDetail.GetMessage() != "rejected" || nack.VersionInfo != "" {
+		t.Errorf("expected a NACK of the listeners, got %v", nack)
+	}
+}
+
+func TestNackDiscovery(t *testing.T) {
+	ads, c, stop := newFakeADS(t)
+	defer stop()
+
+	resp := c.CallDiscoveryOrFail(t, NewDiscoveryRequest("node", Route))
+	if err := c.NackDiscovery(resp, nil); err == nil {
+		t.Error("expected a NACK without detail to fail")
+	}
+	if err := c.NackDiscovery(resp, errors.New("invalid route")); err != nil {
+		t.Fatal(err)
+	}
+	nack := expectAck(t, ads.acks)
+	if nack.ResponseNonce != resp.Nonce || nack.ErrorDetail.GetMessage() != "invalid route" {
+		t.Errorf("expected a NACK of the routes, got %v", nack)
+	}
+}
+
+func TestDeltaDiscovery(t *testing.T) {
+	ads, c, stop := newFakeADS(t)
+	defer stop()
+
+	resp := c.CallDeltaDiscoveryOrFail(t, NewDeltaDiscoveryRequest("node", Cluster, "a", "b"))
+	if len(resp.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %v", resp.Resources)
+	}
+	if err := c.NackDeltaDiscovery(resp, errors.New("invalid cluster")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case nack := <-ads.deltaAcks:
+		if nack.ResponseNonce != resp.Nonce || nack.ErrorDetail.GetMessage() != "invalid cluster" || nack.Node.GetId() != "node" {
+			t.Errorf("expected a NACK of the clusters, got %v", nack)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the NACK")
+	}
+
+	c.StartDeltaDiscoveryOrFail(t, NewDeltaDiscoveryRequest("node", Listener, "c"))
+	c.WatchDeltaDiscoveryOrFail(t, 5*time.Second, func(resp *xdsapi.DeltaDiscoveryResponse) (bool, error) {
+		return resp.TypeUrl == string(Listener) && len(resp.Resources) == 1, nil
+	})
+	select {
+	case ack := <-ads.deltaAcks:
+		if ack.TypeUrl != string(Listener) || ack.ErrorDetail != nil {
+			t.Errorf("expected an ACK of the listeners, got %v", ack)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the ACK")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	meshapi "istio.io/api/mesh/v1alpha1"
 
 	"istio.io/istio/pilot/pkg/bootstrap"
+	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/test/env"
@@ -109,6 +110,11 @@ func newNative(ctx resource.Context, cfg Config) (Instance, error) {
 		ForceStop: true,
 	}
 
+	if cfg.Consul != nil {
+		bootstrapArgs.Service.Registries = append(bootstrapArgs.Service.Registries, string(serviceregistry.ConsulRegistry))
+		bootstrapArgs.Service.Consul.ServerURL = cfg.Consul.Address()
+	}
+
 	if bootstrapArgs.MeshConfig == nil {
 		bootstrapArgs.MeshConfig = &meshapi.MeshConfig{}
 	}
@@ -23,6 +23,7 @@ import (
 
 	meshConfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pkg/test"
+	"istio.io/istio/pkg/test/framework/components/consul"
 	"istio.io/istio/pkg/test/framework/components/environment"
 	"istio.io/istio/pkg/test/framework/components/galley"
 	"istio.io/istio/pkg/test/framework/resource"
@@ -50,8 +51,39 @@ type Instance interface {
 	StartDiscoveryOrFail(t test.Failer, req *xdsapi.DiscoveryRequest)
 	WatchDiscovery(duration time.Duration, accept func(*xdsapi.DiscoveryResponse) (bool, error)) error
 	WatchDiscoveryOrFail(t test.Failer, duration time.Duration, accept func(*xdsapi.DiscoveryResponse) (bool, error))
+
+	// WatchDiscoveryTypes watches the responses of the discovery requests started for several types,
+	// until a response of each of the given types is accepted.
+	WatchDiscoveryTypes(duration time.Duration, accept map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error)) error
+	WatchDiscoveryTypesOrFail(t test.Failer, duration time.Duration,
+		accept map[TypeURL]func(*xdsapi.DiscoveryResponse) (bool, error))
+
+	// AckDiscovery and NackDiscovery answer a response obtained with CallDiscovery. The responses
+	// received while watching are answered according to the NACK policy.
+	AckDiscovery(resp *xdsapi.DiscoveryResponse) error
+	NackDiscovery(resp *xdsapi.DiscoveryResponse, detail error) error
+
+	// The delta variants of the discovery methods use the incremental xDS stream of the client.
+	CallDeltaDiscovery(req *xdsapi.DeltaDiscoveryRequest) (*xdsapi.DeltaDiscoveryResponse, error)
+	CallDeltaDiscoveryOrFail(t test.Failer, req *xdsapi.DeltaDiscoveryRequest) *xdsapi.DeltaDiscoveryResponse
+
+	StartDeltaDiscovery(req *xdsapi.DeltaDiscoveryRequest) error
+	StartDeltaDiscoveryOrFail(t test.Failer, req *xdsapi.DeltaDiscoveryRequest)
+	WatchDeltaDiscovery(duration time.Duration, accept func(*xdsapi.DeltaDiscoveryResponse) (bool, error)) error
+	WatchDeltaDiscoveryOrFail(t test.Failer, duration time.Duration, accept func(*xdsapi.DeltaDiscoveryResponse) (bool, error))
+
+	AckDeltaDiscovery(resp *xdsapi.DeltaDiscoveryResponse) error
+	NackDeltaDiscovery(resp *xdsapi.DeltaDiscoveryResponse, detail error) error
+
+	// SetNackPolicy sets the policy answering the responses received while watching. By default
+	// all the responses are ACKed.
+	SetNackPolicy(policy NackPolicy)
 }
 
+// NackPolicy decides how the responses of a type are answered while watching: they are NACKed with
+// the returned error as detail, or ACKed if it is nil.
+type NackPolicy func(typeURL TypeURL) error
+
 // Structured config for the Pilot component
 type Config struct {
 	fmt.Stringer
@@ -61,6 +93,10 @@ type Config struct {
 	// The MeshConfig to be used for Pilot in native environment. In Kube environment this can be
 	// configured with Helm.
 	MeshConfig *meshConfig.MeshConfig
+
+	// If set then Pilot in native environment adds the services of the referenced Consul instance to
+	// its aggregate registry, next to the ServiceEntries.
+	Consul consul.Instance
 }
 
 // New returns a new instance of echo.
@@ -93,3 +129,15 @@ func NewDiscoveryRequest(nodeID string, typeURL TypeURL) *xdsapi.DiscoveryReques
 		TypeUrl: string(typeURL),
 	}
 }
+
+// NewDeltaDiscoveryRequest is a utility method for creating a new delta request for the given node
+// and type, subscribing to the given resources.
+func NewDeltaDiscoveryRequest(nodeID string, typeURL TypeURL, subscribe ...string) *xdsapi.DeltaDiscoveryRequest {
+	return &xdsapi.DeltaDiscoveryRequest{
+		Node: &xdscore.Node{
+			Id: nodeID,
+		},
+		TypeUrl:                string(typeURL),
+		ResourceNamesSubscribe: subscribe,
+	}
+}
@@ -72,4 +72,7 @@ func init() {
 
 	flag.BoolVar(&settingsFromCommandLine.FailOnDeprecation, "istio.test.deprecation_failure", settingsFromCommandLine.FailOnDeprecation,
 		"Make tests fail if any usage of deprecated stuff (e.g. Envoy flags) is detected.")
+
+	flag.BoolVar(&settingsFromCommandLine.DumpOnFailure, "istio.test.dump_on_failure", settingsFromCommandLine.DumpOnFailure,
+		"Dump the state of the mesh into the work dir of the tests that fail.")
 }
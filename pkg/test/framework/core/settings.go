@@ -50,6 +50,10 @@ type Settings struct {
 	// Should the tests fail if usage of deprecated stuff (e.g. Envoy flags) is detected
 	FailOnDeprecation bool
 
+	// Dump the state of the mesh (control plane logs, proxy configuration and stats, Istio
+	// configuration) into the work dir of the failed tests.
+	DumpOnFailure bool
+
 	// Local working directory root for creating temporary directories / files in. If left empty,
 	// os.TempDir() will be used.
 	BaseDir string
@@ -84,8 +88,9 @@ func (s *Settings) Clone() *Settings {
 // DefaultSettings returns a default settings instance.
 func DefaultSettings() *Settings {
 	return &Settings{
-		Environment: environment.DefaultName().String(),
-		RunID:       uuid.New(),
+		Environment:   environment.DefaultName().String(),
+		RunID:         uuid.New(),
+		DumpOnFailure: true,
 	}
 }
 
@@ -100,5 +105,6 @@ func (s *Settings) String() string {
 	result += fmt.Sprintf("BaseDir:           %s\n", s.BaseDir)
 	result += fmt.Sprintf("Selector:          %v\n", s.Selector)
 	result += fmt.Sprintf("FailOnDeprecation: %v\n", s.FailOnDeprecation)
+	result += fmt.Sprintf("DumpOnFailure:     %v\n", s.DumpOnFailure)
 	return result
 }
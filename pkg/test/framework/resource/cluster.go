@@ -0,0 +1,28 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package resource
+
+import "fmt"
+
+// ClusterIndex is the index of a cluster of the environment, in the order its clusters are declared.
+type ClusterIndex int
+
+// Cluster is a cluster of a multicluster environment.
+type Cluster interface {
+	fmt.Stringer
+
+	// Index of the cluster in the environment.
+	Index() ClusterIndex
+}
@@ -19,3 +19,10 @@ package resource
 type Dumper interface {
 	Dump()
 }
+
+// StateDumper is implemented by the components that can dump the state of the mesh, e.g. the logs of the
+// control plane, the configuration and stats of the proxies and the Istio configuration. When a test fails,
+// the state is dumped into a directory of the test.
+type StateDumper interface {
+	DumpState(dir string)
+}
@@ -131,3 +131,17 @@ func (s *scope) dump() {
 		}
 	}
 }
+
+// dumpState dumps the state of the mesh into dir, with the resources of the scope and of its ancestors.
+func (s *scope) dumpState(dir string) {
+	for sc := s; sc != nil; sc = sc.parent {
+		sc.mu.Lock()
+		resources := append([]resource.Resource{}, sc.resources...)
+		sc.mu.Unlock()
+		for _, r := range resources {
+			if d, ok := r.(resource.StateDumper); ok {
+				d.DumpState(dir)
+			}
+		}
+	}
+}
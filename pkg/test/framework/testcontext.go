@@ -21,6 +21,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"istio.io/istio/pkg/test"
@@ -98,6 +99,13 @@ type testContext struct {
 
 	// The workDir for this particular context
 	workDir string
+
+	// The context of the parent test, if any.
+	parent *testContext
+
+	// Set when a child context dumped the state of the mesh, so that the contexts of its ancestors,
+	// which fail along with it, don't dump it again.
+	childDumped int32
 }
 
 func newTestContext(test *Test, goTest *testing.T, s *suiteContext, parentScope *scope, labels label.Set) *testContext {
@@ -197,7 +205,9 @@ func (c *testContext) RequireOrSkip(envName environment.Name) {
 }
 
 func (c *testContext) newChildContext(test *Test) *testContext {
-	return newTestContext(test, test.goTest, c.suite, c.scope, label.NewSet(test.labels...))
+	child := newTestContext(test, test.goTest, c.suite, c.scope, label.NewSet(test.labels...))
+	child.parent = c
+	return child
 }
 
 func (c *testContext) NewSubTest(name string) *Test {
@@ -225,6 +235,13 @@ func (c *testContext) Done() {
 		scopes.Framework.Debugf("Begin dumping testContext: %q", c.id)
 		c.scope.dump()
 		scopes.Framework.Debugf("Completed dumping testContext: %q", c.id)
+
+		if c.Settings().DumpOnFailure && atomic.LoadInt32(&c.childDumped) == 0 {
+			c.dumpState()
+		}
+		if c.parent != nil {
+			atomic.StoreInt32(&c.parent.childDumped, 1)
+		}
 	}
 
 	scopes.Framework.Debugf("Begin cleaning up testContext: %q", c.id)
@@ -239,6 +256,17 @@ func (c *testContext) Done() {
 	scopes.Framework.Debugf("Completed cleaning up testContext: %q", c.id)
 }
 
+// dumpState dumps the state of the mesh into the work dir of the test.
+func (c *testContext) dumpState() {
+	dir := path.Join(c.workDir, "_state")
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		scopes.CI.Errorf("Unable to create directory for dumping the state of test %s: %v", c.Name(), err)
+		return
+	}
+	scopes.CI.Infof("=== Dumping the state of the mesh for test %s into %s", c.Name(), dir)
+	c.scope.dumpState(dir)
+}
+
 func (c *testContext) Error(args ...interface{}) {
 	c.Helper()
 	c.T.Error(args...)
@@ -328,7 +328,7 @@ func (s Script) getEnv(ctx Context) []string {
 	// Copy the user-specified environment (if set) and add the k8s config.
 	customVars := make(map[string]string)
 	ctx.Environment().Case(environment.Kube, func() {
-		customVars["KUBECONFIG"] = ctx.KubeEnv().Settings().KubeConfig
+		customVars["KUBECONFIG"] = ctx.KubeEnv().Settings().KubeConfig[0]
 	})
 	for k, v := range s.Env {
 		customVars[k] = v
@@ -117,6 +117,16 @@ func (a *Accessor) GetPods(namespace string, selectors ...string) ([]kubeApiCore
 	return list.Items, nil
 }
 
+// GetNodes returns the nodes of the cluster.
+func (a *Accessor) GetNodes() ([]kubeApiCore.Node, error) {
+	list, err := a.set.CoreV1().Nodes().List(kubeApiMeta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	return list.Items, nil
+}
+
 // GetEvents returns events in the given namespace, based on the involvedObject.
 func (a *Accessor) GetEvents(namespace string, involvedObject string) ([]kubeApiCore.Event, error) {
 	s := "involvedObject.name=" + involvedObject
@@ -514,6 +524,17 @@ func (a *Accessor) GetUnstructured(gvr schema.GroupVersionResource, namespace, n
 	return u, nil
 }
 
+// ListUnstructured returns the unstructured k8s resource objects of the provided schema in the namespace,
+// or in all the namespaces if it is empty.
+func (a *Accessor) ListUnstructured(gvr schema.GroupVersionResource, namespace string) (*unstructured.UnstructuredList, error) {
+	list, err := a.dynClient.Resource(gvr).Namespace(namespace).List(kubeApiMeta.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources of type %v: %v", gvr, err)
+	}
+
+	return list, nil
+}
+
 // ApplyContents applies the given config contents using kubectl.
 func (a *Accessor) ApplyContents(namespace string, contents string) ([]string, error) {
 	return a.ctl.applyContents(namespace, contents, false)
@@ -0,0 +1,102 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chiron
+
+import (
+	"fmt"
+	"time"
+
+	certutil "istio.io/istio/security/pkg/util"
+	"istio.io/pkg/log"
+)
+
+const (
+	// The interval between two attempts to renew a certificate after a failed renewal.
+	defaultRenewRetryInterval = 30 * time.Second
+)
+
+// KeyCertRotator renews a key and certificate that are not stored in a secret, such as the
+// DNS certificate served by istiod, before the certificate enters its grace period.
+type KeyCertRotator struct {
+	// renew generates a new key and certificate, saves them where they are consumed,
+	// and returns the new certificate chain.
+	renew          func() ([]byte, error)
+	minGracePeriod time.Duration
+	certUtil       certutil.CertUtil
+	retryInterval  time.Duration
+}
+
+// NewKeyCertRotator returns a pointer to a newly constructed KeyCertRotator instance.
+func NewKeyCertRotator(gracePeriodRatio float32, minGracePeriod time.Duration,
+	renew func() ([]byte, error)) (*KeyCertRotator, error) {
+	if gracePeriodRatio < 0 || gracePeriodRatio > 1 {
+		return nil, fmt.Errorf("grace period ratio %f should be within [0, 1]", gracePeriodRatio)
+	}
+	if gracePeriodRatio < recommendedMinGracePeriodRatio || gracePeriodRatio > recommendedMaxGracePeriodRatio {
+		log.Warnf("grace period ratio %f is out of the recommended window [%.2f, %.2f]",
+			gracePeriodRatio, recommendedMinGracePeriodRatio, recommendedMaxGracePeriodRatio)
+	}
+	if renew == nil {
+		return nil, fmt.Errorf("the renew function must be set")
+	}
+
+	return &KeyCertRotator{
+		renew:          renew,
+		minGracePeriod: minGracePeriod,
+		certUtil:       certutil.NewCertUtil(int(gracePeriodRatio * 100)),
+		retryInterval:  defaultRenewRetryInterval,
+	}, nil
+}
+
+// Run renews the certificate chain each time it enters its grace period, until stopCh is notified.
+// A certificate chain that cannot be parsed or is already expired is renewed immediately.
+// A failed renewal is retried periodically while the current certificate is kept.
+func (r *KeyCertRotator) Run(certChain []byte, stopCh <-chan struct{}) {
+	wait := r.waitTime(certChain)
+	for {
+		timer := time.NewTimer(wait)
+		select {
+		case <-stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		renewed, err := r.renew()
+		if err != nil {
+			log.Errorf("failed to renew the certificate, retrying in %v: %v", r.retryInterval, err)
+			wait = r.retryInterval
+			continue
+		}
+		certChain = renewed
+		wait = r.waitTime(certChain)
+		// A renewed certificate whose lifetime is shorter than the grace period would
+		// otherwise be renewed in a tight loop.
+		if wait < r.retryInterval {
+			wait = r.retryInterval
+		}
+		log.Infof("renewed the certificate, next renewal in %v", wait)
+	}
+}
+
+// waitTime returns the time to wait before renewing the certificate chain.
+func (r *KeyCertRotator) waitTime(certChain []byte) time.Duration {
+	wait, err := r.certUtil.GetWaitTime(certChain, time.Now(), r.minGracePeriod)
+	if err != nil {
+		log.Infof("the certificate needs to be renewed now: %v", err)
+		return 0
+	}
+	return wait
+}
@@ -0,0 +1,136 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chiron
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+func genTestCert(t *testing.T, ttl time.Duration) []byte {
+	t.Helper()
+	certPEM, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         "istiod.istio-system.svc",
+		NotBefore:    time.Now(),
+		TTL:          ttl,
+		IsSelfSigned: true,
+		RSAKeySize:   1024,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate a certificate: %v", err)
+	}
+	return certPEM
+}
+
+func TestNewKeyCertRotator(t *testing.T) {
+	renew := func() ([]byte, error) { return nil, nil }
+	testCases := map[string]struct {
+		gracePeriodRatio float32
+		renew            func() ([]byte, error)
+		expectFail       bool
+	}{
+		"valid rotator": {
+			gracePeriodRatio: 0.5,
+			renew:            renew,
+		},
+		"invalid grace period ratio": {
+			gracePeriodRatio: 1.5,
+			renew:            renew,
+			expectFail:       true,
+		},
+		"missing renew function": {
+			gracePeriodRatio: 0.5,
+			expectFail:       true,
+		},
+	}
+
+	for name, tc := range testCases {
+		_, err := NewKeyCertRotator(tc.gracePeriodRatio, time.Minute, tc.renew)
+		if tc.expectFail != (err != nil) {
+			t.Errorf("%s: expect failure %v, got error %v", name, tc.expectFail, err)
+		}
+	}
+}
+
+func TestKeyCertRotatorRun(t *testing.T) {
+	validCert := genTestCert(t, time.Hour)
+	testCases := map[string]struct {
+		certChain []byte
+		// The number of failed renewals before a renewal succeeds.
+		failures int
+		// Whether a renewal is expected before the rotator is stopped.
+		expectRenew bool
+	}{
+		"expired certificate is renewed": {
+			certChain:   []byte(exampleExpiredCert),
+			expectRenew: true,
+		},
+		"invalid certificate is renewed": {
+			certChain:   []byte("invalid"),
+			expectRenew: true,
+		},
+		"failed renewal is retried": {
+			certChain:   []byte(exampleExpiredCert),
+			failures:    2,
+			expectRenew: true,
+		},
+		"valid certificate is not renewed": {
+			certChain: validCert,
+		},
+	}
+
+	for name, tc := range testCases {
+		renewed := make(chan struct{})
+		attempts := 0
+		rotator, err := NewKeyCertRotator(0.5, time.Minute, func() ([]byte, error) {
+			attempts++
+			if attempts <= tc.failures {
+				return nil, fmt.Errorf("renewal %d failed", attempts)
+			}
+			close(renewed)
+			return validCert, nil
+		})
+		if err != nil {
+			t.Fatalf("%s: failed to create the rotator: %v", name, err)
+		}
+		rotator.retryInterval = 10 * time.Millisecond
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			rotator.Run(tc.certChain, stop)
+			close(done)
+		}()
+
+		select {
+		case <-renewed:
+			if !tc.expectRenew {
+				t.Errorf("%s: unexpected renewal", name)
+			}
+			if attempts != tc.failures+1 {
+				t.Errorf("%s: expect %d renewal attempts, got %d", name, tc.failures+1, attempts)
+			}
+		case <-time.After(time.Second):
+			if tc.expectRenew {
+				t.Errorf("%s: the certificate was not renewed", name)
+			}
+		}
+		close(stop)
+		<-done
+	}
+}
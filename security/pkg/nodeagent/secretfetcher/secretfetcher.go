@@ -75,7 +75,11 @@ var (
 	secretControllerResyncPeriod = env.RegisterStringVar("SECRET_WATCHER_RESYNC_PERIOD", "", "").Get()
 	// ingressFallbackSecret specifies the name of fallback secret for ingress gateway.
 	ingressFallbackSecret = env.RegisterStringVar("INGRESS_GATEWAY_FALLBACK_SECRET", "gateway-fallback", "").Get()
-	secretFetcherLog      = log.RegisterScope("secretFetcherLog", "secret fetcher debugging", 0)
+	// missingSecretTTL specifies how long a secret found missing by a direct API call is considered missing,
+	// before the next SDS request for it calls the API server again.
+	missingSecretTTL = env.RegisterDurationVar("INGRESS_GATEWAY_MISSING_SECRET_TTL", 30*time.Second,
+		"How long a gateway secret not found through the API server is considered missing").Get()
+	secretFetcherLog = log.RegisterScope("secretFetcherLog", "secret fetcher debugging", 0)
 )
 
 // SecretFetcher fetches secret via watching k8s secrets or sending CSR to CA.
@@ -88,8 +92,17 @@ type SecretFetcher struct {
 	scrtController cache.Controller
 	scrtStore      cache.Store
 
-	// secrets maps k8sKey to secrets
+	// secrets maps k8sKey to secrets. Only the secrets requested by SDS are loaded.
 	secrets sync.Map
+	// k8sSecrets maps the names of the watched kubernetes secrets to the secrets, loaded into secrets
+	// on the first SDS request of their resource names.
+	k8sSecrets sync.Map
+	// requested holds the resource names requested by SDS. The watched kubernetes secrets of these
+	// resource names are loaded, and pushed through AddCache and UpdateCache, as soon as they change.
+	requested sync.Map
+	// missingSecrets maps the names of the secrets not found by a direct API call to the time until which
+	// they are considered missing.
+	missingSecrets sync.Map
 
 	// Add all entries containing secretName in SecretCache. Called when K8S secret is added.
 	AddCache func(secretName string, ns model.SecretItem)
@@ -330,6 +343,28 @@ func (sf *SecretFetcher) scrtAdded(obj interface{}) {
 		return
 	}
 
+	sf.k8sSecrets.Store(resourceName, scrt)
+	sf.missingSecrets.Delete(resourceName)
+	sf.missingSecrets.Delete(resourceName + IngressGatewaySdsCaSuffix)
+	if !sf.isRequested(resourceName) {
+		secretFetcherLog.Debugf("secret %s is not requested, skip loading secret", resourceName)
+		return
+	}
+	sf.loadSecret(scrt, true)
+}
+
+// isRequested returns whether SDS requested the server key/cert or the client CA cert of the kubernetes secret.
+func (sf *SecretFetcher) isRequested(k8sSecretName string) bool {
+	if _, ok := sf.requested.Load(k8sSecretName); ok {
+		return true
+	}
+	_, ok := sf.requested.Load(k8sSecretName + IngressGatewaySdsCaSuffix)
+	return ok
+}
+
+// loadSecret extracts the secret items of the kubernetes secret into the local store. If notify is true,
+// AddCache is called for each item.
+func (sf *SecretFetcher) loadSecret(scrt *v1.Secret, notify bool) {
 	t := time.Now()
 	newSecret, certificateAuthorityNewSecret, isCaOnly := extractK8sSecretIntoSecretItem(scrt, t)
 
@@ -338,7 +373,7 @@ func (sf *SecretFetcher) scrtAdded(obj interface{}) {
 		sf.secrets.Delete(certificateAuthorityNewSecret.ResourceName)
 		sf.secrets.Store(certificateAuthorityNewSecret.ResourceName, *certificateAuthorityNewSecret)
 		secretFetcherLog.Debugf("secret %s is added as a client CA cert", certificateAuthorityNewSecret.ResourceName)
-		if sf.AddCache != nil {
+		if notify && sf.AddCache != nil {
 			sf.AddCache(certificateAuthorityNewSecret.ResourceName, *certificateAuthorityNewSecret)
 		}
 		return
@@ -349,7 +384,7 @@ func (sf *SecretFetcher) scrtAdded(obj interface{}) {
 		sf.secrets.Delete(newSecret.ResourceName)
 		sf.secrets.Store(newSecret.ResourceName, *newSecret)
 		secretFetcherLog.Debugf("secret %s is added as a server certificate", newSecret.ResourceName)
-		if sf.AddCache != nil {
+		if notify && sf.AddCache != nil {
 			sf.AddCache(newSecret.ResourceName, *newSecret)
 		}
 		if certificateAuthorityNewSecret != nil {
@@ -357,7 +392,7 @@ func (sf *SecretFetcher) scrtAdded(obj interface{}) {
 			sf.secrets.Delete(certificateAuthorityNewSecret.ResourceName)
 			sf.secrets.Store(certificateAuthorityNewSecret.ResourceName, *certificateAuthorityNewSecret)
 			secretFetcherLog.Debugf("secret %s is added as a client CA cert (from a compound Secret)", certificateAuthorityNewSecret.ResourceName)
-			if sf.AddCache != nil {
+			if notify && sf.AddCache != nil {
 				sf.AddCache(certificateAuthorityNewSecret.ResourceName, *certificateAuthorityNewSecret)
 			}
 		}
@@ -372,6 +407,7 @@ func (sf *SecretFetcher) scrtDeleted(obj interface{}) {
 	}
 
 	key := scrt.GetName()
+	sf.k8sSecrets.Delete(key)
 	sf.secrets.Delete(key)
 	secretFetcherLog.Infof("secret %s is deleted", key)
 	// Delete all cache entries that match the deleted key.
@@ -418,6 +454,12 @@ func (sf *SecretFetcher) scrtUpdated(oldObj, newObj interface{}) {
 		return
 	}
 
+	sf.k8sSecrets.Store(newScrtName, nscrt)
+	if !sf.isRequested(newScrtName) {
+		secretFetcherLog.Debugf("kubernetes secret %s is not requested, skip update", newScrtName)
+		return
+	}
+
 	secretFetcherLog.Infof("scrtUpdated is called on kubernetes secret %s", newScrtName)
 	// Kubernetes secret update is done by deleting first and creating a new one with the same name.
 	// Accordingly scrtDeleted and scrtAdded are called. When scrtUpdated is called, secret should remain unchanged.
@@ -494,9 +536,10 @@ func shouldUpdateSecret(oldScrt, oldCaScrt, newScrt, newCaScrt *model.SecretItem
 // FindIngressGatewaySecret returns the secret whose name matches the key, or empty secret if no
 // secret is present. The ok result indicates whether secret was found.
 // If there is a fallback secret named FallbackSecretName, return the fall back secret.
+// The secret is loaded on the first request of the key, and then kept up to date with the kubernetes secret.
 func (sf *SecretFetcher) FindIngressGatewaySecret(key string) (secret model.SecretItem, ok bool) {
 	secretFetcherLog.Debugf("SecretFetcher search for secret %s", key)
-	val, exist := sf.secrets.Load(key)
+	e, exist := sf.loadRequestedSecret(key)
 	secretFetcherLog.Debugf("load secret %s from secret fetcher: %v", key, exist)
 	if !exist {
 		// Sometimes we see that a secret in installed but not in cache because watcher is in an
@@ -504,7 +547,8 @@ func (sf *SecretFetcher) FindIngressGatewaySecret(key string) (secret model.Secr
 		// the secret from API call. Since this is a rare case, to avoid complication, we don't add
 		// the secret back to cache as it is not a normal codepath. When watcher recovers, those secret
 		// shall be added back. Note that this approach only covers the TLS server key/cert fetching.
-		if sf.coreV1 != nil {
+		// The secrets not found are not fetched again until missingSecretTTL expires.
+		if sf.coreV1 != nil && !sf.isMissing(key) {
 			if secret, err := sf.coreV1.Secrets(sf.secretNamespace).Get(key, metav1.GetOptions{}); err == nil {
 				secretItem, _, _ := extractK8sSecretIntoSecretItem(secret, time.Now())
 				if secretItem != nil {
@@ -512,26 +556,66 @@ func (sf *SecretFetcher) FindIngressGatewaySecret(key string) (secret model.Secr
 					return *secretItem, true
 				}
 				secretFetcherLog.Infof("Fail to extract secret %s found by direct api call", key)
+			} else {
+				sf.missingSecrets.Store(key, time.Now().Add(missingSecretTTL))
 			}
 		}
 
 		// Expected secret does not exist, try to find the fallback secret.
 		// TODO(JimmyCYJ): Add metrics to node agent to imply usage of fallback secret
 		secretFetcherLog.Warnf("Cannot find secret %s, searching for fallback secret %s", key, sf.FallbackSecretName)
-		fallbackVal, fallbackExist := sf.secrets.Load(sf.FallbackSecretName)
+		fallbackSecret, fallbackExist := sf.loadRequestedSecret(sf.FallbackSecretName)
 		if fallbackExist {
 			secretFetcherLog.Debugf("Return fallback secret %s for gateway secret %s", sf.FallbackSecretName, key)
-			return fallbackVal.(model.SecretItem), true
+			return fallbackSecret, true
 		}
 
 		secretFetcherLog.Errorf("cannot find secret %s and cannot find fallback secret %s", key, sf.FallbackSecretName)
 		return model.SecretItem{}, false
 	}
-	e := val.(model.SecretItem)
 	secretFetcherLog.Debugf("SecretFetcher return secret %s", key)
 	return e, true
 }
 
+// loadRequestedSecret records the request of the resource name and returns its secret, loading it from
+// the watched kubernetes secrets if it is not loaded yet.
+func (sf *SecretFetcher) loadRequestedSecret(resourceName string) (model.SecretItem, bool) {
+	// Record the request before looking up the kubernetes secrets, so that a secret added concurrently is
+	// either found here or loaded by scrtAdded.
+	sf.requested.Store(resourceName, struct{}{})
+	if val, exist := sf.secrets.Load(resourceName); exist {
+		return val.(model.SecretItem), true
+	}
+
+	scrt, exist := sf.k8sSecrets.Load(resourceName)
+	if !exist && strings.HasSuffix(resourceName, IngressGatewaySdsCaSuffix) {
+		// The client CA cert may come from a compound secret.
+		scrt, exist = sf.k8sSecrets.Load(strings.TrimSuffix(resourceName, IngressGatewaySdsCaSuffix))
+	}
+	if !exist {
+		return model.SecretItem{}, false
+	}
+	sf.loadSecret(scrt.(*v1.Secret), false)
+	val, exist := sf.secrets.Load(resourceName)
+	if !exist {
+		return model.SecretItem{}, false
+	}
+	return val.(model.SecretItem), true
+}
+
+// isMissing returns whether the secret was not found by a direct API call during the last missingSecretTTL.
+func (sf *SecretFetcher) isMissing(key string) bool {
+	val, exist := sf.missingSecrets.Load(key)
+	if !exist {
+		return false
+	}
+	if time.Now().After(val.(time.Time)) {
+		sf.missingSecrets.Delete(key)
+		return false
+	}
+	return true
+}
+
 // AddSecret adds obj into local store. Only used for testing.
 func (sf *SecretFetcher) AddSecret(obj interface{}) {
 	sf.scrtAdded(obj)
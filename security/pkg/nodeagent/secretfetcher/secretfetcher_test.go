@@ -771,6 +771,80 @@ func TestSecretFetcherUsingFallbackIngressSecret(t *testing.T) {
 	}
 }
 
+// TestSecretFetcherLazyLoading verifies that the secrets are only loaded and pushed once requested, and
+// that the secrets not found through the API server are not fetched again.
+func TestSecretFetcherLazyLoading(t *testing.T) {
+	var added []string
+	client := fake.NewSimpleClientset()
+	gSecretFetcher := &SecretFetcher{
+		UseCaClient: false,
+		AddCache: func(secretName string, ns model.SecretItem) {
+			added = append(added, secretName)
+		},
+		DeleteCache: func(secretName string) {},
+		UpdateCache: func(secretName string, ns model.SecretItem) {},
+	}
+	gSecretFetcher.InitWithKubeClient(client.CoreV1())
+	ch := make(chan struct{})
+	defer close(ch)
+	gSecretFetcher.Run(ch)
+
+	// A secret not requested is neither loaded nor pushed.
+	gSecretFetcher.scrtAdded(k8sTestGenericSecretA)
+	if len(added) != 0 {
+		t.Errorf("secrets %v pushed before being requested", added)
+	}
+	if _, ok := gSecretFetcher.secrets.Load(k8sSecretNameA); ok {
+		t.Errorf("secret %s loaded before being requested", k8sSecretNameA)
+	}
+
+	// The first request loads the secret.
+	secret, ok := gSecretFetcher.FindIngressGatewaySecret(k8sSecretNameA + IngressGatewaySdsCaSuffix)
+	if !ok {
+		t.Fatalf("secretFetcher failed to find secret %s", k8sSecretNameA+IngressGatewaySdsCaSuffix)
+	}
+	compareSecret(t, &secret, &model.SecretItem{
+		ResourceName: k8sSecretNameA + IngressGatewaySdsCaSuffix,
+		RootCert:     k8sCaCertA,
+	})
+
+	// Once requested, the changes of the secret are pushed.
+	gSecretFetcher.scrtAdded(k8sTestGenericSecretA)
+	if len(added) != 2 {
+		t.Errorf("got pushed secrets %v, want %s and its CA cert", added, k8sSecretNameA)
+	}
+
+	// A missing secret is fetched once from the API server until missingSecretTTL expires.
+	for i := 0; i < 3; i++ {
+		if _, ok := gSecretFetcher.FindIngressGatewaySecret("missing-secret"); ok {
+			t.Fatal("secretFetcher returns a secret missing-secret that should not exist")
+		}
+	}
+	gets := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "get" && action.GetResource().Resource == "secrets" {
+			gets++
+		}
+	}
+	if gets != 1 {
+		t.Errorf("got %d API calls for the missing secret, want 1", gets)
+	}
+	if !gSecretFetcher.isMissing("missing-secret") {
+		t.Error("expected missing-secret to be cached as missing")
+	}
+
+	// Adding the secret clears the negative cache entry.
+	missing := k8sTestGenericSecretB.DeepCopy()
+	missing.Name = "missing-secret"
+	gSecretFetcher.scrtAdded(missing)
+	if gSecretFetcher.isMissing("missing-secret") {
+		t.Error("expected missing-secret to no longer be cached as missing")
+	}
+	if _, ok := gSecretFetcher.FindIngressGatewaySecret("missing-secret"); !ok {
+		t.Error("secretFetcher failed to find the added secret missing-secret")
+	}
+}
+
 func compareSecret(t *testing.T, secret, expectedSecret *model.SecretItem) {
 	if expectedSecret.ResourceName != secret.ResourceName {
 		t.Errorf("resource name verification error: expected %s but got %s", expectedSecret.ResourceName, secret.ResourceName)
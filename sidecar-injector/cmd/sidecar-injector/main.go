@@ -28,12 +28,14 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
 	"k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	"k8s.io/client-go/tools/cache"
 
 	"istio.io/istio/pkg/cmd"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/kube/inject"
 	"istio.io/istio/pkg/util"
@@ -62,6 +64,8 @@ var (
 		webhookName            string
 		monitoringPort         int
 		reconcileWebhookConfig bool
+		revision               string
+		nativeSidecars         bool
 	}{
 		loggingOptions: log.DefaultOptions(),
 	}
@@ -79,6 +83,11 @@ var (
 
 			log.Infof("version %s", version.Info.String())
 
+			client, err := kube.CreateClientset(flags.kubeconfigFile, "")
+			if err != nil {
+				return multierror.Prefix(err, "failed to create the Kubernetes client")
+			}
+
 			parameters := inject.WebhookParameters{
 				ConfigFile:          flags.injectConfigFile,
 				ValuesFile:          flags.injectValuesFile,
@@ -89,6 +98,9 @@ var (
 				HealthCheckInterval: flags.healthCheckInterval,
 				HealthCheckFile:     flags.healthCheckFile,
 				MonitoringPort:      flags.monitoringPort,
+				Revision:            flags.revision,
+				NativeSidecars:      flags.nativeSidecars && nativeSidecarsSupported(client),
+				Client:              client,
 			}
 			wh, err := inject.NewWebhook(parameters)
 			if err != nil {
@@ -97,7 +109,7 @@ var (
 
 			stop := make(chan struct{})
 			if flags.reconcileWebhookConfig {
-				if err := patchCertLoop(stop); err != nil {
+				if err := patchCertLoop(client, stop); err != nil {
 					return multierror.Prefix(err, "failed to start patch cert loop")
 				}
 			}
@@ -127,12 +139,13 @@ var (
 
 const delayedRetryTime = time.Second
 
-func patchCertLoop(stopCh <-chan struct{}) error {
-	client, err := kube.CreateClientset(flags.kubeconfigFile, "")
-	if err != nil {
-		return err
-	}
+func nativeSidecarsSupported(client kubernetes.Interface) bool {
+	supported := inject.NativeSidecarsSupported(client)
+	log.Infof("native sidecars supported: %v", supported)
+	return supported
+}
 
+func patchCertLoop(client *kubernetes.Clientset, stopCh <-chan struct{}) error {
 	caCertPem, err := ioutil.ReadFile(flags.caCertFile)
 	if err != nil {
 		return err
@@ -147,11 +160,7 @@ func patchCertLoop(stopCh <-chan struct{}) error {
 		return fmt.Errorf("could not watch %v: %v", flags.caCertFile, err)
 	}
 
-	var retry bool
-	if err = util.PatchMutatingWebhookConfig(client.AdmissionregistrationV1beta1().MutatingWebhookConfigurations(),
-		flags.webhookConfigName, flags.webhookName, caCertPem); err != nil {
-		retry = true
-	}
+	retry := doPatch(client, caCertPem)
 
 	shouldPatch := make(chan struct{})
 
@@ -236,7 +245,26 @@ func doPatch(cs *kubernetes.Clientset, caCertPem []byte) (retry bool) {
 		log.Errorf("Patch webhook failed: %v", err)
 		return true
 	}
-	return false
+
+	// the webhooks of the tags of the revision call this injector as well
+	revision := flags.revision
+	if revision == "" {
+		revision = constants.DefaultRevision
+	}
+	tags, err := client.List(metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s,%s=%s", constants.IstioTagLabel, constants.IstioRevisionLabel, revision),
+	})
+	if err != nil {
+		log.Errorf("Patch webhook failed, could not list the webhooks of the revision tags: %v", err)
+		return true
+	}
+	for _, tag := range tags.Items {
+		if err := util.PatchMutatingWebhookConfig(client, tag.Name, flags.webhookName, caCertPem); err != nil {
+			log.Errorf("Patch webhook of tag %s failed: %v", tag.Labels[constants.IstioTagLabel], err)
+			retry = true
+		}
+	}
+	return retry
 }
 
 func init() {
@@ -267,6 +295,10 @@ func init() {
 		"Name of the webhook entry in the webhook config.")
 	rootCmd.PersistentFlags().BoolVar(&flags.reconcileWebhookConfig, "reconcileWebhookConfig", true,
 		"Enable managing webhook configuration.")
+	rootCmd.PersistentFlags().StringVar(&flags.revision, "revision", "",
+		"Revision of the control plane the webhook injects for, matched against the istio.io/rev label.")
+	rootCmd.PersistentFlags().BoolVar(&flags.nativeSidecars, "nativeSidecars", true,
+		"Inject the proxy as a native sidecar container when the cluster supports them.")
 	// Attach the Istio logging options to the command.
 	flags.loggingOptions.AttachCobraFlags(rootCmd)
 
@@ -0,0 +1,152 @@
+//  Copyright 2018 Istio Authors
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package pilot
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	"github.com/gogo/protobuf/proto"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/test/framework"
+	"istio.io/istio/pkg/test/framework/components/consul"
+	"istio.io/istio/pkg/test/framework/components/environment"
+	"istio.io/istio/pkg/test/framework/components/namespace"
+	"istio.io/istio/pkg/test/framework/components/pilot"
+)
+
+const consulServiceEntry = `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: details
+spec:
+  hosts:
+  - details.example.com
+  ports:
+  - number: 9080
+    name: http
+    protocol: HTTP
+  location: MESH_INTERNAL
+  resolution: STATIC
+  endpoints:
+  - address: 10.10.0.1
+`
+
+// TestConsulRegistry checks that Pilot serves the services of a Consul registry next to the
+// ServiceEntries, and follows the changes of the Consul catalog.
+func TestConsulRegistry(t *testing.T) {
+	framework.NewTest(t).
+		RequiresEnvironment(environment.Native).
+		Run(func(ctx framework.TestContext) {
+			c := consul.NewOrFail(t, ctx, consul.Config{})
+			cp := pilot.NewOrFail(t, ctx, pilot.Config{
+				Galley: g,
+				Consul: c,
+			})
+
+			ns := namespace.NewOrFail(t, ctx, namespace.Config{
+				Prefix: "consul",
+			})
+			g.ApplyConfigOrFail(t, ns, consulServiceEntry)
+
+			ratings := consul.Service{
+				Name: "ratings",
+				Endpoints: []consul.Endpoint{
+					{
+						Address:  "10.20.0.1",
+						Port:     9080,
+						Protocol: protocol.HTTP,
+						Labels:   map[string]string{"version": "v1"},
+					},
+				},
+			}
+			c.RegisterOrFail(t, ratings)
+
+			nodeID := &model.Proxy{
+				ClusterID:    "integration-test",
+				Type:         model.SidecarProxy,
+				IPAddresses:  []string{"10.30.0.1"},
+				ID:           "app." + ns.Name(),
+				DNSDomain:    ns.Name() + ".cluster.local",
+				IstioVersion: model.MaxIstioVersion,
+			}
+
+			consulCluster := fmt.Sprintf("outbound|9080||%s", ratings.Hostname())
+			serviceEntryCluster := "outbound|9080||details.example.com"
+
+			// Both registries contribute to the clusters.
+			cp.StartDiscoveryOrFail(t, pilot.NewDiscoveryRequest(nodeID.ServiceNode(), pilot.Cluster))
+			cp.WatchDiscoveryOrFail(t, time.Second*30,
+				func(response *xdsapi.DiscoveryResponse) (bool, error) {
+					names := map[string]bool{}
+					for _, res := range response.Resources {
+						cluster := &xdsapi.Cluster{}
+						if err := proto.Unmarshal(res.Value, cluster); err != nil {
+							return false, err
+						}
+						names[cluster.Name] = true
+					}
+					return names[consulCluster] && names[serviceEntryCluster], nil
+				})
+
+			req := pilot.NewDiscoveryRequest(nodeID.ServiceNode(), pilot.ClusterLoadAssignment)
+			req.ResourceNames = []string{consulCluster, serviceEntryCluster}
+			cp.StartDiscoveryOrFail(t, req)
+			cp.WatchDiscoveryOrFail(t, time.Second*30, checkEndpoints(map[string][]string{
+				consulCluster:       {"10.20.0.1"},
+				serviceEntryCluster: {"10.10.0.1"},
+			}))
+
+			// The changes of the Consul catalog are pushed.
+			ratings.Endpoints = append(ratings.Endpoints, consul.Endpoint{
+				Address:  "10.20.0.2",
+				Port:     9080,
+				Protocol: protocol.HTTP,
+				Labels:   map[string]string{"version": "v2"},
+			})
+			c.RegisterOrFail(t, ratings)
+			cp.WatchDiscoveryOrFail(t, time.Second*30, checkEndpoints(map[string][]string{
+				consulCluster:       {"10.20.0.1", "10.20.0.2"},
+				serviceEntryCluster: {"10.10.0.1"},
+			}))
+		})
+}
+
+// checkEndpoints accepts the endpoints responses with the given addresses for the clusters.
+func checkEndpoints(expected map[string][]string) func(*xdsapi.DiscoveryResponse) (bool, error) {
+	return func(response *xdsapi.DiscoveryResponse) (bool, error) {
+		got := map[string][]string{}
+		for _, res := range response.Resources {
+			cla := &xdsapi.ClusterLoadAssignment{}
+			if err := proto.Unmarshal(res.Value, cla); err != nil {
+				return false, err
+			}
+			for _, ep := range cla.Endpoints {
+				for _, lb := range ep.LbEndpoints {
+					got[cla.ClusterName] = append(got[cla.ClusterName], lb.GetEndpoint().Address.GetSocketAddress().Address)
+				}
+			}
+			sort.Strings(got[cla.ClusterName])
+		}
+		return reflect.DeepEqual(expected, got), nil
+	}
+}
@@ -19,7 +19,6 @@ import (
 	"fmt"
 	"math/rand"
 	"net/http"
-	"regexp"
 	"testing"
 	"text/template"
 	"time"
@@ -30,6 +29,7 @@ import (
 	"istio.io/istio/pkg/test"
 	"istio.io/istio/pkg/test/framework"
 	"istio.io/istio/pkg/test/framework/components/echo"
+	"istio.io/istio/pkg/test/framework/components/echo/distribution"
 	"istio.io/istio/pkg/test/framework/components/environment"
 	"istio.io/istio/pkg/test/framework/components/galley"
 	"istio.io/istio/pkg/test/framework/components/istio"
@@ -111,7 +111,6 @@ spec:
 )
 
 var (
-	bHostnameMatcher   = regexp.MustCompile("^b-.*$")
 	deploymentTemplate *template.Template
 
 	ist istio.Instance
@@ -225,22 +224,25 @@ func sendTraffic(from echo.Instance, host string) error {
 	headers.Add("Host", host)
 	// This is a hack to remain infrastructure agnostic when running these tests
 	// We actually call the host set above not the endpoint we pass
-	resp, err := from.Call(echo.CallOptions{
-		Target:   from,
-		PortName: "http",
-		Headers:  headers,
-		Count:    sendCount,
-	})
+	checker := distribution.Checker{
+		From: from,
+		Options: echo.CallOptions{
+			Target:   from,
+			PortName: "http",
+			Headers:  headers,
+			Count:    sendCount,
+		},
+		GroupBy: distribution.ByService("b"),
+	}
+	d, err := checker.Send()
 	if err != nil {
 		return fmt.Errorf("%s->%s failed sending: %v", from.Config().Service, host, err)
 	}
-	if len(resp) != sendCount {
-		return fmt.Errorf("%s->%s expected %d responses, received %d", from.Config().Service, host, sendCount, len(resp))
+	if d.Total != sendCount {
+		return fmt.Errorf("%s->%s expected %d responses, received %d", from.Config().Service, host, sendCount, d.Total)
 	}
-	for i, r := range resp {
-		if match := bHostnameMatcher.FindString(r.Hostname); len(match) == 0 {
-			return fmt.Errorf("%s->%s request[%d] made to unexpected service: %s", from.Config().Service, host, i, r.Hostname)
-		}
+	if err := d.CheckPreferred("b", 100); err != nil {
+		return fmt.Errorf("%s->%s: %v", from.Config().Service, host, err)
 	}
 	return nil
 }
@@ -16,20 +16,16 @@ package pilot
 
 import (
 	"fmt"
-	"math"
-	"strings"
-	"sync"
 	"testing"
 
 	"istio.io/istio/pkg/test/framework/components/environment"
 
 	envoyAdmin "github.com/envoyproxy/go-control-plane/envoy/admin/v2alpha"
-	multierror "github.com/hashicorp/go-multierror"
 
 	"istio.io/istio/pkg/config/protocol"
-	"istio.io/istio/pkg/test/echo/client"
 	"istio.io/istio/pkg/test/framework"
 	"istio.io/istio/pkg/test/framework/components/echo"
+	"istio.io/istio/pkg/test/framework/components/echo/distribution"
 	"istio.io/istio/pkg/test/framework/components/echo/echoboot"
 	"istio.io/istio/pkg/test/framework/components/namespace"
 	"istio.io/istio/pkg/test/util/file"
@@ -56,7 +52,7 @@ import (
 //
 
 const (
-	batchSize = 100
+	batchSize = 1000
 
 	// Error threshold. For example, we expect 25% traffic, traffic distribution within [15%, 35%] is accepted.
 	errorThreshold = 10.0
@@ -148,7 +144,20 @@ func TestTrafficShifting(t *testing.T) {
 						}
 					}
 
-					sendTraffic(t, batchSize, instances[0], instances[1], hosts, v, errorThreshold)
+					expected := map[string]int32{}
+					for i, h := range hosts {
+						expected[h] = v[i]
+					}
+					checker := distribution.Checker{
+						From: instances[0],
+						Options: echo.CallOptions{
+							Target:   instances[1],
+							PortName: "http",
+							Count:    batchSize,
+						},
+						GroupBy: distribution.ByService(hosts...),
+					}
+					checker.CheckWeightsOrFail(t, expected, errorThreshold)
 				})
 			}
 		})
@@ -183,67 +192,3 @@ func echoConfig(ns namespace.Instance, name string) echo.Config {
 		Pilot:  p,
 	}
 }
-
-func sendTraffic(t *testing.T, batchSize int, from, to echo.Instance, hosts []string, weight []int32, errorThreshold float64) {
-	const totalThreads = 10
-
-	results := make(chan client.ParsedResponses, 10)
-	errs := make(chan error, 10)
-
-	wg := sync.WaitGroup{}
-	wg.Add(totalThreads)
-
-	for i := 0; i < totalThreads; i++ {
-		go func() {
-			resp, err := from.Call(echo.CallOptions{
-				Target:   to,
-				PortName: "http",
-				Count:    batchSize,
-			})
-			if err != nil {
-				errs <- err
-			} else {
-				results <- resp
-			}
-			wg.Done()
-		}()
-	}
-
-	wg.Wait()
-	close(results)
-	close(errs)
-
-	var callerrors error
-	for err := range errs {
-		callerrors = multierror.Append(err, callerrors)
-	}
-	if callerrors != nil {
-		t.Fatalf("Error occurred during call: %v", callerrors)
-	}
-
-	var totalRequests int
-	hitCount := map[string]int{}
-	for resp := range results {
-		for _, r := range resp {
-			for _, h := range hosts {
-				if strings.HasPrefix(r.Hostname, h+"-") {
-					hitCount[h]++
-					totalRequests++
-					break
-				}
-			}
-		}
-	}
-
-	for i, v := range hosts {
-		percentOfTrafficToHost := float64(hitCount[v]) * 100.0 / float64(totalRequests)
-		deltaFromExpected := math.Abs(float64(weight[i]) - percentOfTrafficToHost)
-		if errorThreshold-deltaFromExpected < 0 {
-			t.Errorf("Unexpected traffic weight for host %v. Expected %d%%, got %g%% (thresold: %g%%)",
-				v, weight[i], percentOfTrafficToHost, errorThreshold)
-		} else {
-			t.Logf("Got expected traffic weight for host %v. Expected %d%%, got %g%% (thresold: %g%%)",
-				v, weight[i], percentOfTrafficToHost, errorThreshold)
-		}
-	}
-}
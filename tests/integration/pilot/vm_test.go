@@ -0,0 +1,94 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pilot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/test/framework"
+	"istio.io/istio/pkg/test/framework/components/echo"
+	"istio.io/istio/pkg/test/framework/components/echo/echoboot"
+	"istio.io/istio/pkg/test/framework/components/environment"
+	"istio.io/istio/pkg/test/framework/components/namespace"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/tests/integration/security/util/connection"
+)
+
+// TestVMTraffic checks the traffic between a pod and a simulated VM registered with a WorkloadEntry.
+func TestVMTraffic(t *testing.T) {
+	framework.NewTest(t).
+		RequiresEnvironment(environment.Kube).
+		Run(func(ctx framework.TestContext) {
+			ns := namespace.NewOrFail(t, ctx, namespace.Config{
+				Prefix: "vm",
+				Inject: true,
+			})
+
+			ports := []echo.Port{
+				{
+					Name:     "http",
+					Protocol: protocol.HTTP,
+				},
+				{
+					Name:     "tcp",
+					Protocol: protocol.TCP,
+				},
+			}
+
+			var pod, vm echo.Instance
+			echoboot.NewBuilderOrFail(t, ctx).
+				With(&pod, echo.Config{
+					Service:   "pod",
+					Namespace: ns,
+					Ports:     ports,
+					Galley:    g,
+					Pilot:     p,
+				}).
+				With(&vm, echo.Config{
+					Service:    "vm",
+					Namespace:  ns,
+					Ports:      ports,
+					Galley:     g,
+					Pilot:      p,
+					DeployAsVM: true,
+				}).
+				BuildOrFail(ctx)
+
+			pod.WaitUntilCallableOrFail(t, vm)
+			vm.WaitUntilCallableOrFail(t, pod)
+
+			for _, pair := range [][2]echo.Instance{{pod, vm}, {vm, pod}} {
+				for _, port := range ports {
+					connChecker := connection.Checker{
+						From: pair[0],
+						Options: echo.CallOptions{
+							Target:   pair[1],
+							PortName: port.Name,
+						},
+						ExpectSuccess: true,
+					}
+					t.Run(fmt.Sprintf("%s->%s:%s", pair[0].Config().Service, pair[1].Config().Service, port.Name),
+						func(t *testing.T) {
+							retry.UntilSuccessOrFail(t, connChecker.Check,
+								retry.Delay(time.Second),
+								retry.Timeout(30*time.Second))
+						})
+				}
+			}
+		})
+}
@@ -23,7 +23,8 @@ docker: build-linux docker.all
 
 # Add new docker targets to the end of the DOCKER_TARGETS list.
 DOCKER_TARGETS:=docker.pilot docker.istiod docker.proxytproxy docker.proxyv2 docker.app docker.app_sidecar docker.test_policybackend \
-	docker.mixer docker.mixer_codegen docker.citadel docker.galley docker.sidecar_injector docker.kubectl docker.node-agent-k8s
+	docker.mixer docker.mixer_codegen docker.citadel docker.galley docker.sidecar_injector docker.kubectl docker.node-agent-k8s \
+	docker.install-cni docker.operator
 
 $(ISTIO_DOCKER) $(ISTIO_DOCKER_TAR):
 	mkdir -p $@
@@ -49,10 +50,16 @@ $(ISTIO_DOCKER)/node_agent.crt $(ISTIO_DOCKER)/node_agent.key: ${GEN_CERT} $(IST
 # 	cp $(ISTIO_OUT_LINUX)/$FILE $(ISTIO_DOCKER)/($FILE)
 DOCKER_FILES_FROM_ISTIO_OUT_LINUX:=client server \
                              pilot-discovery pilot-agent sidecar-injector mixs mixgen \
-                             istio_ca node_agent node_agent_k8s galley istio-iptables istio-clean-iptables
+                             istio_ca node_agent node_agent_k8s galley istio-iptables istio-clean-iptables \
+                             istio-cni install-cni operator
 $(foreach FILE,$(DOCKER_FILES_FROM_ISTIO_OUT_LINUX), \
         $(eval $(ISTIO_DOCKER)/$(FILE): $(ISTIO_OUT_LINUX)/$(FILE) | $(ISTIO_DOCKER); cp $(ISTIO_OUT_LINUX)/$(FILE) $(ISTIO_DOCKER)/$(FILE)))
 
+# rule for the charts the operator renders the installations from.
+$(ISTIO_DOCKER)/charts:
+	mkdir -p $(ISTIO_DOCKER)/charts
+	cp -a install/kubernetes/helm/istio install/kubernetes/helm/istio-init $(ISTIO_DOCKER)/charts/.
+
 # rule for the test certs.
 $(ISTIO_DOCKER)/certs:
 	mkdir -p $(ISTIO_DOCKER)
@@ -85,6 +92,12 @@ docker.sidecar_injector: sidecar-injector/docker/Dockerfile.sidecar_injector
 docker.sidecar_injector:$(ISTIO_DOCKER)/sidecar-injector
 	$(DOCKER_RULE)
 
+docker.install-cni: BUILD_PRE=chmod 755 istio-cni install-cni istio-iptables &&
+docker.install-cni: BUILD_ARGS=--build-arg BASE_VERSION=${BASE_VERSION}
+docker.install-cni: cni/docker/Dockerfile.install-cni
+docker.install-cni: $(ISTIO_DOCKER)/istio-cni $(ISTIO_DOCKER)/install-cni $(ISTIO_DOCKER)/istio-iptables
+	$(DOCKER_RULE)
+
 # BUILD_PRE tells $(DOCKER_RULE) to run the command specified before executing a docker build
 # BUILD_ARGS tells  $(DOCKER_RULE) to execute a docker build with the specified commands
 
@@ -32,6 +32,9 @@ var rootCmd = &cobra.Command{
 	Long: "Script responsible for setting up port forwarding for Istio sidecar.",
 	Run: func(cmd *cobra.Command, args []string) {
 		config := constructConfig()
+		if err := config.Validate(); err != nil {
+			handleError(err)
+		}
 		iptConfigurator := NewIptablesConfigurator(config)
 		iptConfigurator.run()
 	},
@@ -52,6 +55,7 @@ func constructConfig() *config.Config {
 		OutboundIPRangesInclude: viper.GetString(constants.ServiceCidr),
 		OutboundIPRangesExclude: viper.GetString(constants.ServiceExcludeCidr),
 		KubevirtInterfaces:      viper.GetString(constants.KubeVirtInterfaces),
+		ExcludeInterfaces:       viper.GetString(constants.ExcludeInterfaces),
 		DryRun:                  viper.GetBool(constants.DryRun),
 		EnableInboundIPv6s:      nil,
 	}
@@ -151,6 +155,13 @@ func init() {
 	}
 	viper.SetDefault(constants.KubeVirtInterfaces, "")
 
+	rootCmd.Flags().StringP(constants.ExcludeInterfaces, "c", "",
+		"Comma separated list of interfaces whose inbound and outbound traffic will not be redirected to Envoy")
+	if err := viper.BindPFlag(constants.ExcludeInterfaces, rootCmd.Flags().Lookup(constants.ExcludeInterfaces)); err != nil {
+		handleError(err)
+	}
+	viper.SetDefault(constants.ExcludeInterfaces, "")
+
 	rootCmd.Flags().StringP(constants.InboundTProxyMark, "t", "", "")
 	if err := viper.BindPFlag(constants.InboundTProxyMark, rootCmd.Flags().Lookup(constants.InboundTProxyMark)); err != nil {
 		handleError(err)
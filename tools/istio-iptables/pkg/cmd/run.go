@@ -307,6 +307,25 @@ func (iptConfigurator *IptablesConfigurator) handleInboundIpv4Rules(ipv4RangesIn
 	}
 }
 
+// handleExcludeInterfaces skips the redirection of the traffic received or sent on the excluded interfaces,
+// ahead of all the other rules.
+func (iptConfigurator *IptablesConfigurator) handleExcludeInterfaces() {
+	for _, excludedInterface := range split(iptConfigurator.cfg.ExcludeInterfaces) {
+		iptConfigurator.iptables.InsertRuleV4(constants.PREROUTING, constants.NAT, 1, "-i", excludedInterface, "-j", constants.RETURN)
+		iptConfigurator.iptables.InsertRuleV4(constants.OUTPUT, constants.NAT, 1, "-o", excludedInterface, "-j", constants.RETURN)
+		if iptConfigurator.cfg.InboundInterceptionMode == constants.TPROXY {
+			iptConfigurator.iptables.InsertRuleV4(constants.PREROUTING, constants.MANGLE, 1, "-i", excludedInterface, "-j", constants.RETURN)
+		}
+		if iptConfigurator.cfg.EnableInboundIPv6s != nil {
+			iptConfigurator.iptables.InsertRuleV6(constants.PREROUTING, constants.NAT, 1, "-i", excludedInterface, "-j", constants.RETURN)
+			iptConfigurator.iptables.InsertRuleV6(constants.OUTPUT, constants.NAT, 1, "-o", excludedInterface, "-j", constants.RETURN)
+			if iptConfigurator.cfg.InboundInterceptionMode == constants.TPROXY {
+				iptConfigurator.iptables.InsertRuleV6(constants.PREROUTING, constants.MANGLE, 1, "-i", excludedInterface, "-j", constants.RETURN)
+			}
+		}
+	}
+}
+
 func (iptConfigurator *IptablesConfigurator) run() {
 	defer func() {
 		iptConfigurator.ext.RunOrFail(dep.IPTABLESSAVE)
@@ -433,6 +452,7 @@ func (iptConfigurator *IptablesConfigurator) run() {
 
 	iptConfigurator.handleInboundIpv4Rules(ipv4RangesInclude)
 	iptConfigurator.handleInboundIpv6Rules(ipv6RangesExclude, ipv6RangesInclude)
+	iptConfigurator.handleExcludeInterfaces()
 
 	// Execute iptables commands
 	for _, cmd := range iptConfigurator.iptables.BuildV4() {
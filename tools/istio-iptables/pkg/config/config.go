@@ -18,6 +18,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 
 	"istio.io/pkg/log"
 )
@@ -38,6 +40,7 @@ type Config struct {
 	OutboundIPRangesInclude string `json:"OUTBOUND_IPRANGES_INCLUDE"`
 	OutboundIPRangesExclude string `json:"OUTBOUND_IPRANGES_EXCLUDE"`
 	KubevirtInterfaces      string `json:"KUBEVIRT_INTERFACES"`
+	ExcludeInterfaces       string `json:"EXCLUDE_INTERFACES"`
 	EnableInboundIPv6s      net.IP `json:"ENABLE_INBOUND_IPV6"`
 }
 
@@ -64,6 +67,7 @@ func (c *Config) Print() {
 	fmt.Println(fmt.Sprintf("OUTBOUND_IP_RANGES_EXCLUDE=%s", c.OutboundIPRangesExclude))
 	fmt.Println(fmt.Sprintf("OUTBOUND_PORTS_EXCLUDE=%s", c.OutboundPortsExclude))
 	fmt.Println(fmt.Sprintf("KUBEVIRT_INTERFACES=%s", c.KubevirtInterfaces))
+	fmt.Println(fmt.Sprintf("EXCLUDE_INTERFACES=%s", c.ExcludeInterfaces))
 	// Print "" instead of <nil> to produce same output as script and satisfy golden tests
 	if c.EnableInboundIPv6s == nil {
 		fmt.Println(fmt.Sprintf("ENABLE_INBOUND_IPV6=%s", ""))
@@ -72,3 +76,50 @@ func (c *Config) Print() {
 	}
 	fmt.Println("")
 }
+
+// Validate checks the lists of ports and interfaces, which are passed as is to iptables.
+func (c *Config) Validate() error {
+	if c.InboundPortsInclude != "*" {
+		if err := validatePorts(c.InboundPortsInclude); err != nil {
+			return fmt.Errorf("invalid INBOUND_PORTS_INCLUDE: %v", err)
+		}
+	}
+	if err := validatePorts(c.InboundPortsExclude); err != nil {
+		return fmt.Errorf("invalid INBOUND_PORTS_EXCLUDE: %v", err)
+	}
+	if err := validatePorts(c.OutboundPortsExclude); err != nil {
+		return fmt.Errorf("invalid OUTBOUND_PORTS_EXCLUDE: %v", err)
+	}
+	if err := validateInterfaces(c.KubevirtInterfaces); err != nil {
+		return fmt.Errorf("invalid KUBEVIRT_INTERFACES: %v", err)
+	}
+	if err := validateInterfaces(c.ExcludeInterfaces); err != nil {
+		return fmt.Errorf("invalid EXCLUDE_INTERFACES: %v", err)
+	}
+	return nil
+}
+
+func validatePorts(ports string) error {
+	if ports == "" {
+		return nil
+	}
+	for _, port := range strings.Split(ports, ",") {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("invalid port %q", port)
+		}
+	}
+	return nil
+}
+
+// validateInterfaces checks the names of the interfaces against the rules of the kernel.
+func validateInterfaces(interfaces string) error {
+	if interfaces == "" {
+		return nil
+	}
+	for _, name := range strings.Split(interfaces, ",") {
+		if name == "" || len(name) > 15 || strings.ContainsAny(name, "/: \t\n") {
+			return fmt.Errorf("invalid interface name %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2019 Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{"empty", Config{}, ""},
+		{"valid", Config{
+			InboundPortsInclude:  "*",
+			InboundPortsExclude:  "15020,9090",
+			OutboundPortsExclude: "3306",
+			KubevirtInterfaces:   "net1",
+			ExcludeInterfaces:    "eth1,wg0",
+		}, ""},
+		{"invalid inbound port", Config{InboundPortsInclude: "80,http"}, "INBOUND_PORTS_INCLUDE"},
+		{"out of range port", Config{OutboundPortsExclude: "70000"}, "OUTBOUND_PORTS_EXCLUDE"},
+		{"empty interface", Config{ExcludeInterfaces: "eth1,"}, "EXCLUDE_INTERFACES"},
+		{"long interface", Config{KubevirtInterfaces: "averylonginterface"}, "KUBEVIRT_INTERFACES"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
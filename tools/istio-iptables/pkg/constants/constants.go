@@ -75,6 +75,7 @@ const (
 	ProxyUID                  = "proxy-uid"
 	ProxyGID                  = "proxy-gid"
 	KubeVirtInterfaces        = "kube-virt-interfaces"
+	ExcludeInterfaces         = "exclude-interfaces"
 	DryRun                    = "dry-run"
 	Clean                     = "clean"
 )